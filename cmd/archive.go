@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/session"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <name>",
+	Short: "Move a session out of the active list",
+	Long: `Move a session to .snap/archive/. It no longer shows in snap list and
+can't be run, but its tasks, state and plan history are kept. List archived
+sessions with snap list --archived and restore one with snap unarchive.
+
+A session that is running or has a task in progress is only archived with
+--force.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          archiveRun,
+}
+
+var unarchiveCmd = &cobra.Command{
+	Use:           "unarchive <name>",
+	Short:         "Restore an archived session",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          unarchiveRun,
+}
+
+var archiveForce bool
+
+func init() {
+	archiveCmd.Flags().BoolVar(&archiveForce, "force", false, "Archive even while the session is running or has a task in progress")
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(unarchiveCmd)
+}
+
+func archiveRun(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	if err := session.Archive(".", name, archiveForce); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Archived session '%s'\n", name)
+	return nil
+}
+
+func unarchiveRun(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	if err := session.Unarchive(".", name); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Restored session '%s'\n", name)
+	return nil
+}
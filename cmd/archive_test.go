@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+func TestArchive_ListArchived(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+	require.NoError(t, session.Create(".", "auth"))
+	require.NoError(t, session.Create(".", "api"))
+
+	runList := func(t *testing.T, archived bool) string {
+		t.Helper()
+		listArchived = archived
+		defer func() { listArchived = false }()
+		var outBuf strings.Builder
+		listCmd.SetOut(&outBuf)
+		defer listCmd.SetOut(nil)
+		require.NoError(t, listCmd.RunE(listCmd, nil))
+		return ui.StripColors(outBuf.String())
+	}
+
+	assert.Contains(t, runList(t, true), "No archived sessions")
+
+	var outBuf strings.Builder
+	archiveCmd.SetOut(&outBuf)
+	defer archiveCmd.SetOut(nil)
+	require.NoError(t, archiveCmd.RunE(archiveCmd, []string{"auth"}))
+	assert.Contains(t, outBuf.String(), "Archived session 'auth'")
+
+	active := runList(t, false)
+	assert.Contains(t, active, "api")
+	assert.NotContains(t, active, "auth")
+	archived := runList(t, true)
+	assert.Contains(t, archived, "auth")
+	assert.NotContains(t, archived, "api")
+
+	outBuf.Reset()
+	unarchiveCmd.SetOut(&outBuf)
+	defer unarchiveCmd.SetOut(nil)
+	require.NoError(t, unarchiveCmd.RunE(unarchiveCmd, []string{"auth"}))
+	assert.Contains(t, outBuf.String(), "Restored session 'auth'")
+	assert.Contains(t, runList(t, false), "auth")
+}
+
+func TestArchive_Force(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+	require.NoError(t, session.Create(".", "auth"))
+	statePath := filepath.Join(session.Dir(".", "auth"), "state.json")
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"current_task_id": "TASK1"}`), 0o600))
+
+	var outBuf strings.Builder
+	archiveCmd.SetOut(&outBuf)
+	defer archiveCmd.SetOut(nil)
+	err := archiveCmd.RunE(archiveCmd, []string{"auth"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TASK1 in progress")
+
+	archiveForce = true
+	defer func() { archiveForce = false }()
+	require.NoError(t, archiveCmd.RunE(archiveCmd, []string{"auth"}))
+	assert.Contains(t, outBuf.String(), "Archived session 'auth'")
+}
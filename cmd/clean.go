@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/tap"
+
+	"github.com/yarlson/snap/internal/input"
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/state"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+var (
+	cleanState     bool
+	cleanSnapshots bool
+	cleanAll       bool
+	cleanSession   string
+	cleanYes       bool
+)
+
+// cleanInteractive reports whether clean can ask for confirmation.
+// Tests replace it to drive the prompt.
+var cleanInteractive = func() bool { return input.IsTerminal(os.Stdin) }
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove workflow state and snapshots",
+	Long: `Remove snap's workflow state and step snapshots for a fresh start.
+
+--state removes state.json for the legacy layout and every session (or only
+--session). --snapshots drops the "snap:" stash entries and leaves other
+stashes alone. Plans and task files are never touched; use snap delete to
+remove a session.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          cleanRun,
+}
+
+func init() {
+	cleanCmd.Flags().BoolVar(&cleanState, "state", false, "Remove workflow state files")
+	cleanCmd.Flags().BoolVar(&cleanSnapshots, "snapshots", false, "Drop snap step snapshots from the git stash")
+	cleanCmd.Flags().BoolVar(&cleanAll, "all", false, "Remove state and snapshots")
+	cleanCmd.Flags().StringVar(&cleanSession, "session", "", "Only remove state for this session")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Skip confirmation prompt")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+func cleanRun(cmd *cobra.Command, _ []string) error {
+	doState := cleanState || cleanAll
+	doSnapshots := cleanSnapshots || cleanAll
+	if !doState && !doSnapshots {
+		return errors.New("nothing to clean: pass --state, --snapshots or --all")
+	}
+	if cleanSession != "" {
+		if _, err := session.Resolve(".", cleanSession); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var statePaths []string
+	if doState {
+		paths, err := stateFiles(".", cleanSession)
+		if err != nil {
+			return err
+		}
+		statePaths = paths
+	}
+
+	var snapshots []snapshot.Entry
+	snapshotter := snapshot.New(".")
+	if doSnapshots {
+		entries, err := snapshotter.List(ctx, snapshot.Filter{})
+		if err != nil {
+			return err
+		}
+		snapshots = entries
+	}
+
+	out := cmd.OutOrStdout()
+	if len(statePaths) == 0 && len(snapshots) == 0 {
+		fmt.Fprint(out, ui.Info("Nothing to clean"))
+		return nil
+	}
+
+	fmt.Fprint(out, ui.Info("Will remove:"))
+	for _, p := range statePaths {
+		fmt.Fprint(out, ui.Info("  "+p))
+	}
+	for _, e := range snapshots {
+		fmt.Fprint(out, ui.Info(fmt.Sprintf("  %s  %s", e.Ref, e.Label)))
+	}
+
+	if !cleanYes {
+		if !cleanInteractive() {
+			return errors.New("refusing to clean without confirmation: pass --yes")
+		}
+
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigCh)
+		go func() {
+			<-sigCh
+			cancel()
+		}()
+
+		confirmed := tap.Confirm(ctx, tap.ConfirmOptions{
+			Message:  fmt.Sprintf("Remove %d item(s)?", len(statePaths)+len(snapshots)),
+			Active:   "Yes",
+			Inactive: "No",
+		})
+		if !confirmed {
+			return nil
+		}
+	}
+
+	for _, p := range statePaths {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove %s: %w", p, err)
+		}
+	}
+	if len(statePaths) > 0 {
+		fmt.Fprint(out, ui.Info(fmt.Sprintf("Removed %d state file(s)", len(statePaths))))
+	}
+
+	if len(snapshots) > 0 {
+		if err := snapshotter.Drop(ctx, snapshots); err != nil {
+			return err
+		}
+		fmt.Fprint(out, ui.Info(fmt.Sprintf("Dropped %d snapshot(s)", len(snapshots))))
+	}
+	return nil
+}
+
+// stateFiles returns the existing state files under projectRoot: the legacy
+// .snap/state.json and each session's state.json, or only the named
+// session's when name is set.
+func stateFiles(projectRoot, name string) ([]string, error) {
+	var candidates []string
+	if name != "" {
+		candidates = []string{filepath.Join(session.Dir(projectRoot, name), state.StateFile)}
+	} else {
+		candidates = []string{filepath.Join(projectRoot, state.StateDir, state.StateFile)}
+		sessions, err := session.List(projectRoot)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range sessions {
+			candidates = append(candidates, filepath.Join(session.Dir(projectRoot, s.Name), state.StateFile))
+		}
+	}
+
+	var paths []string
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yarlson/tap"
+
+	"github.com/yarlson/snap/internal/snapshot"
+)
+
+// setCleanFlags sets the clean flags for one test and restores them after.
+func setCleanFlags(t *testing.T, stateFlag, snapshots, all, yes bool, sessionName string) {
+	t.Helper()
+	cleanState, cleanSnapshots, cleanAll, cleanYes, cleanSession = stateFlag, snapshots, all, yes, sessionName
+	t.Cleanup(func() {
+		cleanState, cleanSnapshots, cleanAll, cleanYes, cleanSession = false, false, false, false, ""
+	})
+}
+
+// writeStateFiles creates legacy state plus state for the auth and api sessions.
+func writeStateFiles(t *testing.T, projectDir string) {
+	t.Helper()
+	for _, dir := range []string{
+		filepath.Join(projectDir, ".snap"),
+		filepath.Join(projectDir, ".snap", "sessions", "auth"),
+		filepath.Join(projectDir, ".snap", "sessions", "api"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "tasks"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "state.json"), []byte("{}"), 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks", "TASK1.md"), []byte("# Task 1"), 0o600))
+}
+
+func TestClean_RequiresWhatToClean(t *testing.T) {
+	chdir(t, t.TempDir())
+	setCleanFlags(t, false, false, false, true, "")
+
+	err := cleanCmd.RunE(cleanCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pass --state, --snapshots or --all")
+}
+
+func TestClean_StateWithYes(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+	writeStateFiles(t, projectDir)
+	setCleanFlags(t, true, false, false, true, "")
+
+	var outBuf strings.Builder
+	cleanCmd.SetOut(&outBuf)
+	defer cleanCmd.SetOut(nil)
+
+	require.NoError(t, cleanCmd.RunE(cleanCmd, nil))
+
+	output := outBuf.String()
+	assert.Contains(t, output, "Will remove:")
+	assert.Contains(t, output, filepath.Join(".snap", "sessions", "auth", "state.json"))
+	assert.Contains(t, output, "Removed 3 state file(s)")
+
+	assert.NoFileExists(t, filepath.Join(projectDir, ".snap", "state.json"))
+	assert.NoFileExists(t, filepath.Join(projectDir, ".snap", "sessions", "auth", "state.json"))
+	assert.NoFileExists(t, filepath.Join(projectDir, ".snap", "sessions", "api", "state.json"))
+	assert.FileExists(t, filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks", "TASK1.md"), "task files are kept")
+}
+
+func TestClean_SessionOnly(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+	writeStateFiles(t, projectDir)
+	setCleanFlags(t, true, false, false, true, "auth")
+
+	var outBuf strings.Builder
+	cleanCmd.SetOut(&outBuf)
+	defer cleanCmd.SetOut(nil)
+
+	require.NoError(t, cleanCmd.RunE(cleanCmd, nil))
+	assert.Contains(t, outBuf.String(), "Removed 1 state file(s)")
+
+	assert.NoFileExists(t, filepath.Join(projectDir, ".snap", "sessions", "auth", "state.json"))
+	assert.FileExists(t, filepath.Join(projectDir, ".snap", "sessions", "api", "state.json"))
+	assert.FileExists(t, filepath.Join(projectDir, ".snap", "state.json"))
+}
+
+func TestClean_UnknownSession(t *testing.T) {
+	chdir(t, t.TempDir())
+	setCleanFlags(t, true, false, false, true, "nope")
+
+	err := cleanCmd.RunE(cleanCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session 'nope' not found")
+}
+
+func TestClean_NothingToClean(t *testing.T) {
+	chdir(t, t.TempDir())
+	setCleanFlags(t, true, false, false, false, "")
+
+	var outBuf strings.Builder
+	cleanCmd.SetOut(&outBuf)
+	defer cleanCmd.SetOut(nil)
+
+	require.NoError(t, cleanCmd.RunE(cleanCmd, nil))
+	assert.Contains(t, outBuf.String(), "Nothing to clean")
+}
+
+func TestClean_NonInteractiveRequiresYes(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+	writeStateFiles(t, projectDir)
+	setCleanFlags(t, true, false, false, false, "")
+
+	orig := cleanInteractive
+	cleanInteractive = func() bool { return false }
+	defer func() { cleanInteractive = orig }()
+
+	err := cleanCmd.RunE(cleanCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pass --yes")
+	assert.FileExists(t, filepath.Join(projectDir, ".snap", "state.json"))
+}
+
+func TestClean_Confirmation(t *testing.T) {
+	for _, tt := range []struct {
+		key     string
+		removed bool
+	}{
+		{key: "y", removed: true},
+		{key: "n", removed: false},
+	} {
+		t.Run(tt.key, func(t *testing.T) {
+			projectDir := t.TempDir()
+			chdir(t, projectDir)
+			writeStateFiles(t, projectDir)
+			setCleanFlags(t, true, false, false, false, "api")
+
+			orig := cleanInteractive
+			cleanInteractive = func() bool { return true }
+			defer func() { cleanInteractive = orig }()
+
+			in := tap.NewMockReadable()
+			out := tap.NewMockWritable()
+			tap.SetTermIO(in, out)
+			defer tap.SetTermIO(nil, nil)
+
+			cleanCmd.SetOut(&strings.Builder{})
+			defer cleanCmd.SetOut(nil)
+
+			resultCh := make(chan error, 1)
+			go func() {
+				resultCh <- cleanCmd.RunE(cleanCmd, nil)
+			}()
+
+			time.Sleep(200 * time.Millisecond)
+			in.EmitKeypress(tt.key, tap.Key{Name: tt.key})
+
+			select {
+			case err := <-resultCh:
+				require.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out")
+			}
+
+			_, err := os.Stat(filepath.Join(projectDir, ".snap", "sessions", "api", "state.json"))
+			assert.Equal(t, tt.removed, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestClean_SnapshotsKeepForeignStashes(t *testing.T) {
+	projectDir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		c := exec.CommandContext(context.Background(), "git", args...)
+		c.Dir = projectDir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	git("init")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte("# init"), 0o600))
+	git("add", ".")
+	git("commit", "-m", "initial commit")
+
+	s := snapshot.New(projectDir)
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte("TASK1"), 0o600))
+	_, err := s.Capture(context.Background(), snapshot.Label{TaskID: "TASK1", Step: 1, Total: 10, Name: "Implement"}.String())
+	require.NoError(t, err)
+	git("stash", "push", "-m", "my manual stash")
+
+	chdir(t, projectDir)
+	setCleanFlags(t, false, false, true, true, "")
+
+	var outBuf strings.Builder
+	cleanCmd.SetOut(&outBuf)
+	defer cleanCmd.SetOut(nil)
+
+	require.NoError(t, cleanCmd.RunE(cleanCmd, nil))
+
+	output := outBuf.String()
+	assert.Contains(t, output, "snap: TASK1 step 1/10 — Implement")
+	assert.NotContains(t, output, "my manual stash")
+	assert.Contains(t, output, "Dropped 1 snapshot(s)")
+
+	remaining, err := s.List(context.Background(), snapshot.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	c := exec.CommandContext(context.Background(), "git", "stash", "list")
+	c.Dir = projectDir
+	list, err := c.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(list), "my manual stash")
+}
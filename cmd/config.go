@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/config"
+	"github.com/yarlson/snap/internal/provider"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get and set persisted defaults in .snaprc",
+}
+
+var configGetCmd = &cobra.Command{
+	Use:           "get <key>",
+	Short:         "Print the effective value of a config key",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          configGetRun,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:           "set <key> <value>",
+	Short:         "Write a config key to the repo .snaprc",
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          configSetRun,
+}
+
+var configListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List effective config values and where they come from",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          configListRun,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd)
+}
+
+func configGetRun(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	v, err := cfg.Get(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), v.Value)
+	return nil
+}
+
+func configSetRun(cmd *cobra.Command, args []string) error {
+	if err := config.Set(".", args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Set %s = %s in %s\n", args[0], args[1], config.FileName)
+	return nil
+}
+
+func configListRun(cmd *cobra.Command, _ []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, v := range cfg.List() {
+		fmt.Fprintf(tw, "%s\t%s\t(%s)\n", v.Key, v.Value, v.Source)
+	}
+	return tw.Flush()
+}
+
+// loadConfig reads the repo .snaprc and the one in the home directory, then
+// applies the flags of cmd that were set and override a config key.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = ""
+	}
+	cfg, err := config.Load(".", home)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range config.Keys() {
+		if k.Flag == "" {
+			continue
+		}
+		if f := cmd.Flag(k.Flag); f != nil && f.Changed {
+			if err := cfg.SetFlag(k.Name, f.Value.String()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// resolveProviderName returns the provider from SNAP_PROVIDER, .snaprc, or the default.
+func resolveProviderName(cfg *config.Config) (string, error) {
+	v, err := cfg.Get("provider")
+	if err != nil {
+		return "", err
+	}
+	return provider.NormalizeName(v.Value), nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_SetGetList(t *testing.T) {
+	projectDir := t.TempDir()
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("SNAP_PROVIDER", "")
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(projectDir))
+	defer func() { require.NoError(t, os.Chdir(origDir)) }()
+
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, ".snaprc"), []byte("ci-poll: 45s\n"), 0o600))
+
+	var outBuf strings.Builder
+	configSetCmd.SetOut(&outBuf)
+	configGetCmd.SetOut(&outBuf)
+	configListCmd.SetOut(&outBuf)
+	defer func() {
+		configSetCmd.SetOut(nil)
+		configGetCmd.SetOut(nil)
+		configListCmd.SetOut(nil)
+	}()
+
+	require.NoError(t, configSetCmd.RunE(configSetCmd, []string{"provider", "codex"}))
+	assert.Contains(t, outBuf.String(), "Set provider = codex in .snaprc")
+	assert.FileExists(t, filepath.Join(projectDir, ".snaprc"))
+
+	outBuf.Reset()
+	require.NoError(t, configGetCmd.RunE(configGetCmd, []string{"provider"}))
+	assert.Equal(t, "codex\n", outBuf.String())
+
+	outBuf.Reset()
+	require.NoError(t, configListCmd.RunE(configListCmd, nil))
+	output := outBuf.String()
+	assert.Regexp(t, `provider\s+codex\s+\(repo\)`, output)
+	assert.Regexp(t, `ci-poll\s+45s\s+\(home\)`, output)
+	assert.Regexp(t, `tasks-dir\s+docs/tasks\s+\(default\)`, output)
+}
+
+func TestConfig_GetUnknownKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	defer func() { require.NoError(t, os.Chdir(origDir)) }()
+
+	err = configGetCmd.RunE(configGetCmd, []string{"ci-pol"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `did you mean "ci-poll"`)
+}
+
+func TestConfig_ListReportsFlagSource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".snaprc"), []byte("tasks-dir: work/tasks\n"), 0o600))
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(projectDir))
+	defer func() { require.NoError(t, os.Chdir(origDir)) }()
+
+	flag := rootCmd.PersistentFlags().Lookup("tasks-dir")
+	require.NoError(t, flag.Value.Set("flag/tasks"))
+	flag.Changed = true
+	defer func() {
+		require.NoError(t, flag.Value.Set(flag.DefValue))
+		flag.Changed = false
+	}()
+
+	var outBuf strings.Builder
+	configListCmd.SetOut(&outBuf)
+	defer configListCmd.SetOut(nil)
+
+	require.NoError(t, configListCmd.RunE(configListCmd, nil))
+	assert.Regexp(t, `tasks-dir\s+flag/tasks\s+\(flag\)`, outBuf.String())
+}
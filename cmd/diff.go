@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/state"
+	"github.com/yarlson/snap/internal/ui"
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+var (
+	diffStat     bool
+	diffNameOnly bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [session]",
+	Short: "Show what changed since the current task started",
+	Long: `Show git diff from the commit HEAD pointed to when the current task
+started: the task's commits plus uncommitted changes to tracked files.
+
+Read-only: it works while a run is in progress, paused or interrupted.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          diffRun,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolVar(&diffStat, "stat", false, "Show a per-file summary instead of the full diff")
+	diffCmd.Flags().BoolVar(&diffNameOnly, "name-only", false, "Show only the names of changed files")
+}
+
+func diffRun(cmd *cobra.Command, args []string) error {
+	if diffStat && diffNameOnly {
+		return markPreflight(errors.New("--stat and --name-only cannot be used together"))
+	}
+
+	stateManager, err := resolveDiffState(args, tasksDir)
+	if err != nil {
+		return err
+	}
+	workflowState, err := stateManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if workflowState == nil || workflowState.CurrentTaskID == "" {
+		fmt.Fprint(out, ui.Info("No active task — nothing to diff"))
+		return nil
+	}
+	taskID := workflowState.CurrentTaskID
+	if workflowState.TaskStartCommit == "" {
+		fmt.Fprint(out, ui.Info(fmt.Sprintf("No start commit recorded for %s — nothing to diff", taskID)))
+		return nil
+	}
+
+	var flags []string
+	switch {
+	case diffStat:
+		flags = append(flags, "--stat")
+	case diffNameOnly:
+		flags = append(flags, "--name-only")
+	}
+	if ui.ColorsEnabled() {
+		flags = append(flags, "--color=always")
+	}
+
+	diff, err := snapshot.New(".").DiffSince(context.Background(), workflowState.TaskStartCommit, flags...)
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		fmt.Fprint(out, ui.Info(fmt.Sprintf("No changes since %s started", taskID)))
+		return nil
+	}
+	fmt.Fprintln(out, diff)
+	return nil
+}
+
+// resolveDiffState picks the state to diff with the same rules as run:
+// the named session, the only session, the legacy layout (legacyTasksDir),
+// or a new "default" session.
+func resolveDiffState(args []string, legacyTasksDir string) (workflow.StateManager, error) {
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+	target, err := session.ResolveForRun(".", name, legacyTasksDir)
+	if err != nil {
+		var multi *session.MultipleSessionsError
+		if errors.As(err, &multi) {
+			return nil, formatMultipleSessionsError(multi.Sessions, "diff")
+		}
+		return nil, err
+	}
+	if target.Legacy() {
+		return state.NewManager(), nil
+	}
+	return state.NewManagerInDir(session.Dir(".", target.Session)), nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/state"
+)
+
+func TestDiff_ActiveTask(t *testing.T) {
+	projectDir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		c := exec.CommandContext(context.Background(), "git", args...)
+		c.Dir = projectDir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	git("init")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte("# init\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".gitignore"), []byte(".snap/\n"), 0o600))
+	git("add", ".")
+	git("commit", "-m", "initial commit")
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "docs", "tasks"), 0o755))
+
+	chdir(t, projectDir)
+
+	run := func(stat, nameOnly bool) (string, error) {
+		t.Helper()
+		diffStat, diffNameOnly = stat, nameOnly
+		defer func() { diffStat, diffNameOnly = false, false }()
+		var outBuf strings.Builder
+		diffCmd.SetOut(&outBuf)
+		defer diffCmd.SetOut(nil)
+		err := diffCmd.RunE(diffCmd, nil)
+		return outBuf.String(), err
+	}
+
+	output, err := run(false, false)
+	require.NoError(t, err)
+	assert.Contains(t, output, "No active task")
+
+	head, err := snapshot.New(".").Head(context.Background())
+	require.NoError(t, err)
+	s := state.NewState("docs/tasks", "docs/tasks/PRD.md", 10)
+	s.CurrentTaskID = "TASK1"
+	s.CurrentTaskFile = "TASK1.md"
+	s.CurrentStep = 4
+	s.TaskStartCommit = head
+	require.NoError(t, state.NewManager().Save(s))
+
+	output, err = run(false, false)
+	require.NoError(t, err)
+	assert.Contains(t, output, "No changes since TASK1 started")
+
+	// One committed and one uncommitted change since the task started.
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "main.go"), []byte("package main\n"), 0o600))
+	git("add", "main.go")
+	git("commit", "-m", "add main")
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte("# changed\n"), 0o600))
+
+	output, err = run(false, false)
+	require.NoError(t, err)
+	assert.Contains(t, output, "+package main")
+	assert.Contains(t, output, "+# changed")
+
+	output, err = run(false, true)
+	require.NoError(t, err)
+	assert.Equal(t, "README.md\nmain.go\n", output)
+
+	output, err = run(true, false)
+	require.NoError(t, err)
+	assert.Contains(t, output, "2 files changed")
+
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "util.go"), []byte("package main\n"), 0o600))
+	output, err = run(false, true)
+	require.NoError(t, err)
+	assert.Equal(t, "README.md\nmain.go\nutil.go\n", output, "new files the task created are included")
+
+	_, err = run(true, true)
+	require.Error(t, err)
+	assert.Equal(t, "--stat and --name-only cannot be used together", err.Error())
+}
+
+func TestDiff_MultipleSessions(t *testing.T) {
+	chdir(t, t.TempDir())
+	require.NoError(t, session.Create(".", "auth"))
+	require.NoError(t, session.Create(".", "api"))
+
+	err := diffCmd.RunE(diffCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multiple sessions found")
+	assert.Contains(t, err.Error(), "snap diff <name>")
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yarlson/snap/internal/postrun"
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+// Exit codes, so scripts can tell failure causes apart.
+const (
+	exitFailure     = 1   // Any other failure, e.g. a failed iteration
+	exitPreflight   = 2   // Invalid flags or config, missing provider CLI, unresolvable session
+	exitProvider    = 3   // The provider CLI failed
+	exitCI          = 4   // CI still failing after the fix attempts
+	exitInterrupted = 130 // SIGINT/SIGTERM (128 + 2)
+)
+
+// ErrPreflight marks failures detected before a command starts its work.
+// Errors carrying it keep their own message; match them with errors.Is.
+var ErrPreflight = errors.New("preflight failed")
+
+// preflightError attaches ErrPreflight to an error without changing its message.
+type preflightError struct{ error }
+
+func (e preflightError) Unwrap() []error { return []error{e.error, ErrPreflight} }
+
+// markPreflight marks err as a preflight failure. A nil err stays nil.
+func markPreflight(err error) error {
+	if err == nil {
+		return nil
+	}
+	return preflightError{err}
+}
+
+// exitCode maps an error returned by a command to the process exit code.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, ErrPreflight):
+		return exitPreflight
+	case errors.Is(err, workflow.ErrProvider):
+		return exitProvider
+	case errors.Is(err, postrun.ErrCI):
+		return exitCI
+	default:
+		return exitFailure
+	}
+}
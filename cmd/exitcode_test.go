@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/postrun"
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "generic failure", err: errors.New("iteration failed"), want: exitFailure},
+		{name: "interrupt", err: fmt.Errorf("iteration failed: %w", context.Canceled), want: exitInterrupted},
+		{name: "interrupt during preflight", err: markPreflight(context.Canceled), want: exitInterrupted},
+		{name: "preflight", err: markPreflight(errors.New("claude not found in PATH")), want: exitPreflight},
+		{name: "provider", err: fmt.Errorf("iteration failed: %w", workflow.ProviderError(errors.New("claude command failed"))), want: exitProvider},
+		{name: "CI", err: fmt.Errorf("%w after 10 attempts: lint", postrun.ErrCI), want: exitCI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
+
+func TestMarkPreflight_KeepsMessage(t *testing.T) {
+	err := markPreflight(errors.New("invalid --provider-stderr"))
+	assert.Equal(t, "invalid --provider-stderr", err.Error())
+	assert.ErrorIs(t, err, ErrPreflight)
+	assert.NoError(t, markPreflight(nil))
+}
+
+func TestE2E_ExitCodes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "unknown flag", args: []string{"--no-such-flag"}, want: exitPreflight},
+		{name: "invalid flag value", args: []string{"run", "--provider-stderr", "loud"}, want: exitPreflight},
+		{name: "invalid repo", args: []string{"--repo", "/nonexistent/snap-repo", "list"}, want: exitPreflight},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.CommandContext(context.Background(), binPath, tt.args...)
+			cmd.Dir = t.TempDir()
+			output, err := cmd.CombinedOutput()
+
+			var exitErr *exec.ExitError
+			require.ErrorAs(t, err, &exitErr, "output: %s", output)
+			assert.Equal(t, tt.want, exitErr.ExitCode(), "output: %s", output)
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 
@@ -10,8 +12,15 @@ import (
 	"github.com/yarlson/snap/internal/ui"
 )
 
+var listArchived bool
+
+// listDescriptionWidth is the widest session description snap list prints
+// before truncating it with "…".
+const listDescriptionWidth = 40
+
 var listCmd = &cobra.Command{
 	Use:           "list",
+	Aliases:       []string{"ls"},
 	Short:         "List all sessions",
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -20,16 +29,38 @@ var listCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output sessions as JSON")
+	listCmd.Flags().BoolVar(&listArchived, "archived", false, "List archived sessions instead")
 }
 
 func listRun(cmd *cobra.Command, _ []string) error {
-	sessions, err := session.List(".")
+	list := session.List
+	if listArchived {
+		list = session.ListArchived
+	}
+	sessions, err := list(".")
 	if err != nil {
 		return err
 	}
 
 	out := cmd.OutOrStdout()
 
+	if jsonOutput {
+		if sessions == nil {
+			sessions = []session.Info{}
+		}
+		data, err := json.MarshalIndent(sessions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal sessions: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	if len(sessions) == 0 && listArchived {
+		fmt.Fprint(out, ui.Info("No archived sessions"))
+		return nil
+	}
 	if len(sessions) == 0 {
 		fmt.Fprint(out, ui.Info("No sessions found"))
 		fmt.Fprintln(out)
@@ -40,13 +71,17 @@ func listRun(cmd *cobra.Command, _ []string) error {
 
 	// Calculate column widths for alignment.
 	maxName := 0
+	maxDesc := 0
 	maxTasks := 0
+	descriptions := make([]string, len(sessions))
 	taskSummaries := make([]string, len(sessions))
 
 	for i, s := range sessions {
 		if len(s.Name) > maxName {
 			maxName = len(s.Name)
 		}
+		descriptions[i] = truncateRunes(s.SessionDescription, listDescriptionWidth)
+		maxDesc = max(maxDesc, utf8.RuneCountInString(descriptions[i]))
 		ts := formatTaskSummary(s.TaskCount, s.CompletedCount)
 		taskSummaries[i] = ts
 		if len(ts) > maxTasks {
@@ -59,15 +94,33 @@ func listRun(cmd *cobra.Command, _ []string) error {
 	resetCode := ui.ResolveStyle(ui.WeightNormal)
 
 	for i, s := range sessions {
-		fmt.Fprintf(out, "  %s%-*s%s  %s%-*s%s  %s%s\n",
-			boldCode, maxName, s.Name, resetCode,
+		fmt.Fprintf(out, "  %s%-*s%s", boldCode, maxName, s.Name, resetCode)
+		if maxDesc > 0 {
+			// %-*s pads by bytes; pad by runes so "…" keeps the columns aligned.
+			pad := maxDesc - utf8.RuneCountInString(descriptions[i])
+			fmt.Fprintf(out, "  %s%s", descriptions[i], strings.Repeat(" ", pad))
+		}
+		fmt.Fprintf(out, "  %s%-*s%s  %s%s",
 			dimCode, maxTasks, taskSummaries[i], resetCode,
 			s.Status, resetCode)
+		if s.Description != "" {
+			fmt.Fprintf(out, "  %s%s%s", dimCode, s.Description, resetCode)
+		}
+		fmt.Fprintln(out)
 	}
 
 	return nil
 }
 
+// truncateRunes shortens s to at most width runes, ending in "…" when cut.
+func truncateRunes(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
 func formatTaskSummary(taskCount, completedCount int) string {
 	if taskCount == 0 {
 		return "0 tasks"
@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/ui"
 )
 
 // --- Integration tests ---
@@ -67,3 +72,81 @@ func TestList_FormattedOutput(t *testing.T) {
 	// api should show 0 tasks.
 	assert.Contains(t, output, "0 tasks")
 }
+
+func TestList_JSON(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+
+	run := func(t *testing.T) string {
+		t.Helper()
+		jsonOutput = true
+		defer func() { jsonOutput = false }()
+		var outBuf strings.Builder
+		listCmd.SetOut(&outBuf)
+		defer listCmd.SetOut(nil)
+		require.NoError(t, listCmd.RunE(listCmd, nil))
+		return outBuf.String()
+	}
+
+	assert.Equal(t, "[]\n", run(t), "no sessions is an empty array")
+
+	sessionsDir := filepath.Join(projectDir, ".snap", "sessions")
+	require.NoError(t, session.Create(".", "api"))
+	require.NoError(t, session.Create(".", "auth", session.WithDescription("Login flow")))
+	for _, name := range []string{"TASK1.md", "TASK2.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(sessionsDir, "auth", "tasks", name), []byte("# Task\n"), 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(sessionsDir, "auth", "state.json"), []byte(`{
+		"current_task_id": "TASK2",
+		"current_task_file": "TASK2.md",
+		"current_step": 3,
+		"total_steps": 10,
+		"completed_task_ids": ["TASK1"],
+		"task_description": "Add token refresh"
+	}`), 0o600))
+
+	output := run(t)
+	var got []session.Info
+	require.NoError(t, json.Unmarshal([]byte(output), &got))
+	assert.Equal(t, []session.Info{
+		{Name: "api", Status: "no tasks"},
+		{Name: "auth", TaskCount: 2, CompletedCount: 1, Status: "paused at step 3", Description: "Add token refresh", SessionDescription: "Login flow"},
+	}, got)
+
+	// Field names are part of the scripting interface.
+	var raw []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &raw))
+	assert.Equal(t, map[string]any{
+		"name": "auth", "task_count": 2.0, "completed_count": 1.0,
+		"status": "paused at step 3", "description": "Add token refresh", "session_description": "Login flow",
+	}, raw[1])
+	assert.NotContains(t, raw[0], "description", "empty descriptions are omitted")
+	assert.NotContains(t, raw[0], "session_description")
+}
+
+func TestList_SessionDescription(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+	require.NoError(t, session.Create(".", "api"))
+	require.NoError(t, session.Create(".", "auth", session.WithDescription("Login flow")))
+	require.NoError(t, session.Create(".", "billing", session.WithDescription(strings.Repeat("Stripe invoices ", 5))))
+
+	var outBuf strings.Builder
+	listCmd.SetOut(&outBuf)
+	defer listCmd.SetOut(nil)
+	require.NoError(t, listCmd.RunE(listCmd, nil))
+
+	lines := strings.Split(strings.TrimRight(ui.StripColors(outBuf.String()), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "auth     Login flow")
+	assert.Contains(t, lines[2], "billing  Stripe invoices Stripe invoices Stripe …  0 tasks")
+	assert.NotContains(t, lines[2], "Stripe invoices Stripe invoices Stripe invoices")
+
+	// The tasks column lines up whatever the description length.
+	column := func(line string) int {
+		return utf8.RuneCountInString(line[:strings.Index(line, "0 tasks")])
+	}
+	for _, line := range lines {
+		assert.Equal(t, column(lines[2]), column(line), line)
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/provider"
+)
+
+var (
+	modelFast     string
+	modelThinking string
+	providerEnv   []string
+)
+
+// addModelFlags registers --model-fast, --model-thinking and --provider-env
+// on cmd.
+func addModelFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&modelFast, "model-fast", "", "Model name the provider uses for fast steps (default: provider's fast model)")
+	cmd.Flags().StringVar(&modelThinking, "model-thinking", "", "Model name the provider uses for thinking steps (default: provider's thinking model)")
+	cmd.Flags().StringArrayVar(&providerEnv, "provider-env", nil, "KEY=VALUE set in the provider CLI's environment (repeatable)")
+}
+
+// modelOptions returns executor options pinning the --model-fast and
+// --model-thinking names and setting the --provider-env variables. A flag
+// given with an empty name is an error.
+func modelOptions(cmd *cobra.Command) ([]provider.Option, error) {
+	flags := []struct {
+		name  string
+		mt    model.Type
+		value string
+	}{
+		{"model-fast", model.Fast, modelFast},
+		{"model-thinking", model.Thinking, modelThinking},
+	}
+
+	var opts []provider.Option
+	for _, f := range flags {
+		if !cmd.Flags().Changed(f.name) {
+			continue
+		}
+		name := strings.TrimSpace(f.value)
+		if name == "" {
+			return nil, fmt.Errorf("invalid --%s: model name cannot be empty", f.name)
+		}
+		opts = append(opts, provider.WithModel(f.mt, name))
+	}
+	env, err := parseProviderEnv(providerEnv)
+	if err != nil {
+		return nil, err
+	}
+	if len(env) > 0 {
+		opts = append(opts, provider.WithEnv(env))
+	}
+	return opts, nil
+}
+
+// parseProviderEnv turns --provider-env KEY=VALUE pairs into a map. The
+// value may be empty; the key may not. A repeated key keeps the last value.
+func parseProviderEnv(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("invalid --provider-env %q: expected KEY=VALUE", pair)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newModelFlagsCmd(t *testing.T) *cobra.Command {
+	t.Helper()
+	t.Cleanup(func() { modelFast, modelThinking, providerEnv = "", "", nil })
+	c := &cobra.Command{}
+	addModelFlags(c)
+	return c
+}
+
+func TestModelOptions(t *testing.T) {
+	t.Run("no flags keeps provider defaults", func(t *testing.T) {
+		c := newModelFlagsCmd(t)
+		opts, err := modelOptions(c)
+		require.NoError(t, err)
+		assert.Empty(t, opts)
+	})
+
+	t.Run("pins given names", func(t *testing.T) {
+		c := newModelFlagsCmd(t)
+		require.NoError(t, c.Flags().Set("model-fast", "haiku-x"))
+		require.NoError(t, c.Flags().Set("model-thinking", "opus-y"))
+		opts, err := modelOptions(c)
+		require.NoError(t, err)
+		assert.Len(t, opts, 2)
+	})
+
+	t.Run("rejects empty names", func(t *testing.T) {
+		c := newModelFlagsCmd(t)
+		require.NoError(t, c.Flags().Set("model-thinking", "  "))
+		_, err := modelOptions(c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --model-thinking: model name cannot be empty")
+	})
+}
+
+func TestModelOptions_ProviderEnv(t *testing.T) {
+	t.Run("adds an option without pinning models", func(t *testing.T) {
+		c := newModelFlagsCmd(t)
+		require.NoError(t, c.Flags().Set("provider-env", "ANTHROPIC_BASE_URL=http://localhost:8080"))
+		opts, err := modelOptions(c)
+		require.NoError(t, err)
+		assert.Len(t, opts, 1)
+	})
+
+	t.Run("rejects pairs without a key", func(t *testing.T) {
+		c := newModelFlagsCmd(t)
+		require.NoError(t, c.Flags().Set("provider-env", "=value"))
+		_, err := modelOptions(c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid --provider-env "=value": expected KEY=VALUE`)
+	})
+}
+
+func TestModelOptions_ProviderEnvOnRoot(t *testing.T) {
+	t.Cleanup(func() {
+		providerEnv = nil
+		f := rootCmd.Flags().Lookup("provider-env")
+		require.NoError(t, f.Value.(pflag.SliceValue).Replace(nil))
+		f.Changed = false
+	})
+
+	require.NoError(t, rootCmd.ParseFlags([]string{"--provider-env", "ANTHROPIC_BASE_URL=http://localhost:8080"}))
+	opts, err := modelOptions(rootCmd)
+	require.NoError(t, err)
+	assert.Len(t, opts, 1, "plain snap passes --provider-env to the provider")
+}
+
+func TestParseProviderEnv(t *testing.T) {
+	env, err := parseProviderEnv([]string{"A=1", "B=", "C=x=y", "A=2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "2", "B": "", "C": "x=y"}, env)
+
+	_, err = parseProviderEnv([]string{"NOVALUE"})
+	assert.Error(t, err)
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/yarlson/snap/internal/ui"
 )
 
+var newDescription string
+
 var newCmd = &cobra.Command{
 	Use:           "new <name>",
 	Short:         "Create a new named session",
@@ -19,13 +21,18 @@ var newCmd = &cobra.Command{
 }
 
 func init() {
+	newCmd.Flags().StringVar(&newDescription, "desc", "", "Short description shown in snap list")
 	rootCmd.AddCommand(newCmd)
 }
 
 func newRun(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
-	if err := session.Create(".", name); err != nil {
+	var opts []session.CreateOption
+	if newDescription != "" {
+		opts = append(opts, session.WithDescription(newDescription))
+	}
+	if err := session.Create(".", name, opts...); err != nil {
 		return err
 	}
 
@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/session"
 )
 
 // --- E2E tests ---
@@ -395,3 +397,21 @@ func TestNew_DuplicateSessionErrors(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "already exists")
 }
+
+func TestNew_WithDescription(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+
+	require.NoError(t, newCmd.Flags().Set("desc", "Login and token refresh"))
+	defer func() { require.NoError(t, newCmd.Flags().Set("desc", "")) }()
+
+	var outBuf strings.Builder
+	newCmd.SetOut(&outBuf)
+	defer newCmd.SetOut(nil)
+
+	require.NoError(t, newCmd.RunE(newCmd, []string{"auth"}))
+
+	meta, err := session.LoadMeta(".", "auth")
+	require.NoError(t, err)
+	assert.Equal(t, "Login and token refresh", meta.Description)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+var (
+	outputPath  string
+	eventsPath  string
+	metricsPath string
+
+	noColor     bool
+	asciiOutput bool
+	noEmoji     bool
+	outputWidth int
+)
+
+// addUIFlags registers the output style flags. They override the ui
+// preferences in a session's meta.json.
+func addUIFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable ANSI colors, as if NO_COLOR were set")
+	cmd.Flags().BoolVar(&asciiOutput, "ascii", false, "Draw ASCII symbols and box lines instead of Unicode ones")
+	cmd.Flags().BoolVar(&noEmoji, "no-emoji", false, "Leave emoji out of headers and boxes")
+	cmd.Flags().IntVar(&outputWidth, "width", 0, fmt.Sprintf("Width of separators and boxes (default %d, minimum %d)", ui.SeparatorWidth, ui.MinWidth))
+}
+
+// applyUIPrefs applies a session's ui preferences, with any output style
+// flag passed to cmd taking precedence. --no-color=false cancels the
+// session's no_color but does not turn colors on where NO_COLOR, a non-TTY
+// or --output turned them off.
+func applyUIPrefs(cmd *cobra.Command, prefs session.UIPrefs) {
+	if cmd.Flags().Changed("no-color") {
+		prefs.NoColor = noColor
+	}
+	if cmd.Flags().Changed("ascii") {
+		prefs.ASCII = asciiOutput
+	}
+	if cmd.Flags().Changed("no-emoji") {
+		prefs.NoEmoji = noEmoji
+	}
+	if cmd.Flags().Changed("width") {
+		prefs.Width = outputWidth
+	}
+
+	if prefs.NoColor {
+		ui.DisableColors()
+	}
+	ui.SetASCII(prefs.ASCII)
+	ui.SetEmoji(!prefs.NoEmoji)
+	ui.SetWidth(prefs.Width)
+}
+
+// openOutput resolves the --output flag. An empty path or "-" writes to
+// stdout. Any other path is opened for appending, colors are disabled as for
+// non-TTY output, and toFile is true so callers can turn off interactive UI.
+// The returned close function is always non-nil.
+func openOutput(path string) (w io.Writer, toFile bool, closeFn func() error, err error) {
+	if path == "" || path == "-" {
+		return os.Stdout, false, func() error { return nil }, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // G304: path is user-provided by design
+	if err != nil {
+		return nil, false, nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	ui.DisableColors()
+	return f, true, f.Close, nil
+}
+
+// openEvents resolves the --events flag. An empty path returns a nil writer
+// (no events); any other path is opened for appending. The returned close
+// function is always non-nil.
+func openEvents(path string) (w io.Writer, closeFn func() error, err error) {
+	if path == "" {
+		return nil, func() error { return nil }, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // G304: path is user-provided by design
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	return f, f.Close, nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+func TestOpenOutput_StdoutByDefault(t *testing.T) {
+	for _, path := range []string{"", "-"} {
+		w, toFile, closeFn, err := openOutput(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.Stdout, w)
+		assert.False(t, toFile)
+		assert.NoError(t, closeFn())
+	}
+}
+
+func TestOpenOutput_FileAppendsAndDisablesColors(t *testing.T) {
+	prev := ui.ColorsEnabled()
+	t.Cleanup(func() { ui.SetColorsEnabled(prev) })
+	t.Setenv("NO_COLOR", "")
+	ui.ResetColorMode()
+
+	path := filepath.Join(t.TempDir(), "snap.log")
+	require.NoError(t, os.WriteFile(path, []byte("previous run\n"), 0o600))
+
+	w, toFile, closeFn, err := openOutput(path)
+	require.NoError(t, err)
+	assert.True(t, toFile)
+
+	_, err = w.Write([]byte(ui.Step("Implementing")))
+	require.NoError(t, err)
+	require.NoError(t, closeFn())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "previous run\n")
+	assert.Contains(t, string(content), "Implementing")
+	assert.NotContains(t, string(content), "\x1b[", "colors should be disabled for file output")
+}
+
+func TestOpenOutput_InvalidPath(t *testing.T) {
+	_, _, _, err := openOutput(filepath.Join(t.TempDir(), "missing", "snap.log"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open output file")
+}
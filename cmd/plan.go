@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yarlson/tap"
@@ -21,7 +22,16 @@ import (
 	"github.com/yarlson/snap/internal/ui"
 )
 
-var fromFile string
+var (
+	fromFiles           []string
+	planMaxTurns        int
+	requirementsTimeout time.Duration
+	planAmend           bool
+	planWithTests       bool
+	planValidate        bool
+	planScript          string
+	requirementsPrompt  string
+)
 
 var planCmd = &cobra.Command{
 	Use:           "plan [session]",
@@ -34,58 +44,180 @@ var planCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(planCmd)
-	planCmd.Flags().StringVar(&fromFile, "from", "", "Input file to use instead of interactive requirements gathering")
+	planCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write planning output to a file instead of stdout (\"-\" for stdout)")
+	planCmd.Flags().BoolVar(&planAmend, "amend", false, "Add requirements to the session's existing plan, keeping unchanged task files")
+	planCmd.Flags().BoolVar(&planValidate, "validate", false, "Check the session's existing plan for missing documents and sections, without planning")
+	planCmd.Flags().StringArrayVar(&guardrails, "guardrail", nil, "Project rule the planned documents and tasks must respect (repeatable)")
+	planCmd.Flags().BoolVar(&jsonOutput, "json", false, "Write planning progress to stdout as JSON lines instead of text (requires --from)")
+	addPlanFlags(planCmd)
+	addModelFlags(planCmd)
 }
 
-func planRun(_ *cobra.Command, args []string) error {
-	sessionName, err := resolvePlanSession(args)
+// addPlanFlags registers the requirements and planning flags shared by plan
+// and ship.
+func addPlanFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&fromFiles, "from", nil, "Input file to use instead of interactive requirements gathering (repeatable)")
+	cmd.Flags().StringVar(&planScript, "script", "", "Read the requirements conversation from a script file (\"-\" for stdin), messages separated by --- lines")
+	cmd.Flags().IntVar(&planMaxTurns, "max-turns", 0, "Max requirements messages before generating documents (0 = unlimited)")
+	cmd.Flags().BoolVar(&planWithTests, "with-tests", false, "After generating tasks, write an acceptance test outline (TASK<N>.tests.md) for each")
+	cmd.Flags().StringVar(&requirementsPrompt, "requirements-prompt", "", "Use this file as the requirements-gathering prompt instead of the built-in one")
+	cmd.Flags().DurationVar(&requirementsTimeout, "requirements-timeout", 0, "Abort interactive requirements gathering after this long without input (0 = no timeout)")
+}
+
+func planRun(cmd *cobra.Command, args []string) error {
+	if planValidate {
+		return validatePlan(args)
+	}
+
+	sessionName, err := planSession(cmd, args)
 	if err != nil {
 		return err
 	}
 
+	if !jsonOutput {
+		fmt.Print("\n")
+		fmt.Print(ui.Info(fmt.Sprintf("Run: snap run %s", sessionName)))
+	}
+
+	return nil
+}
+
+// planSession runs the planner for the session resolved from args and returns
+// the name of the session that was planned, which may differ from args when
+// the user chooses to plan in a new session on conflict.
+func planSession(cmd *cobra.Command, args []string) (_ string, err error) {
+	// Anything that fails before the planner starts is a preflight failure.
+	started := false
+	defer func() {
+		if !started {
+			err = markPreflight(err)
+		}
+	}()
+
+	if err := validateGuardrails(); err != nil {
+		return "", err
+	}
+	if planScript != "" && len(fromFiles) > 0 {
+		return "", fmt.Errorf("--script cannot be combined with --from")
+	}
+	if jsonOutput {
+		// JSON mode owns stdout, so requirements can't be gathered from the user.
+		if len(fromFiles) == 0 {
+			return "", fmt.Errorf("--json requires --from")
+		}
+		if outputPath != "" {
+			return "", fmt.Errorf("--json cannot be combined with --output")
+		}
+	}
+	if requirementsPrompt != "" {
+		if len(fromFiles) > 0 || planAmend {
+			return "", fmt.Errorf("--requirements-prompt cannot be combined with --from or --amend")
+		}
+		// Catch an unreadable or blank prompt file before any provider call.
+		if _, err := plan.LoadRequirementsPrompt(requirementsPrompt); err != nil {
+			return "", err
+		}
+	}
+
+	sessionName, err := resolvePlanSession(args)
+	if err != nil {
+		return "", err
+	}
+
 	// Set up signal handling early so ctx is available for tap components.
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
 	go func() {
-		<-sigCh
-		cancel()
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
 	}()
 
-	// Conflict guard: check for existing planning artifacts.
-	isTTY := input.IsTerminal(os.Stdin)
-	sessionName, err = checkPlanConflict(ctx, sessionName, isTTY)
+	// Conflict guard: check for existing planning artifacts. Amending expects
+	// them, so it validates the existing plan instead.
+	if planAmend {
+		if err := validateAmend(sessionName); err != nil {
+			return "", err
+		}
+	} else {
+		isTTY := input.IsTerminal(os.Stdin) && !jsonOutput
+		sessionName, err = checkPlanConflict(ctx, sessionName, isTTY)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Pre-flight: resolve the provider CLI in PATH once; the executor reuses the path.
+	cfg, err := loadConfig(cmd)
 	if err != nil {
-		return err
+		return "", err
+	}
+	providerName, err := resolveProviderName(cfg)
+	if err != nil {
+		return "", err
+	}
+	providerPath, err := provider.ResolveCLI(providerName)
+	if err != nil {
+		return "", err
 	}
 
-	// Pre-flight: validate provider CLI is available in PATH.
-	providerName := provider.ResolveProviderName()
-	if err := provider.ValidateCLI(providerName); err != nil {
-		return err
+	out, toFile, closeOutput, err := openOutput(outputPath)
+	if err != nil {
+		return "", err
 	}
+	defer closeOutput() //nolint:errcheck // best-effort close of the output file
+
+	// The interactive chat shows replies on the terminal, so it is replaced by
+	// line-based stdin input when output goes to a file.
+	interactive := input.IsTerminal(os.Stdin) && !toFile && !jsonOutput
 
 	// Read --from file if specified.
 	var opts []plan.PlannerOption
-	var planOutput io.Writer = os.Stdout
-	if input.IsTerminal(os.Stdin) {
+	planOutput := out
+	switch {
+	case jsonOutput:
+		planOutput = io.Discard
+		opts = append(opts, plan.WithEvents(plan.JSONEvents(os.Stdout)))
+	case interactive:
 		planOutput = ui.NewSwitchWriter(os.Stdout, ui.WithLFToCRLF())
 	}
-	opts = append(opts, plan.WithOutput(planOutput), plan.WithInput(os.Stdin), plan.WithInteractive(input.IsTerminal(os.Stdin)))
+	opts = append(opts, plan.WithOutput(planOutput), plan.WithInput(os.Stdin), plan.WithInteractive(interactive),
+		plan.WithMaxTurns(planMaxTurns), plan.WithRequirementTimeout(requirementsTimeout),
+		plan.WithRequirementsPrompt(requirementsPrompt), plan.WithGuardrails(guardrails))
+
+	if len(fromFiles) > 0 {
+		briefs := make([]plan.Brief, 0, len(fromFiles))
+		for _, f := range fromFiles {
+			content, err := os.ReadFile(f)
+			if err != nil {
+				return "", fmt.Errorf("failed to read input file: %w", err)
+			}
+			briefs = append(briefs, plan.Brief{Name: filepath.Base(f), Content: string(content)})
+		}
+		opts = append(opts, plan.WithBriefs(briefs))
+	}
 
-	if fromFile != "" {
-		content, err := os.ReadFile(fromFile)
+	if planScript != "" {
+		turns, err := readPlanScript(planScript)
 		if err != nil {
-			return fmt.Errorf("failed to read input file: %w", err)
+			return "", err
 		}
-		opts = append(opts, plan.WithBrief(filepath.Base(fromFile), string(content)))
+		opts = append(opts, plan.WithScript(turns))
 	}
 
-	executor, err := provider.NewExecutorFromEnv()
+	modelOpts, err := modelOptions(cmd)
 	if err != nil {
-		return err
+		return "", err
+	}
+	executor, err := provider.NewExecutor(providerName, providerPath, modelOpts...)
+	if err != nil {
+		return "", err
 	}
 
 	td := session.TasksDir(".", sessionName)
@@ -95,6 +227,8 @@ func planRun(_ *cobra.Command, args []string) error {
 	resumePlan := session.HasPlanHistory(".", sessionName)
 	opts = append(opts,
 		plan.WithResume(resumePlan),
+		plan.WithAmend(planAmend),
+		plan.WithTests(planWithTests),
 		plan.WithAfterFirstMessage(func() error {
 			return session.MarkPlanStarted(".", sessionName)
 		}),
@@ -102,21 +236,32 @@ func planRun(_ *cobra.Command, args []string) error {
 
 	planner := plan.NewPlanner(executor, sessionName, td, opts...)
 
+	started = true
 	if err := planner.Run(ctx); err != nil {
 		if ctx.Err() != nil {
 			// Signal-initiated cancellation — planner already printed abort message.
-			return ctx.Err()
+			return "", ctx.Err()
 		}
-		return err
+		return "", err
 	}
 
 	// Print file listing after completion.
 	printFileListing(planOutput, td)
 
-	fmt.Print("\n")
-	fmt.Print(ui.Info(fmt.Sprintf("Run: snap run %s", sessionName)))
+	return sessionName, nil
+}
 
-	return nil
+// readPlanScript parses the requirements script at path, or stdin for "-".
+func readPlanScript(path string) ([]string, error) {
+	if path == "-" {
+		return plan.ParseScript(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only file
+	return plan.ParseScript(f)
 }
 
 // resolvePlanSession resolves the session name for the plan command.
@@ -159,6 +304,49 @@ func formatMultiplePlanSessionsError(sessions []session.Info) error {
 	return fmt.Errorf("%s", b.String())
 }
 
+// validateAmend checks that a session has a plan to amend: planning
+// artifacts to update and a planning conversation to continue.
+func validateAmend(sessionName string) error {
+	if len(fromFiles) > 0 {
+		return fmt.Errorf("--amend cannot be combined with --from")
+	}
+	if !session.HasArtifacts(".", sessionName) {
+		return fmt.Errorf("session %q has no plan to amend\n\nTo plan it:\n  snap plan %s", sessionName, sessionName)
+	}
+	if !session.HasPlanHistory(".", sessionName) {
+		return fmt.Errorf("session %q has no planning conversation to continue\n\n"+
+			"To re-plan, clean up first:\n  snap delete %s && snap new %s", sessionName, sessionName, sessionName)
+	}
+	return nil
+}
+
+// validatePlan checks an existing plan for missing documents and sections,
+// reporting each problem. It fails when the plan is incomplete so scripts
+// can gate on it.
+func validatePlan(args []string) error {
+	if len(fromFiles) > 0 || planAmend || planScript != "" {
+		return fmt.Errorf("--validate cannot be combined with --from, --amend or --script")
+	}
+	sessionName, err := resolvePlanSession(args)
+	if err != nil {
+		return err
+	}
+	if !session.HasArtifacts(".", sessionName) {
+		return fmt.Errorf("session %q has no plan to validate\n\nTo plan it:\n  snap plan %s", sessionName, sessionName)
+	}
+
+	warnings, err := plan.Validate(session.TasksDir(".", sessionName))
+	if err != nil {
+		return fmt.Errorf("failed to validate plan: %w", err)
+	}
+	if len(warnings) == 0 {
+		fmt.Println(ui.Success(fmt.Sprintf("Plan for session %q is complete", sessionName)))
+		return nil
+	}
+	plan.ReportWarnings(os.Stdout, sessionName, warnings)
+	return fmt.Errorf("plan for session %q is incomplete", sessionName)
+}
+
 // checkPlanConflict detects existing planning artifacts and either prompts
 // the user (TTY) or returns an error (non-TTY). Returns the session name to
 // proceed with, or an error to abort.
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -136,6 +137,13 @@ func TestE2E_CUJ2_PlanInteractive(t *testing.T) {
 	markerPath := filepath.Join(projectDir, ".snap", "sessions", "auth", ".plan-started")
 	_, err = os.Stat(markerPath)
 	assert.NoError(t, err, ".plan-started marker should exist")
+
+	// Assert the manifest summarizes the generated task list.
+	manifest, err := planpkg.LoadManifest(tasksDir)
+	require.NoError(t, err)
+	require.Len(t, manifest.Tasks, 2)
+	assert.Equal(t, "Task zero", manifest.Tasks[0].Name)
+	assert.Contains(t, outputStr, "manifest.json")
 }
 
 // CUJ-2: Plan and Implement — with file (--from).
@@ -185,6 +193,57 @@ func TestE2E_CUJ2_PlanWithFile(t *testing.T) {
 	assert.Contains(t, outputStr, "Planning complete")
 }
 
+// Test: snap plan --json writes only NDJSON progress events to stdout.
+func TestE2E_PlanJSON(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "new", "auth")
+	create.Dir = projectDir
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap new failed: %s", out)
+
+	tasksDir := filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks")
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "brief.md"), []byte("I want OAuth2 authentication"), 0o600))
+	mockPath := mockPlanProvider(t)
+
+	// Without --from there is no way to gather requirements.
+	plan := exec.CommandContext(ctx, binPath, "plan", "auth", "--json")
+	plan.Dir = projectDir
+	plan.Env = append(os.Environ(), "PATH="+mockPath)
+	output, planErr := plan.CombinedOutput()
+	require.Error(t, planErr)
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, planErr, &exitErr)
+	assert.Equal(t, exitPreflight, exitErr.ExitCode())
+	assert.Contains(t, string(output), "--json requires --from")
+
+	plan = exec.CommandContext(ctx, binPath, "plan", "auth", "--from", "brief.md", "--json")
+	plan.Dir = projectDir
+	plan.Env = append(os.Environ(), "PATH="+mockPath, "MOCK_TASKS_DIR="+tasksDir)
+	stdout, planErr := plan.Output()
+	require.NoError(t, planErr, "snap plan --json failed: %s", stdout)
+
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+	var types []string
+	for _, line := range lines {
+		var e planpkg.Event
+		require.NoError(t, json.Unmarshal([]byte(line), &e), "not a JSON event: %q", line)
+		assert.Equal(t, "auth", e.Session)
+		types = append(types, string(e.Type))
+	}
+	require.NotEmpty(t, types)
+	assert.Equal(t, "plan_start", types[0])
+	assert.Equal(t, "plan_done", types[len(types)-1])
+	assert.Contains(t, types, "substep_done")
+	assert.NotContains(t, string(stdout), "Planning complete")
+}
+
 // Test: snap plan with nonexistent session.
 func TestE2E_PlanNonexistentSession(t *testing.T) {
 	if testing.Short() {
@@ -349,6 +408,117 @@ func TestE2E_PlanConflictNonTTY(t *testing.T) {
 	assert.Contains(t, outputStr, "snap plan")
 }
 
+func TestE2E_PlanAmend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "new", "auth")
+	create.Dir = projectDir
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap new failed: %s", out)
+
+	// An existing plan: artifacts plus the planning conversation marker.
+	sessDir := filepath.Join(projectDir, ".snap", "sessions", "auth")
+	require.NoError(t, os.WriteFile(filepath.Join(sessDir, "tasks", "PRD.md"), []byte("# PRD\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(sessDir, "tasks", "TASK1.md"), []byte("# Task 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(sessDir, ".plan-started"), nil, 0o600))
+
+	plan := exec.CommandContext(ctx, binPath, "plan", "auth", "--amend")
+	plan.Dir = projectDir
+	plan.Env = append(os.Environ(), "PATH="+mockPlanProvider(t))
+	plan.Stdin = strings.NewReader("add rate limiting\n/done\n")
+
+	output, planErr := plan.CombinedOutput()
+	require.NoError(t, planErr, "snap plan --amend failed: %s", output)
+
+	outputStr := string(output)
+	assert.Contains(t, outputStr, "Amending plan for session 'auth'")
+	assert.NotContains(t, outputStr, "already has planning artifacts", "amend skips the conflict guard")
+	assert.Contains(t, outputStr, "Task files: 1 unchanged")
+	assert.FileExists(t, filepath.Join(sessDir, "tasks", "TASK1.md"))
+}
+
+// Test: snap plan --script - reads a multi-turn requirements script from stdin.
+func TestE2E_PlanScriptFromStdin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "new", "auth")
+	create.Dir = projectDir
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap new failed: %s", out)
+
+	tasksDir := filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks")
+
+	plan := exec.CommandContext(ctx, binPath, "plan", "auth", "--script", "-")
+	plan.Dir = projectDir
+	plan.Env = append(os.Environ(), "PATH="+mockPlanProvider(t), "MOCK_TASKS_DIR="+tasksDir)
+	plan.Stdin = strings.NewReader("Add auth\nwith JWT sessions\n---\nUsers are admins\n/done\n")
+
+	output, planErr := plan.CombinedOutput()
+	require.NoError(t, planErr, "snap plan --script failed: %s", output)
+
+	outputStr := string(output)
+	assert.Contains(t, outputStr, "2 scripted message(s)")
+	assert.Contains(t, outputStr, "snap plan> Add auth\nwith JWT sessions")
+	assert.Contains(t, outputStr, "Planning complete")
+}
+
+// Test: snap plan --requirements-prompt sends the custom prompt first and
+// rejects a blank prompt file before calling the provider.
+func TestE2E_PlanCustomRequirementsPrompt(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "new", "auth")
+	create.Dir = projectDir
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap new failed: %s", out)
+
+	blank := filepath.Join(projectDir, "blank.md")
+	require.NoError(t, os.WriteFile(blank, []byte("\n"), 0o600))
+
+	plan := exec.CommandContext(ctx, binPath, "plan", "auth", "--requirements-prompt", blank)
+	plan.Dir = projectDir
+	plan.Env = append(os.Environ(), "PATH="+mockPlanProvider(t))
+	plan.Stdin = strings.NewReader("/done\n")
+
+	output, planErr := plan.CombinedOutput()
+	require.Error(t, planErr)
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, planErr, &exitErr)
+	assert.Equal(t, exitPreflight, exitErr.ExitCode())
+	assert.Contains(t, string(output), "is empty")
+
+	custom := filepath.Join(projectDir, "mobile.md")
+	require.NoError(t, os.WriteFile(custom, []byte("Ask about mobile platforms."), 0o600))
+	tasksDir := filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks")
+
+	plan = exec.CommandContext(ctx, binPath, "plan", "auth", "--requirements-prompt", custom)
+	plan.Dir = projectDir
+	plan.Env = append(os.Environ(), "PATH="+mockPlanProvider(t), "MOCK_TASKS_DIR="+tasksDir)
+	plan.Stdin = strings.NewReader("/done\n")
+
+	output, planErr = plan.CombinedOutput()
+	require.NoError(t, planErr, "snap plan --requirements-prompt failed: %s", output)
+	assert.Contains(t, string(output), "Planning complete")
+}
+
 // CUJ-1: Plan CLI Feature with UI Contract — verifies planning prompts contain UI task sections.
 func TestPlanE2E_UIContract(t *testing.T) {
 	if testing.Short() {
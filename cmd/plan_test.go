@@ -73,6 +73,89 @@ func TestResolvePlanSession_MultipleSessions_Errors(t *testing.T) {
 
 // --- Integration tests: checkPlanConflict ---
 
+func TestValidateAmend(t *testing.T) {
+	tests := []struct {
+		name     string
+		artifact bool
+		history  bool
+		from     []string
+		wantErr  string
+	}{
+		{name: "planned session", artifact: true, history: true},
+		{name: "no artifacts", history: true, wantErr: "has no plan to amend"},
+		{name: "no planning conversation", artifact: true, wantErr: "no planning conversation to continue"},
+		{name: "combined with --from", artifact: true, history: true, from: []string{"brief.md"}, wantErr: "--amend cannot be combined with --from"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			chdir(t, projectDir)
+			require.NoError(t, session.Create(".", "auth"))
+			if tt.artifact {
+				require.NoError(t, os.WriteFile(filepath.Join(session.TasksDir(".", "auth"), "PRD.md"), []byte("# PRD\n"), 0o600))
+			}
+			if tt.history {
+				require.NoError(t, session.MarkPlanStarted(".", "auth"))
+			}
+			fromFiles = tt.from
+			t.Cleanup(func() { fromFiles = nil })
+
+			err := validateAmend("auth")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidatePlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		amend   bool
+		wantErr string
+	}{
+		{
+			name: "complete plan",
+			files: map[string]string{
+				"PRD.md":        "# PRD\n\n## Goals\n\n## Requirements\n",
+				"TECHNOLOGY.md": "# Technology\n\n## Architecture\n\n## Testing strategy\n",
+				"DESIGN.md":     "# Design\n\n## Voice & tone\n\n## Terminology\n",
+				"TASKS.md":      "# Tasks\n\n## G. Task List\n\n| File | Name |\n|---|---|\n| TASK1.md | Login |\n\n## H. Dependency Graph\n",
+				"TASK1.md":      "# Task 1\n",
+			},
+		},
+		{name: "incomplete plan", files: map[string]string{"PRD.md": "# PRD\n"}, wantErr: `plan for session "auth" is incomplete`},
+		{name: "no plan", wantErr: "has no plan to validate"},
+		{name: "combined with --amend", amend: true, wantErr: "--validate cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			chdir(t, projectDir)
+			require.NoError(t, session.Create(".", "auth"))
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(session.TasksDir(".", "auth"), name), []byte(content), 0o600))
+			}
+			planAmend = tt.amend
+			t.Cleanup(func() { planAmend = false })
+
+			err := validatePlan([]string{"auth"})
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestCheckPlanConflict_EmptySession_NoPrompt(t *testing.T) {
 	projectDir := t.TempDir()
 	chdir(t, projectDir)
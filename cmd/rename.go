@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/session"
+)
+
+var renameCmd = &cobra.Command{
+	Use:           "rename <old> <new>",
+	Short:         "Rename a session, keeping its tasks and progress",
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          renameRun,
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+}
+
+func renameRun(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+
+	if err := session.Rename(".", oldName, newName); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Renamed session '%s' to '%s'\n", oldName, newName)
+	return nil
+}
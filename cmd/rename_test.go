@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/session"
+)
+
+func TestRename(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+	require.NoError(t, session.Create(".", "auth"))
+	require.NoError(t, os.WriteFile(filepath.Join(session.TasksDir(".", "auth"), "TASK1.md"), []byte("# Task\n"), 0o600))
+
+	var outBuf strings.Builder
+	renameCmd.SetOut(&outBuf)
+	defer renameCmd.SetOut(nil)
+
+	require.NoError(t, renameCmd.RunE(renameCmd, []string{"auth", "login"}))
+	assert.Contains(t, outBuf.String(), "Renamed session 'auth' to 'login'")
+	assert.False(t, session.Exists(".", "auth"))
+	assert.FileExists(t, filepath.Join(session.TasksDir(".", "login"), "TASK1.md"))
+
+	err := renameCmd.RunE(renameCmd, []string{"login", "../escape"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid session name")
+	assert.True(t, session.Exists(".", "login"))
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/tap"
+
+	"github.com/yarlson/snap/internal/input"
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+var restoreForce bool
+
+// restoreInteractive reports whether restore can show the snapshot picker.
+// Tests replace it to drive the prompt.
+var restoreInteractive = func() bool { return input.IsTerminal(os.Stdin) }
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [ref]",
+	Short: "Restore the working tree from a step snapshot",
+	Long: `Apply a step snapshot to the working tree. ref is a stash ref from
+snap snapshot list (e.g. stash@{2}); without one, pick a snapshot from the list.
+
+The working tree must be clean: pass --force to apply the snapshot on top of
+uncommitted changes. The snapshot stays in the stash.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          restoreRun,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().BoolVar(&restoreForce, "force", false, "Apply the snapshot even though the working tree has uncommitted changes")
+}
+
+func restoreRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	out := cmd.OutOrStdout()
+	snapshotter := snapshot.New(".")
+
+	clean, err := snapshotter.Clean(ctx)
+	if err != nil {
+		return err
+	}
+	if !clean {
+		if !restoreForce {
+			return markPreflight(errors.New("working tree has uncommitted changes; commit or stash them first, or pass --force to apply the snapshot on top"))
+		}
+		fmt.Fprint(out, ui.Interrupted("Working tree has uncommitted changes; applying the snapshot on top"))
+	}
+
+	entries, err := snapshotter.List(ctx, snapshot.Filter{})
+	if err != nil {
+		return err
+	}
+
+	var ref string
+	if len(args) > 0 {
+		ref = args[0]
+	} else {
+		if len(entries) == 0 {
+			fmt.Fprint(out, ui.Info("No snapshots found"))
+			return nil
+		}
+		if !restoreInteractive() {
+			return markPreflight(errors.New("no snapshot given: pass a ref from snap snapshot list, e.g. snap restore stash@{0}"))
+		}
+		if ref = pickSnapshot(ctx, entries); ref == "" {
+			return nil
+		}
+	}
+
+	if err := snapshotter.Restore(ctx, ref); err != nil {
+		if errors.Is(err, snapshot.ErrSnapshotNotFound) {
+			return fmt.Errorf("%w; list snapshots with: snap snapshot list", err)
+		}
+		return err
+	}
+
+	for _, e := range entries {
+		if e.Ref == ref {
+			fmt.Fprint(out, ui.Info(fmt.Sprintf("Restored %s: %s step %d/%d — %s", ref, e.Label.TaskID, e.Label.Step, e.Label.Total, e.Label.Name)))
+			return nil
+		}
+	}
+	fmt.Fprint(out, ui.Info(fmt.Sprintf("Restored %s", ref)))
+	return nil
+}
+
+// pickSnapshot asks which snapshot to restore, newest first. Returns "" when
+// the prompt is cancelled.
+func pickSnapshot(ctx context.Context, entries []snapshot.Entry) string {
+	options := make([]tap.SelectOption[string], len(entries))
+	for i, e := range entries {
+		options[i] = tap.SelectOption[string]{
+			Value: e.Ref,
+			Label: fmt.Sprintf("%s  %s  %s step %d/%d — %s", e.Ref, e.Time.Format("2006-01-02 15:04"),
+				e.Label.TaskID, e.Label.Step, e.Label.Total, e.Label.Name),
+		}
+	}
+	choice := tap.Select(ctx, tap.SelectOptions[string]{
+		Message: "Restore which snapshot?",
+		Options: options,
+	})
+	if ctx.Err() != nil {
+		return ""
+	}
+	return choice
+}
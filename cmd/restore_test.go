@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yarlson/tap"
+
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// initSnapshotRepo creates a clean repo in a temp dir, changes into it, and
+// captures two snapshots: TASK1 step 1 (stash@{1}) and TASK2 step 2
+// (stash@{0}), each writing the task ID to README.md.
+func initSnapshotRepo(t *testing.T) string {
+	t.Helper()
+	projectDir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		c := exec.CommandContext(context.Background(), "git", args...)
+		c.Dir = projectDir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	git("init")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte("# init"), 0o600))
+	git("add", ".")
+	git("commit", "-m", "initial commit")
+
+	s := snapshot.New(projectDir)
+	for i, task := range []string{"TASK1", "TASK2"} {
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte(task), 0o600))
+		_, err := s.Capture(context.Background(), snapshot.Label{TaskID: task, Step: i + 1, Total: 10, Name: "Implement"}.String())
+		require.NoError(t, err)
+	}
+	git("checkout", "--", ".")
+
+	chdir(t, projectDir)
+	return projectDir
+}
+
+// setRestoreForce sets --force for one test and restores it after.
+func setRestoreForce(t *testing.T, force bool) {
+	t.Helper()
+	restoreForce = force
+	t.Cleanup(func() { restoreForce = false })
+}
+
+func readme(t *testing.T, projectDir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(projectDir, "README.md"))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRestore_ByRef(t *testing.T) {
+	projectDir := initSnapshotRepo(t)
+
+	var outBuf strings.Builder
+	restoreCmd.SetOut(&outBuf)
+	defer restoreCmd.SetOut(nil)
+
+	require.NoError(t, restoreCmd.RunE(restoreCmd, []string{"stash@{1}"}))
+	assert.Equal(t, "TASK1", readme(t, projectDir))
+	assert.Contains(t, ui.StripColors(outBuf.String()), "Restored stash@{1}: TASK1 step 1/10 — Implement")
+}
+
+func TestRestore_DirtyTreeRequiresForce(t *testing.T) {
+	projectDir := initSnapshotRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "notes.txt"), []byte("wip"), 0o600))
+
+	var outBuf strings.Builder
+	restoreCmd.SetOut(&outBuf)
+	defer restoreCmd.SetOut(nil)
+
+	err := restoreCmd.RunE(restoreCmd, []string{"stash@{0}"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPreflight)
+	assert.Contains(t, err.Error(), "pass --force")
+	assert.Equal(t, "# init", readme(t, projectDir))
+
+	setRestoreForce(t, true)
+	require.NoError(t, restoreCmd.RunE(restoreCmd, []string{"stash@{0}"}))
+	assert.Equal(t, "TASK2", readme(t, projectDir))
+	assert.FileExists(t, filepath.Join(projectDir, "notes.txt"))
+	assert.Contains(t, ui.StripColors(outBuf.String()), "applying the snapshot on top")
+}
+
+func TestRestore_MissingSnapshot(t *testing.T) {
+	initSnapshotRepo(t)
+
+	restoreCmd.SetOut(&strings.Builder{})
+	defer restoreCmd.SetOut(nil)
+
+	err := restoreCmd.RunE(restoreCmd, []string{"stash@{7}"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, snapshot.ErrSnapshotNotFound)
+	assert.Equal(t, "restore stash@{7}: snapshot not found; list snapshots with: snap snapshot list", err.Error())
+}
+
+func TestRestore_NonInteractiveRequiresRef(t *testing.T) {
+	initSnapshotRepo(t)
+
+	orig := restoreInteractive
+	restoreInteractive = func() bool { return false }
+	defer func() { restoreInteractive = orig }()
+
+	err := restoreCmd.RunE(restoreCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no snapshot given")
+}
+
+func TestRestore_Picker(t *testing.T) {
+	projectDir := initSnapshotRepo(t)
+
+	orig := restoreInteractive
+	restoreInteractive = func() bool { return true }
+	defer func() { restoreInteractive = orig }()
+
+	in := tap.NewMockReadable()
+	out := tap.NewMockWritable()
+	tap.SetTermIO(in, out)
+	defer tap.SetTermIO(nil, nil)
+
+	restoreCmd.SetOut(&strings.Builder{})
+	defer restoreCmd.SetOut(nil)
+
+	resultCh := make(chan error, 1)
+	go func() {
+		resultCh <- restoreCmd.RunE(restoreCmd, nil)
+	}()
+
+	// The newest snapshot is pre-selected; just press Enter.
+	time.Sleep(200 * time.Millisecond)
+	in.EmitKeypress("", tap.Key{Name: "return"})
+
+	select {
+	case err := <-resultCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+	assert.Equal(t, "TASK2", readme(t, projectDir))
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+var resumeCmd = &cobra.Command{
+	Use:           "resume [session]",
+	Short:         "Continue an interrupted task; fails if there is nothing to resume",
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          resumeRun,
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+
+	resumeCmd.Flags().IntVar(&resumeStep, "step", 0, "Resume the active task at this step instead of the saved one")
+	resumeCmd.Flags().StringArrayVar(&guardrails, "guardrail", nil, "Project rule the implement and code-review steps enforce (repeatable)")
+	resumeCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write workflow output to a file instead of stdout (\"-\" for stdout)")
+	addRunFlags(resumeCmd)
+	addModelFlags(resumeCmd)
+	addUIFlags(resumeCmd)
+}
+
+// resumeRun continues the active task of the session, unlike run, which
+// starts the next task when nothing is in progress.
+func resumeRun(cmd *cobra.Command, args []string) error {
+	if err := validateStepFlag(cmd); err != nil {
+		return err
+	}
+	return runWorkflow(cmd, args, &resumeRequest{step: resumeStep})
+}
+
+// validateStepFlag rejects an explicit --step 0; other out-of-range steps are
+// checked against the saved run's step list when its state is loaded.
+func validateStepFlag(cmd *cobra.Command) error {
+	if !cmd.Flags().Changed("step") || resumeStep != 0 {
+		return nil
+	}
+	_, steps, err := resolveSteps()
+	if err != nil {
+		return markPreflight(err)
+	}
+	return markPreflight(fmt.Errorf("invalid --step: must be between 1 and %d", workflow.StepCount(steps)))
+}
+
+// resolveResumeTarget loads the run's state and returns the task and step it
+// resumes from, or an error when there is no interrupted work. configured is
+// the step list before --skip-step (nil for the built-in steps).
+func resolveResumeTarget(rc *runConfig, configured []workflow.StepDef, step int) (*workflow.ResumeTarget, error) {
+	if !rc.stateManager.Exists() {
+		return nil, nothingToResume(rc)
+	}
+	workflowState, err := rc.stateManager.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	if step == 0 {
+		if err := workflow.RemapSkippedStep(workflowState, configured, skipSteps); err != nil {
+			return nil, fmt.Errorf("cannot resume: %w", err)
+		}
+	}
+	steps, _, err := workflow.SkipSteps(configured, skipSteps)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --skip-step: %w", err)
+	}
+	target, err := workflow.ResolveResume(workflowState, rc.tasksDir, rc.taskFile, tasksGlob, steps, step)
+	if errors.Is(err, workflow.ErrNothingToResume) {
+		return nil, nothingToResume(rc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot resume: %w", err)
+	}
+	return target, nil
+}
+
+func nothingToResume(rc *runConfig) error {
+	return fmt.Errorf("%w in %s; start new work with 'snap run'", workflow.ErrNothingToResume, rc.displayName)
+}
+
+func resumeStepFor(resume *resumeRequest) int {
+	if resume == nil {
+		return 0
+	}
+	return resume.step
+}
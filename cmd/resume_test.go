@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+// writeSessionState creates session name with TASK1.md and the given state.json.
+func writeSessionState(t *testing.T, projectDir, name, stateJSON string) {
+	t.Helper()
+	sessDir := filepath.Join(projectDir, ".snap", "sessions", name)
+	td := filepath.Join(sessDir, "tasks")
+	require.NoError(t, os.MkdirAll(td, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(td, "TASK1.md"), []byte("# Task 1\n"), 0o600))
+	if stateJSON != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(sessDir, "state.json"), []byte(stateJSON), 0o600))
+	}
+}
+
+const activeStateJSON = `{
+	"tasks_dir": ".snap/sessions/auth/tasks",
+	"current_task_id": "TASK1",
+	"current_task_file": "TASK1.md",
+	"current_step": 5,
+	"total_steps": 10,
+	"completed_task_ids": [],
+	"session_id": "",
+	"last_updated": "2025-01-01T00:00:00Z",
+	"prd_path": ".snap/sessions/auth/tasks/PRD.md"
+}`
+
+func TestResolveResumeTarget(t *testing.T) {
+	t.Run("returns saved task and step", func(t *testing.T) {
+		projectDir := t.TempDir()
+		chdir(t, projectDir)
+		writeSessionState(t, projectDir, "auth", activeStateJSON)
+
+		rc, err := resolveRunConfig("auth", "docs/tasks", "", "")
+		require.NoError(t, err)
+
+		target, err := resolveResumeTarget(rc, nil, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "TASK1", target.TaskID)
+		assert.Equal(t, 5, target.Step)
+	})
+
+	t.Run("applies requested step", func(t *testing.T) {
+		projectDir := t.TempDir()
+		chdir(t, projectDir)
+		writeSessionState(t, projectDir, "auth", activeStateJSON)
+
+		rc, err := resolveRunConfig("auth", "docs/tasks", "", "")
+		require.NoError(t, err)
+
+		target, err := resolveResumeTarget(rc, nil, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 2, target.Step)
+
+		_, err = resolveResumeTarget(rc, nil, 99)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid step 99")
+	})
+
+	t.Run("refuses without state", func(t *testing.T) {
+		projectDir := t.TempDir()
+		chdir(t, projectDir)
+		writeSessionState(t, projectDir, "auth", "")
+
+		rc, err := resolveRunConfig("auth", "docs/tasks", "", "")
+		require.NoError(t, err)
+
+		_, err = resolveResumeTarget(rc, nil, 0)
+		require.ErrorIs(t, err, workflow.ErrNothingToResume)
+		assert.Contains(t, err.Error(), "snap run")
+	})
+
+	t.Run("refuses when no task is active", func(t *testing.T) {
+		projectDir := t.TempDir()
+		chdir(t, projectDir)
+		writeSessionState(t, projectDir, "auth", `{"tasks_dir": ".snap/sessions/auth/tasks", "current_step": 1, "total_steps": 10, "completed_task_ids": ["TASK1"]}`)
+
+		rc, err := resolveRunConfig("auth", "docs/tasks", "", "")
+		require.NoError(t, err)
+
+		_, err = resolveResumeTarget(rc, nil, 0)
+		assert.ErrorIs(t, err, workflow.ErrNothingToResume)
+	})
+}
+
+func TestE2E_ResumeNothingToResume(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	writeSessionState(t, projectDir, "auth", "")
+
+	mockPath := createMockProvider(t, "#!/bin/sh\nexit 0\n")
+
+	cmd := exec.CommandContext(context.Background(), binPath, "resume", "auth")
+	cmd.Dir = projectDir
+	cmd.Env = append(os.Environ(), "PATH="+mockPath)
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), "expected non-zero exit, got %v: %s", err, output)
+	assert.Equal(t, exitPreflight, exitErr.ExitCode())
+	assert.Contains(t, string(output), "nothing to resume in auth")
+}
+
+func TestE2E_RunStep(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+
+	t.Run("restarts the interrupted task", func(t *testing.T) {
+		projectDir := t.TempDir()
+		writeSessionState(t, projectDir, "auth", activeStateJSON)
+		mockPath := createMockProvider(t, "#!/bin/sh\nexec /bin/sleep 3600\n")
+
+		run := exec.CommandContext(context.Background(), binPath, "run", "auth", "--step", "2")
+		run.Dir = projectDir
+		run.Env = append(os.Environ(), "PATH="+mockPath)
+		var combinedOut strings.Builder
+		run.Stdout = &combinedOut
+		run.Stderr = &combinedOut
+
+		require.NoError(t, run.Start())
+		time.Sleep(2 * time.Second)
+		require.NoError(t, run.Process.Signal(syscall.SIGINT))
+		//nolint:errcheck // expect non-zero exit from SIGINT
+		_ = run.Wait()
+
+		assert.Contains(t, combinedOut.String(), "TASK1: restarting from step 2/10: Ensure completeness")
+	})
+
+	for _, tc := range []struct {
+		name  string
+		state string
+		args  []string
+		want  string
+	}{
+		{"no interrupted task", "", []string{"--step", "2"}, "--step needs an interrupted task to restart: nothing to resume in auth"},
+		{"with fresh", activeStateJSON, []string{"--step", "2", "--fresh"}, "cannot be combined with --fresh"},
+		{"step zero", activeStateJSON, []string{"--step", "0"}, "invalid --step: must be between 1 and 10"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			writeSessionState(t, projectDir, "auth", tc.state)
+			mockPath := createMockProvider(t, "#!/bin/sh\nexit 0\n")
+
+			cmd := exec.CommandContext(context.Background(), binPath, append([]string{"run", "auth"}, tc.args...)...)
+			cmd.Dir = projectDir
+			cmd.Env = append(os.Environ(), "PATH="+mockPath)
+			output, err := cmd.CombinedOutput()
+
+			var exitErr *exec.ExitError
+			require.True(t, errors.As(err, &exitErr), "expected non-zero exit, got %v: %s", err, output)
+			assert.Equal(t, exitPreflight, exitErr.ExitCode())
+			assert.Contains(t, string(output), tc.want)
+		})
+	}
+}
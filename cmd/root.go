@@ -1,12 +1,13 @@
 package cmd
 
 import (
-	"context"
-	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Version is set at build time via ldflags:
@@ -18,9 +19,45 @@ var (
 	tasksDir   string
 	prdPath    string
 	taskFile   string
+	onlyTask   string
+	tasksGlob  string
 	freshStart bool
 	showState  bool
 	jsonOutput bool
+	noDescribe bool
+
+	confirmCommits bool
+	pauseTasks     bool
+	signCommits    bool
+	strictCommits  bool
+	showDiff       bool
+	explainSteps   bool
+	scopePath      string
+	baseSHA        string
+	sessionMemory  bool
+	changelogPath  string
+	providerStderr string
+	snapshotMode   string
+	failFast       bool
+	isolateCIFix   bool
+	prPerTask      bool
+	pushRemote     string
+	prRemote       string
+	ciFixFallback  []string
+	guardrails     []string
+	skipSteps      []string
+
+	queueInterval time.Duration
+	idleTimeout   time.Duration
+	stepTimeout   time.Duration
+	stepRetries   int
+	maxIterations int
+	keepSnapshots int
+	parallelTasks int
+	retryBackoff  time.Duration
+	resumeStep    int
+
+	repoPath string
 )
 
 var rootCmd = &cobra.Command{
@@ -38,30 +75,112 @@ var rootCmd = &cobra.Command{
 - Updates project context
 
 Runs continuously until interrupted with Ctrl+C.`,
-	RunE: run,
+	PersistentPreRunE: enterRepo,
+	RunE:              run,
 }
 
 func init() {
 	rootCmd.Version = Version
 	rootCmd.SetVersionTemplate("snap {{.Version}}\n")
 
+	// Flag parse errors are preflight failures in every subcommand.
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return markPreflight(err)
+	})
+
+	rootCmd.PersistentFlags().StringVar(&repoPath, "repo", "", "Run against the repository at this path instead of the current directory")
 	rootCmd.PersistentFlags().StringVarP(&tasksDir, "tasks-dir", "d", "docs/tasks", "Directory containing PRD and task files")
-	rootCmd.Flags().StringVar(&taskFile, "task-file", "", "Path to a single task file to run")
+	rootCmd.Flags().StringVar(&onlyTask, "only-task", "", "Run only this task (e.g. TASK3), even if completed, then stop")
 	rootCmd.Flags().StringVarP(&prdPath, "prd", "p", "", "Path to PRD file (default: <tasks-dir>/PRD.md)")
 	rootCmd.Flags().BoolVar(&freshStart, "fresh", false, "Force fresh start, ignore existing state")
+	rootCmd.Flags().IntVar(&resumeStep, "step", 0, "Restart the interrupted task from this step instead of the saved one")
+	rootCmd.Flags().StringArrayVar(&guardrails, "guardrail", nil, "Project rule the implement and code-review steps enforce (repeatable)")
+	rootCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write workflow output to a file instead of stdout (\"-\" for stdout)")
 	rootCmd.Flags().BoolVar(&showState, "show-state", false, "Show current state and exit")
 	rootCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output raw JSON (only with --show-state)")
+	addRunFlags(rootCmd)
+	addModelFlags(rootCmd)
+	addUIFlags(rootCmd)
+}
+
+// callerPathFlags name the flags whose paths are the caller's files (logs,
+// inputs), not the repository's. enterRepo makes them absolute before it
+// changes directory, so they still resolve against the caller's working
+// directory. Repository paths such as --tasks-dir, --prd and --scope keep
+// resolving inside --repo.
+var callerPathFlags = []string{"output", "events", "metrics-file", "task-file", "script", "from", "requirements-prompt"}
+
+// enterRepo switches the working directory to --repo before any command
+// runs. Sessions, state, snapshots and the provider CLI all work relative to
+// the working directory, so this moves all of them at once. The paths in
+// callerPathFlags are made absolute first.
+func enterRepo(cmd *cobra.Command, _ []string) error {
+	if repoPath == "" {
+		return nil
+	}
+	info, err := os.Stat(repoPath)
+	if err != nil {
+		return markPreflight(fmt.Errorf("invalid --repo: %w", err))
+	}
+	if !info.IsDir() {
+		return markPreflight(fmt.Errorf("invalid --repo: %s is not a directory", repoPath))
+	}
+	if err := absCallerPaths(cmd.Flags()); err != nil {
+		return markPreflight(err)
+	}
+	if err := os.Chdir(repoPath); err != nil {
+		return markPreflight(fmt.Errorf("invalid --repo: %w", err))
+	}
+	return nil
+}
+
+// absCallerPaths rewrites the set callerPathFlags in flags to absolute
+// paths. "-" (stdin or stdout) is left alone.
+func absCallerPaths(flags *pflag.FlagSet) error {
+	abs := func(path string) (string, error) {
+		if path == "" || path == "-" || filepath.IsAbs(path) {
+			return path, nil
+		}
+		return filepath.Abs(path)
+	}
+	for _, name := range callerPathFlags {
+		f := flags.Lookup(name)
+		if f == nil || !f.Changed {
+			continue
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			paths := slice.GetSlice()
+			for i, path := range paths {
+				var err error
+				if paths[i], err = abs(path); err != nil {
+					return fmt.Errorf("invalid --%s: %w", name, err)
+				}
+			}
+			if err := slice.Replace(paths); err != nil {
+				return fmt.Errorf("invalid --%s: %w", name, err)
+			}
+			continue
+		}
+		path, err := abs(f.Value.String())
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %w", name, err)
+		}
+		if err := f.Value.Set(path); err != nil {
+			return fmt.Errorf("invalid --%s: %w", name, err)
+		}
+	}
+	return nil
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		// Map context.Canceled (from SIGINT/SIGTERM) to exit code 130
-		// (standard SIGINT convention: 128 + 2). The signal handler in
-		// Runner.Run() already printed the interruption message.
-		if errors.Is(err, context.Canceled) {
-			os.Exit(130)
+		// Interrupts (context.Canceled from SIGINT/SIGTERM) exit with 130
+		// silently: the signal handler in Runner.Run() already printed the
+		// interruption message.
+		code := exitCode(err)
+		if code != exitInterrupted {
+			fmt.Fprintln(os.Stderr, err)
 		}
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(code)
 	}
 }
@@ -12,6 +12,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -84,6 +86,39 @@ func TestRootCommand_TaskFileFlagRecognized(t *testing.T) {
 	assert.Equal(t, "/tmp/custom-task.md", taskFile)
 }
 
+func TestRootCommand_IsolateCIFixFlagRecognized(t *testing.T) {
+	t.Cleanup(func() {
+		isolateCIFix = false
+		rootCmd.Flags().Lookup("isolate-ci-fix").Changed = false
+	})
+
+	require.NoError(t, rootCmd.ParseFlags([]string{"--isolate-ci-fix"}))
+	assert.True(t, isolateCIFix)
+}
+
+func TestRootCommand_CIFixFallbackFlagRecognized(t *testing.T) {
+	t.Cleanup(func() {
+		ciFixFallback = nil
+		f := rootCmd.Flags().Lookup("ci-fix-fallback")
+		require.NoError(t, f.Value.(interface{ Replace([]string) error }).Replace(nil))
+		f.Changed = false
+	})
+
+	require.NoError(t, rootCmd.ParseFlags([]string{"--ci-fix-fallback", "codex", "--ci-fix-fallback", "claude"}))
+	assert.Equal(t, []string{"codex", "claude"}, ciFixFallback)
+}
+
+func TestRootCommand_AcceptsEveryRunFlag(t *testing.T) {
+	// "snap" runs the same workflow as "snap run", so every run flag must work on both.
+	runCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		rootFlag := rootCmd.Flags().Lookup(f.Name)
+		if assert.NotNil(t, rootFlag, "--%s is registered on run but not on root", f.Name) {
+			assert.Equal(t, f.Shorthand, rootFlag.Shorthand, "--%s shorthand", f.Name)
+			assert.Equal(t, f.DefValue, rootFlag.DefValue, "--%s default", f.Name)
+		}
+	})
+}
+
 func TestRootCommand_InvalidFlagDoesNotPrintUsage(t *testing.T) {
 	// Use the shared root command but restore test-facing settings afterward.
 	origArgs := rootCmd.Flags().Args()
@@ -236,10 +271,10 @@ func TestPreflightProviderCLI_MissingBinary(t *testing.T) {
 	assert.Contains(t, outputStr, "not found in PATH")
 	assert.Contains(t, outputStr, "https://")
 
-	// Verify exit code is 1.
+	// A missing provider CLI is a preflight failure.
 	var exitErr *exec.ExitError
 	require.ErrorAs(t, runErr, &exitErr)
-	assert.Equal(t, 1, exitErr.ExitCode())
+	assert.Equal(t, exitPreflight, exitErr.ExitCode())
 }
 
 func TestCI_WorkflowExistsAndValid(t *testing.T) {
@@ -462,6 +497,102 @@ func TestShowState_NoStateFile(t *testing.T) {
 	assert.Contains(t, string(output), "No state file exists")
 }
 
+func TestEnterRepo(t *testing.T) {
+	repoDir := t.TempDir()
+	filePath := filepath.Join(repoDir, "file.txt")
+	require.NoError(t, os.WriteFile(filePath, nil, 0o600))
+
+	tests := []struct {
+		name    string
+		repo    string
+		wantDir string
+		wantErr string
+	}{
+		{name: "unset stays put"},
+		{name: "directory", repo: repoDir, wantDir: repoDir},
+		{name: "missing path", repo: filepath.Join(repoDir, "missing"), wantErr: "invalid --repo"},
+		{name: "file", repo: filePath, wantErr: "is not a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startDir := t.TempDir()
+			chdir(t, startDir)
+			repoPath = tt.repo
+			t.Cleanup(func() { repoPath = "" })
+
+			err := enterRepo(rootCmd, nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			wd, err := os.Getwd()
+			require.NoError(t, err)
+			want := startDir
+			if tt.wantDir != "" {
+				want = tt.wantDir
+			}
+			// Resolve symlinks (e.g. /tmp on macOS) before comparing.
+			want, err = filepath.EvalSymlinks(want)
+			require.NoError(t, err)
+			wd, err = filepath.EvalSymlinks(wd)
+			require.NoError(t, err)
+			assert.Equal(t, want, wd)
+		})
+	}
+}
+
+func TestEnterRepo_CallerPathsStayInCallerDir(t *testing.T) {
+	repoDir := t.TempDir()
+	startDir := t.TempDir()
+	chdir(t, startDir)
+	repoPath = repoDir
+	t.Cleanup(func() { repoPath = "" })
+
+	// A command of its own, so the shared flag variables stay untouched.
+	var output, script, prd string
+	var from []string
+	cmd := &cobra.Command{Use: "run"}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "")
+	cmd.Flags().StringVar(&script, "script", "", "")
+	cmd.Flags().StringArrayVar(&from, "from", nil, "")
+	cmd.Flags().StringVar(&prd, "prd", "", "")
+	absFrom := filepath.Join(t.TempDir(), "b.md")
+	require.NoError(t, cmd.ParseFlags([]string{"-o", "run.log", "--script", "-", "--from", "a.md", "--from", absFrom, "--prd", "docs/PRD.md"}))
+
+	require.NoError(t, enterRepo(cmd, nil))
+
+	assert.Equal(t, filepath.Join(startDir, "run.log"), output, "snap --repo ../other run -o run.log writes to the caller's run.log")
+	assert.Equal(t, []string{filepath.Join(startDir, "a.md"), absFrom}, from)
+	assert.Equal(t, "-", script, "stdin stays stdin")
+	assert.Equal(t, "docs/PRD.md", prd, "repository paths resolve inside --repo")
+}
+
+func TestE2E_RepoFlag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	repoDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "--repo", repoDir, "new", "auth")
+	create.Dir = t.TempDir()
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap --repo new failed: %s", out)
+	assert.DirExists(t, filepath.Join(repoDir, ".snap", "sessions", "auth"))
+
+	list := exec.CommandContext(ctx, binPath, "list", "--repo", repoDir)
+	list.Dir = t.TempDir()
+	out, err = list.CombinedOutput()
+	require.NoError(t, err, "snap list --repo failed: %s", out)
+	assert.Contains(t, string(out), "auth")
+}
+
 func TestJSONFlag_IgnoredWithoutShowState(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping E2E test in short mode")
@@ -2,27 +2,30 @@ package cmd
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/yarlson/tap"
 	"golang.org/x/term"
 
+	"github.com/yarlson/snap/internal/config"
 	"github.com/yarlson/snap/internal/input"
 	"github.com/yarlson/snap/internal/pathutil"
-	"github.com/yarlson/snap/internal/postrun"
 	"github.com/yarlson/snap/internal/provider"
+	"github.com/yarlson/snap/internal/queue"
 	"github.com/yarlson/snap/internal/session"
 	"github.com/yarlson/snap/internal/state"
 	"github.com/yarlson/snap/internal/ui"
 	"github.com/yarlson/snap/internal/workflow"
+	"github.com/yarlson/snap/snap"
 )
 
 var runCmd = &cobra.Command{
@@ -38,10 +41,54 @@ func init() {
 	rootCmd.AddCommand(runCmd)
 
 	runCmd.Flags().StringVarP(&prdPath, "prd", "p", "", "Path to PRD file (default: <tasks-dir>/PRD.md)")
-	runCmd.Flags().StringVar(&taskFile, "task-file", "", "Path to a single task file to run")
+	runCmd.Flags().StringVar(&onlyTask, "only-task", "", "Run only this task (e.g. TASK3), even if completed, then stop")
 	runCmd.Flags().BoolVar(&freshStart, "fresh", false, "Force fresh start, ignore existing state")
+	runCmd.Flags().IntVar(&resumeStep, "step", 0, "Restart the interrupted task from this step instead of the saved one")
+	runCmd.Flags().StringArrayVar(&guardrails, "guardrail", nil, "Project rule the implement and code-review steps enforce (repeatable)")
+	runCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write workflow output to a file instead of stdout (\"-\" for stdout)")
 	runCmd.Flags().BoolVar(&showState, "show-state", false, "Show current state and exit")
 	runCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output raw JSON (only with --show-state)")
+	addRunFlags(runCmd)
+	addModelFlags(runCmd)
+	addUIFlags(runCmd)
+}
+
+// addRunFlags registers the workflow flags shared by snap itself, run, resume
+// and ship. Flags whose meaning differs per command (--step, --output,
+// --guardrail) are registered by each command.
+func addRunFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&taskFile, "task-file", "", "Path to a single task file to run")
+	cmd.Flags().StringVar(&tasksGlob, "tasks-glob", "", "Task file name pattern, e.g. \"story-*.md\" (default: TASK<n>.md)")
+	cmd.Flags().BoolVar(&noDescribe, "no-describe", false, "Skip the per-task description call")
+	cmd.Flags().BoolVar(&confirmCommits, "confirm-commits", false, "Ask before each task's commit steps (TTY only)")
+	cmd.Flags().BoolVar(&pauseTasks, "pause-between-tasks", false, "Ask before starting each next task (TTY only)")
+	cmd.Flags().BoolVar(&signCommits, "sign-commits", false, "Sign commits (GPG/SSH, per git config); unsigned commits fail the step")
+	cmd.Flags().BoolVar(&strictCommits, "strict-commits", false, "Fail the step when a commit step leaves uncommitted changes")
+	cmd.Flags().BoolVar(&prPerTask, "pr-per-task", false, "Implement each task on its own branch and open one PR per task")
+	cmd.Flags().IntVar(&parallelTasks, "parallel", 0, "Run up to N tasks at a time in separate git worktrees when their affects: paths don't overlap")
+	cmd.Flags().StringVar(&pushRemote, "push-remote", "", "Git remote to push to (default: origin)")
+	cmd.Flags().StringVar(&prRemote, "pr-remote", "", "Git remote of the GitHub repository to open PRs in, e.g. upstream for a fork")
+	cmd.Flags().BoolVar(&explainSteps, "explain", false, "Print what each step does before it runs")
+	cmd.Flags().BoolVar(&showDiff, "show-diff", false, "Print a diff summary after the implement step (TTY only)")
+	cmd.Flags().StringVar(&scopePath, "scope", "", "Focus lint/test, review and docs steps on this repo subdirectory")
+	cmd.Flags().StringVar(&baseSHA, "base-sha", "", "Commit the review and docs steps diff against (default: the task's start commit)")
+	cmd.Flags().BoolVar(&sessionMemory, "session-memory", false, "Keep the memory vault in the session directory instead of docs/context/")
+	cmd.Flags().StringArrayVar(&skipSteps, "skip-step", nil, "Leave the named workflow step out of every task, e.g. \"Code review\" (repeatable)")
+	cmd.Flags().StringVar(&changelogPath, "changelog", "", "Add a Keep a Changelog entry per task to this file (e.g. CHANGELOG.md) in the update-docs step")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop when a lint/test step reports failing checks")
+	cmd.Flags().BoolVar(&isolateCIFix, "isolate-ci-fix", false, "Apply CI fixes in a temporary worktree, leaving the working tree untouched")
+	cmd.Flags().StringArrayVar(&ciFixFallback, "ci-fix-fallback", nil, "Provider to switch CI fixes to when the provider call keeps failing (repeatable)")
+	cmd.Flags().StringVar(&providerStderr, "provider-stderr", "hide", "Provider stderr during steps: hide, dim, or show")
+	cmd.Flags().StringVar(&snapshotMode, "snapshots", "off", "Step snapshots in the git stash: every-step, on-failure, or off")
+	cmd.Flags().IntVar(&keepSnapshots, "keep-snapshots", 0, "Keep only this many of the newest step snapshots, pruning after each task (0 = keep all)")
+	cmd.Flags().StringVar(&eventsPath, "events", "", "Append newline-delimited JSON progress events to this file")
+	cmd.Flags().StringVar(&metricsPath, "metrics-file", "", "Append per-step timings as a JSON array to this file")
+	cmd.Flags().DurationVar(&idleTimeout, "idle-timeout", 0, "Cancel a step when the provider prints nothing for this long (0 = off)")
+	cmd.Flags().DurationVar(&stepTimeout, "step-timeout", 0, "Cancel a step that runs longer than this (0 = no limit)")
+	cmd.Flags().IntVar(&stepRetries, "step-retries", 0, "Retry a step whose provider call fails up to this many times")
+	cmd.Flags().DurationVar(&retryBackoff, "retry-backoff", defaultRetryBackoff, "Wait before the first step retry; doubles for each retry after it")
+	cmd.Flags().IntVar(&maxIterations, "max-iterations", 0, "Stop after this many completed tasks; the next run continues (0 = unlimited)")
+	cmd.Flags().DurationVar(&queueInterval, "queue-interval", 0, "Minimum time between queued prompts run between steps (e.g. 5s)")
 }
 
 // runConfig holds resolved paths and state manager for a run invocation.
@@ -51,10 +98,43 @@ type runConfig struct {
 	taskFile     string
 	displayName  string
 	stateManager workflow.StateManager
-	userSupplied bool // true when paths come from user-provided flags; false for auto-detected or session-derived paths
+	session      string // Session name; empty for the legacy layout and ad hoc task files
+	userSupplied bool   // true when paths come from user-provided flags; false for auto-detected or session-derived paths
+
+	uiPrefs session.UIPrefs // Output preferences from the session's meta.json
 }
 
 func run(cmd *cobra.Command, args []string) error {
+	if !cmd.Flags().Changed("step") {
+		return runWorkflow(cmd, args, nil)
+	}
+	if freshStart {
+		return markPreflight(errors.New("--step restarts the interrupted task and cannot be combined with --fresh"))
+	}
+	if err := validateStepFlag(cmd); err != nil {
+		return err
+	}
+	return runWorkflow(cmd, args, &resumeRequest{step: resumeStep, restart: true})
+}
+
+// resumeRequest asks runWorkflow to continue interrupted work only, refusing
+// when there is nothing to resume.
+type resumeRequest struct {
+	step    int  // Step to jump to (0 = saved step)
+	restart bool // snap run --step: announced as a restart of the active task
+}
+
+// runWorkflow runs the implementation workflow for the session in args. A
+// non-nil resume restricts it to continuing the active task.
+func runWorkflow(cmd *cobra.Command, args []string, resume *resumeRequest) (err error) {
+	// Anything that fails before the runner starts is a preflight failure.
+	started := false
+	defer func() {
+		if !started {
+			err = markPreflight(err)
+		}
+	}()
+
 	var sessionName string
 	if len(args) > 0 {
 		sessionName = args[0]
@@ -69,26 +149,50 @@ func run(cmd *cobra.Command, args []string) error {
 		return handleShowState(sessionName, taskFile)
 	}
 
-	// Pre-flight: validate provider CLI is available in PATH.
-	providerName := provider.ResolveProviderName()
-	if err := provider.ValidateCLI(providerName); err != nil {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	steps, runSteps, err := resolveSteps()
+	if err != nil {
+		return err
+	}
+	stepModels, err := workflow.LoadStepModels(".")
+	if err != nil {
+		return err
+	}
+	preamble, err := workflow.LoadPreamble(".")
+	if err != nil {
+		return err
+	}
+	effective, err := resolveRunDefaults(cfg)
+	if err != nil {
 		return err
 	}
 
-	// Pre-flight: detect git remote and validate gh CLI if GitHub.
-	remoteURL, err := postrun.DetectRemote()
+	if _, err := workflow.ParseStderrMode(providerStderr); err != nil {
+		return fmt.Errorf("invalid --provider-stderr: %w", err)
+	}
+	if _, err := workflow.ParseSnapshotMode(snapshotMode); err != nil {
+		return fmt.Errorf("invalid --snapshots: %w", err)
+	}
+	modelOpts, err := modelOptions(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to detect git remote: %w", err)
+		return err
 	}
-	isGitHub := postrun.IsGitHubRemote(remoteURL)
-	if isGitHub {
-		if err := provider.ValidateGH(); err != nil {
-			return err
-		}
+
+	// Pre-flight: resolve the provider CLI in PATH once; the executor reuses the path.
+	providerName, err := resolveProviderName(cfg)
+	if err != nil {
+		return err
+	}
+	providerPath, err := provider.ResolveCLI(providerName)
+	if err != nil {
+		return err
 	}
 
 	// Resolve session or legacy layout.
-	rc, err := resolveRunConfig(sessionName, tasksDir, prdPath, taskFile)
+	rc, err := resolveRunConfig(sessionName, effective.tasksDir, prdPath, taskFile)
 	if err != nil {
 		return err
 	}
@@ -110,6 +214,17 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var target *workflow.ResumeTarget
+	if resume != nil {
+		target, err = resolveResumeTarget(rc, steps, resume.step)
+		if err != nil && resume.restart && errors.Is(err, workflow.ErrNothingToResume) {
+			return fmt.Errorf("--step needs an interrupted task to restart: %w in %s", workflow.ErrNothingToResume, rc.displayName)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check if PRD file exists and warn if not.
 	if rc.prdPath != "" {
 		if exists, warning := pathutil.CheckPathExists(rc.prdPath); !exists {
@@ -117,28 +232,34 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	executor, err := provider.NewExecutorFromEnv()
+	executor, err := provider.NewExecutor(providerName, providerPath, modelOpts...)
 	if err != nil {
 		return err
 	}
-	isTTY := input.IsTerminal(os.Stdin)
 
-	config := workflow.Config{
-		TasksDir:     rc.tasksDir,
-		PRDPath:      rc.prdPath,
-		TaskFilePath: rc.taskFile,
-		FreshStart:   freshStart,
-		ProviderName: providerName,
-		IsTTY:        isTTY,
-		DisplayName:  rc.displayName,
-		RemoteURL:    remoteURL,
-		IsGitHub:     isGitHub,
+	out, toFile, closeOutput, err := openOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	defer closeOutput() //nolint:errcheck // best-effort close of the output file
+
+	events, closeEvents, err := openEvents(eventsPath)
+	if err != nil {
+		return err
 	}
+	defer closeEvents() //nolint:errcheck // best-effort close of the events file
+
+	// Modal input renders on the terminal alongside workflow output, so it is
+	// disabled when output goes to a file.
+	isTTY := input.IsTerminal(os.Stdin) && !toFile
+
+	// The commit confirmation and between-task prompts read stdin themselves,
+	// so the directive queue reader is not started alongside them.
+	stdinReserved := confirmCommits || pauseTasks
 
 	// When running in a TTY, create a SwitchWriter for modal input support.
 	// All workflow output routes through the SwitchWriter so it can be paused
 	// during user input composing and flushed on submit/cancel.
-	var runnerOpts []workflow.RunnerOption
 	var sw *ui.SwitchWriter
 	if isTTY {
 		swOpts := []ui.SwitchWriterOption{}
@@ -146,18 +267,111 @@ func run(cmd *cobra.Command, args []string) error {
 			swOpts = append(swOpts, ui.WithLFToCRLF())
 		}
 		sw = ui.NewSwitchWriter(os.Stdout, swOpts...)
-		runnerOpts = append(runnerOpts, workflow.WithRunnerOutput(sw))
+		out = sw
+	}
+
+	// The stdin reader starts after pre-flight passes; the interrupt hook
+	// stops it, restoring the terminal before the interrupted message.
+	var stdinReader *input.Reader
+	directives, stepInfo := queue.New(), workflow.NewStepContext()
+	var commitPrompt workflow.CommitPromptFunc
+	if isTTY && confirmCommits {
+		commitPrompt = confirmCommit
+	}
+	var pausePrompt workflow.ConfirmFunc
+	if isTTY && pauseTasks {
+		pausePrompt = confirmNextTask
+	}
+
+	// The pipeline runs the remaining pre-flight checks and builds the runner.
+	pipeline, err := snap.New(snap.Options{
+		Session:         rc.session,
+		TasksDir:        rc.tasksDir,
+		PRDPath:         rc.prdPath,
+		TaskFile:        rc.taskFile,
+		TasksGlob:       tasksGlob,
+		Provider:        providerName,
+		FastModel:       modelFast,
+		ThinkingModel:   modelThinking,
+		Executor:        executor,
+		Output:          out,
+		Fresh:           freshStart,
+		Scope:           scopePath,
+		BaseSHA:         baseSHA,
+		SessionMemory:   sessionMemory,
+		Changelog:       changelogPath,
+		FailFast:        failFast,
+		NoDescribe:      noDescribe,
+		Explain:         explainSteps,
+		SignCommits:     signCommits,
+		StrictCommits:   strictCommits,
+		PRPerTask:       prPerTask,
+		Parallel:        parallelTasks,
+		PushRemote:      pushRemote,
+		PRRemote:        prRemote,
+		ProviderStderr:  providerStderr,
+		Snapshots:       snapshotMode,
+		KeepSnapshots:   keepSnapshots,
+		IdleTimeout:     idleTimeout,
+		StepTimeout:     stepTimeout,
+		StepRetries:     stepRetries,
+		MaxIterations:   maxIterations,
+		OnlyTask:        onlyTask,
+		RetryBackoff:    retryBackoff,
+		CIPollInterval:  effective.ciPoll,
+		IsolateCIFix:    isolateCIFix,
+		CIFixFallbacks:  ciFixFallback,
+		Guardrails:      guardrails,
+		LintCommand:     effective.lintCommand,
+		TestCommand:     effective.testCommand,
+		Steps:           steps,
+		SkipSteps:       skipSteps,
+		StepModels:      stepModels,
+		Preamble:        preamble,
+		Events:          events,
+		MetricsFile:     metricsPath,
+		ResumeStep:      resumeStepFor(resume),
+		Terminal:        isTTY,
+		NoInput:         stdinReserved,
+		ShowDiff:        showDiff,
+		QueueInterval:   effective.queueInterval,
+		Directives:      directives,
+		StepInfo:        stepInfo,
+		ConfirmCommit:   commitPrompt,
+		ConfirmNextTask: pausePrompt,
+		OnInterrupt: func() {
+			if stdinReader != nil {
+				stdinReader.Stop()
+			}
+		},
+		PostIterationHook: effective.hook,
+		HookFatal:         effective.hookFatal,
+	})
+	if err != nil {
+		return err
 	}
 
-	runnerOpts = append(runnerOpts, workflow.WithStateManager(rc.stateManager))
+	// Session output preferences and flags apply on top of the environment defaults.
+	applyUIPrefs(cmd, rc.uiPrefs)
 
-	runner := workflow.NewRunner(executor, config, runnerOpts...)
+	switch {
+	case target == nil:
+	case target.MonitoringCI:
+		fmt.Fprint(out, ui.Info("Resuming CI monitoring"))
+	case resume.restart:
+		fmt.Fprint(out, ui.Info(fmt.Sprintf("%s: restarting from step %d/%d: %s",
+			target.TaskID, target.Step, workflow.StepCount(runSteps), workflow.StepName(runSteps, target.Step))))
+	default:
+		fmt.Fprint(out, ui.Info(fmt.Sprintf("Resuming %s at step %d/%d: %s",
+			target.TaskID, target.Step, workflow.StepCount(runSteps), workflow.StepName(runSteps, target.Step))))
+	}
 
-	// Start reading user prompts from stdin in background (TTY only).
+	// Start reading user prompts from stdin in background (skipped when headless,
+	// so no raw-mode setup is attempted on a non-terminal).
 	// Raw terminal mode suppresses echo to prevent garbled output during streaming.
 	// Modal input: first keystroke pauses output and shows input prompt;
 	// Enter submits, Escape cancels, both flush buffered output and resume.
-	if isTTY {
+	if isTTY && !stdinReserved {
 		im := input.NewMode(sw)
 
 		// Handle terminal resize (SIGWINCH) to update input mode width.
@@ -178,23 +392,175 @@ func run(cmd *cobra.Command, args []string) error {
 			im.SetTermWidth(w)
 		}
 
-		stdinReader := input.NewReader(os.Stdin, runner.Queue(),
+		stdinReader = input.NewReader(os.Stdin, directives,
 			input.WithTerminal(os.Stdin),
 			input.WithOutput(sw),
-			input.WithStepInfo(runner.StepContext()),
+			input.WithStepInfo(stepInfo),
 			input.WithMode(im),
 		)
 		stdinReader.Start()
 		defer stdinReader.Stop()
 	}
 
-	return runner.Run(context.Background())
+	started = true
+	return pipeline.Run(context.Background())
+}
+
+// confirmCommit asks whether to run a task's commit steps. Defaults to skip
+// so an accidental Enter leaves the work uncommitted for inspection; a
+// cancelled prompt aborts the run.
+func confirmCommit(ctx context.Context, message string) workflow.CommitDecision {
+	initial := workflow.CommitSkip
+	choice := tap.Select(ctx, tap.SelectOptions[workflow.CommitDecision]{
+		Message: message,
+		Options: []tap.SelectOption[workflow.CommitDecision]{
+			{Value: workflow.CommitApprove, Label: "Approve", Hint: "run the commit steps"},
+			{Value: workflow.CommitSkip, Label: "Skip", Hint: "leave the changes uncommitted and continue"},
+			{Value: workflow.CommitAbort, Label: "Abort", Hint: "stop here; snap resume asks again"},
+		},
+		InitialValue: &initial,
+	})
+	if choice == "" {
+		return workflow.CommitAbort
+	}
+	return choice
 }
 
+// confirmNextTask asks whether to start the next task. Defaults to Yes;
+// declining stops the run so the finished task can be reviewed.
+func confirmNextTask(ctx context.Context, message string) bool {
+	return tap.Confirm(ctx, tap.ConfirmOptions{
+		Message:      message,
+		Active:       "Yes",
+		Inactive:     "No, stop here",
+		InitialValue: true,
+	})
+}
+
+// runDefaults holds run settings that come from a flag or .snaprc.
+type runDefaults struct {
+	tasksDir      string
+	queueInterval time.Duration
+	ciPoll        time.Duration
+	lintCommand   string
+	testCommand   string
+	hook          string
+	hookFatal     bool
+}
+
+// resolveRunDefaults applies flag > env > repo .snaprc > home .snaprc > default;
+// loadConfig has already applied the flags.
+func resolveRunDefaults(cfg *config.Config) (runDefaults, error) {
+	var d runDefaults
+	dir, err := cfg.Path("tasks-dir")
+	if err != nil {
+		return d, err
+	}
+	d.tasksDir = dir
+	if d.queueInterval, err = cfg.Duration("queue-interval"); err != nil {
+		return d, err
+	}
+	ciPoll, err := cfg.Duration("ci-poll")
+	if err != nil {
+		return d, err
+	}
+	d.ciPoll = ciPoll
+	lint, err := cfg.Get("lint-command")
+	if err != nil {
+		return d, err
+	}
+	d.lintCommand = lint.Value
+	test, err := cfg.Get("test-command")
+	if err != nil {
+		return d, err
+	}
+	d.testCommand = test.Value
+	hook, err := cfg.Get("post-iteration-hook")
+	if err != nil {
+		return d, err
+	}
+	d.hook = hook.Value
+	if d.hookFatal, err = cfg.Bool("hook-fatal"); err != nil {
+		return d, err
+	}
+	return d, nil
+}
+
+// resolveSteps loads the step list from .snap/workflow.yaml and applies
+// --skip-step. configured is what the pipeline is given (nil for the
+// built-in steps); running is what each iteration runs.
+func resolveSteps() (configured, running []workflow.StepDef, err error) {
+	configured, err = workflow.LoadSteps(".")
+	if err != nil {
+		return nil, nil, err
+	}
+	running, _, err = workflow.SkipSteps(configured, skipSteps)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --skip-step: %w", err)
+	}
+	return configured, running, nil
+}
+
+// stateSteps returns the steps a saved run was using: .snap/workflow.yaml
+// minus the steps it skipped. When the skipped names no longer match, the
+// configured list is used as is.
+func stateSteps(skipped []string) ([]workflow.StepDef, error) {
+	configured, err := workflow.LoadSteps(".")
+	if err != nil {
+		return nil, err
+	}
+	running, _, err := workflow.SkipSteps(configured, skipped)
+	if err != nil {
+		return configured, nil
+	}
+	return running, nil
+}
+
+// defaultRetryBackoff is the wait before the first step retry.
+const defaultRetryBackoff = 5 * time.Second
+
 func validateRunFlags(cmd *cobra.Command, sessionName, taskFilePath string) error {
+	if err := validateGuardrails(); err != nil {
+		return err
+	}
+	if stepRetries < 0 {
+		return fmt.Errorf("invalid --step-retries: must not be negative")
+	}
+	if retryBackoff < 0 {
+		return fmt.Errorf("invalid --retry-backoff: must not be negative")
+	}
+	if maxIterations < 0 {
+		return fmt.Errorf("invalid --max-iterations: must not be negative")
+	}
+	if keepSnapshots < 0 {
+		return fmt.Errorf("invalid --keep-snapshots: must not be negative")
+	}
+	if parallelTasks < 0 {
+		return fmt.Errorf("invalid --parallel: must not be negative")
+	}
+	if parallelTasks > 1 && prPerTask {
+		return fmt.Errorf("--parallel cannot be used with --pr-per-task")
+	}
+	if parallelTasks > 1 && taskFilePath != "" {
+		return fmt.Errorf("--parallel cannot be used with --task-file")
+	}
+	if onlyTask != "" && parallelTasks > 1 {
+		return fmt.Errorf("--only-task cannot be used with --parallel")
+	}
+	if onlyTask != "" && taskFilePath != "" {
+		return fmt.Errorf("--only-task cannot be used with --task-file")
+	}
+	if tasksGlob != "" {
+		if err := workflow.ValidateTasksGlob(tasksGlob); err != nil {
+			return err
+		}
+	}
 	if taskFilePath == "" {
 		return nil
 	}
+	if cmd.Flags().Changed("tasks-glob") {
+		return fmt.Errorf("--task-file cannot be used with --tasks-glob")
+	}
 	if sessionName != "" {
 		return fmt.Errorf("--task-file cannot be used with a session name")
 	}
@@ -207,6 +573,16 @@ func validateRunFlags(cmd *cobra.Command, sessionName, taskFilePath string) erro
 	return nil
 }
 
+// validateGuardrails rejects blank --guardrail rules.
+func validateGuardrails() error {
+	for _, rule := range guardrails {
+		if strings.TrimSpace(rule) == "" {
+			return fmt.Errorf("invalid --guardrail: rule cannot be empty")
+		}
+	}
+	return nil
+}
+
 func normalizeTaskFilePath(path string) (string, error) {
 	if strings.Contains(path, "\n") || strings.Contains(path, "\r") {
 		return "", fmt.Errorf("path contains invalid characters (newline)")
@@ -251,92 +627,67 @@ func resolveTaskFileRun(path string) (*runConfig, error) {
 		prdPath:      "",
 		taskFile:     absPath,
 		displayName:  absPath,
-		stateManager: newAdhocStateManager(absPath),
+		stateManager: state.NewManagerForTaskFile(absPath),
 		userSupplied: true,
 	}, nil
 }
 
-func newAdhocStateManager(taskFilePath string) workflow.StateManager {
-	sum := sha256.Sum256([]byte(taskFilePath))
-	stateDir := filepath.Join(".snap", "adhoc", hex.EncodeToString(sum[:]))
-	return state.NewManagerInDir(stateDir)
-}
-
 // resolveRunConfig determines the tasks directory, PRD path, display name, and
 // state manager based on the session name (or auto-detection/legacy fallback).
 func resolveRunConfig(sessionName, flagTasksDir, flagPRDPath, flagTaskFile string) (*runConfig, error) {
 	if flagTaskFile != "" {
 		return resolveTaskFileRun(flagTaskFile)
 	}
-	if sessionName != "" {
-		return resolveNamedSession(sessionName)
-	}
 
-	// Auto-detect: check for sessions, then fall back to legacy layout.
-	sessions, err := session.List(".")
+	target, err := session.ResolveForRun(".", sessionName, flagTasksDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list sessions: %w", err)
+		var multi *session.MultipleSessionsError
+		if errors.As(err, &multi) {
+			return nil, formatMultipleSessionsError(multi.Sessions, "run")
+		}
+		return nil, err
 	}
 
-	switch len(sessions) {
-	case 0:
-		return resolveLegacyFallback(flagTasksDir, flagPRDPath)
-	case 1:
-		return resolveNamedSession(sessions[0].Name)
-	default:
-		return nil, formatMultipleSessionsError(sessions)
+	if target.Legacy() {
+		return &runConfig{
+			tasksDir:     target.TasksDir,
+			prdPath:      pathutil.ResolvePRDPath(target.TasksDir, flagPRDPath),
+			displayName:  target.DisplayName,
+			stateManager: state.NewManager(),
+			userSupplied: true,
+		}, nil
 	}
-}
 
-// resolveNamedSession resolves paths for a named session.
-func resolveNamedSession(name string) (*runConfig, error) {
-	_, err := session.Resolve(".", name)
+	meta, err := session.LoadMeta(".", target.Session)
 	if err != nil {
 		return nil, err
 	}
-	td := session.TasksDir(".", name)
 	return &runConfig{
-		tasksDir:     td,
-		prdPath:      filepath.Join(td, "PRD.md"),
-		displayName:  name,
-		stateManager: state.NewManagerInDir(session.Dir(".", name)),
+		tasksDir:     target.TasksDir,
+		prdPath:      filepath.Join(target.TasksDir, "PRD.md"),
+		displayName:  target.DisplayName,
+		stateManager: state.NewManagerInDir(session.Dir(".", target.Session)),
+		session:      target.Session,
+		uiPrefs:      meta.UI,
 		userSupplied: false,
 	}, nil
 }
 
-// resolveLegacyFallback checks for a legacy layout (tasks directory exists
-// or existing .snap/state.json) and returns a legacy run config.
-func resolveLegacyFallback(flagTasksDir, flagPRDPath string) (*runConfig, error) {
-	legacyManager := state.NewManager()
-	if legacyManager.Exists() || dirExists(flagTasksDir) {
-		return &runConfig{
-			tasksDir:     flagTasksDir,
-			prdPath:      pathutil.ResolvePRDPath(flagTasksDir, flagPRDPath),
-			displayName:  flagTasksDir,
-			stateManager: legacyManager,
-			userSupplied: true,
-		}, nil
-	}
-	if err := session.EnsureDefault("."); err != nil {
-		return nil, err
-	}
-	return resolveNamedSession("default")
-}
-
 // dirExists checks if a directory exists.
 func dirExists(path string) bool {
 	info, err := os.Stat(path)
 	return err == nil && info.IsDir()
 }
 
-// formatMultipleSessionsError builds an error message listing available sessions.
-func formatMultipleSessionsError(sessions []session.Info) error {
+// formatMultipleSessionsError builds an error message listing available
+// sessions and how to pick one with the snap subcommand command.
+func formatMultipleSessionsError(sessions []session.Info, command string) error {
 	var b strings.Builder
 	b.WriteString("Error: multiple sessions found\n\nAvailable sessions:\n")
 	for _, s := range sessions {
 		fmt.Fprintf(&b, "  %-12s  %s\n", s.Name, formatTaskSummary(s.TaskCount, s.CompletedCount))
 	}
-	b.WriteString("\nSpecify a session:\n  snap run <name>")
+	fmt.Fprintf(&b, "\nSpecify a session:\n  snap %s <name>", command)
 	return fmt.Errorf("%s", b.String())
 }
 
@@ -366,7 +717,14 @@ func handleShowState(sessionName, taskFilePath string) error {
 		return nil
 	}
 
-	fmt.Println(workflowState.Summary(workflow.StepName))
+	steps, err := stateSteps(workflowState.SkippedSteps)
+	if err != nil {
+		return err
+	}
+	fmt.Println(workflowState.Summary(func(n int) string { return workflow.StepName(steps, n) }))
+	if workflowState.CurrentTaskID != "" && workflowState.TaskDescription != "" {
+		fmt.Println("  " + workflowState.TaskDescription)
+	}
 	return nil
 }
 
@@ -378,7 +736,7 @@ func resolveStateManager(sessionName, taskFilePath string) (workflow.StateManage
 		if err != nil {
 			return nil, err
 		}
-		return newAdhocStateManager(absPath), nil
+		return state.NewManagerForTaskFile(absPath), nil
 	}
 	if sessionName != "" {
 		dir, err := session.Resolve(".", sessionName)
@@ -350,6 +350,54 @@ func TestE2E_RunNonexistentSession(t *testing.T) {
 	assert.Contains(t, outputStr, "snap new nonexistent")
 }
 
+func TestE2E_RunInvalidProviderStderr(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	mockPath := createMockProvider(t, "#!/bin/sh\nexit 0\n")
+
+	run := exec.CommandContext(ctx, binPath, "run", "--provider-stderr", "loud")
+	run.Dir = projectDir
+	run.Env = append(os.Environ(), "PATH="+mockPath)
+	output, runErr := run.CombinedOutput()
+	require.Error(t, runErr)
+	assert.Contains(t, string(output), `invalid --provider-stderr: "loud" is not a stderr mode`)
+}
+
+func TestE2E_RunSignCommitsWithoutSigningSetup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	for _, args := range [][]string{{"init"}, {"config", "gpg.format", "ssh"}, {"config", "gpg.ssh.program", "sh"}} {
+		git := exec.CommandContext(ctx, "git", args...)
+		git.Dir = projectDir
+		out, err := git.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+
+	mockPath := createMockProvider(t, "#!/bin/sh\nexit 0\n")
+
+	run := exec.CommandContext(ctx, binPath, "run", "--sign-commits")
+	run.Dir = projectDir
+	run.Env = append(os.Environ(), "PATH="+mockPath, "GIT_CONFIG_GLOBAL=/dev/null")
+	output, runErr := run.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, runErr, &exitErr, "output: %s", output)
+	assert.Equal(t, exitPreflight, exitErr.ExitCode())
+	assert.Contains(t, string(output), "--sign-commits: commit signing: gpg.format is ssh but user.signingkey is not set")
+}
+
 // Test: --show-state with session name reads session state.
 func TestE2E_ShowStateWithSession(t *testing.T) {
 	if testing.Short() {
@@ -593,3 +641,92 @@ func TestE2E_ResumeAcrossSessionRuns(t *testing.T) {
 	assert.Contains(t, output, "snap: auth |",
 		"startup summary should show session name")
 }
+
+// Test: snap run --output writes workflow output to the file instead of stdout.
+func TestE2E_RunOutputToFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "new", "auth")
+	create.Dir = projectDir
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap new failed: %s", out)
+
+	sessTasksDir := filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks")
+	require.NoError(t, os.WriteFile(
+		filepath.Join(sessTasksDir, "TASK1.md"),
+		[]byte("# Task 1\nImplement something small"), 0o600))
+
+	mockPath := createMockProvider(t, "#!/bin/sh\nexec /bin/sleep 3600\n")
+	logPath := filepath.Join(t.TempDir(), "run.log")
+
+	run := exec.CommandContext(ctx, binPath, "run", "auth", "--output", logPath)
+	run.Dir = projectDir
+	run.Env = append(os.Environ(), "PATH="+mockPath)
+
+	var combinedOut strings.Builder
+	run.Stdout = &combinedOut
+	run.Stderr = &combinedOut
+
+	require.NoError(t, run.Start())
+	time.Sleep(2 * time.Second)
+	require.NoError(t, run.Process.Signal(syscall.SIGINT))
+
+	//nolint:errcheck // expect non-zero exit from SIGINT
+	_ = run.Wait()
+
+	assert.NotContains(t, combinedOut.String(), "snap: auth |", "workflow output should not go to stdout")
+
+	logged, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(logged), "snap: auth |", "startup summary should be written to the output file")
+	assert.NotContains(t, string(logged), "\x1b[", "file output should not contain ANSI colors")
+}
+
+// Test: without a terminal on stdin the run is headless. No reader is
+// started, so text piped to stdin is never queued as a directive, and the
+// run completes.
+func TestE2E_RunHeadlessIgnoresStdin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "new", "auth")
+	create.Dir = projectDir
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap new failed: %s", out)
+
+	sessTasksDir := filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks")
+	require.NoError(t, os.WriteFile(filepath.Join(sessTasksDir, "PRD.md"), []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(sessTasksDir, "TASK1.md"),
+		[]byte("# Task 1\nImplement something small"), 0o600))
+
+	// The mock provider logs every prompt it receives.
+	promptLog := filepath.Join(t.TempDir(), "prompts.log")
+	mockPath := createMockProvider(t, "#!/bin/sh\nprintf '%s\\n' \"$@\" >> \""+promptLog+"\"\n")
+
+	run := exec.CommandContext(ctx, binPath, "run", "auth", "--no-describe")
+	run.Dir = projectDir
+	run.Env = append(os.Environ(), "PATH="+mockPath)
+	run.Stdin = strings.NewReader("PIPED-DIRECTIVE\n")
+	output, err := run.CombinedOutput()
+	require.NoError(t, err, "headless run failed: %s", output)
+
+	assert.NotContains(t, string(output), "Type a directive", "no prompt hint without a terminal")
+	assert.Contains(t, string(output), "Iteration complete")
+
+	prompts, err := os.ReadFile(promptLog)
+	require.NoError(t, err)
+	assert.Contains(t, string(prompts), "TASK1", "the workflow steps ran")
+	assert.NotContains(t, string(prompts), "PIPED-DIRECTIVE", "stdin is not read as a directive")
+}
@@ -5,8 +5,12 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/ui"
 )
 
 // --- Integration tests: resolveRunConfig ---
@@ -27,9 +31,51 @@ func TestResolveRunConfig_NamedSession_Exists(t *testing.T) {
 	assert.Equal(t, filepath.Join(".snap", "sessions", "auth", "tasks"), rc.tasksDir)
 	assert.Equal(t, filepath.Join(".snap", "sessions", "auth", "tasks", "PRD.md"), rc.prdPath)
 	assert.Equal(t, "auth", rc.displayName)
+	assert.Equal(t, "auth", rc.session)
 	assert.NotNil(t, rc.stateManager)
 }
 
+func TestApplyUIPrefs_FlagsOverrideSession(t *testing.T) {
+	prevColors := ui.ColorsEnabled()
+	t.Cleanup(func() {
+		ui.SetColorsEnabled(prevColors)
+		ui.SetASCII(false)
+		ui.SetEmoji(true)
+		ui.SetWidth(0)
+	})
+
+	cmd := &cobra.Command{Use: "run"}
+	addUIFlags(cmd)
+	require.NoError(t, cmd.Flags().Set("ascii", "false"))
+	require.NoError(t, cmd.Flags().Set("width", "60"))
+	defer func() { asciiOutput, outputWidth = false, 0 }()
+
+	ui.SetColorsEnabled(true)
+	applyUIPrefs(cmd, session.UIPrefs{NoColor: true, ASCII: true, NoEmoji: true, Width: 50})
+
+	assert.False(t, ui.ColorsEnabled(), "session no_color applies when --no-color is not passed")
+	assert.False(t, ui.ASCIIEnabled(), "--ascii=false overrides the session")
+	assert.False(t, ui.EmojiEnabled())
+	assert.Equal(t, 60, ui.Width(), "--width overrides the session")
+}
+
+func TestResolveRunConfig_NamedSession_UIPrefs(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+
+	sessDir := filepath.Join(projectDir, ".snap", "sessions", "auth")
+	require.NoError(t, os.MkdirAll(filepath.Join(sessDir, "tasks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(sessDir, "meta.json"), []byte(`{"ui":{"no_color":true,"ascii":true,"no_emoji":true,"width":50}}`), 0o600))
+
+	rc, err := resolveRunConfig("auth", "docs/tasks", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, session.UIPrefs{NoColor: true, ASCII: true, NoEmoji: true, Width: 50}, rc.uiPrefs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(sessDir, "meta.json"), []byte("{"), 0o600))
+	_, err = resolveRunConfig("auth", "docs/tasks", "", "")
+	require.Error(t, err, "corrupt meta.json is reported, not ignored")
+}
+
 func TestResolveRunConfig_NamedSession_NotFound(t *testing.T) {
 	projectDir := t.TempDir()
 	chdir(t, projectDir)
@@ -70,6 +116,7 @@ func TestResolveRunConfig_NoName_ZeroSessions_LegacyTaskFiles(t *testing.T) {
 	assert.Equal(t, "docs/tasks", rc.tasksDir)
 	assert.Equal(t, "docs/tasks/PRD.md", rc.prdPath)
 	assert.Equal(t, "docs/tasks", rc.displayName)
+	assert.Empty(t, rc.session, "the legacy layout has no session memory")
 
 	// No "default" session should have been created.
 	defaultDir := filepath.Join(projectDir, ".snap", "sessions", "default")
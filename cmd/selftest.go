@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/provider"
+	"github.com/yarlson/snap/internal/ui"
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+// selftestPrompt asks for a reply that is cheap to produce and easy to check.
+const selftestPrompt = "Reply with exactly OK and nothing else. Do not use any tools."
+
+var selftestTimeout time.Duration
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Call the provider with a trivial prompt to check auth and connectivity",
+	Long: `Call the configured provider once on the fast model with a prompt that
+asks for "OK", and report the latency and the resolved model names. This
+catches expired credentials and network problems that a PATH check misses.
+
+When the provider CLI is not installed and the CI environment variable is
+set, the self-test is skipped instead of failing.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          selftestRun,
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().DurationVar(&selftestTimeout, "timeout", time.Minute, "Fail when the provider has not answered after this long")
+	addModelFlags(selftestCmd)
+}
+
+func selftestRun(cmd *cobra.Command, _ []string) error {
+	out := cmd.OutOrStdout()
+
+	if selftestTimeout <= 0 {
+		return markPreflight(errors.New("invalid --timeout: must be positive"))
+	}
+	modelOpts, err := modelOptions(cmd)
+	if err != nil {
+		return markPreflight(err)
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return markPreflight(err)
+	}
+	providerName, err := resolveProviderName(cfg)
+	if err != nil {
+		return markPreflight(err)
+	}
+	providerPath, err := provider.ResolveCLI(providerName)
+	if err != nil {
+		if os.Getenv("CI") != "" {
+			fmt.Fprint(out, ui.Info(fmt.Sprintf("Self-test skipped: %s CLI not found in PATH (CI)", providerName)))
+			return nil
+		}
+		return markPreflight(err)
+	}
+	executor, err := provider.NewExecutor(providerName, providerPath, modelOpts...)
+	if err != nil {
+		return markPreflight(err)
+	}
+
+	fmt.Fprint(out, ui.KeyValue("Provider", fmt.Sprintf("%s (%s)", providerName, providerPath)))
+	if namer, ok := executor.(workflow.ModelNamer); ok {
+		fmt.Fprint(out, ui.KeyValue("Models  ", fmt.Sprintf("fast=%s, thinking=%s",
+			namer.ModelName(model.Fast), namer.ModelName(model.Thinking))))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), selftestTimeout)
+	defer cancel()
+
+	var reply bytes.Buffer
+	start := time.Now()
+	err = executor.Run(ctx, &reply, model.Fast, selftestPrompt)
+	elapsed := time.Since(start)
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return workflow.ProviderError(fmt.Errorf("self-test: %s did not answer within %s", providerName, selftestTimeout))
+	case err != nil:
+		return workflow.ProviderError(fmt.Errorf("self-test: %s call failed: %w", providerName, err))
+	}
+
+	text := strings.TrimSpace(ui.StripColors(reply.String()))
+	if !strings.Contains(strings.ToUpper(text), "OK") {
+		return workflow.ProviderError(fmt.Errorf("self-test: unexpected reply from %s: %q", providerName, text))
+	}
+
+	fmt.Fprintln(out, ui.StepComplete(fmt.Sprintf("%s replied OK", providerName), elapsed))
+	return nil
+}
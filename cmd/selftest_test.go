@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selftestEnv returns the environment with PATH replaced and CI unset.
+func selftestEnv(path string, extra ...string) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "PATH=") && !strings.HasPrefix(kv, "CI=") {
+			env = append(env, kv)
+		}
+	}
+	return append(append(env, "PATH="+path), extra...)
+}
+
+func TestE2E_Selftest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	okPath := mockPlanProvider(t)
+	slowPath := createMockProvider(t, "#!/bin/sh\nexec sleep 10\n")
+	chattyPath := createMockProvider(t, `#!/bin/sh
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"Please log in."}]}}'
+`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      []string
+		wantCode int
+		want     []string
+	}{
+		{
+			name: "provider replies",
+			env:  selftestEnv(okPath),
+			want: []string{"Provider", "fast=haiku, thinking=opus", "claude replied OK"},
+		},
+		{
+			name: "pinned model",
+			args: []string{"--model-fast", "sonnet"},
+			env:  selftestEnv(okPath),
+			want: []string{"fast=sonnet, thinking=opus"},
+		},
+		{
+			name:     "timeout",
+			args:     []string{"--timeout", "200ms"},
+			env:      selftestEnv(slowPath),
+			wantCode: exitProvider,
+			want:     []string{"self-test: claude did not answer within 200ms"},
+		},
+		{
+			name:     "unexpected reply",
+			env:      selftestEnv(chattyPath),
+			wantCode: exitProvider,
+			want:     []string{`unexpected reply from claude: "Please log in."`},
+		},
+		{
+			name:     "provider missing",
+			env:      selftestEnv(t.TempDir()),
+			wantCode: exitPreflight,
+			want:     []string{"claude not found in PATH"},
+		},
+		{
+			name: "provider missing in CI",
+			env:  selftestEnv(t.TempDir(), "CI=true"),
+			want: []string{"Self-test skipped: claude CLI not found in PATH (CI)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.CommandContext(context.Background(), binPath, append([]string{"selftest"}, tt.args...)...)
+			cmd.Dir = t.TempDir()
+			cmd.Env = tt.env
+			output, err := cmd.CombinedOutput()
+
+			if tt.wantCode == 0 {
+				require.NoError(t, err, "output: %s", output)
+			} else {
+				var exitErr *exec.ExitError
+				require.ErrorAs(t, err, &exitErr, "output: %s", output)
+				assert.Equal(t, tt.wantCode, exitErr.ExitCode(), "output: %s", output)
+			}
+			for _, want := range tt.want {
+				assert.Contains(t, string(output), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+var shipCmd = &cobra.Command{
+	Use:           "ship [session]",
+	Short:         "Plan a session, then run its tasks",
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          shipRun,
+}
+
+func init() {
+	rootCmd.AddCommand(shipCmd)
+
+	shipCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write plan and run output to a file instead of stdout (\"-\" for stdout)")
+	shipCmd.Flags().StringArrayVar(&guardrails, "guardrail", nil, "Project rule planning, implementation and code review respect (repeatable)")
+	shipCmd.Flags().BoolVar(&freshStart, "fresh", false, "Force fresh start, ignore existing state")
+	addPlanFlags(shipCmd)
+	addRunFlags(shipCmd)
+	addModelFlags(shipCmd)
+	addUIFlags(shipCmd)
+}
+
+// shipRun plans the session to completion and, on success, runs the
+// implementation workflow on the generated tasks in the same session.
+func shipRun(cmd *cobra.Command, args []string) error {
+	// Check the run flags before planning rather than after it. Ship always
+	// runs a session, so any session name stands in for the planned one.
+	if err := validateRunFlags(cmd, "ship", taskFile); err != nil {
+		return markPreflight(err)
+	}
+
+	sessionName, err := planSession(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	if err := printShipHandoff(sessionName); err != nil {
+		return err
+	}
+
+	return run(cmd, []string{sessionName})
+}
+
+// printShipHandoff announces the run to the --output destination, where
+// the planning and run output go.
+func printShipHandoff(sessionName string) error {
+	out, _, closeOutput, err := openOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	defer closeOutput() //nolint:errcheck // best-effort close of the output file
+
+	fmt.Fprint(out, "\n")
+	fmt.Fprint(out, ui.Info(fmt.Sprintf("Planning done — running session '%s'", sessionName)))
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test: snap ship plans the session, then starts the runner on the same session.
+func TestE2E_ShipPlansThenRuns(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "new", "auth")
+	create.Dir = projectDir
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap new failed: %s", out)
+
+	tasksDir := filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks")
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "brief.md"), []byte("I want OAuth2"), 0o600))
+
+	// Planning calls write a task file and return; once tasks exist the
+	// runner's calls block so the test can interrupt it.
+	mockPath := createMockProvider(t, `#!/bin/sh
+if [ -f "$MOCK_TASKS_DIR/TASK1.md" ]; then
+  exec /bin/sleep 3600
+fi
+case "$*" in
+  *"TASK<N>.md"*) mkdir -p "$MOCK_TASKS_DIR"; printf '# Task 1\nDo it\n' > "$MOCK_TASKS_DIR/TASK1.md" ;;
+esac
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"OK"}]}}'
+exit 0
+`)
+
+	ship := exec.CommandContext(ctx, binPath, "ship", "auth", "--from", "brief.md")
+	ship.Dir = projectDir
+	ship.Env = append(os.Environ(), "PATH="+mockPath, "MOCK_TASKS_DIR="+tasksDir)
+
+	var combinedOut strings.Builder
+	ship.Stdout = &combinedOut
+	ship.Stderr = &combinedOut
+
+	require.NoError(t, ship.Start())
+	time.Sleep(3 * time.Second)
+	require.NoError(t, ship.Process.Signal(syscall.SIGINT))
+
+	//nolint:errcheck // expect non-zero exit from SIGINT
+	_ = ship.Wait()
+
+	output := combinedOut.String()
+	assert.Contains(t, output, "Planning complete")
+	assert.Contains(t, output, "running session 'auth'")
+	assert.Contains(t, output, "snap: auth |")
+	assert.NotContains(t, output, "Run: snap run")
+}
+
+// Test: snap ship stops before running when planning fails.
+func TestE2E_ShipStopsOnPlanFailure(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	binPath := buildSnap(t)
+	projectDir := t.TempDir()
+	ctx := context.Background()
+
+	create := exec.CommandContext(ctx, binPath, "new", "auth")
+	create.Dir = projectDir
+	out, err := create.CombinedOutput()
+	require.NoError(t, err, "snap new failed: %s", out)
+
+	mockPath := mockPlanProvider(t)
+
+	ship := exec.CommandContext(ctx, binPath, "ship", "auth", "--from", "nonexistent.md")
+	ship.Dir = projectDir
+	ship.Env = append(os.Environ(), "PATH="+mockPath)
+
+	output, shipErr := ship.CombinedOutput()
+	require.Error(t, shipErr)
+
+	outputStr := string(output)
+	assert.Contains(t, outputStr, "failed to read input file")
+	assert.NotContains(t, outputStr, "snap: auth |")
+}
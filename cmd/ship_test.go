@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+func TestShipCommand_AcceptsPlanAndRunFlags(t *testing.T) {
+	// Ship plans and then runs, so it takes the flags of both commands,
+	// except those that pick what to plan or run, or what to do instead.
+	notShipped := map[string]bool{
+		"amend": true, "validate": true, "json": true,
+		"prd": true, "only-task": true, "step": true, "show-state": true,
+	}
+	for _, c := range []*cobra.Command{planCmd, runCmd} {
+		c.Flags().VisitAll(func(f *pflag.Flag) {
+			if notShipped[f.Name] {
+				return
+			}
+			shipFlag := shipCmd.Flags().Lookup(f.Name)
+			if assert.NotNil(t, shipFlag, "--%s is registered on %s but not on ship", f.Name, c.Name()) {
+				assert.Equal(t, f.Shorthand, shipFlag.Shorthand, "--%s shorthand", f.Name)
+				assert.Equal(t, f.DefValue, shipFlag.DefValue, "--%s default", f.Name)
+			}
+		})
+	}
+}
+
+func TestShipRun_RejectsRunFlagsBeforePlanning(t *testing.T) {
+	taskFile = "TASK1.md"
+	t.Cleanup(func() { taskFile = "" })
+
+	err := shipRun(shipCmd, []string{"auth"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--task-file cannot be used with a session name")
+	assert.Equal(t, exitPreflight, exitCode(err))
+}
+
+func TestPrintShipHandoff_WritesToOutput(t *testing.T) {
+	prev := ui.ColorsEnabled()
+	t.Cleanup(func() { ui.SetColorsEnabled(prev) })
+
+	path := filepath.Join(t.TempDir(), "ship.log")
+	outputPath = path
+	t.Cleanup(func() { outputPath = "" })
+
+	require.NoError(t, printShipHandoff("auth"))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Planning done — running session 'auth'")
+}
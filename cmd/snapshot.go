@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+var (
+	snapshotTask  string
+	snapshotSince string
+	snapshotUntil string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Inspect workflow snapshots",
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List workflow snapshots, newest first",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          snapshotListRun,
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+
+	snapshotListCmd.Flags().StringVar(&snapshotTask, "task", "", "Only show snapshots for this task ID (e.g. TASK2)")
+	snapshotListCmd.Flags().StringVar(&snapshotSince, "since", "", "Only show snapshots newer than a duration (e.g. 2h) or time (RFC 3339 or YYYY-MM-DD)")
+	snapshotListCmd.Flags().StringVar(&snapshotUntil, "until", "", "Only show snapshots older than a duration (e.g. 30m) or time (RFC 3339 or YYYY-MM-DD)")
+}
+
+func snapshotListRun(cmd *cobra.Command, _ []string) error {
+	now := time.Now()
+	since, err := parseSnapshotTime(snapshotSince, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := parseSnapshotTime(snapshotUntil, now)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	entries, err := snapshot.New(".").List(context.Background(), snapshot.Filter{
+		TaskID: snapshotTask,
+		Since:  since,
+		Until:  until,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+
+	if len(entries) == 0 {
+		fmt.Fprint(out, ui.Info("No snapshots found"))
+		return nil
+	}
+
+	// Calculate column widths for alignment.
+	maxRef := 0
+	maxTask := 0
+	for _, e := range entries {
+		maxRef = max(maxRef, len(e.Ref))
+		maxTask = max(maxTask, len(e.Label.TaskID))
+	}
+
+	boldCode := ui.ResolveStyle(ui.WeightBold)
+	dimCode := ui.ResolveStyle(ui.WeightDim)
+	resetCode := ui.ResolveStyle(ui.WeightNormal)
+
+	for _, e := range entries {
+		fmt.Fprintf(out, "  %s%-*s%s  %s%s%s  %-*s  step %d/%d — %s\n",
+			dimCode, maxRef, e.Ref, resetCode,
+			dimCode, e.Time.Format("2006-01-02 15:04"), resetCode,
+			maxTask, e.Label.TaskID,
+			e.Label.Step, e.Label.Total, boldCode+e.Label.Name+resetCode)
+	}
+
+	return nil
+}
+
+// parseSnapshotTime parses a --since/--until value. A duration is taken as
+// relative to now; otherwise RFC 3339 and YYYY-MM-DD (local time) are accepted.
+// An empty value returns the zero time (no bound).
+func parseSnapshotTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration (e.g. 2h) or time (RFC 3339 or YYYY-MM-DD)", value)
+}
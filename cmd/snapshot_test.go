@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/snapshot"
+)
+
+func TestParseSnapshotTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseSnapshotTime("", now)
+	require.NoError(t, err)
+	assert.True(t, got.IsZero())
+
+	got, err = parseSnapshotTime("2h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-2*time.Hour), got)
+
+	got, err = parseSnapshotTime("2026-03-09T08:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC), got)
+
+	got, err = parseSnapshotTime("2026-03-09", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local), got)
+
+	_, err = parseSnapshotTime("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestSnapshotList_FiltersByTask(t *testing.T) {
+	projectDir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		c := exec.CommandContext(context.Background(), "git", args...)
+		c.Dir = projectDir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	git("init")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte("# init"), 0o600))
+	git("add", ".")
+	git("commit", "-m", "initial commit")
+
+	s := snapshot.New(projectDir)
+	for i, task := range []string{"TASK1", "TASK2"} {
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte(task), 0o600))
+		_, err := s.Capture(context.Background(), snapshot.Label{TaskID: task, Step: i + 1, Total: 10, Name: "Implement"}.String())
+		require.NoError(t, err)
+	}
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(projectDir))
+	defer func() { require.NoError(t, os.Chdir(origDir)) }()
+
+	var outBuf strings.Builder
+	snapshotListCmd.SetOut(&outBuf)
+	defer snapshotListCmd.SetOut(nil)
+
+	snapshotTask = "TASK2"
+	defer func() { snapshotTask = "" }()
+
+	require.NoError(t, snapshotListCmd.RunE(snapshotListCmd, nil))
+
+	output := outBuf.String()
+	assert.Contains(t, output, "stash@{0}")
+	assert.Contains(t, output, "TASK2")
+	assert.Contains(t, output, "step 2/10")
+	assert.NotContains(t, output, "TASK1")
+
+	outBuf.Reset()
+	snapshotTask = "TASK9"
+	require.NoError(t, snapshotListCmd.RunE(snapshotListCmd, nil))
+	assert.Contains(t, outBuf.String(), "No snapshots found")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -22,6 +23,7 @@ var statusCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output status as JSON")
 }
 
 func statusRun(cmd *cobra.Command, args []string) error {
@@ -37,6 +39,23 @@ func statusRun(cmd *cobra.Command, args []string) error {
 
 	out := cmd.OutOrStdout()
 
+	if jsonOutput {
+		if st.Tasks == nil {
+			st.Tasks = []session.TaskStatus{}
+		}
+		data, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal status: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	steps, err := stateSteps(st.SkippedSteps)
+	if err != nil {
+		return err
+	}
+
 	fmt.Fprint(out, ui.KeyValue("Session", st.Name))
 	fmt.Fprint(out, ui.KeyValue("Path   ", st.TasksDir))
 
@@ -54,9 +73,12 @@ func statusRun(cmd *cobra.Command, args []string) error {
 		switch {
 		case task.Completed:
 			completedCount++
-			fmt.Fprint(out, ui.TaskDone(task.ID))
+			fmt.Fprint(out, ui.TaskDone(taskDoneLabel(task)))
+			if summary := workflow.DirectivesSummary(task.Directives); summary != "" {
+				fmt.Fprint(out, ui.Info("      "+summary))
+			}
 		case task.ID == st.ActiveTask && st.ActiveStep > 0:
-			suffix := fmt.Sprintf("step %d/%d: %s", st.ActiveStep, st.TotalSteps, workflow.StepName(st.ActiveStep))
+			suffix := fmt.Sprintf("step %d/%d: %s", st.ActiveStep, st.TotalSteps, workflow.StepName(steps, st.ActiveStep))
 			fmt.Fprint(out, ui.TaskActive(task.ID, suffix))
 		default:
 			fmt.Fprint(out, ui.TaskPending(task.ID))
@@ -70,6 +92,21 @@ func statusRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// taskDoneLabel appends the recorded provider/model to a completed task ID,
+// or "(skipped)" for a task the user skipped.
+func taskDoneLabel(task session.TaskStatus) string {
+	switch {
+	case task.Skipped:
+		return task.ID + " (skipped)"
+	case task.Model == "":
+		return task.ID
+	case task.Provider == "":
+		return fmt.Sprintf("%s (%s)", task.ID, task.Model)
+	default:
+		return fmt.Sprintf("%s (%s/%s)", task.ID, task.Provider, task.Model)
+	}
+}
+
 // resolveStatusSession resolves the session name for the status command.
 func resolveStatusSession(args []string) (string, error) {
 	if len(args) > 0 {
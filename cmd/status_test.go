@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -58,6 +59,139 @@ func TestStatus_WithTasksAndActiveStep(t *testing.T) {
 	assert.Contains(t, output, "1 complete")
 }
 
+func TestStatus_NamesStepsAfterSkippedSteps(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+
+	sessDir := filepath.Join(projectDir, ".snap", "sessions", "auth")
+	tasksDir := filepath.Join(sessDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1\n"), 0o600))
+	stateJSON := `{
+		"tasks_dir": "tasks",
+		"current_task_id": "TASK1",
+		"current_task_file": "TASK1.md",
+		"current_step": 4,
+		"total_steps": 8,
+		"skipped_steps": ["Code review", "Update docs"],
+		"completed_task_ids": []
+	}`
+	require.NoError(t, os.WriteFile(filepath.Join(sessDir, "state.json"), []byte(stateJSON), 0o600))
+
+	var outBuf strings.Builder
+	statusCmd.SetOut(&outBuf)
+	defer statusCmd.SetOut(nil)
+
+	require.NoError(t, statusCmd.RunE(statusCmd, []string{"auth"}))
+	assert.Contains(t, outBuf.String(), "step 4/8: Apply fixes")
+}
+
+func TestStatus_ShowsProviderAndModelForCompletedTasks(t *testing.T) {
+	projectDir := t.TempDir()
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(projectDir))
+	defer func() { require.NoError(t, os.Chdir(origDir)) }()
+
+	sessDir := filepath.Join(projectDir, ".snap", "sessions", "auth")
+	tasksDir := filepath.Join(sessDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK2.md"), []byte("# Task 2\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK3.md"), []byte("# Task 3\n"), 0o600))
+
+	// TASK2 was completed by an older version and has no record; TASK3 was
+	// skipped with /skip.
+	stateJSON := `{
+		"tasks_dir": "tasks",
+		"current_step": 1,
+		"total_steps": 10,
+		"completed_task_ids": ["TASK1", "TASK2", "TASK3"],
+		"completed_tasks": {
+			"TASK1": {"completed_at": "2025-01-01T00:00:00Z", "provider": "codex", "model": "gpt-5.3-codex",
+				"directives": ["use the existing logger"]},
+			"TASK3": {"completed_at": "2025-01-01T00:00:00Z", "provider": "codex", "model": "gpt-5.3-codex",
+				"skipped": true}
+		},
+		"session_id": "",
+		"last_updated": "2025-01-01T00:00:00Z",
+		"prd_path": "tasks/PRD.md"
+	}`
+	require.NoError(t, os.WriteFile(filepath.Join(sessDir, "state.json"), []byte(stateJSON), 0o600))
+
+	var outBuf strings.Builder
+	statusCmd.SetOut(&outBuf)
+	defer statusCmd.SetOut(nil)
+
+	require.NoError(t, statusCmd.RunE(statusCmd, []string{"auth"}))
+
+	output := outBuf.String()
+	assert.Contains(t, output, "[x] TASK1 (codex/gpt-5.3-codex)")
+	assert.Contains(t, output, "1 directive applied: use the existing logger")
+	assert.Contains(t, output, "[x] TASK2\n")
+	assert.Contains(t, output, "[x] TASK3 (skipped)")
+}
+
+func TestStatus_JSON(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+
+	sessDir := filepath.Join(projectDir, ".snap", "sessions", "auth")
+	tasksDir := filepath.Join(sessDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK2.md"), []byte("# Task 2\n"), 0o600))
+
+	stateJSON := `{
+		"tasks_dir": "tasks",
+		"current_task_id": "TASK2",
+		"current_step": 4,
+		"total_steps": 10,
+		"completed_task_ids": ["TASK1"],
+		"completed_tasks": {"TASK1": {"completed_at": "2025-01-01T00:00:00Z", "provider": "claude", "model": "opus"}},
+		"last_updated": "2025-01-01T00:00:00Z"
+	}`
+	require.NoError(t, os.WriteFile(filepath.Join(sessDir, "state.json"), []byte(stateJSON), 0o600))
+
+	jsonOutput = true
+	t.Cleanup(func() { jsonOutput = false })
+
+	var outBuf strings.Builder
+	statusCmd.SetOut(&outBuf)
+	defer statusCmd.SetOut(nil)
+
+	require.NoError(t, statusCmd.RunE(statusCmd, []string{"auth"}))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal([]byte(outBuf.String()), &got), outBuf.String())
+	assert.Equal(t, "auth", got["name"])
+	assert.Equal(t, "TASK2", got["current_task_id"])
+	assert.EqualValues(t, 4, got["current_step"])
+	assert.EqualValues(t, 10, got["total_steps"])
+	assert.Equal(t, []any{
+		map[string]any{"id": "TASK1", "completed": true, "provider": "claude", "model": "opus"},
+		map[string]any{"id": "TASK2", "completed": false},
+	}, got["tasks"])
+}
+
+func TestStatus_JSONNoTasks(t *testing.T) {
+	projectDir := t.TempDir()
+	chdir(t, projectDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".snap", "sessions", "auth", "tasks"), 0o755))
+
+	jsonOutput = true
+	t.Cleanup(func() { jsonOutput = false })
+
+	var outBuf strings.Builder
+	statusCmd.SetOut(&outBuf)
+	defer statusCmd.SetOut(nil)
+
+	require.NoError(t, statusCmd.RunE(statusCmd, []string{"auth"}))
+	assert.Contains(t, outBuf.String(), `"tasks": []`)
+	assert.NotContains(t, outBuf.String(), "current_task_id")
+}
+
 func TestStatus_NoTasks(t *testing.T) {
 	projectDir := t.TempDir()
 
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/snap/internal/plan"
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/ui"
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [session]",
+	Short: "Check a tasks directory before running",
+	Long: `Check the task files, PRD.md and TASKS.md of a session (or --tasks-dir)
+without calling the provider.
+
+Exits non-zero when something would stop or derail a run: no task files,
+duplicate task numbers, misnamed task files, a missing PRD.md, or task files
+listed in TASKS.md that don't exist. Gaps in task numbering and task files
+missing from TASKS.md are reported as warnings.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          validateRun,
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&tasksGlob, "tasks-glob", "", "Task file name pattern the run uses, e.g. \"story-*.md\" (default: TASK<n>.md)")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validateReport collects the findings of snap validate.
+type validateReport struct {
+	problems []string // Blocking: the run would fail or skip work
+	warnings []string
+}
+
+func validateRun(cmd *cobra.Command, args []string) error {
+	dir, err := resolveValidateDir(cmd, args)
+	if err != nil {
+		return markPreflight(err)
+	}
+	if !dirExists(dir) {
+		return markPreflight(fmt.Errorf("tasks directory not found: %s", dir))
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprint(out, ui.KeyValue("Tasks", dir))
+	fmt.Fprintln(out)
+
+	// A custom glob names tasks freely, so the TASK<n>.md numbering and
+	// naming checks only apply without one.
+	var report validateReport
+	tasks, err := workflow.ScanTasksGlob(dir, tasksGlob)
+	switch {
+	case err != nil:
+		report.problems = append(report.problems, err.Error())
+	case len(tasks) == 0 && tasksGlob != "":
+		return markPreflight(fmt.Errorf("no task files matching %s found in %s", tasksGlob, dir))
+	case len(tasks) == 0:
+		return markPreflight(fmt.Errorf("%s", workflow.FormatTaskDirError(dir, workflow.DiagnoseEmptyTaskDir(dir))))
+	default:
+		fmt.Fprintln(out, ui.Success(fmt.Sprintf("%d task files (%s … %s)", len(tasks), tasks[0].Filename, tasks[len(tasks)-1].Filename)))
+		if missing := missingTaskNumbers(tasks); len(missing) > 0 && tasksGlob == "" {
+			report.warnings = append(report.warnings, fmt.Sprintf("Task numbering has gaps: no %s", strings.Join(missing, ", ")))
+		}
+	}
+	if tasksGlob == "" {
+		report.problems = append(report.problems, workflow.MisnamedTaskFiles(dir)...)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "PRD.md")); err == nil {
+		fmt.Fprintln(out, ui.Success("PRD.md found"))
+	} else {
+		report.problems = append(report.problems, "PRD.md not found; the workflow steps read it for context")
+	}
+
+	if err := validateTaskList(out, dir, tasks, &report); err != nil {
+		return err
+	}
+
+	for _, warning := range report.warnings {
+		fmt.Fprint(out, ui.Interrupted(warning))
+	}
+	for _, problem := range report.problems {
+		fmt.Fprintln(out, ui.Error(problem))
+	}
+	fmt.Fprintln(out)
+
+	if n := len(report.problems); n > 0 {
+		noun := "issues"
+		if n == 1 {
+			noun = "issue"
+		}
+		return markPreflight(fmt.Errorf("%d blocking %s found in %s", n, noun, dir))
+	}
+	fmt.Fprint(out, ui.Info("No blocking issues found"))
+	return nil
+}
+
+// validateTaskList checks TASKS.md, when present, against the task files.
+func validateTaskList(out io.Writer, dir string, tasks []workflow.TaskInfo, report *validateReport) error {
+	data, err := os.ReadFile(filepath.Join(dir, "TASKS.md"))
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprint(out, ui.Info("No TASKS.md; skipping the task list check"))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read TASKS.md: %w", err)
+	}
+
+	specs := plan.ExtractTaskSpecs(string(data))
+	if len(specs) == 0 {
+		report.warnings = append(report.warnings, "TASKS.md has no task list (section G); skipping the task list check")
+		return nil
+	}
+	fmt.Fprintln(out, ui.Success(fmt.Sprintf("TASKS.md lists %d tasks", len(specs))))
+	if tasks == nil {
+		return nil // The task files couldn't be scanned; that's already reported
+	}
+
+	onDisk := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		onDisk[task.Filename] = true
+	}
+	listed := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if listed[spec.File] {
+			report.problems = append(report.problems, fmt.Sprintf("TASKS.md lists %s more than once", spec.File))
+			continue
+		}
+		listed[spec.File] = true
+		if !onDisk[spec.File] {
+			report.problems = append(report.problems, fmt.Sprintf("TASKS.md lists %s, but the file doesn't exist", spec.File))
+		}
+	}
+	for _, task := range tasks {
+		if !listed[task.Filename] {
+			report.warnings = append(report.warnings, fmt.Sprintf("%s is not listed in TASKS.md", task.Filename))
+		}
+	}
+	return nil
+}
+
+// missingTaskNumbers returns the task IDs missing between TASK1 and the
+// highest numbered task.
+func missingTaskNumbers(tasks []workflow.TaskInfo) []string {
+	present := make(map[int]bool, len(tasks))
+	highest := 0
+	for _, task := range tasks {
+		present[task.Number] = true
+		highest = max(highest, task.Number)
+	}
+	var missing []string
+	for n := 1; n <= highest; n++ {
+		if !present[n] {
+			missing = append(missing, "TASK"+strconv.Itoa(n))
+		}
+	}
+	return missing
+}
+
+// resolveValidateDir picks the tasks directory to check: --tasks-dir when
+// set, otherwise the one snap run would use (session.ResolveForRun, with the
+// legacy tasks directory from .snaprc).
+func resolveValidateDir(cmd *cobra.Command, args []string) (string, error) {
+	if tasksGlob != "" {
+		if err := workflow.ValidateTasksGlob(tasksGlob); err != nil {
+			return "", err
+		}
+	}
+	if cmd.Flags().Changed("tasks-dir") {
+		if len(args) > 0 {
+			return "", errors.New("--tasks-dir cannot be used with a session name")
+		}
+		return tasksDir, nil
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return "", err
+	}
+	legacyTasksDir, err := cfg.Path("tasks-dir")
+	if err != nil {
+		return "", err
+	}
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+	target, err := session.ResolveForRun(".", name, legacyTasksDir)
+	if err != nil {
+		var multi *session.MultipleSessionsError
+		if errors.As(err, &multi) {
+			return "", formatMultipleSessionsError(multi.Sessions, "validate")
+		}
+		return "", err
+	}
+	return target.TasksDir, nil
+}
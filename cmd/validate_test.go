@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+func TestValidate(t *testing.T) {
+	setup := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+		projectDir := t.TempDir()
+		chdir(t, projectDir)
+		require.NoError(t, session.Create(".", "auth"))
+		dir := session.TasksDir(".", "auth")
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+		}
+		return dir
+	}
+	run := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		var outBuf strings.Builder
+		validateCmd.SetOut(&outBuf)
+		defer validateCmd.SetOut(nil)
+		err := validateCmd.RunE(validateCmd, args)
+		return ui.StripColors(outBuf.String()), err
+	}
+	taskList := "## G. Task List\n\n| # | File | Name |\n|---|---|---|\n| 1 | TASK1.md | Login |\n| 2 | TASK2.md | Logout |\n"
+
+	t.Run("valid directory", func(t *testing.T) {
+		setup(t, map[string]string{"PRD.md": "# PRD", "TASKS.md": taskList, "TASK1.md": "# 1", "TASK2.md": "# 2"})
+
+		output, err := run(t)
+		require.NoError(t, err)
+		assert.Contains(t, output, "2 task files (TASK1.md … TASK2.md)")
+		assert.Contains(t, output, "PRD.md found")
+		assert.Contains(t, output, "TASKS.md lists 2 tasks")
+		assert.Contains(t, output, "No blocking issues found")
+	})
+
+	t.Run("reports blocking issues and warnings", func(t *testing.T) {
+		setup(t, map[string]string{"TASKS.md": taskList, "TASK1.md": "# 1", "TASK3.md": "# 3", "task4.md": "# 4"})
+
+		output, err := run(t, "auth")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrPreflight))
+		assert.Contains(t, err.Error(), "3 blocking issues")
+		assert.Contains(t, output, "Found: task4.md (rename to TASK4.md)")
+		assert.Contains(t, output, "PRD.md not found")
+		assert.Contains(t, output, "TASKS.md lists TASK2.md, but the file doesn't exist")
+		assert.Contains(t, output, "Task numbering has gaps: no TASK2")
+		assert.Contains(t, output, "TASK3.md is not listed in TASKS.md")
+	})
+
+	t.Run("duplicate task numbers", func(t *testing.T) {
+		setup(t, map[string]string{"PRD.md": "# PRD", "TASK1.md": "# 1", "TASK01.md": "# 1 again"})
+
+		output, err := run(t)
+		require.Error(t, err)
+		assert.Contains(t, output, "duplicate task number 1")
+		assert.Contains(t, output, "No TASKS.md")
+	})
+
+	t.Run("empty directory reuses the run diagnostics", func(t *testing.T) {
+		setup(t, map[string]string{"PRD.md": "# PRD", "task1.md": "# 1"})
+
+		_, err := run(t)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrPreflight))
+		assert.Contains(t, err.Error(), "no task files found")
+		assert.Contains(t, err.Error(), "Found: task1.md (rename to TASK1.md)")
+	})
+
+	t.Run("tasks glob", func(t *testing.T) {
+		setup(t, map[string]string{"PRD.md": "# PRD", "story-1.md": "# 1", "story-3.md": "# 3", "task4.md": "# 4"})
+		tasksGlob = "story-*.md"
+		t.Cleanup(func() { tasksGlob = "" })
+
+		output, err := run(t)
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "2 task files (story-1.md … story-3.md)")
+		assert.NotContains(t, output, "Task numbering has gaps")
+		assert.NotContains(t, output, "task4.md")
+
+		tasksGlob = "epic-*.md"
+		_, err = run(t)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no task files matching epic-*.md found in")
+	})
+
+	t.Run("several sessions need a name", func(t *testing.T) {
+		setup(t, map[string]string{"PRD.md": "# PRD", "TASK1.md": "# 1"})
+		require.NoError(t, session.Create(".", "billing"))
+
+		_, err := run(t)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "multiple sessions found")
+		assert.Contains(t, err.Error(), "snap validate <name>")
+
+		output, err := run(t, "auth")
+		require.NoError(t, err, output)
+	})
+}
@@ -4,26 +4,90 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/procio"
 	"github.com/yarlson/snap/internal/ui"
 )
 
 // Executor runs the claude CLI and streams its output.
-type Executor struct{}
+type Executor struct {
+	binary string
+	models map[model.Type]string // Per-type model name overrides
+	env    []string              // KEY=VALUE pairs set on every CLI process
+}
+
+// Option configures an Executor.
+type Option func(*Executor)
+
+// WithBinary sets the path of the claude binary, typically resolved once at
+// startup so each invocation skips the PATH lookup. Defaults to "claude".
+func WithBinary(path string) Option {
+	return func(e *Executor) {
+		if path != "" {
+			e.binary = path
+		}
+	}
+}
+
+// WithModel pins the model name passed to the CLI for mt, replacing the
+// built-in default. An empty name keeps the default.
+func WithModel(mt model.Type, name string) Option {
+	return func(e *Executor) {
+		if name == "" {
+			return
+		}
+		if e.models == nil {
+			e.models = make(map[model.Type]string)
+		}
+		e.models[mt] = name
+	}
+}
+
+// WithEnv sets environment variables on every claude process, over the
+// inherited environment.
+func WithEnv(env map[string]string) Option {
+	return func(e *Executor) {
+		for _, key := range slices.Sorted(maps.Keys(env)) {
+			e.env = append(e.env, key+"="+env[key])
+		}
+	}
+}
 
 // NewExecutor creates a new claude CLI executor.
-func NewExecutor() *Executor {
-	return &Executor{}
+func NewExecutor(opts ...Option) *Executor {
+	e := &Executor{binary: "claude"}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
-// resolveModel maps an abstract model type to a Claude-specific model name.
-func resolveModel(mt model.Type) string {
+// ModelName returns the model name passed to the CLI for the given type.
+func (e *Executor) ModelName(mt model.Type) string {
+	return e.resolveModel(mt)
+}
+
+// resolveModel returns the model name for mt: the pinned override, or the
+// Claude default.
+func (e *Executor) resolveModel(mt model.Type) string {
+	if name, ok := e.models[mt]; ok {
+		return name
+	}
+	return defaultModel(mt)
+}
+
+// defaultModel maps an abstract model type to a Claude-specific model name.
+func defaultModel(mt model.Type) string {
 	switch mt {
 	case model.Fast:
 		return "haiku"
@@ -36,7 +100,23 @@ func resolveModel(mt model.Type) string {
 
 // Run executes the claude CLI with the given arguments and streams parsed output to the writer.
 // The model parameter is resolved to a Claude-specific model name and passed via --model flag.
+// The CLI's stderr is only reported in the error when the command fails.
 func (e *Executor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	return e.RunSplit(ctx, w, nil, mt, args...)
+}
+
+// RunSplit is Run with the CLI's stderr also streamed to stderr as it
+// arrives. A nil stderr behaves like Run.
+func (e *Executor) RunSplit(ctx context.Context, w, stderr io.Writer, mt model.Type, args ...string) error {
+	return e.run(ctx, "", w, stderr, mt, args...)
+}
+
+// RunInDir is Run with the CLI started in dir instead of the current directory.
+func (e *Executor) RunInDir(ctx context.Context, dir string, w io.Writer, mt model.Type, args ...string) error {
+	return e.run(ctx, dir, w, nil, mt, args...)
+}
+
+func (e *Executor) run(ctx context.Context, dir string, w, stderr io.Writer, mt model.Type, args ...string) error {
 	// Add required flags for stream-json output
 	fullArgs := []string{
 		"--dangerously-skip-permissions",
@@ -45,41 +125,47 @@ func (e *Executor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...
 		"--include-partial-messages",
 		"--verbose",
 	}
-	if resolved := resolveModel(mt); resolved != "" {
+	if resolved := e.resolveModel(mt); resolved != "" {
 		fullArgs = append(fullArgs, "--model", resolved)
 	}
 	fullArgs = append(fullArgs, args...)
 
-	cmd := exec.CommandContext(ctx, "claude", fullArgs...)
+	cmd := exec.CommandContext(ctx, e.binary, fullArgs...)
+	cmd.Dir = dir
+	if len(e.env) > 0 {
+		// Later entries win, so these override the inherited values.
+		cmd.Env = append(os.Environ(), e.env...)
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	stderr, err := cmd.StderrPipe()
+	stderrPipe, err := cmd.StderrPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("claude CLI no longer found at %s (was it removed or moved during the run?): %w", e.binary, err)
+		}
 		return fmt.Errorf("failed to start claude command: %w", err)
 	}
 
+	// Read stderr alongside stdout so it can be streamed while the step runs
+	stderrBuf, stderrDone := procio.CollectStderr(stderrPipe, stderr)
+
 	// Parse and stream output in real-time
 	parser := NewStreamParser(w)
 	parseErr := parser.Parse(stdout)
-
-	// Read stderr
-	var stderrBytes []byte
-	if stderrData, err := io.ReadAll(stderr); err == nil {
-		stderrBytes = stderrData
-	}
+	<-stderrDone
 
 	// Wait for command to complete
 	if err := cmd.Wait(); err != nil {
-		if len(stderrBytes) > 0 {
-			return fmt.Errorf("claude command failed: %w (stderr: %s)", err, string(stderrBytes))
+		if stderrBuf.Len() > 0 {
+			return fmt.Errorf("claude command failed: %w (stderr: %s)", err, stderrBuf.String())
 		}
 		return fmt.Errorf("claude command failed: %w", err)
 	}
@@ -3,6 +3,8 @@ package claude_test
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -49,6 +51,24 @@ func TestExecutor_Run(t *testing.T) {
 	}
 }
 
+func TestExecutor_ModelName(t *testing.T) {
+	executor := claude.NewExecutor()
+	assert.Equal(t, "opus", executor.ModelName(model.Thinking))
+	assert.Equal(t, "haiku", executor.ModelName(model.Fast))
+}
+
+func TestExecutor_WithModel(t *testing.T) {
+	executor := claude.NewExecutor(claude.WithModel(model.Thinking, "claude-sonnet-4"))
+	assert.Equal(t, "claude-sonnet-4", executor.ModelName(model.Thinking))
+	assert.Equal(t, "haiku", executor.ModelName(model.Fast))
+
+	// The pinned name is what the CLI receives.
+	cli := fakeCLI(t, "echo \"$@\" >&2\nexit 1\n")
+	err := claude.NewExecutor(claude.WithBinary(cli), claude.WithModel(model.Fast, "my-fast")).Run(context.Background(), &bytes.Buffer{}, model.Fast)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--model my-fast")
+}
+
 func TestStreamParser(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -157,3 +177,55 @@ func stripColors(s string) string {
 	}
 	return result.String()
 }
+
+// fakeCLI writes an executable script standing in for the claude binary.
+func fakeCLI(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claude")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestExecutor_RunSplit_StreamsStderr(t *testing.T) {
+	executor := claude.NewExecutor(claude.WithBinary(fakeCLI(t, "echo 'loading model' >&2\n")))
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, executor.RunSplit(context.Background(), &stdout, &stderr, model.Fast))
+	assert.Equal(t, "loading model\n", stderr.String())
+	assert.NotContains(t, stdout.String(), "loading model")
+}
+
+func TestExecutor_Run_StderrOnlyInError(t *testing.T) {
+	executor := claude.NewExecutor(claude.WithBinary(fakeCLI(t, "echo 'loading model' >&2\n")))
+
+	var stdout bytes.Buffer
+	require.NoError(t, executor.Run(context.Background(), &stdout, model.Fast))
+	assert.NotContains(t, stdout.String(), "loading model")
+
+	failing := claude.NewExecutor(claude.WithBinary(fakeCLI(t, "echo 'rate limited' >&2\nexit 1\n")))
+	err := failing.Run(context.Background(), &stdout, model.Fast)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limited")
+}
+
+func TestExecutor_RunInDir(t *testing.T) {
+	executor := claude.NewExecutor(claude.WithBinary(fakeCLI(t, "touch ran-here\n")))
+	dir := t.TempDir()
+
+	var stdout bytes.Buffer
+	require.NoError(t, executor.RunInDir(context.Background(), dir, &stdout, model.Fast))
+	assert.FileExists(t, filepath.Join(dir, "ran-here"))
+}
+
+func TestExecutor_WithEnv(t *testing.T) {
+	t.Setenv("SNAP_TEST_ENDPOINT", "from-parent")
+	t.Setenv("SNAP_TEST_INHERITED", "kept")
+	executor := claude.NewExecutor(
+		claude.WithBinary(fakeCLI(t, "echo \"$SNAP_TEST_ENDPOINT $SNAP_TEST_INHERITED $SNAP_TEST_PROJECT\" >&2\n")),
+		claude.WithEnv(map[string]string{"SNAP_TEST_ENDPOINT": "http://localhost:8080", "SNAP_TEST_PROJECT": "demo"}),
+	)
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, executor.RunSplit(context.Background(), &stdout, &stderr, model.Fast))
+	assert.Equal(t, "http://localhost:8080 kept demo\n", stderr.String(), "injected variables override the inherited environment")
+}
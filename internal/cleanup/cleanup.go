@@ -0,0 +1,101 @@
+// Package cleanup tracks temporary resources, such as git worktrees, so they
+// are removed however a run ends: the owner releases them on its normal path,
+// and whatever an interrupt or early return left behind is removed when the
+// run unwinds.
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Registry holds pending cleanups. The zero value is ready to use, and a nil
+// *Registry only runs cleanups when they are released.
+type Registry struct {
+	mu      sync.Mutex
+	next    int
+	entries []entry
+}
+
+type entry struct {
+	id   int
+	name string
+	fn   func(context.Context) error
+}
+
+// Add registers fn to remove the resource described by name and returns a
+// release func. Release runs fn (once) and unregisters it; call it where the
+// resource is no longer needed. Cleanups never see a cancelled context.
+func (r *Registry) Add(name string, fn func(context.Context) error) (release func() error) {
+	var once sync.Once
+	run := func() error {
+		var err error
+		once.Do(func() {
+			if err = fn(context.Background()); err != nil {
+				err = fmt.Errorf("%s: %w", name, err)
+			}
+		})
+		return err
+	}
+	if r == nil {
+		return run
+	}
+
+	r.mu.Lock()
+	r.next++
+	id := r.next
+	r.entries = append(r.entries, entry{id: id, name: name, fn: func(context.Context) error { return run() }})
+	r.mu.Unlock()
+
+	return func() error {
+		r.remove(id)
+		return run()
+	}
+}
+
+// Pending returns the names of the cleanups not yet released, oldest first.
+func (r *Registry) Pending() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, len(r.entries))
+	for i, e := range r.entries {
+		names[i] = e.name
+	}
+	return names
+}
+
+// Run runs every pending cleanup, newest first, and returns their errors
+// joined. A failing cleanup doesn't stop the others.
+func (r *Registry) Run() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	entries := r.entries
+	r.entries = nil
+	r.mu.Unlock()
+
+	var errs []error
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := entries[i].fn(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) remove(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.entries {
+		if e.id == id {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_RunIsNewestFirst(t *testing.T) {
+	var r Registry
+	var order []string
+	for _, name := range []string{"a", "b", "c"} {
+		r.Add(name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, r.Pending())
+
+	require.NoError(t, r.Run())
+	assert.Equal(t, []string{"c", "b", "a"}, order)
+	assert.Empty(t, r.Pending())
+	require.NoError(t, r.Run(), "a second Run has nothing to do")
+	assert.Len(t, order, 3)
+}
+
+func TestRegistry_ReleaseRunsOnceAndUnregisters(t *testing.T) {
+	var r Registry
+	calls := 0
+	release := r.Add("worktree", func(ctx context.Context) error {
+		calls++
+		return ctx.Err()
+	})
+	r.Add("other", func(context.Context) error { return nil })
+
+	require.NoError(t, release())
+	require.NoError(t, release())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"other"}, r.Pending())
+
+	require.NoError(t, r.Run())
+	assert.Equal(t, 1, calls, "released cleanups don't run again")
+}
+
+func TestRegistry_RunJoinsErrors(t *testing.T) {
+	var r Registry
+	ran := false
+	r.Add("first", func(context.Context) error { return errors.New("busy") })
+	r.Add("second", func(context.Context) error {
+		ran = true
+		return nil
+	})
+	r.Add("third", func(context.Context) error { return errors.New("gone") })
+
+	err := r.Run()
+	require.Error(t, err)
+	assert.True(t, ran, "a failing cleanup doesn't stop the others")
+	assert.Contains(t, err.Error(), "first: busy")
+	assert.Contains(t, err.Error(), "third: gone")
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	var r *Registry
+	calls := 0
+	release := r.Add("worktree", func(context.Context) error {
+		calls++
+		return nil
+	})
+	assert.Empty(t, r.Pending())
+	require.NoError(t, r.Run())
+	assert.Equal(t, 0, calls)
+
+	require.NoError(t, release())
+	assert.Equal(t, 1, calls)
+}
@@ -4,13 +4,18 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/procio"
 	"github.com/yarlson/snap/internal/ui"
 )
 
@@ -20,11 +25,56 @@ const (
 )
 
 // Executor runs the codex CLI and streams parsed output.
-type Executor struct{}
+type Executor struct {
+	binary string
+	models map[model.Type]string // Per-type model name overrides
+	env    []string              // KEY=VALUE pairs set on every CLI process
+}
+
+// Option configures an Executor.
+type Option func(*Executor)
+
+// WithBinary sets the path of the codex binary, typically resolved once at
+// startup so each invocation skips the PATH lookup. Defaults to "codex".
+func WithBinary(path string) Option {
+	return func(e *Executor) {
+		if path != "" {
+			e.binary = path
+		}
+	}
+}
+
+// WithModel pins the model name passed to the CLI for mt, replacing the
+// built-in default. An empty name keeps the default.
+func WithModel(mt model.Type, name string) Option {
+	return func(e *Executor) {
+		if name == "" {
+			return
+		}
+		if e.models == nil {
+			e.models = make(map[model.Type]string)
+		}
+		e.models[mt] = name
+	}
+}
+
+// WithEnv sets environment variables on every codex process, over the
+// inherited environment.
+func WithEnv(env map[string]string) Option {
+	return func(e *Executor) {
+		for _, key := range slices.Sorted(maps.Keys(env)) {
+			e.env = append(e.env, key+"="+env[key])
+		}
+	}
+}
 
 // NewExecutor creates a new codex CLI executor.
-func NewExecutor() *Executor {
-	return &Executor{}
+func NewExecutor(opts ...Option) *Executor {
+	e := &Executor{binary: "codex"}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // ProviderName returns the provider identifier.
@@ -32,8 +82,22 @@ func (e *Executor) ProviderName() string {
 	return "codex"
 }
 
-// resolveModel maps an abstract model type to a Codex-specific model name.
-func resolveModel(mt model.Type) string {
+// ModelName returns the model name passed to the CLI for the given type.
+func (e *Executor) ModelName(mt model.Type) string {
+	return e.resolveModel(mt)
+}
+
+// resolveModel returns the model name for mt: the pinned override, or the
+// Codex default.
+func (e *Executor) resolveModel(mt model.Type) string {
+	if name, ok := e.models[mt]; ok {
+		return name
+	}
+	return defaultModel(mt)
+}
+
+// defaultModel maps an abstract model type to a Codex-specific model name.
+func defaultModel(mt model.Type) string {
 	switch mt {
 	case model.Fast:
 		return "gpt-5.3-codex-spark"
@@ -46,38 +110,60 @@ func resolveModel(mt model.Type) string {
 
 // Run executes codex with JSONL output and streams parsed output in real-time.
 // The model parameter is resolved to a Codex-specific model name and passed via --model flag.
+// The CLI's stderr is only reported in the error when the command fails.
 func (e *Executor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	return e.RunSplit(ctx, w, nil, mt, args...)
+}
+
+// RunSplit is Run with the CLI's stderr also streamed to stderr as it
+// arrives. A nil stderr behaves like Run.
+func (e *Executor) RunSplit(ctx context.Context, w, stderr io.Writer, mt model.Type, args ...string) error {
+	return e.run(ctx, "", w, stderr, mt, args...)
+}
+
+// RunInDir is Run with the CLI started in dir instead of the current directory.
+func (e *Executor) RunInDir(ctx context.Context, dir string, w io.Writer, mt model.Type, args ...string) error {
+	return e.run(ctx, dir, w, nil, mt, args...)
+}
+
+func (e *Executor) run(ctx context.Context, dir string, w, stderr io.Writer, mt model.Type, args ...string) error {
 	cmdArgs := BuildCommandArgs(args...)
-	if resolved := resolveModel(mt); resolved != "" {
+	if resolved := e.resolveModel(mt); resolved != "" {
 		cmdArgs = append(cmdArgs, "--model", resolved)
 	}
-	cmd := exec.CommandContext(ctx, "codex", cmdArgs...)
+	cmd := exec.CommandContext(ctx, e.binary, cmdArgs...)
+	cmd.Dir = dir
+	if len(e.env) > 0 {
+		// Later entries win, so these override the inherited values.
+		cmd.Env = append(os.Environ(), e.env...)
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	stderr, err := cmd.StderrPipe()
+	stderrPipe, err := cmd.StderrPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("codex CLI no longer found at %s (was it removed or moved during the run?): %w", e.binary, err)
+		}
 		return fmt.Errorf("failed to start codex command: %w", err)
 	}
 
+	stderrBuf, stderrDone := procio.CollectStderr(stderrPipe, stderr)
+
 	parser := NewEventParser(w)
 	parseErr := parser.Parse(stdout)
-
-	stderrBytes, readErr := io.ReadAll(stderr)
-	if readErr != nil {
-		stderrBytes = nil
-	}
+	<-stderrDone
 
 	if err := cmd.Wait(); err != nil {
-		if len(stderrBytes) > 0 {
-			return fmt.Errorf("codex command failed: %w (stderr: %s)", err, strings.TrimSpace(string(stderrBytes)))
+		if stderrBuf.Len() > 0 {
+			return fmt.Errorf("codex command failed: %w (stderr: %s)", err, strings.TrimSpace(stderrBuf.String()))
 		}
 		return fmt.Errorf("codex command failed: %w", err)
 	}
@@ -3,6 +3,8 @@ package codex_test
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -90,7 +92,72 @@ func TestEventParser_Parse(t *testing.T) {
 func TestExecutor_Metadata(t *testing.T) {
 	executor := codex.NewExecutor()
 	assert.Equal(t, "codex", executor.ProviderName())
+	assert.Equal(t, "gpt-5.3-codex", executor.ModelName(model.Thinking))
+	assert.Equal(t, "gpt-5.3-codex-spark", executor.ModelName(model.Fast))
 
 	err := executor.Run(context.Background(), &bytes.Buffer{}, model.Fast, "Reply with exactly hi")
 	_ = err // Runtime execution depends on local codex auth/setup; interface is exercised.
 }
+
+func TestExecutor_WithModel(t *testing.T) {
+	executor := codex.NewExecutor(codex.WithModel(model.Fast, "gpt-mini"))
+	assert.Equal(t, "gpt-mini", executor.ModelName(model.Fast))
+	assert.Equal(t, "gpt-5.3-codex", executor.ModelName(model.Thinking))
+
+	cli := fakeCLI(t, "echo \"$@\" >&2\nexit 1\n")
+	err := codex.NewExecutor(codex.WithBinary(cli), codex.WithModel(model.Thinking, "gpt-big")).Run(context.Background(), &bytes.Buffer{}, model.Thinking)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--model gpt-big")
+}
+
+// fakeCLI writes an executable script standing in for the codex binary.
+func fakeCLI(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "codex")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestExecutor_RunSplit_StreamsStderr(t *testing.T) {
+	executor := codex.NewExecutor(codex.WithBinary(fakeCLI(t, "echo 'loading model' >&2\n")))
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, executor.RunSplit(context.Background(), &stdout, &stderr, model.Fast))
+	assert.Equal(t, "loading model\n", stderr.String())
+	assert.NotContains(t, stdout.String(), "loading model")
+}
+
+func TestExecutor_Run_StderrOnlyInError(t *testing.T) {
+	executor := codex.NewExecutor(codex.WithBinary(fakeCLI(t, "echo 'loading model' >&2\n")))
+
+	var stdout bytes.Buffer
+	require.NoError(t, executor.Run(context.Background(), &stdout, model.Fast))
+	assert.NotContains(t, stdout.String(), "loading model")
+
+	failing := codex.NewExecutor(codex.WithBinary(fakeCLI(t, "echo 'rate limited' >&2\nexit 1\n")))
+	err := failing.Run(context.Background(), &stdout, model.Fast)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limited")
+}
+
+func TestExecutor_RunInDir(t *testing.T) {
+	executor := codex.NewExecutor(codex.WithBinary(fakeCLI(t, "touch ran-here\n")))
+	dir := t.TempDir()
+
+	var stdout bytes.Buffer
+	require.NoError(t, executor.RunInDir(context.Background(), dir, &stdout, model.Fast))
+	assert.FileExists(t, filepath.Join(dir, "ran-here"))
+}
+
+func TestExecutor_WithEnv(t *testing.T) {
+	t.Setenv("SNAP_TEST_ENDPOINT", "from-parent")
+	t.Setenv("SNAP_TEST_INHERITED", "kept")
+	executor := codex.NewExecutor(
+		codex.WithBinary(fakeCLI(t, "echo \"$SNAP_TEST_ENDPOINT $SNAP_TEST_INHERITED $SNAP_TEST_PROJECT\" >&2\n")),
+		codex.WithEnv(map[string]string{"SNAP_TEST_ENDPOINT": "http://localhost:8080", "SNAP_TEST_PROJECT": "demo"}),
+	)
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, executor.RunSplit(context.Background(), &stdout, &stderr, model.Fast))
+	assert.Equal(t, "http://localhost:8080 kept demo\n", stderr.String(), "injected variables override the inherited environment")
+}
@@ -0,0 +1,347 @@
+// Package config reads and writes snap's persisted defaults in .snaprc files.
+//
+// Values resolve in order: command-line flag (where a key has one and the
+// caller passed it with SetFlag), environment variable (where a key has one),
+// the repo-level .snaprc, the .snaprc in the user's home directory, then the
+// built-in default.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yarlson/snap/internal/pathutil"
+)
+
+// FileName is the name of the config file in the repo root and home directory.
+const FileName = ".snaprc"
+
+// Source identifies where an effective value came from.
+type Source string
+
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceRepo    Source = "repo"
+	SourceHome    Source = "home"
+	SourceDefault Source = "default"
+)
+
+// Key describes a supported configuration key.
+type Key struct {
+	Name        string
+	Default     string
+	Env         string // Environment variable that overrides config files (optional)
+	Flag        string // Command-line flag that overrides everything else (optional)
+	Description string
+	path        bool // Value is a path; Path expands environment variables in it
+	validate    func(string) error
+}
+
+var keys = []Key{
+	{Name: "provider", Default: "claude", Env: "SNAP_PROVIDER", Description: "Provider CLI (claude, codex)", validate: validateProvider},
+	{Name: "tasks-dir", Default: "docs/tasks", Flag: "tasks-dir", Description: "Tasks directory for the legacy layout", path: true, validate: validateNonEmpty},
+	{Name: "ci-poll", Default: "15s", Description: "CI status poll interval after push", validate: validatePositiveDuration},
+	{Name: "queue-interval", Default: "0s", Flag: "queue-interval", Description: "Minimum gap between queued prompts", validate: validateDuration},
+	{Name: "lint-command", Description: "Lint command for the lint/test step (empty = detect)", validate: validateCommand},
+	{Name: "test-command", Description: "Test command for the lint/test step (empty = detect)", validate: validateCommand},
+	{Name: "post-iteration-hook", Description: "Shell command run after each completed task (empty = none)", validate: validateCommand},
+	{Name: "hook-fatal", Default: "false", Description: "Stop the run when the post-iteration hook fails", validate: validateBool},
+}
+
+// Keys returns the supported configuration keys in display order.
+func Keys() []Key {
+	out := make([]Key, len(keys))
+	copy(out, keys)
+	return out
+}
+
+// Lookup returns the key with the given name. Unknown names produce an error
+// that suggests the closest known key.
+func Lookup(name string) (Key, error) {
+	for _, k := range keys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+	if suggestion := closestKey(name); suggestion != "" {
+		return Key{}, fmt.Errorf("unknown config key %q (did you mean %q?)", name, suggestion)
+	}
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.Name
+	}
+	return Key{}, fmt.Errorf("unknown config key %q (known keys: %s)", name, strings.Join(names, ", "))
+}
+
+// Value is an effective configuration value and its source.
+type Value struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// Config holds the values read from the repo and home config files, and
+// any flag values set on top of them.
+type Config struct {
+	flags map[string]string
+	repo  map[string]string
+	home  map[string]string
+}
+
+// Load reads the .snaprc files in repoDir and homeDir. Missing files are
+// treated as empty; an empty homeDir skips the home file.
+func Load(repoDir, homeDir string) (*Config, error) {
+	repo, err := readFile(filepath.Join(repoDir, FileName))
+	if err != nil {
+		return nil, err
+	}
+	home := map[string]string{}
+	if homeDir != "" {
+		if home, err = readFile(filepath.Join(homeDir, FileName)); err != nil {
+			return nil, err
+		}
+	}
+	return &Config{flags: map[string]string{}, repo: repo, home: home}, nil
+}
+
+// SetFlag records a value given on the command line for the named key. It
+// takes precedence over every other source and is reported as SourceFlag.
+func (c *Config) SetFlag(name, value string) error {
+	k, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+	if k.Flag == "" {
+		return fmt.Errorf("config %s has no command-line flag", k.Name)
+	}
+	c.flags[k.Name] = value
+	return nil
+}
+
+// Get returns the effective value for the named key.
+func (c *Config) Get(name string) (Value, error) {
+	k, err := Lookup(name)
+	if err != nil {
+		return Value{}, err
+	}
+	if v, ok := c.flags[k.Name]; ok {
+		return Value{Key: k.Name, Value: v, Source: SourceFlag}, nil
+	}
+	if k.Env != "" {
+		if v := os.Getenv(k.Env); v != "" {
+			return Value{Key: k.Name, Value: v, Source: SourceEnv}, nil
+		}
+	}
+	if v, ok := c.repo[k.Name]; ok {
+		return Value{Key: k.Name, Value: v, Source: SourceRepo}, nil
+	}
+	if v, ok := c.home[k.Name]; ok {
+		return Value{Key: k.Name, Value: v, Source: SourceHome}, nil
+	}
+	return Value{Key: k.Name, Value: k.Default, Source: SourceDefault}, nil
+}
+
+// Duration returns the effective value for a duration key, parsed.
+func (c *Config) Duration(name string) (time.Duration, error) {
+	v, err := c.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(v.Value)
+	if err != nil {
+		return 0, fmt.Errorf("config %s (%s): %w", name, v.Source, err)
+	}
+	return d, nil
+}
+
+// Bool returns the effective value for a boolean key, parsed.
+func (c *Config) Bool(name string) (bool, error) {
+	v, err := c.Get(name)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(v.Value)
+	if err != nil {
+		return false, fmt.Errorf("config %s (%s): %q is not true or false", name, v.Source, v.Value)
+	}
+	return b, nil
+}
+
+// Path returns the effective value for a path key with $VAR and ${VAR}
+// expanded, so a shared .snaprc can point at machine-specific locations.
+// Flag values are used as given; the shell has already expanded them.
+func (c *Config) Path(name string) (string, error) {
+	k, err := Lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if !k.path {
+		return "", fmt.Errorf("config %s is not a path", name)
+	}
+	v, err := c.Get(name)
+	if err != nil {
+		return "", err
+	}
+	if v.Source == SourceFlag {
+		return v.Value, nil
+	}
+	p, err := pathutil.ExpandEnv(v.Value)
+	if err != nil {
+		return "", fmt.Errorf("config %s (%s): %w", name, v.Source, err)
+	}
+	return p, nil
+}
+
+// List returns the effective value of every key in display order.
+func (c *Config) List() []Value {
+	values := make([]Value, 0, len(keys))
+	for _, k := range keys {
+		//nolint:errcheck // keys are known, Get cannot fail
+		v, _ := c.Get(k.Name)
+		values = append(values, v)
+	}
+	return values
+}
+
+// Set validates value and writes it to the .snaprc in repoDir, keeping any
+// other keys already in the file.
+func Set(repoDir, name, value string) error {
+	k, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+	value = strings.TrimSpace(value)
+	if err := k.validate(value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", k.Name, err)
+	}
+
+	path := filepath.Join(repoDir, FileName)
+	values, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	values[k.Name] = value
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", FileName, err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// readFile parses a .snaprc file. A missing file yields an empty map.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for name := range values {
+		if _, err := Lookup(name); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return values, nil
+}
+
+func validateProvider(v string) error {
+	switch strings.ToLower(v) {
+	case "claude", "claude-code", "codex":
+		return nil
+	default:
+		return fmt.Errorf("%q is not a provider (supported: claude, codex)", v)
+	}
+}
+
+func validateNonEmpty(v string) error {
+	if v == "" {
+		return errors.New("value cannot be empty")
+	}
+	return nil
+}
+
+func validateCommand(v string) error {
+	if strings.ContainsAny(v, "\r\n") {
+		return errors.New("command must be a single line")
+	}
+	return nil
+}
+
+func validateBool(v string) error {
+	if _, err := strconv.ParseBool(v); err != nil {
+		return fmt.Errorf("%q is not true or false", v)
+	}
+	return nil
+}
+
+func validateDuration(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration (e.g. 5s, 1m)", v)
+	}
+	if d < 0 {
+		return errors.New("duration cannot be negative")
+	}
+	return nil
+}
+
+func validatePositiveDuration(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration (e.g. 5s, 1m)", v)
+	}
+	if d <= 0 {
+		return errors.New("duration must be greater than zero")
+	}
+	return nil
+}
+
+// closestKey returns the known key within edit distance 2 of name, or "".
+func closestKey(name string) string {
+	best, bestDist := "", 3
+	for _, k := range keys {
+		if d := editDistance(name, k.Name); d < bestDist {
+			best, bestDist = k.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRC(t *testing.T, dir, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0o600))
+}
+
+func TestGet_Precedence(t *testing.T) {
+	repoDir, homeDir := t.TempDir(), t.TempDir()
+	t.Setenv("SNAP_PROVIDER", "")
+
+	cfg, err := Load(repoDir, homeDir)
+	require.NoError(t, err)
+	v, err := cfg.Get("provider")
+	require.NoError(t, err)
+	assert.Equal(t, Value{Key: "provider", Value: "claude", Source: SourceDefault}, v)
+
+	writeRC(t, homeDir, "provider: codex\nci-poll: 30s\n")
+	cfg, err = Load(repoDir, homeDir)
+	require.NoError(t, err)
+	v, err = cfg.Get("provider")
+	require.NoError(t, err)
+	assert.Equal(t, SourceHome, v.Source)
+	assert.Equal(t, "codex", v.Value)
+
+	writeRC(t, repoDir, "provider: claude\n")
+	cfg, err = Load(repoDir, homeDir)
+	require.NoError(t, err)
+	v, err = cfg.Get("provider")
+	require.NoError(t, err)
+	assert.Equal(t, SourceRepo, v.Source)
+	assert.Equal(t, "claude", v.Value)
+
+	poll, err := cfg.Duration("ci-poll")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, poll, "keys missing from the repo file fall back to home")
+
+	t.Setenv("SNAP_PROVIDER", "codex")
+	v, err = cfg.Get("provider")
+	require.NoError(t, err)
+	assert.Equal(t, Value{Key: "provider", Value: "codex", Source: SourceEnv}, v)
+}
+
+func TestSetFlag_OverridesEverySource(t *testing.T) {
+	repoDir := t.TempDir()
+	writeRC(t, repoDir, "tasks-dir: work/tasks\nqueue-interval: 5s\n")
+
+	cfg, err := Load(repoDir, "")
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetFlag("tasks-dir", "$HOME/tasks"))
+
+	v, err := cfg.Get("tasks-dir")
+	require.NoError(t, err)
+	assert.Equal(t, Value{Key: "tasks-dir", Value: "$HOME/tasks", Source: SourceFlag}, v)
+	dir, err := cfg.Path("tasks-dir")
+	require.NoError(t, err)
+	assert.Equal(t, "$HOME/tasks", dir, "flag values are not expanded again")
+
+	interval, err := cfg.Duration("queue-interval")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, interval, "keys without a flag value keep their source")
+
+	err = cfg.SetFlag("ci-poll", "1m")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no command-line flag")
+}
+
+func TestList_ReturnsEveryKey(t *testing.T) {
+	t.Setenv("SNAP_PROVIDER", "")
+	repoDir := t.TempDir()
+	writeRC(t, repoDir, "tasks-dir: work/tasks\n")
+
+	cfg, err := Load(repoDir, "")
+	require.NoError(t, err)
+
+	values := cfg.List()
+	require.Len(t, values, len(Keys()))
+	assert.Equal(t, Value{Key: "tasks-dir", Value: "work/tasks", Source: SourceRepo}, values[1])
+	assert.Equal(t, SourceDefault, values[0].Source)
+}
+
+func TestSet_PreservesOtherKeys(t *testing.T) {
+	repoDir := t.TempDir()
+	writeRC(t, repoDir, "tasks-dir: work/tasks\n")
+
+	require.NoError(t, Set(repoDir, "ci-poll", " 1m "))
+
+	cfg, err := Load(repoDir, "")
+	require.NoError(t, err)
+	poll, err := cfg.Duration("ci-poll")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, poll)
+	v, err := cfg.Get("tasks-dir")
+	require.NoError(t, err)
+	assert.Equal(t, "work/tasks", v.Value)
+}
+
+func TestSet_RejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr string
+	}{
+		{name: "unknown key with suggestion", key: "provder", value: "codex", wantErr: `did you mean "provider"`},
+		{name: "unknown key without suggestion", key: "colour-scheme", value: "dark", wantErr: "known keys: provider, tasks-dir, ci-poll, queue-interval, lint-command, test-command, post-iteration-hook, hook-fatal"},
+		{name: "bad provider", key: "provider", value: "gpt", wantErr: "not a provider"},
+		{name: "bad duration", key: "queue-interval", value: "soon", wantErr: "not a duration"},
+		{name: "zero ci poll", key: "ci-poll", value: "0s", wantErr: "greater than zero"},
+		{name: "empty tasks dir", key: "tasks-dir", value: "  ", wantErr: "cannot be empty"},
+		{name: "multi-line command", key: "test-command", value: "make test\nmake e2e", wantErr: "single line"},
+		{name: "bad bool", key: "hook-fatal", value: "sometimes", wantErr: "not true or false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := t.TempDir()
+			err := Set(repoDir, tt.key, tt.value)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.NoFileExists(t, filepath.Join(repoDir, FileName))
+		})
+	}
+}
+
+func TestBool(t *testing.T) {
+	repoDir := t.TempDir()
+
+	cfg, err := Load(repoDir, "")
+	require.NoError(t, err)
+	fatal, err := cfg.Bool("hook-fatal")
+	require.NoError(t, err)
+	assert.False(t, fatal, "default")
+
+	writeRC(t, repoDir, "hook-fatal: true\n")
+	cfg, err = Load(repoDir, "")
+	require.NoError(t, err)
+	fatal, err = cfg.Bool("hook-fatal")
+	require.NoError(t, err)
+	assert.True(t, fatal)
+
+	writeRC(t, repoDir, "hook-fatal: maybe\n")
+	cfg, err = Load(repoDir, "")
+	require.NoError(t, err)
+	_, err = cfg.Bool("hook-fatal")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config hook-fatal (repo)")
+}
+
+func TestLoad_RejectsUnknownKeyInFile(t *testing.T) {
+	repoDir := t.TempDir()
+	writeRC(t, repoDir, "tasks_dir: docs/tasks\n")
+
+	_, err := Load(repoDir, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `did you mean "tasks-dir"`)
+}
+
+func TestPath_ExpandsEnvironment(t *testing.T) {
+	t.Setenv("SNAP_WORK", "/srv/work")
+	repoDir := t.TempDir()
+	writeRC(t, repoDir, "tasks-dir: ${SNAP_WORK}/tasks\n")
+
+	cfg, err := Load(repoDir, "")
+	require.NoError(t, err)
+	dir, err := cfg.Path("tasks-dir")
+	require.NoError(t, err)
+	assert.Equal(t, "/srv/work/tasks", dir)
+
+	v, err := cfg.Get("tasks-dir")
+	require.NoError(t, err)
+	assert.Equal(t, "${SNAP_WORK}/tasks", v.Value, "Get returns the stored value")
+
+	_, err = cfg.Path("ci-poll")
+	assert.EqualError(t, err, "config ci-poll is not a path")
+}
+
+func TestPath_UnsetVariable(t *testing.T) {
+	repoDir := t.TempDir()
+	writeRC(t, repoDir, "tasks-dir: $SNAP_TEST_UNSET/tasks\n")
+
+	cfg, err := Load(repoDir, "")
+	require.NoError(t, err)
+	_, err = cfg.Path("tasks-dir")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config tasks-dir (repo)")
+	assert.Contains(t, err.Error(), "environment variable SNAP_TEST_UNSET is not set")
+}
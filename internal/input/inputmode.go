@@ -1,6 +1,7 @@
 package input
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"time"
@@ -22,6 +23,21 @@ func promptPrefix() string {
 // promptPrefixLen is the visible character width of the prompt prefix ("❯ ").
 const promptPrefixLen = 2 // "❯" (1 column) + " " (1 column)
 
+// historyLimit is the number of submitted lines kept for reverse search.
+const historyLimit = 100
+
+// searchPrefix returns the reverse-search prompt for query and its visible
+// width. failed marks a query with no match, as in readline.
+func searchPrefix(query []byte, failed bool) (string, int) {
+	label := "(reverse-i-search)"
+	if failed {
+		label = "(failed reverse-i-search)"
+	}
+	text := fmt.Sprintf("%s`%s': ", label, query)
+	styled := fmt.Sprintf("%s%s%s", ui.ResolveStyle(ui.WeightDim), text, ui.ResolveStyle(ui.WeightNormal))
+	return styled, utf8.RuneCountInString(text)
+}
+
 // Mode is a state machine that manages modal input. In idle state, output
 // streams freely and keystrokes are invisible. On the first printable byte,
 // it transitions to composing: output pauses (via SwitchWriter), a styled
@@ -32,13 +48,25 @@ const promptPrefixLen = 2 // "❯" (1 column) + " " (1 column)
 // ellipsis prefix (…) while the full text is kept in the buffer. Terminal
 // width is tracked via SetTermWidth to handle SIGWINCH events.
 //
+// Submitted lines are kept in a history. Search (Ctrl+R) starts an
+// incremental reverse search over it: typed bytes extend the query, the line
+// shows the newest match, and Enter submits it or Escape cancels, exactly as
+// while composing. Output stays paused for the whole search.
+//
 // Mode is NOT thread-safe; all methods must be called from a single goroutine
-// (the rawReader goroutine).
+// (the rawReader goroutine). The exception is release, which only touches the
+// SwitchWriter and is called by Reader.Stop.
 type Mode struct {
 	sw        *ui.SwitchWriter
 	composing bool
 	line      []byte
 	termWidth int // Updated via SetTermWidth; 0 means unknown width
+
+	history   []string // Submitted lines, oldest first
+	searching bool     // Reverse search active (implies composing)
+	query     []byte   // Reverse search query
+	matchIdx  int      // Index into history of the current match; len(history) before the first
+	failed    bool     // The query has no match at or before matchIdx
 }
 
 // NewMode creates a Mode that controls the given SwitchWriter.
@@ -51,6 +79,11 @@ func (m *Mode) IsComposing() bool {
 	return m.composing
 }
 
+// IsSearching reports whether a reverse search is active.
+func (m *Mode) IsSearching() bool {
+	return m.searching
+}
+
 // Line returns the current input line as a string.
 func (m *Mode) Line() string {
 	return string(m.line)
@@ -63,28 +96,40 @@ func (m *Mode) SetTermWidth(w int) {
 	}
 }
 
+// newlineMarker stands in for newlines from a paste when the line is drawn,
+// keeping the prompt on one terminal row.
+const newlineMarker = "↵"
+
 // getDisplayText returns the text to display, truncating if necessary to fit terminal width.
 // If the line is longer than available space, returns "…" + tail of line.
-// Available space is termWidth minus prompt prefix length (2 for "❯ ").
+// Available space is termWidth minus the visible width of the prompt.
 func (m *Mode) getDisplayText() string {
-	const ellipsis = "…"
-	const ellipsisWidth = 1 // "…" is 1 character wide
-	const promptWidth = promptPrefixLen
-
+	line := m.line
+	if bytes.IndexByte(line, '\n') >= 0 {
+		line = bytes.ReplaceAll(line, []byte("\n"), []byte(newlineMarker))
+	}
 	if m.termWidth == 0 {
 		// If terminal width is unknown, don't truncate.
-		return string(m.line)
+		return string(line)
 	}
+	_, prefixWidth := m.prefix()
+	return truncateTail(line, m.termWidth-prefixWidth)
+}
+
+// truncateTail returns line, or "…" + the tail of line when it is wider than
+// availableWidth columns. A non-positive width leaves line untouched.
+func truncateTail(line []byte, availableWidth int) string {
+	const ellipsis = "…"
+	const ellipsisWidth = 1 // "…" is 1 character wide
 
-	availableWidth := m.termWidth - promptWidth
 	if availableWidth <= 0 {
 		// Terminal too narrow; show only what fits.
-		return string(m.line)
+		return string(line)
 	}
 
-	lineWidth := utf8.RuneCount(m.line)
+	lineWidth := utf8.RuneCount(line)
 	if lineWidth <= availableWidth {
-		return string(m.line)
+		return string(line)
 	}
 
 	// Line is too long; show tail with ellipsis prefix.
@@ -103,8 +148,8 @@ func (m *Mode) getDisplayText() string {
 	// Convert rune offset to byte offset.
 	byteOffset := 0
 	runeCount := 0
-	for i := 0; i < len(m.line); i++ {
-		if (m.line[i] & 0xC0) != 0x80 {
+	for i := 0; i < len(line); i++ {
+		if (line[i] & 0xC0) != 0x80 {
 			runeCount++
 		}
 		if runeCount == tailStart {
@@ -113,7 +158,15 @@ func (m *Mode) getDisplayText() string {
 		}
 	}
 
-	return ellipsis + string(m.line[byteOffset:])
+	return ellipsis + string(line[byteOffset:])
+}
+
+// prefix returns the styled prompt shown before the line and its visible width.
+func (m *Mode) prefix() (string, int) {
+	if m.searching {
+		return searchPrefix(m.query, m.failed)
+	}
+	return promptPrefix(), promptPrefixLen
 }
 
 // HandleByte processes a single byte of input. If idle, the first printable
@@ -128,6 +181,12 @@ func (m *Mode) HandleByte(b byte) {
 		m.activate(b)
 		return
 	}
+	if m.searching {
+		m.query = append(m.query, b)
+		m.findFrom(m.matchIdx)
+		m.redrawLine()
+		return
+	}
 	if len(m.line) >= maxLineLen {
 		return
 	}
@@ -147,11 +206,21 @@ func (m *Mode) HandleByte(b byte) {
 }
 
 // HandleBackspace removes the last UTF-8 rune from the input line. If the line
-// becomes empty, a second backspace cancels composing mode entirely.
+// becomes empty, a second backspace cancels composing mode entirely. While
+// searching, it removes the last rune of the query instead.
 func (m *Mode) HandleBackspace() {
 	if !m.composing {
 		return
 	}
+	if m.searching {
+		if len(m.query) > 0 {
+			_, size := utf8.DecodeLastRune(m.query)
+			m.query = m.query[:len(m.query)-size]
+			m.findFrom(len(m.history) - 1)
+			m.redrawLine()
+		}
+		return
+	}
 	if len(m.line) == 0 {
 		m.clearPrompt()
 		m.composing = false
@@ -169,7 +238,8 @@ func (m *Mode) HandleBackspace() {
 
 // Submit finalizes the current input and returns the text. Clears the prompt
 // line, resets to idle, and resumes output streaming. Returns empty string
-// if not composing.
+// if not composing. While searching, the current match is submitted. Non-empty
+// text is added to the history.
 func (m *Mode) Submit() string {
 	if !m.composing {
 		return ""
@@ -178,10 +248,87 @@ func (m *Mode) Submit() string {
 	m.clearPrompt()
 	m.line = m.line[:0]
 	m.composing = false
+	m.searching = false
 	m.sw.Resume()
+	m.remember(text)
 	return text
 }
 
+// Paste inserts text from a bracketed paste, which may contain newlines, into
+// the input line, activating composing mode if idle, so the whole paste is
+// submitted as one line. While searching, it extends the query instead. Bytes
+// beyond maxLineLen are dropped.
+func (m *Mode) Paste(text string) {
+	if text == "" {
+		return
+	}
+	if m.searching {
+		m.query = append(m.query, text...)
+		m.findFrom(m.matchIdx)
+		m.redrawLine()
+		return
+	}
+	if !m.composing {
+		m.composing = true
+		m.line = m.line[:0]
+		m.sw.Pause()
+	}
+	m.line = appendPaste(m.line, text)
+	m.redrawLine()
+}
+
+// Search starts a reverse search over submitted lines (Ctrl+R), pausing
+// output if idle. When already searching, it moves to the next older match
+// of the query.
+func (m *Mode) Search() {
+	if m.searching {
+		m.findFrom(m.matchIdx - 1)
+		m.redrawLine()
+		return
+	}
+	if !m.composing {
+		m.composing = true
+		m.line = m.line[:0]
+		m.sw.Pause()
+	}
+	m.searching = true
+	m.query = m.query[:0]
+	m.matchIdx = len(m.history)
+	m.failed = false
+	m.redrawLine()
+}
+
+// findFrom sets the line to the newest history entry at or before index start
+// that contains the query. Without a match, the line keeps the previous match
+// and the search is marked failed.
+func (m *Mode) findFrom(start int) {
+	if len(m.query) == 0 {
+		m.failed = false
+		return
+	}
+	for i := min(start, len(m.history)-1); i >= 0; i-- {
+		if strings.Contains(m.history[i], string(m.query)) {
+			m.matchIdx = i
+			m.line = append(m.line[:0], m.history[i]...)
+			m.failed = false
+			return
+		}
+	}
+	m.failed = true
+}
+
+// remember appends a submitted line to the history, skipping empty lines and
+// repeats of the previous entry.
+func (m *Mode) remember(text string) {
+	if text == "" || (len(m.history) > 0 && m.history[len(m.history)-1] == text) {
+		return
+	}
+	m.history = append(m.history, text)
+	if len(m.history) > historyLimit {
+		m.history = m.history[len(m.history)-historyLimit:]
+	}
+}
+
 // Cancel discards the current input, clears the prompt, resets to idle,
 // and resumes output streaming. Shows a brief "cancelled" message before clearing.
 func (m *Mode) Cancel() {
@@ -200,15 +347,23 @@ func (m *Mode) Cancel() {
 	m.clearPrompt()
 	m.line = m.line[:0]
 	m.composing = false
+	m.searching = false
 	m.sw.Resume()
 }
 
 // ClearLine removes all text from the input line (Ctrl+U). If the line is
 // already empty, cancels composing mode. If not composing, this is a no-op.
+// While searching, it clears the query instead.
 func (m *Mode) ClearLine() {
 	if !m.composing {
 		return
 	}
+	if m.searching {
+		m.query = m.query[:0]
+		m.failed = false
+		m.redrawLine()
+		return
+	}
 	if len(m.line) == 0 {
 		m.clearPrompt()
 		m.composing = false
@@ -222,10 +377,17 @@ func (m *Mode) ClearLine() {
 // DeleteWord removes the last word from the input line (Ctrl+W). Skips trailing
 // whitespace, then deletes back to the previous whitespace boundary. If the line
 // becomes empty, cancels composing mode. If not composing, this is a no-op.
+// While searching, it deletes the last word of the query instead.
 func (m *Mode) DeleteWord() {
 	if !m.composing {
 		return
 	}
+	if m.searching {
+		m.query = deleteWord(m.query)
+		m.findFrom(len(m.history) - 1)
+		m.redrawLine()
+		return
+	}
 	m.line = deleteWord(m.line)
 	if len(m.line) == 0 {
 		m.clearPrompt()
@@ -236,6 +398,17 @@ func (m *Mode) DeleteWord() {
 	m.redrawLine()
 }
 
+// release erases an open input prompt and resumes paused output, so text
+// written after the reader stops isn't stuck behind a half-typed directive.
+// The typed text is discarded. Safe to call from another goroutine.
+func (m *Mode) release() {
+	if !m.sw.IsPaused() {
+		return
+	}
+	m.echo("\r\x1b[K")
+	m.sw.Resume()
+}
+
 // activate transitions from idle to composing mode: pauses output, renders the
 // styled input prompt with the first character, and stores the byte.
 func (m *Mode) activate(b byte) {
@@ -243,7 +416,8 @@ func (m *Mode) activate(b byte) {
 	m.line = append(m.line[:0], b)
 	m.sw.Pause()
 	displayText := m.getDisplayText()
-	m.echo(fmt.Sprintf("\r%s%s", promptPrefix(), displayText))
+	// Clear the line first: it may hold the step's thinking spinner.
+	m.echo(fmt.Sprintf("\r\x1b[K%s%s", promptPrefix(), displayText))
 }
 
 // echo writes text directly to the underlying writer (bypassing the buffer).
@@ -256,7 +430,8 @@ func (m *Mode) echo(s string) {
 // Accounts for display truncation if line is longer than terminal width.
 func (m *Mode) clearPrompt() {
 	displayText := m.getDisplayText()
-	width := promptPrefixLen + utf8.RuneCount([]byte(displayText))
+	_, prefixWidth := m.prefix()
+	width := prefixWidth + utf8.RuneCount([]byte(displayText))
 	m.echo("\r" + strings.Repeat(" ", width) + "\r")
 }
 
@@ -265,5 +440,6 @@ func (m *Mode) clearPrompt() {
 func (m *Mode) redrawLine() {
 	// \r returns to column 0, \x1b[K clears from cursor to end of line.
 	displayText := m.getDisplayText()
-	m.echo("\r\x1b[K" + promptPrefix() + displayText)
+	prefix, _ := m.prefix()
+	m.echo("\r\x1b[K" + prefix + displayText)
 }
@@ -535,3 +535,105 @@ func TestMode_UTF8MultiByteWithNarrowTerminal(t *testing.T) {
 	// Verify it contains the multi-byte character.
 	assert.Contains(t, line, "é", "Multi-byte UTF-8 should be preserved")
 }
+
+// submitLine types text and submits it, adding it to the history.
+func submitLine(m *Mode, text string) {
+	for i := 0; i < len(text); i++ {
+		m.HandleByte(text[i])
+	}
+	m.Submit()
+}
+
+func TestMode_Search_FindsNewestMatch(t *testing.T) {
+	m, sw, buf := newTestMode()
+	submitLine(m, "fix the login bug")
+	submitLine(m, "add tests")
+	submitLine(m, "fix lint")
+
+	m.Search()
+	assert.True(t, m.IsSearching())
+	assert.True(t, m.IsComposing(), "Search composes a line")
+	assert.True(t, sw.IsPaused(), "Output should stay paused during search")
+
+	m.HandleByte('f')
+	m.HandleByte('i')
+	assert.Equal(t, "fix lint", m.Line())
+	assert.Contains(t, ui.StripColors(buf.String()), "(reverse-i-search)`fi': fix lint")
+
+	assert.Equal(t, "fix lint", m.Submit())
+	assert.False(t, m.IsSearching())
+	assert.False(t, sw.IsPaused(), "Output should resume after submit")
+}
+
+func TestMode_Search_RepeatMovesToOlderMatch(t *testing.T) {
+	m, _, _ := newTestMode()
+	submitLine(m, "fix the login bug")
+	submitLine(m, "add tests")
+	submitLine(m, "fix lint")
+
+	m.Search()
+	m.HandleByte('f')
+	m.Search()
+	assert.Equal(t, "fix the login bug", m.Line())
+
+	// No older match: the line keeps the last match.
+	m.Search()
+	assert.Equal(t, "fix the login bug", m.Line())
+}
+
+func TestMode_Search_RefinesQuery(t *testing.T) {
+	m, _, buf := newTestMode()
+	submitLine(m, "fix the login bug")
+	submitLine(m, "fix lint")
+
+	m.Search()
+	for _, b := range []byte("fix t") {
+		m.HandleByte(b)
+	}
+	assert.Equal(t, "fix the login bug", m.Line())
+
+	m.HandleByte('z')
+	assert.Contains(t, ui.StripColors(buf.String()), "(failed reverse-i-search)`fix tz': fix the login bug")
+
+	m.HandleBackspace()
+	m.HandleBackspace()
+	m.HandleBackspace()
+	assert.Equal(t, "fix lint", m.Line(), "A shorter query searches from the newest entry again")
+}
+
+func TestMode_Search_EscapeCancels(t *testing.T) {
+	m, sw, _ := newTestMode()
+	submitLine(m, "fix lint")
+
+	m.Search()
+	m.HandleByte('f')
+	m.Cancel()
+
+	assert.False(t, m.IsSearching())
+	assert.False(t, m.IsComposing())
+	assert.False(t, sw.IsPaused())
+	assert.Equal(t, "", m.Submit(), "Nothing is submitted after cancel")
+}
+
+func TestMode_History_SkipsRepeats(t *testing.T) {
+	m, _, _ := newTestMode()
+	submitLine(m, "fix lint")
+	submitLine(m, "fix lint")
+	submitLine(m, "add tests")
+
+	assert.Equal(t, []string{"fix lint", "add tests"}, m.history)
+}
+
+func TestMode_Paste_KeepsNewlinesOnOneRow(t *testing.T) {
+	m, sw, buf := newTestMode()
+
+	m.Paste("first\nsecond")
+
+	assert.True(t, m.IsComposing(), "A paste starts composing")
+	assert.True(t, sw.IsPaused())
+	assert.Equal(t, "first\nsecond", m.Line())
+	assert.Contains(t, ui.StripColors(buf.String()), "❯ first↵second")
+	assert.NotContains(t, buf.String(), "first\nsecond", "Newlines are not echoed")
+
+	assert.Equal(t, "first\nsecond", m.Submit())
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"unicode/utf8"
@@ -24,6 +25,7 @@ const (
 	keyEnter     = '\r'
 	keyBackspace = 127
 	keyCtrlC     = 3
+	keyCtrlR     = 18
 	keyCtrlU     = 21
 	keyCtrlW     = 23
 	keyEsc       = 0x1b
@@ -31,6 +33,20 @@ const (
 	// maxLineLen is the maximum number of bytes allowed in a single input line.
 	// Characters beyond this limit are silently dropped.
 	maxLineLen = 4096
+
+	// readChunkSize is how many bytes one read from the source may return.
+	// A terminal delivers an escape sequence in one write, so bytes left over
+	// from the same read tell an escape sequence apart from a bare Escape key.
+	readChunkSize = 256
+)
+
+// Bracketed paste: the terminal wraps pasted text in pasteStart/pasteEnd
+// (after ESC) while enabled, so a paste can't be mistaken for typed Enters.
+const (
+	enableBracketedPaste  = "\x1b[?2004h"
+	disableBracketedPaste = "\x1b[?2004l"
+	pasteStart            = "[200~"
+	pasteEnd              = "[201~"
 )
 
 // rawReader reads byte-by-byte from a reader, assembling lines and enqueuing
@@ -39,6 +55,9 @@ const (
 // input with output buffering.
 type rawReader struct {
 	source    io.Reader
+	out       io.Writer // terminal output for mode sequences (bracketed paste)
+	chunk     []byte    // read buffer for source
+	pending   []byte    // bytes read from source but not yet consumed
 	queue     *queue.Queue
 	stop      chan struct{}
 	mu        sync.Mutex
@@ -57,13 +76,14 @@ type rawReader struct {
 func newRawReader(f *os.File, q *queue.Queue, stop chan struct{}) *rawReader {
 	return &rawReader{
 		source: f,
+		out:    os.Stdout,
 		queue:  q,
 		stop:   stop,
 		fd:     int(f.Fd()),
 	}
 }
 
-// run enables raw mode if backed by a terminal, then reads byte-by-byte.
+// run enables raw mode and bracketed paste if backed by a terminal, then reads byte-by-byte.
 // Returns when the stop channel is closed, Ctrl+C is pressed, EOF, or error.
 // Terminal is restored before returning. A signal handler ensures the terminal
 // is restored even if the process receives SIGINT or SIGTERM. A deferred panic
@@ -78,11 +98,23 @@ func (rr *rawReader) run() error {
 		rr.mu.Lock()
 		rr.oldState = oldState
 		rr.mu.Unlock()
+		//nolint:errcheck // Best-effort; without it, pastes arrive as typed input.
+		io.WriteString(rr.out, enableBracketedPaste)
 
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		defer signal.Stop(sigCh)
 		defer rr.restore()
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-sigCh:
+				rr.restore()
+			case <-done:
+			}
+		}()
 	}
 
 	// Restore terminal on panic before re-panicking.
@@ -96,6 +128,30 @@ func (rr *rawReader) run() error {
 	return rr.readLoop()
 }
 
+// Read implements io.Reader over the source, returning bytes left over from
+// the previous source read before reading more.
+func (rr *rawReader) Read(p []byte) (int, error) {
+	if len(rr.pending) == 0 {
+		if rr.chunk == nil {
+			rr.chunk = make([]byte, readChunkSize)
+		}
+		n, err := rr.source.Read(rr.chunk)
+		if n == 0 {
+			return 0, err
+		}
+		rr.pending = rr.chunk[:n]
+	}
+	n := copy(p, rr.pending)
+	rr.pending = rr.pending[n:]
+	return n, nil
+}
+
+// escapeFollows reports whether bytes from the same read follow an ESC, which
+// makes it the start of an escape sequence rather than the Escape key.
+func (rr *rawReader) escapeFollows() bool {
+	return len(rr.pending) > 0
+}
+
 // readLoop reads byte-by-byte, assembles lines, and enqueues on Enter.
 func (rr *rawReader) readLoop() error {
 	if rr.inputMode != nil {
@@ -114,13 +170,19 @@ func (rr *rawReader) readLoopModal() error {
 		default:
 		}
 
-		n, err := rr.source.Read(buf)
+		n, err := rr.Read(buf)
 		if err != nil {
 			return err
 		}
 		if n == 0 {
 			continue
 		}
+		// A key read after Stop must not reopen the prompt it released.
+		select {
+		case <-rr.stop:
+			return nil
+		default:
+		}
 
 		b := buf[0]
 		switch b {
@@ -131,6 +193,9 @@ func (rr *rawReader) readLoopModal() error {
 			}
 			return nil
 
+		case keyCtrlR:
+			rr.inputMode.Search()
+
 		case keyCtrlU:
 			rr.inputMode.ClearLine()
 
@@ -155,10 +220,13 @@ func (rr *rawReader) readLoopModal() error {
 			}
 
 		case keyEsc:
-			if rr.inputMode.IsComposing() {
+			if !rr.escapeFollows() {
 				rr.inputMode.Cancel()
-			} else {
-				rr.consumeEscape(buf)
+				continue
+			}
+			// Escape sequences other than a paste (arrow keys, Alt+key) are ignored.
+			if consumeEscape(rr, buf) == pasteStart {
+				rr.inputMode.Paste(rr.readPaste(buf))
 			}
 
 		case keyBackspace:
@@ -184,7 +252,7 @@ func (rr *rawReader) readLoopPlain() error {
 		default:
 		}
 
-		n, err := rr.source.Read(buf)
+		n, err := rr.Read(buf)
 		if err != nil {
 			return err
 		}
@@ -221,7 +289,9 @@ func (rr *rawReader) readLoopPlain() error {
 			}
 
 		case keyEsc:
-			rr.consumeEscape(buf)
+			if rr.escapeFollows() && consumeEscape(rr, buf) == pasteStart {
+				line = appendPaste(line, rr.readPaste(buf))
+			}
 
 		case keyBackspace:
 			if len(line) > 0 {
@@ -258,9 +328,46 @@ func isSpace(b byte) bool {
 	return b == ' ' || b == '\t'
 }
 
-// consumeEscape reads and discards the remainder of an ANSI escape sequence.
-func (rr *rawReader) consumeEscape(buf []byte) {
-	consumeEscape(rr.source, buf)
+// readPaste reads a bracketed paste up to its end marker, or EOF, and returns
+// the pasted text with line endings normalized to LF. Other control bytes
+// and escape sequences inside the paste are dropped, as are trailing
+// newlines; the user still presses Enter to submit.
+func (rr *rawReader) readPaste(buf []byte) string {
+	var text []byte
+	prevCR := false
+	for {
+		b, ok := readByte(rr, buf)
+		if !ok {
+			break
+		}
+		if b == keyEsc {
+			if consumeEscape(rr, buf) == pasteEnd {
+				break
+			}
+			prevCR = false
+			continue
+		}
+		switch {
+		case b == '\n' && prevCR:
+			// Second half of CRLF; the CR already added the newline.
+		case (b == '\r' || b == '\n' || b == '\t' || b >= 32) && len(text) < maxLineLen:
+			if b == '\r' {
+				text = append(text, '\n')
+			} else {
+				text = append(text, b)
+			}
+		}
+		prevCR = b == '\r'
+	}
+	return strings.TrimRight(string(text), "\n")
+}
+
+// appendPaste appends pasted text to line, dropping bytes beyond maxLineLen.
+func appendPaste(line []byte, text string) []byte {
+	if room := maxLineLen - len(line); len(text) > room {
+		text = text[:max(room, 0)]
+	}
+	return append(line, text...)
 }
 
 // readByte reads a single byte from r into buf and returns it.
@@ -272,37 +379,43 @@ func readByte(r io.Reader, buf []byte) (byte, bool) {
 	return buf[0], true
 }
 
-// consumeEscape reads and discards the remainder of an ANSI escape sequence
-// from r. This prevents arrow keys, Home, End, etc. from injecting garbage
-// characters into the line buffer when the terminal is in raw mode.
-func consumeEscape(r io.Reader, buf []byte) {
+// consumeEscape reads the remainder of an ANSI escape sequence from r and
+// returns it (the bytes after ESC). This prevents arrow keys, Home, End, etc.
+// from injecting garbage characters into the line buffer when the terminal is
+// in raw mode, and lets callers recognize the bracketed paste markers.
+func consumeEscape(r io.Reader, buf []byte) string {
 	b, ok := readByte(r, buf)
 	if !ok {
-		return
+		return ""
 	}
 	if b != '[' {
 		// Not a CSI sequence (e.g., Alt+key sends ESC followed by key).
-		return
+		return string(b)
 	}
 	// CSI sequence: ESC [ (parameter bytes 0x30-0x3F)* (intermediate bytes 0x20-0x2F)* (final byte 0x40-0x7E)
+	seq := []byte{b}
 	for {
 		b, ok = readByte(r, buf)
 		if !ok {
-			return
+			return string(seq)
 		}
+		seq = append(seq, b)
 		if b >= 0x40 && b <= 0x7E {
-			return // final byte — sequence complete
+			return string(seq) // final byte — sequence complete
 		}
 	}
 }
 
-// restore returns the terminal to its original state. Safe to call multiple
-// times; subsequent calls after the first are no-ops.
+// restore disables bracketed paste and returns the terminal to its original
+// state. Safe to call multiple times; subsequent calls after the first are
+// no-ops.
 func (rr *rawReader) restore() {
 	rr.mu.Lock()
 	defer rr.mu.Unlock()
 
 	if rr.oldState != nil {
+		//nolint:errcheck // Best-effort; the terminal is being restored anyway.
+		io.WriteString(rr.out, disableBracketedPaste)
 		//nolint:errcheck // Best-effort terminal restore; nothing to do on failure.
 		termRestore(rr.fd, rr.oldState)
 		rr.oldState = nil
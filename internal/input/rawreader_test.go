@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/term"
 
 	"github.com/yarlson/snap/internal/queue"
 	"github.com/yarlson/snap/internal/ui"
@@ -904,6 +905,56 @@ func TestRawReader_LongLineIsTruncated(t *testing.T) {
 	w.Close()
 }
 
+func TestRawReaderModal_CtrlR_SubmitsMatch(t *testing.T) {
+	rr, w, q, sw, _ := newModalRawReader(t)
+
+	//nolint:errcheck // Background goroutine; error checked via queue assertions.
+	go func() { rr.run() }()
+
+	_, err := w.WriteString("fix lint\radd tests\r")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return q.Len() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// Ctrl+R, search "li", Enter re-queues the match.
+	_, err = w.WriteString("\x12li\r")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return q.Len() == 3
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"fix lint", "add tests", "fix lint"}, q.All())
+	assert.False(t, sw.IsPaused(), "Output should resume after submit")
+}
+
+func TestRawReaderModal_CtrlR_EscapeCancels(t *testing.T) {
+	rr, w, q, sw, _ := newModalRawReader(t)
+
+	//nolint:errcheck // Background goroutine; error checked via queue assertions.
+	go func() { rr.run() }()
+
+	_, err := w.WriteString("fix lint\r")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return q.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = w.WriteString("\x12fix")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return sw.IsPaused()
+	}, time.Second, 10*time.Millisecond, "Output pauses during search")
+
+	_, err = w.WriteString("\x1b")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return !sw.IsPaused()
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 1, q.Len(), "Nothing should be enqueued on cancel")
+}
+
 func TestRawReaderModal_LongLineIsTruncated(t *testing.T) {
 	rr, w, q, _, _ := newModalRawReader(t)
 
@@ -958,3 +1009,94 @@ func TestRawReader_PanicRecoveryRestoresTerminal(t *testing.T) {
 		rr.run()
 	}, "Should re-panic after terminal restore")
 }
+
+// pasteSequence wraps text in the bracketed paste markers a terminal sends.
+func pasteSequence(text string) string {
+	return "\x1b" + pasteStart + text + "\x1b" + pasteEnd
+}
+
+func TestRawReader_BracketedPasteIsOneLine(t *testing.T) {
+	q := queue.New()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	stop := make(chan struct{})
+	rr := newRawReader(r, q, stop)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- rr.run()
+	}()
+
+	_, err = w.WriteString(pasteSequence("line one\r\nline two\rline three\n") + "\r")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return q.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	prompt, ok := q.Dequeue()
+	require.True(t, ok)
+	assert.Equal(t, "line one\nline two\nline three", prompt)
+
+	close(stop)
+	w.Close()
+}
+
+func TestRawReaderModal_BracketedPasteIsOneDirective(t *testing.T) {
+	rr, w, q, sw, buf := newModalRawReader(t)
+
+	//nolint:errcheck // Background goroutine; error checked via queue assertions.
+	go func() { rr.run() }()
+
+	_, err := w.WriteString(pasteSequence("fix the bug\r\x1b[Aand add a test\r"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return sw.IsPaused()
+	}, time.Second, 10*time.Millisecond, "A paste starts composing")
+	assert.Equal(t, 0, q.Len(), "A paste is not submitted until Enter")
+
+	_, err = w.WriteString("\r")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return q.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	prompt, ok := q.Dequeue()
+	require.True(t, ok)
+	assert.Equal(t, "fix the bug\nand add a test", prompt)
+	assert.Contains(t, ui.StripColors(buf.String()), "fix the bug↵and add a test")
+}
+
+func TestRawReader_BracketedPasteLimitedToMaxLineLen(t *testing.T) {
+	line := appendPaste(bytes.Repeat([]byte("a"), maxLineLen-2), "bcd")
+	assert.Len(t, line, maxLineLen)
+	assert.Equal(t, "bc", string(line[maxLineLen-2:]))
+}
+
+func TestRawReader_TogglesBracketedPasteOnTerminal(t *testing.T) {
+	origIsTerminal, origMakeRaw, origRestore := termIsTerminal, termMakeRaw, termRestore
+	t.Cleanup(func() { termIsTerminal, termMakeRaw, termRestore = origIsTerminal, origMakeRaw, origRestore })
+	termIsTerminal = func(int) bool { return true }
+	termMakeRaw = func(int) (*term.State, error) { return &term.State{}, nil }
+	termRestore = func(int, *term.State) error { return nil }
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	var out bytes.Buffer
+	rr := newRawReader(r, queue.New(), make(chan struct{}))
+	rr.out = &out
+
+	done := make(chan error, 1)
+	go func() { done <- rr.run() }()
+
+	_, err = w.WriteString("\x03")
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, enableBracketedPaste+disableBracketedPaste, out.String())
+}
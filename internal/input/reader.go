@@ -99,7 +99,8 @@ func (r *Reader) Start() {
 // blocking read completes (e.g., on next newline or EOF). For os.Stdin, the
 // goroutine may not exit until the process ends because Scan blocks on input.
 // When using raw terminal mode, Stop restores terminal settings immediately
-// even if the goroutine remains blocked on the current read.
+// even if the goroutine remains blocked on the current read. With modal
+// input, an open prompt is erased and paused output is flushed.
 func (r *Reader) Stop() {
 	select {
 	case <-r.stop:
@@ -114,6 +115,9 @@ func (r *Reader) Stop() {
 	if raw != nil {
 		raw.restore()
 	}
+	if r.inputMode != nil {
+		r.inputMode.release()
+	}
 }
 
 // Done returns true if the reader has stopped (EOF or error).
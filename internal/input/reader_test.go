@@ -341,3 +341,41 @@ func TestReader_WithTerminal_StopCleansUp(t *testing.T) {
 
 	pr.Close()
 }
+
+func TestReader_StopReleasesOpenPrompt(t *testing.T) {
+	q := queue.New()
+	pr, pw, err := os.Pipe()
+	require.NoError(t, err)
+	defer pr.Close()
+
+	var mu sync.Mutex
+	var buf bytes.Buffer
+	sw := ui.NewSwitchWriter(&syncWriter{mu: &mu, buf: &buf})
+
+	reader := input.NewReader(pr, q,
+		input.WithTerminal(pr),
+		input.WithMode(input.NewMode(sw)),
+	)
+	reader.Start()
+
+	_, err = pw.WriteString("draft")
+	require.NoError(t, err)
+	require.Eventually(t, sw.IsPaused, time.Second, 10*time.Millisecond)
+	_, err = sw.Write([]byte("held output\n"))
+	require.NoError(t, err)
+
+	reader.Stop()
+	assert.False(t, sw.IsPaused(), "Stop resumes paused output")
+	mu.Lock()
+	output := buf.String()
+	mu.Unlock()
+	assert.Contains(t, output, "\r\x1b[Kheld output", "the prompt line is erased before held output")
+
+	// A key read after Stop doesn't reopen the prompt.
+	_, err = pw.WriteString("x")
+	require.NoError(t, err)
+	assert.Eventually(t, reader.Done, time.Second, 10*time.Millisecond)
+	assert.False(t, sw.IsPaused())
+	assert.Zero(t, q.Len())
+	pw.Close()
+}
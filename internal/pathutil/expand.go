@@ -0,0 +1,25 @@
+package pathutil
+
+import (
+	"fmt"
+	"os"
+)
+
+// ExpandEnv replaces $VAR and ${VAR} in path with the environment variable's
+// value, so shared config can use machine-agnostic locations such as
+// $HOME/prompts. A variable that is not set is an error naming it, rather
+// than silently expanding to an empty path segment.
+func ExpandEnv(path string) (string, error) {
+	var missing string
+	expanded := os.Expand(path, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return v
+	})
+	if missing != "" {
+		return "", fmt.Errorf("%s: environment variable %s is not set", path, missing)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,39 @@
+package pathutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/pathutil"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("SNAP_PROMPTS", "shared/prompts")
+	t.Setenv("SNAP_EMPTY", "")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "docs/tasks", want: "docs/tasks"},
+		{path: "$HOME/tasks", want: "/home/dev/tasks"},
+		{path: "${HOME}/${SNAP_PROMPTS}/req.md", want: "/home/dev/shared/prompts/req.md"},
+		{path: "tasks${SNAP_EMPTY}", want: "tasks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := pathutil.ExpandEnv(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpandEnv_UnsetVariable(t *testing.T) {
+	_, err := pathutil.ExpandEnv("${SNAP_TEST_UNSET_DIR}/tasks")
+	require.Error(t, err)
+	assert.Equal(t, "${SNAP_TEST_UNSET_DIR}/tasks: environment variable SNAP_TEST_UNSET_DIR is not set", err.Error())
+}
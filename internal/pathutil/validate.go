@@ -34,6 +34,38 @@ func ValidatePath(path string) error {
 	return nil
 }
 
+// ResolveScope validates a scope path and returns it relative to the current
+// working directory (the repo root), in slash form for use as a git pathspec.
+// The path must exist and stay within the working directory. An empty scope or
+// one naming the root itself returns "".
+func ResolveScope(scope string) (string, error) {
+	if scope == "" {
+		return "", nil
+	}
+	if err := ValidatePath(scope); err != nil {
+		return "", err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	absPath, err := filepath.Abs(scope)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	rel, err := filepath.Rel(cwd, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path must be within project directory (cwd: %s, path: %s)", cwd, absPath)
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		return "", fmt.Errorf("scope path does not exist: %s", scope)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
 // ResolvePRDPath resolves the PRD path with a default from tasksDir.
 func ResolvePRDPath(tasksDir, prdPath string) string {
 	if prdPath == "" {
@@ -73,6 +73,39 @@ func TestValidatePath(t *testing.T) {
 	}
 }
 
+func TestResolveScope(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "services", "billing"), 0o755))
+	t.Chdir(root)
+
+	tests := []struct {
+		name    string
+		scope   string
+		want    string
+		wantErr string
+	}{
+		{name: "empty scope", scope: "", want: ""},
+		{name: "repo root", scope: ".", want: ""},
+		{name: "relative subdirectory", scope: "services/billing/", want: "services/billing"},
+		{name: "absolute path inside root", scope: filepath.Join(root, "services"), want: "services"},
+		{name: "missing path", scope: "services/missing", wantErr: "does not exist"},
+		{name: "outside root", scope: "..", wantErr: "within project directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pathutil.ResolveScope(tt.scope)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestResolvePRDPath(t *testing.T) {
 	tests := []struct {
 		name        string
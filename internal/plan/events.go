@@ -0,0 +1,76 @@
+package plan
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType identifies a planning progress event.
+type EventType string
+
+const (
+	EventPlanStart     EventType = "plan_start"
+	EventStepStart     EventType = "step_start"
+	EventStepDone      EventType = "step_done"
+	EventStepFailed    EventType = "step_failed"
+	EventSubstepDone   EventType = "substep_done" // One document of a parallel step
+	EventSubstepFailed EventType = "substep_failed"
+	EventPlanDone      EventType = "plan_done"
+)
+
+// Event is a Phase 2 planning progress event. Step fields are set for step
+// and sub-step events; a sub-step carries its parent step's number and its
+// own name.
+type Event struct {
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"`
+	Session   string    `json:"session"`
+	Step      int       `json:"step,omitempty"`
+	Total     int       `json:"total,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	ElapsedMS int64     `json:"elapsed_ms,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// EventSink receives planning progress events.
+type EventSink func(Event)
+
+// JSONEvents returns an EventSink that writes each event to w as one line of
+// JSON (NDJSON), for CI and editors that render planning progress.
+func JSONEvents(w io.Writer) EventSink {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		//nolint:errcheck // Best-effort progress output; planning continues without it.
+		_ = enc.Encode(e)
+	}
+}
+
+// WithEvents sends planning progress events to sink, alongside the text
+// written to the output writer.
+func WithEvents(sink EventSink) PlannerOption {
+	return func(p *Planner) { p.events = sink }
+}
+
+// emit sends an event for the session to the configured sink, if any.
+func (p *Planner) emit(e Event) {
+	if p.events == nil {
+		return
+	}
+	e.Time = time.Now().UTC()
+	e.Session = p.sessionName
+	p.events(e)
+}
+
+// stepEvent builds an event for the Phase 2 step at index i.
+func (p *Planner) stepEvent(t EventType, i int, elapsed time.Duration, err error) Event {
+	e := Event{Type: t, Step: i + 1, Total: p.stepCount(), Name: planSteps[i].Name, ElapsedMS: elapsed.Milliseconds()}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
@@ -0,0 +1,95 @@
+package plan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeEvents parses NDJSON written by JSONEvents.
+func decodeEvents(t *testing.T, r io.Reader) []Event {
+	t.Helper()
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e), "line: %s", scanner.Text())
+		events = append(events, e)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func eventKey(e Event) string {
+	if e.Step == 0 {
+		return string(e.Type)
+	}
+	return fmt.Sprintf("%s %d %s", e.Type, e.Step, e.Name)
+}
+
+func TestPlanner_JSONEvents(t *testing.T) {
+	var events bytes.Buffer
+	p := NewPlanner(&mockExecutor{}, "auth", t.TempDir(),
+		WithOutput(io.Discard),
+		WithBrief("requirements.md", "OAuth2"),
+		WithEvents(JSONEvents(&events)),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	got := decodeEvents(t, bytes.NewReader(events.Bytes()))
+	keys := make([]string, len(got))
+	for i, e := range got {
+		keys[i] = eventKey(e)
+		assert.Equal(t, "auth", e.Session)
+		assert.False(t, e.Time.IsZero())
+	}
+	assert.Equal(t, []string{
+		"plan_start",
+		"step_start 1 Generate PRD",
+		"step_done 1 Generate PRD",
+		"step_start 2 Generate technology plan + design spec",
+		"substep_done 2 Technology plan",
+		"substep_done 2 Design spec",
+		"step_done 2 Generate technology plan + design spec",
+		"step_start 3 Analyze tasks",
+		"step_done 3 Analyze tasks",
+		"step_start 4 Generate tasks",
+		"step_done 4 Generate tasks",
+		"plan_done",
+	}, keys)
+	assert.Equal(t, 4, got[1].Total)
+}
+
+func TestPlanner_JSONEvents_SubstepFailure(t *testing.T) {
+	exec := &promptMatchExecutor{failOn: map[string]error{"DESIGN.md": fmt.Errorf("design generation failed")}}
+	var events bytes.Buffer
+	p := NewPlanner(exec, "auth", t.TempDir(),
+		WithOutput(io.Discard),
+		WithBrief("requirements.md", "OAuth2"),
+		WithEvents(JSONEvents(&events)),
+	)
+	require.Error(t, p.Run(context.Background()))
+
+	got := decodeEvents(t, bytes.NewReader(events.Bytes()))
+	require.NotEmpty(t, got)
+	last := got[len(got)-1]
+	assert.Equal(t, EventStepFailed, last.Type)
+	assert.Equal(t, 2, last.Step)
+	assert.Contains(t, last.Error, "Design spec: design generation failed")
+
+	var failed []string
+	for _, e := range got {
+		if e.Type == EventSubstepFailed {
+			failed = append(failed, e.Name+": "+e.Error)
+		}
+	}
+	assert.Equal(t, []string{"Design spec: design generation failed"}, failed)
+	assert.NotContains(t, events.String(), `"plan_done"`)
+}
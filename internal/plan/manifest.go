@@ -0,0 +1,479 @@
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ManifestFile is the name of the plan manifest written to the tasks directory.
+const ManifestFile = "manifest.json"
+
+// TaskSpec is one entry of the TASKS.md task list (section G).
+type TaskSpec struct {
+	ID        string   `json:"id"`
+	File      string   `json:"file"`
+	Name      string   `json:"name,omitempty"`
+	Epic      string   `json:"epic,omitempty"`
+	Size      string   `json:"size,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// Artifact is a planning document and the SHA-256 of its content.
+type Artifact struct {
+	Path   string `json:"path"` // Relative to the tasks directory
+	SHA256 string `json:"sha256"`
+}
+
+// ManifestTask is a task from the task list with the hash of its task file.
+// SHA256 is empty when the task file is missing.
+type ManifestTask struct {
+	TaskSpec
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Manifest summarizes the artifacts produced by planning.
+type Manifest struct {
+	PRD        *Artifact      `json:"prd,omitempty"`
+	Technology *Artifact      `json:"technology,omitempty"`
+	Design     *Artifact      `json:"design,omitempty"`
+	TaskList   *Artifact      `json:"task_list,omitempty"`
+	Tasks      []ManifestTask `json:"tasks"`
+}
+
+var (
+	// taskRefPattern matches task IDs such as TASK3, with or without ".md".
+	taskRefPattern = regexp.MustCompile(`\bTASK(\d+)(?:\.md)?\b`)
+	// sectionHeadingPattern matches a TASKS.md section heading and captures its letter.
+	sectionHeadingPattern = regexp.MustCompile(`^#{2,3}\s+([A-Z])\.`)
+	// listItemPattern matches a numbered list item and captures its text.
+	listItemPattern = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	// listBulletPattern matches a bulleted line.
+	listBulletPattern = regexp.MustCompile(`^\s*[-*+]\s`)
+	// listFieldPattern matches a "Key: value" field, optionally bulleted.
+	listFieldPattern = regexp.MustCompile(`^\s*(?:[-*+]\s+)?([A-Za-z][A-Za-z /()-]*?)\s*:\s*(.*)$`)
+	// listSeparatorPattern splits the fields of a one-line list item.
+	listSeparatorPattern = regexp.MustCompile(`\s+[—–|-]\s+|;\s+`)
+	// sizePattern matches a task size such as S or (M).
+	sizePattern = regexp.MustCompile(`^\(?(XS|S|M|L|XL)\)?$`)
+)
+
+// ExtractTaskSpecs parses the task list in section G of TASKS.md, either a
+// markdown table or the numbered list the planner prompt asks for.
+//
+// Table columns are matched by header name (file, name, epic, size or scope,
+// depends); rows without a TASK<N>.md reference are skipped. List items are
+// read by listSpecs. Dependencies come from a depends column or field when
+// present, otherwise from "depends on" lines in section H.
+func ExtractTaskSpecs(tasksMD string) []TaskSpec {
+	sections := splitSections(tasksMD)
+
+	specs := tableSpecs(sections["G"])
+	if len(specs) == 0 {
+		specs = listSpecs(sections["G"])
+	}
+
+	deps := dependenciesFromSection(sections["H"])
+	for i := range specs {
+		if specs[i].DependsOn == nil {
+			specs[i].DependsOn = deps[specs[i].ID]
+		}
+	}
+	return specs
+}
+
+// tableSpecs reads the task list table rows of section G.
+func tableSpecs(lines []string) []TaskSpec {
+	var specs []TaskSpec
+	var cols map[string]int
+	for _, line := range lines {
+		cells, ok := tableCells(line)
+		if !ok {
+			cols = nil
+			continue
+		}
+		if cols == nil {
+			cols = headerColumns(cells)
+			continue
+		}
+		if isSeparatorRow(cells) {
+			continue
+		}
+		spec, ok := rowSpec(cells, cols)
+		if ok {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// listSpecs reads a numbered task list: one item per task, starting with its
+// TASK<N>.md file. The rest of the item is either "Key: value" fields on the
+// indented or bulleted lines below it (Name, Epic/increment type, Scope,
+// Depends on), or
+// fields on the same line separated by " — ", " | " or "; " in the prompt's
+// order: name, epic, outcome, risk, scope. Items without a task file are
+// skipped.
+func listSpecs(lines []string) []TaskSpec {
+	var specs []TaskSpec
+	current := -1
+	for _, raw := range lines {
+		line := stripEmphasis(raw)
+		if m := listItemPattern.FindStringSubmatch(line); m != nil && !isIndented(raw) {
+			spec, ok := listItemSpec(m[1])
+			if !ok {
+				current = -1
+				continue
+			}
+			specs = append(specs, spec)
+			current = len(specs) - 1
+			continue
+		}
+		if current < 0 || strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !isIndented(raw) && !listBulletPattern.MatchString(line) {
+			current = -1
+			continue
+		}
+		if m := listFieldPattern.FindStringSubmatch(line); m != nil {
+			applyListField(&specs[current], m[1], m[2])
+		}
+	}
+	return specs
+}
+
+// listItemSpec builds a TaskSpec from the text of a numbered list item.
+func listItemSpec(text string) (TaskSpec, bool) {
+	loc := taskRefPattern.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return TaskSpec{}, false
+	}
+	num := text[loc[2]:loc[3]]
+	spec := TaskSpec{ID: "TASK" + num, File: "TASK" + num + ".md"}
+
+	rest := strings.TrimLeft(text[loc[1]:], " :—–-|,")
+	var positional []string
+	for _, field := range listSeparatorPattern.Split(rest, -1) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if m := listFieldPattern.FindStringSubmatch(field); m != nil && listFieldKind(m[1]) != "" {
+			applyListField(&spec, m[1], m[2])
+			continue
+		}
+		positional = append(positional, field)
+	}
+	for i, field := range positional {
+		switch {
+		case i > 0 && sizePattern.MatchString(field):
+			spec.Size = strings.Trim(field, "()")
+		case i == 0 && spec.Name == "":
+			spec.Name = field
+		case i == 1 && spec.Epic == "":
+			spec.Epic = field
+		}
+	}
+	return spec, true
+}
+
+// applyListField sets the TaskSpec field named by a list item's key.
+func applyListField(spec *TaskSpec, key, value string) {
+	value = strings.TrimSpace(value)
+	switch listFieldKind(key) {
+	case "name":
+		spec.Name = value
+	case "epic":
+		spec.Epic = value
+	case "size":
+		spec.Size = value
+		if words := strings.Fields(value); len(words) > 0 {
+			if m := sizePattern.FindStringSubmatch(words[0]); m != nil {
+				spec.Size = m[1]
+			}
+		}
+	case "depends":
+		spec.DependsOn = taskRefs(value, spec.ID)
+		if spec.DependsOn == nil {
+			spec.DependsOn = []string{}
+		}
+	}
+}
+
+// listFieldKind maps a list field key to a TaskSpec field, like headerColumns
+// does for table headers. Unknown keys map to "".
+func listFieldKind(key string) string {
+	k := strings.ToLower(key)
+	switch {
+	case strings.Contains(k, "depend"):
+		return "depends"
+	case strings.Contains(k, "epic"), strings.Contains(k, "increment"):
+		return "epic"
+	case strings.Contains(k, "size"), strings.Contains(k, "scope"):
+		return "size"
+	case strings.Contains(k, "name"), k == "task", k == "title":
+		return "name"
+	}
+	return ""
+}
+
+// stripEmphasis removes markdown bold and code markers from a line.
+func stripEmphasis(line string) string {
+	return strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+}
+
+// isIndented reports whether a line starts with whitespace.
+func isIndented(line string) bool {
+	return line != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+// BuildManifest reads the planning documents in tasksDir and builds a manifest.
+func BuildManifest(tasksDir string) (*Manifest, error) {
+	m := &Manifest{Tasks: []ManifestTask{}}
+
+	var err error
+	for _, doc := range []struct {
+		name string
+		dst  **Artifact
+	}{
+		{"PRD.md", &m.PRD},
+		{"TECHNOLOGY.md", &m.Technology},
+		{"DESIGN.md", &m.Design},
+		{"TASKS.md", &m.TaskList},
+	} {
+		if *doc.dst, err = hashArtifact(tasksDir, doc.name); err != nil {
+			return nil, err
+		}
+	}
+
+	var specs []TaskSpec
+	if m.TaskList != nil {
+		data, err := os.ReadFile(filepath.Join(tasksDir, "TASKS.md"))
+		if err != nil {
+			return nil, fmt.Errorf("read TASKS.md: %w", err)
+		}
+		specs = ExtractTaskSpecs(string(data))
+	}
+
+	// Task files on disk that the list doesn't mention are still artifacts.
+	listed := make(map[string]bool, len(specs))
+	for _, s := range specs {
+		listed[s.File] = true
+	}
+	var extra []string
+	for name := range readTaskFiles(tasksDir) {
+		if !listed[name] {
+			extra = append(extra, name)
+		}
+	}
+	sortTaskNames(extra)
+	for _, name := range extra {
+		specs = append(specs, TaskSpec{ID: strings.TrimSuffix(name, ".md"), File: name})
+	}
+
+	for _, s := range specs {
+		task := ManifestTask{TaskSpec: s}
+		a, err := hashArtifact(tasksDir, s.File)
+		if err != nil {
+			return nil, err
+		}
+		if a != nil {
+			task.SHA256 = a.SHA256
+		}
+		m.Tasks = append(m.Tasks, task)
+	}
+	return m, nil
+}
+
+// WriteManifest builds the manifest for tasksDir and writes it to
+// tasksDir/manifest.json.
+func WriteManifest(tasksDir string) error {
+	m, err := BuildManifest(tasksDir)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tasksDir, ManifestFile), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
+
+// LoadManifest reads tasksDir/manifest.json.
+func LoadManifest(tasksDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(tasksDir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// hashArtifact returns the artifact for tasksDir/name, or nil when the file
+// does not exist.
+func hashArtifact(tasksDir, name string) (*Artifact, error) {
+	data, err := os.ReadFile(filepath.Join(tasksDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	return &Artifact{Path: name, SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+// splitSections groups TASKS.md lines by their lettered section heading.
+func splitSections(md string) map[string][]string {
+	sections := make(map[string][]string)
+	current := ""
+	for _, line := range strings.Split(md, "\n") {
+		if m := sectionHeadingPattern.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			continue
+		}
+		if strings.HasPrefix(line, "## ") {
+			current = ""
+			continue
+		}
+		if current != "" {
+			sections[current] = append(sections[current], line)
+		}
+	}
+	return sections
+}
+
+// tableCells splits a markdown table row into trimmed cells.
+func tableCells(line string) ([]string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "|") {
+		return nil, false
+	}
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i, c := range cells {
+		cells[i] = strings.Trim(strings.TrimSpace(c), "`*")
+	}
+	return cells, true
+}
+
+func isSeparatorRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.Trim(c, "-: ") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// headerColumns maps known column kinds to their index in a header row.
+func headerColumns(cells []string) map[string]int {
+	cols := make(map[string]int)
+	for i, c := range cells {
+		h := strings.ToLower(c)
+		var kind string
+		switch {
+		case strings.Contains(h, "file"):
+			kind = "file"
+		case strings.Contains(h, "depend"):
+			kind = "depends"
+		case strings.Contains(h, "epic"):
+			kind = "epic"
+		case strings.Contains(h, "size"), strings.Contains(h, "scope"):
+			kind = "size"
+		case strings.Contains(h, "name"), h == "task":
+			kind = "name"
+		}
+		if _, seen := cols[kind]; kind != "" && !seen {
+			cols[kind] = i
+		}
+	}
+	return cols
+}
+
+// rowSpec builds a TaskSpec from a table row. The task file is taken from the
+// file column, or from the first cell that references one.
+func rowSpec(cells []string, cols map[string]int) (TaskSpec, bool) {
+	cell := func(kind string) string {
+		if i, ok := cols[kind]; ok && i < len(cells) {
+			return cells[i]
+		}
+		return ""
+	}
+
+	var num string
+	if m := taskRefPattern.FindStringSubmatch(cell("file")); m != nil {
+		num = m[1]
+	} else {
+		for _, c := range cells {
+			if m := taskRefPattern.FindStringSubmatch(c); m != nil {
+				num = m[1]
+				break
+			}
+		}
+	}
+	if num == "" {
+		return TaskSpec{}, false
+	}
+
+	spec := TaskSpec{
+		ID:   "TASK" + num,
+		File: "TASK" + num + ".md",
+		Name: cell("name"),
+		Epic: cell("epic"),
+		Size: cell("size"),
+	}
+	if _, ok := cols["depends"]; ok {
+		spec.DependsOn = taskRefs(cell("depends"), spec.ID)
+		if spec.DependsOn == nil {
+			spec.DependsOn = []string{}
+		}
+	}
+	return spec, true
+}
+
+// dependenciesFromSection reads "TASK3 depends on TASK1, TASK2" style lines.
+func dependenciesFromSection(lines []string) map[string][]string {
+	deps := make(map[string][]string)
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		idx := strings.Index(lower, "depends on")
+		if idx < 0 {
+			continue
+		}
+		subject := taskRefPattern.FindStringSubmatch(line[:idx])
+		if subject == nil {
+			continue
+		}
+		id := "TASK" + subject[1]
+		deps[id] = append(deps[id], taskRefs(line[idx:], id)...)
+	}
+	return deps
+}
+
+// taskRefs returns the distinct task IDs referenced in s, excluding self.
+func taskRefs(s, self string) []string {
+	var refs []string
+	seen := map[string]bool{self: true}
+	for _, m := range taskRefPattern.FindAllStringSubmatch(s, -1) {
+		id := "TASK" + m[1]
+		if !seen[id] {
+			seen[id] = true
+			refs = append(refs, id)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,237 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleTasksMD = `# Tasks
+
+## G. Task List
+
+| # | File | Name | Epic | Outcome | Risk | Size |
+|---|------|------|------|---------|------|------|
+| 0 | ` + "`TASK0.md`" + ` | Scaffold project | E1 | Builds | Low | S |
+| 1 | TASK1.md | Login | E2 | Users can log in | Med | M |
+| 2 | TASK2.md | Logout | E2 | Users can log out | Low | S |
+
+## H. Dependency Graph & Critical Path
+
+- TASK1 depends on TASK0
+- TASK2 depends on TASK0, TASK1
+`
+
+// promptFormatTasksMD is a TASKS.md in the format generate-tasks.md asks
+// for: sections A–J, with section G a numbered list of file name, name,
+// Epic/increment type, user-visible outcome, risk justification and scope.
+const promptFormatTasksMD = `# TASKS
+
+## A. Document Intake Summary
+
+- CLI that greets users by name (PRD.md); Go 1.22, cobra (TECHNOLOGY.md)
+
+## B. Assumptions
+
+- Names are ASCII
+
+## C. Vertical Slice Design Principles
+
+- Every task ships a runnable command
+
+## D. Critical User Journeys
+
+1. Greet: user runs ` + "`greet Ada`" + ` and sees "Hello, Ada"
+
+## E. Epic List
+
+- Epic 1: Greeting — Thin E2E: TASK0; Enhancement Wave: TASK1, TASK2
+
+## F. Capability Map
+
+- Greeting → cmd/greet.go
+
+## G. Task List
+
+1. **TASK0.md** — Scaffold the greet command
+   - Epic/increment type: Epic 1 — Thin E2E
+   - User-visible outcome: ` + "`greet`" + ` prints a usage line
+   - Risk justification: Unblocks every later task
+   - Scope: S
+2. **TASK1.md** — Greet by name
+   - Epic/increment type: Epic 1 — Enhancement Wave
+   - User-visible outcome: ` + "`greet Ada`" + ` prints "Hello, Ada"
+   - Risk justification: Core journey
+   - Scope: M
+3. **TASK2.md** — Reject empty names
+   - Epic/increment type: Epic 1 — Enhancement Wave
+   - User-visible outcome: an empty name exits 2 with an error
+   - Risk justification: Validation regressions
+   - Scope: S
+
+## H. Dependency Graph & Critical Path
+
+- TASK1 depends on TASK0
+- TASK2 depends on TASK1
+- Critical path: TASK0 → TASK1 → TASK2
+
+## I. Risk Register
+
+- Validation regressions → bad input crashes → TASK2
+
+## J. Coverage Checklist
+
+- Greeting → TASK1
+`
+
+func TestExtractTaskSpecs(t *testing.T) {
+	specs := ExtractTaskSpecs(sampleTasksMD)
+	require.Len(t, specs, 3)
+
+	assert.Equal(t, TaskSpec{ID: "TASK0", File: "TASK0.md", Name: "Scaffold project", Epic: "E1", Size: "S"}, specs[0])
+	assert.Equal(t, TaskSpec{
+		ID: "TASK1", File: "TASK1.md", Name: "Login", Epic: "E2", Size: "M",
+		DependsOn: []string{"TASK0"},
+	}, specs[1])
+	assert.Equal(t, []string{"TASK0", "TASK1"}, specs[2].DependsOn)
+}
+
+func TestExtractTaskSpecs_DependsColumn(t *testing.T) {
+	md := "## G. Task List\n\n" +
+		"| File | Task name | Epic | Scope | Depends on |\n" +
+		"|------|-----------|------|-------|------------|\n" +
+		"| TASK1.md | Login | Auth | L | — |\n" +
+		"| TASK2.md | Logout | Auth | S | TASK1 |\n\n" +
+		"## H. Dependency Graph\n\nTASK1 depends on TASK9\n"
+
+	specs := ExtractTaskSpecs(md)
+	require.Len(t, specs, 2)
+	assert.Equal(t, "Login", specs[0].Name)
+	assert.Equal(t, "L", specs[0].Size)
+	assert.Empty(t, specs[0].DependsOn, "depends column takes precedence over section H")
+	assert.Equal(t, []string{"TASK1"}, specs[1].DependsOn)
+}
+
+func TestExtractTaskSpecs_PromptFormat(t *testing.T) {
+	specs := ExtractTaskSpecs(promptFormatTasksMD)
+	require.Len(t, specs, 3)
+
+	assert.Equal(t, TaskSpec{
+		ID: "TASK0", File: "TASK0.md", Name: "Scaffold the greet command",
+		Epic: "Epic 1 — Thin E2E", Size: "S",
+	}, specs[0])
+	assert.Equal(t, TaskSpec{
+		ID: "TASK1", File: "TASK1.md", Name: "Greet by name",
+		Epic: "Epic 1 — Enhancement Wave", Size: "M", DependsOn: []string{"TASK0"},
+	}, specs[1])
+	assert.Equal(t, "Reject empty names", specs[2].Name)
+	assert.Equal(t, []string{"TASK1"}, specs[2].DependsOn)
+}
+
+func TestExtractTaskSpecs_OneLineListItems(t *testing.T) {
+	md := "## G. Task List\n\n" +
+		"1. TASK1.md — Login — Epic 1 / Thin E2E — Users can log in — Touches auth — (M)\n" +
+		"2. `TASK2.md` | Logout | Epic 1 / Enhancement | Users can log out | Low | S; Depends on: TASK1\n" +
+		"3. Write the README (no task file)\n\n" +
+		"## H. Dependency Graph\n"
+
+	specs := ExtractTaskSpecs(md)
+	require.Len(t, specs, 2)
+	assert.Equal(t, TaskSpec{ID: "TASK1", File: "TASK1.md", Name: "Login", Epic: "Epic 1 / Thin E2E", Size: "M"}, specs[0])
+	assert.Equal(t, TaskSpec{
+		ID: "TASK2", File: "TASK2.md", Name: "Logout", Epic: "Epic 1 / Enhancement", Size: "S",
+		DependsOn: []string{"TASK1"},
+	}, specs[1])
+}
+
+func TestExtractTaskSpecs_NoTaskList(t *testing.T) {
+	assert.Empty(t, ExtractTaskSpecs("# Tasks\n\n## A. Overview\n\n| File |\n|---|\n| TASK1.md |\n"))
+	assert.Empty(t, ExtractTaskSpecs(""))
+}
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"PRD.md":        "# PRD\n",
+		"TECHNOLOGY.md": "# Tech\n",
+		"TASKS.md":      sampleTasksMD,
+		"TASK0.md":      "# Task 0\n",
+		"TASK1.md":      "# Task 1\n",
+		"TASK5.md":      "# Task 5\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	require.NoError(t, WriteManifest(dir))
+	m, err := LoadManifest(dir)
+	require.NoError(t, err)
+
+	require.NotNil(t, m.PRD)
+	assert.Equal(t, "PRD.md", m.PRD.Path)
+	assert.Equal(t, sha256Hex(files["PRD.md"]), m.PRD.SHA256)
+	require.NotNil(t, m.Technology)
+	assert.Nil(t, m.Design, "missing documents are omitted")
+	require.NotNil(t, m.TaskList)
+	assert.Equal(t, sha256Hex(sampleTasksMD), m.TaskList.SHA256)
+
+	require.Len(t, m.Tasks, 4)
+	assert.Equal(t, "TASK0", m.Tasks[0].ID)
+	assert.Equal(t, sha256Hex(files["TASK0.md"]), m.Tasks[0].SHA256)
+	assert.Equal(t, "TASK2", m.Tasks[2].ID)
+	assert.Empty(t, m.Tasks[2].SHA256, "listed task without a file has no hash")
+	assert.Equal(t, "TASK5", m.Tasks[3].ID, "unlisted task files are appended")
+	assert.Empty(t, m.Tasks[3].Name)
+
+	// Tasks match ExtractTaskSpecs for the listed entries.
+	for i, spec := range ExtractTaskSpecs(sampleTasksMD) {
+		assert.Equal(t, spec, m.Tasks[i].TaskSpec)
+	}
+}
+
+func TestWriteManifest_EmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, WriteManifest(dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"tasks\": []\n}\n", string(data))
+}
+
+func TestLoadManifest_Missing(t *testing.T) {
+	_, err := LoadManifest(t.TempDir())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestPlanner_WritesManifest(t *testing.T) {
+	dir := t.TempDir()
+	exec := &taskWritingExecutor{dir: dir, files: map[string]string{
+		"TASKS.md": sampleTasksMD,
+		"TASK0.md": "# Task 0\n",
+	}}
+	var out bytes.Buffer
+
+	p := NewPlanner(exec, "auth", dir,
+		WithOutput(&out),
+		WithInput(strings.NewReader("/done\n")),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	m, err := LoadManifest(dir)
+	require.NoError(t, err)
+	require.Len(t, m.Tasks, 3)
+	assert.Equal(t, sha256Hex("# Task 0\n"), m.Tasks[0].SHA256)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -7,6 +7,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,6 +21,10 @@ import (
 	"github.com/yarlson/snap/internal/workflow"
 )
 
+// ErrRequirementTimeout is returned when Phase 1 receives no input within the
+// configured requirement timeout.
+var ErrRequirementTimeout = errors.New("requirements input timed out")
+
 // Planner orchestrates the two-phase planning pipeline.
 type Planner struct {
 	executor          workflow.Executor
@@ -25,11 +33,20 @@ type Planner struct {
 	output            io.Writer
 	input             io.Reader
 	interactive       bool         // when true, uses tap for interactive TTY input
-	briefFile         string       // filename for display (e.g., "brief.md")
+	scripted          bool         // when true, Phase 1 sends script instead of reading input
+	script            []string     // scripted Phase 1 messages
+	briefFile         string       // filename(s) for display (e.g., "brief.md")
 	briefBody         string       // file content
 	resume            bool         // when true, first executor call uses -c to continue previous conversation
+	amend             bool         // when true, adds requirements to an existing plan instead of starting one
+	guardrails        []string     // project rules appended to each Phase 2 prompt
 	afterFirstMessage func() error // called once after the first successful executor call
 	firstMessageDone  bool
+	maxTurns          int           // max user messages in Phase 1 before auto-advancing (0 = unlimited)
+	requireTimeout    time.Duration // max wait for interactive Phase 1 input (0 = no timeout)
+	requirementsPath  string        // custom Phase 1 prompt file (empty = embedded prompt)
+	events            EventSink     // receives Phase 2 progress events (nil = none)
+	withTests         bool          // when true, a final step writes TASK<N>.tests.md per task
 }
 
 // PlannerOption configures a Planner.
@@ -51,6 +68,32 @@ func WithResume(resume bool) PlannerOption {
 	return func(p *Planner) { p.resume = resume }
 }
 
+// WithAmend switches the planner to amending an existing plan: Phase 1
+// continues the previous conversation (-c) and asks only for new
+// requirements, and Phase 2 updates the documents in place, keeping task
+// files the new requirements don't affect.
+func WithAmend(amend bool) PlannerOption {
+	return func(p *Planner) {
+		p.amend = amend
+		if amend {
+			p.resume = true
+		}
+	}
+}
+
+// WithGuardrails appends project rules (e.g. "no new dependencies without an
+// ADR") to every Phase 2 prompt, after the engineering principles, so the
+// PRD, design and tasks respect them. Empty rules add nothing.
+func WithGuardrails(rules []string) PlannerOption {
+	return func(p *Planner) { p.guardrails = rules }
+}
+
+// WithTests adds a final Phase 2 step that writes an acceptance test outline,
+// TASK<N>.tests.md, next to each generated task file.
+func WithTests(enabled bool) PlannerOption {
+	return func(p *Planner) { p.withTests = enabled }
+}
+
 // WithAfterFirstMessage sets a callback that fires once after the first successful executor call.
 func WithAfterFirstMessage(fn func() error) PlannerOption {
 	return func(p *Planner) { p.afterFirstMessage = fn }
@@ -63,6 +106,39 @@ func WithInteractive(interactive bool) PlannerOption {
 	return func(p *Planner) { p.interactive = interactive }
 }
 
+// WithScript replaces Phase 1 input with a fixed list of messages (see
+// ParseScript). The planner sends the requirements prompt, then each message
+// with -c, then moves on to Phase 2, so a script of N messages always makes
+// 1+N Phase 1 executor calls (fewer if WithMaxTurns caps it). An empty script
+// sends only the requirements prompt.
+func WithScript(turns []string) PlannerOption {
+	return func(p *Planner) {
+		p.scripted = true
+		p.script = turns
+	}
+}
+
+// WithMaxTurns caps the number of user messages sent during Phase 1. Once the
+// limit is reached, the planner advances to Phase 2 as if /done was entered.
+// Zero or negative means unlimited.
+func WithMaxTurns(n int) PlannerOption {
+	return func(p *Planner) { p.maxTurns = n }
+}
+
+// WithRequirementTimeout aborts interactive Phase 1 with ErrRequirementTimeout
+// when no input is submitted within d. Zero or negative disables the timeout.
+func WithRequirementTimeout(d time.Duration) PlannerOption {
+	return func(p *Planner) { p.requireTimeout = d }
+}
+
+// WithRequirementsPrompt replaces the embedded Phase 1 requirements prompt
+// with the contents of the file at path (see LoadRequirementsPrompt). The
+// /done loop and -c continuation are unchanged. An empty path keeps the
+// embedded prompt; amending always uses the amend prompt.
+func WithRequirementsPrompt(path string) PlannerOption {
+	return func(p *Planner) { p.requirementsPath = path }
+}
+
 // WithBrief sets the brief file content, skipping Phase 1.
 func WithBrief(filename, content string) PlannerOption {
 	return func(p *Planner) {
@@ -71,10 +147,38 @@ func WithBrief(filename, content string) PlannerOption {
 	}
 }
 
+// Brief is a named input document used in place of interactive requirements gathering.
+type Brief struct {
+	Name    string
+	Content string
+}
+
+// WithBriefs sets several brief documents, skipping Phase 1. The documents are
+// concatenated into the PRD prompt, each under a heading labeled with its name.
+// A single brief behaves exactly like WithBrief.
+func WithBriefs(briefs []Brief) PlannerOption {
+	return func(p *Planner) {
+		if len(briefs) == 1 {
+			p.briefFile = briefs[0].Name
+			p.briefBody = briefs[0].Content
+			return
+		}
+
+		names := make([]string, 0, len(briefs))
+		sections := make([]string, 0, len(briefs))
+		for _, b := range briefs {
+			names = append(names, b.Name)
+			sections = append(sections, fmt.Sprintf("#### Source: %s\n\n%s", b.Name, strings.TrimSpace(b.Content)))
+		}
+		p.briefFile = strings.Join(names, ", ")
+		p.briefBody = strings.Join(sections, "\n\n")
+	}
+}
+
 // NewPlanner creates a new Planner with the given options.
 func NewPlanner(executor workflow.Executor, sessionName, tasksDir string, opts ...PlannerOption) *Planner {
 	p := &Planner{
-		executor:    executor,
+		executor:    providerExecutor{executor},
 		sessionName: sessionName,
 		tasksDir:    tasksDir,
 		output:      os.Stdout,
@@ -86,6 +190,14 @@ func NewPlanner(executor workflow.Executor, sessionName, tasksDir string, opts .
 	return p
 }
 
+// providerExecutor marks every executor failure with workflow.ErrProvider,
+// so the CLI can tell provider failures apart from planning errors.
+type providerExecutor struct{ workflow.Executor }
+
+func (e providerExecutor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	return workflow.ProviderError(e.Executor.Run(ctx, w, mt, args...))
+}
+
 // onFirstMessage fires the afterFirstMessage callback once after the first successful executor call.
 func (p *Planner) onFirstMessage() error {
 	if p.firstMessageDone || p.afterFirstMessage == nil {
@@ -98,9 +210,13 @@ func (p *Planner) onFirstMessage() error {
 // Run orchestrates the full planning pipeline: Phase 1 (requirements gathering)
 // followed by Phase 2 (autonomous document generation).
 func (p *Planner) Run(ctx context.Context) error {
+	p.emit(Event{Type: EventPlanStart})
+
 	switch {
 	case p.briefBody != "":
 		fmt.Fprint(p.output, ui.Step(fmt.Sprintf("Planning session '%s' — using %s as input", p.sessionName, p.briefFile)))
+	case p.amend:
+		fmt.Fprint(p.output, ui.Step(fmt.Sprintf("Amending plan for session '%s'", p.sessionName)))
 	case p.resume:
 		fmt.Fprint(p.output, ui.Step(fmt.Sprintf("Resuming planning for session '%s'", p.sessionName)))
 	default:
@@ -118,16 +234,38 @@ func (p *Planner) Run(ctx context.Context) error {
 	}
 
 	// Phase 2: autonomous document generation.
-	return p.generateDocuments(ctx)
+	if !p.amend {
+		return p.generateDocuments(ctx)
+	}
+
+	before := readTaskFiles(p.tasksDir)
+	if err := p.generateDocuments(ctx); err != nil {
+		return err
+	}
+	reportTaskChanges(p.output, before, readTaskFiles(p.tasksDir))
+	return nil
 }
 
 // gatherRequirements runs the interactive Phase 1 chat loop.
 func (p *Planner) gatherRequirements(ctx context.Context) error {
-	fmt.Fprint(p.output, ui.Step("Gathering requirements — type /done when ready"))
+	if p.scripted {
+		fmt.Fprint(p.output, ui.Step(fmt.Sprintf("Gathering requirements — %d scripted message(s)", len(p.script))))
+	} else {
+		fmt.Fprint(p.output, ui.Step("Gathering requirements — type /done when ready"))
+	}
 
 	// Send the initial requirements-gathering prompt.
 	// When resuming, add -c flag to continue previous conversation.
-	prompt, err := RenderRequirementsPrompt()
+	var prompt string
+	var err error
+	switch {
+	case p.amend:
+		prompt, err = RenderAmendPrompt(p.tasksDir)
+	case p.requirementsPath != "":
+		prompt, err = LoadRequirementsPrompt(p.requirementsPath)
+	default:
+		prompt, err = RenderRequirementsPrompt()
+	}
 	if err != nil {
 		return fmt.Errorf("requirements prompt failed: %w", err)
 	}
@@ -145,15 +283,30 @@ func (p *Planner) gatherRequirements(ctx context.Context) error {
 	}
 
 	// Chat loop: read user input, send with -c, repeat until /done or EOF.
+	if p.scripted {
+		return p.gatherRequirementsScript(ctx)
+	}
 	if p.interactive {
 		return p.gatherRequirementsInteractive(ctx)
 	}
 	return p.gatherRequirementsScanner(ctx)
 }
 
+// turnLimitReached reports whether the Phase 1 message cap has been hit after
+// sent messages, printing a note when it has.
+func (p *Planner) turnLimitReached(sent int) bool {
+	if p.maxTurns <= 0 || sent < p.maxTurns {
+		return false
+	}
+	fmt.Fprint(p.output, "\n")
+	fmt.Fprint(p.output, ui.Info(fmt.Sprintf("Reached the %d-message limit — moving on to document generation", p.maxTurns)))
+	return true
+}
+
 // gatherRequirementsInteractive uses tap.Textarea for interactive TTY input.
 // Ctrl+C or Escape returns context.Canceled to abort the plan command.
 func (p *Planner) gatherRequirementsInteractive(ctx context.Context) error {
+	sent := 0
 	for {
 		if ctx.Err() != nil {
 			return ctx.Err()
@@ -161,7 +314,12 @@ func (p *Planner) gatherRequirementsInteractive(ctx context.Context) error {
 
 		fmt.Fprint(p.output, "\n")
 
-		result := tap.Textarea(ctx, tap.TextareaOptions{
+		promptCtx, cancelPrompt := ctx, context.CancelFunc(func() {})
+		if p.requireTimeout > 0 {
+			promptCtx, cancelPrompt = context.WithTimeout(ctx, p.requireTimeout)
+		}
+
+		result := tap.Textarea(promptCtx, tap.TextareaOptions{
 			Message:     "Your response",
 			Placeholder: "Describe your requirements, or /done to finish",
 			Validate: func(s string) error {
@@ -171,10 +329,16 @@ func (p *Planner) gatherRequirementsInteractive(ctx context.Context) error {
 				return nil
 			},
 		})
+		timedOut := errors.Is(promptCtx.Err(), context.DeadlineExceeded)
+		cancelPrompt()
 
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if timedOut {
+			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("No input for %s — requirements gathering aborted", ui.FormatDuration(p.requireTimeout))))
+			return fmt.Errorf("%w after %s", ErrRequirementTimeout, ui.FormatDuration(p.requireTimeout))
+		}
 		// tap.Text returns empty string when user aborts (Ctrl+C or Escape).
 		// The Validate func ensures non-empty input on normal submission.
 		if result == "" {
@@ -189,12 +353,17 @@ func (p *Planner) gatherRequirementsInteractive(ctx context.Context) error {
 		if err := p.executor.Run(ctx, p.output, model.Thinking, "-c", result); err != nil {
 			return fmt.Errorf("chat message failed: %w", err)
 		}
+		sent++
+		if p.turnLimitReached(sent) {
+			return nil
+		}
 	}
 }
 
 // gatherRequirementsScanner uses bufio.Scanner for non-TTY (piped) input.
 func (p *Planner) gatherRequirementsScanner(ctx context.Context) error {
 	scanner := bufio.NewScanner(p.input)
+	sent := 0
 	for {
 		if ctx.Err() != nil {
 			return ctx.Err()
@@ -217,6 +386,10 @@ func (p *Planner) gatherRequirementsScanner(ctx context.Context) error {
 		if err := p.executor.Run(ctx, p.output, model.Thinking, "-c", line); err != nil {
 			return fmt.Errorf("chat message failed: %w", err)
 		}
+		sent++
+		if p.turnLimitReached(sent) {
+			break
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -226,22 +399,42 @@ func (p *Planner) gatherRequirementsScanner(ctx context.Context) error {
 	return nil
 }
 
+// gatherRequirementsScript sends each scripted message with -c, echoing it
+// after the chat prompt so logs read like a piped session.
+func (p *Planner) gatherRequirementsScript(ctx context.Context) error {
+	for sent, turn := range p.script {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		fmt.Fprintf(p.output, "\nsnap plan> %s\n", turn)
+
+		if err := p.executor.Run(ctx, p.output, model.Thinking, "-c", turn); err != nil {
+			return fmt.Errorf("chat message failed: %w", err)
+		}
+		if p.turnLimitReached(sent + 1) {
+			break
+		}
+	}
+	return nil
+}
+
 // generateDocuments runs the autonomous Phase 2 document generation pipeline.
 // Pipeline: PRD (sequential) → TECHNOLOGY + DESIGN (parallel) → Analyze tasks (fresh conversation)
 // → Generate tasks with subagents (-c continuation).
 func (p *Planner) generateDocuments(ctx context.Context) error {
 	fmt.Fprint(p.output, ui.Step("Generating planning documents..."))
 
-	const totalSteps = 4
+	totalSteps := p.stepCount()
 
-	// --- Step 1/4: Generate PRD (sequential) ---
+	// --- Step 1: Generate PRD (sequential) ---
 	if ctx.Err() != nil {
-		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step 1/%d", totalSteps)))
+		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepPRD+1, totalSteps)))
 		fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
 		return ctx.Err()
 	}
 
-	prdPrompt, err := RenderPRDPrompt(p.tasksDir, p.briefBody)
+	prdPrompt, err := p.phase2Prompt(RenderPRDPrompt(p.tasksDir, p.briefBody))
 	if err != nil {
 		return fmt.Errorf("failed to render Generate PRD prompt: %w", err)
 	}
@@ -254,19 +447,21 @@ func (p *Planner) generateDocuments(ctx context.Context) error {
 	}
 	prdArgs = append(prdArgs, prdPrompt)
 
-	fmt.Fprint(p.output, ui.StepNumbered(1, totalSteps, "Generate PRD"))
+	fmt.Fprint(p.output, ui.StepNumbered(stepPRD+1, totalSteps, planSteps[stepPRD].Name))
+	p.emit(p.stepEvent(EventStepStart, stepPRD, 0, nil))
 
 	start := time.Now()
 	if err := p.executor.Run(ctx, p.output, model.Thinking, prdArgs...); err != nil {
 		elapsed := time.Since(start)
 		fmt.Fprintln(p.output, ui.StepFailed("Step failed", elapsed))
+		p.emit(p.stepEvent(EventStepFailed, stepPRD, elapsed, err))
 
 		if ctx.Err() != nil {
-			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step 1/%d", totalSteps)))
+			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepPRD+1, totalSteps)))
 			fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
 			return ctx.Err()
 		}
-		return fmt.Errorf("step 1/%d %q failed: %w", totalSteps, "Generate PRD", err)
+		return fmt.Errorf("step %d/%d %q failed: %w", stepPRD+1, totalSteps, planSteps[stepPRD].Name, err)
 	}
 
 	if err := p.onFirstMessage(); err != nil {
@@ -274,20 +469,21 @@ func (p *Planner) generateDocuments(ctx context.Context) error {
 	}
 
 	fmt.Fprintln(p.output, ui.StepComplete("Step complete", time.Since(start)))
+	p.emit(p.stepEvent(EventStepDone, stepPRD, time.Since(start), nil))
 
-	// --- Step 2/4: Generate technology plan + design spec (parallel) ---
+	// --- Step 2: Generate technology plan + design spec (parallel) ---
 	if ctx.Err() != nil {
-		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step 2/%d", totalSteps)))
+		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepTechDesign+1, totalSteps)))
 		fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
 		return ctx.Err()
 	}
 
-	techPrompt, err := RenderTechnologyPrompt(p.tasksDir)
+	techPrompt, err := p.phase2Prompt(RenderTechnologyPrompt(p.tasksDir))
 	if err != nil {
 		return fmt.Errorf("failed to render technology prompt: %w", err)
 	}
 
-	designPrompt, err := RenderDesignPrompt(p.tasksDir)
+	designPrompt, err := p.phase2Prompt(RenderDesignPrompt(p.tasksDir))
 	if err != nil {
 		return fmt.Errorf("failed to render design prompt: %w", err)
 	}
@@ -297,96 +493,303 @@ func (p *Planner) generateDocuments(ctx context.Context) error {
 		{name: "Design spec", modelType: model.Thinking, args: []string{designPrompt}},
 	}
 
-	fmt.Fprint(p.output, ui.StepNumbered(2, totalSteps, "Generate technology plan + design spec"))
+	fmt.Fprint(p.output, ui.StepNumbered(stepTechDesign+1, totalSteps, planSteps[stepTechDesign].Name))
+	p.emit(p.stepEvent(EventStepStart, stepTechDesign, 0, nil))
 
+	start = time.Now()
 	results := runParallel(ctx, p.executor, tasks, 0)
 
 	// Print sub-step results and check for failures.
-	var parallelFailed bool
+	var errs []string
 	for _, r := range results {
+		sub := p.stepEvent(EventSubstepDone, stepTechDesign, r.elapsed, r.err)
+		sub.Name = r.name
 		if r.err != nil {
 			fmt.Fprintln(p.output, ui.StepFailed(r.name, r.elapsed))
-			parallelFailed = true
+			sub.Type = EventSubstepFailed
+			errs = append(errs, fmt.Sprintf("%s: %v", r.name, r.err))
 		} else {
 			fmt.Fprintln(p.output, ui.StepComplete(r.name, r.elapsed))
 		}
+		p.emit(sub)
 	}
 
-	if parallelFailed {
+	if len(errs) > 0 {
+		p.emit(p.stepEvent(EventStepFailed, stepTechDesign, time.Since(start), errors.New(strings.Join(errs, "; "))))
 		if ctx.Err() != nil {
-			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step 2/%d", totalSteps)))
+			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepTechDesign+1, totalSteps)))
 			fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
 			return ctx.Err()
 		}
-		var errs []string
-		for _, r := range results {
-			if r.err != nil {
-				errs = append(errs, fmt.Sprintf("%s: %v", r.name, r.err))
-			}
-		}
-		return fmt.Errorf("step 2/%d failed: %s", totalSteps, strings.Join(errs, "; "))
+		return fmt.Errorf("step %d/%d failed: %s", stepTechDesign+1, totalSteps, strings.Join(errs, "; "))
 	}
+	p.emit(p.stepEvent(EventStepDone, stepTechDesign, time.Since(start), nil))
 
-	// --- Step 3/4: Analyze tasks (fresh conversation, no -c) ---
+	// --- Step 3: Analyze tasks (fresh conversation, no -c) ---
 	if ctx.Err() != nil {
-		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step 3/%d", totalSteps)))
+		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepAnalyze+1, totalSteps)))
 		fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
 		return ctx.Err()
 	}
 
-	analyzePrompt, err := RenderAnalyzeTasksPrompt(p.tasksDir)
+	analyzePrompt, err := p.phase2Prompt(RenderAnalyzeTasksPrompt(p.tasksDir))
 	if err != nil {
 		return fmt.Errorf("failed to render Analyze tasks prompt: %w", err)
 	}
 
-	fmt.Fprint(p.output, ui.StepNumbered(3, totalSteps, "Analyze tasks"))
+	fmt.Fprint(p.output, ui.StepNumbered(stepAnalyze+1, totalSteps, planSteps[stepAnalyze].Name))
+	p.emit(p.stepEvent(EventStepStart, stepAnalyze, 0, nil))
 
 	start = time.Now()
 	if err := p.executor.Run(ctx, p.output, model.Thinking, analyzePrompt); err != nil {
 		elapsed := time.Since(start)
 		fmt.Fprintln(p.output, ui.StepFailed("Step failed", elapsed))
+		p.emit(p.stepEvent(EventStepFailed, stepAnalyze, elapsed, err))
 
 		if ctx.Err() != nil {
-			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step 3/%d", totalSteps)))
+			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepAnalyze+1, totalSteps)))
 			fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
 			return ctx.Err()
 		}
-		return fmt.Errorf("step 3/%d %q failed: %w", totalSteps, "Analyze tasks", err)
+		return fmt.Errorf("step %d/%d %q failed: %w", stepAnalyze+1, totalSteps, planSteps[stepAnalyze].Name, err)
 	}
 
 	fmt.Fprintln(p.output, ui.StepComplete("Step complete", time.Since(start)))
+	p.emit(p.stepEvent(EventStepDone, stepAnalyze, time.Since(start), nil))
 
-	// --- Step 4/4: Generate tasks (-c, continues step 3 conversation) ---
+	// --- Step 4: Generate tasks (-c, continues step 3 conversation) ---
 	if ctx.Err() != nil {
-		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step 4/%d", totalSteps)))
+		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepGenerate+1, totalSteps)))
 		fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
 		return ctx.Err()
 	}
 
-	generatePrompt, err := RenderGenerateTasksPrompt(p.tasksDir)
+	generatePrompt, err := p.phase2Prompt(RenderGenerateTasksPrompt(p.tasksDir))
 	if err != nil {
 		return fmt.Errorf("failed to render Generate tasks prompt: %w", err)
 	}
 
-	fmt.Fprint(p.output, ui.StepNumbered(4, totalSteps, "Generate tasks"))
+	fmt.Fprint(p.output, ui.StepNumbered(stepGenerate+1, totalSteps, planSteps[stepGenerate].Name))
+	p.emit(p.stepEvent(EventStepStart, stepGenerate, 0, nil))
 
 	start = time.Now()
 	if err := p.executor.Run(ctx, p.output, model.Thinking, "-c", generatePrompt); err != nil {
 		elapsed := time.Since(start)
 		fmt.Fprintln(p.output, ui.StepFailed("Step failed", elapsed))
+		p.emit(p.stepEvent(EventStepFailed, stepGenerate, elapsed, err))
 
 		if ctx.Err() != nil {
-			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step 4/%d", totalSteps)))
+			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepGenerate+1, totalSteps)))
 			fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
 			return ctx.Err()
 		}
-		return fmt.Errorf("step 4/%d %q failed: %w", totalSteps, "Generate tasks", err)
+		return fmt.Errorf("step %d/%d %q failed: %w", stepGenerate+1, totalSteps, planSteps[stepGenerate].Name, err)
 	}
 
 	fmt.Fprintln(p.output, ui.StepComplete("Step complete", time.Since(start)))
+	p.emit(p.stepEvent(EventStepDone, stepGenerate, time.Since(start), nil))
+
+	// --- Step 5 (optional): Generate acceptance tests (parallel, one per task) ---
+	if p.withTests {
+		if err := p.generateTests(ctx, totalSteps); err != nil {
+			return err
+		}
+	}
+
+	// The manifest is a convenience for tooling; failing to write it
+	// shouldn't throw away a finished plan.
+	if _, err := os.Stat(p.tasksDir); err == nil {
+		if err := WriteManifest(p.tasksDir); err != nil {
+			fmt.Fprint(p.output, ui.Info(fmt.Sprintf("Could not write %s: %v", ManifestFile, err)))
+		}
+		p.reportValidation()
+		fmt.Fprintln(p.output)
+		ReportScope(p.output, p.tasksDir)
+	}
 
 	fmt.Fprintln(p.output)
 	fmt.Fprintln(p.output, ui.Complete("Planning complete"))
+	p.emit(Event{Type: EventPlanDone})
+
+	return nil
+}
+
+// testsConcurrency caps the acceptance test outlines generated at once.
+const testsConcurrency = 4
+
+// generateTests runs the optional acceptance tests step: one fresh
+// conversation per TASK<N>.md in the tasks directory, in parallel batches.
+func (p *Planner) generateTests(ctx context.Context, totalSteps int) error {
+	if ctx.Err() != nil {
+		fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepTests+1, totalSteps)))
+		fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
+		return ctx.Err()
+	}
+
+	files := readTaskFiles(p.tasksDir)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sortTaskNames(names)
+
+	tasks := make([]parallelTask, 0, len(names))
+	for _, name := range names {
+		prompt, err := p.phase2Prompt(RenderGenerateTestsPrompt(p.tasksDir, name))
+		if err != nil {
+			return fmt.Errorf("failed to render acceptance tests prompt: %w", err)
+		}
+		tasks = append(tasks, parallelTask{name: TestsFileName(name), modelType: model.Thinking, args: []string{prompt}})
+	}
+
+	fmt.Fprint(p.output, ui.StepNumbered(stepTests+1, totalSteps, planSteps[stepTests].Name))
+	p.emit(p.stepEvent(EventStepStart, stepTests, 0, nil))
+
+	if len(tasks) == 0 {
+		fmt.Fprint(p.output, ui.Info("  No task files to write tests for"))
+		p.emit(p.stepEvent(EventStepDone, stepTests, 0, nil))
+		return nil
+	}
 
+	start := time.Now()
+	results := runParallel(ctx, p.executor, tasks, testsConcurrency)
+
+	var errs []string
+	for _, r := range results {
+		sub := p.stepEvent(EventSubstepDone, stepTests, r.elapsed, r.err)
+		sub.Name = r.name
+		if r.err != nil {
+			fmt.Fprintln(p.output, ui.StepFailed(r.name, r.elapsed))
+			sub.Type = EventSubstepFailed
+			errs = append(errs, fmt.Sprintf("%s: %v", r.name, r.err))
+		} else {
+			fmt.Fprintln(p.output, ui.StepComplete(r.name, r.elapsed))
+		}
+		p.emit(sub)
+	}
+
+	if len(errs) > 0 {
+		p.emit(p.stepEvent(EventStepFailed, stepTests, time.Since(start), errors.New(strings.Join(errs, "; "))))
+		if ctx.Err() != nil {
+			fmt.Fprintln(p.output, ui.Interrupted(fmt.Sprintf("Planning aborted at step %d/%d", stepTests+1, totalSteps)))
+			fmt.Fprint(p.output, ui.Info(fmt.Sprintf("  Files written so far are preserved in %s", p.tasksDir)))
+			return ctx.Err()
+		}
+		return fmt.Errorf("step %d/%d failed: %s", stepTests+1, totalSteps, strings.Join(errs, "; "))
+	}
+	p.emit(p.stepEvent(EventStepDone, stepTests, time.Since(start), nil))
 	return nil
 }
+
+// TestsFileName returns the acceptance test outline name for a task file:
+// TASK3.md → TASK3.tests.md.
+func TestsFileName(taskFile string) string {
+	return strings.TrimSuffix(taskFile, ".md") + ".tests.md"
+}
+
+// reportValidation warns about missing or truncated planning documents so
+// they can be fixed before the runner consumes them. It never fails planning.
+func (p *Planner) reportValidation() {
+	warnings, err := Validate(p.tasksDir)
+	if err != nil {
+		fmt.Fprint(p.output, ui.Info(fmt.Sprintf("Could not validate plan: %v", err)))
+		return
+	}
+	if len(warnings) > 0 {
+		fmt.Fprintln(p.output)
+		ReportWarnings(p.output, p.sessionName, warnings)
+	}
+}
+
+// phase2Prompt appends the project guardrails, if any, and the amend
+// instructions, when amending, to a rendered Phase 2 prompt.
+func (p *Planner) phase2Prompt(prompt string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if len(p.guardrails) > 0 {
+		rules, err := RenderGuardrails(p.guardrails)
+		if err != nil {
+			return "", err
+		}
+		prompt += "\n\n" + rules
+	}
+	if !p.amend {
+		return prompt, nil
+	}
+	note, err := RenderAmendNote(p.tasksDir)
+	if err != nil {
+		return "", err
+	}
+	return prompt + "\n\n" + note, nil
+}
+
+// taskFilePattern matches TASK<N>.md file names and captures N.
+var taskFilePattern = regexp.MustCompile(`^TASK(\d+)\.md$`)
+
+// sortTaskNames orders TASK<N>.md names by N.
+func sortTaskNames(names []string) {
+	num := func(name string) int {
+		m := taskFilePattern.FindStringSubmatch(name)
+		if m == nil {
+			return 0
+		}
+		n, _ := strconv.Atoi(m[1]) //nolint:errcheck // pattern guarantees digits
+		return n
+	}
+	sort.Slice(names, func(i, j int) bool { return num(names[i]) < num(names[j]) })
+}
+
+// readTaskFiles returns the contents of the TASK<N>.md files in dir keyed by
+// file name. Unreadable files and directories are skipped.
+func readTaskFiles(dir string) map[string]string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	files := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() || !taskFilePattern.MatchString(e.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		files[e.Name()] = string(data)
+	}
+	return files
+}
+
+// reportTaskChanges prints how an amend changed the task files.
+func reportTaskChanges(w io.Writer, before, after map[string]string) {
+	var unchanged, updated, added, removed []string
+	for name, content := range after {
+		prev, ok := before[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case prev == content:
+			unchanged = append(unchanged, name)
+		default:
+			updated = append(updated, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	fmt.Fprint(w, ui.Info(fmt.Sprintf("Task files: %d unchanged", len(unchanged))))
+	for _, group := range []struct {
+		label string
+		names []string
+	}{{"updated", updated}, {"added", added}, {"removed", removed}} {
+		if len(group.names) == 0 {
+			continue
+		}
+		sortTaskNames(group.names)
+		fmt.Fprint(w, ui.Info(fmt.Sprintf("  %s: %s", group.label, strings.Join(group.names, ", "))))
+	}
+}
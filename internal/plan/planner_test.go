@@ -5,6 +5,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"testing"
@@ -15,6 +18,8 @@ import (
 	"github.com/yarlson/tap"
 
 	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/ui"
+	"github.com/yarlson/snap/internal/workflow"
 )
 
 // mockExecutor records all calls and returns canned responses.
@@ -97,6 +102,39 @@ func TestPlanner_Phase1_UserMessageThenDone(t *testing.T) {
 	assert.Contains(t, output, "snap plan>")
 }
 
+func TestPlanner_Phase1_CustomRequirementsPrompt(t *testing.T) {
+	promptPath := filepath.Join(t.TempDir(), "backend.md")
+	require.NoError(t, os.WriteFile(promptPath, []byte("Gather requirements for a backend service."), 0o600))
+
+	exec := &mockExecutor{}
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&bytes.Buffer{}),
+		WithInput(strings.NewReader("Needs rate limiting\n/done\n")),
+		WithRequirementsPrompt(promptPath),
+	)
+
+	require.NoError(t, p.Run(context.Background()))
+
+	calls := exec.getCalls()
+	require.GreaterOrEqual(t, len(calls), 2)
+	assert.Equal(t, []string{"Gather requirements for a backend service."}, calls[0].args)
+	assert.Equal(t, []string{"-c", "Needs rate limiting"}, calls[1].args)
+}
+
+func TestPlanner_Phase1_CustomRequirementsPromptMissing(t *testing.T) {
+	exec := &mockExecutor{}
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&bytes.Buffer{}),
+		WithInput(strings.NewReader("/done\n")),
+		WithRequirementsPrompt(filepath.Join(t.TempDir(), "missing.md")),
+	)
+
+	err := p.Run(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Empty(t, exec.getCalls())
+}
+
 func TestPlanner_Phase1_DoneImmediately(t *testing.T) {
 	exec := &mockExecutor{}
 	var out bytes.Buffer
@@ -212,6 +250,64 @@ func TestPlanner_Phase2_Pipeline(t *testing.T) {
 	assert.Contains(t, calls[5].args, "-c", "generate tasks should have -c")
 }
 
+// outlineWriter writes the acceptance test outline each tests prompt asks for.
+type outlineWriter struct {
+	mockExecutor
+}
+
+var outlinePathPattern = regexp.MustCompile("Write exactly one file: `([^`]+\\.tests\\.md)`")
+
+func (o *outlineWriter) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	if m := outlinePathPattern.FindStringSubmatch(args[len(args)-1]); m != nil {
+		if err := os.WriteFile(m[1], []byte("# Acceptance tests"), 0o600); err != nil {
+			return err
+		}
+	}
+	return o.mockExecutor.Run(ctx, w, mt, args...)
+}
+
+func TestPlanner_WithTests_WritesOutlinePerTask(t *testing.T) {
+	tasksDir := t.TempDir()
+	for _, name := range []string{"TASK1.md", "TASK2.md", "TASK3.md", "TASKS.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tasksDir, name), []byte("# "+name), 0o600))
+	}
+
+	exec := &outlineWriter{}
+	var out bytes.Buffer
+	p := NewPlanner(exec, "auth", tasksDir,
+		WithOutput(&out),
+		WithBrief("brief.md", "some brief"),
+		WithTests(true),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	matches, err := filepath.Glob(filepath.Join(tasksDir, "*.tests.md"))
+	require.NoError(t, err)
+	assert.Len(t, matches, 3, "one outline per TASK<N>.md")
+	for _, name := range []string{"TASK1.tests.md", "TASK2.tests.md", "TASK3.tests.md"} {
+		assert.FileExists(t, filepath.Join(tasksDir, name))
+	}
+	assert.Len(t, exec.getCalls(), 5+3, "PRD, tech, design, analyze, generate, then one call per task")
+
+	output := out.String()
+	assert.Contains(t, output, "Step 1/5: Generate PRD")
+	assert.Contains(t, output, "Step 5/5: Generate acceptance tests")
+	assert.Contains(t, output, "TASK2.tests.md")
+}
+
+func TestPlanner_WithoutTests_NoOutlines(t *testing.T) {
+	tasksDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	exec := &outlineWriter{}
+	var out bytes.Buffer
+	p := NewPlanner(exec, "auth", tasksDir, WithOutput(&out), WithBrief("brief.md", "some brief"))
+	require.NoError(t, p.Run(context.Background()))
+
+	assert.NoFileExists(t, filepath.Join(tasksDir, "TASK1.tests.md"))
+	assert.Contains(t, out.String(), "Step 4/4: Generate tasks")
+}
+
 func TestPlanner_Phase2_StepHeaders(t *testing.T) {
 	exec := &mockExecutor{}
 	var out bytes.Buffer
@@ -541,6 +637,50 @@ func TestPlanner_WithBrief_SkipsPhase1(t *testing.T) {
 	assert.Contains(t, output, "Planning complete")
 }
 
+func TestPlanner_WithBriefs_ConcatenatesLabeledSections(t *testing.T) {
+	exec := &mockExecutor{}
+	var out bytes.Buffer
+
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&out),
+		WithBriefs([]Brief{
+			{Name: "brief.md", Content: "I want OAuth2 with Google\n"},
+			{Name: "api.yaml", Content: "openapi: 3.0.0"},
+		}),
+	)
+
+	err := p.Run(context.Background())
+	require.NoError(t, err)
+
+	calls := exec.getCalls()
+	assert.Equal(t, 5, len(calls))
+
+	prdPrompt := calls[0].args[len(calls[0].args)-1]
+	assert.Contains(t, prdPrompt, "#### Source: brief.md\n\nI want OAuth2 with Google")
+	assert.Contains(t, prdPrompt, "#### Source: api.yaml\n\nopenapi: 3.0.0")
+	assert.Less(t, strings.Index(prdPrompt, "brief.md"), strings.Index(prdPrompt, "api.yaml"))
+
+	assert.Contains(t, out.String(), "using brief.md, api.yaml as input")
+}
+
+func TestPlanner_WithBriefs_SingleMatchesWithBrief(t *testing.T) {
+	exec := &mockExecutor{}
+	var out bytes.Buffer
+
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&out),
+		WithBriefs([]Brief{{Name: "brief.md", Content: "some brief"}}),
+	)
+
+	err := p.Run(context.Background())
+	require.NoError(t, err)
+
+	prdPrompt := exec.getCalls()[0].args[len(exec.getCalls()[0].args)-1]
+	assert.Contains(t, prdPrompt, "some brief")
+	assert.NotContains(t, prdPrompt, "#### Source:")
+	assert.Contains(t, out.String(), "using brief.md as input")
+}
+
 func TestPlanner_WithBrief_NoPhase1Output(t *testing.T) {
 	exec := &mockExecutor{}
 	var out bytes.Buffer
@@ -571,6 +711,7 @@ func TestPlanner_ExecutorError(t *testing.T) {
 	err := p.Run(context.Background())
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "provider failed")
+	assert.ErrorIs(t, err, workflow.ErrProvider)
 }
 
 // --- Resume tests ---
@@ -619,6 +760,94 @@ func TestPlanner_WithoutResume_FirstCallNoCFlag(t *testing.T) {
 	assert.NotContains(t, output, "Resuming planning")
 }
 
+// taskWritingExecutor writes task files when it receives the Generate tasks prompt.
+type taskWritingExecutor struct {
+	mockExecutor
+	dir   string
+	files map[string]string
+}
+
+func (e *taskWritingExecutor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	if strings.Contains(args[len(args)-1], "Write TASKS.md") {
+		for name, content := range e.files {
+			if err := os.WriteFile(filepath.Join(e.dir, name), []byte(content), 0o600); err != nil {
+				return err
+			}
+		}
+	}
+	return e.mockExecutor.Run(ctx, w, mt, args...)
+}
+
+func TestPlanner_WithAmend(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TASK1.md"), []byte("# Task 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TASK2.md"), []byte("# Task 2\n"), 0o600))
+
+	exec := &taskWritingExecutor{dir: dir, files: map[string]string{
+		"TASK2.md": "# Task 2 (with rate limits)\n",
+		"TASK3.md": "# Task 3\n",
+	}}
+	var out bytes.Buffer
+
+	p := NewPlanner(exec, "auth", dir,
+		WithOutput(&out),
+		WithInput(strings.NewReader("also add rate limiting\n/done\n")),
+		WithAmend(true),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	calls := exec.getCalls()
+	require.GreaterOrEqual(t, len(calls), 7)
+	assert.Equal(t, "-c", calls[0].args[0], "amend continues the previous conversation")
+	assert.Contains(t, calls[0].args[1], "Gather additional requirements for the existing plan")
+
+	// Every Phase 2 prompt carries the amend instructions.
+	for _, c := range calls[2:] {
+		assert.Contains(t, c.args[len(c.args)-1], "Amending an Existing Plan")
+	}
+
+	output := ui.StripColors(out.String())
+	assert.Contains(t, output, "Amending plan for session 'auth'")
+	assert.Contains(t, output, "Task files: 1 unchanged")
+	assert.Contains(t, output, "updated: TASK2.md")
+	assert.Contains(t, output, "added: TASK3.md")
+	assert.NotContains(t, output, "removed:")
+}
+
+func TestPlanner_WithoutAmend_NoAmendNote(t *testing.T) {
+	exec := &mockExecutor{}
+
+	p := NewPlanner(exec, "auth", t.TempDir(),
+		WithOutput(io.Discard),
+		WithInput(strings.NewReader("/done\n")),
+		WithResume(true),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	for _, c := range exec.getCalls() {
+		assert.NotContains(t, c.args[len(c.args)-1], "Amending an Existing Plan")
+	}
+}
+
+func TestPlanner_WithGuardrails(t *testing.T) {
+	exec := &mockExecutor{}
+
+	p := NewPlanner(exec, "auth", t.TempDir(),
+		WithOutput(io.Discard),
+		WithInput(strings.NewReader("/done\n")),
+		WithGuardrails([]string{"No new dependencies without an ADR"}),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	calls := exec.getCalls()
+	require.Greater(t, len(calls), 1)
+	assert.NotContains(t, calls[0].args[len(calls[0].args)-1], "Project Rules", "Phase 1 gathers requirements only")
+	for _, c := range calls[1:] {
+		assert.Contains(t, c.args[len(c.args)-1], "## Project Rules")
+		assert.Contains(t, c.args[len(c.args)-1], "- No new dependencies without an ADR")
+	}
+}
+
 // --- AfterFirstMessage callback tests ---
 
 func TestPlanner_AfterFirstMessage_CalledOnSuccess(t *testing.T) {
@@ -1032,3 +1261,54 @@ func TestPlanner_Interactive_WithResume(t *testing.T) {
 	output := buf.String()
 	assert.Contains(t, output, "Resuming planning")
 }
+
+func TestPlanner_Phase1_MaxTurnsAutoAdvances(t *testing.T) {
+	exec := &mockExecutor{}
+	var out bytes.Buffer
+
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&out),
+		WithInput(strings.NewReader("first\nsecond\nthird\n/done\n")),
+		WithMaxTurns(2),
+	)
+
+	err := p.Run(context.Background())
+	require.NoError(t, err)
+
+	calls := exec.getCalls()
+	// 1 (requirements prompt) + 2 (capped user messages) + 5 (generation) = 8
+	require.Equal(t, 8, len(calls))
+	assert.Equal(t, "first", calls[1].args[len(calls[1].args)-1])
+	assert.Equal(t, "second", calls[2].args[len(calls[2].args)-1])
+	for _, c := range calls {
+		assert.NotEqual(t, "third", c.args[len(c.args)-1])
+	}
+
+	output := out.String()
+	assert.Contains(t, output, "Reached the 2-message limit")
+	assert.Contains(t, output, "Planning complete")
+}
+
+func TestPlanner_Interactive_RequirementTimeout(t *testing.T) {
+	in := tap.NewMockReadable()
+	out := tap.NewMockWritable()
+	tap.SetTermIO(in, out)
+	defer tap.SetTermIO(nil, nil)
+
+	exec := &mockExecutor{}
+	var buf bytes.Buffer
+
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&buf),
+		WithInteractive(true),
+		WithRequirementTimeout(200*time.Millisecond),
+	)
+
+	err := p.Run(context.Background())
+	require.ErrorIs(t, err, ErrRequirementTimeout)
+
+	// Only the requirements prompt ran; Phase 2 never started.
+	assert.Len(t, exec.getCalls(), 1)
+	assert.Contains(t, buf.String(), "requirements gathering aborted")
+	assert.NotContains(t, buf.String(), "Generating planning documents")
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"os"
 	"strings"
 	"text/template"
+
+	"github.com/yarlson/snap/internal/pathutil"
 )
 
 //go:embed prompts/*.md
@@ -13,8 +16,11 @@ var promptFS embed.FS
 
 // promptData holds template parameters for plan prompt rendering.
 type promptData struct {
-	TasksDir string
-	Brief    string
+	TasksDir   string
+	Brief      string
+	Guardrails []string
+	TaskFile   string // TASK<N>.md, for per-task prompts
+	TestsFile  string // TASK<N>.tests.md, for per-task prompts
 }
 
 // RenderPrinciplesPreamble renders the shared engineering principles preamble.
@@ -36,6 +42,43 @@ func RenderRequirementsPrompt() (string, error) {
 	return renderTemplate("prompts/requirements.md", promptData{})
 }
 
+// LoadRequirementsPrompt reads a custom Phase 1 prompt from path, for teams
+// that want requirements gathering tailored to their domain. The file must be
+// readable and not blank. $VAR and ${VAR} in path are expanded.
+func LoadRequirementsPrompt(path string) (string, error) {
+	path, err := pathutil.ExpandEnv(path)
+	if err != nil {
+		return "", fmt.Errorf("requirements prompt: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read requirements prompt: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("requirements prompt %s is empty", path)
+	}
+	return string(data), nil
+}
+
+// RenderAmendPrompt returns the Phase 1 prompt for adding requirements to an
+// existing plan in tasksDir.
+func RenderAmendPrompt(tasksDir string) (string, error) {
+	return renderTemplate("prompts/amend.md", promptData{TasksDir: tasksDir})
+}
+
+// RenderAmendNote returns the instructions appended to each Phase 2 prompt
+// when amending, so documents are updated in place and unchanged task files
+// are preserved.
+func RenderAmendNote(tasksDir string) (string, error) {
+	return renderTemplate("prompts/amend-note.md", promptData{TasksDir: tasksDir})
+}
+
+// RenderGuardrails returns the project rules appended to each Phase 2 prompt
+// when the planner has extra guardrails.
+func RenderGuardrails(rules []string) (string, error) {
+	return renderTemplate("prompts/guardrails.md", promptData{Guardrails: rules})
+}
+
 // RenderPRDPrompt renders the PRD generation prompt with the given tasks directory and optional brief.
 func RenderPRDPrompt(tasksDir, brief string) (string, error) {
 	prompt, err := renderTemplate("prompts/prd.md", promptData{TasksDir: tasksDir, Brief: brief})
@@ -81,6 +124,20 @@ func RenderGenerateTasksPrompt(tasksDir string) (string, error) {
 	return prependPreamble(prompt)
 }
 
+// RenderGenerateTestsPrompt renders the acceptance test outline prompt for
+// one task file in tasksDir.
+func RenderGenerateTestsPrompt(tasksDir, taskFile string) (string, error) {
+	prompt, err := renderTemplate("prompts/generate-tests.md", promptData{
+		TasksDir:  tasksDir,
+		TaskFile:  taskFile,
+		TestsFile: TestsFileName(taskFile),
+	})
+	if err != nil {
+		return "", err
+	}
+	return prependPreamble(prompt)
+}
+
 func renderTemplate(name string, data promptData) (string, error) {
 	content, err := promptFS.ReadFile(name)
 	if err != nil {
@@ -1,6 +1,8 @@
 package plan
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -33,6 +35,37 @@ func TestRenderRequirementsPrompt(t *testing.T) {
 	assert.Contains(t, prompt, "out-of-scope")
 }
 
+func TestLoadRequirementsPrompt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mobile.md")
+	require.NoError(t, os.WriteFile(path, []byte("Ask about iOS and Android targets.\n"), 0o600))
+
+	prompt, err := LoadRequirementsPrompt(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Ask about iOS and Android targets.\n", prompt)
+
+	blank := filepath.Join(dir, "blank.md")
+	require.NoError(t, os.WriteFile(blank, []byte(" \n\n"), 0o600))
+	_, err = LoadRequirementsPrompt(blank)
+	assert.ErrorContains(t, err, "is empty")
+
+	_, err = LoadRequirementsPrompt(filepath.Join(dir, "missing.md"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadRequirementsPrompt_ExpandsEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mobile.md"), []byte("Ask about targets.\n"), 0o600))
+	t.Setenv("SNAP_PROMPTS", dir)
+
+	prompt, err := LoadRequirementsPrompt("${SNAP_PROMPTS}/mobile.md")
+	require.NoError(t, err)
+	assert.Equal(t, "Ask about targets.\n", prompt)
+
+	_, err = LoadRequirementsPrompt("$SNAP_TEST_UNSET/mobile.md")
+	assert.ErrorContains(t, err, "environment variable SNAP_TEST_UNSET is not set")
+}
+
 func TestRenderPRDPrompt_WithoutBrief(t *testing.T) {
 	result, err := RenderPRDPrompt(".snap/sessions/auth/tasks", "")
 	require.NoError(t, err)
@@ -0,0 +1,112 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// sizeOrder is the display order of the task sizes the planning prompts ask for.
+var sizeOrder = []string{"S", "M", "L"}
+
+// EpicCount is the number of tasks in one epic.
+type EpicCount struct {
+	Epic  string
+	Tasks int
+}
+
+// Scope summarizes the size of a plan's task list.
+type Scope struct {
+	Tasks   int
+	Sizes   map[string]int // Keyed by S, M or L
+	Unsized int            // Tasks with a missing or unrecognized size
+	Epics   []EpicCount    // In order of first appearance
+}
+
+// SummarizeScope counts tasks per size and per epic. Sizes are matched
+// case-insensitively and may be spelled out ("Medium").
+func SummarizeScope(specs []TaskSpec) Scope {
+	scope := Scope{Tasks: len(specs), Sizes: make(map[string]int)}
+	epicIndex := make(map[string]int)
+	for _, spec := range specs {
+		if size := normalizeSize(spec.Size); size != "" {
+			scope.Sizes[size]++
+		} else {
+			scope.Unsized++
+		}
+
+		if spec.Epic == "" {
+			continue
+		}
+		i, ok := epicIndex[spec.Epic]
+		if !ok {
+			i = len(scope.Epics)
+			epicIndex[spec.Epic] = i
+			scope.Epics = append(scope.Epics, EpicCount{Epic: spec.Epic})
+		}
+		scope.Epics[i].Tasks++
+	}
+	return scope
+}
+
+// String renders the scope headline, e.g. "12 tasks: 5 S, 5 M, 2 L".
+func (s Scope) String() string {
+	var parts []string
+	for _, size := range sizeOrder {
+		if n := s.Sizes[size]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, size))
+		}
+	}
+	if s.Unsized > 0 {
+		parts = append(parts, fmt.Sprintf("%d unsized", s.Unsized))
+	}
+	headline := pluralTasks(s.Tasks)
+	if len(parts) > 0 {
+		headline += ": " + strings.Join(parts, ", ")
+	}
+	return headline
+}
+
+// ReportScope writes the plan scope read from TASKS.md in tasksDir: the size
+// breakdown followed by one line per epic. Nothing is written when TASKS.md is
+// missing or lists no tasks.
+func ReportScope(w io.Writer, tasksDir string) {
+	data, err := os.ReadFile(filepath.Join(tasksDir, "TASKS.md"))
+	if err != nil {
+		return
+	}
+	specs := ExtractTaskSpecs(string(data))
+	if len(specs) == 0 {
+		return
+	}
+
+	scope := SummarizeScope(specs)
+	fmt.Fprint(w, ui.Info("Plan scope: "+scope.String()))
+	for _, e := range scope.Epics {
+		fmt.Fprint(w, ui.Info(fmt.Sprintf("  %s: %s", e.Epic, pluralTasks(e.Tasks))))
+	}
+}
+
+// normalizeSize maps a size cell to S, M or L, or "" when unrecognized.
+func normalizeSize(size string) string {
+	switch strings.ToUpper(strings.Trim(strings.TrimSpace(size), "*`")) {
+	case "S", "SMALL":
+		return "S"
+	case "M", "MEDIUM":
+		return "M"
+	case "L", "LARGE":
+		return "L"
+	}
+	return ""
+}
+
+func pluralTasks(n int) string {
+	if n == 1 {
+		return "1 task"
+	}
+	return fmt.Sprintf("%d tasks", n)
+}
@@ -0,0 +1,75 @@
+package plan
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+func TestSummarizeScope(t *testing.T) {
+	scope := SummarizeScope(ExtractTaskSpecs(sampleTasksMD))
+
+	assert.Equal(t, 3, scope.Tasks)
+	assert.Equal(t, map[string]int{"S": 2, "M": 1}, scope.Sizes)
+	assert.Zero(t, scope.Unsized)
+	assert.Equal(t, []EpicCount{{Epic: "E1", Tasks: 1}, {Epic: "E2", Tasks: 2}}, scope.Epics)
+	assert.Equal(t, "3 tasks: 2 S, 1 M", scope.String())
+}
+
+func TestSummarizeScope_NormalizesSizes(t *testing.T) {
+	scope := SummarizeScope([]TaskSpec{
+		{ID: "TASK1", Size: "Large"},
+		{ID: "TASK2", Size: " m "},
+		{ID: "TASK3", Size: "**L**"},
+		{ID: "TASK4", Size: "XL"},
+		{ID: "TASK5"},
+	})
+
+	assert.Equal(t, map[string]int{"M": 1, "L": 2}, scope.Sizes)
+	assert.Equal(t, 2, scope.Unsized)
+	assert.Empty(t, scope.Epics)
+	assert.Equal(t, "5 tasks: 1 M, 2 L, 2 unsized", scope.String())
+	assert.Equal(t, "1 task: 1 unsized", SummarizeScope([]TaskSpec{{ID: "TASK1"}}).String())
+}
+
+func TestReportScope(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TASKS.md"), []byte(sampleTasksMD), 0o600))
+
+	var buf bytes.Buffer
+	ReportScope(&buf, dir)
+
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "Plan scope: 3 tasks: 2 S, 1 M")
+	assert.Contains(t, output, "E1: 1 task\n")
+	assert.Contains(t, output, "E2: 2 tasks\n")
+}
+
+func TestReportScope_PromptFormat(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TASKS.md"), []byte(promptFormatTasksMD), 0o600))
+
+	var buf bytes.Buffer
+	ReportScope(&buf, dir)
+
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "Plan scope: 3 tasks: 2 S, 1 M")
+	assert.Contains(t, output, "Epic 1 — Enhancement Wave: 2 tasks\n")
+}
+
+func TestReportScope_NoTasks(t *testing.T) {
+	var buf bytes.Buffer
+	ReportScope(&buf, t.TempDir())
+	assert.Empty(t, buf.String(), "missing TASKS.md")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TASKS.md"), []byte("# Tasks\n"), 0o600))
+	ReportScope(&buf, dir)
+	assert.Empty(t, buf.String(), "no task rows")
+}
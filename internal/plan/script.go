@@ -0,0 +1,52 @@
+package plan
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Script markers. Each must appear alone on its line; surrounding whitespace
+// is ignored and ScriptDone is case-insensitive, like /done in chat input.
+const (
+	// ScriptTurnSeparator ends one scripted message and starts the next.
+	ScriptTurnSeparator = "---"
+	// ScriptDone ends the script; anything after it is ignored.
+	ScriptDone = "/done"
+)
+
+// ParseScript reads a requirements script: the messages of a Phase 1
+// conversation separated by "---" lines, ending at "/done" or EOF. Unlike chat
+// input, a message may span several lines. Messages are trimmed and empty
+// ones are dropped, so the result is exactly the messages the planner sends.
+func ParseScript(r io.Reader) ([]string, error) {
+	var turns []string
+	var current []string
+	flush := func() {
+		if turn := strings.TrimSpace(strings.Join(current, "\n")); turn != "" {
+			turns = append(turns, turn)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch marker := strings.TrimSpace(line); {
+		case marker == ScriptTurnSeparator:
+			flush()
+		case strings.EqualFold(marker, ScriptDone):
+			flush()
+			return turns, nil
+		default:
+			current = append(current, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read requirements script: %w", err)
+	}
+	flush()
+	return turns, nil
+}
@@ -0,0 +1,89 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "multi-line turns",
+			script: "I want auth\nwith JWT sessions\n---\nUsers are admins\n/done\n",
+			want:   []string{"I want auth\nwith JWT sessions", "Users are admins"},
+		},
+		{
+			name:   "ends at EOF without /done",
+			script: "one\n---\ntwo",
+			want:   []string{"one", "two"},
+		},
+		{
+			name:   "ignores text after /done",
+			script: "one\n  /DONE  \n---\ntwo\n",
+			want:   []string{"one"},
+		},
+		{
+			name:   "drops empty turns and trims",
+			script: "\n---\n\n  keep blank lines\n\n  inside  \n\n---\n---\r\n",
+			want:   []string{"keep blank lines\n\n  inside"},
+		},
+		{
+			name:   "empty script",
+			script: "/done\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			turns, err := ParseScript(strings.NewReader(tt.script))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, turns)
+		})
+	}
+}
+
+func TestPlanner_WithScript_SendsEachTurn(t *testing.T) {
+	exec := &mockExecutor{}
+	var out bytes.Buffer
+
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&out),
+		WithInput(strings.NewReader("ignored\n/done\n")),
+		WithScript([]string{"I want auth\nwith JWT sessions", "Users are admins"}),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	calls := exec.getCalls()
+	// 1 (requirements prompt) + 2 (scripted turns) + 5 (generation) = 8
+	require.Len(t, calls, 8)
+	assert.NotContains(t, calls[0].args, "-c")
+	assert.Equal(t, []string{"-c", "I want auth\nwith JWT sessions"}, calls[1].args)
+	assert.Equal(t, []string{"-c", "Users are admins"}, calls[2].args)
+
+	output := out.String()
+	assert.Contains(t, output, "2 scripted message(s)")
+	assert.Contains(t, output, "snap plan> Users are admins")
+}
+
+func TestPlanner_WithScript_RespectsMaxTurns(t *testing.T) {
+	exec := &mockExecutor{}
+
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&bytes.Buffer{}),
+		WithScript([]string{"one", "two", "three"}),
+		WithMaxTurns(1),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	// 1 (requirements prompt) + 1 (capped turn) + 5 (generation) = 7
+	assert.Len(t, exec.getCalls(), 7)
+}
@@ -0,0 +1,59 @@
+package plan
+
+// StepInfo describes a single Phase 2 document generation step.
+type StepInfo struct {
+	Name string
+	// Parallel is true when the step fans out into concurrent sub-tasks.
+	Parallel bool
+	// ContinuesConversation is true when the step resumes the previous
+	// conversation (-c) instead of starting a fresh one.
+	ContinuesConversation bool
+	// Optional is true when the step only runs when enabled (WithTests).
+	Optional bool
+}
+
+// Indices into planSteps, in execution order.
+const (
+	stepPRD = iota
+	stepTechDesign
+	stepAnalyze
+	stepGenerate
+	stepTests
+)
+
+// planSteps drives the step numbering and labels rendered by generateDocuments.
+var planSteps = [...]StepInfo{
+	// Continues the Phase 1 conversation; starts fresh when a brief is used.
+	stepPRD:        {Name: "Generate PRD", ContinuesConversation: true},
+	stepTechDesign: {Name: "Generate technology plan + design spec", Parallel: true},
+	stepAnalyze:    {Name: "Analyze tasks"},
+	stepGenerate:   {Name: "Generate tasks", ContinuesConversation: true},
+	stepTests:      {Name: "Generate acceptance tests", Parallel: true, Optional: true},
+}
+
+// Steps returns the Phase 2 planning steps in execution order, including
+// optional ones.
+func Steps() []StepInfo {
+	steps := make([]StepInfo, len(planSteps))
+	copy(steps, planSteps[:])
+	return steps
+}
+
+// StepCount returns the number of Phase 2 planning steps that always run.
+func StepCount() int {
+	n := 0
+	for _, s := range planSteps {
+		if !s.Optional {
+			n++
+		}
+	}
+	return n
+}
+
+// stepCount returns the number of Phase 2 steps this planner runs.
+func (p *Planner) stepCount() int {
+	if p.withTests {
+		return StepCount() + 1
+	}
+	return StepCount()
+}
@@ -0,0 +1,48 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSteps_MatchesStepCount(t *testing.T) {
+	steps := Steps()
+	require.Len(t, steps, StepCount()+1, "one optional step")
+	assert.Equal(t, "Generate PRD", steps[0].Name)
+	assert.True(t, steps[1].Parallel)
+	assert.False(t, steps[2].ContinuesConversation)
+	assert.True(t, steps[3].ContinuesConversation)
+	assert.True(t, steps[4].Optional)
+	assert.True(t, steps[4].Parallel)
+}
+
+func TestSteps_ReturnsCopy(t *testing.T) {
+	steps := Steps()
+	steps[0].Name = "changed"
+	assert.Equal(t, "Generate PRD", Steps()[0].Name)
+}
+
+func TestSteps_DriveGenerateDocumentsOutput(t *testing.T) {
+	exec := &mockExecutor{}
+	var out bytes.Buffer
+
+	p := NewPlanner(exec, "auth", ".snap/sessions/auth/tasks",
+		WithOutput(&out),
+		WithBrief("brief.md", "some brief"),
+	)
+	require.NoError(t, p.Run(context.Background()))
+
+	output := out.String()
+	for i, s := range Steps() {
+		if s.Optional {
+			assert.NotContains(t, output, s.Name)
+			continue
+		}
+		assert.Contains(t, output, fmt.Sprintf("Step %d/%d: %s", i+1, StepCount(), s.Name))
+	}
+}
@@ -0,0 +1,171 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// Warning is a problem found in a session's planning documents.
+type Warning struct {
+	File    string // Relative to the tasks directory
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.File + ": " + w.Message
+}
+
+// requiredHeading is a section a planning document must contain, matched
+// against its markdown headings.
+type requiredHeading struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// headingPattern matches a markdown heading and captures its text.
+var headingPattern = regexp.MustCompile(`^#{1,6}\s+(.+)$`)
+
+// requiredHeadings lists the sections each document's prompt asks for that
+// the runner and later planning steps rely on. Patterns are case-insensitive
+// and tolerate numbering ("## 3. Goals").
+var requiredHeadings = map[string][]requiredHeading{
+	"PRD.md": {
+		// Excludes "Non-goals", which the PRD also has.
+		{name: "Goals", pattern: regexp.MustCompile(`(?i)(^|[^\w-])goals?\b`)},
+		{name: "Requirements", pattern: regexp.MustCompile(`(?i)\brequirements?\b`)},
+	},
+	"TECHNOLOGY.md": {
+		{name: "Architecture", pattern: regexp.MustCompile(`(?i)\barchitecture\b`)},
+		{name: "Testing strategy", pattern: regexp.MustCompile(`(?i)\btesting\b`)},
+	},
+	"DESIGN.md": {
+		{name: "Voice & tone", pattern: regexp.MustCompile(`(?i)\bvoice\b`)},
+		{name: "Terminology", pattern: regexp.MustCompile(`(?i)\bterminology\b`)},
+	},
+}
+
+// requiredTaskSections are the TASKS.md sections the runner consumes: the
+// task list (G) and the dependency graph (H).
+var requiredTaskSections = []struct{ letter, name string }{
+	{"G", "Task List"},
+	{"H", "Dependency Graph"},
+}
+
+// Validate checks the planning documents in tasksDir for truncated or
+// incomplete output: PRD.md, TECHNOLOGY.md, DESIGN.md and TASKS.md must exist
+// and contain their key sections, and every task file listed in TASKS.md must
+// exist. It returns one warning per problem, in document order.
+func Validate(tasksDir string) ([]Warning, error) {
+	var warnings []Warning
+
+	for _, name := range []string{"PRD.md", "TECHNOLOGY.md", "DESIGN.md"} {
+		content, ok, err := readDocument(tasksDir, name, &warnings)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		headings := markdownHeadings(content)
+		for _, req := range requiredHeadings[name] {
+			if !anyMatch(req.pattern, headings) {
+				warnings = append(warnings, Warning{File: name, Message: fmt.Sprintf("missing %q section", req.name)})
+			}
+		}
+	}
+
+	content, ok, err := readDocument(tasksDir, "TASKS.md", &warnings)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return warnings, nil
+	}
+
+	letters := sectionLetters(content)
+	for _, s := range requiredTaskSections {
+		if !letters[s.letter] {
+			warnings = append(warnings, Warning{File: "TASKS.md", Message: fmt.Sprintf("missing section %s (%s)", s.letter, s.name)})
+		}
+	}
+
+	specs := ExtractTaskSpecs(content)
+	if letters["G"] && len(specs) == 0 {
+		warnings = append(warnings, Warning{File: "TASKS.md", Message: "task list in section G names no TASK<N>.md files"})
+	}
+	for _, spec := range specs {
+		if _, err := os.Stat(filepath.Join(tasksDir, spec.File)); errors.Is(err, os.ErrNotExist) {
+			warnings = append(warnings, Warning{File: spec.File, Message: "listed in TASKS.md but missing"})
+		}
+	}
+
+	return warnings, nil
+}
+
+// ReportWarnings writes validation warnings for a session to w, followed by
+// how to fix and re-check the plan.
+func ReportWarnings(w io.Writer, sessionName string, warnings []Warning) {
+	details := make([]string, len(warnings))
+	for i, warning := range warnings {
+		details[i] = warning.String()
+	}
+	fmt.Fprintln(w, ui.ErrorWithDetails(fmt.Sprintf("Plan validation found %d issue(s)", len(warnings)), details))
+	fmt.Fprint(w, ui.Info(fmt.Sprintf("  Edit the files or amend the plan (snap plan --amend %s), then check again: snap plan --validate %s",
+		sessionName, sessionName)))
+}
+
+// readDocument reads a planning document, recording a warning and returning
+// false when it is missing or empty.
+func readDocument(tasksDir, name string, warnings *[]Warning) (string, bool, error) {
+	data, err := os.ReadFile(filepath.Join(tasksDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		*warnings = append(*warnings, Warning{File: name, Message: "missing"})
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("read %s: %w", name, err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		*warnings = append(*warnings, Warning{File: name, Message: "empty"})
+		return "", false, nil
+	}
+	return string(data), true, nil
+}
+
+// markdownHeadings returns the text of every heading in md.
+func markdownHeadings(md string) []string {
+	var headings []string
+	for _, line := range strings.Split(md, "\n") {
+		if m := headingPattern.FindStringSubmatch(strings.TrimRight(line, "\r")); m != nil {
+			headings = append(headings, m[1])
+		}
+	}
+	return headings
+}
+
+// sectionLetters returns the letters of the lettered section headings in TASKS.md.
+func sectionLetters(md string) map[string]bool {
+	letters := make(map[string]bool)
+	for _, line := range strings.Split(md, "\n") {
+		if m := sectionHeadingPattern.FindStringSubmatch(line); m != nil {
+			letters[m[1]] = true
+		}
+	}
+	return letters
+}
+
+func anyMatch(re *regexp.Regexp, items []string) bool {
+	for _, s := range items {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// writeCompletePlan writes planning documents that pass validation.
+func writeCompletePlan(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		"PRD.md":        "# PRD\n\n## 3. Goals\n\n- Ship\n\n## 4. Non-goals\n\n## 7. Requirements\n\n- R1\n",
+		"TECHNOLOGY.md": "# Technology\n\n## Architecture / modules\n\n## Testing strategy\n",
+		"DESIGN.md":     "# Design\n\n## Voice & tone\n\n## User-facing terminology\n",
+		"TASKS.md":      sampleTasksMD,
+		"TASK0.md":      "# Task 0\n",
+		"TASK1.md":      "# Task 1\n",
+		"TASK2.md":      "# Task 2\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+}
+
+func TestValidate_CompletePlan(t *testing.T) {
+	dir := t.TempDir()
+	writeCompletePlan(t, dir)
+
+	warnings, err := Validate(dir)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestValidate_PromptFormatTaskList(t *testing.T) {
+	dir := t.TempDir()
+	writeCompletePlan(t, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TASKS.md"), []byte(promptFormatTasksMD), 0o600))
+
+	warnings, err := Validate(dir)
+	require.NoError(t, err)
+	assert.Empty(t, warnings, "the numbered list the prompt asks for is a valid task list")
+}
+
+func TestValidate_ReportsProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, dir string)
+		want   []Warning
+	}{
+		{
+			name: "missing document",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.Remove(filepath.Join(dir, "TECHNOLOGY.md")))
+			},
+			want: []Warning{{File: "TECHNOLOGY.md", Message: "missing"}},
+		},
+		{
+			name: "empty document",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "DESIGN.md"), []byte("\n  \n"), 0o600))
+			},
+			want: []Warning{{File: "DESIGN.md", Message: "empty"}},
+		},
+		{
+			name: "only non-goals",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "PRD.md"), []byte("# PRD\n\n## Non-goals\n\n## Requirements\n"), 0o600))
+			},
+			want: []Warning{{File: "PRD.md", Message: `missing "Goals" section`}},
+		},
+		{
+			name: "truncated task list",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "TASKS.md"), []byte("# Tasks\n\n## G. Task List\n\n"), 0o600))
+			},
+			want: []Warning{
+				{File: "TASKS.md", Message: "missing section H (Dependency Graph)"},
+				{File: "TASKS.md", Message: "task list in section G names no TASK<N>.md files"},
+			},
+		},
+		{
+			name: "listed task file missing",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.Remove(filepath.Join(dir, "TASK2.md")))
+			},
+			want: []Warning{{File: "TASK2.md", Message: "listed in TASKS.md but missing"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeCompletePlan(t, dir)
+			tt.modify(t, dir)
+
+			warnings, err := Validate(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, warnings)
+		})
+	}
+}
+
+func TestReportWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	ReportWarnings(&buf, "auth", []Warning{{File: "PRD.md", Message: "missing"}})
+
+	out := ui.StripColors(buf.String())
+	assert.Contains(t, out, "Plan validation found 1 issue(s)")
+	assert.Contains(t, out, "PRD.md: missing")
+	assert.Contains(t, out, "snap plan --validate auth")
+}
+
+func TestPlanner_ReportsValidationWarnings(t *testing.T) {
+	dir := t.TempDir()
+	exec := &taskWritingExecutor{dir: dir, files: map[string]string{
+		"TASKS.md": sampleTasksMD,
+		"TASK0.md": "# Task 0\n",
+	}}
+	var out bytes.Buffer
+
+	p := NewPlanner(exec, "auth", dir,
+		WithOutput(&out),
+		WithInput(strings.NewReader("/done\n")),
+	)
+	require.NoError(t, p.Run(context.Background()), "validation warnings don't fail planning")
+
+	output := ui.StripColors(out.String())
+	assert.Contains(t, output, "PRD.md: missing")
+	assert.Contains(t, output, "TASK1.md: listed in TASKS.md but missing")
+	assert.Contains(t, output, "Planning complete")
+}
@@ -1,31 +1,32 @@
 package postrun
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
-	"os/exec"
+	"os"
 	"strings"
+
+	"github.com/yarlson/snap/internal/vcs"
 )
 
-// DetectRemote returns the URL for the "origin" remote.
-// Returns empty string and nil error if no remote named "origin" exists.
-func DetectRemote() (string, error) {
-	ctx := context.Background()
-	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		stderrStr := stderr.String()
-		// No remote named "origin" or not in a git repo — not an error
+// DefaultRemote is the remote pushed to unless another is configured.
+const DefaultRemote = "origin"
+
+// DetectRemote returns the URL for the named remote.
+// Returns empty string and nil error if no such remote exists.
+func DetectRemote(git vcs.Runner, name string) (string, error) {
+	out, err := git.Output(context.Background(), "remote", "get-url", name)
+	if err != nil {
+		stderrStr := vcs.Stderr(err)
+		// No such remote or not in a git repo — not an error
 		if strings.Contains(stderrStr, "No such remote") || strings.Contains(stderrStr, "not a git repository") {
 			return "", nil
 		}
 		return "", err
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	return strings.TrimSpace(out), nil
 }
 
 // IsGitHubRemote returns true if the remote URL points to github.com.
@@ -56,17 +57,75 @@ func IsGitHubRemote(remoteURL string) bool {
 	return host == "github.com"
 }
 
-// Push pushes the current branch to origin. Never uses --force.
-func Push(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "git", "push", "origin", "HEAD")
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return &PushError{Stderr: strings.TrimSpace(stderr.String()), Err: err}
+// GitHubRepo returns the "owner/repo" a GitHub remote URL points to.
+// Returns false for non-GitHub URLs and URLs without an owner and name.
+func GitHubRepo(remoteURL string) (string, bool) {
+	if !IsGitHubRemote(remoteURL) {
+		return "", false
+	}
+	var path string
+	if strings.HasPrefix(remoteURL, "git@") {
+		_, path, _ = strings.Cut(remoteURL, ":")
+	} else {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", false
+		}
+		path = u.Path
+	}
+	owner, name, ok := strings.Cut(strings.TrimSuffix(strings.Trim(path, "/"), ".git"), "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return owner + "/" + name, true
+}
+
+// Push pushes the current branch to remote. Never uses --force.
+func Push(ctx context.Context, git vcs.Runner, remote string) error {
+	if err := git.Run(ctx, "push", remote, "HEAD"); err != nil {
+		return &PushError{Stderr: vcs.Stderr(err), Err: err}
 	}
 	return nil
 }
 
+// PushBranch pushes HEAD to branch on remote, for commits made outside the
+// branch's own checkout (e.g. a detached worktree). Never uses --force.
+func PushBranch(ctx context.Context, git vcs.Runner, remote, branch string) error {
+	if err := git.Run(ctx, "push", remote, "HEAD:refs/heads/"+branch); err != nil {
+		return &PushError{Stderr: vcs.Stderr(err), Err: err}
+	}
+	return nil
+}
+
+// FetchBranch fetches branch from remote into FETCH_HEAD.
+func FetchBranch(ctx context.Context, git vcs.Runner, remote, branch string) error {
+	return git.Run(ctx, "fetch", remote, branch)
+}
+
+// AddWorktree creates a worktree at dir with ref checked out as a detached HEAD.
+func AddWorktree(ctx context.Context, git vcs.Runner, dir, ref string) error {
+	return git.Run(ctx, "worktree", "add", "--detach", dir, ref)
+}
+
+// RemoveWorktree removes the worktree at dir, discarding any changes in it.
+func RemoveWorktree(ctx context.Context, git vcs.Runner, dir string) error {
+	return git.Run(ctx, "worktree", "remove", "--force", dir)
+}
+
+// DiscardWorktree removes the worktree at dir and the directory itself. When
+// git can't remove it (e.g. it was never fully added), the directory is
+// deleted and stale worktree entries are pruned instead, so none dangle.
+func DiscardWorktree(ctx context.Context, git vcs.Runner, dir string) error {
+	removeErr := RemoveWorktree(ctx, git, dir)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if removeErr == nil {
+		return nil
+	}
+	return git.Run(ctx, "worktree", "prune")
+}
+
 // PushError wraps a git push failure with stderr output.
 type PushError struct {
 	Stderr string
@@ -86,31 +145,33 @@ func (e *PushError) Unwrap() error {
 
 // CurrentBranch returns the name of the current branch.
 // Returns empty string for detached HEAD.
-func CurrentBranch(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "branch", "--show-current")
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	if err := cmd.Run(); err != nil {
+func CurrentBranch(ctx context.Context, git vcs.Runner) (string, error) {
+	out, err := git.Output(ctx, "branch", "--show-current")
+	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	return strings.TrimSpace(out), nil
 }
 
-// CommitAll stages all changes and creates a new commit. Never amends.
-func CommitAll(ctx context.Context, message string) error {
-	addCmd := exec.CommandContext(ctx, "git", "add", "-A")
-	var addStderr bytes.Buffer
-	addCmd.Stderr = &addStderr
-	if err := addCmd.Run(); err != nil {
-		return fmt.Errorf("git add failed: %s", strings.TrimSpace(addStderr.String()))
+// CommitAll stages all changes and creates a new commit, signed with -S when
+// sign is set. Never amends.
+func CommitAll(ctx context.Context, git vcs.Runner, message string, sign bool) error {
+	if err := git.Run(ctx, "add", "-A"); err != nil {
+		return fmt.Errorf("git add failed: %s", vcs.Stderr(err))
 	}
 
-	commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", message)
-	var commitStdout, commitStderr bytes.Buffer
-	commitCmd.Stdout = &commitStdout
-	commitCmd.Stderr = &commitStderr
-	if err := commitCmd.Run(); err != nil {
-		combined := strings.TrimSpace(commitStdout.String() + "\n" + commitStderr.String())
+	args := []string{"commit", "-m", message}
+	if sign {
+		args = append(args, "-S")
+	}
+	if err := git.Run(ctx, args...); err != nil {
+		var combined string
+		var vErr *vcs.Error
+		if errors.As(err, &vErr) {
+			combined = strings.TrimSpace(vErr.Stdout + "\n" + vErr.Stderr)
+		} else {
+			combined = err.Error()
+		}
 		// "nothing to commit" is not a crash — return a descriptive error
 		if strings.Contains(combined, "nothing to commit") {
 			return fmt.Errorf("nothing to commit")
@@ -120,14 +181,46 @@ func CommitAll(ctx context.Context, message string) error {
 	return nil
 }
 
+// UnsignedCommits returns the commits in base..head, oldest first, that carry
+// no signature. Only the commit headers are read; signatures aren't verified,
+// so no keys are needed.
+func UnsignedCommits(ctx context.Context, git vcs.Runner, base, head string) ([]string, error) {
+	out, err := git.Output(ctx, "rev-list", "--reverse", base+".."+head)
+	if err != nil {
+		return nil, fmt.Errorf("list commits in %s..%s: %w", base, head, err)
+	}
+	var unsigned []string
+	for _, sha := range strings.Fields(out) {
+		commit, err := git.Output(ctx, "cat-file", "commit", sha)
+		if err != nil {
+			return nil, fmt.Errorf("read commit %s: %w", sha, err)
+		}
+		if !hasSignature(commit) {
+			unsigned = append(unsigned, sha)
+		}
+	}
+	return unsigned, nil
+}
+
+// hasSignature reports whether a raw commit object's headers include a
+// gpgsig (or gpgsig-sha256) signature.
+func hasSignature(commit string) bool {
+	for _, line := range strings.Split(commit, "\n") {
+		if line == "" {
+			return false // End of the headers; the message follows.
+		}
+		if strings.HasPrefix(line, "gpgsig ") || strings.HasPrefix(line, "gpgsig-sha256 ") {
+			return true
+		}
+	}
+	return false
+}
+
 // DiffStat returns the diff stat between the given base branch and HEAD.
-func DiffStat(ctx context.Context, baseBranch string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "diff", baseBranch+"...HEAD", "--stat") //nolint:gosec // baseBranch comes from gh CLI output, not user input
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+func DiffStat(ctx context.Context, git vcs.Runner, baseBranch string) (string, error) {
+	out, err := git.Output(ctx, "diff", baseBranch+"...HEAD", "--stat")
+	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	return strings.TrimSpace(out), nil
 }
@@ -5,10 +5,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/vcs"
 )
 
 func TestIsGitHubRemote(t *testing.T) {
@@ -35,6 +38,29 @@ func TestIsGitHubRemote(t *testing.T) {
 	}
 }
 
+func TestGitHubRepo(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "https://github.com/user/repo.git", want: "user/repo"},
+		{input: "https://github.com/user/repo/", want: "user/repo"},
+		{input: "git@github.com:user/repo.git", want: "user/repo"},
+		{input: "ssh://git@github.com/user/repo", want: "user/repo"},
+		{input: "https://github.com/user", want: ""},
+		{input: "https://github.com/user/repo/extra", want: ""},
+		{input: "https://gitlab.com/user/repo.git", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := GitHubRepo(tt.input)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want != "", ok)
+		})
+	}
+}
+
 // gitCmd runs a git command in the given directory.
 func gitCmd(t *testing.T, dir string, args ...string) {
 	t.Helper()
@@ -90,7 +116,7 @@ func TestDetectRemote_WithRemote(t *testing.T) {
 	bareDir := initBareRemote(t, dir)
 	chdir(t, dir)
 
-	remote, err := DetectRemote()
+	remote, err := DetectRemote(vcs.Git(""), "origin")
 	require.NoError(t, err)
 	assert.Equal(t, bareDir, remote)
 }
@@ -99,7 +125,7 @@ func TestDetectRemote_NoRemote(t *testing.T) {
 	dir := initGitRepo(t)
 	chdir(t, dir)
 
-	remote, err := DetectRemote()
+	remote, err := DetectRemote(vcs.Git(""), "origin")
 	require.NoError(t, err)
 	assert.Empty(t, remote)
 }
@@ -108,7 +134,7 @@ func TestCurrentBranch(t *testing.T) {
 	dir := initGitRepo(t)
 	chdir(t, dir)
 
-	branch, err := CurrentBranch(context.Background())
+	branch, err := CurrentBranch(context.Background(), vcs.Git(""))
 	require.NoError(t, err)
 	assert.Equal(t, "main", branch)
 }
@@ -118,7 +144,7 @@ func TestPush_Success(t *testing.T) {
 	bareDir := initBareRemote(t, dir)
 	chdir(t, dir)
 
-	err := Push(context.Background())
+	err := Push(context.Background(), vcs.Git(""), "origin")
 	require.NoError(t, err)
 
 	// Verify commit exists in bare repo
@@ -137,7 +163,7 @@ func TestCommitAll(t *testing.T) {
 	// Create uncommitted changes
 	require.NoError(t, os.WriteFile(filepath.Join(dir, "newfile.txt"), []byte("hello"), 0o600))
 
-	err := CommitAll(context.Background(), "add new file")
+	err := CommitAll(context.Background(), vcs.Git(""), "add new file", false)
 	require.NoError(t, err)
 
 	// Verify new commit exists
@@ -145,14 +171,61 @@ func TestCommitAll(t *testing.T) {
 	cmd.Dir = dir
 	out, err := cmd.CombinedOutput()
 	require.NoError(t, err)
-	assert.Contains(t, string(out), "add new file")
+	assert.Contains(t, string(out), "add new file", false)
+}
+
+func TestCommitAll_Signed(t *testing.T) {
+	git := &stubRunner{outputs: map[string]string{
+		"add -A":                 "",
+		"commit -m fix: lint -S": "",
+	}}
+
+	require.NoError(t, CommitAll(context.Background(), git, "fix: lint", true))
+	assert.Equal(t, []string{"add -A", "commit -m fix: lint -S"}, git.calls)
+}
+
+func TestUnsignedCommits(t *testing.T) {
+	dir := initGitRepo(t)
+	base := strings.TrimSpace(gitOutput(t, dir, "rev-parse", "HEAD"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o600))
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-m", "add a")
+	head := strings.TrimSpace(gitOutput(t, dir, "rev-parse", "HEAD"))
+
+	unsigned, err := UnsignedCommits(context.Background(), vcs.Git(dir), base, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{head}, unsigned)
+
+	unsigned, err = UnsignedCommits(context.Background(), vcs.Git(dir), head, "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, unsigned)
+}
+
+func TestUnsignedCommits_ChecksEveryCommitInRange(t *testing.T) {
+	signed := "tree abc\n" +
+		"author a <a@b> 1 +0000\n" +
+		"gpgsig -----BEGIN SSH SIGNATURE-----\n" +
+		" U1NIU0lH\n" +
+		" -----END SSH SIGNATURE-----\n" +
+		"\n" +
+		"signed\n"
+	git := &stubRunner{outputs: map[string]string{
+		"rev-list --reverse base..HEAD": "c1\nc2\nc3\n",
+		"cat-file commit c1":            "tree abc\nauthor a <a@b> 1 +0000\n\nearly commit from another step\n",
+		"cat-file commit c2":            signed,
+		"cat-file commit c3":            "tree abc\n\ngpgsig in the message does not count\n",
+	}}
+
+	unsigned, err := UnsignedCommits(context.Background(), git, "base", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1", "c3"}, unsigned)
 }
 
 func TestCommitAll_NothingToCommit(t *testing.T) {
 	dir := initGitRepo(t)
 	chdir(t, dir)
 
-	err := CommitAll(context.Background(), "empty commit")
+	err := CommitAll(context.Background(), vcs.Git(""), "empty commit", false)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "nothing to commit")
 }
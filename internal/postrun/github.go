@@ -1,25 +1,64 @@
 package postrun
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
+
+	"github.com/yarlson/snap/internal/vcs"
 )
 
-// DefaultBranch returns the default branch name of the GitHub repository.
-// Runs: gh repo view --json defaultBranchRef -q .defaultBranchRef.name.
-func DefaultBranch(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "gh", "repo", "view", "--json", "defaultBranchRef", "-q", ".defaultBranchRef.name")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return "", &GHError{Stderr: strings.TrimSpace(stderr.String()), Err: err}
+// Repo points gh at the repositories of a fork workflow: PRs are opened
+// against Upstream from the branch pushed to Fork. The zero value lets gh
+// infer the repository from the working directory.
+type Repo struct {
+	Upstream string // "owner/repo" PRs and their checks live in
+	Fork     string // "owner/repo" the branch is pushed to
+}
+
+// head returns the PR head for branch in the fork, e.g. "me:feature".
+func (r Repo) head(branch string) string {
+	owner, _, _ := strings.Cut(r.Fork, "/")
+	return owner + ":" + branch
+}
+
+// prArgs returns the arguments selecting branch's PR: none for the current
+// branch's PR, or the fork head in the upstream repository.
+func (r Repo) prArgs(branch string) []string {
+	if r.Upstream == "" {
+		return nil
+	}
+	return []string{r.head(branch), "--repo", r.Upstream}
+}
+
+// runsRepo returns the repository CI runs for branch happen in: the
+// upstream for a PR, the fork for a pushed branch without one. Empty when
+// gh infers it.
+func (r Repo) runsRepo(hasPR bool) string {
+	if r.Upstream == "" {
+		return ""
+	}
+	if hasPR {
+		return r.Upstream
+	}
+	return r.Fork
+}
+
+// DefaultBranch returns the default branch name of the GitHub repository
+// (the upstream one for a fork).
+// Runs: gh repo view [upstream] --json defaultBranchRef -q .defaultBranchRef.name.
+func DefaultBranch(ctx context.Context, gh vcs.Runner, repo Repo) (string, error) {
+	args := []string{"repo", "view"}
+	if repo.Upstream != "" {
+		args = append(args, repo.Upstream)
+	}
+	args = append(args, "--json", "defaultBranchRef", "-q", ".defaultBranchRef.name")
+	out, err := gh.Output(ctx, args...)
+	if err != nil {
+		return "", &GHError{Stderr: vcs.Stderr(err), Err: err}
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	return strings.TrimSpace(out), nil
 }
 
 // prViewResult represents the JSON output from gh pr view.
@@ -28,39 +67,40 @@ type prViewResult struct {
 	URL   string `json:"url"`
 }
 
-// PRExists checks if a PR already exists for the current branch.
+// PRExists checks if a PR already exists for branch, the current branch.
 // Returns (exists, url, error). Exit code 1 from gh means no PR exists (not an error).
-func PRExists(ctx context.Context) (exists bool, prURL string, err error) {
-	cmd := exec.CommandContext(ctx, "gh", "pr", "view", "--json", "state,url")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+func PRExists(ctx context.Context, gh vcs.Runner, repo Repo, branch string) (exists bool, prURL string, err error) {
+	args := append(append([]string{"pr", "view"}, repo.prArgs(branch)...), "--json", "state,url")
+	out, err := gh.Output(ctx, args...)
+	if err != nil {
 		// Exit code 1 = no PR for this branch — not an error
-		if cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == 1 {
+		if vcs.ExitCode(err) == 1 {
 			return false, "", nil
 		}
-		return false, "", &GHError{Stderr: strings.TrimSpace(stderr.String()), Err: err}
+		return false, "", &GHError{Stderr: vcs.Stderr(err), Err: err}
 	}
 
 	var result prViewResult
-	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
 		return false, "", err
 	}
 	return true, result.URL, nil
 }
 
-// CreatePR creates a new pull request with the given title and body.
-// Returns the PR URL.
-func CreatePR(ctx context.Context, title, body string) (string, error) {
-	cmd := exec.CommandContext(ctx, "gh", "pr", "create", "--title", title, "--body", body)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return "", &GHError{Stderr: strings.TrimSpace(stderr.String()), Err: err}
+// CreatePR creates a new pull request for branch, the current branch, with
+// the given title and body. For a fork it is opened in the upstream
+// repository with the fork's branch as head. Returns the PR URL.
+func CreatePR(ctx context.Context, gh vcs.Runner, repo Repo, branch, title, body string) (string, error) {
+	args := []string{"pr", "create"}
+	if repo.Upstream != "" {
+		args = append(args, "--repo", repo.Upstream, "--head", repo.head(branch))
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	args = append(args, "--title", title, "--body", body)
+	out, err := gh.Output(ctx, args...)
+	if err != nil {
+		return "", &GHError{Stderr: vcs.Stderr(err), Err: err}
+	}
+	return strings.TrimSpace(out), nil
 }
 
 // prCheckResult represents a single check from gh pr checks --json.
@@ -68,6 +108,7 @@ type prCheckResult struct {
 	Name       string `json:"name"`
 	State      string `json:"state"`
 	Conclusion string `json:"conclusion"`
+	Link       string `json:"link"`
 }
 
 // runCheckResult represents a single run from gh run list --json.
@@ -75,28 +116,27 @@ type runCheckResult struct {
 	Name       string `json:"name"`
 	Status     string `json:"status"`
 	Conclusion string `json:"conclusion"`
+	URL        string `json:"url"`
 }
 
 // CheckStatus returns the current CI check results.
 // If hasPR is true, uses "gh pr checks --json"; otherwise uses "gh run list --json" scoped to the given branch.
-func CheckStatus(ctx context.Context, hasPR bool, branch string) ([]CheckResult, error) {
+func CheckStatus(ctx context.Context, gh vcs.Runner, repo Repo, hasPR bool, branch string) ([]CheckResult, error) {
 	if hasPR {
-		return checkStatusPR(ctx)
+		return checkStatusPR(ctx, gh, repo.prArgs(branch))
 	}
-	return checkStatusRun(ctx, branch)
+	return checkStatusRun(ctx, gh, repo.runsRepo(false), branch)
 }
 
-func checkStatusPR(ctx context.Context) ([]CheckResult, error) {
-	cmd := exec.CommandContext(ctx, "gh", "pr", "checks", "--json", "name,state,conclusion")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return nil, &GHError{Stderr: strings.TrimSpace(stderr.String()), Err: err}
+func checkStatusPR(ctx context.Context, gh vcs.Runner, prArgs []string) ([]CheckResult, error) {
+	args := append(append([]string{"pr", "checks"}, prArgs...), "--json", "name,state,conclusion,link")
+	out, err := gh.Output(ctx, args...)
+	if err != nil {
+		return nil, &GHError{Stderr: vcs.Stderr(err), Err: err}
 	}
 
 	var raw []prCheckResult
-	if err := json.Unmarshal(stdout.Bytes(), &raw); err != nil {
+	if err := json.Unmarshal([]byte(out), &raw); err != nil {
 		return nil, err
 	}
 
@@ -106,22 +146,24 @@ func checkStatusPR(ctx context.Context) ([]CheckResult, error) {
 			Name:       r.Name,
 			Status:     normalizePRCheckStatus(r.State, r.Conclusion),
 			Conclusion: r.Conclusion,
+			URL:        r.Link,
 		}
 	}
 	return results, nil
 }
 
-func checkStatusRun(ctx context.Context, branch string) ([]CheckResult, error) {
-	cmd := exec.CommandContext(ctx, "gh", "run", "list", "--branch", branch, "--json", "name,status,conclusion", "--limit", "1")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return nil, &GHError{Stderr: strings.TrimSpace(stderr.String()), Err: err}
+func checkStatusRun(ctx context.Context, gh vcs.Runner, runsRepo, branch string) ([]CheckResult, error) {
+	args := []string{"run", "list", "--branch", branch, "--json", "name,status,conclusion,url", "--limit", "1"}
+	if runsRepo != "" {
+		args = append(args, "--repo", runsRepo)
+	}
+	out, err := gh.Output(ctx, args...)
+	if err != nil {
+		return nil, &GHError{Stderr: vcs.Stderr(err), Err: err}
 	}
 
 	var raw []runCheckResult
-	if err := json.Unmarshal(stdout.Bytes(), &raw); err != nil {
+	if err := json.Unmarshal([]byte(out), &raw); err != nil {
 		return nil, err
 	}
 
@@ -131,6 +173,7 @@ func checkStatusRun(ctx context.Context, branch string) ([]CheckResult, error) {
 			Name:       r.Name,
 			Status:     normalizeRunStatus(r.Status, r.Conclusion),
 			Conclusion: r.Conclusion,
+			URL:        r.URL,
 		}
 	}
 	return results, nil
@@ -169,17 +212,19 @@ func normalizeRunStatus(status, conclusion string) string {
 
 const maxLogSize = 50 * 1024 // 50KB
 
-// FailureLogs fetches the failed run logs via gh run view --log-failed.
-// Truncates output to maxLogSize (50KB) to prevent context window overflow.
-func FailureLogs(ctx context.Context, runID string) (string, error) {
-	cmd := exec.CommandContext(ctx, "gh", "run", "view", runID, "--log-failed")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return "", &GHError{Stderr: strings.TrimSpace(stderr.String()), Err: err}
+// FailureLogs fetches the failed run logs via gh run view --log-failed, in
+// runsRepo when set. Truncates output to maxLogSize (50KB) to prevent
+// context window overflow.
+func FailureLogs(ctx context.Context, gh vcs.Runner, runsRepo, runID string) (string, error) {
+	args := []string{"run", "view", runID, "--log-failed"}
+	if runsRepo != "" {
+		args = append(args, "--repo", runsRepo)
 	}
-	return truncateLog(stdout.String()), nil
+	out, err := gh.Output(ctx, args...)
+	if err != nil {
+		return "", &GHError{Stderr: vcs.Stderr(err), Err: err}
+	}
+	return truncateLog(out), nil
 }
 
 // truncateLog truncates log content to maxLogSize, appending a marker if truncated.
@@ -195,18 +240,21 @@ type failedRunResult struct {
 	DatabaseID int `json:"databaseId"` //nolint:tagliatelle // GitHub API uses camelCase
 }
 
-// FailedRunID finds the ID of the most recent failed workflow run.
-func FailedRunID(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "gh", "run", "list", "--status", "failure", "--limit", "1", "--json", "databaseId")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return "", &GHError{Stderr: strings.TrimSpace(stderr.String()), Err: err}
+// FailedRunID finds the ID of the most recent failed workflow run. With
+// runsRepo set (a fork), it looks there and only at branch's runs, since
+// an upstream repository also runs CI for other contributors.
+func FailedRunID(ctx context.Context, gh vcs.Runner, runsRepo, branch string) (string, error) {
+	args := []string{"run", "list", "--status", "failure", "--limit", "1", "--json", "databaseId"}
+	if runsRepo != "" {
+		args = append(args, "--branch", branch, "--repo", runsRepo)
+	}
+	out, err := gh.Output(ctx, args...)
+	if err != nil {
+		return "", &GHError{Stderr: vcs.Stderr(err), Err: err}
 	}
 
 	var results []failedRunResult
-	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
 		return "", err
 	}
 	if len(results) == 0 {
@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/vcs"
 )
 
 // mockGH creates a fake gh script in a temp directory and prepends it to PATH.
@@ -44,7 +46,7 @@ func mockGHScript(t *testing.T, script string) {
 func TestDefaultBranch(t *testing.T) {
 	mockGH(t, "main")
 
-	branch, err := DefaultBranch(context.Background())
+	branch, err := DefaultBranch(context.Background(), vcs.GH(), Repo{})
 	require.NoError(t, err)
 	assert.Equal(t, "main", branch)
 }
@@ -52,7 +54,7 @@ func TestDefaultBranch(t *testing.T) {
 func TestDefaultBranch_DevelopBranch(t *testing.T) {
 	mockGH(t, "develop")
 
-	branch, err := DefaultBranch(context.Background())
+	branch, err := DefaultBranch(context.Background(), vcs.GH(), Repo{})
 	require.NoError(t, err)
 	assert.Equal(t, "develop", branch)
 }
@@ -60,7 +62,7 @@ func TestDefaultBranch_DevelopBranch(t *testing.T) {
 func TestPRExists_NoPR(t *testing.T) {
 	mockGHScript(t, "exit 1\n")
 
-	exists, url, err := PRExists(context.Background())
+	exists, url, err := PRExists(context.Background(), vcs.GH(), Repo{}, "feature")
 	require.NoError(t, err)
 	assert.False(t, exists)
 	assert.Empty(t, url)
@@ -69,7 +71,7 @@ func TestPRExists_NoPR(t *testing.T) {
 func TestPRExists_HasPR(t *testing.T) {
 	mockGH(t, `{"state":"OPEN","url":"https://github.com/user/repo/pull/42"}`)
 
-	exists, url, err := PRExists(context.Background())
+	exists, url, err := PRExists(context.Background(), vcs.GH(), Repo{}, "feature")
 	require.NoError(t, err)
 	assert.True(t, exists)
 	assert.Equal(t, "https://github.com/user/repo/pull/42", url)
@@ -78,7 +80,7 @@ func TestPRExists_HasPR(t *testing.T) {
 func TestCreatePR(t *testing.T) {
 	mockGH(t, "https://github.com/user/repo/pull/42")
 
-	url, err := CreatePR(context.Background(), "Add feature", "Body text")
+	url, err := CreatePR(context.Background(), vcs.GH(), Repo{}, "feature", "Add feature", "Body text")
 	require.NoError(t, err)
 	assert.Equal(t, "https://github.com/user/repo/pull/42", url)
 }
@@ -86,7 +88,7 @@ func TestCreatePR(t *testing.T) {
 func TestCreatePR_Failure(t *testing.T) {
 	mockGHScript(t, "echo 'permission denied' >&2\nexit 1\n")
 
-	_, err := CreatePR(context.Background(), "Title", "Body")
+	_, err := CreatePR(context.Background(), vcs.GH(), Repo{}, "feature", "Title", "Body")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "permission denied")
 }
@@ -96,7 +98,7 @@ func TestCheckStatus_AllGreen(t *testing.T) {
 printf '%s' '[{"name":"lint","state":"SUCCESS","conclusion":"success"},{"name":"test","state":"SUCCESS","conclusion":"success"}]'
 `)
 
-	checks, err := CheckStatus(context.Background(), true, "main")
+	checks, err := CheckStatus(context.Background(), vcs.GH(), Repo{}, true, "main")
 	require.NoError(t, err)
 	require.Len(t, checks, 2)
 	assert.Equal(t, "lint", checks[0].Name)
@@ -107,34 +109,37 @@ printf '%s' '[{"name":"lint","state":"SUCCESS","conclusion":"success"},{"name":"
 
 func TestCheckStatus_Mixed(t *testing.T) {
 	mockGHScript(t, `
-printf '%s' '[{"name":"lint","state":"SUCCESS","conclusion":"success"},{"name":"test","state":"FAILURE","conclusion":"failure"},{"name":"build","state":"PENDING","conclusion":""}]'
+printf '%s' '[{"name":"lint","state":"SUCCESS","conclusion":"success"},{"name":"test","state":"FAILURE","conclusion":"failure","link":"https://github.com/user/repo/actions/runs/1/job/2"},{"name":"build","state":"PENDING","conclusion":""}]'
 `)
 
-	checks, err := CheckStatus(context.Background(), true, "main")
+	checks, err := CheckStatus(context.Background(), vcs.GH(), Repo{}, true, "main")
 	require.NoError(t, err)
 	require.Len(t, checks, 3)
 	assert.Equal(t, "passed", checks[0].Status)
 	assert.Equal(t, "failed", checks[1].Status)
 	assert.Equal(t, "pending", checks[2].Status)
+	assert.Empty(t, checks[0].URL)
+	assert.Equal(t, "https://github.com/user/repo/actions/runs/1/job/2", checks[1].URL)
 }
 
 func TestCheckStatus_NoPR(t *testing.T) {
 	// When no PR, CheckStatus uses gh run list with --json
 	mockGHScript(t, `
-printf '%s' '[{"name":"CI","status":"completed","conclusion":"success"}]'
+printf '%s' '[{"name":"CI","status":"completed","conclusion":"success","url":"https://github.com/user/repo/actions/runs/7"}]'
 `)
 
-	checks, err := CheckStatus(context.Background(), false, "main")
+	checks, err := CheckStatus(context.Background(), vcs.GH(), Repo{}, false, "main")
 	require.NoError(t, err)
 	require.Len(t, checks, 1)
 	assert.Equal(t, "CI", checks[0].Name)
 	assert.Equal(t, "passed", checks[0].Status)
+	assert.Equal(t, "https://github.com/user/repo/actions/runs/7", checks[0].URL)
 }
 
 func TestCheckStatus_Empty(t *testing.T) {
 	mockGHScript(t, `printf '%s' '[]'`)
 
-	checks, err := CheckStatus(context.Background(), true, "main")
+	checks, err := CheckStatus(context.Background(), vcs.GH(), Repo{}, true, "main")
 	require.NoError(t, err)
 	assert.Empty(t, checks)
 }
@@ -162,7 +167,7 @@ func TestTruncateLog_ExactLimit(t *testing.T) {
 func TestFailureLogs(t *testing.T) {
 	mockGHScript(t, `printf '%s' 'Error: lint failed on line 42'`)
 
-	logs, err := FailureLogs(context.Background(), "12345")
+	logs, err := FailureLogs(context.Background(), vcs.GH(), "", "12345")
 	require.NoError(t, err)
 	assert.Equal(t, "Error: lint failed on line 42", logs)
 }
@@ -178,7 +183,7 @@ func TestFailureLogs_Truncated(t *testing.T) {
 	origPath := os.Getenv("PATH")
 	t.Setenv("PATH", binDir+string(os.PathListSeparator)+origPath)
 
-	logs, err := FailureLogs(context.Background(), "12345")
+	logs, err := FailureLogs(context.Background(), vcs.GH(), "", "12345")
 	require.NoError(t, err)
 	assert.Contains(t, logs, "[log truncated")
 }
@@ -186,7 +191,7 @@ func TestFailureLogs_Truncated(t *testing.T) {
 func TestFailedRunID(t *testing.T) {
 	mockGH(t, `[{"databaseId":98765}]`)
 
-	id, err := FailedRunID(context.Background())
+	id, err := FailedRunID(context.Background(), vcs.GH(), "", "feature")
 	require.NoError(t, err)
 	assert.Equal(t, "98765", id)
 }
@@ -194,7 +199,7 @@ func TestFailedRunID(t *testing.T) {
 func TestFailedRunID_NoRuns(t *testing.T) {
 	mockGH(t, `[]`)
 
-	_, err := FailedRunID(context.Background())
+	_, err := FailedRunID(context.Background(), vcs.GH(), "", "feature")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "no failed runs found")
 }
@@ -2,6 +2,7 @@ package postrun
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -9,9 +10,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/yarlson/snap/internal/cleanup"
 	"github.com/yarlson/snap/internal/model"
 	"github.com/yarlson/snap/internal/postrun/prompts"
 	"github.com/yarlson/snap/internal/ui"
+	"github.com/yarlson/snap/internal/vcs"
 )
 
 // CheckResult represents the status of a single CI check.
@@ -19,32 +22,116 @@ type CheckResult struct {
 	Name       string // Check/job name
 	Status     string // "pending", "running", "passed", "failed"
 	Conclusion string // Raw GitHub conclusion field
+	URL        string // Link to the check's details page (empty if unavailable)
 }
 
 var prNumberRe = regexp.MustCompile(`/pull/(\d+)`)
 
+// ErrCI is returned when CI checks still fail after the last fix attempt.
+var ErrCI = errors.New("CI still failing")
+
 // Executor runs an LLM call. Matches the workflow.Executor interface.
 type Executor interface {
 	Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error
 }
 
+// DirExecutor is implemented by executors that can run the provider in a
+// given working directory. Isolated CI fixes (Config.IsolateCIFix) need it.
+type DirExecutor interface {
+	RunInDir(ctx context.Context, dir string, w io.Writer, mt model.Type, args ...string) error
+}
+
+// Fallback is an executor CI fixes switch to when the configured executor
+// keeps failing the fix call.
+type Fallback struct {
+	Name     string // Provider name for output, e.g. "codex"
+	Executor Executor
+}
+
 // Config holds configuration for the post-run step.
 type Config struct {
 	Output       io.Writer
 	Executor     Executor      // LLM executor for PR generation (nil = skip LLM, use default title)
-	RemoteURL    string        // Pre-detected remote URL (empty = no remote)
+	RemoteURL    string        // Pre-detected URL of the push remote (empty = no remote)
 	IsGitHub     bool          // Pre-detected GitHub flag
+	PushRemote   string        // Remote branches and CI fixes are pushed to (default: DefaultRemote)
 	PRDPath      string        // Path to PRD.md for PR body context
 	TasksDir     string        // Tasks directory
 	RepoRoot     string        // Repository root path for workflow detection (defaults to ".")
 	PollInterval time.Duration // CI poll interval (defaults to 15s)
+	Git          vcs.Runner    // git commands (nil = git in the working directory)
+	GH           vcs.Runner    // GitHub CLI commands (nil = gh)
+
+	// Repo opens PRs in an upstream repository from a fork (the push
+	// remote's repository). The zero value uses the repository gh infers.
+	Repo Repo
+
+	// IsolateCIFix applies CI fixes in a temporary worktree checked out from
+	// the pushed branch, so the fix never touches the user's working tree.
+	// Requires an Executor that implements DirExecutor and a named branch;
+	// otherwise fixes fall back to the working tree.
+	IsolateCIFix bool
+
+	// Cleanup tracks the temporary CI fix worktree while it exists, so the
+	// caller can remove it if post-run is cut short. Nil leaves removal to
+	// post-run alone.
+	Cleanup *cleanup.Registry
+
+	// SignCommits signs the CI fix commits with -S.
+	SignCommits bool
+
+	// FixFallbacks are tried in order when the CI fix provider call still
+	// fails after its retry. Isolated fixes skip fallbacks that are not
+	// DirExecutors.
+	FixFallbacks []Fallback
+
+	// Reattach resumes CI monitoring that an earlier run was interrupted in:
+	// the branch's open PR is reused as-is instead of going through PR
+	// creation. Without an open PR the normal PR flow runs.
+	Reattach bool
+
+	// OnMonitorCI, when set, is called just before CI monitoring starts so
+	// the caller can record that monitoring is in progress.
+	OnMonitorCI func() error
+}
+
+// git returns the configured git runner, defaulting to git in the working directory.
+func (c Config) git() vcs.Runner {
+	if c.Git != nil {
+		return c.Git
+	}
+	return vcs.Git("")
+}
+
+// remote returns the remote to push to, defaulting to DefaultRemote.
+func (c Config) remote() string {
+	if c.PushRemote != "" {
+		return c.PushRemote
+	}
+	return DefaultRemote
+}
+
+// gh returns the configured GitHub CLI runner, defaulting to gh.
+func (c Config) gh() vcs.Runner {
+	if c.GH != nil {
+		return c.GH
+	}
+	return vcs.GH()
 }
 
 const (
 	defaultPollInterval = 15 * time.Second
 	maxFixAttempts      = 10
+	fixCallRetries      = 1 // Retries of a failed CI fix provider call per executor
 )
 
+// fixRetryDelay is the pause before retrying a failed CI fix provider call.
+var fixRetryDelay = 5 * time.Second
+
+// errFixCall marks a fix attempt whose provider call failed on every
+// executor. It counts as a failed attempt rather than ending the fix loop.
+var errFixCall = errors.New("CI fix provider call failed")
+
 // Run executes the post-run step: push to remote, create PR if on GitHub, monitor CI.
 func Run(ctx context.Context, cfg Config) error {
 	if cfg.RemoteURL == "" {
@@ -52,19 +139,20 @@ func Run(ctx context.Context, cfg Config) error {
 		return nil
 	}
 
-	// Push to origin
-	fmt.Fprint(cfg.Output, ui.Step("Pushing to origin..."))
+	// Push to the push remote
+	remote := cfg.remote()
+	fmt.Fprint(cfg.Output, ui.Step(fmt.Sprintf("Pushing to %s...", remote)))
 	pushStart := time.Now()
 
-	if err := Push(ctx); err != nil {
+	if err := Push(ctx, cfg.git(), remote); err != nil {
 		return fmt.Errorf("push failed: %w", err)
 	}
 
-	branch, err := CurrentBranch(ctx)
+	branch, err := CurrentBranch(ctx, cfg.git())
 	if err != nil {
 		branch = "unknown"
 	}
-	fmt.Fprint(cfg.Output, ui.StepComplete(fmt.Sprintf("Pushed to origin/%s", branch), time.Since(pushStart)))
+	fmt.Fprint(cfg.Output, ui.StepComplete(fmt.Sprintf("Pushed to %s/%s", remote, branch), time.Since(pushStart)))
 
 	if !cfg.IsGitHub {
 		fmt.Fprint(cfg.Output, ui.Info("Non-GitHub remote, skipping PR and CI"))
@@ -72,15 +160,43 @@ func Run(ctx context.Context, cfg Config) error {
 	}
 
 	// PR creation flow — returns whether a PR exists for the branch
-	hasPR, err := createPRFlow(ctx, cfg, branch)
+	hasPR, err := reattachPR(ctx, cfg, branch)
 	if err != nil {
 		return err
 	}
+	if !hasPR {
+		hasPR, err = createPRFlow(ctx, cfg, branch)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.OnMonitorCI != nil {
+		if err := cfg.OnMonitorCI(); err != nil {
+			return fmt.Errorf("failed to record CI monitoring: %w", err)
+		}
+	}
 
 	// CI monitoring
 	return monitorCI(ctx, cfg, hasPR, branch)
 }
 
+// reattachPR reports whether the branch has an open PR to reattach CI
+// monitoring to. It only looks when cfg.Reattach is set.
+func reattachPR(ctx context.Context, cfg Config, branch string) (bool, error) {
+	if !cfg.Reattach {
+		return false, nil
+	}
+	exists, url, err := PRExists(ctx, cfg.gh(), cfg.Repo, branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for existing PR: %w", err)
+	}
+	if exists {
+		fmt.Fprint(cfg.Output, ui.Info(fmt.Sprintf("Reattaching to CI for PR: %s", url)))
+	}
+	return exists, nil
+}
+
 func createPRFlow(ctx context.Context, cfg Config, currentBranch string) (hasPR bool, err error) {
 	// Detached HEAD — skip PR creation silently
 	if currentBranch == "" || currentBranch == "unknown" {
@@ -88,19 +204,20 @@ func createPRFlow(ctx context.Context, cfg Config, currentBranch string) (hasPR
 	}
 
 	// Get default branch
-	defaultBranch, err := DefaultBranch(ctx)
+	defaultBranch, err := DefaultBranch(ctx, cfg.gh(), cfg.Repo)
 	if err != nil {
 		return false, fmt.Errorf("failed to detect default branch: %w", err)
 	}
 
-	// On default branch — skip PR creation
-	if currentBranch == defaultBranch {
+	// On default branch — skip PR creation. A fork's default branch can
+	// still be proposed upstream.
+	if currentBranch == defaultBranch && cfg.Repo.Upstream == "" {
 		fmt.Fprint(cfg.Output, ui.Info("On default branch, skipping PR creation"))
 		return false, nil
 	}
 
 	// Check if PR already exists
-	exists, existingURL, err := PRExists(ctx)
+	exists, existingURL, err := PRExists(ctx, cfg.gh(), cfg.Repo, currentBranch)
 	if err != nil {
 		return false, fmt.Errorf("failed to check for existing PR: %w", err)
 	}
@@ -116,7 +233,7 @@ func createPRFlow(ctx context.Context, cfg Config, currentBranch string) (hasPR
 	title, body := generatePR(ctx, cfg, defaultBranch)
 
 	// Create PR
-	prURL, err := CreatePR(ctx, title, body)
+	prURL, err := CreatePR(ctx, cfg.gh(), cfg.Repo, currentBranch, title, body)
 	if err != nil {
 		fmt.Fprint(cfg.Output, ui.Error(fmt.Sprintf("PR creation failed: %s", err)))
 		return false, fmt.Errorf("PR creation failed: %w", err)
@@ -144,7 +261,7 @@ func generatePR(ctx context.Context, cfg Config, defaultBranch string) (title, b
 	}
 
 	// Get diff stat (best-effort, ignore errors).
-	diffStat, _ := DiffStat(ctx, defaultBranch) //nolint:errcheck // best-effort diff stat
+	diffStat, _ := DiffStat(ctx, cfg.git(), defaultBranch) //nolint:errcheck // best-effort diff stat
 
 	// Render prompt
 	prompt, err := prompts.PR(prompts.PRData{
@@ -202,7 +319,7 @@ func monitorCI(ctx context.Context, cfg Config, hasPR bool, branch string) error
 			return nil //nolint:nilerr // context cancellation is a clean exit, not an error
 		}
 
-		checks, err := CheckStatus(ctx, hasPR, branch)
+		checks, err := CheckStatus(ctx, cfg.gh(), cfg.Repo, hasPR, branch)
 		if err != nil {
 			if ctx.Err() != nil {
 				return nil //nolint:nilerr // context cancellation is a clean exit, not an error
@@ -220,12 +337,16 @@ func monitorCI(ctx context.Context, cfg Config, hasPR bool, branch string) error
 				attempt++
 				if attempt > maxFixAttempts {
 					fmt.Fprint(cfg.Output, ui.Error(fmt.Sprintf("CI still failing after %d attempts", maxFixAttempts)))
-					return fmt.Errorf("CI still failing after %d attempts: %s", maxFixAttempts, failedCheckNames(checks))
+					return fmt.Errorf("%w after %d attempts: %s", ErrCI, maxFixAttempts, failedCheckNames(checks))
 				}
 
-				checkName := firstFailedName(checks)
-				if err := fixCI(ctx, cfg, checkName, attempt); err != nil {
-					return err
+				if err := fixCI(ctx, cfg, firstFailed(checks), attempt, hasPR, branch); err != nil {
+					if !errors.Is(err, errFixCall) {
+						return err
+					}
+					// Nothing was pushed, so the next poll sees the same
+					// failure and starts the next attempt.
+					fmt.Fprintln(cfg.Output, ui.Error(fmt.Sprintf("Fix attempt %d/%d failed: %v", attempt, maxFixAttempts, err)))
 				}
 
 				// Reset prev so we re-print status on next poll
@@ -258,18 +379,24 @@ func monitorCI(ctx context.Context, cfg Config, hasPR bool, branch string) error
 }
 
 // fixCI performs a single CI fix attempt: fetch logs, call LLM, commit, push.
-func fixCI(ctx context.Context, cfg Config, checkName string, attempt int) error {
-	fmt.Fprint(cfg.Output, ui.Info(fmt.Sprintf("CI failed — %s (attempt %d/%d)", checkName, attempt, maxFixAttempts)))
+func fixCI(ctx context.Context, cfg Config, failed CheckResult, attempt int, hasPR bool, branch string) error {
+	checkName := failed.Name
+	msg := fmt.Sprintf("CI failed — %s (attempt %d/%d)", checkName, attempt, maxFixAttempts)
+	if failed.URL != "" {
+		msg += ": " + failed.URL
+	}
+	fmt.Fprint(cfg.Output, ui.Info(msg))
 
 	// Fetch failed run ID
-	runID, err := FailedRunID(ctx)
+	runsRepo := cfg.Repo.runsRepo(hasPR)
+	runID, err := FailedRunID(ctx, cfg.gh(), runsRepo, branch)
 	if err != nil {
 		fmt.Fprint(cfg.Output, ui.Error(fmt.Sprintf("Failed to read CI logs: %s", err)))
 		return fmt.Errorf("failed to get failed run ID: %w", err)
 	}
 
 	// Fetch failure logs (in-memory only, never written to disk)
-	logs, err := FailureLogs(ctx, runID)
+	logs, err := FailureLogs(ctx, cfg.gh(), runsRepo, runID)
 	if err != nil {
 		fmt.Fprint(cfg.Output, ui.Error(fmt.Sprintf("Failed to read CI logs: %s", err)))
 		return fmt.Errorf("failed to fetch CI logs: %w", err)
@@ -290,33 +417,149 @@ func fixCI(ctx context.Context, cfg Config, checkName string, attempt int) error
 	if cfg.Executor == nil {
 		return fmt.Errorf("no executor configured for CI fix")
 	}
-	if err := cfg.Executor.Run(ctx, cfg.Output, model.Fast, prompt); err != nil {
-		return fmt.Errorf("CI fix LLM call failed: %w", err)
-	}
 
-	// Commit the fix (new commit, never amend)
+	// New commit, never amend
 	commitMsg := fmt.Sprintf("fix: resolve %s CI failure", checkName)
-	if err := CommitAll(ctx, commitMsg); err != nil {
+
+	executors := append([]Fallback{{Executor: cfg.Executor}}, cfg.FixFallbacks...)
+	if cfg.IsolateCIFix {
+		reason := isolationSupport(cfg, branch)
+		if reason == "" {
+			return fixInWorktree(ctx, cfg, dirExecutors(executors), branch, prompt, commitMsg)
+		}
+		fmt.Fprint(cfg.Output, ui.Info(fmt.Sprintf("Isolated CI fix unavailable (%s), fixing in the working tree", reason)))
+	}
+
+	if err := callFix(ctx, cfg, executors, func(e Executor) error {
+		return e.Run(ctx, cfg.Output, model.Fast, prompt)
+	}); err != nil {
+		return err
+	}
+
+	// Commit the fix
+	if err := CommitAll(ctx, cfg.git(), commitMsg, cfg.SignCommits); err != nil {
 		return fmt.Errorf("failed to commit CI fix: %w", err)
 	}
 
 	// Push the fix
-	if err := Push(ctx); err != nil {
+	if err := Push(ctx, cfg.git(), cfg.remote()); err != nil {
+		return fmt.Errorf("failed to push CI fix: %w", err)
+	}
+
+	fmt.Fprint(cfg.Output, ui.Step("Fix pushed, waiting for CI..."))
+	return nil
+}
+
+// callFix runs the CI fix provider call on each executor in turn: the
+// configured one first, then the fallbacks. Each gets fixCallRetries retries
+// before the next is tried. When all of them fail it returns errFixCall with
+// the last error.
+func callFix(ctx context.Context, cfg Config, executors []Fallback, call func(Executor) error) error {
+	var err error
+	for i, fb := range executors {
+		if i > 0 {
+			fmt.Fprint(cfg.Output, ui.Info(fmt.Sprintf("Switching CI fix to %s", fb.Name)))
+		}
+		for try := 0; try <= fixCallRetries; try++ {
+			if try > 0 {
+				fmt.Fprint(cfg.Output, ui.Info(fmt.Sprintf("CI fix call failed (%v), retrying", err)))
+				select {
+				case <-ctx.Done():
+					return fmt.Errorf("CI fix provider call failed: %w", ctx.Err())
+				case <-time.After(fixRetryDelay):
+				}
+			}
+			if err = call(fb.Executor); err == nil {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return fmt.Errorf("CI fix provider call failed: %w", err)
+			}
+		}
+	}
+	return fmt.Errorf("%w: %w", errFixCall, err)
+}
+
+// isolationSupport returns the reason an isolated fix is not possible, or ""
+// when the configured executor can run in the fix worktree.
+func isolationSupport(cfg Config, branch string) string {
+	if branch == "" || branch == "unknown" {
+		return "no branch checked out"
+	}
+	if _, ok := cfg.Executor.(DirExecutor); !ok {
+		return "provider cannot run in another directory"
+	}
+	return ""
+}
+
+// dirExecutors returns the executors that can run in another directory.
+func dirExecutors(executors []Fallback) []Fallback {
+	var out []Fallback
+	for _, fb := range executors {
+		if _, ok := fb.Executor.(DirExecutor); ok {
+			out = append(out, fb)
+		}
+	}
+	return out
+}
+
+// fixInWorktree applies a CI fix in a temporary worktree checked out from
+// the remote branch, then commits and pushes it from there. The user's
+// working tree and local branch are left untouched.
+func fixInWorktree(ctx context.Context, cfg Config, executors []Fallback, branch, prompt, commitMsg string) error {
+	dir, err := os.MkdirTemp("", "snap-ci-fix-")
+	if err != nil {
+		return fmt.Errorf("failed to create CI fix worktree: %w", err)
+	}
+	// Registered before the worktree exists, so a failed add or an
+	// interrupt at any point still removes the directory.
+	release := cfg.Cleanup.Add("CI fix worktree "+dir, func(ctx context.Context) error {
+		return DiscardWorktree(ctx, cfg.git(), dir)
+	})
+	defer func() {
+		if err := release(); err != nil {
+			fmt.Fprint(cfg.Output, ui.Interrupted(fmt.Sprintf("Warning: failed to remove %v", err)))
+		}
+	}()
+
+	// Start from the remote tip: earlier isolated fixes are not on the local branch.
+	if err := FetchBranch(ctx, cfg.git(), cfg.remote(), branch); err != nil {
+		return fmt.Errorf("failed to fetch %s for CI fix: %w", branch, err)
+	}
+	if err := AddWorktree(ctx, cfg.git(), dir, "FETCH_HEAD"); err != nil {
+		return fmt.Errorf("failed to create CI fix worktree: %w", err)
+	}
+
+	fmt.Fprint(cfg.Output, ui.Info("Fixing in isolated worktree "+dir))
+
+	if err := callFix(ctx, cfg, executors, func(e Executor) error {
+		return e.(DirExecutor).RunInDir(ctx, dir, cfg.Output, model.Fast, prompt)
+	}); err != nil {
+		return err
+	}
+
+	worktree := vcs.Git(dir)
+	if err := CommitAll(ctx, worktree, commitMsg, cfg.SignCommits); err != nil {
+		return fmt.Errorf("failed to commit CI fix: %w", err)
+	}
+	if err := PushBranch(ctx, worktree, cfg.remote(), branch); err != nil {
 		return fmt.Errorf("failed to push CI fix: %w", err)
 	}
 
+	fmt.Fprint(cfg.Output, ui.Info(fmt.Sprintf("Fix pushed to %s/%s from the isolated worktree — pull to update your local branch", cfg.remote(), branch)))
 	fmt.Fprint(cfg.Output, ui.Step("Fix pushed, waiting for CI..."))
 	return nil
 }
 
-// firstFailedName returns the name of the first failed check.
-func firstFailedName(checks []CheckResult) string {
+// firstFailed returns the first failed check. The returned check is named
+// "unknown" when no check has failed.
+func firstFailed(checks []CheckResult) CheckResult {
 	for _, c := range checks {
 		if c.Status == "failed" {
-			return c.Name
+			return c
 		}
 	}
-	return "unknown"
+	return CheckResult{Name: "unknown"}
 }
 
 // failedCheckNames returns a comma-separated list of failed check names.
@@ -3,6 +3,7 @@ package postrun
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -16,7 +17,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/yarlson/snap/internal/cleanup"
 	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/ui"
+	"github.com/yarlson/snap/internal/vcs"
 )
 
 // mockExecutor is a test double for the LLM executor.
@@ -246,6 +250,155 @@ func TestRun_PRSkip_DefaultBranch(t *testing.T) {
 	assert.NotContains(t, output, "Creating pull request...")
 }
 
+// stubRunner answers VCS commands from a table keyed by the joined args and
+// records every call. Unlisted commands fail with exit code 1.
+type stubRunner struct {
+	outputs map[string]string
+	calls   []string
+}
+
+func (r *stubRunner) Run(ctx context.Context, args ...string) error {
+	_, err := r.Output(ctx, args...)
+	return err
+}
+
+func (r *stubRunner) Output(_ context.Context, args ...string) (string, error) {
+	key := strings.Join(args, " ")
+	r.calls = append(r.calls, key)
+	out, ok := r.outputs[key]
+	if !ok {
+		return "", &vcs.Error{Args: args, Stderr: "unexpected: " + key, ExitCode: 1}
+	}
+	return out, nil
+}
+
+func TestRun_StubbedRunners_ExistingPR(t *testing.T) {
+	git := &stubRunner{outputs: map[string]string{
+		"push origin HEAD":      "",
+		"branch --show-current": "feature-x\n",
+	}}
+	gh := &stubRunner{outputs: map[string]string{
+		"repo view --json defaultBranchRef -q .defaultBranchRef.name": "main\n",
+		"pr view --json state,url":                                    `{"state":"OPEN","url":"https://github.com/user/repo/pull/7"}`,
+	}}
+
+	var buf bytes.Buffer
+	cfg := Config{
+		Output:    &buf,
+		RemoteURL: "https://github.com/user/repo.git",
+		IsGitHub:  true,
+		RepoRoot:  t.TempDir(), // no workflows, so CI monitoring ends immediately
+		Git:       git,
+		GH:        gh,
+	}
+
+	require.NoError(t, Run(context.Background(), cfg))
+
+	output := buf.String()
+	assert.Contains(t, output, "Pushed to origin/feature-x")
+	assert.Contains(t, output, "PR already exists: https://github.com/user/repo/pull/7")
+	assert.Contains(t, output, "No CI workflows found")
+	assert.Equal(t, []string{"push origin HEAD", "branch --show-current"}, git.calls)
+}
+
+func TestRun_StubbedRunners_ForkPR(t *testing.T) {
+	git := &stubRunner{outputs: map[string]string{
+		"push fork HEAD":          "",
+		"branch --show-current":   "main\n",
+		"diff main...HEAD --stat": "",
+	}}
+	gh := &stubRunner{outputs: map[string]string{
+		"repo view upstream/repo --json defaultBranchRef -q .defaultBranchRef.name": "main\n",
+		"pr create --repo upstream/repo --head me:main --title Update --body ":      "https://github.com/upstream/repo/pull/9\n",
+	}}
+
+	var buf bytes.Buffer
+	cfg := Config{
+		Output:     &buf,
+		RemoteURL:  "git@github.com:me/repo.git",
+		IsGitHub:   true,
+		PushRemote: "fork",
+		Repo:       Repo{Upstream: "upstream/repo", Fork: "me/repo"},
+		RepoRoot:   t.TempDir(),
+		Git:        git,
+		GH:         gh,
+	}
+
+	require.NoError(t, Run(context.Background(), cfg))
+
+	output := buf.String()
+	assert.Contains(t, output, "Pushed to fork/main")
+	assert.Contains(t, output, "https://github.com/upstream/repo/pull/9")
+	assert.Contains(t, gh.calls, "pr view me:main --repo upstream/repo --json state,url", "existing PR is looked up in the upstream repo")
+}
+
+func TestRun_Reattach_ExistingPR(t *testing.T) {
+	git := &stubRunner{outputs: map[string]string{
+		"push origin HEAD":      "",
+		"branch --show-current": "feature-x\n",
+	}}
+	gh := &stubRunner{outputs: map[string]string{
+		"pr view --json state,url": `{"state":"OPEN","url":"https://github.com/user/repo/pull/7"}`,
+	}}
+
+	var buf bytes.Buffer
+	var monitored bool
+	cfg := Config{
+		Output:      &buf,
+		RemoteURL:   "https://github.com/user/repo.git",
+		IsGitHub:    true,
+		RepoRoot:    t.TempDir(),
+		Git:         git,
+		GH:          gh,
+		Reattach:    true,
+		OnMonitorCI: func() error { monitored = true; return nil },
+	}
+
+	require.NoError(t, Run(context.Background(), cfg))
+
+	output := buf.String()
+	assert.Contains(t, output, "Reattaching to CI for PR: https://github.com/user/repo/pull/7")
+	assert.NotContains(t, output, "PR already exists")
+	assert.Equal(t, []string{"pr view --json state,url"}, gh.calls, "PR creation flow is skipped")
+	assert.True(t, monitored)
+}
+
+func TestRun_OnMonitorCIError(t *testing.T) {
+	git := &stubRunner{outputs: map[string]string{
+		"push origin HEAD":      "",
+		"branch --show-current": "",
+	}}
+
+	err := Run(context.Background(), Config{
+		Output:      io.Discard,
+		RemoteURL:   "https://github.com/user/repo.git",
+		IsGitHub:    true,
+		Git:         git,
+		GH:          &stubRunner{},
+		OnMonitorCI: func() error { return errors.New("disk full") },
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to record CI monitoring: disk full")
+}
+
+func TestRun_StubbedRunners_PushRejected(t *testing.T) {
+	git := &stubRunner{}
+
+	var buf bytes.Buffer
+	err := Run(context.Background(), Config{
+		Output:    &buf,
+		RemoteURL: "https://example.com/repo.git",
+		Git:       git,
+		GH:        &stubRunner{},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "push failed: unexpected: push origin HEAD")
+
+	var pushErr *PushError
+	require.ErrorAs(t, err, &pushErr)
+	assert.Equal(t, 1, vcs.ExitCode(pushErr))
+}
+
 func TestRun_PRSkip_Existing(t *testing.T) {
 	dir := initGitRepo(t)
 	initBareRemote(t, dir)
@@ -870,7 +1023,7 @@ func TestRun_CIFix_SuccessOnSecondAttempt(t *testing.T) {
 	// First poll: lint fails. Second poll (after fix): all pass.
 	mockGHWithCIFix(t, "main", "", "https://github.com/user/repo/pull/60",
 		[]string{
-			`[{"name":"lint","state":"FAILURE","conclusion":"failure"},{"name":"test","state":"SUCCESS","conclusion":"success"}]`,
+			`[{"name":"lint","state":"FAILURE","conclusion":"failure","link":"https://github.com/user/repo/actions/runs/12345/job/1"},{"name":"test","state":"SUCCESS","conclusion":"success","link":"https://github.com/user/repo/actions/runs/12345/job/2"}]`,
 			`[{"name":"lint","state":"SUCCESS","conclusion":"success"},{"name":"test","state":"SUCCESS","conclusion":"success"}]`,
 		},
 		"12345", "Error: unused variable on line 10",
@@ -892,7 +1045,8 @@ func TestRun_CIFix_SuccessOnSecondAttempt(t *testing.T) {
 	require.NoError(t, err)
 
 	output := buf.String()
-	assert.Contains(t, output, "CI failed — lint (attempt 1/10)")
+	assert.Contains(t, output, "CI failed — lint (attempt 1/10): https://github.com/user/repo/actions/runs/12345/job/1")
+	assert.NotContains(t, output, "actions/runs/12345/job/2")
 	assert.Contains(t, output, "Fix pushed, waiting for CI...")
 	assert.Contains(t, output, "CI passed — PR ready for review")
 
@@ -901,6 +1055,247 @@ func TestRun_CIFix_SuccessOnSecondAttempt(t *testing.T) {
 	assert.Contains(t, logOutput, "fix: resolve lint CI failure")
 }
 
+// dirFixExecutor is a fixLoopExecutor that also implements DirExecutor,
+// writing its fix into the directory it is run in.
+type dirFixExecutor struct {
+	fixLoopExecutor
+	dirs []string
+}
+
+func (m *dirFixExecutor) RunInDir(ctx context.Context, dir string, w io.Writer, mt model.Type, args ...string) error {
+	m.dirs = append(m.dirs, dir)
+	m.dir = dir
+	return m.Run(ctx, w, mt, args...)
+}
+
+// setupCIFixBranch creates a feature branch with CI workflows, pushed to a
+// bare origin, and mocks gh so lint fails once and then passes.
+func setupCIFixBranch(t *testing.T) (dir, bareDir string) {
+	t.Helper()
+	dir = initGitRepo(t)
+	bareDir = initBareRemote(t, dir)
+
+	gitCmd(t, dir, "checkout", "-b", "feature-ci-fix")
+	addWorkflowFile(t, dir)
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-m", "add workflows")
+
+	chdir(t, dir)
+
+	mockGHWithCIFix(t, "main", "", "https://github.com/user/repo/pull/61",
+		[]string{
+			`[{"name":"lint","state":"FAILURE","conclusion":"failure"}]`,
+			`[{"name":"lint","state":"SUCCESS","conclusion":"success"}]`,
+		},
+		"12345", "Error: unused variable on line 10",
+	)
+	return dir, bareDir
+}
+
+func TestRun_CIFix_IsolatedWorktree(t *testing.T) {
+	dir, bareDir := setupCIFixBranch(t)
+
+	// Uncommitted user edit that the fix must not pick up.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wip.txt"), []byte("work in progress"), 0o600))
+
+	executor := &dirFixExecutor{fixLoopExecutor: fixLoopExecutor{dir: dir, prOutput: "Fix lint\n\nFixed the lint issue."}}
+
+	var buf bytes.Buffer
+	err := Run(context.Background(), Config{
+		Output:       &buf,
+		RemoteURL:    "https://github.com/user/repo.git",
+		IsGitHub:     true,
+		Executor:     executor,
+		RepoRoot:     dir,
+		PollInterval: time.Millisecond,
+		IsolateCIFix: true,
+	})
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Fixing in isolated worktree")
+	assert.Contains(t, output, "Fix pushed to origin/feature-ci-fix from the isolated worktree")
+	assert.Contains(t, output, "CI passed — PR ready for review")
+
+	require.Len(t, executor.dirs, 1)
+	assert.NotEqual(t, dir, executor.dirs[0])
+	assert.NoDirExists(t, executor.dirs[0], "worktree should be removed")
+
+	// The fix reached origin without touching the user's tree or branch.
+	assert.Contains(t, gitOutput(t, bareDir, "log", "--oneline", "feature-ci-fix"), "fix: resolve lint CI failure")
+	assert.NotContains(t, gitOutput(t, dir, "log", "--oneline"), "fix: resolve lint CI failure")
+	assert.Contains(t, gitOutput(t, dir, "status", "--porcelain"), "?? wip.txt")
+	assert.NotContains(t, gitOutput(t, dir, "worktree", "list"), executor.dirs[0])
+}
+
+// interruptingFixExecutor cancels the run from inside the isolated CI fix,
+// as Ctrl+C would, and records what the cleanup registry held at that point.
+type interruptingFixExecutor struct {
+	dirFixExecutor
+	cancel   context.CancelFunc
+	registry *cleanup.Registry
+	pending  []string
+}
+
+func (m *interruptingFixExecutor) RunInDir(ctx context.Context, dir string, _ io.Writer, _ model.Type, _ ...string) error {
+	m.dirs = append(m.dirs, dir)
+	m.pending = m.registry.Pending()
+	m.cancel()
+	return ctx.Err()
+}
+
+func TestRun_CIFix_IsolatedWorktreeRemovedOnInterrupt(t *testing.T) {
+	dir, _ := setupCIFixBranch(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	registry := &cleanup.Registry{}
+	executor := &interruptingFixExecutor{
+		dirFixExecutor: dirFixExecutor{fixLoopExecutor: fixLoopExecutor{dir: dir, prOutput: "Fix lint\n\nFixed the lint issue."}},
+		cancel:         cancel,
+		registry:       registry,
+	}
+
+	var buf bytes.Buffer
+	err := Run(ctx, Config{
+		Output:       &buf,
+		RemoteURL:    "https://github.com/user/repo.git",
+		IsGitHub:     true,
+		Executor:     executor,
+		RepoRoot:     dir,
+		PollInterval: time.Millisecond,
+		IsolateCIFix: true,
+		Cleanup:      registry,
+	})
+	require.Error(t, err)
+
+	require.Len(t, executor.dirs, 1)
+	assert.Len(t, executor.pending, 1, "worktree registered while the fix runs")
+	assert.Empty(t, registry.Pending(), "worktree released on the way out")
+	assert.NoDirExists(t, executor.dirs[0])
+	assert.NotContains(t, gitOutput(t, dir, "worktree", "list"), executor.dirs[0])
+}
+
+func TestRun_CIFix_IsolationFallsBackWithoutDirExecutor(t *testing.T) {
+	dir, _ := setupCIFixBranch(t)
+
+	executor := &fixLoopExecutor{dir: dir, prOutput: "Fix lint\n\nFixed the lint issue."}
+
+	var buf bytes.Buffer
+	err := Run(context.Background(), Config{
+		Output:       &buf,
+		RemoteURL:    "https://github.com/user/repo.git",
+		IsGitHub:     true,
+		Executor:     executor,
+		RepoRoot:     dir,
+		PollInterval: time.Millisecond,
+		IsolateCIFix: true,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "Isolated CI fix unavailable (provider cannot run in another directory)")
+	assert.Contains(t, gitOutput(t, dir, "log", "--oneline"), "fix: resolve lint CI failure")
+}
+
+// flakyFixExecutor is a fixLoopExecutor whose first failCalls CI fix calls
+// fail; a negative failCalls fails them all. PR generation always works.
+type flakyFixExecutor struct {
+	fixLoopExecutor
+	failCalls int
+	fixCalls  int
+}
+
+func (m *flakyFixExecutor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	if len(args) > 0 && !strings.Contains(args[0], "pull request") {
+		m.fixCalls++
+		if m.failCalls < 0 || m.fixCalls <= m.failCalls {
+			return errors.New("401 Unauthorized")
+		}
+	}
+	return m.fixLoopExecutor.Run(ctx, w, mt, args...)
+}
+
+// noFixRetryDelay retries failed CI fix calls immediately for the test.
+func noFixRetryDelay(t *testing.T) {
+	t.Helper()
+	prev := fixRetryDelay
+	fixRetryDelay = 0
+	t.Cleanup(func() { fixRetryDelay = prev })
+}
+
+func TestRun_CIFix_RetriesFailedProviderCall(t *testing.T) {
+	noFixRetryDelay(t)
+	dir, _ := setupCIFixBranch(t)
+
+	executor := &flakyFixExecutor{fixLoopExecutor: fixLoopExecutor{dir: dir, prOutput: "Fix\n\nBody."}, failCalls: 1}
+
+	var buf bytes.Buffer
+	err := Run(context.Background(), Config{
+		Output:       &buf,
+		RemoteURL:    "https://github.com/user/repo.git",
+		IsGitHub:     true,
+		Executor:     executor,
+		RepoRoot:     dir,
+		PollInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "CI fix call failed (401 Unauthorized), retrying")
+	assert.Contains(t, output, "CI passed — PR ready for review")
+	assert.Equal(t, 2, executor.fixCalls)
+	assert.Contains(t, gitOutput(t, dir, "log", "--oneline"), "fix: resolve lint CI failure")
+}
+
+func TestRun_CIFix_SwitchesToFallbackProvider(t *testing.T) {
+	noFixRetryDelay(t)
+	dir, _ := setupCIFixBranch(t)
+
+	primary := &flakyFixExecutor{fixLoopExecutor: fixLoopExecutor{prOutput: "Fix\n\nBody."}, failCalls: -1}
+	fallback := &fixLoopExecutor{dir: dir}
+
+	var buf bytes.Buffer
+	err := Run(context.Background(), Config{
+		Output:       &buf,
+		RemoteURL:    "https://github.com/user/repo.git",
+		IsGitHub:     true,
+		Executor:     primary,
+		FixFallbacks: []Fallback{{Name: "codex", Executor: fallback}},
+		RepoRoot:     dir,
+		PollInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Equal(t, 1+fixCallRetries, primary.fixCalls)
+	assert.Contains(t, output, "Switching CI fix to codex")
+	assert.Equal(t, 1, fallback.callNum)
+	assert.Contains(t, output, "CI passed — PR ready for review")
+}
+
+func TestRun_CIFix_FailedProviderCallCountsAsAttempt(t *testing.T) {
+	noFixRetryDelay(t)
+	dir, _ := setupCIFixBranch(t)
+
+	executor := &flakyFixExecutor{fixLoopExecutor: fixLoopExecutor{dir: dir, prOutput: "Fix\n\nBody."}, failCalls: -1}
+
+	var buf bytes.Buffer
+	err := Run(context.Background(), Config{
+		Output:       &buf,
+		RemoteURL:    "https://github.com/user/repo.git",
+		IsGitHub:     true,
+		Executor:     executor,
+		RepoRoot:     dir,
+		PollInterval: time.Millisecond,
+	})
+	require.NoError(t, err, "the fix loop keeps polling instead of aborting")
+
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "Fix attempt 1/10 failed: CI fix provider call failed: 401 Unauthorized")
+	assert.Contains(t, output, "CI passed — PR ready for review")
+	assert.NotContains(t, gitOutput(t, dir, "log", "--oneline"), "fix: resolve")
+}
+
 func TestRun_CIFix_MaxRetriesExhausted(t *testing.T) {
 	dir := initGitRepo(t)
 	initBareRemote(t, dir)
@@ -942,6 +1337,7 @@ func TestRun_CIFix_MaxRetriesExhausted(t *testing.T) {
 	err := Run(context.Background(), cfg)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "CI still failing after 10 attempts")
+	assert.ErrorIs(t, err, ErrCI)
 
 	output := buf.String()
 	assert.Contains(t, output, "CI still failing after 10 attempts")
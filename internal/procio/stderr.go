@@ -0,0 +1,34 @@
+// Package procio holds the pipe handling shared by the provider executors.
+package procio
+
+import (
+	"bytes"
+	"io"
+)
+
+// CollectStderr copies r into a buffer, and to w when it is non-nil, until EOF.
+// The returned channel is closed once the copy finishes.
+func CollectStderr(r io.Reader, w io.Writer) (*bytes.Buffer, <-chan struct{}) {
+	buf := &bytes.Buffer{}
+	dst := io.Writer(buf)
+	if w != nil {
+		dst = io.MultiWriter(buf, lenientWriter{w})
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		//nolint:errcheck // Best-effort: a read error just truncates the captured stderr.
+		_, _ = io.Copy(dst, r)
+	}()
+	return buf, done
+}
+
+// lenientWriter drops write errors so a failing display writer never stops
+// the stderr pipe from being drained.
+type lenientWriter struct{ w io.Writer }
+
+func (l lenientWriter) Write(p []byte) (int, error) {
+	//nolint:errcheck // Display is best-effort; the buffered copy is what errors report.
+	_, _ = l.w.Write(p)
+	return len(p), nil
+}
@@ -0,0 +1,40 @@
+package procio_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yarlson/snap/internal/procio"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("closed") }
+
+func TestCollectStderr_BuffersAndStreams(t *testing.T) {
+	var shown bytes.Buffer
+	buf, done := procio.CollectStderr(strings.NewReader("warning: slow\n"), &shown)
+	<-done
+
+	assert.Equal(t, "warning: slow\n", buf.String())
+	assert.Equal(t, "warning: slow\n", shown.String())
+}
+
+func TestCollectStderr_NilWriter(t *testing.T) {
+	buf, done := procio.CollectStderr(strings.NewReader("boom"), nil)
+	<-done
+
+	assert.Equal(t, "boom", buf.String())
+}
+
+func TestCollectStderr_FailingWriterKeepsDraining(t *testing.T) {
+	long := strings.Repeat("x", 64*1024)
+	buf, done := procio.CollectStderr(strings.NewReader(long), failingWriter{})
+	<-done
+
+	assert.Equal(t, long, buf.String())
+}
@@ -2,12 +2,14 @@ package provider
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/yarlson/snap/internal/claude"
 	"github.com/yarlson/snap/internal/codex"
+	"github.com/yarlson/snap/internal/model"
 	"github.com/yarlson/snap/internal/workflow"
 )
 
@@ -17,16 +19,77 @@ const (
 )
 
 // NewExecutorFromEnv creates an executor based on SNAP_PROVIDER.
+// The provider binary is looked up in PATH on every invocation.
 func NewExecutorFromEnv() (workflow.Executor, error) {
-	provider := normalize(os.Getenv(envVar))
+	return NewExecutor(normalize(os.Getenv(envVar)), "")
+}
+
+// Option configures an executor created by NewExecutor.
+type Option func(*options)
+
+type options struct {
+	models map[model.Type]string
+	env    map[string]string
+}
+
+// WithModel pins the concrete model name the provider uses for mt (e.g. a
+// cheaper fast model). An empty name keeps the provider's default.
+func WithModel(mt model.Type, name string) Option {
+	return func(o *options) {
+		if name == "" {
+			return
+		}
+		if o.models == nil {
+			o.models = make(map[model.Type]string)
+		}
+		o.models[mt] = name
+	}
+}
+
+// WithEnv sets environment variables on every provider CLI process, over
+// the inherited environment (e.g. an API endpoint or project ID).
+func WithEnv(env map[string]string) Option {
+	return func(o *options) {
+		if len(env) == 0 {
+			return
+		}
+		if o.env == nil {
+			o.env = make(map[string]string, len(env))
+		}
+		maps.Copy(o.env, env)
+	}
+}
+
+// NewExecutor creates an executor for the named provider that runs the binary
+// at binaryPath. An empty binaryPath falls back to a PATH lookup per invocation;
+// pass the result of ResolveCLI to resolve it once up front.
+func NewExecutor(providerName, binaryPath string, opts ...Option) (workflow.Executor, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-	switch provider {
+	switch providerName {
 	case "claude":
-		return claude.NewExecutor(), nil
+		claudeOpts := []claude.Option{claude.WithBinary(binaryPath)}
+		for mt, name := range o.models {
+			claudeOpts = append(claudeOpts, claude.WithModel(mt, name))
+		}
+		if len(o.env) > 0 {
+			claudeOpts = append(claudeOpts, claude.WithEnv(o.env))
+		}
+		return claude.NewExecutor(claudeOpts...), nil
 	case "codex":
-		return codex.NewExecutor(), nil
+		codexOpts := []codex.Option{codex.WithBinary(binaryPath)}
+		for mt, name := range o.models {
+			codexOpts = append(codexOpts, codex.WithModel(mt, name))
+		}
+		if len(o.env) > 0 {
+			codexOpts = append(codexOpts, codex.WithEnv(o.env))
+		}
+		return codex.NewExecutor(codexOpts...), nil
 	default:
-		return nil, fmt.Errorf("invalid %s value %q (supported: claude, codex)", envVar, provider)
+		return nil, fmt.Errorf("invalid %s value %q (supported: claude, codex)", envVar, providerName)
 	}
 }
 
@@ -54,19 +117,26 @@ var providers = map[string]providerInfo{
 
 // ValidateCLI checks that the provider's CLI binary exists in PATH.
 func ValidateCLI(providerName string) error {
+	_, err := ResolveCLI(providerName)
+	return err
+}
+
+// ResolveCLI looks up the provider's CLI binary in PATH and returns its path.
+func ResolveCLI(providerName string) (string, error) {
 	info, ok := providers[providerName]
 	if !ok {
-		return fmt.Errorf("unknown provider %q (supported: claude, codex)", providerName)
+		return "", fmt.Errorf("unknown provider %q (supported: claude, codex)", providerName)
 	}
 
-	if _, err := exec.LookPath(info.Binary); err != nil {
-		return fmt.Errorf( //nolint:staticcheck // ST1005: capitalized for user-facing DESIGN.md error format
+	path, err := exec.LookPath(info.Binary)
+	if err != nil {
+		return "", fmt.Errorf( //nolint:staticcheck // ST1005: capitalized for user-facing DESIGN.md error format
 			"Error: %s not found in PATH\n\nsnap requires the %s to run. Install it:\n  %s\n\nOr use a different provider:\n  SNAP_PROVIDER=%s snap",
 			info.Binary, info.DisplayName, info.InstallURL, info.Alternative,
 		)
 	}
 
-	return nil
+	return path, nil
 }
 
 // ValidateGH checks that the gh CLI binary exists in PATH.
@@ -84,6 +154,12 @@ func ResolveProviderName() string {
 	return normalize(os.Getenv(envVar))
 }
 
+// NormalizeName normalizes a provider name from any source (env, config file):
+// lowercased, "claude-code" mapped to "claude", empty mapped to the default.
+func NormalizeName(value string) string {
+	return normalize(value)
+}
+
 func normalize(value string) string {
 	normalized := strings.ToLower(strings.TrimSpace(value))
 	if normalized == "" {
@@ -1,9 +1,12 @@
 package provider
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,6 +14,8 @@ import (
 
 	"github.com/yarlson/snap/internal/claude"
 	"github.com/yarlson/snap/internal/codex"
+	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/ui"
 )
 
 func TestNewExecutorFromEnv(t *testing.T) {
@@ -35,6 +40,26 @@ func TestNewExecutorFromEnv_Invalid(t *testing.T) {
 	assert.Contains(t, err.Error(), envVar)
 }
 
+func TestNewExecutor_PinnedModels(t *testing.T) {
+	for _, name := range []string{"claude", "codex"} {
+		t.Run(name, func(t *testing.T) {
+			executor, err := NewExecutor(name, "",
+				WithModel(model.Fast, "cheap-model"),
+				WithModel(model.Thinking, ""),
+			)
+			require.NoError(t, err)
+
+			defaults, err := NewExecutor(name, "")
+			require.NoError(t, err)
+
+			namer := executor.(interface{ ModelName(model.Type) string })
+			defaultNamer := defaults.(interface{ ModelName(model.Type) string })
+			assert.Equal(t, "cheap-model", namer.ModelName(model.Fast))
+			assert.Equal(t, defaultNamer.ModelName(model.Thinking), namer.ModelName(model.Thinking), "empty name keeps the default")
+		})
+	}
+}
+
 func TestNormalize(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -91,6 +116,50 @@ func TestValidateCLI_BinaryExists(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestResolveCLI_ReturnsPath(t *testing.T) {
+	dir := t.TempDir()
+	binaryName := "codex"
+	if runtime.GOOS == "windows" {
+		binaryName = "codex.exe"
+	}
+	mockBin := filepath.Join(dir, binaryName)
+	require.NoError(t, os.WriteFile(mockBin, []byte("#!/bin/sh\n"), 0o755)) //nolint:gosec // G306: executable permission required for LookPath
+
+	t.Setenv("PATH", dir)
+
+	path, err := ResolveCLI("codex")
+	require.NoError(t, err)
+	assert.Equal(t, mockBin, path)
+}
+
+func TestNewExecutor_UsesResolvedBinary(t *testing.T) {
+	dir := t.TempDir()
+	mockBin := filepath.Join(dir, "claude")
+	script := "#!/bin/sh\necho '{\"type\":\"assistant\",\"message\":{\"content\":[{\"type\":\"text\",\"text\":\"from resolved path\"}]}}'\n"
+	require.NoError(t, os.WriteFile(mockBin, []byte(script), 0o755)) //nolint:gosec // G306: executable permission required for exec
+
+	// PATH no longer contains the binary; the resolved path must still be used.
+	t.Setenv("PATH", t.TempDir())
+
+	executor, err := NewExecutor("claude", mockBin)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	require.NoError(t, executor.Run(context.Background(), &out, model.Fast, "hi"))
+	assert.Contains(t, ui.StripColors(out.String()), "from resolved path")
+}
+
+func TestNewExecutor_BinaryRemovedMidRun(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "claude")
+
+	executor, err := NewExecutor("claude", missing)
+	require.NoError(t, err)
+
+	err = executor.Run(context.Background(), io.Discard, model.Fast, "hi")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no longer found at "+missing)
+}
+
 func TestValidateCLI_UnknownProvider(t *testing.T) {
 	err := ValidateCLI("unknown")
 	require.Error(t, err)
@@ -0,0 +1,63 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// metaFileName is the per-session metadata file inside the session directory.
+const metaFileName = "meta.json"
+
+// Meta holds per-session settings persisted in meta.json.
+type Meta struct {
+	Description string  `json:"description,omitempty"` // Set by snap new --desc
+	UI          UIPrefs `json:"ui"`
+}
+
+// UIPrefs are output preferences applied when running the session. The
+// matching snap run flags (--no-color, --ascii, --no-emoji, --width)
+// override them.
+type UIPrefs struct {
+	NoColor bool `json:"no_color,omitempty"` // Disable ANSI colors, as if NO_COLOR were set
+	ASCII   bool `json:"ascii,omitempty"`    // Draw ASCII symbols and box lines; implies NoEmoji
+	NoEmoji bool `json:"no_emoji,omitempty"` // Leave emoji out of headers and boxes
+	Width   int  `json:"width,omitempty"`    // Width of separators and boxes; 0 = default
+}
+
+// LoadMeta reads a session's meta.json. A missing file yields a zero Meta.
+func LoadMeta(projectRoot, name string) (Meta, error) {
+	return readMeta(Dir(projectRoot, name))
+}
+
+// readMeta reads meta.json from a session directory.
+func readMeta(sessionDir string) (Meta, error) {
+	var m Meta
+	path := filepath.Join(sessionDir, metaFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, fmt.Errorf("failed to read session meta: %w", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// SaveMeta writes a session's meta.json.
+func SaveMeta(projectRoot, name string, m Meta) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session meta: %w", err)
+	}
+	path := filepath.Join(Dir(projectRoot, name), metaFileName)
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write session meta: %w", err)
+	}
+	return nil
+}
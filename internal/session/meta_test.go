@@ -0,0 +1,56 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMeta_MissingFileIsZero(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+
+	m, err := LoadMeta(root, "auth")
+	require.NoError(t, err)
+	assert.Equal(t, Meta{}, m)
+}
+
+func TestSaveMeta_RoundTrip(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+
+	require.NoError(t, SaveMeta(root, "auth", Meta{UI: UIPrefs{NoColor: true}}))
+
+	data, err := os.ReadFile(filepath.Join(Dir(root, "auth"), "meta.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"no_color": true`)
+
+	m, err := LoadMeta(root, "auth")
+	require.NoError(t, err)
+	assert.True(t, m.UI.NoColor)
+}
+
+func TestLoadMeta_CorruptFile(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, os.WriteFile(filepath.Join(Dir(root, "auth"), "meta.json"), []byte("{not json"), 0o600))
+
+	_, err := LoadMeta(root, "auth")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "meta.json")
+}
+
+func TestCleanSession_KeepsMeta(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, SaveMeta(root, "auth", Meta{UI: UIPrefs{NoColor: true}}))
+
+	require.NoError(t, CleanSession(root, "auth"))
+
+	m, err := LoadMeta(root, "auth")
+	require.NoError(t, err)
+	assert.True(t, m.UI.NoColor, "preferences survive a re-plan")
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RunTarget is the workflow target ResolveForRun picks for snap run.
+type RunTarget struct {
+	Session     string // Session name; empty for the legacy layout
+	TasksDir    string // Tasks directory, relative to the project root
+	DisplayName string // Session name, or the tasks directory for the legacy layout
+}
+
+// Legacy reports whether the target is the pre-session layout (a tasks
+// directory and .snap/state.json at the project root).
+func (t RunTarget) Legacy() bool {
+	return t.Session == ""
+}
+
+// MultipleSessionsError is returned by ResolveForRun when no session name is
+// given and more than one session exists.
+type MultipleSessionsError struct {
+	Sessions []Info
+}
+
+func (e *MultipleSessionsError) Error() string {
+	return fmt.Sprintf("multiple sessions found (%d); specify one", len(e.Sessions))
+}
+
+// ResolveForRun decides which session snap run operates on:
+//
+//   - a named session, which must exist;
+//   - otherwise the only session, when exactly one exists;
+//   - with no sessions, the legacy layout when legacyTasksDir or
+//     .snap/state.json exists, else a newly created "default" session.
+//
+// More than one session without a name returns *MultipleSessionsError.
+func ResolveForRun(projectRoot, name, legacyTasksDir string) (RunTarget, error) {
+	if name != "" {
+		if _, err := Resolve(projectRoot, name); err != nil {
+			return RunTarget{}, err
+		}
+		return sessionTarget(projectRoot, name), nil
+	}
+
+	sessions, err := List(projectRoot)
+	if err != nil {
+		return RunTarget{}, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	switch len(sessions) {
+	case 0:
+		if hasLegacyLayout(projectRoot, legacyTasksDir) {
+			return RunTarget{TasksDir: legacyTasksDir, DisplayName: legacyTasksDir}, nil
+		}
+		if err := EnsureDefault(projectRoot); err != nil {
+			return RunTarget{}, err
+		}
+		return sessionTarget(projectRoot, "default"), nil
+	case 1:
+		return sessionTarget(projectRoot, sessions[0].Name), nil
+	default:
+		return RunTarget{}, &MultipleSessionsError{Sessions: sessions}
+	}
+}
+
+func sessionTarget(projectRoot, name string) RunTarget {
+	return RunTarget{Session: name, TasksDir: TasksDir(projectRoot, name), DisplayName: name}
+}
+
+// hasLegacyLayout reports whether the project has legacy workflow state or
+// the legacy tasks directory.
+func hasLegacyLayout(projectRoot, legacyTasksDir string) bool {
+	if _, err := os.Stat(filepath.Join(projectRoot, ".snap", "state.json")); err == nil {
+		return true
+	}
+	if legacyTasksDir == "" {
+		return false
+	}
+	dir := legacyTasksDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(projectRoot, dir)
+	}
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
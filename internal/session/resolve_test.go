@@ -0,0 +1,84 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveForRun_NamedSession(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+
+	target, err := ResolveForRun(root, "auth", "docs/tasks")
+	require.NoError(t, err)
+	assert.Equal(t, RunTarget{Session: "auth", TasksDir: TasksDir(root, "auth"), DisplayName: "auth"}, target)
+	assert.False(t, target.Legacy())
+}
+
+func TestResolveForRun_NamedSessionMissing(t *testing.T) {
+	root := t.TempDir()
+
+	_, err := ResolveForRun(root, "nope", "docs/tasks")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "snap new nope")
+}
+
+func TestResolveForRun_SingleSession(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "api"))
+	// A legacy tasks directory does not win over an existing session.
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs", "tasks"), 0o755))
+
+	target, err := ResolveForRun(root, "", "docs/tasks")
+	require.NoError(t, err)
+	assert.Equal(t, "api", target.Session)
+}
+
+func TestResolveForRun_MultipleSessions(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "api"))
+	require.NoError(t, Create(root, "auth"))
+
+	_, err := ResolveForRun(root, "", "docs/tasks")
+	var multi *MultipleSessionsError
+	require.ErrorAs(t, err, &multi)
+	require.Len(t, multi.Sessions, 2)
+	assert.Equal(t, "api", multi.Sessions[0].Name)
+	assert.Equal(t, "auth", multi.Sessions[1].Name)
+}
+
+func TestResolveForRun_LegacyTasksDir(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs", "tasks"), 0o755))
+
+	target, err := ResolveForRun(root, "", "docs/tasks")
+	require.NoError(t, err)
+	assert.True(t, target.Legacy())
+	assert.Equal(t, "docs/tasks", target.TasksDir)
+	assert.Equal(t, "docs/tasks", target.DisplayName)
+	assert.False(t, Exists(root, "default"), "legacy layout must not create a default session")
+}
+
+func TestResolveForRun_LegacyStateFile(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".snap"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".snap", "state.json"), []byte("{}"), 0o600))
+
+	target, err := ResolveForRun(root, "", "custom/tasks")
+	require.NoError(t, err)
+	assert.True(t, target.Legacy())
+	assert.Equal(t, "custom/tasks", target.TasksDir)
+}
+
+func TestResolveForRun_CreatesDefault(t *testing.T) {
+	root := t.TempDir()
+
+	target, err := ResolveForRun(root, "", "docs/tasks")
+	require.NoError(t, err)
+	assert.Equal(t, "default", target.Session)
+	assert.True(t, Exists(root, "default"))
+}
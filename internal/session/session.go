@@ -8,6 +8,9 @@ import (
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
+
+	"github.com/yarlson/snap/internal/state"
 )
 
 var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
@@ -28,6 +31,12 @@ func Dir(projectRoot, name string) string {
 	return filepath.Join(projectRoot, ".snap", "sessions", name)
 }
 
+// MemoryDir returns the path to a session's own memory vault, used instead
+// of docs/context/ when memory is kept per session.
+func MemoryDir(projectRoot, name string) string {
+	return filepath.Join(Dir(projectRoot, name), "memory")
+}
+
 // TasksDir returns the path to a session's tasks directory.
 func TasksDir(projectRoot, name string) string {
 	return filepath.Join(Dir(projectRoot, name), "tasks")
@@ -39,15 +48,38 @@ func Exists(projectRoot, name string) bool {
 	return err == nil && info.IsDir()
 }
 
-// Create validates the session name and creates the session directory structure.
-func Create(projectRoot, name string) error {
+// CreateOption configures a session created by Create.
+type CreateOption func(*Meta)
+
+// WithDescription stores a short description of the session in its
+// meta.json; snap list shows it next to the name.
+func WithDescription(description string) CreateOption {
+	return func(m *Meta) {
+		m.Description = strings.TrimSpace(description)
+	}
+}
+
+// Create validates the session name and creates the session directory
+// structure. meta.json is only written when an option sets something in it.
+func Create(projectRoot, name string, opts ...CreateOption) error {
 	if err := ValidateName(name); err != nil {
 		return err
 	}
 	if Exists(projectRoot, name) {
 		return fmt.Errorf("session '%s' already exists", name)
 	}
-	return os.MkdirAll(TasksDir(projectRoot, name), 0o755)
+
+	var meta Meta
+	for _, opt := range opts {
+		opt(&meta)
+	}
+	if err := os.MkdirAll(TasksDir(projectRoot, name), 0o755); err != nil {
+		return err
+	}
+	if meta == (Meta{}) {
+		return nil
+	}
+	return SaveMeta(projectRoot, name, meta)
 }
 
 // EnsureDefault creates a "default" session if it does not already exist.
@@ -59,12 +91,15 @@ func EnsureDefault(projectRoot string) error {
 	return Create(projectRoot, "default")
 }
 
-// Info describes a session's name, task counts, and derived status.
+// Info describes a session's name, task counts, and derived status. Its
+// JSON field names are stable: snap list --json prints them for scripts.
 type Info struct {
-	Name           string
-	TaskCount      int
-	CompletedCount int
-	Status         string
+	Name               string `json:"name"`
+	TaskCount          int    `json:"task_count"`
+	CompletedCount     int    `json:"completed_count"`
+	Status             string `json:"status"`                        // e.g. "planning", "paused at step 3", "complete"
+	Description        string `json:"description,omitempty"`         // Generated one-line description of the active task, if any
+	SessionDescription string `json:"session_description,omitempty"` // Session description from meta.json, if any
 }
 
 // sessionsDir returns the path to the sessions root directory.
@@ -72,6 +107,18 @@ func sessionsDir(projectRoot string) string {
 	return filepath.Join(projectRoot, ".snap", "sessions")
 }
 
+// archiveDir returns the path to the archived sessions root directory. It
+// sits beside .snap/sessions/, so List, Exists and Resolve never see
+// archived sessions.
+func archiveDir(projectRoot string) string {
+	return filepath.Join(projectRoot, ".snap", "archive")
+}
+
+// ArchivedDir returns the path to an archived session's directory.
+func ArchivedDir(projectRoot, name string) string {
+	return filepath.Join(archiveDir(projectRoot), name)
+}
+
 // taskFileRegex matches TASK<n>.md filenames (uppercase only).
 var taskFileRegex = regexp.MustCompile(`^TASK\d+\.md$`)
 
@@ -79,8 +126,19 @@ var taskFileRegex = regexp.MustCompile(`^TASK\d+\.md$`)
 var statusTaskRegex = regexp.MustCompile(`^TASK(\d+)\.md$`)
 
 // List scans .snap/sessions/ and returns info for each session, sorted by name.
+// Archived sessions are not included.
 func List(projectRoot string) ([]Info, error) {
-	dir := sessionsDir(projectRoot)
+	return listDir(sessionsDir(projectRoot))
+}
+
+// ListArchived scans .snap/archive/ and returns info for each archived
+// session, sorted by name.
+func ListArchived(projectRoot string) ([]Info, error) {
+	return listDir(archiveDir(projectRoot))
+}
+
+// listDir returns info for each session directory in dir, sorted by name.
+func listDir(dir string) ([]Info, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -118,11 +176,19 @@ func List(projectRoot string) ([]Info, error) {
 			if json.Unmarshal(stateData, &parsed) == nil {
 				st = &parsed
 				info.CompletedCount = len(parsed.CompletedTaskIDs)
+				if parsed.CurrentTaskID != "" {
+					info.Description = parsed.TaskDescription
+				}
 			} else {
 				stateCorrupt = true
 			}
 		}
 
+		// A missing or unreadable meta.json just leaves the description empty.
+		if meta, err := readMeta(sessionPath); err == nil {
+			info.SessionDescription = meta.Description
+		}
+
 		// Check for .plan-started marker.
 		planMarkerPath := filepath.Join(sessionPath, ".plan-started")
 		_, planErr := os.Stat(planMarkerPath)
@@ -144,10 +210,44 @@ func List(projectRoot string) ([]Info, error) {
 // sessionState is a minimal struct to read state.json fields needed for status derivation.
 // Note: Keep fields in sync with internal/state/types.go State struct.
 type sessionState struct {
-	CurrentTaskID    string   `json:"current_task_id"`
-	CurrentStep      int      `json:"current_step"`
-	TotalSteps       int      `json:"total_steps"`
-	CompletedTaskIDs []string `json:"completed_task_ids"`
+	PID              int                   `json:"pid"`
+	CurrentTaskID    string                `json:"current_task_id"`
+	TaskDescription  string                `json:"task_description"`
+	CurrentStep      int                   `json:"current_step"`
+	TotalSteps       int                   `json:"total_steps"`
+	SkippedSteps     []string              `json:"skipped_steps"`
+	CompletedTaskIDs []string              `json:"completed_task_ids"`
+	CompletedTasks   map[string]taskRecord `json:"completed_tasks"`
+}
+
+// taskRecord mirrors the completion fields of state.TaskRecord.
+type taskRecord struct {
+	Provider   string   `json:"provider"`
+	Model      string   `json:"model"`
+	Directives []string `json:"directives"`
+	Skipped    bool     `json:"skipped"`
+}
+
+// readHistory reads the latest completion record per task from the
+// session's history.jsonl (see state.HistoryFile), which keeps the records
+// after a finished run resets state.json. A missing file yields nil.
+func readHistory(sessionDir string) map[string]taskRecord {
+	data, err := os.ReadFile(filepath.Join(sessionDir, "history.jsonl"))
+	if err != nil {
+		return nil
+	}
+	records := make(map[string]taskRecord)
+	for _, line := range strings.Split(string(data), "\n") {
+		var entry struct {
+			TaskID string `json:"task_id"`
+			taskRecord
+		}
+		if json.Unmarshal([]byte(line), &entry) != nil || entry.TaskID == "" {
+			continue
+		}
+		records[entry.TaskID] = entry.taskRecord
+	}
+	return records
 }
 
 func deriveStatus(taskCount int, st *sessionState, stateCorrupt, hasPlanning bool) string {
@@ -262,18 +362,27 @@ func CleanSession(projectRoot, name string) error {
 
 // TaskStatus describes one task file's completion state.
 type TaskStatus struct {
-	ID        string
-	Completed bool
+	ID        string `json:"id"`
+	Completed bool   `json:"completed"`
+	Provider  string `json:"provider,omitempty"` // Provider that completed the task (empty if unrecorded)
+	Model     string `json:"model,omitempty"`    // Model that implemented the task (empty if unrecorded)
+	// Directives are the queued directives applied while the task ran.
+	Directives []string `json:"directives,omitempty"`
+	Skipped    bool     `json:"skipped,omitempty"` // Skipped with the /skip directive before its steps finished
 }
 
-// StatusInfo holds detailed information about a session.
+// StatusInfo holds detailed information about a session. JSON field names
+// follow the state file's.
 type StatusInfo struct {
-	Name       string
-	TasksDir   string
-	Tasks      []TaskStatus
-	ActiveTask string
-	ActiveStep int
-	TotalSteps int
+	Name       string       `json:"name"`
+	TasksDir   string       `json:"tasks_dir"`
+	Tasks      []TaskStatus `json:"tasks"`
+	ActiveTask string       `json:"current_task_id,omitempty"`
+	ActiveStep int          `json:"current_step"`
+	TotalSteps int          `json:"total_steps"`
+
+	// SkippedSteps names the steps the run left out (--skip-step).
+	SkippedSteps []string `json:"skipped_steps,omitempty"`
 }
 
 // Status returns detailed status for a named session.
@@ -337,17 +446,28 @@ func Status(projectRoot, name string) (*StatusInfo, error) {
 		TasksDir: td,
 	}
 
+	history := readHistory(sessionDir)
 	for _, t := range tasks {
-		result.Tasks = append(result.Tasks, TaskStatus{
+		ts := TaskStatus{
 			ID:        t.id,
 			Completed: completedSet[t.id],
-		})
+		}
+		rec, ok := taskRecord{}, false
+		if st != nil {
+			rec, ok = st.CompletedTasks[t.id]
+		}
+		if !ok {
+			rec = history[t.id]
+		}
+		ts.Provider, ts.Model, ts.Directives, ts.Skipped = rec.Provider, rec.Model, rec.Directives, rec.Skipped
+		result.Tasks = append(result.Tasks, ts)
 	}
 
 	if st != nil && st.CurrentTaskID != "" {
 		result.ActiveTask = st.CurrentTaskID
 		result.ActiveStep = st.CurrentStep
 		result.TotalSteps = st.TotalSteps
+		result.SkippedSteps = st.SkippedSteps
 	}
 
 	return result, nil
@@ -363,3 +483,176 @@ func Delete(projectRoot, name string) error {
 	}
 	return os.RemoveAll(Dir(projectRoot, name))
 }
+
+// Archive moves a session to .snap/archive/, out of the active list. Its
+// files, state and plan history are kept; Unarchive brings it back. A
+// session that a live process is running, or that has a task in progress,
+// is only archived with force.
+func Archive(projectRoot, name string, force bool) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if !Exists(projectRoot, name) {
+		return fmt.Errorf("session '%s' not found", name)
+	}
+	if archived(projectRoot, name) {
+		return fmt.Errorf("session '%s' is already archived (run snap unarchive %s or delete the archived copy first)", name, name)
+	}
+	if !force {
+		if pid := liveRun(Dir(projectRoot, name)); pid != 0 {
+			return fmt.Errorf("session '%s' is being run by process %d; stop the run first, or use --force", name, pid)
+		}
+		if st := readState(Dir(projectRoot, name)); st != nil && st.CurrentTaskID != "" {
+			return fmt.Errorf("session '%s' has %s in progress; finish it with snap resume %s, or use --force", name, st.CurrentTaskID, name)
+		}
+	}
+	if err := os.MkdirAll(archiveDir(projectRoot), 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	if err := os.Rename(Dir(projectRoot, name), ArchivedDir(projectRoot, name)); err != nil {
+		return fmt.Errorf("failed to archive session: %w", err)
+	}
+	return nil
+}
+
+// Unarchive moves an archived session back to .snap/sessions/.
+func Unarchive(projectRoot, name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if !archived(projectRoot, name) {
+		return fmt.Errorf("archived session '%s' not found", name)
+	}
+	if Exists(projectRoot, name) {
+		return fmt.Errorf("session '%s' already exists", name)
+	}
+	if err := os.MkdirAll(sessionsDir(projectRoot), 0o755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	if err := os.Rename(ArchivedDir(projectRoot, name), Dir(projectRoot, name)); err != nil {
+		return fmt.Errorf("failed to unarchive session: %w", err)
+	}
+	return nil
+}
+
+// readState reads a session's state.json. A missing or unreadable file
+// yields nil.
+func readState(sessionDir string) *sessionState {
+	data, err := os.ReadFile(filepath.Join(sessionDir, "state.json"))
+	if err != nil {
+		return nil
+	}
+	var st sessionState
+	if json.Unmarshal(data, &st) != nil {
+		return nil
+	}
+	return &st
+}
+
+// liveRun returns the PID of the process running a session, or 0 when no
+// run is active: no PID is recorded or its process is gone.
+func liveRun(sessionDir string) int {
+	st := readState(sessionDir)
+	if st == nil || st.PID == 0 || !state.ProcessAlive(st.PID) {
+		return 0
+	}
+	return st.PID
+}
+
+// archived reports whether an archived session directory exists.
+func archived(projectRoot, name string) bool {
+	info, err := os.Stat(ArchivedDir(projectRoot, name))
+	return err == nil && info.IsDir()
+}
+
+// Rename moves a session's directory to a new name. The tasks_dir and
+// prd_path fields of its state.json are rewritten when they point inside the
+// old session directory, so the session resumes where it left off.
+func Rename(projectRoot, oldName, newName string) error {
+	if err := ValidateName(oldName); err != nil {
+		return err
+	}
+	if err := ValidateName(newName); err != nil {
+		return err
+	}
+	if !Exists(projectRoot, oldName) {
+		return fmt.Errorf("session '%s' not found", oldName)
+	}
+	if Exists(projectRoot, newName) {
+		return fmt.Errorf("session '%s' already exists", newName)
+	}
+	if archived(projectRoot, newName) {
+		return fmt.Errorf("an archived session named '%s' exists (run snap unarchive %s or pick another name)", newName, newName)
+	}
+	// The run would keep writing state.json to the old path.
+	if pid := liveRun(Dir(projectRoot, oldName)); pid != 0 {
+		return fmt.Errorf("session '%s' is being run by process %d; stop the run before renaming it", oldName, pid)
+	}
+	if err := os.Rename(Dir(projectRoot, oldName), Dir(projectRoot, newName)); err != nil {
+		return fmt.Errorf("failed to rename session: %w", err)
+	}
+	if err := renameStatePaths(filepath.Join(Dir(projectRoot, newName), "state.json"), oldName, newName); err != nil {
+		return fmt.Errorf("renamed session '%s' to '%s', but %w", oldName, newName, err)
+	}
+	return nil
+}
+
+// renameStatePaths rewrites the session paths stored in a state.json after a
+// rename. Other fields are kept as they are; a missing file is not an error.
+func renameStatePaths(path, oldName, newName string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state.json: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to parse state.json: %w", err)
+	}
+	changed := false
+	for _, key := range []string{"tasks_dir", "prd_path"} {
+		var value string
+		if raw, ok := fields[key]; !ok || json.Unmarshal(raw, &value) != nil {
+			continue
+		}
+		renamed := renameSessionPath(value, oldName, newName)
+		if renamed == value {
+			continue
+		}
+		encoded, err := json.Marshal(renamed)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		fields[key] = encoded
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	data, err = json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state.json: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write state.json: %w", err)
+	}
+	return nil
+}
+
+// renameSessionPath replaces the session name in a path that runs through
+// .snap/sessions/<oldName>, relative or absolute. Other paths are returned
+// unchanged.
+func renameSessionPath(path, oldName, newName string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i := 0; i+2 < len(parts); i++ {
+		if parts[i] == ".snap" && parts[i+1] == "sessions" && parts[i+2] == oldName {
+			parts[i+2] = newName
+			return filepath.FromSlash(strings.Join(parts, "/"))
+		}
+	}
+	return path
+}
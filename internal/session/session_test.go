@@ -1,6 +1,8 @@
 package session
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -65,6 +67,42 @@ func TestCreate_Success(t *testing.T) {
 	assert.True(t, info.IsDir())
 }
 
+func TestCreate_WithDescription(t *testing.T) {
+	root := t.TempDir()
+
+	require.NoError(t, Create(root, "auth", WithDescription("  Login and token refresh\n")))
+
+	m, err := LoadMeta(root, "auth")
+	require.NoError(t, err)
+	assert.Equal(t, "Login and token refresh", m.Description)
+
+	sessions, err := List(root)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "Login and token refresh", sessions[0].SessionDescription)
+}
+
+func TestCreate_WithoutOptionsWritesNoMeta(t *testing.T) {
+	root := t.TempDir()
+
+	require.NoError(t, Create(root, "auth", WithDescription("   ")))
+
+	_, err := os.Stat(filepath.Join(Dir(root, "auth"), "meta.json"))
+	assert.True(t, os.IsNotExist(err), "a blank description leaves no meta.json behind")
+}
+
+func TestList_CorruptMetaLeavesDescriptionEmpty(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, os.WriteFile(filepath.Join(Dir(root, "auth"), "meta.json"), []byte("{not json"), 0o600))
+
+	sessions, err := List(root)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Empty(t, sessions[0].SessionDescription)
+	assert.Equal(t, "no tasks", sessions[0].Status)
+}
+
 func TestCreate_DuplicateReturnsError(t *testing.T) {
 	root := t.TempDir()
 
@@ -124,6 +162,10 @@ func TestTasksDir(t *testing.T) {
 	assert.Equal(t, filepath.Join(root, ".snap", "sessions", "auth", "tasks"), got)
 }
 
+func TestMemoryDir(t *testing.T) {
+	assert.Equal(t, filepath.Join(".snap", "sessions", "auth", "memory"), MemoryDir("", "auth"))
+}
+
 // --- Integration tests: List ---
 
 func TestList_ZeroSessions(t *testing.T) {
@@ -164,6 +206,7 @@ func TestList_TaskCountsAndCompleted(t *testing.T) {
 		"tasks_dir": "tasks",
 		"current_task_id": "TASK2",
 		"current_task_file": "TASK2.md",
+		"task_description": "Add the login endpoint",
 		"current_step": 5,
 		"total_steps": 10,
 		"completed_task_ids": ["TASK1"],
@@ -182,6 +225,8 @@ func TestList_TaskCountsAndCompleted(t *testing.T) {
 	assert.Equal(t, 2, sessions[0].TaskCount)
 	assert.Equal(t, 1, sessions[0].CompletedCount)
 	assert.Equal(t, "paused at step 5", sessions[0].Status)
+	assert.Equal(t, "Add the login endpoint", sessions[0].Description)
+	assert.Empty(t, sessions[0].SessionDescription, "no meta.json, no session description")
 }
 
 func TestList_CorruptStateJSON(t *testing.T) {
@@ -680,3 +725,243 @@ func TestStatus_MissingStateJSON(t *testing.T) {
 	assert.Empty(t, st.ActiveTask)
 	assert.Equal(t, 0, st.ActiveStep)
 }
+
+func TestStatus_RecordsFromHistory(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+
+	td := TasksDir(root, "auth")
+	require.NoError(t, os.WriteFile(filepath.Join(td, "TASK1.md"), []byte("# Task 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(td, "TASK2.md"), []byte("# Task 2\n"), 0o600))
+
+	// The run finished and reset state.json; history.jsonl kept the records.
+	history := `{"task_id":"TASK1","completed_at":"2025-01-01T00:00:00Z","provider":"claude","model":"sonnet"}
+{"task_id":"TASK1","completed_at":"2025-01-02T00:00:00Z","provider":"claude","model":"opus"}
+{"task_id":"TASK2","completed_at":"2025-01-02T00:00:00Z","skipped":true}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(Dir(root, "auth"), "history.jsonl"), []byte(history), 0o600))
+
+	st, err := Status(root, "auth")
+	require.NoError(t, err)
+
+	require.Len(t, st.Tasks, 2)
+	assert.Equal(t, "claude", st.Tasks[0].Provider)
+	assert.Equal(t, "opus", st.Tasks[0].Model, "the latest record wins")
+	assert.True(t, st.Tasks[1].Skipped)
+}
+
+// --- Integration tests: Rename ---
+
+func TestRename_Success(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, os.WriteFile(filepath.Join(TasksDir(root, "auth"), "TASK1.md"), []byte("# Task 1\n"), 0o600))
+
+	require.NoError(t, Rename(root, "auth", "login"))
+	assert.False(t, Exists(root, "auth"))
+	assert.True(t, Exists(root, "login"))
+	assert.FileExists(t, filepath.Join(TasksDir(root, "login"), "TASK1.md"))
+}
+
+func TestRename_RewritesStatePaths(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	absTasks := TasksDir(root, "auth")
+	state := `{
+  "tasks_dir": "` + filepath.ToSlash(absTasks) + `",
+  "prd_path": ".snap/sessions/auth/tasks/PRD.md",
+  "current_step": 4,
+  "completed_task_ids": ["TASK1"]
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(Dir(root, "auth"), "state.json"), []byte(state), 0o600))
+
+	require.NoError(t, Rename(root, "auth", "login"))
+
+	data, err := os.ReadFile(filepath.Join(Dir(root, "login"), "state.json"))
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, TasksDir(root, "login"), got["tasks_dir"])
+	assert.Equal(t, filepath.Join(".snap", "sessions", "login", "tasks", "PRD.md"), got["prd_path"])
+	assert.EqualValues(t, 4, got["current_step"], "other fields are kept")
+	assert.Equal(t, []any{"TASK1"}, got["completed_task_ids"])
+}
+
+func TestRename_KeepsOutsidePaths(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	state := `{"tasks_dir": "docs/auth/tasks", "prd_path": "docs/auth/PRD.md"}`
+	statePath := filepath.Join(Dir(root, "auth"), "state.json")
+	require.NoError(t, os.WriteFile(statePath, []byte(state), 0o600))
+
+	require.NoError(t, Rename(root, "auth", "login"))
+
+	data, err := os.ReadFile(filepath.Join(Dir(root, "login"), "state.json"))
+	require.NoError(t, err)
+	assert.Equal(t, state, string(data), "state.json is left untouched")
+}
+
+func TestRename_TargetExists(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, Create(root, "login"))
+
+	err := Rename(root, "auth", "login")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+	assert.True(t, Exists(root, "auth"))
+}
+
+func TestRename_ArchivedTargetExists(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "login"))
+	require.NoError(t, Archive(root, "login", false))
+	require.NoError(t, Create(root, "auth"))
+
+	err := Rename(root, "auth", "login")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "an archived session named 'login' exists")
+	assert.True(t, Exists(root, "auth"))
+}
+
+func TestRename_LiveRun(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	statePath := filepath.Join(Dir(root, "auth"), "state.json")
+	require.NoError(t, os.WriteFile(statePath, []byte(fmt.Sprintf(`{"pid": %d}`, os.Getpid())), 0o600))
+
+	err := Rename(root, "auth", "login")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("session 'auth' is being run by process %d", os.Getpid()))
+	assert.True(t, Exists(root, "auth"))
+
+	// A PID left by a run that is gone doesn't block the rename.
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"pid": 999999999}`), 0o600))
+	require.NoError(t, Rename(root, "auth", "login"))
+}
+
+func TestRename_Nonexistent(t *testing.T) {
+	root := t.TempDir()
+
+	err := Rename(root, "auth", "login")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestRename_PathTraversal(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+
+	for _, names := range [][2]string{
+		{"../../..", "login"},
+		{"..", "login"},
+		{"auth", "../../escape"},
+		{"auth", ".."},
+	} {
+		err := Rename(root, names[0], names[1])
+		require.Error(t, err, "%s -> %s", names[0], names[1])
+		assert.Contains(t, err.Error(), "invalid session name")
+	}
+	assert.True(t, Exists(root, "auth"), "the session is not moved")
+}
+
+// --- Integration tests: Archive ---
+
+func TestArchive_MovesOutOfActiveList(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, Create(root, "api"))
+	require.NoError(t, os.WriteFile(filepath.Join(TasksDir(root, "auth"), "TASK1.md"), []byte("# Task 1\n"), 0o600))
+
+	require.NoError(t, Archive(root, "auth", false))
+
+	assert.False(t, Exists(root, "auth"))
+	_, err := Resolve(root, "auth")
+	require.Error(t, err, "archived sessions don't resolve")
+	assert.FileExists(t, filepath.Join(ArchivedDir(root, "auth"), "tasks", "TASK1.md"))
+
+	sessions, err := List(root)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "api", sessions[0].Name)
+
+	archived, err := ListArchived(root)
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	assert.Equal(t, Info{Name: "auth", TaskCount: 1, Status: "idle"}, archived[0])
+}
+
+func TestArchive_Errors(t *testing.T) {
+	root := t.TempDir()
+
+	err := Archive(root, "auth", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	err = Archive(root, "../sessions", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid session name")
+
+	// A second session with an archived name can't be archived over it.
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, Archive(root, "auth", false))
+	require.NoError(t, Create(root, "auth"))
+	err = Archive(root, "auth", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already archived")
+	assert.True(t, Exists(root, "auth"))
+}
+
+func TestArchive_RefusesUnfinishedSessions(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	statePath := filepath.Join(Dir(root, "auth"), "state.json")
+
+	require.NoError(t, os.WriteFile(statePath, []byte(fmt.Sprintf(`{"pid": %d}`, os.Getpid())), 0o600))
+	err := Archive(root, "auth", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("session 'auth' is being run by process %d", os.Getpid()))
+
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"pid": 999999999, "current_task_id": "TASK2"}`), 0o600))
+	err = Archive(root, "auth", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session 'auth' has TASK2 in progress; finish it with snap resume auth, or use --force")
+	assert.True(t, Exists(root, "auth"))
+
+	require.NoError(t, Archive(root, "auth", true))
+	assert.DirExists(t, ArchivedDir(root, "auth"))
+}
+
+func TestUnarchive(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, os.WriteFile(filepath.Join(Dir(root, "auth"), "state.json"), []byte(`{"completed_task_ids": ["TASK1"]}`), 0o600))
+	require.NoError(t, Archive(root, "auth", false))
+
+	require.NoError(t, Unarchive(root, "auth"))
+	assert.True(t, Exists(root, "auth"))
+	assert.FileExists(t, filepath.Join(Dir(root, "auth"), "state.json"))
+	archived, err := ListArchived(root)
+	require.NoError(t, err)
+	assert.Empty(t, archived)
+
+	err = Unarchive(root, "auth")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "archived session 'auth' not found")
+
+	err = Unarchive(root, "..")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid session name")
+}
+
+func TestUnarchive_ActiveSessionExists(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, Create(root, "auth"))
+	require.NoError(t, Archive(root, "auth", false))
+	require.NoError(t, Create(root, "auth"))
+
+	err := Unarchive(root, "auth")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+	assert.DirExists(t, ArchivedDir(root, "auth"))
+}
@@ -4,18 +4,107 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/yarlson/snap/internal/vcs"
 )
 
+// Label identifies the workflow step a snapshot was captured after.
+// Its String form is the stash message, so snapshots stay readable in
+// plain `git stash list` output while remaining parseable by ParseLabel.
+type Label struct {
+	TaskID string
+	Step   int
+	Total  int
+	Name   string
+}
+
+var labelPattern = regexp.MustCompile(`^snap: (.+) step (\d+)/(\d+) — (.+)$`)
+
+// ErrSnapshotNotFound is returned by Restore for a ref that is not a snap
+// snapshot in the stash.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// stashRefPattern matches a stash reflog ref such as "stash@{3}".
+var stashRefPattern = regexp.MustCompile(`^stash@\{(\d+)\}$`)
+
+// String formats the label as a stash message, e.g. "snap: TASK2 step 3/10 — Lint & test".
+func (l Label) String() string {
+	return fmt.Sprintf("snap: %s step %d/%d — %s", l.TaskID, l.Step, l.Total, l.Name)
+}
+
+// ParseLabel parses a stash message produced by Label.String.
+// Returns false for messages that were not created by snap.
+func ParseLabel(message string) (Label, bool) {
+	m := labelPattern.FindStringSubmatch(message)
+	if m == nil {
+		return Label{}, false
+	}
+	step, err := strconv.Atoi(m[2])
+	if err != nil {
+		return Label{}, false
+	}
+	total, err := strconv.Atoi(m[3])
+	if err != nil {
+		return Label{}, false
+	}
+	return Label{TaskID: m[1], Step: step, Total: total, Name: m[4]}, true
+}
+
+// Entry is a snap snapshot stored in the stash reflog.
+type Entry struct {
+	Ref   string // Stash reference, e.g. "stash@{0}"
+	Time  time.Time
+	Label Label
+}
+
+// Filter narrows List results. Zero-valued fields match everything.
+type Filter struct {
+	TaskID string    // Only snapshots for this task
+	Since  time.Time // Only snapshots created at or after this time
+	Until  time.Time // Only snapshots created at or before this time
+}
+
+func (f Filter) matches(e Entry) bool {
+	if f.TaskID != "" && e.Label.TaskID != f.TaskID {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
 // Snapshotter creates non-disruptive git stash snapshots.
 type Snapshotter struct {
-	dir string
+	runner vcs.Runner
 }
 
 // New creates a Snapshotter for the given directory.
 func New(dir string) *Snapshotter {
-	return &Snapshotter{dir: dir}
+	return &Snapshotter{runner: vcs.Git(dir)}
+}
+
+// NewWithRunner creates a Snapshotter that issues git commands through r.
+// Useful for testing without a real repository.
+func NewWithRunner(r vcs.Runner) *Snapshotter {
+	return &Snapshotter{runner: r}
+}
+
+// Git returns the runner the Snapshotter issues git commands through, for
+// callers that run other git helpers against the same working tree.
+func (s *Snapshotter) Git() vcs.Runner {
+	return s.runner
 }
 
 // Capture creates a stash snapshot with the given message.
@@ -65,6 +154,492 @@ func (s *Snapshotter) Capture(ctx context.Context, message string) (bool, error)
 	return true, nil
 }
 
+// List returns snap snapshots matching the filter, newest first.
+// Stash entries not created by snap are skipped.
+func (s *Snapshotter) List(ctx context.Context, f Filter) ([]Entry, error) {
+	out, err := s.gitOutput(ctx, "stash", "list", "--format=%gd%x1f%ct%x1f%gs")
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+
+	var entries []Entry
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		label, ok := ParseLabel(fields[2])
+		if !ok {
+			continue
+		}
+		unix, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		e := Entry{Ref: fields[0], Time: time.Unix(unix, 0), Label: label}
+		if f.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// Drop removes the given snapshots from the stash. Entries are dropped from
+// the highest stash index down so earlier refs stay valid; refs that aren't
+// stash@{N} are rejected before anything is dropped.
+func (s *Snapshotter) Drop(ctx context.Context, entries []Entry) error {
+	indexes := make([]int, 0, len(entries))
+	for _, e := range entries {
+		m := stashRefPattern.FindStringSubmatch(e.Ref)
+		if m == nil {
+			return fmt.Errorf("drop snapshot: %q is not a stash ref", e.Ref)
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return fmt.Errorf("drop snapshot: %q: %w", e.Ref, err)
+		}
+		indexes = append(indexes, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+
+	for _, n := range indexes {
+		ref := fmt.Sprintf("stash@{%d}", n)
+		if err := s.git(ctx, "stash", "drop", ref); err != nil {
+			return fmt.Errorf("drop %s: %w", ref, err)
+		}
+	}
+	return nil
+}
+
+// Prune drops all but the newest keep snap snapshots and returns how many
+// it dropped. Stash entries not created by snap are never touched. keep
+// below zero is treated as zero.
+func (s *Snapshotter) Prune(ctx context.Context, keep int) (int, error) {
+	entries, err := s.List(ctx, Filter{})
+	if err != nil {
+		return 0, err
+	}
+	keep = max(keep, 0)
+	if len(entries) <= keep {
+		return 0, nil
+	}
+	stale := entries[keep:]
+	if err := s.Drop(ctx, stale); err != nil {
+		return 0, err
+	}
+	return len(stale), nil
+}
+
+// Restore applies the snap snapshot at ref (e.g. "stash@{2}") to the working
+// tree with `git stash apply`, keeping it in the stash. A ref that isn't a
+// snap snapshot, for example one dropped since it was listed, returns
+// ErrSnapshotNotFound. Git refuses to apply over local changes to the same
+// files.
+func (s *Snapshotter) Restore(ctx context.Context, ref string) error {
+	entries, err := s.List(ctx, Filter{})
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, e := range entries {
+		if e.Ref == ref {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("restore %s: %w", ref, ErrSnapshotNotFound)
+	}
+
+	if err := s.git(ctx, "stash", "apply", ref); err != nil {
+		return fmt.Errorf("restore %s: %w", ref, err)
+	}
+	return nil
+}
+
+// DiffStat returns `git diff --stat HEAD` for the working tree: staged,
+// unstaged and untracked changes (ignored files don't count). Empty when
+// there are no changes.
+func (s *Snapshotter) DiffStat(ctx context.Context) (string, error) {
+	var out string
+	err := s.withUntracked(ctx, func() error {
+		var err error
+		out, err = s.gitOutput(ctx, "diff", "--stat", "HEAD")
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("diff stat: %w", err)
+	}
+	return out, nil
+}
+
+// withUntracked runs fn with untracked files marked intent-to-add, so
+// `git diff` against a commit counts them as new files, then restores the
+// exact prior index, as Capture does.
+func (s *Snapshotter) withUntracked(ctx context.Context, fn func() error) error {
+	indexTree, err := s.gitOutput(ctx, "write-tree")
+	if err != nil {
+		return fmt.Errorf("save index: %w", err)
+	}
+	if err := s.git(ctx, "add", "--intent-to-add", "."); err != nil {
+		//nolint:contextcheck,errcheck // cleanup must succeed even if parent context is cancelled
+		_ = s.restoreIndex(context.Background(), indexTree)
+		return fmt.Errorf("mark untracked: %w", err)
+	}
+	fnErr := fn()
+	//nolint:contextcheck // cleanup must succeed even if parent context is cancelled
+	if err := s.restoreIndex(context.Background(), indexTree); err != nil {
+		return fmt.Errorf("restore index: %w", err)
+	}
+	return fnErr
+}
+
+// Head returns the commit HEAD points to.
+func (s *Snapshotter) Head(ctx context.Context) (string, error) {
+	out, err := s.gitOutput(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("rev-parse HEAD: %w", err)
+	}
+	return out, nil
+}
+
+// ResolveCommit returns the full hash of the commit ref names.
+func (s *Snapshotter) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	out, err := s.gitOutput(ctx, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%s is not a commit: %w", ref, err)
+	}
+	return out, nil
+}
+
+// Branch returns the checked-out branch, or "" on a detached HEAD.
+func (s *Snapshotter) Branch(ctx context.Context) (string, error) {
+	out, err := s.gitOutput(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if vcs.ExitCode(err) == 1 {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("symbolic-ref HEAD: %w", err)
+	}
+	return out, nil
+}
+
+// Switch checks out branch. With create set it starts the branch at HEAD,
+// resetting it there if it already exists.
+func (s *Snapshotter) Switch(ctx context.Context, branch string, create bool) error {
+	args := []string{"switch"}
+	if create {
+		args = append(args, "-C")
+	}
+	if err := s.git(ctx, append(args, branch)...); err != nil {
+		return fmt.Errorf("switch to %s: %w", branch, err)
+	}
+	return nil
+}
+
+// AddWorktree creates a worktree at dir with branch checked out. With create
+// set it starts a new branch at HEAD.
+func (s *Snapshotter) AddWorktree(ctx context.Context, dir, branch string, create bool) error {
+	args := []string{"worktree", "add", dir, branch}
+	if create {
+		args = []string{"worktree", "add", "-b", branch, dir, "HEAD"}
+	}
+	if err := s.git(ctx, args...); err != nil {
+		return fmt.Errorf("add worktree %s: %w", dir, err)
+	}
+	return nil
+}
+
+// DiscardWorktree removes the worktree at dir, discarding any changes in it,
+// and the directory itself. When git can't remove it (e.g. it was never
+// fully added), stale worktree entries are pruned instead. The worktree's
+// branch is kept.
+func (s *Snapshotter) DiscardWorktree(ctx context.Context, dir string) error {
+	removeErr := s.git(ctx, "worktree", "remove", "--force", dir)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if removeErr == nil {
+		return nil
+	}
+	return s.git(ctx, "worktree", "prune")
+}
+
+// Merge merges branch into the checked-out branch, fast-forwarding when it
+// can, and signs a merge commit when sign is set. A failed merge is aborted,
+// so the working tree is left as it was.
+func (s *Snapshotter) Merge(ctx context.Context, branch string, sign bool) error {
+	args := []string{"merge", "--no-edit"}
+	if sign {
+		args = append(args, "-S")
+	}
+	if err := s.git(ctx, append(args, branch)...); err != nil {
+		//nolint:errcheck // Best effort: there is nothing to abort when the merge never started.
+		_ = s.git(ctx, "merge", "--abort")
+		return fmt.Errorf("merge %s: %w", branch, err)
+	}
+	return nil
+}
+
+// MergeSquash applies the changes on branch since it forked to the working
+// tree and index without committing them (git merge --squash). A failed
+// merge is undone with git reset --merge, which keeps unrelated local
+// changes.
+func (s *Snapshotter) MergeSquash(ctx context.Context, branch string) error {
+	if err := s.git(ctx, "merge", "--squash", branch); err != nil {
+		//nolint:errcheck // Best effort: there is nothing to undo when the merge never started.
+		_ = s.git(ctx, "reset", "--merge")
+		return fmt.Errorf("merge %s: %w", branch, err)
+	}
+	return nil
+}
+
+// CommitAll commits every change in the working tree, untracked files
+// included, and reports whether there was anything to commit. Commit hooks
+// are skipped; the commit is signed when sign is set.
+func (s *Snapshotter) CommitAll(ctx context.Context, message string, sign bool) (bool, error) {
+	clean, err := s.Clean(ctx)
+	if err != nil || clean {
+		return false, err
+	}
+	if err := s.git(ctx, "add", "--all"); err != nil {
+		return false, fmt.Errorf("stage: %w", err)
+	}
+	args := []string{"commit", "--no-verify", "-m", message}
+	if sign {
+		args = append(args, "-S")
+	}
+	if err := s.git(ctx, args...); err != nil {
+		return false, fmt.Errorf("commit: %w", err)
+	}
+	return true, nil
+}
+
+// ResetTo moves the checked-out branch, index and working tree to commit
+// and removes the untracked files under the snapshotter's directory (the
+// ones Capture saves), leaving ignored files and anything under the keep
+// paths (e.g. task files not committed yet). Keep paths outside the
+// repository are skipped; directories left empty stay. Capture the changes
+// first to keep them, and save commits past commit with CreateBranch.
+func (s *Snapshotter) ResetTo(ctx context.Context, commit string, keep ...string) error {
+	if err := s.git(ctx, "reset", "--hard", commit); err != nil {
+		return fmt.Errorf("reset to %s: %w", commit, err)
+	}
+	top, err := s.gitOutput(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("list untracked files: %w", err)
+	}
+	args := []string{"ls-files", "--others", "--exclude-standard", "--full-name", "-z", "--", "."}
+	for _, path := range keep {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(top, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		args = append(args, ":(top,exclude)"+filepath.ToSlash(rel))
+	}
+	out, err := s.gitOutput(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("list untracked files: %w", err)
+	}
+	for _, name := range strings.Split(out, "\x00") {
+		if name == "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(top, filepath.FromSlash(name))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove untracked file: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateBranch creates branch at commit without checking it out.
+func (s *Snapshotter) CreateBranch(ctx context.Context, branch, commit string) error {
+	if err := s.git(ctx, "branch", branch, commit); err != nil {
+		return fmt.Errorf("create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// CommitsAhead returns the number of commits on branch that HEAD doesn't
+// have.
+func (s *Snapshotter) CommitsAhead(ctx context.Context, branch string) (int, error) {
+	out, err := s.gitOutput(ctx, "rev-list", "--count", "HEAD.."+branch)
+	if err != nil {
+		return 0, fmt.Errorf("count commits on %s: %w", branch, err)
+	}
+	n, err := strconv.Atoi(out)
+	if err != nil {
+		return 0, fmt.Errorf("count commits on %s: %w", branch, err)
+	}
+	return n, nil
+}
+
+// DeleteBranch deletes a branch that has been merged. With force set it
+// deletes it even when its commits aren't merged, e.g. after MergeSquash.
+func (s *Snapshotter) DeleteBranch(ctx context.Context, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if err := s.git(ctx, "branch", flag, branch); err != nil {
+		return fmt.Errorf("delete branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// ChangeStat counts the changes to tracked files since a commit.
+type ChangeStat struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+var (
+	shortStatFiles      = regexp.MustCompile(`(\d+) files? changed`)
+	shortStatInsertions = regexp.MustCompile(`(\d+) insertions?\(\+\)`)
+	shortStatDeletions  = regexp.MustCompile(`(\d+) deletions?\(-\)`)
+)
+
+// ChangesSince returns `git diff --shortstat <base>` for the working tree:
+// commits made since base plus uncommitted changes to tracked files.
+func (s *Snapshotter) ChangesSince(ctx context.Context, base string) (ChangeStat, error) {
+	out, err := s.gitOutput(ctx, "diff", "--shortstat", base)
+	if err != nil {
+		return ChangeStat{}, fmt.Errorf("diff shortstat: %w", err)
+	}
+	return ChangeStat{
+		Files:      shortStatCount(shortStatFiles, out),
+		Insertions: shortStatCount(shortStatInsertions, out),
+		Deletions:  shortStatCount(shortStatDeletions, out),
+	}, nil
+}
+
+// DiffSince returns `git diff <flags> <base>` for the working tree: commits
+// made since base plus uncommitted changes, untracked files included (as in
+// DiffStat). flags select the format (e.g. --stat, --name-only,
+// --color=always). Empty when nothing changed.
+func (s *Snapshotter) DiffSince(ctx context.Context, base string, flags ...string) (string, error) {
+	args := append(append([]string{"diff"}, flags...), base)
+	var out string
+	err := s.withUntracked(ctx, func() error {
+		var err error
+		out, err = s.gitOutput(ctx, args...)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("diff %s: %w", base, err)
+	}
+	return out, nil
+}
+
+func shortStatCount(re *regexp.Regexp, out string) int {
+	m := re.FindStringSubmatch(out)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1]) // The pattern only matches digits.
+	return n
+}
+
+// signingPrograms maps gpg.format values to the config key naming their
+// signing program and git's default for it.
+var signingPrograms = map[string]struct{ key, fallback string }{
+	"openpgp": {"gpg.openpgp.program", "gpg"},
+	"x509":    {"gpg.x509.program", "gpgsm"},
+	"ssh":     {"gpg.ssh.program", "ssh-keygen"},
+}
+
+// SigningConfigured checks that git can sign commits: the signing program
+// for gpg.format must be installed, and SSH signing needs user.signingkey.
+// OpenPGP and X.509 fall back to the committer identity when no key is set.
+func (s *Snapshotter) SigningConfigured(ctx context.Context) error {
+	format, err := s.configValue(ctx, "gpg.format")
+	if err != nil {
+		return err
+	}
+	if format == "" {
+		format = "openpgp"
+	}
+	prog, ok := signingPrograms[format]
+	if !ok {
+		return fmt.Errorf("commit signing: unsupported gpg.format %q", format)
+	}
+
+	program, err := s.configValue(ctx, prog.key)
+	if err != nil {
+		return err
+	}
+	if program == "" && format == "openpgp" {
+		if program, err = s.configValue(ctx, "gpg.program"); err != nil {
+			return err
+		}
+	}
+	if program == "" {
+		program = prog.fallback
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Errorf("commit signing: %s signing program %q not found; install it or set %s", format, program, prog.key)
+	}
+
+	if format == "ssh" {
+		key, err := s.configValue(ctx, "user.signingkey")
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return errors.New("commit signing: gpg.format is ssh but user.signingkey is not set")
+		}
+	}
+	return nil
+}
+
+// configValue returns a git config value, or "" when it is not set.
+func (s *Snapshotter) configValue(ctx context.Context, key string) (string, error) {
+	out, err := s.gitOutput(ctx, "config", "--get", key)
+	if vcs.ExitCode(err) == 1 {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("git config %s: %w", key, err)
+	}
+	return out, nil
+}
+
+// Clean reports whether the working tree has no staged, unstaged, or
+// untracked changes (ignored files don't count).
+func (s *Snapshotter) Clean(ctx context.Context) (bool, error) {
+	out, err := s.gitOutput(ctx, "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("status: %w", err)
+	}
+	return out == "", nil
+}
+
+// Dirty returns the paths `git status --porcelain` reports: staged,
+// unstaged and untracked changes (ignored files don't count). Empty when the
+// tree is clean.
+func (s *Snapshotter) Dirty(ctx context.Context) ([]string, error) {
+	// Not gitOutput: trimming would eat the leading status column.
+	out, err := s.runner.Output(ctx, "status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("status: %w", err)
+	}
+	var paths []string
+	for line := range strings.SplitSeq(strings.TrimRight(out, "\n"), "\n") {
+		if len(line) > 3 {
+			paths = append(paths, line[3:])
+		}
+	}
+	return paths, nil
+}
+
 // restoreIndex restores the git index to a previously-saved tree state.
 func (s *Snapshotter) restoreIndex(ctx context.Context, treeID string) error {
 	if treeID == "" {
@@ -76,25 +651,13 @@ func (s *Snapshotter) restoreIndex(ctx context.Context, treeID string) error {
 }
 
 func (s *Snapshotter) git(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = s.dir
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
-	}
-	return nil
+	return s.runner.Run(ctx, args...)
 }
 
 func (s *Snapshotter) gitOutput(ctx context.Context, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = s.dir
-	out, err := cmd.Output()
+	out, err := s.runner.Output(ctx, args...)
 	if err != nil {
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			return "", fmt.Errorf("%s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
-		}
-		return "", fmt.Errorf("%s: %w", strings.Join(args, " "), err)
+		return "", err
 	}
-	return strings.TrimSpace(string(out)), nil
+	return strings.TrimSpace(out), nil
 }
@@ -2,16 +2,19 @@ package snapshot_test
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/vcs"
 )
 
 // initGitRepo creates a git repo in dir with one initial commit.
@@ -128,3 +131,593 @@ func TestCapture_MessageFormat(t *testing.T) {
 	require.Len(t, entries, 1)
 	assert.Contains(t, entries[0], msg)
 }
+
+func TestLabel_RoundTrip(t *testing.T) {
+	label := snapshot.Label{TaskID: "TASK2", Step: 3, Total: 10, Name: "Lint & test"}
+	assert.Equal(t, "snap: TASK2 step 3/10 — Lint & test", label.String())
+
+	parsed, ok := snapshot.ParseLabel(label.String())
+	require.True(t, ok)
+	assert.Equal(t, label, parsed)
+
+	_, ok = snapshot.ParseLabel("WIP on main: abc123 something")
+	assert.False(t, ok)
+}
+
+func TestList_FiltersByTaskAndTime(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	ctx := context.Background()
+	s := snapshot.New(dir)
+
+	capture := func(label snapshot.Label, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte(content), 0o600))
+		created, err := s.Capture(ctx, label.String())
+		require.NoError(t, err)
+		require.True(t, created)
+	}
+	capture(snapshot.Label{TaskID: "TASK1", Step: 1, Total: 10, Name: "Implement"}, "one")
+	capture(snapshot.Label{TaskID: "TASK2", Step: 1, Total: 10, Name: "Implement"}, "two")
+	capture(snapshot.Label{TaskID: "TASK2", Step: 2, Total: 10, Name: "Ensure completeness"}, "three")
+
+	all, err := s.List(ctx, snapshot.Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	// Newest first.
+	assert.Equal(t, "stash@{0}", all[0].Ref)
+	assert.Equal(t, "Ensure completeness", all[0].Label.Name)
+	assert.WithinDuration(t, time.Now(), all[0].Time, time.Minute)
+
+	task2, err := s.List(ctx, snapshot.Filter{TaskID: "TASK2"})
+	require.NoError(t, err)
+	require.Len(t, task2, 2)
+	for _, e := range task2 {
+		assert.Equal(t, "TASK2", e.Label.TaskID)
+	}
+
+	future, err := s.List(ctx, snapshot.Filter{Since: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, future)
+
+	past, err := s.List(ctx, snapshot.Filter{Until: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, past)
+}
+
+func TestList_SkipsForeignStashes(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("manual"), 0o600))
+	cmd := exec.CommandContext(ctx, "git", "stash", "push", "-m", "my manual stash")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git stash push: %s", out)
+
+	entries, err := snapshot.New(dir).List(ctx, snapshot.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDrop_KeepsForeignStashes(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	ctx := context.Background()
+	s := snapshot.New(dir)
+
+	capture := func(task string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte(task), 0o600))
+		_, err := s.Capture(ctx, snapshot.Label{TaskID: task, Step: 1, Total: 10, Name: "Implement"}.String())
+		require.NoError(t, err)
+	}
+	capture("TASK1")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("manual"), 0o600))
+	cmd := exec.CommandContext(ctx, "git", "stash", "push", "-m", "my manual stash")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git stash push: %s", out)
+	capture("TASK2")
+
+	entries, err := s.List(ctx, snapshot.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.NoError(t, s.Drop(ctx, entries))
+
+	remaining := stashList(t, dir)
+	require.Len(t, remaining, 1)
+	assert.Contains(t, remaining[0], "my manual stash")
+}
+
+func TestPrune_KeepsNewestAndForeignStashes(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	ctx := context.Background()
+	s := snapshot.New(dir)
+
+	userStash := func(msg string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte(msg), 0o600))
+		cmd := exec.CommandContext(ctx, "git", "stash", "push", "-m", msg)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git stash push: %s", out)
+	}
+	for step := 1; step <= 15; step++ {
+		switch step {
+		case 3:
+			userStash("early manual stash")
+		case 13:
+			userStash("late manual stash")
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte(fmt.Sprintf("step %d", step)), 0o600))
+		_, err := s.Capture(ctx, snapshot.Label{TaskID: "TASK1", Step: step, Total: 15, Name: "Implement"}.String())
+		require.NoError(t, err)
+	}
+	require.Len(t, stashList(t, dir), 17)
+
+	pruned, err := s.Prune(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 10, pruned)
+
+	entries, err := s.List(ctx, snapshot.Filter{})
+	require.NoError(t, err)
+	var steps []int
+	for _, e := range entries {
+		steps = append(steps, e.Label.Step)
+	}
+	assert.Equal(t, []int{15, 14, 13, 12, 11}, steps)
+
+	remaining := strings.Join(stashList(t, dir), "\n")
+	assert.Len(t, stashList(t, dir), 7)
+	assert.Contains(t, remaining, "early manual stash")
+	assert.Contains(t, remaining, "late manual stash")
+
+	pruned, err = s.Prune(ctx, 5)
+	require.NoError(t, err)
+	assert.Zero(t, pruned, "nothing beyond the retention is left to prune")
+}
+
+func TestDrop_RejectsBadRef(t *testing.T) {
+	err := snapshot.New(t.TempDir()).Drop(context.Background(), []snapshot.Entry{{Ref: "HEAD"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a stash ref")
+}
+
+func TestDiffStat(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+
+	s := snapshot.New(dir)
+	stat, err := s.DiffStat(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, stat, "clean tree has no diff")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# init\nmore\n"), 0o600))
+
+	stat, err = s.DiffStat(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, stat, "README.md")
+	assert.Contains(t, stat, "1 file changed")
+}
+
+func TestDiffStat_CountsUntrackedFiles(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0o600))
+
+	s := snapshot.New(dir)
+	stat, err := s.DiffStat(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, stat, "new.go")
+	assert.Contains(t, stat, "1 file changed, 1 insertion(+)")
+
+	// The file is left untracked.
+	dirty, err := s.Dirty(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new.go"}, dirty)
+	out, err := exec.CommandContext(context.Background(), "git", "-C", dir, "status", "--porcelain").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "?? new.go\n", string(out))
+}
+
+func TestChangesSince(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+
+	s := snapshot.New(dir)
+	head, err := s.Head(context.Background())
+	require.NoError(t, err)
+	require.Len(t, head, 40)
+
+	stat, err := s.ChangesSince(context.Background(), head)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.ChangeStat{}, stat, "no changes since HEAD")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\nmore\nlines\n"), 0o600))
+
+	stat, err = s.ChangesSince(context.Background(), head)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.ChangeStat{Files: 1, Insertions: 3, Deletions: 1}, stat)
+
+	_, err = snapshot.New(t.TempDir()).Head(context.Background())
+	assert.Error(t, err, "not a git repo")
+}
+
+func TestResolveCommit(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+
+	s := snapshot.New(dir)
+	head, err := s.Head(context.Background())
+	require.NoError(t, err)
+
+	sha, err := s.ResolveCommit(context.Background(), "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, head, sha)
+
+	sha, err = s.ResolveCommit(context.Background(), head[:7])
+	require.NoError(t, err)
+	assert.Equal(t, head, sha)
+
+	_, err = s.ResolveCommit(context.Background(), "no-such-branch")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no-such-branch is not a commit")
+}
+
+func TestDiffSince(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+
+	s := snapshot.New(dir)
+	head, err := s.Head(context.Background())
+	require.NoError(t, err)
+
+	diff, err := s.DiffSince(context.Background(), head)
+	require.NoError(t, err)
+	assert.Empty(t, diff, "no changes since HEAD")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\n"), 0o600))
+
+	diff, err = s.DiffSince(context.Background(), head)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "-# init")
+	assert.Contains(t, diff, "+# changed")
+
+	names, err := s.DiffSince(context.Background(), head, "--name-only")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md", names)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0o600))
+	names, err = s.DiffSince(context.Background(), head, "--name-only")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md\nnew.txt", names, "untracked files count as new")
+	status, err := s.Dirty(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "new.txt"}, status, "the untracked file stays untracked")
+
+	_, err = s.DiffSince(context.Background(), "0000000000000000000000000000000000000000")
+	assert.Error(t, err, "unknown base commit")
+}
+
+func TestResetTo(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	s := snapshot.New(dir)
+	ctx := context.Background()
+	start, err := s.Head(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tasks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tasks", "TASK1.md"), []byte("# Task 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0o600))
+
+	require.NoError(t, s.ResetTo(ctx, start, filepath.Join(dir, "tasks"), t.TempDir()))
+
+	content, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# init", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "new.txt"))
+	assert.FileExists(t, filepath.Join(dir, "tasks", "TASK1.md"), "kept paths stay")
+
+	// A snapshotter in a subdirectory only removes untracked files there.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "inside.txt"), []byte("x"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "outside.txt"), []byte("x"), 0o600))
+	require.NoError(t, snapshot.New(filepath.Join(dir, "sub")).ResetTo(ctx, start))
+	assert.NoFileExists(t, filepath.Join(dir, "sub", "inside.txt"))
+	assert.FileExists(t, filepath.Join(dir, "outside.txt"))
+}
+
+func TestCreateBranch(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	s := snapshot.New(dir)
+	ctx := context.Background()
+	head, err := s.Head(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, s.CreateBranch(ctx, "snap/skipped/task1-1", head))
+	branch, err := s.Branch(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, "snap/skipped/task1-1", branch, "the new branch is not checked out")
+	saved, err := s.ResolveCommit(ctx, "snap/skipped/task1-1")
+	require.NoError(t, err)
+	assert.Equal(t, head, saved)
+
+	assert.Error(t, s.CreateBranch(ctx, "snap/skipped/task1-1", head), "existing branch")
+}
+
+func TestClean(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+
+	s := snapshot.New(dir)
+	clean, err := s.Clean(context.Background())
+	require.NoError(t, err)
+	assert.True(t, clean)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0o600))
+
+	clean, err = s.Clean(context.Background())
+	require.NoError(t, err)
+	assert.False(t, clean, "untracked files make the tree dirty")
+
+	_, err = snapshot.New(t.TempDir()).Clean(context.Background())
+	assert.Error(t, err, "not a git repo")
+}
+
+func TestDirty(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+
+	s := snapshot.New(dir)
+	paths, err := s.Dirty(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0o600))
+
+	paths, err = s.Dirty(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "new.txt"}, paths, "modified and untracked paths, status columns stripped")
+}
+
+func TestBranchAndSwitch(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	s := snapshot.New(dir)
+	ctx := context.Background()
+
+	base, err := s.Branch(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, base)
+
+	require.NoError(t, s.Switch(ctx, "snap/task1", true))
+	branch, err := s.Branch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "snap/task1", branch)
+
+	require.NoError(t, s.Switch(ctx, base, false))
+	require.NoError(t, s.Switch(ctx, "snap/task1", true), "an existing branch is reset")
+	require.NoError(t, s.Switch(ctx, base, false))
+	branch, err = s.Branch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, base, branch)
+
+	detach := exec.CommandContext(ctx, "git", "switch", "--detach")
+	detach.Dir = dir
+	require.NoError(t, detach.Run())
+	branch, err = s.Branch(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, branch, "detached HEAD")
+}
+
+func TestWorktreeMerge(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	s := snapshot.New(dir)
+	ctx := context.Background()
+
+	wtDir := filepath.Join(t.TempDir(), "TASK1")
+	require.NoError(t, s.AddWorktree(ctx, wtDir, "snap/parallel/task1", true))
+	require.NoError(t, os.WriteFile(filepath.Join(wtDir, "task1.txt"), []byte("done"), 0o600))
+	wt := snapshot.New(wtDir)
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "task1"}} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = wtDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	wtHead, err := wt.Head(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, s.DiscardWorktree(ctx, wtDir))
+	assert.NoDirExists(t, wtDir)
+
+	ahead, err := s.CommitsAhead(ctx, "snap/parallel/task1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, ahead)
+
+	require.NoError(t, s.Merge(ctx, "snap/parallel/task1", false))
+	head, err := s.Head(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, wtHead, head, "fast-forwarded to the task's commit")
+	assert.FileExists(t, filepath.Join(dir, "task1.txt"))
+
+	ahead, err = s.CommitsAhead(ctx, "snap/parallel/task1")
+	require.NoError(t, err)
+	assert.Zero(t, ahead, "merged")
+
+	require.NoError(t, s.DeleteBranch(ctx, "snap/parallel/task1", false))
+	err = s.Merge(ctx, "snap/parallel/task1", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "merge snap/parallel/task1")
+}
+
+func TestWorktreeMergeSquash(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	s := snapshot.New(dir)
+	ctx := context.Background()
+	head, err := s.Head(ctx)
+	require.NoError(t, err)
+
+	wtDir := filepath.Join(t.TempDir(), "TASK1")
+	require.NoError(t, s.AddWorktree(ctx, wtDir, "snap/parallel/task1", true))
+	require.NoError(t, os.WriteFile(filepath.Join(wtDir, "task1.txt"), []byte("done"), 0o600))
+	wt := snapshot.New(wtDir)
+	committed, err := wt.CommitAll(ctx, "TASK1: work in progress", false)
+	require.NoError(t, err)
+	assert.True(t, committed)
+	committed, err = wt.CommitAll(ctx, "TASK1: work in progress", false)
+	require.NoError(t, err)
+	assert.False(t, committed, "clean tree")
+	require.NoError(t, s.DiscardWorktree(ctx, wtDir))
+
+	// The branch checks out again as it was left.
+	require.NoError(t, s.AddWorktree(ctx, wtDir, "snap/parallel/task1", false))
+	assert.FileExists(t, filepath.Join(wtDir, "task1.txt"))
+	require.NoError(t, s.DiscardWorktree(ctx, wtDir))
+
+	require.NoError(t, s.MergeSquash(ctx, "snap/parallel/task1"))
+	after, err := s.Head(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, head, after, "nothing is committed")
+	dirty, err := s.Dirty(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task1.txt"}, dirty)
+
+	require.Error(t, s.DeleteBranch(ctx, "snap/parallel/task1", false), "squashed commits aren't merged")
+	require.NoError(t, s.DeleteBranch(ctx, "snap/parallel/task1", true))
+	require.Error(t, s.MergeSquash(ctx, "snap/parallel/task1"))
+}
+
+// stubGit answers git commands from a fixed table keyed by the joined args.
+type stubGit struct {
+	outputs map[string]string
+	calls   []string
+}
+
+func (g *stubGit) Run(ctx context.Context, args ...string) error {
+	_, err := g.Output(ctx, args...)
+	return err
+}
+
+func (g *stubGit) Output(_ context.Context, args ...string) (string, error) {
+	key := strings.Join(args, " ")
+	g.calls = append(g.calls, key)
+	out, ok := g.outputs[key]
+	if !ok {
+		return "", &vcs.Error{Args: args, Stderr: "unexpected command", ExitCode: 1}
+	}
+	return out, nil
+}
+
+func TestNewWithRunner_StubbedGit(t *testing.T) {
+	git := &stubGit{outputs: map[string]string{
+		"status --porcelain":    " M main.go\n",
+		"write-tree":            "4b825dc\n",
+		"add --intent-to-add .": "",
+		"diff --stat HEAD":      " main.go | 2 +-\n",
+		"read-tree 4b825dc":     "",
+	}}
+	s := snapshot.NewWithRunner(git)
+
+	clean, err := s.Clean(context.Background())
+	require.NoError(t, err)
+	assert.False(t, clean)
+
+	stat, err := s.DiffStat(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "main.go | 2 +-", stat)
+
+	_, err = s.List(context.Background(), snapshot.Filter{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected command")
+	assert.Equal(t, []string{
+		"status --porcelain",
+		"write-tree", "add --intent-to-add .", "diff --stat HEAD", "read-tree 4b825dc",
+		"stash list --format=%gd%x1f%ct%x1f%gs",
+	}, git.calls)
+}
+
+func TestSigningConfigured(t *testing.T) {
+	ctx := context.Background()
+
+	git := &stubGit{outputs: map[string]string{"config --get gpg.program": "sh"}}
+	require.NoError(t, snapshot.NewWithRunner(git).SigningConfigured(ctx), "openpgp falls back to gpg.program")
+
+	git = &stubGit{outputs: map[string]string{"config --get gpg.format": "ssh", "config --get gpg.ssh.program": "sh"}}
+	err := snapshot.NewWithRunner(git).SigningConfigured(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "user.signingkey is not set")
+
+	git.outputs["config --get user.signingkey"] = "~/.ssh/id_ed25519.pub"
+	require.NoError(t, snapshot.NewWithRunner(git).SigningConfigured(ctx))
+
+	git = &stubGit{outputs: map[string]string{"config --get gpg.openpgp.program": "snap-no-such-gpg"}}
+	err = snapshot.NewWithRunner(git).SigningConfigured(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `signing program "snap-no-such-gpg" not found`)
+
+	git = &stubGit{outputs: map[string]string{"config --get gpg.format": "pgp"}}
+	err = snapshot.NewWithRunner(git).SigningConfigured(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported gpg.format "pgp"`)
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	ctx := context.Background()
+	s := snapshot.New(dir)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("step 3"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0o600))
+	_, err := s.Capture(ctx, snapshot.Label{TaskID: "TASK1", Step: 3, Total: 10, Name: "Lint & test"}.String())
+	require.NoError(t, err)
+
+	// Roll the tree back to HEAD, then restore the snapshot.
+	for _, args := range [][]string{{"checkout", "--", "."}, {"clean", "-fd"}} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, out)
+	}
+	require.NoFileExists(t, filepath.Join(dir, "new.go"))
+
+	require.NoError(t, s.Restore(ctx, "stash@{0}"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "step 3", string(data))
+	assert.FileExists(t, filepath.Join(dir, "new.go"), "files untracked at capture come back")
+	assert.Len(t, stashList(t, dir), 1, "the snapshot stays in the stash")
+}
+
+func TestRestore_MissingSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("manual"), 0o600))
+	cmd := exec.CommandContext(ctx, "git", "stash", "push", "-m", "my manual stash")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git stash push: %s", out)
+
+	s := snapshot.New(dir)
+	err = s.Restore(ctx, "stash@{0}")
+	require.ErrorIs(t, err, snapshot.ErrSnapshotNotFound, "foreign stashes are not snapshots")
+
+	err = s.Restore(ctx, "stash@{5}")
+	require.ErrorIs(t, err, snapshot.ErrSnapshotNotFound)
+	assert.Equal(t, "restore stash@{5}: snapshot not found", err.Error())
+}
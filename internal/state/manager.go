@@ -1,6 +1,9 @@
 package state
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,6 +18,10 @@ const (
 
 	// StateFile is the name of the state file.
 	StateFile = "state.json"
+
+	// HistoryFile is the name of the task completion history, kept beside
+	// the state file. Reset leaves it in place.
+	HistoryFile = "history.jsonl"
 )
 
 // Manager provides persistent state management for workflow resumption.
@@ -56,6 +63,14 @@ func NewManagerInDir(dir string) *Manager {
 	}
 }
 
+// NewManagerForTaskFile creates the state manager for a single task file
+// run (--task-file): state lives in .snap/adhoc/<sha256 of the path>/, so
+// each task file keeps its own.
+func NewManagerForTaskFile(taskFilePath string) *Manager {
+	sum := sha256.Sum256([]byte(taskFilePath))
+	return NewManagerInDir(filepath.Join(StateDir, "adhoc", hex.EncodeToString(sum[:])))
+}
+
 // Load reads state from disk.
 // Returns nil state with nil error if file doesn't exist (no error occurred, just no state).
 func (m *Manager) Load() (*State, error) {
@@ -143,6 +158,70 @@ func (m *Manager) Reset() error {
 	return nil
 }
 
+// HistoryEntry is one line of the completion history.
+type HistoryEntry struct {
+	TaskID string `json:"task_id"`
+	TaskRecord
+}
+
+// AppendHistory appends a task's completion record to the history file,
+// which outlives Reset so the records survive the end of a run.
+func (m *Manager) AppendHistory(taskID string, rec TaskRecord) error {
+	if err := os.MkdirAll(m.stateDir, 0o755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+
+	data, err := json.Marshal(HistoryEntry{TaskID: taskID, TaskRecord: rec})
+	if err != nil {
+		return fmt.Errorf("marshal history entry: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(m.stateDir, HistoryFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open history file: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write history file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close history file: %w", err)
+	}
+
+	return nil
+}
+
+// History reads the completion history, oldest first. Returns nil when no
+// task has completed yet.
+func (m *Manager) History() ([]HistoryEntry, error) {
+	return ReadHistory(filepath.Join(m.stateDir, HistoryFile))
+}
+
+// ReadHistory parses a history file. A missing file yields nil; lines that
+// do not parse (e.g. a write cut short) are skipped.
+func ReadHistory(path string) ([]HistoryEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history file: %w", err)
+	}
+
+	var entries []HistoryEntry
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e HistoryEntry
+		if json.Unmarshal(line, &e) != nil || e.TaskID == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
 // Exists checks if state file exists.
 func (m *Manager) Exists() bool {
 	_, err := os.Stat(m.statePath)
@@ -418,6 +418,49 @@ func TestNewManagerInDir_ResetAndExists(t *testing.T) {
 	}
 }
 
+func TestManager_HistorySurvivesReset(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManagerWithDir(tmpDir)
+
+	if err := mgr.Save(NewState("docs/tasks", "docs/tasks/PRD.md", 9)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	completedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := mgr.AppendHistory("TASK1", TaskRecord{CompletedAt: completedAt, Provider: "claude", Model: "opus"}); err != nil {
+		t.Fatalf("AppendHistory() error = %v", err)
+	}
+	if err := mgr.AppendHistory("TASK2", TaskRecord{CompletedAt: completedAt, Skipped: true}); err != nil {
+		t.Fatalf("AppendHistory() error = %v", err)
+	}
+	if err := mgr.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	history, err := mgr.History()
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(history))
+	}
+	if history[0].TaskID != "TASK1" || history[0].Model != "opus" || !history[0].CompletedAt.Equal(completedAt) {
+		t.Errorf("unexpected first entry: %+v", history[0])
+	}
+	if history[1].TaskID != "TASK2" || !history[1].Skipped {
+		t.Errorf("unexpected second entry: %+v", history[1])
+	}
+}
+
+func TestManager_HistoryMissing(t *testing.T) {
+	history, err := NewManagerWithDir(t.TempDir()).History()
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if history != nil {
+		t.Errorf("expected nil history, got %v", history)
+	}
+}
+
 func TestManager_SerializedFieldPresence(t *testing.T) {
 	tmpDir := t.TempDir()
 	mgr := NewManagerWithDir(tmpDir)
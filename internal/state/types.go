@@ -1,7 +1,9 @@
 package state
 
 import (
+	"errors"
 	"fmt"
+	"syscall"
 	"time"
 )
 
@@ -18,15 +20,38 @@ type State struct {
 	// CurrentTaskFile is the filename of the active task (e.g. "TASK1.md"), empty when idle.
 	CurrentTaskFile string `json:"current_task_file,omitempty"`
 
+	// TaskStartCommit is the commit HEAD pointed to when the active task
+	// started; the task's change summary is measured from it. Empty when idle
+	// or outside a git repository.
+	TaskStartCommit string `json:"task_start_commit,omitempty"`
+
+	// TaskDescription is the generated one-line description of the active
+	// task, reused on resume while the task file's SHA-256 still equals
+	// TaskDescriptionHash. Empty when idle or not yet generated.
+	TaskDescription     string `json:"task_description,omitempty"`
+	TaskDescriptionHash string `json:"task_description_hash,omitempty"`
+
+	// TaskDirectives are the queued directives applied between the active
+	// task's steps, in order; moved into its TaskRecord on completion.
+	TaskDirectives []string `json:"task_directives,omitempty"`
+
 	// CurrentStep is the step number within the workflow (1-indexed).
 	CurrentStep int `json:"current_step"`
 
 	// TotalSteps is the total number of steps in the workflow.
 	TotalSteps int `json:"total_steps"`
 
+	// SkippedSteps names the workflow steps left out with --skip-step, so
+	// step numbers map back to names; empty when none are skipped.
+	SkippedSteps []string `json:"skipped_steps,omitempty"`
+
 	// CompletedTaskIDs tracks which tasks have been completed.
 	CompletedTaskIDs []string `json:"completed_task_ids"`
 
+	// CompletedTasks holds completion metadata keyed by task ID. Tasks
+	// completed by older versions have no entry.
+	CompletedTasks map[string]TaskRecord `json:"completed_tasks,omitempty"`
+
 	// SessionID is the Claude CLI session ID for resuming with -c flag.
 	// Empty string means session ID unavailable (graceful degradation to -c flag).
 	SessionID string `json:"session_id"`
@@ -37,8 +62,82 @@ type State struct {
 	// LastError contains error message from last failed step (empty if none).
 	LastError string `json:"last_error,omitempty"`
 
+	// LastFailure describes the step behind LastError, when the error came
+	// from a workflow step.
+	LastFailure *Failure `json:"last_failure,omitempty"`
+
+	// MonitoringCI is set while post-run CI monitoring is in progress, so an
+	// interrupted run reattaches to CI instead of starting post-run over.
+	// Starting a task or a failed post-run clears it.
+	MonitoringCI bool `json:"monitoring_ci,omitempty"`
+
+	// BaseBranch is the branch each task's branch starts from when a run
+	// opens one PR per task; empty otherwise.
+	BaseBranch string `json:"base_branch,omitempty"`
+
+	// PublishBranch is the branch of a completed task whose post-run (push,
+	// PR, CI) has not finished when a run opens one PR per task, so an
+	// interrupted run publishes it before starting the next task.
+	PublishBranch string `json:"publish_branch,omitempty"`
+
+	// KeptBranches maps a task that failed or was interrupted in a parallel
+	// worktree to the branch holding its work, so the task resumes from
+	// there instead of starting over.
+	KeptBranches map[string]KeptBranch `json:"kept_branches,omitempty"`
+
 	// PRDPath is the resolved path to PRD.md for validation.
 	PRDPath string `json:"prd_path"`
+
+	// PID is the process running the workflow, set while a run is active
+	// and cleared when it exits. A leftover PID whose process is gone means
+	// the run never shut down cleanly (crash, kill -9, reboot).
+	PID int `json:"pid,omitempty"`
+}
+
+// TaskRecord is the completion metadata recorded for a finished task.
+type TaskRecord struct {
+	// CompletedAt is when the task's final step finished.
+	CompletedAt time.Time `json:"completed_at"`
+
+	// Provider is the provider that ran the task (e.g. "claude").
+	Provider string `json:"provider,omitempty"`
+
+	// Model is the provider model that implemented the task (e.g. "opus").
+	Model string `json:"model,omitempty"`
+
+	// Directives are the queued directives applied while the task ran.
+	Directives []string `json:"directives,omitempty"`
+
+	// Skipped is true when the user skipped the task with the /skip
+	// directive before its steps finished.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// KeptBranch is the branch a parallel run kept for an unfinished task.
+type KeptBranch struct {
+	// Branch holds the task's work, committed.
+	Branch string `json:"branch"`
+
+	// Step is the 1-indexed step the task stopped at.
+	Step int `json:"step"`
+
+	// StartCommit is the commit the branch started from.
+	StartCommit string `json:"start_commit,omitempty"`
+}
+
+// Failure records where a task failed.
+type Failure struct {
+	// Step is the 1-indexed step that failed.
+	Step int `json:"step"`
+
+	// StepName is the step's display name (e.g. "Code review").
+	StepName string `json:"step_name"`
+
+	// Model is the provider model the step ran with (e.g. "sonnet").
+	Model string `json:"model,omitempty"`
+
+	// Output is the tail of the step's output, without colors.
+	Output string `json:"output,omitempty"`
 }
 
 // NewState creates a new idle state with default values.
@@ -65,9 +164,16 @@ func (s *State) Summary(stepName func(int) string) string {
 	if completed == 1 {
 		label = "task"
 	}
+	if s.CurrentTaskID == "" && s.MonitoringCI {
+		return fmt.Sprintf("Monitoring CI — %d %s completed", completed, label)
+	}
 	if s.CurrentTaskID == "" {
 		return fmt.Sprintf("No active task — %d %s completed", completed, label)
 	}
+	if f := s.LastFailure; f != nil {
+		return fmt.Sprintf("%s failed at step %d/%d (%s) — %d %s completed",
+			s.CurrentTaskID, f.Step, s.TotalSteps, f.StepName, completed, label)
+	}
 	return fmt.Sprintf("%s in progress — step %d/%d: %s — %d %s completed",
 		s.CurrentTaskID, s.CurrentStep, s.TotalSteps, stepName(s.CurrentStep), completed, label)
 }
@@ -99,19 +205,63 @@ func (s *State) IsValid() bool {
 	return true
 }
 
+// StartTask makes taskID (read from file) the active task. Working on a task
+// ends any interrupted CI monitoring: its commits go through post-run again
+// once the tasks are done.
+func (s *State) StartTask(taskID, file string) {
+	s.CurrentTaskID = taskID
+	s.CurrentTaskFile = file
+	s.MonitoringCI = false
+}
+
+// RecordCompletion stores completion metadata for a task.
+func (s *State) RecordCompletion(taskID string, rec TaskRecord) {
+	if s.CompletedTasks == nil {
+		s.CompletedTasks = make(map[string]TaskRecord)
+	}
+	s.CompletedTasks[taskID] = rec
+}
+
+// KeepBranch records the branch holding an unfinished task's work.
+func (s *State) KeepBranch(taskID string, kept KeptBranch) {
+	if s.KeptBranches == nil {
+		s.KeptBranches = make(map[string]KeptBranch)
+	}
+	s.KeptBranches[taskID] = kept
+}
+
 // MarkStepComplete advances to the next step and clears any error.
 func (s *State) MarkStepComplete() {
 	s.CurrentStep++
 	s.LastError = ""
+	s.LastFailure = nil
 	s.LastUpdated = time.Now()
 }
 
-// MarkStepFailed records an error for the current step.
+// MarkStepFailed records an error for the current step. Any earlier
+// failure details are cleared; callers set LastFailure when they have them.
 func (s *State) MarkStepFailed(err error) {
 	s.LastError = err.Error()
+	s.LastFailure = nil
 	s.LastUpdated = time.Now()
 }
 
+// Abandoned reports whether the state was left mid-task by a run that
+// didn't shut down cleanly: the process that owned it is gone and no error
+// was recorded, so the current step was interrupted partway through.
+func (s *State) Abandoned() bool {
+	if s.PID == 0 || s.CurrentTaskID == "" || s.LastError != "" {
+		return false
+	}
+	return !ProcessAlive(s.PID)
+}
+
+// ProcessAlive reports whether a process with the given pid exists.
+func ProcessAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
 // IsTaskComplete returns true if all steps are complete.
 func (s *State) IsTaskComplete() bool {
 	return s.CurrentStep > s.TotalSteps
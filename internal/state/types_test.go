@@ -1,7 +1,10 @@
 package state
 
 import (
+	"encoding/json"
 	"errors"
+	"os"
+	"os/exec"
 	"strings"
 	"testing"
 	"time"
@@ -224,6 +227,63 @@ func TestState_MarkStepComplete(t *testing.T) {
 	}
 }
 
+func TestState_StartTask(t *testing.T) {
+	state := NewState("docs/tasks", "prd.md", 10)
+	state.MonitoringCI = true
+
+	state.StartTask("TASK2", "TASK2.md")
+
+	if state.CurrentTaskID != "TASK2" || state.CurrentTaskFile != "TASK2.md" {
+		t.Errorf("expected TASK2 (TASK2.md) to be active, got %s (%s)", state.CurrentTaskID, state.CurrentTaskFile)
+	}
+	if state.MonitoringCI {
+		t.Error("expected starting a task to clear MonitoringCI")
+	}
+}
+
+func TestState_RecordCompletion(t *testing.T) {
+	state := NewState("docs/tasks", "prd.md", 10)
+	completedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	state.RecordCompletion("TASK1", TaskRecord{CompletedAt: completedAt, Provider: "claude", Model: "opus"})
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var loaded State
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	rec, ok := loaded.CompletedTasks["TASK1"]
+	if !ok {
+		t.Fatal("expected completion record for TASK1")
+	}
+	if rec.Provider != "claude" || rec.Model != "opus" || !rec.CompletedAt.Equal(completedAt) {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
+
+func TestState_KeepBranch(t *testing.T) {
+	state := NewState("docs/tasks", "prd.md", 10)
+	state.KeepBranch("TASK2", KeptBranch{Branch: "snap/parallel/task2-1", Step: 4, StartCommit: "abc123"})
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var loaded State
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	want := KeptBranch{Branch: "snap/parallel/task2-1", Step: 4, StartCommit: "abc123"}
+	if got := loaded.KeptBranches["TASK2"]; got != want {
+		t.Errorf("KeptBranches[TASK2] = %+v, want %+v", got, want)
+	}
+}
+
 func TestState_MarkStepFailed(t *testing.T) {
 	state := NewState("docs/tasks", "prd.md", 9)
 	state.CurrentStep = 5
@@ -241,11 +301,51 @@ func TestState_MarkStepFailed(t *testing.T) {
 	if state.LastError != "test error" {
 		t.Errorf("expected error 'test error', got %s", state.LastError)
 	}
+	if state.LastFailure != nil {
+		t.Errorf("expected no failure details, got %+v", state.LastFailure)
+	}
+
+	state.LastFailure = &Failure{Step: 5, StepName: "Apply fixes"}
+	state.MarkStepComplete()
+	if state.LastFailure != nil || state.LastError != "" {
+		t.Errorf("expected failure cleared on completion, got %+v / %q", state.LastFailure, state.LastError)
+	}
 	if !state.LastUpdated.After(beforeUpdate) {
 		t.Error("expected last updated to be updated")
 	}
 }
 
+func TestState_Abandoned(t *testing.T) {
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("run true: %v", err)
+	}
+	deadPID := exited.Process.Pid
+
+	tests := []struct {
+		name   string
+		modify func(s *State)
+		want   bool
+	}{
+		{"dead process mid-task", func(s *State) { s.PID = deadPID }, true},
+		{"no pid recorded", func(s *State) {}, false},
+		{"process still running", func(s *State) { s.PID = os.Getpid() }, false},
+		{"error recorded", func(s *State) { s.PID = deadPID; s.LastError = "boom" }, false},
+		{"idle", func(s *State) { s.PID = deadPID; s.CurrentTaskID = "" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewState("docs/tasks", "prd.md", 10)
+			s.CurrentTaskID = "TASK1"
+			s.CurrentStep = 4
+			tt.modify(s)
+			if got := s.Abandoned(); got != tt.want {
+				t.Errorf("Abandoned() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestState_IsTaskComplete(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -329,6 +429,28 @@ func TestState_Summary(t *testing.T) {
 			},
 			contains: []string{"step 10/10", "Commit memory"},
 		},
+		{
+			name: "failed task",
+			state: &State{
+				CurrentTaskID:    "TASK3",
+				CurrentStep:      4,
+				TotalSteps:       10,
+				CompletedTaskIDs: []string{"TASK1", "TASK2"},
+				LastError:        "step 4/10 \"Code review\" failed: exit status 1",
+				LastFailure:      &Failure{Step: 4, StepName: "Code review", Model: "opus"},
+			},
+			contains: []string{"TASK3 failed at step 4/10 (Code review)", "2 tasks completed"},
+		},
+		{
+			name: "monitoring CI",
+			state: &State{
+				CurrentStep:      1,
+				TotalSteps:       10,
+				CompletedTaskIDs: []string{"TASK1", "TASK2"},
+				MonitoringCI:     true,
+			},
+			contains: []string{"Monitoring CI", "2 tasks completed"},
+		},
 		{
 			name: "no active task with 0 completed",
 			state: &State{
@@ -15,14 +15,10 @@ func Header(text, description string) string {
 	styleCode := ResolveStyle(WeightBold)
 	resetCode := ResolveStyle(WeightNormal)
 
-	titleLine := fmt.Sprintf("%s%s▶ %s%s", styleCode, colorCode, text, resetCode)
+	titleLine := fmt.Sprintf("%s%s%s %s%s", styleCode, colorCode, glyphs().arrow, text, resetCode)
 
 	if description != "" {
-		maxLen := SeparatorWidth - 2 // account for 2-char indent
-		descRunes := []rune(description)
-		if len(descRunes) > maxLen {
-			description = string(descRunes[:maxLen-1]) + "…"
-		}
+		description = truncate(description, Width()-2) // account for 2-char indent
 		dimCode := ResolveStyle(WeightDim)
 		descLine := fmt.Sprintf("  %s%s%s", dimCode, description, resetCode)
 		return VerticalSpace(SpaceMD) + titleLine + "\n" + descLine + VerticalSpace(SpaceXS)
@@ -36,7 +32,7 @@ func Step(text string) string {
 	colorCode := ResolveColor(ColorSecondary)
 	styleCode := ResolveStyle(WeightBold)
 	resetCode := ResolveStyle(WeightNormal)
-	return fmt.Sprintf("\n%s%s▶ %s%s\n", styleCode, colorCode, text, resetCode)
+	return fmt.Sprintf("\n%s%s%s %s%s\n", styleCode, colorCode, glyphs().arrow, text, resetCode)
 }
 
 // StepNumbered formats a step header with numbering (e.g., "Step 2/9: Implement TASK2").
@@ -44,8 +40,8 @@ func StepNumbered(current, total int, text string) string {
 	colorCode := ResolveColor(ColorSecondary)
 	styleCode := ResolveStyle(WeightBold)
 	resetCode := ResolveStyle(WeightNormal)
-	return fmt.Sprintf("\n%s%s▶ Step %d/%d: %s%s%s",
-		styleCode, colorCode, current, total, text, resetCode,
+	return fmt.Sprintf("\n%s%s%s Step %d/%d: %s%s%s",
+		styleCode, colorCode, glyphs().arrow, current, total, text, resetCode,
 		VerticalSpace(SpaceXS))
 }
 
@@ -57,8 +53,8 @@ func Success(text string) string {
 	dimCode := ResolveStyle(WeightDim)
 	resetCode := ResolveStyle(WeightNormal)
 	indent := strings.Repeat(" ", IndentResult)
-	return fmt.Sprintf("%s%s%s✓%s %s%s%s",
-		indent, styleCode, colorCode, resetCode, dimCode, sanitized, resetCode)
+	return fmt.Sprintf("%s%s%s%s%s %s%s%s",
+		indent, styleCode, colorCode, glyphs().check, resetCode, dimCode, sanitized, resetCode)
 }
 
 // Error formats an error message with X mark.
@@ -69,8 +65,8 @@ func Error(text string) string {
 	dimCode := ResolveStyle(WeightDim)
 	resetCode := ResolveStyle(WeightNormal)
 	indent := strings.Repeat(" ", IndentResult)
-	return fmt.Sprintf("%s%s%s✗%s %s%s%s",
-		indent, styleCode, colorCode, resetCode, dimCode, sanitized, resetCode)
+	return fmt.Sprintf("%s%s%s%s%s %s%s%s",
+		indent, styleCode, colorCode, glyphs().cross, resetCode, dimCode, sanitized, resetCode)
 }
 
 // ErrorWithDetails formats an error message with X mark and multi-line details in tree format.
@@ -86,13 +82,13 @@ func ErrorWithDetails(message string, details []string) string {
 	var builder strings.Builder
 
 	// Main error line with X mark
-	fmt.Fprintf(&builder, "%s%s%s✗%s %s%s%s",
-		indent, styleCode, colorCode, resetCode, dimCode, StripColors(message), resetCode)
+	fmt.Fprintf(&builder, "%s%s%s%s%s %s%s%s",
+		indent, styleCode, colorCode, glyphs().cross, resetCode, dimCode, StripColors(message), resetCode)
 
 	// Add detail lines with tree structure
 	for _, detail := range details {
-		fmt.Fprintf(&builder, "\n%s%s└─ %s%s",
-			detailIndent, dimCode, StripColors(detail), resetCode)
+		fmt.Fprintf(&builder, "\n%s%s%s %s%s",
+			detailIndent, dimCode, glyphs().branch, StripColors(detail), resetCode)
 	}
 
 	return builder.String()
@@ -121,14 +117,14 @@ func Tool(text string) string {
 	colorCode := ResolveColor(ColorTool)
 	resetCode := ResolveStyle(WeightNormal)
 	indent := strings.Repeat(" ", IndentTool)
-	return fmt.Sprintf("%s%s🔧 %s%s", indent, colorCode, sanitized, resetCode)
+	return fmt.Sprintf("%s%s%s%s%s", indent, colorCode, emoji("🔧"), sanitized, resetCode)
 }
 
 // Separator returns a visual separator line.
 func Separator() string {
 	styleCode := ResolveStyle(WeightDim)
 	resetCode := ResolveStyle(WeightNormal)
-	return fmt.Sprintf("%s%s%s\n", styleCode, strings.Repeat("─", SeparatorWidth), resetCode)
+	return fmt.Sprintf("%s%s%s\n", styleCode, strings.Repeat(glyphs().hLine, Width()), resetCode)
 }
 
 // Complete formats a completion message.
@@ -136,7 +132,7 @@ func Complete(text string) string {
 	colorCode := ResolveColor(ColorCelebrate)
 	styleCode := ResolveStyle(WeightBold)
 	resetCode := ResolveStyle(WeightNormal)
-	return fmt.Sprintf("\n%s%s✨ %s%s\n", styleCode, colorCode, text, resetCode)
+	return fmt.Sprintf("\n%s%s%s%s%s\n", styleCode, colorCode, emoji("✨"), text, resetCode)
 }
 
 // CompleteBoxed formats a completion message with boxed format and statistics.
@@ -144,48 +140,45 @@ func CompleteBoxed(taskName string, filesChanged, linesAdded int, testsPassing b
 	colorCode := ResolveColor(ColorCelebrate)
 	styleCode := ResolveStyle(WeightBold)
 	resetCode := ResolveStyle(WeightNormal)
+	g := glyphs()
 
 	// Top border
-	topBorder := fmt.Sprintf("%s%s┌%s┐%s\n",
+	topBorder := fmt.Sprintf("%s%s%s%s%s%s\n",
 		styleCode, colorCode,
-		strings.Repeat("─", BoxWidth-2),
+		g.corners[0], strings.Repeat(g.hLine, Width()-2), g.corners[1],
 		resetCode)
 
 	// Title line
-	title := fmt.Sprintf("✨ %s implementation complete", taskName)
-	titleLine := fmt.Sprintf("%s%s│  %-*s│%s\n",
+	title := fmt.Sprintf("%s%s implementation complete", emoji("✨"), taskName)
+	titleLine := fmt.Sprintf("%s%s%s  %-*s%s%s\n",
 		styleCode, colorCode,
-		BoxWidth-4,
-		title,
+		g.vLine, Width()-4, title, g.vLine,
 		resetCode)
 
 	// Detail lines
-	filesLine := fmt.Sprintf("%s%s│     • %-*s│%s\n",
+	filesLine := fmt.Sprintf("%s%s%s     %s %-*s%s%s\n",
 		styleCode, colorCode,
-		BoxWidth-9,
-		fmt.Sprintf("%d files changed", filesChanged),
+		g.vLine, g.bullet, Width()-9, fmt.Sprintf("%d files changed", filesChanged), g.vLine,
 		resetCode)
 
-	linesLine := fmt.Sprintf("%s%s│     • %-*s│%s\n",
+	linesLine := fmt.Sprintf("%s%s%s     %s %-*s%s%s\n",
 		styleCode, colorCode,
-		BoxWidth-9,
-		fmt.Sprintf("%d lines added", linesAdded),
+		g.vLine, g.bullet, Width()-9, fmt.Sprintf("%d lines added", linesAdded), g.vLine,
 		resetCode)
 
 	testStatus := "All tests passing"
 	if !testsPassing {
 		testStatus = "Tests need attention"
 	}
-	testsLine := fmt.Sprintf("%s%s│     • %-*s│%s\n",
+	testsLine := fmt.Sprintf("%s%s%s     %s %-*s%s%s\n",
 		styleCode, colorCode,
-		BoxWidth-9,
-		testStatus,
+		g.vLine, g.bullet, Width()-9, testStatus, g.vLine,
 		resetCode)
 
 	// Bottom border
-	bottomBorder := fmt.Sprintf("%s%s└%s┘%s",
+	bottomBorder := fmt.Sprintf("%s%s%s%s%s%s",
 		styleCode, colorCode,
-		strings.Repeat("─", BoxWidth-2),
+		g.corners[2], strings.Repeat(g.hLine, Width()-2), g.corners[3],
 		resetCode)
 
 	return VerticalSpace(SpaceMD) +
@@ -203,7 +196,7 @@ func Interrupted(text string) string {
 	colorCode := ResolveColor(ColorWarning)
 	styleCode := ResolveStyle(WeightBold)
 	resetCode := ResolveStyle(WeightNormal)
-	return fmt.Sprintf("\n%s%s⚠ %s%s\n", styleCode, colorCode, text, resetCode)
+	return fmt.Sprintf("\n%s%s%s %s%s\n", styleCode, colorCode, glyphs().warn, text, resetCode)
 }
 
 // InterruptedWithContext formats an interruption message with step context and resume instructions.
@@ -213,7 +206,7 @@ func InterruptedWithContext(text string, currentStep, totalSteps int) string {
 	dimCode := ResolveStyle(WeightDim)
 	resetCode := ResolveStyle(WeightNormal)
 
-	mainLine := fmt.Sprintf("%s%s⚠  %s%s\n", styleCode, colorCode, text, resetCode)
+	mainLine := fmt.Sprintf("%s%s%s  %s%s\n", styleCode, colorCode, glyphs().warn, text, resetCode)
 	contextLine := fmt.Sprintf("   %sState saved at step %d/%d — resume with 'snap'%s",
 		dimCode, currentStep, totalSteps, resetCode)
 
@@ -257,7 +250,7 @@ func FormatDuration(d time.Duration) string {
 // Uses success color for the checkmark, dim weight for text and duration.
 func StepComplete(text string, elapsed time.Duration) string {
 	durationStr := FormatDuration(elapsed)
-	prefix := " ✓ " + StripColors(text)
+	prefix := " " + glyphs().check + " " + StripColors(text)
 	return rightAlignedLine(prefix, durationStr,
 		ResolveColor(ColorSuccess), ResolveStyle(WeightBold),
 		ResolveStyle(WeightDim), ResolveColor(ColorDim))
@@ -267,7 +260,7 @@ func StepComplete(text string, elapsed time.Duration) string {
 // Uses error color for the X mark, dim weight for text and duration.
 func StepFailed(text string, elapsed time.Duration) string {
 	durationStr := FormatDuration(elapsed)
-	prefix := " ✗ " + StripColors(text)
+	prefix := " " + glyphs().cross + " " + StripColors(text)
 	return rightAlignedLine(prefix, durationStr,
 		ResolveColor(ColorError), ResolveStyle(WeightBold),
 		ResolveStyle(WeightDim), ResolveColor(ColorDim))
@@ -277,14 +270,14 @@ func StepFailed(text string, elapsed time.Duration) string {
 // Uses celebrate color for the sparkle and text, dim for the duration.
 func CompleteWithDuration(text string, elapsed time.Duration) string {
 	durationStr := FormatDuration(elapsed)
-	prefix := "✨ " + text
+	prefix := emoji("✨") + text
 	colorCode := ResolveColor(ColorCelebrate)
 	styleCode := ResolveStyle(WeightBold)
 	dimCode := ResolveStyle(WeightDim)
 	dimColor := ResolveColor(ColorDim)
 	resetCode := ResolveStyle(WeightNormal)
 
-	padWidth := SeparatorWidth - utf8.RuneCountInString(prefix) - len(durationStr)
+	padWidth := Width() - utf8.RuneCountInString(prefix) - len(durationStr)
 	if padWidth < 1 {
 		padWidth = 1
 	}
@@ -298,7 +291,7 @@ func CompleteWithDuration(text string, elapsed time.Duration) string {
 
 // rightAlignedLine renders a line with an icon prefix and right-aligned duration.
 // The icon (first 2 runes of prefix) uses iconColor + iconStyle; the rest uses dimStyle;
-// the duration uses dimStyle + dimColor. Total visible width = SeparatorWidth.
+// the duration uses dimStyle + dimColor. Total visible width = Width().
 func rightAlignedLine(prefix, durationStr, iconColor, iconStyle, dimStyle, dimColor string) string {
 	resetCode := ResolveStyle(WeightNormal)
 
@@ -308,7 +301,7 @@ func rightAlignedLine(prefix, durationStr, iconColor, iconStyle, dimStyle, dimCo
 	icon := string(runes[:2]) // " ✓" or " ✗"
 	text := string(runes[2:]) // " Step complete"
 
-	padWidth := SeparatorWidth - utf8.RuneCountInString(prefix) - len(durationStr)
+	padWidth := Width() - utf8.RuneCountInString(prefix) - len(durationStr)
 	if padWidth < 1 {
 		padWidth = 1
 	}
@@ -321,15 +314,44 @@ func rightAlignedLine(prefix, durationStr, iconColor, iconStyle, dimStyle, dimCo
 		resetCode)
 }
 
-// FormatStartupSummary returns a plain-text startup summary line (no ANSI codes).
-// Format: snap: <tasksDir> | <provider> | <N> tasks (<M> done) | <action>.
-func FormatStartupSummary(tasksDir, provider string, taskCount, doneCount int, action string) string {
+// StartupSummary is the data shown in the startup summary line.
+type StartupSummary struct {
+	DisplayName string `json:"display_name"`       // Session name or tasks directory
+	Provider    string `json:"provider,omitempty"` // Omitted from the line when empty
+	Models      string `json:"models,omitempty"`   // Pinned model names (e.g. "fast: haiku, thinking: opus"); omitted when empty
+	TaskCount   int    `json:"task_count"`
+	DoneCount   int    `json:"done_count"`
+	Action      string `json:"action"` // e.g. "starting TASK1", "resuming TASK2 from step 5"
+}
+
+// String renders the summary as plain text (no ANSI codes).
+// Format: snap: <displayName> | <provider> | [<models> |] <N> tasks (<M> done) | <action>.
+func (s StartupSummary) String() string {
 	noun := "tasks"
-	if taskCount == 1 {
+	if s.TaskCount == 1 {
 		noun = "task"
 	}
-	return fmt.Sprintf("snap: %s | %s | %d %s (%d done) | %s",
-		tasksDir, provider, taskCount, noun, doneCount, action)
+	parts := []string{"snap: " + s.DisplayName}
+	if s.Provider != "" {
+		parts = append(parts, s.Provider)
+	}
+	if s.Models != "" {
+		parts = append(parts, s.Models)
+	}
+	parts = append(parts, fmt.Sprintf("%d %s (%d done)", s.TaskCount, noun, s.DoneCount), s.Action)
+	return strings.Join(parts, " | ")
+}
+
+// FormatStartupSummary returns a plain-text startup summary line (no ANSI codes).
+// Format: snap: <tasksDir> | <provider> | <N> tasks (<M> done) | <action>.
+func FormatStartupSummary(tasksDir, provider string, taskCount, doneCount int, action string) string {
+	return StartupSummary{
+		DisplayName: tasksDir,
+		Provider:    provider,
+		TaskCount:   taskCount,
+		DoneCount:   doneCount,
+		Action:      action,
+	}.String()
 }
 
 // KeyValue renders a key-value pair. Key in bold, value in normal weight,
@@ -378,6 +400,36 @@ func TaskActive(text, suffix string) string {
 		styleCode, colorCode, resetCode, sanitized, suffixPart)
 }
 
+// DiffStat renders `git diff --stat` output indented by 2 spaces, with the
+// "+" run of each file's graph in success color, the "-" run in error color,
+// and the trailing "N files changed" summary dimmed.
+func DiffStat(stat string) string {
+	addCode := ResolveColor(ColorSuccess)
+	delCode := ResolveColor(ColorError)
+	dimCode := ResolveStyle(WeightDim)
+	resetCode := ResolveStyle(WeightNormal)
+
+	var b strings.Builder
+	for _, line := range strings.Split(StripColors(stat), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sep := strings.LastIndex(line, "|")
+		if sep < 0 {
+			fmt.Fprintf(&b, "  %s%s%s\n", dimCode, line, resetCode)
+			continue
+		}
+		graph := line[sep+1:]
+		counts := strings.TrimRight(graph, "+-")
+		bar := graph[len(counts):]
+		adds := strings.Count(bar, "+")
+		fmt.Fprintf(&b, "  %s|%s%s%s%s%s%s%s\n",
+			line[:sep], counts, addCode, bar[:adds], resetCode, delCode, bar[adds:], resetCode)
+	}
+	return b.String()
+}
+
 // StripColors removes ANSI escape sequences from a string (useful for testing and sanitization).
 func StripColors(s string) string {
 	// Match all ANSI escape sequences:
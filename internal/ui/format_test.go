@@ -1,6 +1,7 @@
 package ui_test
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -456,6 +457,25 @@ func TestFormatStartupSummary(t *testing.T) {
 	}
 }
 
+func TestStartupSummary_OmitsEmptyProvider(t *testing.T) {
+	summary := ui.StartupSummary{DisplayName: "auth", TaskCount: 3, DoneCount: 1, Action: "starting TASK2"}
+	assert.Equal(t, "snap: auth | 3 tasks (1 done) | starting TASK2", summary.String())
+}
+
+func TestStartupSummary_PinnedModels(t *testing.T) {
+	summary := ui.StartupSummary{DisplayName: "auth", Provider: "claude", Models: "fast: haiku, thinking: opus", TaskCount: 3, DoneCount: 1, Action: "starting TASK2"}
+	assert.Equal(t, "snap: auth | claude | fast: haiku, thinking: opus | 3 tasks (1 done) | starting TASK2", summary.String())
+}
+
+func TestStartupSummary_JSON(t *testing.T) {
+	summary := ui.StartupSummary{DisplayName: "auth", Provider: "claude", TaskCount: 3, DoneCount: 1, Action: "starting TASK2"}
+	data, err := json.Marshal(summary)
+	require.NoError(t, err)
+	assert.JSONEq(t,
+		`{"display_name":"auth","provider":"claude","task_count":3,"done_count":1,"action":"starting TASK2"}`,
+		string(data))
+}
+
 func TestFormatStartupSummaryContainsNoANSI(t *testing.T) {
 	result := ui.FormatStartupSummary("docs/tasks/", "claude", 3, 1, "starting TASK2")
 	// Summary line must contain no ANSI escape sequences.
@@ -526,3 +546,29 @@ func TestTaskActive_EmptySuffix(t *testing.T) {
 	assert.Equal(t, "  [~] TASK2\n", result)
 	assert.NotContains(t, result, "(", "Empty suffix should not include parentheses")
 }
+
+func TestDiffStat_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	ui.ResetColorMode()
+	t.Cleanup(func() { ui.ResetColorMode() })
+
+	stat := " cmd/run.go  | 12 +++++++---\n logo.png    | Bin 0 -> 42 bytes\n 2 files changed, 9 insertions(+), 3 deletions(-)"
+	result := ui.DiffStat(stat)
+
+	assert.NotContains(t, result, "\033[", "output should contain no ANSI escape sequences when NO_COLOR=1")
+	assert.Equal(t,
+		"  cmd/run.go  | 12 +++++++---\n  logo.png    | Bin 0 -> 42 bytes\n  2 files changed, 9 insertions(+), 3 deletions(-)\n",
+		result)
+}
+
+func TestDiffStat_WithColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	ui.ResetColorMode()
+	t.Cleanup(func() { ui.ResetColorMode() })
+
+	result := ui.DiffStat(" main.go | 3 ++-")
+
+	assert.Contains(t, result, ui.ResolveColor(ui.ColorSuccess)+"++", "additions should use success color")
+	assert.Contains(t, result, ui.ResolveColor(ui.ColorError)+"-", "deletions should use error color")
+	assert.Equal(t, "  main.go | 3 ++-\n", ui.StripColors(result))
+}
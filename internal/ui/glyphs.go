@@ -0,0 +1,113 @@
+package ui
+
+import "unicode/utf8"
+
+// Output preferences beyond color. Like colorsEnabled they are process-wide
+// and set once before output starts, from flags or a session's meta.json.
+var (
+	asciiMode    bool
+	emojiEnabled = true
+	lineWidth    = SeparatorWidth
+)
+
+// MinWidth is the narrowest line width SetWidth accepts.
+const MinWidth = 40
+
+// glyphSet holds the symbols the formatters draw.
+type glyphSet struct {
+	arrow    string // Header and step marker
+	check    string // Success; a single rune, see rightAlignedLine
+	cross    string // Failure; a single rune, see rightAlignedLine
+	warn     string
+	branch   string // Detail line under an error
+	bullet   string
+	ellipsis string
+	hLine    string
+	vLine    string
+	corners  [4]string // Top left, top right, bottom left, bottom right
+	spinner  []string  // Drawn in order, one per tick
+}
+
+var unicodeGlyphs = glyphSet{
+	arrow: "▶", check: "✓", cross: "✗", warn: "⚠", branch: "└─", bullet: "•", ellipsis: "…",
+	hLine: "─", vLine: "│", corners: [4]string{"┌", "┐", "└", "┘"},
+	spinner: []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+}
+
+var asciiGlyphs = glyphSet{
+	arrow: ">", check: "+", cross: "x", warn: "!", branch: "`-", bullet: "*", ellipsis: "...",
+	hLine: "-", vLine: "|", corners: [4]string{"+", "+", "+", "+"},
+	spinner: []string{"|", "/", "-", `\`},
+}
+
+// SetASCII switches the formatters to ASCII stand-ins for box drawing,
+// symbols and the spinner, and leaves out emoji, for terminals and
+// screenshots without Unicode.
+func SetASCII(enabled bool) {
+	asciiMode = enabled
+}
+
+// ASCIIEnabled reports whether ASCII mode is on.
+func ASCIIEnabled() bool {
+	return asciiMode
+}
+
+// SetEmoji turns the emoji in headers and boxes (🔧, ✨, 📌, ⏳, 📋) on or
+// off. ASCII mode always leaves them out.
+func SetEmoji(enabled bool) {
+	emojiEnabled = enabled
+}
+
+// EmojiEnabled reports whether emoji are drawn.
+func EmojiEnabled() bool {
+	return emojiEnabled && !asciiMode
+}
+
+// SetWidth sets the width of separators, boxes and right-aligned durations.
+// Zero or less restores the default (SeparatorWidth); values under MinWidth
+// are raised to it.
+func SetWidth(n int) {
+	switch {
+	case n <= 0:
+		lineWidth = SeparatorWidth
+	case n < MinWidth:
+		lineWidth = MinWidth
+	default:
+		lineWidth = n
+	}
+}
+
+// Width returns the active line width.
+func Width() int {
+	return lineWidth
+}
+
+// glyphs returns the symbol set for the current mode.
+func glyphs() glyphSet {
+	if asciiMode {
+		return asciiGlyphs
+	}
+	return unicodeGlyphs
+}
+
+// emoji returns e followed by a space, or "" when emoji are off.
+func emoji(e string) string {
+	if !EmojiEnabled() {
+		return ""
+	}
+	return e + " "
+}
+
+// truncate shortens s to at most n runes, ending it with the ellipsis glyph
+// when cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	ellipsis := glyphs().ellipsis
+	keep := n - utf8.RuneCountInString(ellipsis)
+	if keep < 0 {
+		keep = 0
+	}
+	return string([]rune(s)[:keep]) + ellipsis
+}
@@ -0,0 +1,72 @@
+package ui_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// setOutputPrefs applies output preferences for one test and restores the
+// defaults afterwards.
+func setOutputPrefs(t *testing.T, ascii, emoji bool, width int) {
+	t.Helper()
+	t.Cleanup(func() {
+		ui.SetASCII(false)
+		ui.SetEmoji(true)
+		ui.SetWidth(0)
+	})
+	ui.SetASCII(ascii)
+	ui.SetEmoji(emoji)
+	ui.SetWidth(width)
+}
+
+func TestSetASCII_UsesASCIIGlyphs(t *testing.T) {
+	setOutputPrefs(t, true, true, 0)
+
+	out := ui.StripColors(ui.StepNumbered(1, 9, "Implement") +
+		ui.StepComplete("Step complete", time.Second) +
+		ui.ErrorWithDetails("failed", []string{"detail"}) +
+		ui.Complete("done") +
+		ui.QueuedPrompt("fix it", 1, 9, "Implement", 1) +
+		ui.Separator())
+
+	for _, r := range out {
+		assert.Less(t, r, rune(utf8.RuneSelf), "non-ASCII rune %q in %q", r, out)
+	}
+	assert.Contains(t, out, "> Step 1/9: Implement")
+	assert.Contains(t, out, " + Step complete")
+	assert.Contains(t, out, "`- detail")
+	assert.Contains(t, out, "+- Queued -")
+	assert.False(t, ui.EmojiEnabled(), "ASCII mode leaves out emoji")
+}
+
+func TestSetEmoji_Off(t *testing.T) {
+	setOutputPrefs(t, false, false, 0)
+
+	assert.Equal(t, "\ndone\n", ui.StripColors(ui.Complete("done")))
+	assert.Equal(t, " go test", ui.StripColors(ui.Tool("go test")))
+	assert.Contains(t, ui.StripColors(ui.QueueRunning("fix it", 1, 2)), "┌─ Running queued prompt (1/2) ─")
+}
+
+func TestSetWidth(t *testing.T) {
+	setOutputPrefs(t, false, true, 50)
+
+	assert.Equal(t, 50, ui.Width())
+	assert.Equal(t, 50, utf8.RuneCountInString(ui.StripColors(ui.StepComplete("Step complete", time.Second))))
+	assert.Equal(t, strings.Repeat("─", 50)+"\n", ui.StripColors(ui.Separator()))
+	for _, line := range strings.Split(strings.TrimSpace(ui.StripColors(ui.QueuedPrompt("fix it", 1, 9, "Test", 1))), "\n") {
+		if strings.HasPrefix(line, "│") {
+			assert.Equal(t, 50, utf8.RuneCountInString(line), "box line %q", line)
+		}
+	}
+
+	ui.SetWidth(10)
+	assert.Equal(t, ui.MinWidth, ui.Width())
+	ui.SetWidth(0)
+	assert.Equal(t, ui.SeparatorWidth, ui.Width())
+}
@@ -7,7 +7,9 @@ import (
 )
 
 // boxContentWidth is the usable width inside a box line ("│ " content " │").
-const boxContentWidth = BoxWidth - 4
+func boxContentWidth() int {
+	return Width() - 4
+}
 
 // QueuedPrompt formats the boxed acknowledgment shown when a user prompt is queued.
 // It shows the prompt text, which step is currently running, and how many prompts are queued.
@@ -21,7 +23,7 @@ func QueuedPrompt(prompt string, currentStep, totalSteps int, stepName string, q
 	dimCode := ResolveStyle(WeightDim)
 
 	// Top border with title
-	topBorder := boxTopBorder("📌 Queued", styleCode, colorCode, resetCode)
+	topBorder := boxTopBorder(emoji("📌")+"Queued", styleCode, colorCode, resetCode)
 
 	// Prompt text line
 	promptLine := boxLine(fitText(prompt), styleCode, colorCode, resetCode)
@@ -30,7 +32,7 @@ func QueuedPrompt(prompt string, currentStep, totalSteps int, stepName string, q
 	emptyLine := boxLine("", styleCode, colorCode, resetCode)
 
 	// Waiting indicator
-	waitText := fmt.Sprintf("⏳ Waiting for Step %d/%d: %s", currentStep, totalSteps, stepName)
+	waitText := fmt.Sprintf("%sWaiting for Step %d/%d: %s", emoji("⏳"), currentStep, totalSteps, stepName)
 	waitLine := boxLine(fitText(waitText), dimCode, colorCode, resetCode)
 
 	// Queue count
@@ -38,7 +40,7 @@ func QueuedPrompt(prompt string, currentStep, totalSteps int, stepName string, q
 	if queueLen == 1 {
 		noun = "prompt"
 	}
-	countText := fmt.Sprintf("📋 %d %s in queue", queueLen, noun)
+	countText := fmt.Sprintf("%s%d %s in queue", emoji("📋"), queueLen, noun)
 	countLine := boxLine(fitText(countText), dimCode, colorCode, resetCode)
 
 	// Bottom border
@@ -60,7 +62,7 @@ func QueueRunning(prompt string, current, total int) string {
 	styleCode := ResolveStyle(WeightBold)
 	resetCode := ResolveStyle(WeightNormal)
 
-	title := fmt.Sprintf("📌 Running queued prompt (%d/%d)", current, total)
+	title := fmt.Sprintf("%sRunning queued prompt (%d/%d)", emoji("📌"), current, total)
 	topBorder := boxTopBorder(title, styleCode, colorCode, resetCode)
 	promptLine := boxLine(fitText(prompt), styleCode, colorCode, resetCode)
 	bottomBorder := boxBottomBorder(styleCode, colorCode, resetCode)
@@ -79,7 +81,7 @@ func QueueStatus(prompts []string) string {
 	resetCode := ResolveStyle(WeightNormal)
 
 	if len(prompts) == 0 {
-		return fmt.Sprintf("\n%s📋 Queue empty — no prompts pending%s\n", dimCode, resetCode)
+		return fmt.Sprintf("\n%s%sQueue empty — no prompts pending%s\n", dimCode, emoji("📋"), resetCode)
 	}
 
 	noun := "prompts"
@@ -88,8 +90,8 @@ func QueueStatus(prompts []string) string {
 	}
 
 	var builder strings.Builder
-	fmt.Fprintf(&builder, "\n%s📋 Queue (%d %s pending):%s\n",
-		dimCode, len(prompts), noun, resetCode)
+	fmt.Fprintf(&builder, "\n%s%sQueue (%d %s pending):%s\n",
+		dimCode, emoji("📋"), len(prompts), noun, resetCode)
 
 	for i, p := range prompts {
 		fmt.Fprintf(&builder, "%s  %d. %s%s\n", dimCode, i+1, StripColors(p), resetCode)
@@ -100,44 +102,44 @@ func QueueStatus(prompts []string) string {
 
 // boxTopBorder builds a titled top border (e.g. ┌─ title ────┐).
 func boxTopBorder(title, styleCode, colorCode, resetCode string) string {
+	g := glyphs()
 	titleLen := utf8.RuneCountInString(title)
-	dashCount := BoxWidth - 4 - titleLen - 2 // "┌─ " + title + " " + dashes + "┐"
+	dashCount := Width() - 4 - titleLen - 2 // "┌─ " + title + " " + dashes + "┐"
 	if dashCount < 1 {
 		dashCount = 1
 	}
-	return fmt.Sprintf("%s%s┌─ %s %s┐%s\n",
+	return fmt.Sprintf("%s%s%s%s %s %s%s%s\n",
 		styleCode, colorCode,
+		g.corners[0], g.hLine,
 		title,
-		strings.Repeat("─", dashCount),
+		strings.Repeat(g.hLine, dashCount), g.corners[1],
 		resetCode)
 }
 
 // boxLine builds a content line (e.g. │ text │).
 func boxLine(text, styleCode, colorCode, resetCode string) string {
-	padding := boxContentWidth - utf8.RuneCountInString(text)
+	padding := boxContentWidth() - utf8.RuneCountInString(text)
 	if padding < 0 {
 		padding = 0
 	}
-	return fmt.Sprintf("%s%s│ %s%s │%s\n",
+	vLine := glyphs().vLine
+	return fmt.Sprintf("%s%s%s %s%s %s%s\n",
 		styleCode, colorCode,
-		text,
+		vLine, text,
 		strings.Repeat(" ", padding),
-		resetCode)
+		vLine, resetCode)
 }
 
 // boxBottomBorder builds a bottom border (e.g. └──────────┘).
 func boxBottomBorder(styleCode, colorCode, resetCode string) string {
-	return fmt.Sprintf("%s%s└%s┘%s",
+	g := glyphs()
+	return fmt.Sprintf("%s%s%s%s%s%s",
 		styleCode, colorCode,
-		strings.Repeat("─", BoxWidth-2),
+		g.corners[2], strings.Repeat(g.hLine, Width()-2), g.corners[3],
 		resetCode)
 }
 
 // fitText shortens text to boxContentWidth runes, adding "…" if truncated.
 func fitText(s string) string {
-	if utf8.RuneCountInString(s) <= boxContentWidth {
-		return s
-	}
-	runes := []rune(s)
-	return string(runes[:boxContentWidth-1]) + "…"
+	return truncate(s, boxContentWidth())
 }
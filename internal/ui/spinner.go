@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// clearLine returns to column 0 and erases the line.
+const clearLine = "\r\x1b[K"
+
+// pauser is implemented by writers that can hold output back, such as a
+// SwitchWriter while the user composes a directive.
+type pauser interface {
+	IsPaused() bool
+}
+
+// Spinner draws a dim "thinking" indicator with the elapsed time in place on
+// the current terminal line until it is stopped. Frames are skipped while the
+// writer is paused, so nothing piles up in its buffer.
+type Spinner struct {
+	mu      sync.Mutex
+	w       io.Writer
+	start   time.Time
+	frame   int
+	visible bool
+	stopped bool
+	done    chan struct{}
+}
+
+// StartSpinner starts drawing on w every interval. The first frame is drawn
+// after one interval, so calls that answer quickly show nothing.
+func StartSpinner(w io.Writer, interval time.Duration) *Spinner {
+	s := &Spinner{w: w, start: time.Now(), done: make(chan struct{})}
+	go s.loop(interval)
+	return s
+}
+
+// Stop ends the animation and erases the indicator if it is shown. Calling
+// Stop more than once is safe.
+func (s *Spinner) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.done)
+	if s.visible {
+		//nolint:errcheck // Best-effort clear; the indicator is transient UI output.
+		_, _ = io.WriteString(s.w, clearLine)
+		s.visible = false
+	}
+}
+
+func (s *Spinner) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.draw()
+		}
+	}
+}
+
+func (s *Spinner) draw() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	if p, ok := s.w.(pauser); ok && p.IsPaused() {
+		return
+	}
+	frames := glyphs().spinner
+	frame := frames[s.frame%len(frames)]
+	s.frame++
+	styleCode := ResolveStyle(WeightDim)
+	resetCode := ResolveStyle(WeightNormal)
+	//nolint:errcheck // Best-effort draw; the indicator is transient UI output.
+	_, _ = fmt.Fprintf(s.w, "%s%s%s thinking %s%s",
+		clearLine, styleCode, frame, FormatDuration(time.Since(s.start)), resetCode)
+	s.visible = true
+}
@@ -0,0 +1,70 @@
+package ui_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// lockedBuffer is a bytes.Buffer safe for the spinner goroutine and the test.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSpinner_DrawsAndClears(t *testing.T) {
+	var buf lockedBuffer
+	s := ui.StartSpinner(&buf, time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return strings.Count(buf.String(), "thinking") >= 2
+	}, time.Second, time.Millisecond)
+	s.Stop()
+	s.Stop()
+
+	output := buf.String()
+	assert.Contains(t, ui.StripColors(output), "⠋ thinking 0s")
+	assert.True(t, strings.HasSuffix(output, "\r\x1b[K"), "indicator is erased on stop")
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, output, buf.String(), "nothing is drawn after stop")
+}
+
+func TestSpinner_StopBeforeFirstFrame(t *testing.T) {
+	var buf lockedBuffer
+	s := ui.StartSpinner(&buf, time.Hour)
+	s.Stop()
+
+	assert.Empty(t, buf.String())
+}
+
+func TestSpinner_SkipsFramesWhilePaused(t *testing.T) {
+	var buf lockedBuffer
+	sw := ui.NewSwitchWriter(&buf)
+	sw.Pause()
+
+	s := ui.StartSpinner(sw, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	s.Stop()
+	sw.Resume()
+
+	assert.Empty(t, buf.String())
+}
@@ -34,6 +34,23 @@ func ResetColorMode() {
 	colorsEnabled = os.Getenv("NO_COLOR") == ""
 }
 
+// DisableColors turns off ANSI escape codes for the rest of the process,
+// e.g. when output is redirected to a file.
+func DisableColors() {
+	colorsEnabled = false
+}
+
+// ColorsEnabled reports whether ANSI escape codes are being emitted.
+func ColorsEnabled() bool {
+	return colorsEnabled
+}
+
+// SetColorsEnabled turns ANSI escape codes on or off, e.g. to restore a
+// value saved with ColorsEnabled.
+func SetColorsEnabled(enabled bool) {
+	colorsEnabled = enabled
+}
+
 // ColorToken represents a semantic color role in the UI design system.
 type ColorToken string
 
@@ -76,7 +93,7 @@ const (
 
 // Box drawing constants.
 const (
-	BoxWidth        = 70 // Main box width
+	BoxWidth        = 70 // Default main box width; Width() is the active one
 	BoxPaddingLeft  = 2  // Chars of padding inside box
 	BoxPaddingRight = 2
 
@@ -84,7 +101,7 @@ const (
 	IndentTool   = 1 // Tools indented 1 char
 	IndentResult = 1 // Results aligned with tools
 
-	SeparatorWidth = 70 // Match box width
+	SeparatorWidth = 70 // Match box width; default for Width()
 )
 
 // ANSI color mappings for each ColorToken (256-color palette).
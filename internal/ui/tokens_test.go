@@ -162,3 +162,15 @@ func TestNoColor_AnyNonEmptyValueDisables(t *testing.T) {
 		})
 	}
 }
+
+func TestSetColorsEnabled_RestoresSavedValue(t *testing.T) {
+	prev := ui.ColorsEnabled()
+	t.Cleanup(func() { ui.SetColorsEnabled(prev) })
+
+	ui.DisableColors()
+	assert.Empty(t, ui.ResolveColor(ui.ColorPrimary))
+
+	ui.SetColorsEnabled(true)
+	assert.True(t, ui.ColorsEnabled())
+	assert.NotEmpty(t, ui.ResolveColor(ui.ColorPrimary))
+}
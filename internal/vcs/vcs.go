@@ -0,0 +1,109 @@
+// Package vcs runs version-control commands — git, and the gh CLI for
+// GitHub — behind a small interface so callers can be tested with stubs
+// instead of real repositories and PATH script mocks.
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Runner executes commands of a single VCS CLI.
+type Runner interface {
+	// Run executes the command and discards stdout.
+	Run(ctx context.Context, args ...string) error
+	// Output executes the command and returns its raw stdout.
+	Output(ctx context.Context, args ...string) (string, error)
+}
+
+// Command is the default Runner: it shells out to the named binary.
+type Command struct {
+	Name string // Binary to execute, resolved via PATH
+	Dir  string // Working directory (empty = current directory)
+}
+
+// Git returns a Runner for git in dir.
+func Git(dir string) *Command {
+	return &Command{Name: "git", Dir: dir}
+}
+
+// GH returns a Runner for the GitHub CLI in the current directory.
+func GH() *Command {
+	return &Command{Name: "gh"}
+}
+
+// Run executes the command and discards stdout.
+func (c *Command) Run(ctx context.Context, args ...string) error {
+	_, err := c.Output(ctx, args...)
+	return err
+}
+
+// Output executes the command and returns its raw stdout. Failures are
+// returned as *Error.
+func (c *Command) Output(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, c.Name, args...)
+	cmd.Dir = c.Dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		return "", &Error{
+			Args:     args,
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+			ExitCode: exitCode,
+			Err:      err,
+		}
+	}
+	return stdout.String(), nil
+}
+
+// Error describes a failed command with its captured output.
+type Error struct {
+	Args     []string
+	Stdout   string
+	Stderr   string
+	ExitCode int // -1 when the command did not run to completion
+	Err      error
+}
+
+func (e *Error) Error() string {
+	msg := strings.TrimSpace(e.Stderr)
+	if msg == "" {
+		msg = strings.TrimSpace(e.Stdout)
+	}
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	return strings.Join(e.Args, " ") + ": " + msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Stderr returns the trimmed stderr of err when it is an *Error, or "".
+func Stderr(err error) string {
+	var vErr *Error
+	if errors.As(err, &vErr) {
+		return strings.TrimSpace(vErr.Stderr)
+	}
+	return ""
+}
+
+// ExitCode returns the exit code of err when it is an *Error, or -1.
+func ExitCode(err error) int {
+	var vErr *Error
+	if errors.As(err, &vErr) {
+		return vErr.ExitCode
+	}
+	return -1
+}
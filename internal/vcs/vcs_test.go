@@ -0,0 +1,45 @@
+package vcs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/vcs"
+)
+
+func TestCommand_Output(t *testing.T) {
+	out, err := vcs.Git(t.TempDir()).Output(context.Background(), "--version")
+	require.NoError(t, err)
+	assert.Contains(t, out, "git version")
+}
+
+func TestCommand_ErrorCapturesStderrAndExitCode(t *testing.T) {
+	err := vcs.Git(t.TempDir()).Run(context.Background(), "status")
+	require.Error(t, err)
+
+	var vErr *vcs.Error
+	require.True(t, errors.As(err, &vErr))
+	assert.Equal(t, []string{"status"}, vErr.Args)
+	assert.NotZero(t, vErr.ExitCode)
+	assert.Equal(t, vErr.ExitCode, vcs.ExitCode(err))
+	assert.Contains(t, vcs.Stderr(err), "not a git repository")
+	assert.Contains(t, err.Error(), "status: ")
+}
+
+func TestCommand_MissingBinary(t *testing.T) {
+	c := &vcs.Command{Name: "snap-no-such-binary"}
+	_, err := c.Output(context.Background(), "x")
+	require.Error(t, err)
+	assert.Equal(t, -1, vcs.ExitCode(err))
+	assert.Empty(t, vcs.Stderr(err))
+}
+
+func TestHelpers_NonVCSError(t *testing.T) {
+	err := errors.New("boom")
+	assert.Equal(t, -1, vcs.ExitCode(err))
+	assert.Empty(t, vcs.Stderr(err))
+}
@@ -0,0 +1,171 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// ErrChecksFailed reports that a lint/test step ended with SNAP-CHECKS: FAIL
+// while Config.FailFastOnLint is set.
+var ErrChecksFailed = errors.New("lint/test checks failed")
+
+// checksResultPattern matches the result line the lint-and-test prompt asks
+// for when Config.FailFastOnLint is set.
+var checksResultPattern = regexp.MustCompile(`SNAP-CHECKS:\s*(PASS|FAIL)\b`)
+
+// parseChecksResult returns the last SNAP-CHECKS result in a step's output.
+// reported is false when the model didn't emit one.
+func parseChecksResult(output string) (passed, reported bool) {
+	matches := checksResultPattern.FindAllStringSubmatch(ui.StripColors(output), -1)
+	if len(matches) == 0 {
+		return false, false
+	}
+	return matches[len(matches)-1][1] == "PASS", true
+}
+
+// Checks holds the lint and test commands handed to the lint-and-test step.
+// An empty command leaves discovery to the model.
+type Checks struct {
+	Lint string
+	Test string
+}
+
+// makefileNames are the file names make looks for, in its lookup order.
+var makefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+// golangciConfigs are the config file names golangci-lint picks up.
+var golangciConfigs = []string{".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}
+
+// npmPlaceholderTest is the test script npm init writes; it always fails.
+const npmPlaceholderTest = "no test specified"
+
+// DetectChecks inspects the project in dir for its lint and test commands.
+// Makefile test/lint targets win, since they are how the project says to
+// run its checks; otherwise go.mod, golangci-lint config and package.json
+// scripts are used.
+func DetectChecks(dir string) Checks {
+	var c Checks
+	targets := makeTargets(dir)
+	if targets["lint"] {
+		c.Lint = "make lint"
+	}
+	if targets["test"] {
+		c.Test = "make test"
+	}
+
+	if c.Lint == "" && hasAnyFile(dir, golangciConfigs...) {
+		c.Lint = "golangci-lint run"
+	}
+	if hasAnyFile(dir, "go.mod") {
+		if c.Lint == "" {
+			c.Lint = "go vet ./..."
+		}
+		if c.Test == "" {
+			c.Test = "go test ./..."
+		}
+	}
+
+	if scripts := packageScripts(dir); scripts != nil {
+		pm := nodePackageManager(dir)
+		if c.Lint == "" && scripts["lint"] != "" {
+			c.Lint = pm + " run lint"
+		}
+		if c.Test == "" && scripts["test"] != "" && !strings.Contains(scripts["test"], npmPlaceholderTest) {
+			c.Test = pm + " test"
+		}
+	}
+	return c
+}
+
+// resolveChecks applies the Config overrides on top of detection in dir.
+func resolveChecks(cfg Config, dir string) Checks {
+	c := DetectChecks(dir)
+	if cfg.LintCommand != "" {
+		c.Lint = cfg.LintCommand
+	}
+	if cfg.TestCommand != "" {
+		c.Test = cfg.TestCommand
+	}
+	return c
+}
+
+// formatChecks renders the commands for the startup output, or "" when
+// nothing was configured or detected.
+func formatChecks(c Checks) string {
+	var parts []string
+	if c.Lint != "" {
+		parts = append(parts, "lint `"+c.Lint+"`")
+	}
+	if c.Test != "" {
+		parts = append(parts, "test `"+c.Test+"`")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Checks: " + strings.Join(parts, ", ")
+}
+
+// makeTargetPattern matches a rule line; ":=" assignments are excluded.
+var makeTargetPattern = regexp.MustCompile(`(?m)^([A-Za-z0-9_.-]+)\s*:(?:[^=]|$)`)
+
+// makeTargets returns the targets defined in the first Makefile found in dir.
+func makeTargets(dir string) map[string]bool {
+	for _, name := range makefileNames {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		targets := make(map[string]bool)
+		for _, m := range makeTargetPattern.FindAllStringSubmatch(string(data), -1) {
+			targets[m[1]] = true
+		}
+		return targets
+	}
+	return nil
+}
+
+// packageScripts returns the scripts in dir/package.json, or nil when there
+// is no readable package.json.
+func packageScripts(dir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	if pkg.Scripts == nil {
+		return map[string]string{}
+	}
+	return pkg.Scripts
+}
+
+// nodePackageManager picks the package manager from the lockfile in dir.
+func nodePackageManager(dir string) string {
+	switch {
+	case hasAnyFile(dir, "pnpm-lock.yaml"):
+		return "pnpm"
+	case hasAnyFile(dir, "yarn.lock"):
+		return "yarn"
+	default:
+		return "npm"
+	}
+}
+
+func hasAnyFile(dir string, names ...string) bool {
+	for _, name := range names {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
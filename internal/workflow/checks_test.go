@@ -0,0 +1,129 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Checks
+	}{
+		{
+			name: "empty project",
+			want: Checks{},
+		},
+		{
+			name:  "go module",
+			files: map[string]string{"go.mod": "module example.com/x\n"},
+			want:  Checks{Lint: "go vet ./...", Test: "go test ./..."},
+		},
+		{
+			name: "go module with golangci-lint config",
+			files: map[string]string{
+				"go.mod":        "module example.com/x\n",
+				".golangci.yml": "linters: {}\n",
+			},
+			want: Checks{Lint: "golangci-lint run", Test: "go test ./..."},
+		},
+		{
+			name: "makefile targets win",
+			files: map[string]string{
+				"go.mod":   "module example.com/x\n",
+				"Makefile": ".PHONY: lint test\nGOFLAGS := -race\n\nlint:\n\tgolangci-lint run\n\ntest: build\n\tgo test ./...\n",
+			},
+			want: Checks{Lint: "make lint", Test: "make test"},
+		},
+		{
+			name: "makefile without check targets falls through",
+			files: map[string]string{
+				"go.mod":   "module example.com/x\n",
+				"Makefile": "build:\n\tgo build ./...\ntest := 1\n",
+			},
+			want: Checks{Lint: "go vet ./...", Test: "go test ./..."},
+		},
+		{
+			name:  "npm scripts",
+			files: map[string]string{"package.json": `{"scripts": {"test": "vitest run", "lint": "eslint ."}}`},
+			want:  Checks{Lint: "npm run lint", Test: "npm test"},
+		},
+		{
+			name: "yarn lockfile",
+			files: map[string]string{
+				"package.json": `{"scripts": {"test": "jest"}}`,
+				"yarn.lock":    "",
+			},
+			want: Checks{Test: "yarn test"},
+		},
+		{
+			name:  "npm init placeholder test is ignored",
+			files: map[string]string{"package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`},
+			want:  Checks{},
+		},
+		{
+			name:  "invalid package.json",
+			files: map[string]string{"package.json": `{`},
+			want:  Checks{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				createFile(t, dir, name, content)
+			}
+			assert.Equal(t, tt.want, DetectChecks(dir))
+		})
+	}
+}
+
+func TestResolveChecks_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	createFile(t, dir, "go.mod", "module example.com/x\n")
+
+	got := resolveChecks(Config{TestCommand: "go test -race ./..."}, dir)
+	assert.Equal(t, Checks{Lint: "go vet ./...", Test: "go test -race ./..."}, got)
+
+	got = resolveChecks(Config{LintCommand: "staticcheck ./...", TestCommand: "make check"}, dir)
+	assert.Equal(t, Checks{Lint: "staticcheck ./...", Test: "make check"}, got)
+}
+
+func TestParseChecksResult(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		wantPassed   bool
+		wantReported bool
+	}{
+		{name: "pass", output: "all green\nSNAP-CHECKS: PASS\n", wantPassed: true, wantReported: true},
+		{name: "fail", output: "1 test failed\nSNAP-CHECKS: FAIL", wantReported: true},
+		{name: "last result wins", output: "SNAP-CHECKS: FAIL\nfixed it\nSNAP-CHECKS: PASS", wantPassed: true, wantReported: true},
+		{name: "ansi styled", output: "\x1b[1mSNAP-CHECKS:\x1b[0m FAIL", wantReported: true},
+		{name: "missing", output: "done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed, reported := parseChecksResult(tt.output)
+			assert.Equal(t, tt.wantPassed, passed)
+			assert.Equal(t, tt.wantReported, reported)
+		})
+	}
+}
+
+func TestFormatChecks(t *testing.T) {
+	assert.Empty(t, formatChecks(Checks{}))
+	assert.Equal(t, "Checks: test `go test ./...`", formatChecks(Checks{Test: "go test ./..."}))
+	assert.Equal(t, "Checks: lint `make lint`, test `make test`", formatChecks(Checks{Lint: "make lint", Test: "make test"}))
+}
+
+func TestDetectChecks_IgnoresDirectories(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "go.mod"), 0o755))
+	assert.Equal(t, Checks{}, DetectChecks(dir))
+}
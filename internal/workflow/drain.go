@@ -4,33 +4,111 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/yarlson/snap/internal/model"
 	"github.com/yarlson/snap/internal/queue"
 	"github.com/yarlson/snap/internal/ui"
 )
 
+// SkipDirective is the reserved directive that skips the current task
+// instead of running as a prompt.
+const SkipDirective = "/skip"
+
+// DrainResult records the outcome of a single queued prompt.
+type DrainResult struct {
+	Prompt   string
+	Err      error         // nil on success; the context error for prompts skipped on cancellation
+	Duration time.Duration // zero for skipped prompts
+	Skipped  bool          // true when the prompt never ran: the context was cancelled or the task was skipped
+	SkipTask bool          // true for SkipDirective, which asks to skip the current task
+}
+
+// Clock provides the time source used to space out drained prompts.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type drainConfig struct {
+	interval time.Duration
+	clock    Clock
+}
+
+// DrainOption configures DrainQueue behavior.
+type DrainOption func(*drainConfig)
+
+// WithDrainInterval sets the minimum time between the starts of consecutive
+// drained prompts. Zero (the default) runs prompts back-to-back.
+func WithDrainInterval(d time.Duration) DrainOption {
+	return func(c *drainConfig) {
+		c.interval = d
+	}
+}
+
+// WithDrainClock overrides the clock used to space prompts. Useful for testing.
+func WithDrainClock(clock Clock) DrainOption {
+	return func(c *drainConfig) {
+		c.clock = clock
+	}
+}
+
 // DrainQueue executes all queued prompts in FIFO order via the step runner.
 // Each prompt runs as a context-continuing invocation with autonomous and no-commit suffixes.
-// Errors are collected but do not stop execution of remaining prompts.
-// Returns nil if the queue was empty. Stops early if the context is cancelled.
-func DrainQueue(ctx context.Context, w io.Writer, stepRunner *StepRunner, q *queue.Queue) []error {
+// Errors are recorded but do not stop execution of remaining prompts.
+// Returns one result per drained prompt, or nil if the queue was empty.
+// Stops early if the context is cancelled, marking the remaining prompts as skipped.
+// SkipDirective runs nothing: it ends the drain, and the prompts queued after
+// it are skipped along with the task.
+func DrainQueue(ctx context.Context, w io.Writer, stepRunner *StepRunner, q *queue.Queue, opts ...DrainOption) []DrainResult {
 	prompts := q.DrainAll()
 	if len(prompts) == 0 {
 		return nil
 	}
 
-	var errs []error
+	cfg := drainConfig{clock: realClock{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	results := make([]DrainResult, 0, len(prompts))
 	total := len(prompts)
+	var lastStart time.Time
 
 	for i, prompt := range prompts {
+		// Space prompts at least the configured interval apart.
+		if cfg.interval > 0 && i > 0 {
+			if wait := cfg.interval - cfg.clock.Now().Sub(lastStart); wait > 0 {
+				select {
+				case <-ctx.Done():
+				case <-cfg.clock.After(wait):
+				}
+			}
+		}
+
 		// Check for context cancellation before executing each prompt.
 		if err := ctx.Err(); err != nil {
 			for _, skipped := range prompts[i:] {
 				fmt.Fprint(w, ui.Info(fmt.Sprintf("Skipped queued prompt: %s", skipped)))
+				results = append(results, DrainResult{Prompt: skipped, Err: err, Skipped: true})
+			}
+			return results
+		}
+
+		if isSkipDirective(prompt) {
+			results = append(results, DrainResult{Prompt: prompt, SkipTask: true})
+			for _, skipped := range prompts[i+1:] {
+				fmt.Fprint(w, ui.Info(fmt.Sprintf("Skipped queued prompt: %s", skipped)))
+				results = append(results, DrainResult{Prompt: skipped, Skipped: true})
 			}
-			errs = append(errs, err)
-			return errs
+			return results
 		}
 
 		fmt.Fprint(w, ui.QueueRunning(prompt, i+1, total))
@@ -39,12 +117,90 @@ func DrainQueue(ctx context.Context, w io.Writer, stepRunner *StepRunner, q *que
 		fullPrompt := BuildPrompt(prompt, WithNoCommit())
 
 		// Execute with -c flag to maintain session context.
-		if err := stepRunner.RunStep(ctx, fmt.Sprintf("Queued prompt %d/%d", i+1, total), model.Fast, "-c", fullPrompt); err != nil {
+		lastStart = cfg.clock.Now()
+		start := time.Now()
+		err := stepRunner.RunStep(ctx, fmt.Sprintf("Queued prompt %d/%d", i+1, total), model.Fast, continueFlag, fullPrompt)
+		if err != nil {
 			fmt.Fprint(w, ui.Error(fmt.Sprintf("Queued prompt failed: %v", err)))
 			fmt.Fprintln(w)
-			errs = append(errs, err)
 		}
+		results = append(results, DrainResult{Prompt: prompt, Err: err, Duration: time.Since(start)})
 	}
 
-	return errs
+	return results
+}
+
+// isSkipDirective reports whether a queued prompt is SkipDirective.
+func isSkipDirective(prompt string) bool {
+	return strings.EqualFold(strings.TrimSpace(prompt), SkipDirective)
+}
+
+// skipRequested reports whether a drain dequeued SkipDirective.
+func skipRequested(results []DrainResult) bool {
+	for _, r := range results {
+		if r.SkipTask {
+			return true
+		}
+	}
+	return false
+}
+
+// drainSummaryPromptLen caps how much of each prompt is shown in the summary.
+const drainSummaryPromptLen = 40
+
+// LogDrainSummary writes one success or failure line per executed prompt.
+// Skipped prompts are already reported by DrainQueue and are left out.
+func LogDrainSummary(w io.Writer, results []DrainResult) {
+	for _, r := range results {
+		if r.Skipped || r.SkipTask {
+			continue
+		}
+		label := fmt.Sprintf("Queued: %s", summarizePrompt(r.Prompt))
+		if r.Err != nil {
+			fmt.Fprintln(w, ui.StepFailed(label, r.Duration))
+		} else {
+			fmt.Fprintln(w, ui.StepComplete(label, r.Duration))
+		}
+	}
+}
+
+// appliedDirectives returns the prompts that ran successfully, in order.
+// Failed and skipped prompts left no mark on the task.
+func appliedDirectives(results []DrainResult) []string {
+	var applied []string
+	for _, r := range results {
+		if !r.Skipped && !r.SkipTask && r.Err == nil {
+			applied = append(applied, r.Prompt)
+		}
+	}
+	return applied
+}
+
+// DirectivesSummary renders "N directive(s) applied: a; b" with each prompt
+// shortened, or "" when there are none.
+func DirectivesSummary(directives []string) string {
+	if len(directives) == 0 {
+		return ""
+	}
+	label := "directives"
+	if len(directives) == 1 {
+		label = "directive"
+	}
+	short := make([]string, len(directives))
+	for i, d := range directives {
+		short[i] = summarizePrompt(d)
+	}
+	return fmt.Sprintf("%d %s applied: %s", len(directives), label, strings.Join(short, "; "))
+}
+
+// summarizePrompt shortens a prompt to a single line for summary output.
+func summarizePrompt(prompt string) string {
+	if i := strings.IndexAny(prompt, "\r\n"); i >= 0 {
+		prompt = prompt[:i] + "…"
+	}
+	if utf8.RuneCountInString(prompt) <= drainSummaryPromptLen {
+		return prompt
+	}
+	runes := []rune(prompt)
+	return string(runes[:drainSummaryPromptLen-1]) + "…"
 }
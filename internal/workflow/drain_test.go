@@ -6,8 +6,10 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/yarlson/snap/internal/model"
 	"github.com/yarlson/snap/internal/queue"
@@ -30,15 +32,45 @@ func TestDrainQueue_ExecutesAllPrompts(t *testing.T) {
 	q.Enqueue("add a test for empty input")
 
 	runner := workflow.NewStepRunner(mockExec, io.Discard)
-	errors := workflow.DrainQueue(context.Background(), io.Discard, runner, q)
+	results := workflow.DrainQueue(context.Background(), io.Discard, runner, q)
 
-	assert.Len(t, errors, 0)
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, "fix the nil pointer", results[0].Prompt)
 	assert.Equal(t, 2, len(executed))
 	// Prompts should contain the user text plus suffixes.
 	assert.Contains(t, executed[0], "fix the nil pointer")
 	assert.Contains(t, executed[1], "add a test for empty input")
 }
 
+func TestDrainQueue_SkipDirective(t *testing.T) {
+	var executed []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			executed = append(executed, args[len(args)-1])
+			return nil
+		},
+	}
+
+	q := queue.New()
+	q.Enqueue("fix the nil pointer")
+	q.Enqueue(" /SKIP ")
+	q.Enqueue("add a test for empty input")
+
+	var buf strings.Builder
+	runner := workflow.NewStepRunner(mockExec, io.Discard)
+	results := workflow.DrainQueue(context.Background(), &buf, runner, q)
+
+	require.Len(t, results, 3)
+	require.Len(t, executed, 1, "the directive and the prompts after it don't run")
+	assert.Contains(t, executed[0], "fix the nil pointer")
+	assert.True(t, results[1].SkipTask)
+	assert.True(t, results[2].Skipped)
+	assert.Contains(t, ui.StripColors(buf.String()), "Skipped queued prompt: add a test for empty input")
+	assert.Zero(t, q.Len())
+}
+
 func TestDrainQueue_EmptyQueue(t *testing.T) {
 	mockExec := &MockExecutor{
 		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
@@ -49,9 +81,9 @@ func TestDrainQueue_EmptyQueue(t *testing.T) {
 
 	q := queue.New()
 	runner := workflow.NewStepRunner(mockExec, io.Discard)
-	errors := workflow.DrainQueue(context.Background(), io.Discard, runner, q)
+	results := workflow.DrainQueue(context.Background(), io.Discard, runner, q)
 
-	assert.Nil(t, errors)
+	assert.Nil(t, results)
 }
 
 func TestDrainQueue_ContinuesOnError(t *testing.T) {
@@ -71,13 +103,16 @@ func TestDrainQueue_ContinuesOnError(t *testing.T) {
 	q.Enqueue("succeeding prompt")
 
 	runner := workflow.NewStepRunner(mockExec, io.Discard)
-	errs := workflow.DrainQueue(context.Background(), io.Discard, runner, q)
+	results := workflow.DrainQueue(context.Background(), io.Discard, runner, q)
 
 	// Both prompts should be attempted.
 	assert.Equal(t, 2, callCount)
-	// One error should be recorded.
-	assert.Len(t, errs, 1)
-	assert.Contains(t, errs[0].Error(), "first prompt failed")
+	// The failure should be recorded against the first prompt only.
+	require.Len(t, results, 2)
+	require.Error(t, results[0].Err)
+	assert.Contains(t, results[0].Err.Error(), "first prompt failed")
+	assert.Equal(t, "failing prompt", results[0].Prompt)
+	assert.NoError(t, results[1].Err)
 }
 
 func TestDrainQueue_UsesContextFlag(t *testing.T) {
@@ -161,13 +196,16 @@ func TestDrainQueue_StopsOnCancelledContext(t *testing.T) {
 
 	var buf strings.Builder
 	runner := workflow.NewStepRunner(mockExec, io.Discard)
-	errs := workflow.DrainQueue(ctx, &buf, runner, q)
+	results := workflow.DrainQueue(ctx, &buf, runner, q)
 
 	// No prompts should have been executed.
 	assert.Equal(t, 0, callCount)
-	// Should return context error.
-	assert.Len(t, errs, 1)
-	assert.ErrorIs(t, errs[0], context.Canceled)
+	// Every prompt should be marked skipped with the context error.
+	require.Len(t, results, 3)
+	for _, r := range results {
+		assert.True(t, r.Skipped)
+		assert.ErrorIs(t, r.Err, context.Canceled)
+	}
 	// Should log all skipped prompts.
 	output := buf.String()
 	assert.Contains(t, output, "Skipped queued prompt: first")
@@ -196,13 +234,18 @@ func TestDrainQueue_StopsMidDrainOnCancel(t *testing.T) {
 
 	var buf strings.Builder
 	runner := workflow.NewStepRunner(mockExec, io.Discard)
-	errs := workflow.DrainQueue(ctx, &buf, runner, q)
+	results := workflow.DrainQueue(ctx, &buf, runner, q)
 
 	// Only first prompt should have executed.
 	assert.Equal(t, 1, callCount)
-	// Should return context error.
-	assert.Len(t, errs, 1)
-	assert.ErrorIs(t, errs[0], context.Canceled)
+	// The first prompt succeeded; the rest were skipped with the context error.
+	require.Len(t, results, 3)
+	assert.False(t, results[0].Skipped)
+	assert.NoError(t, results[0].Err)
+	for _, r := range results[1:] {
+		assert.True(t, r.Skipped)
+		assert.ErrorIs(t, r.Err, context.Canceled)
+	}
 	// Should log remaining skipped prompts (not the first, which ran).
 	output := buf.String()
 	assert.NotContains(t, output, "Skipped queued prompt: first")
@@ -237,3 +280,132 @@ func TestDrainQueue_OutputsBoxedRunningHeader(t *testing.T) {
 	assert.Contains(t, stripped, "┌", "Should have box top border")
 	assert.Contains(t, stripped, "└", "Should have box bottom border")
 }
+
+func TestLogDrainSummary_PerPromptOutcome(t *testing.T) {
+	results := []workflow.DrainResult{
+		{Prompt: "fix the nil pointer", Duration: 2 * time.Second},
+		{Prompt: "add a test\nwith details", Err: fmt.Errorf("boom"), Duration: time.Second},
+		{Prompt: "skipped one", Err: context.Canceled, Skipped: true},
+		{Prompt: strings.Repeat("x", 80)},
+	}
+
+	var buf strings.Builder
+	workflow.LogDrainSummary(&buf, results)
+
+	stripped := ui.StripColors(buf.String())
+	assert.Contains(t, stripped, "✓ Queued: fix the nil pointer")
+	assert.Contains(t, stripped, "✗ Queued: add a test…")
+	assert.NotContains(t, stripped, "with details")
+	assert.NotContains(t, stripped, "skipped one")
+	assert.Contains(t, stripped, "Queued: "+strings.Repeat("x", 39)+"…")
+	assert.NotContains(t, stripped, strings.Repeat("x", 40))
+}
+
+func TestDirectivesSummary(t *testing.T) {
+	assert.Empty(t, workflow.DirectivesSummary(nil))
+	assert.Equal(t, "1 directive applied: add a test…", workflow.DirectivesSummary([]string{"add a test\nwith details"}))
+	assert.Equal(t, "2 directives applied: a; b", workflow.DirectivesSummary([]string{"a", "b"}))
+}
+
+// fakeClock advances its time only when After is called, so waits are instant
+// and their lengths are observable.
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestDrainQueue_SpacesPromptsByInterval(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var starts []time.Time
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			starts = append(starts, clock.Now())
+			// Simulate a prompt that takes part of the interval.
+			clock.now = clock.now.Add(300 * time.Millisecond)
+			return nil
+		},
+	}
+
+	q := queue.New()
+	q.Enqueue("first")
+	q.Enqueue("second")
+	q.Enqueue("third")
+
+	runner := workflow.NewStepRunner(mockExec, io.Discard)
+	results := workflow.DrainQueue(context.Background(), io.Discard, runner, q,
+		workflow.WithDrainInterval(time.Second), workflow.WithDrainClock(clock))
+
+	require.Len(t, results, 3)
+	require.Len(t, starts, 3)
+	for i := 1; i < len(starts); i++ {
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), time.Second, "prompt %d started too soon", i+1)
+	}
+	// Only the remainder of the interval is waited, not the full interval.
+	assert.Equal(t, []time.Duration{700 * time.Millisecond, 700 * time.Millisecond}, clock.waits)
+}
+
+func TestDrainQueue_ZeroIntervalDoesNotWait(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	mockExec := &MockExecutor{}
+
+	q := queue.New()
+	q.Enqueue("first")
+	q.Enqueue("second")
+
+	runner := workflow.NewStepRunner(mockExec, io.Discard)
+	results := workflow.DrainQueue(context.Background(), io.Discard, runner, q, workflow.WithDrainClock(clock))
+
+	assert.Len(t, results, 2)
+	assert.Empty(t, clock.waits)
+}
+
+// blockingClock never fires and cancels the drain while it is waiting.
+type blockingClock struct {
+	cancel context.CancelFunc
+}
+
+func (c *blockingClock) Now() time.Time { return time.Time{} }
+
+func (c *blockingClock) After(time.Duration) <-chan time.Time {
+	c.cancel()
+	return make(chan time.Time)
+}
+
+func TestDrainQueue_CancelDuringIntervalSkipsRemaining(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	callCount := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			callCount++
+			return nil
+		},
+	}
+
+	q := queue.New()
+	q.Enqueue("first")
+	q.Enqueue("second")
+
+	var buf strings.Builder
+	runner := workflow.NewStepRunner(mockExec, io.Discard)
+	results := workflow.DrainQueue(ctx, &buf, runner, q,
+		workflow.WithDrainInterval(time.Hour), workflow.WithDrainClock(&blockingClock{cancel: cancel}))
+
+	assert.Equal(t, 1, callCount)
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.True(t, results[1].Skipped)
+	assert.ErrorIs(t, results[1].Err, context.Canceled)
+	assert.Contains(t, buf.String(), "Skipped queued prompt: second")
+}
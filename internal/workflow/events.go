@@ -0,0 +1,101 @@
+package workflow
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/yarlson/snap/internal/state"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// Event types written to the event sink.
+const (
+	EventRunStarted        = "run_started"
+	EventTaskSelected      = "task_selected"
+	EventStepStarted       = "step_started"
+	EventStepCompleted     = "step_completed"
+	EventStepFailed        = "step_failed"
+	EventStepSkipped       = "step_skipped"
+	EventSnapshotSaved     = "snapshot_saved"
+	EventDirectivesDrained = "directives_drained"
+	EventIterationComplete = "iteration_complete"
+)
+
+// Event is one line of the event stream: a JSON object describing workflow
+// progress for tools that wrap snap.
+type Event struct {
+	Type       string             `json:"type"`
+	Time       time.Time          `json:"time"`
+	TaskID     string             `json:"task_id,omitempty"`
+	Step       int                `json:"step,omitempty"`
+	TotalSteps int                `json:"total_steps,omitempty"`
+	StepName   string             `json:"step_name,omitempty"`
+	DurationMS int64              `json:"duration_ms,omitempty"`        // step_completed, step_failed, iteration_complete
+	Error      string             `json:"error,omitempty"`              // step_failed
+	Failure    *state.Failure     `json:"failure,omitempty"`            // step_failed
+	Reason     string             `json:"reason,omitempty"`             // step_skipped
+	Summary    *ui.StartupSummary `json:"summary,omitempty"`            // run_started
+	Directives []DrainedDirective `json:"directives,omitempty"`         // directives_drained
+	Applied    []string           `json:"applied_directives,omitempty"` // iteration_complete
+}
+
+// DrainedDirective is the outcome of one queued directive in a
+// directives_drained event.
+type DrainedDirective struct {
+	Prompt     string `json:"prompt"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Skipped    bool   `json:"skipped,omitempty"`   // Never ran: the run was interrupted or the task skipped
+	SkipTask   bool   `json:"skip_task,omitempty"` // The /skip directive
+}
+
+// drainedDirectives converts DrainQueue results for a directives_drained
+// event.
+func drainedDirectives(results []DrainResult) []DrainedDirective {
+	directives := make([]DrainedDirective, len(results))
+	for i, r := range results {
+		directives[i] = DrainedDirective{
+			Prompt:     r.Prompt,
+			DurationMS: r.Duration.Milliseconds(),
+			Skipped:    r.Skipped,
+			SkipTask:   r.SkipTask,
+		}
+		if r.Err != nil {
+			directives[i].Error = r.Err.Error()
+		}
+	}
+	return directives
+}
+
+// eventSink writes events as newline-delimited JSON. A nil sink drops them.
+type eventSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WithEventSink writes newline-delimited JSON progress events to w, separate
+// from the human-readable output.
+func WithEventSink(w io.Writer) RunnerOption {
+	return func(r *Runner) {
+		r.events = &eventSink{w: w}
+	}
+}
+
+// emit stamps e with the current time and writes it. Write errors are
+// dropped: the event stream never stops a run.
+func (s *eventSink) emit(e Event) {
+	if s == nil {
+		return
+	}
+	e.Time = time.Now()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	//nolint:errcheck // Best-effort progress stream; a failed write never stops the run.
+	_, _ = s.w.Write(append(data, '\n'))
+}
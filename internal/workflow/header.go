@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// headerGate holds writes back until the iteration header has been rendered,
+// so output produced while the task description is still being generated
+// appears below the header rather than above it.
+type headerGate struct {
+	mu     sync.Mutex
+	w      io.Writer
+	title  string
+	opened bool
+	buf    bytes.Buffer
+}
+
+func newHeaderGate(w io.Writer, title string) *headerGate {
+	return &headerGate{w: w, title: title}
+}
+
+// Write buffers p until the gate is opened, then writes through.
+func (g *headerGate) Write(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.opened {
+		return g.buf.Write(p)
+	}
+	return g.w.Write(p)
+}
+
+// IsPaused reports whether writes are being held back, either until the gate
+// opens or by a paused underlying writer.
+func (g *headerGate) IsPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.opened {
+		return true
+	}
+	p, ok := g.w.(interface{ IsPaused() bool })
+	return ok && p.IsPaused()
+}
+
+// Open renders the header with the given description and flushes anything
+// buffered. Only the first call has an effect.
+func (g *headerGate) Open(description string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.opened {
+		return
+	}
+	g.opened = true
+	fmt.Fprint(g.w, ui.Header(g.title, description))
+	if g.buf.Len() > 0 {
+		//nolint:errcheck // Best-effort flush; buffer content is transient UI output.
+		_, _ = g.w.Write(g.buf.Bytes())
+		g.buf.Reset()
+	}
+}
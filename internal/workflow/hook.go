@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// ErrHookFailed is returned when the post-iteration hook fails while
+// Config.HookFatal is set.
+var ErrHookFailed = errors.New("post-iteration hook failed")
+
+// hookWaitDelay bounds how long a cancelled hook's children may keep its
+// output open before Wait gives up on them.
+const hookWaitDelay = 5 * time.Second
+
+// runPostIterationHook runs Config.PostIterationHook through sh after taskID
+// completes, with SNAP_TASK_ID and SNAP_TASKS_DIR set and its output written
+// to the runner output. A failure is a warning unless Config.HookFatal is
+// set; cancelling ctx kills the hook.
+func (r *Runner) runPostIterationHook(ctx context.Context, taskID string) error {
+	hook := r.config.PostIterationHook
+	if hook == "" {
+		return nil
+	}
+	fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Running post-iteration hook: %s", hook)))
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook)
+	cmd.Env = append(os.Environ(), "SNAP_TASK_ID="+taskID, "SNAP_TASKS_DIR="+r.config.TasksDir)
+	cmd.Stdout = r.output
+	cmd.Stderr = r.output
+	cmd.WaitDelay = hookWaitDelay
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if r.config.HookFatal {
+		fmt.Fprintln(r.output, ui.Error(fmt.Sprintf("Post-iteration hook failed: %v", err)))
+		return fmt.Errorf("%w for %s: %w", ErrHookFailed, taskID, err)
+	}
+	fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Post-iteration hook failed: %v; continuing", err)))
+	return nil
+}
@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// StepMetric is the timing of one executed step, as written to the metrics
+// file.
+type StepMetric struct {
+	TaskID     string `json:"task_id"`
+	StepName   string `json:"step_name"`
+	StepNumber int    `json:"step_number"`
+	DurationMS int64  `json:"duration_ms"`
+	Model      string `json:"model"`
+	Failed     bool   `json:"failed,omitempty"` // The provider call failed (each retry is its own entry)
+}
+
+// metricsFile collects step timings and appends them to a JSON array file.
+// A nil collector drops them.
+type metricsFile struct {
+	mu      sync.Mutex
+	path    string
+	pending []StepMetric
+}
+
+// WithMetricsFile records the duration of every executed step and, at the
+// end of each iteration, adds them to the JSON array in path, creating it if
+// needed. Entries already in the file are kept.
+func WithMetricsFile(path string) RunnerOption {
+	return func(r *Runner) {
+		r.metrics = &metricsFile{path: path}
+	}
+}
+
+// record queues m for the next flush.
+func (f *metricsFile) record(m StepMetric) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = append(f.pending, m)
+}
+
+// flush merges the queued entries into the file. Entries stay queued when
+// the write fails, so the next flush retries them.
+func (f *metricsFile) flush() error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.pending) == 0 {
+		return nil
+	}
+
+	var all []StepMetric
+	data, err := os.ReadFile(f.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("read metrics: %w", err)
+	case len(data) > 0:
+		if err := json.Unmarshal(data, &all); err != nil {
+			return fmt.Errorf("metrics file %s is not a JSON array of steps: %w", f.path, err)
+		}
+	}
+	all = append(all, f.pending...)
+
+	data, err = json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode metrics: %w", err)
+	}
+	if err := os.WriteFile(f.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write metrics: %w", err)
+	}
+	f.pending = nil
+	return nil
+}
+
+// stepMetric builds the metric for a step of the current task.
+func (r *Runner) stepMetric(t StepTiming) StepMetric {
+	return StepMetric{
+		TaskID:     r.metricsTask,
+		StepName:   t.Name,
+		StepNumber: t.Number,
+		DurationMS: t.Elapsed.Milliseconds(),
+		Model:      r.modelName(t.Model),
+		Failed:     t.Err != nil,
+	}
+}
+
+// flushMetrics writes the step timings recorded so far. A failed write is a
+// warning; the timings are kept for the next iteration's write.
+func (r *Runner) flushMetrics() {
+	if err := r.metrics.flush(); err != nil {
+		fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to save step metrics: %v", err)))
+	}
+}
@@ -0,0 +1,611 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/postrun"
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/state"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// affectsLine matches a task file's "affects:" line, optionally written as a
+// list item, e.g. "- affects: internal/auth, cmd/login.go".
+var affectsLine = regexp.MustCompile(`(?im)^\s*(?:[-*]\s*)?affects:\s*(.*)$`)
+
+// TaskAffects returns the paths a task file declares it changes in its
+// "affects:" line (comma-separated, repo-relative), cleaned. A task without
+// one returns nil.
+func TaskAffects(content string) []string {
+	m := affectsLine.FindStringSubmatch(content)
+	if m == nil {
+		return nil
+	}
+	var paths []string
+	for _, p := range strings.Split(m[1], ",") {
+		p = strings.Trim(strings.TrimSpace(p), "`")
+		if p == "" {
+			continue
+		}
+		paths = append(paths, path.Clean(strings.TrimPrefix(p, "./")))
+	}
+	return paths
+}
+
+// pathsOverlap reports whether a and b are the same path or one contains the
+// other. "." contains every path.
+func pathsOverlap(a, b string) bool {
+	if a == b || a == "." || b == "." {
+		return true
+	}
+	return strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// SelectParallelTasks returns up to n incomplete tasks, in order, that can
+// run at the same time: the next incomplete task, then the ones after it
+// whose affected paths overlap none already selected. The selection stops at
+// the first task that declares no paths or overlaps, so tasks never run ahead
+// of one they may depend on. affects returns a task's declared paths.
+func SelectParallelTasks(tasks []TaskInfo, completedIDs []string, n int, affects func(TaskInfo) []string) []TaskInfo {
+	completed := make(map[string]bool, len(completedIDs))
+	for _, id := range completedIDs {
+		completed[id] = true
+	}
+
+	var selected []TaskInfo
+	var claimed []string
+	for _, task := range tasks {
+		if len(selected) >= n {
+			break
+		}
+		if completed[task.ID] {
+			continue
+		}
+		paths := affects(task)
+		if len(selected) > 0 && (len(paths) == 0 || overlapsAny(paths, claimed)) {
+			break
+		}
+		selected = append(selected, task)
+		claimed = append(claimed, paths...)
+		if len(paths) == 0 {
+			break
+		}
+	}
+	return selected
+}
+
+// overlapsAny reports whether any of paths overlaps any of claimed.
+func overlapsAny(paths, claimed []string) bool {
+	for _, p := range paths {
+		for _, c := range claimed {
+			if pathsOverlap(p, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dirExecutor runs the provider in a fixed directory, so a task's steps work
+// in its worktree.
+type dirExecutor struct {
+	executor postrun.DirExecutor
+	dir      string
+}
+
+func (e dirExecutor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	return e.executor.RunInDir(ctx, e.dir, w, mt, args...)
+}
+
+// ModelName reports the wrapped executor's model names, if it has them.
+func (e dirExecutor) ModelName(mt model.Type) string {
+	if namer, ok := e.executor.(ModelNamer); ok {
+		return namer.ModelName(mt)
+	}
+	return ""
+}
+
+// prefixWriter writes whole lines to w, each prefixed with the task ID, so
+// the output of tasks running at the same time stays readable. Writers
+// sharing mu never interleave within a line.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return len(b), err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes a trailing partial line.
+func (p *prefixWriter) Flush() {
+	if len(p.buf) == 0 {
+		return
+	}
+	//nolint:errcheck // Best-effort: the last partial line of task output.
+	_ = p.writeLine(append(p.buf, '\n'))
+	p.buf = nil
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := fmt.Fprintf(p.w, "%s %s", p.prefix, line)
+	return err
+}
+
+// parallelTask is one task of a parallel batch and the worktree it runs in.
+type parallelTask struct {
+	task        TaskInfo
+	dir         string
+	branch      string
+	start       string       // Commit the branch started from
+	release     func() error // Removes the worktree
+	dropBranch  func() error // Deletes the branch unless keep is set
+	created     bool         // The worktree and branch exist
+	keep        bool         // The branch holds the task's unmerged commits
+	squashed    bool         // The branch's changes were squash-merged
+	step        int          // Step the task stopped at
+	description string       // Task description generated in the worktree
+	descHash    string
+	err         error
+}
+
+// sharedStepsStart returns the 1-indexed number of the first step of steps
+// that writes to files every task shares or commits: update-docs,
+// memory-update or commit. Parallel tasks stop before it in their worktrees
+// and run it and the steps after it in the working tree, one task at a time.
+// It returns 0 when there is no such step.
+func sharedStepsStart(steps []StepDef) int {
+	for i, s := range steps {
+		switch s.Prompt {
+		case PromptUpdateDocs, PromptMemoryUpdate, PromptCommit:
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// sequentialFallback turns parallel mode off for the rest of the run,
+// telling the user why.
+func (r *Runner) sequentialFallback(reason string) {
+	fmt.Fprint(r.output, ui.Interrupted("Running tasks one at a time: "+reason))
+	r.config.Parallel = 0
+}
+
+// parallelBatch returns the tasks to run in parallel next, starting with the
+// selected task, or nil when it should run on its own: parallel mode is off
+// or unsupported, the task has started, or no other task can run with it.
+// The batch never takes the run past Config.MaxIterations; iterations tasks
+// have completed so far.
+func (r *Runner) parallelBatch(ctx context.Context, workflowState *state.State, iterations int) ([]TaskInfo, error) {
+	if r.config.Parallel < 2 || r.config.TaskFilePath != "" || r.config.OnlyTask != "" {
+		return nil, nil
+	}
+	if workflowState.CurrentStep > 1 || workflowState.TaskStartCommit != "" {
+		return nil, nil
+	}
+	if r.worktree == nil {
+		r.sequentialFallback("no git work tree")
+		return nil, nil
+	}
+	if _, err := r.worktree.Head(ctx); err != nil {
+		r.sequentialFallback("no git work tree")
+		return nil, nil
+	}
+	if _, ok := r.executor.(postrun.DirExecutor); !ok {
+		r.sequentialFallback("provider cannot run in another directory")
+		return nil, nil
+	}
+	if r.config.PRPerTask {
+		r.sequentialFallback("PR per task checks out each task's branch")
+		return nil, nil
+	}
+	if sharedStepsStart(r.steps) == 1 {
+		r.sequentialFallback("the first step updates docs or memory, or commits")
+		return nil, nil
+	}
+
+	n := r.config.Parallel
+	if left := r.config.MaxIterations - iterations; r.config.MaxIterations > 0 && left < n {
+		n = left
+	}
+	tasks, err := r.discoverTasks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tasks: %w", err)
+	}
+	batch := SelectParallelTasks(tasks, workflowState.CompletedTaskIDs, n, func(t TaskInfo) []string {
+		content, err := os.ReadFile(r.activeTaskPath(t.Filename))
+		if err != nil {
+			return nil
+		}
+		return TaskAffects(string(content))
+	})
+	if len(batch) < 2 || batch[0].ID != workflowState.CurrentTaskID {
+		return nil, nil
+	}
+	if !r.treeClean(ctx) {
+		fmt.Fprint(r.output, ui.Interrupted("Working tree has uncommitted changes; running the next task on its own"))
+		return nil, nil
+	}
+	return batch, nil
+}
+
+// runParallel runs the selected task together with the tasks that can run
+// alongside it (Config.Parallel), each in its own git worktree on its own
+// branch with its own state. A task with a kept branch (State.KeptBranches)
+// continues on it. The worktrees stop before the shared steps
+// (sharedStepsStart) and commit what is left on their branch. In task
+// order, each branch is then squash-merged into the working tree, where the
+// shared steps run and complete the task. Without shared steps, the branches
+// are merged and the tasks recorded as completed. It returns the number of
+// tasks completed; 0 means the selected task should run on its own.
+//
+// A task that fails, or whose merge fails, keeps its branch and is left
+// incomplete, so the next run resumes it from the branch.
+func (r *Runner) runParallel(ctx context.Context, workflowState *state.State, iterations int) (int, error) {
+	batch, err := r.parallelBatch(ctx, workflowState, iterations)
+	if err != nil || batch == nil {
+		return 0, err
+	}
+
+	ids := make([]string, len(batch))
+	for i, t := range batch {
+		ids[i] = t.ID
+	}
+	fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Running %s in parallel", strings.Join(ids, ", "))))
+
+	batchDir, err := os.MkdirTemp("", "snap-parallel-")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create parallel worktrees: %w", err)
+	}
+	defer func() {
+		//nolint:errcheck // Best effort: the worktrees in it are removed by then.
+		_ = os.RemoveAll(batchDir)
+	}()
+
+	head, err := r.worktree.Head(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create parallel worktrees: %w", err)
+	}
+	stamp := time.Now().Unix()
+	runs := make([]*parallelTask, len(batch))
+	for i, task := range batch {
+		run := &parallelTask{
+			task:   task,
+			dir:    filepath.Join(batchDir, task.ID),
+			branch: fmt.Sprintf("snap/parallel/%s-%d", strings.TrimPrefix(taskBranch(task.ID), "snap/"), stamp),
+			start:  head,
+			step:   1,
+		}
+		kept, resumed := workflowState.KeptBranches[task.ID]
+		if resumed {
+			run.branch, run.step, run.start = kept.Branch, kept.Step, kept.StartCommit
+		}
+		// Registered before the worktree exists, so an interrupt at any
+		// point still removes it. The branch is registered first so it is
+		// deleted after its worktree is gone.
+		run.dropBranch = r.cleanups.Add("parallel branch "+run.branch, func(ctx context.Context) error {
+			if !run.created || run.keep {
+				return nil
+			}
+			return r.worktree.DeleteBranch(ctx, run.branch, run.squashed)
+		})
+		run.release = r.cleanups.Add("parallel worktree "+run.dir, func(ctx context.Context) error {
+			return r.worktree.DiscardWorktree(ctx, run.dir)
+		})
+		runs[i] = run
+		if err := r.worktree.AddWorktree(ctx, run.dir, run.branch, !resumed); err != nil {
+			r.releaseWorktrees(runs[:i+1])
+			return 0, fmt.Errorf("failed to create parallel worktree: %w", err)
+		}
+		run.created = true
+		if resumed {
+			// The branch is tracked by the run now; a failure records it again.
+			run.keep = true
+			delete(workflowState.KeptBranches, task.ID)
+			fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Resuming %s from branch %s at step %d", task.ID, run.branch, run.step)))
+		}
+	}
+
+	var outMu sync.Mutex
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out := &prefixWriter{mu: &outMu, w: r.output, prefix: "[" + run.task.ID + "]"}
+			run.err = r.runIsolatedTask(ctx, run, filepath.Join(batchDir, "state", run.task.ID), out)
+			out.Flush()
+		}()
+	}
+	wg.Wait()
+
+	// An interrupt keeps each task's work the way a failure does. The
+	// context is cancelled by then, so git runs without it.
+	if ctx.Err() != nil {
+		keepCtx := context.WithoutCancel(ctx)
+		for _, run := range runs {
+			r.keepTask(keepCtx, run)
+		}
+		r.recordKeptBranches(workflowState, runs)
+		if err := r.stateManager.Save(workflowState); err != nil {
+			fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Failed to save state: %v", err)))
+		}
+		return 0, ctx.Err()
+	}
+
+	shared := sharedStepsStart(r.steps)
+	completed := 0
+	var errs []error
+	for i, run := range runs {
+		if run.err == nil {
+			run.err = r.mergeParallelTask(ctx, run, shared)
+		}
+		if run.err != nil {
+			r.keepFailedTask(ctx, run)
+			errs = append(errs, fmt.Errorf("%s: %w", run.task.ID, run.err))
+			continue
+		}
+		run.keep = false
+		r.releaseWorktrees([]*parallelTask{run})
+		fmt.Fprint(r.output, ui.Info("Merged "+run.task.ID))
+
+		workflowState.StartTask(run.task.ID, run.task.Filename)
+		if shared == 0 {
+			if err := r.completeTask(workflowState, false); err != nil {
+				return completed, err
+			}
+			completed++
+			if err := r.runPostIterationHook(ctx, run.task.ID); err != nil {
+				return completed, err
+			}
+			continue
+		}
+
+		// The shared steps run on the merged changes as a resumed
+		// iteration, which completes the task. When they fail the task
+		// stays current at the failed step, and the tasks not merged yet
+		// keep their branches.
+		workflowState.CurrentStep = shared
+		workflowState.TaskStartCommit = run.start
+		workflowState.TaskDescription, workflowState.TaskDescriptionHash = run.description, run.descHash
+		if _, err := r.runIteration(ctx, workflowState); err != nil {
+			keepCtx := context.WithoutCancel(ctx)
+			for _, rest := range runs[i+1:] {
+				if rest.err != nil {
+					r.keepFailedTask(keepCtx, rest)
+					continue
+				}
+				r.keepTask(keepCtx, rest)
+			}
+			r.recordKeptBranches(workflowState, runs)
+			return completed, r.iterationFailed(ctx, workflowState, err)
+		}
+		completed++
+	}
+	r.pruneSnapshots(ctx)
+
+	if len(errs) > 0 {
+		// Nothing is current: the next run selects the first incomplete task.
+		workflowState.CurrentTaskID = ""
+		workflowState.CurrentTaskFile = ""
+		r.recordKeptBranches(workflowState, runs)
+		if err := r.stateManager.Save(workflowState); err != nil {
+			return completed, fmt.Errorf("failed to save state after parallel tasks: %w", err)
+		}
+		return completed, fmt.Errorf("parallel tasks failed: %w", errors.Join(errs...))
+	}
+	return completed, nil
+}
+
+// mergeParallelTask brings run's branch into the working tree: squashed and
+// left uncommitted for the shared steps when there are any (shared > 0),
+// merged otherwise. The squash records the commit it starts from, for the
+// task's diff base.
+func (r *Runner) mergeParallelTask(ctx context.Context, run *parallelTask, shared int) error {
+	if shared == 0 {
+		return r.worktree.Merge(ctx, run.branch, r.config.SignCommits)
+	}
+	head, err := r.worktree.Head(ctx)
+	if err != nil {
+		return err
+	}
+	if err := r.worktree.MergeSquash(ctx, run.branch); err != nil {
+		return err
+	}
+	run.start = head
+	run.squashed = true
+	return nil
+}
+
+// recordKeptBranches records the branches of runs that are kept in the
+// state, so their tasks resume from them.
+func (r *Runner) recordKeptBranches(workflowState *state.State, runs []*parallelTask) {
+	for _, run := range runs {
+		if run.keep {
+			workflowState.KeepBranch(run.task.ID, state.KeptBranch{Branch: run.branch, Step: run.step, StartCommit: run.start})
+		}
+	}
+}
+
+// resumeKeptBranch brings back the work of the current task, when it runs
+// on its own, from the branch a parallel run kept for it
+// (State.KeptBranches): the branch is squash-merged into the working tree,
+// left uncommitted, and deleted, and the task resumes at the step it
+// stopped at. When the merge fails the task starts over and the branch is
+// left for inspection.
+func (r *Runner) resumeKeptBranch(ctx context.Context, workflowState *state.State) error {
+	id := workflowState.CurrentTaskID
+	kept, ok := workflowState.KeptBranches[id]
+	if !ok || r.worktree == nil || workflowState.CurrentStep > 1 || workflowState.TaskStartCommit != "" {
+		return nil
+	}
+	delete(workflowState.KeptBranches, id)
+	head, err := r.worktree.Head(ctx)
+	if err == nil {
+		err = r.worktree.MergeSquash(ctx, kept.Branch)
+	}
+	if err != nil {
+		fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Could not resume %s from branch %s: %v; starting it over", id, kept.Branch, err)))
+	} else {
+		if err := r.worktree.DeleteBranch(ctx, kept.Branch, true); err != nil {
+			fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: %v", err)))
+		}
+		workflowState.CurrentStep = kept.Step
+		workflowState.TaskStartCommit = head
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Resuming %s from branch %s at step %d", id, kept.Branch, kept.Step)))
+	}
+	if err := r.stateManager.Save(workflowState); err != nil {
+		return fmt.Errorf("failed to save state after resuming %s: %w", id, err)
+	}
+	return nil
+}
+
+// runIsolatedTask runs run's task in its worktree from run.step up to the
+// shared steps, or to the end when there are none, with its state in
+// stateDir and its output written to out. What the steps leave uncommitted
+// is then committed to the task's branch. The run is headless: it never
+// prompts, and post-iteration work is left to runParallel.
+func (r *Runner) runIsolatedTask(ctx context.Context, run *parallelTask, stateDir string, out io.Writer) error {
+	cfg := r.config
+	cfg.Parallel = 0
+	cfg.IsTTY = false
+	cfg.ConfirmCommits = false
+	cfg.PauseBetweenTasks = false
+	cfg.ShowDiff = false
+	cfg.PostIterationHook = ""
+	cfg.SnapshotRetention = 0
+	cfg.TasksDir = worktreePath(run.dir, cfg.TasksDir)
+	cfg.PRDPath = worktreePath(run.dir, cfg.PRDPath)
+	shared := sharedStepsStart(r.steps)
+
+	opts := []RunnerOption{
+		WithRunnerOutput(out),
+		WithStateManager(state.NewManagerInDir(stateDir)),
+		WithWorkTree(snapshot.New(run.dir)),
+	}
+	if r.snapshotter != nil {
+		opts = append(opts, WithSnapshotter(snapshot.New(run.dir)))
+	}
+	child := NewRunner(dirExecutor{executor: r.executor.(postrun.DirExecutor), dir: run.dir}, cfg, opts...)
+	child.steps, child.skipped = r.steps, r.skipped
+	child.checks, child.checksSet = r.checks, true
+	child.events = r.events
+	child.metrics = r.metrics
+	child.cleanups = r.cleanups
+	child.stopAt = shared
+
+	taskState := state.NewState(cfg.TasksDir, cfg.PRDPath, len(r.steps))
+	taskState.SkippedSteps = r.skipped
+	taskState.StartTask(run.task.ID, run.task.Filename)
+	taskState.CurrentStep = run.step
+	taskState.TaskStartCommit = run.start
+	if err := child.stateManager.Save(taskState); err != nil {
+		return fmt.Errorf("failed to save task state: %w", err)
+	}
+	_, err := child.runIteration(ctx, taskState)
+	run.step = taskState.CurrentStep
+	run.description, run.descHash = taskState.TaskDescription, taskState.TaskDescriptionHash
+	if err != nil || shared == 0 {
+		return err
+	}
+	return r.commitWorktree(ctx, run)
+}
+
+// commitWorktree commits what run's task left uncommitted in its worktree
+// to its branch, so the branch holds all of the task's work.
+func (r *Runner) commitWorktree(ctx context.Context, run *parallelTask) error {
+	message := fmt.Sprintf("snap: %s, stopped before step %d", run.task.ID, run.step)
+	if _, err := snapshot.New(run.dir).CommitAll(ctx, message, r.config.SignCommits); err != nil {
+		return fmt.Errorf("commit %s's changes to %s: %w", run.task.ID, run.branch, err)
+	}
+	return nil
+}
+
+// worktreePath returns the path a task in worktree dir uses for p: p itself
+// when it is absolute or present in the worktree (relative paths resolve
+// against the worktree), otherwise p in the main working tree, e.g. for an
+// untracked tasks directory.
+func worktreePath(dir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	if _, err := os.Stat(filepath.Join(dir, p)); err == nil {
+		return p
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// keepFailedTask reports a failed task and keeps its work (keepTask).
+func (r *Runner) keepFailedTask(ctx context.Context, run *parallelTask) {
+	fmt.Fprintln(r.output, ui.Error(fmt.Sprintf("%s failed: %v", run.task.ID, run.err)))
+	r.keepTask(ctx, run)
+}
+
+// keepTask removes a task's worktree but keeps its branch when the branch
+// has commits the checked-out branch doesn't; a branch without any is
+// deleted. Uncommitted changes are saved as a snapshot first, so snap
+// restore can bring them back, and then committed to the branch, so the
+// task resumes from there.
+func (r *Runner) keepTask(ctx context.Context, run *parallelTask) {
+	label := snapshot.Label{TaskID: run.task.ID, Step: run.step, Total: len(r.steps), Name: StepName(r.steps, run.step)}
+	created, err := snapshot.New(run.dir).Capture(ctx, label.String())
+	switch {
+	case err != nil:
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  snapshot skipped: %v", err)))
+	case created:
+		fmt.Fprint(r.output, ui.Info("  uncommitted changes saved as a snapshot; see: snap snapshot list"))
+	}
+	if err := r.commitWorktree(ctx, run); err != nil {
+		fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: %v", err)))
+	}
+	// When the count fails the branch is kept: it may hold commits.
+	if ahead, err := r.worktree.CommitsAhead(ctx, run.branch); err != nil || ahead > 0 {
+		run.keep = true
+	}
+	r.releaseWorktrees([]*parallelTask{run})
+	if run.keep {
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  %s's commits are kept on branch %s", run.task.ID, run.branch)))
+	}
+}
+
+// releaseWorktrees removes the worktrees of runs and deletes their branches
+// unless they are kept, warning about any that can't be removed.
+func (r *Runner) releaseWorktrees(runs []*parallelTask) {
+	for _, run := range runs {
+		if err := run.release(); err != nil {
+			fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to remove %v", err)))
+		}
+		if err := run.dropBranch(); err != nil {
+			fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to remove %v", err)))
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package workflow_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/state"
+	"github.com/yarlson/snap/internal/ui"
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+func TestTaskAffects(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"none", "# Task 1\n\nDo things.", nil},
+		{"plain line", "# Task\naffects: internal/auth, cmd/login.go\n", []string{"internal/auth", "cmd/login.go"}},
+		{"list item with code spans", "- Affects: `./internal/auth/`, `docs`\n", []string{"internal/auth", "docs"}},
+		{"empty", "affects:\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, workflow.TaskAffects(tt.content))
+		})
+	}
+}
+
+func TestSelectParallelTasks(t *testing.T) {
+	tasks := []workflow.TaskInfo{{ID: "TASK1"}, {ID: "TASK2"}, {ID: "TASK3"}, {ID: "TASK4"}}
+	affects := func(m map[string][]string) func(workflow.TaskInfo) []string {
+		return func(t workflow.TaskInfo) []string { return m[t.ID] }
+	}
+	ids := func(tasks []workflow.TaskInfo) []string {
+		var out []string
+		for _, t := range tasks {
+			out = append(out, t.ID)
+		}
+		return out
+	}
+
+	t.Run("disjoint tasks up to n", func(t *testing.T) {
+		got := workflow.SelectParallelTasks(tasks, nil, 3, affects(map[string][]string{
+			"TASK1": {"a"}, "TASK2": {"b"}, "TASK3": {"c"}, "TASK4": {"d"},
+		}))
+		assert.Equal(t, []string{"TASK1", "TASK2", "TASK3"}, ids(got))
+	})
+
+	t.Run("skips completed tasks", func(t *testing.T) {
+		got := workflow.SelectParallelTasks(tasks, []string{"TASK1", "TASK3"}, 3, affects(map[string][]string{
+			"TASK2": {"b"}, "TASK4": {"d"},
+		}))
+		assert.Equal(t, []string{"TASK2", "TASK4"}, ids(got))
+	})
+
+	t.Run("stops at an overlapping path", func(t *testing.T) {
+		got := workflow.SelectParallelTasks(tasks, nil, 4, affects(map[string][]string{
+			"TASK1": {"internal/auth"}, "TASK2": {"cmd"}, "TASK3": {"internal/auth/token.go"}, "TASK4": {"docs"},
+		}))
+		assert.Equal(t, []string{"TASK1", "TASK2"}, ids(got))
+	})
+
+	t.Run("stops at a task without affects", func(t *testing.T) {
+		got := workflow.SelectParallelTasks(tasks, nil, 4, affects(map[string][]string{
+			"TASK1": {"a"}, "TASK3": {"c"},
+		}))
+		assert.Equal(t, []string{"TASK1"}, ids(got))
+
+		got = workflow.SelectParallelTasks(tasks, nil, 4, affects(map[string][]string{
+			"TASK2": {"b"},
+		}))
+		assert.Equal(t, []string{"TASK1"}, ids(got))
+	})
+
+	t.Run("dot overlaps everything", func(t *testing.T) {
+		got := workflow.SelectParallelTasks(tasks, nil, 4, affects(map[string][]string{
+			"TASK1": {"."}, "TASK2": {"b"},
+		}))
+		assert.Equal(t, []string{"TASK1"}, ids(got))
+	})
+}
+
+// dirMockExecutor runs each provider call through dirFunc with the directory
+// it was asked to run in ("" for Run).
+type dirMockExecutor struct {
+	mu      sync.Mutex
+	dirs    []string
+	dirFunc func(dir string, args ...string) error
+}
+
+func (m *dirMockExecutor) Run(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+	return m.RunInDir(context.Background(), "", nil, "", args...)
+}
+
+func (m *dirMockExecutor) RunInDir(_ context.Context, dir string, _ io.Writer, _ model.Type, args ...string) error {
+	m.mu.Lock()
+	m.dirs = append(m.dirs, dir)
+	m.mu.Unlock()
+	if m.dirFunc != nil {
+		return m.dirFunc(dir, args...)
+	}
+	return nil
+}
+
+func TestRunner_Parallel(t *testing.T) {
+	git := func(t *testing.T, dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+		return strings.TrimSpace(string(out))
+	}
+	setup := func(t *testing.T) string {
+		t.Helper()
+		tmpDir := initSnapshotRepo(t)
+		tasks := map[string]string{
+			"TASK1.md": "# Task 1\naffects: a\n",
+			"TASK2.md": "# Task 2\naffects: b\n",
+			"TASK3.md": "# Task 3\n",
+		}
+		for name, content := range tasks {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0o600))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "PRD.md"), []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte(".snap/\n"), 0o600))
+		git(t, tmpDir, "add", ".")
+		git(t, tmpDir, "commit", "-m", "tasks")
+		return tmpDir
+	}
+	commitAll := func(t *testing.T, dir, message string) error {
+		for _, args := range [][]string{{"add", "."}, {"commit", "-m", message}} {
+			cmd := exec.CommandContext(context.Background(), "git", args...)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Logf("git %v: %s", args, out)
+				return err
+			}
+		}
+		return nil
+	}
+	// Each task's first call in a worktree commits a file named after it;
+	// a call in the working tree commits whatever is there.
+	commitTask := func(t *testing.T, tmpDir string) func(dir string, args ...string) error {
+		return func(dir string, _ ...string) error {
+			if dir == "" {
+				if git(t, tmpDir, "status", "--porcelain") == "" {
+					return nil
+				}
+				return commitAll(t, tmpDir, "task commit")
+			}
+			task := filepath.Base(dir)
+			if _, err := os.Stat(filepath.Join(dir, task+".txt")); err == nil {
+				return nil
+			}
+			if err := os.WriteFile(filepath.Join(dir, task+".txt"), []byte(task), 0o600); err != nil {
+				return err
+			}
+			return commitAll(t, dir, task)
+		}
+	}
+	run := func(t *testing.T, ctx context.Context, tmpDir string, executor *dirMockExecutor) (*state.Manager, string, error) {
+		t.Helper()
+		stateManager := state.NewManagerWithDir(tmpDir)
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(executor, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  filepath.Join(tmpDir, "PRD.md"),
+			Parallel: 3,
+		},
+			workflow.WithStateManager(stateManager),
+			workflow.WithRunnerOutput(&buf),
+			workflow.WithWorkTree(snapshot.New(tmpDir)),
+		)
+		err := runner.Run(ctx)
+		return stateManager, ui.StripColors(buf.String()), err
+	}
+
+	t.Run("runs disjoint tasks in worktrees and merges them", func(t *testing.T) {
+		tmpDir := setup(t)
+		executor := &dirMockExecutor{}
+		executor.dirFunc = commitTask(t, tmpDir)
+
+		_, output, err := run(t, context.Background(), tmpDir, executor)
+		require.NoError(t, err, output)
+
+		assert.Contains(t, output, "Running TASK1, TASK2 in parallel")
+		assert.Contains(t, output, "[TASK1] ")
+		assert.Contains(t, output, "[TASK2] ")
+		assert.Contains(t, output, "Merged TASK1")
+		assert.Contains(t, output, "Merged TASK2")
+		assert.Contains(t, output, "Implementing TASK3")
+		assert.Contains(t, output, "All tasks implemented!")
+
+		assert.FileExists(t, filepath.Join(tmpDir, "TASK1.txt"))
+		assert.FileExists(t, filepath.Join(tmpDir, "TASK2.txt"))
+		assert.Empty(t, git(t, tmpDir, "branch", "--list", "snap/parallel/*"))
+		assert.NotContains(t, git(t, tmpDir, "worktree", "list"), "snap-parallel-")
+
+		// The worktrees ran the six steps before "Update docs"; the docs,
+		// memory and commit steps ran once per task in the working tree,
+		// where the docs step committed the squashed changes.
+		log := git(t, tmpDir, "log", "--format=%s")
+		assert.Equal(t, 2, strings.Count(log, "task commit"))
+		assert.NotContains(t, log, "stopped before step")
+
+		// TASK3 declares no paths, so it ran on its own in the working tree.
+		// Every task skips its two commit steps on a clean tree.
+		worktreeCalls := 0
+		for _, dir := range executor.dirs {
+			if dir != "" {
+				worktreeCalls++
+			}
+		}
+		assert.Equal(t, 12, worktreeCalls)
+		assert.Len(t, executor.dirs, 24)
+	})
+
+	t.Run("a failed task keeps its branch and stays incomplete", func(t *testing.T) {
+		tmpDir := setup(t)
+		executor := &dirMockExecutor{}
+		commit := commitTask(t, tmpDir)
+		executor.dirFunc = func(dir string, args ...string) error {
+			if filepath.Base(dir) == "TASK2" {
+				// The first call commits; the next one fails mid-step.
+				if _, err := os.Stat(filepath.Join(dir, "TASK2.txt")); err != nil {
+					return commit(dir, args...)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "wip.txt"), []byte("wip"), 0o600); err != nil {
+					return err
+				}
+				return assert.AnError
+			}
+			return commit(dir, args...)
+		}
+
+		stateManager, output, err := run(t, context.Background(), tmpDir, executor)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TASK2")
+		assert.Contains(t, output, "Merged TASK1")
+		assert.Contains(t, output, "uncommitted changes saved as a snapshot")
+
+		saved, err := stateManager.Load()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"TASK1"}, saved.CompletedTaskIDs)
+		assert.Empty(t, saved.CurrentTaskID)
+
+		kept := saved.KeptBranches["TASK2"]
+		assert.Contains(t, kept.Branch, "snap/parallel/task2-")
+		assert.Equal(t, 2, kept.Step)
+		assert.Equal(t, "wip", git(t, tmpDir, "show", kept.Branch+":wip.txt"), "uncommitted work is committed to the branch")
+		assert.NoFileExists(t, filepath.Join(tmpDir, "wip.txt"))
+		entries, err := snapshot.New(tmpDir).List(context.Background(), snapshot.Filter{TaskID: "TASK2"})
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		// TASK2 is next and runs on its own: it continues from its branch.
+		executor.dirFunc = commit
+		stateManager, output, err = run(t, context.Background(), tmpDir, executor)
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "Resuming TASK2 from branch "+kept.Branch+" at step 2")
+		assert.FileExists(t, filepath.Join(tmpDir, "wip.txt"))
+		assert.Empty(t, git(t, tmpDir, "branch", "--list", "snap/parallel/*"))
+	})
+
+	t.Run("an interrupt snapshots each task and keeps only branches with commits", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK3.md"), []byte("# Task 3\naffects: c\n"), 0o600))
+		git(t, tmpDir, "commit", "-am", "task 3 paths")
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		wipWritten := make(chan struct{})
+		executor := &dirMockExecutor{}
+		commit := commitTask(t, tmpDir)
+		executor.dirFunc = func(dir string, args ...string) error {
+			switch filepath.Base(dir) {
+			case "TASK1":
+				// Commits, then interrupts the run once TASK2 has uncommitted work.
+				if _, err := os.Stat(filepath.Join(dir, "TASK1.txt")); err != nil {
+					return commit(dir, args...)
+				}
+				<-wipWritten
+				cancel()
+				return ctx.Err()
+			case "TASK2":
+				if _, err := os.Stat(filepath.Join(dir, "wip.txt")); err != nil {
+					if err := os.WriteFile(filepath.Join(dir, "wip.txt"), []byte("wip"), 0o600); err != nil {
+						return err
+					}
+					close(wipWritten)
+					return nil
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		}
+
+		stateManager, output, err := run(t, ctx, tmpDir, executor)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Contains(t, output, "uncommitted changes saved as a snapshot")
+
+		// TASK3 did nothing, so its branch is gone.
+		branches := git(t, tmpDir, "branch", "--list", "snap/parallel/*")
+		assert.Contains(t, branches, "snap/parallel/task1-")
+		assert.Contains(t, branches, "snap/parallel/task2-")
+		assert.NotContains(t, branches, "snap/parallel/task3-")
+		assert.NotContains(t, git(t, tmpDir, "worktree", "list"), "snap-parallel-")
+		entries, err := snapshot.New(tmpDir).List(context.Background(), snapshot.Filter{TaskID: "TASK2"})
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		saved, err := stateManager.Load()
+		require.NoError(t, err)
+		assert.Len(t, saved.KeptBranches, 2)
+
+		// The next run continues both tasks on their branches.
+		executor.dirFunc = commitTask(t, tmpDir)
+		_, output, err = run(t, context.Background(), tmpDir, executor)
+		require.NoError(t, err, output)
+		assert.Contains(t, output, "Resuming TASK1 from branch")
+		assert.Contains(t, output, "Resuming TASK2 from branch")
+		assert.Contains(t, output, "All tasks implemented!")
+		assert.FileExists(t, filepath.Join(tmpDir, "TASK1.txt"))
+		assert.FileExists(t, filepath.Join(tmpDir, "wip.txt"))
+		assert.Empty(t, git(t, tmpDir, "branch", "--list", "snap/parallel/*"))
+	})
+
+	t.Run("without a work tree tasks run one at a time", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for _, name := range []string{"TASK1.md", "TASK2.md"} {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("affects: "+name), 0o600))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "PRD.md"), []byte("# PRD"), 0o600))
+
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(&dirMockExecutor{}, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  filepath.Join(tmpDir, "PRD.md"),
+			Parallel: 2,
+		}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(&buf))
+		require.NoError(t, runner.Run(context.Background()))
+
+		output := ui.StripColors(buf.String())
+		assert.Equal(t, 1, strings.Count(output, "Running tasks one at a time: no git work tree"))
+		assert.Contains(t, output, "All tasks implemented!")
+	})
+}
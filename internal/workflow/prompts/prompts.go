@@ -14,7 +14,7 @@ var implementTmpl string
 var ensureCompletenessTmpl string
 
 //go:embed lint_and_test.md
-var lintAndTest string
+var lintAndTestTmpl string
 
 //go:embed code_review.md
 var codeReview string
@@ -29,16 +29,19 @@ var updateDocsTmpl string
 var commit string
 
 //go:embed memory_update.md
-var memoryUpdate string
+var memoryUpdateTmpl string
 
 //go:embed task_summary.md
 var taskSummaryTmpl string
 
 // ImplementData holds template parameters for the implement prompt.
 type ImplementData struct {
-	PRDPath  string
-	TaskPath string // empty when auto-selecting
-	TaskID   string // empty when auto-selecting
+	PRDPath   string
+	TaskPath  string // empty when auto-selecting
+	TaskID    string // empty when auto-selecting
+	MemoryDir string // optional session memory vault read after docs/context/
+
+	Guardrails []string // project rules appended to the quality guardrails
 }
 
 // Implement renders the implementation prompt template with the given data.
@@ -56,8 +59,9 @@ func Implement(data ImplementData) (string, error) {
 
 // EnsureCompletenessData holds template parameters for the ensure-completeness prompt.
 type EnsureCompletenessData struct {
-	TaskPath string
-	TaskID   string
+	TaskPath  string
+	TaskID    string
+	TestsPath string // Acceptance test outline from snap plan --with-tests (empty = none)
 }
 
 // EnsureCompleteness renders the ensure-completeness prompt template with the given data.
@@ -73,17 +77,43 @@ func EnsureCompleteness(data EnsureCompletenessData) (string, error) {
 	return strings.TrimSpace(buf.String()), nil
 }
 
-// LintAndTest returns the lint-and-test prompt.
-func LintAndTest() string { return strings.TrimSpace(lintAndTest) }
+// LintAndTestData holds template parameters for the lint-and-test prompt.
+type LintAndTestData struct {
+	Scope       string // optional path prefix limiting linters and tests
+	LintCommand string // optional lint command to run; empty leaves discovery to the model
+	TestCommand string // optional test command to run; empty leaves discovery to the model
+
+	ReportResult bool // ask the model to end with a SNAP-CHECKS: PASS/FAIL line
+}
+
+// LintAndTest renders the lint-and-test prompt template with the given data.
+func LintAndTest(data LintAndTestData) (string, error) {
+	tmpl, err := template.New("lint_and_test").Parse(lintAndTestTmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
 
 // CodeReviewData holds template parameters for the code review prompt.
 type CodeReviewData struct {
 	TaskPath string
 	TaskID   string
+	Scope    string // optional path prefix; scopes the git diff commands
+	BaseRef  string // commit the git diff commands compare against (default: HEAD)
+
+	Guardrails []string // project rules the review checks the diff against
 }
 
 // CodeReview renders the code review prompt template with the given data.
 func CodeReview(data CodeReviewData) (string, error) {
+	if data.BaseRef == "" {
+		data.BaseRef = "HEAD"
+	}
 	tmpl, err := template.New("code_review").Parse(codeReview)
 	if err != nil {
 		return "", err
@@ -102,10 +132,19 @@ func ApplyFixes() string { return strings.TrimSpace(applyFixes) }
 type UpdateDocsData struct {
 	TaskPath string // empty when no specific task
 	TaskID   string // empty when no specific task
+	Scope    string // optional path prefix; scopes the git diff command
+	BaseRef  string // commit the git diff command compares against (default: HEAD)
+
+	// Changelog is the changelog file to add this task's entry to; empty
+	// leaves the changelog alone.
+	Changelog string
 }
 
 // UpdateDocs renders the update-docs prompt template with the given data.
 func UpdateDocs(data UpdateDocsData) (string, error) {
+	if data.BaseRef == "" {
+		data.BaseRef = "HEAD"
+	}
 	tmpl, err := template.New("update_docs").Parse(updateDocsTmpl)
 	if err != nil {
 		return "", err
@@ -120,8 +159,30 @@ func UpdateDocs(data UpdateDocsData) (string, error) {
 // Commit returns the commit prompt.
 func Commit() string { return strings.TrimSpace(commit) }
 
-// MemoryUpdate returns the full embedded project context update prompt.
-func MemoryUpdate() string { return strings.TrimSpace(memoryUpdate) }
+// DefaultMemoryDir is the memory vault the memory update step maintains
+// unless a session keeps its own.
+const DefaultMemoryDir = "docs/context"
+
+// MemoryUpdateData holds template parameters for the memory update prompt.
+type MemoryUpdateData struct {
+	Dir string // memory vault, relative to the project root; empty means DefaultMemoryDir
+}
+
+// MemoryUpdate renders the project context update prompt for the vault in data.
+func MemoryUpdate(data MemoryUpdateData) (string, error) {
+	if data.Dir == "" {
+		data.Dir = DefaultMemoryDir
+	}
+	tmpl, err := template.New("memory_update").Parse(memoryUpdateTmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
 
 // TaskSummaryData holds template parameters for the task-summary prompt.
 type TaskSummaryData struct {
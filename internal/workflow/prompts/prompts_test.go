@@ -133,6 +133,35 @@ func TestImplement_ContextLoading(t *testing.T) {
 	assert.Contains(t, result, "CLAUDE.md")
 	assert.Contains(t, result, "AGENTS.md")
 	assert.Contains(t, result, "existing source code")
+	assert.NotContains(t, result, "this session's memory")
+}
+
+func TestImplement_SessionMemory(t *testing.T) {
+	data := prompts.ImplementData{PRDPath: "PRD.md", MemoryDir: ".snap/sessions/auth/memory"}
+	result, err := prompts.Implement(data)
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "docs/context/context-map.md")
+	assert.Contains(t, result, "Then read .snap/sessions/auth/memory/context-map.md")
+}
+
+func TestPrompts_Guardrails(t *testing.T) {
+	rules := []string{"No new dependencies without an ADR", "All SQL goes through the query builder"}
+
+	implement, err := prompts.Implement(prompts.ImplementData{PRDPath: "PRD.md", Guardrails: rules})
+	require.NoError(t, err)
+	assert.Contains(t, implement, "**Project Rules:**\n\n- No new dependencies without an ADR\n- All SQL goes through the query builder")
+
+	review, err := prompts.CodeReview(prompts.CodeReviewData{TaskPath: "docs/tasks/TASK1.md", TaskID: "TASK1", Guardrails: rules})
+	require.NoError(t, err)
+	assert.Contains(t, review, "**Project Rules** (a violation is Severity: **HIGH**):\n\n- No new dependencies without an ADR\n- All SQL goes through the query builder")
+
+	without, err := prompts.Implement(prompts.ImplementData{PRDPath: "PRD.md"})
+	require.NoError(t, err)
+	assert.NotContains(t, without, "Project Rules")
+	withoutReview, err := prompts.CodeReview(prompts.CodeReviewData{TaskPath: "docs/tasks/TASK1.md", TaskID: "TASK1"})
+	require.NoError(t, err)
+	assert.NotContains(t, withoutReview, "Project Rules")
 }
 
 func TestImplement_NoTrailingWhitespace(t *testing.T) {
@@ -173,17 +202,107 @@ func TestEnsureCompleteness(t *testing.T) {
 	assert.Equal(t, strings.TrimSpace(result), result)
 }
 
+func TestEnsureCompleteness_TestsOutline(t *testing.T) {
+	result, err := prompts.EnsureCompleteness(prompts.EnsureCompletenessData{
+		TaskPath: "docs/tasks/TASK1.md",
+		TaskID:   "TASK1",
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, result, ".tests.md")
+	assert.Contains(t, result, "4. Read the source code and tests")
+
+	result, err = prompts.EnsureCompleteness(prompts.EnsureCompletenessData{
+		TaskPath:  "docs/tasks/TASK1.md",
+		TaskID:    "TASK1",
+		TestsPath: "docs/tasks/TASK1.tests.md",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result, "4. Read docs/tasks/TASK1.tests.md")
+	assert.Contains(t, result, "5. Read the source code and tests")
+}
+
 func TestLintAndTest(t *testing.T) {
-	result := prompts.LintAndTest()
+	result, err := prompts.LintAndTest(prompts.LintAndTestData{})
+	require.NoError(t, err)
 
 	assert.Contains(t, result, "AGENTS.md")
 	assert.Contains(t, result, "linters")
 	assert.Contains(t, result, "tests")
 	assert.Contains(t, result, "## Scope")
 	assert.Contains(t, result, "zero issues")
+	assert.NotContains(t, result, "Limit linters and tests")
+	assert.NotContains(t, result, "## Commands")
+	assert.Contains(t, result, "Report the exact lint and test commands you ran")
 	assert.Equal(t, strings.TrimSpace(result), result)
 }
 
+func TestLintAndTest_ReportResult(t *testing.T) {
+	plain, err := prompts.LintAndTest(prompts.LintAndTestData{})
+	require.NoError(t, err)
+	assert.NotContains(t, plain, "SNAP-CHECKS")
+
+	gated, err := prompts.LintAndTest(prompts.LintAndTestData{ReportResult: true})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(gated, "`SNAP-CHECKS: FAIL` if any failure remains."))
+}
+
+func TestLintAndTest_Commands(t *testing.T) {
+	result, err := prompts.LintAndTest(prompts.LintAndTestData{
+		LintCommand: "golangci-lint run",
+		TestCommand: "go test ./...",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result, "## Commands\n\n- Lint: `golangci-lint run`\n- Test: `go test ./...`\n\nUse these commands")
+
+	testOnly, err := prompts.LintAndTest(prompts.LintAndTestData{TestCommand: "npm test"})
+	require.NoError(t, err)
+	assert.Contains(t, testOnly, "## Commands\n\n- Test: `npm test`\n")
+	assert.NotContains(t, testOnly, "- Lint:")
+}
+
+func TestPrompts_Scope(t *testing.T) {
+	lint, err := prompts.LintAndTest(prompts.LintAndTestData{Scope: "services/billing"})
+	require.NoError(t, err)
+	assert.Contains(t, lint, "Limit linters and tests to `services/billing`")
+
+	review, err := prompts.CodeReview(prompts.CodeReviewData{Scope: "services/billing"})
+	require.NoError(t, err)
+	assert.Contains(t, review, "`git diff HEAD --stat -- services/billing`")
+	assert.Contains(t, review, "`git diff HEAD --name-only -- services/billing`")
+	assert.Contains(t, review, "Review only changes under `services/billing`")
+
+	docs, err := prompts.UpdateDocs(prompts.UpdateDocsData{Scope: "services/billing"})
+	require.NoError(t, err)
+	assert.Contains(t, docs, "`git diff HEAD -- services/billing`")
+	assert.Contains(t, docs, "Focus on changes under `services/billing`")
+}
+
+func TestPrompts_NoScopeLeavesDiffUnscoped(t *testing.T) {
+	review, err := prompts.CodeReview(prompts.CodeReviewData{})
+	require.NoError(t, err)
+	assert.Contains(t, review, "`git diff HEAD --stat`")
+	assert.NotContains(t, review, "Path scope")
+
+	docs, err := prompts.UpdateDocs(prompts.UpdateDocsData{})
+	require.NoError(t, err)
+	assert.Contains(t, docs, "`git diff HEAD`")
+	assert.NotContains(t, docs, "Focus on changes under")
+}
+
+func TestPrompts_BaseRef(t *testing.T) {
+	review, err := prompts.CodeReview(prompts.CodeReviewData{BaseRef: "abc1234", Scope: "api"})
+	require.NoError(t, err)
+	assert.Contains(t, review, "`git diff abc1234 --stat -- api`")
+	assert.Contains(t, review, "`git diff abc1234 --name-only -- api`")
+	assert.Contains(t, review, "every change since the task started")
+	assert.NotContains(t, review, "git diff HEAD")
+
+	docs, err := prompts.UpdateDocs(prompts.UpdateDocsData{TaskPath: "docs/tasks/TASK1.md", TaskID: "TASK1", BaseRef: "abc1234"})
+	require.NoError(t, err)
+	assert.Contains(t, docs, "`git diff abc1234` to see every change since the task started")
+	assert.NotContains(t, docs, "git diff HEAD")
+}
+
 func TestCodeReview(t *testing.T) {
 	data := prompts.CodeReviewData{
 		TaskPath: "docs/tasks/TASK1.md",
@@ -297,6 +416,24 @@ func TestUpdateDocs_WithoutTask(t *testing.T) {
 	assert.Equal(t, strings.TrimSpace(result), result)
 }
 
+func TestUpdateDocs_Changelog(t *testing.T) {
+	result, err := prompts.UpdateDocs(prompts.UpdateDocsData{
+		TaskPath:  "docs/tasks/TASK1.md",
+		TaskID:    "TASK1",
+		Changelog: "docs/CHANGES.md",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "## Changelog")
+	assert.Contains(t, result, "`docs/CHANGES.md`")
+	assert.Contains(t, result, "Keep a Changelog")
+	assert.Contains(t, result, "## [Unreleased]")
+
+	without, err := prompts.UpdateDocs(prompts.UpdateDocsData{TaskPath: "docs/tasks/TASK1.md", TaskID: "TASK1"})
+	require.NoError(t, err)
+	assert.NotContains(t, without, "Changelog")
+}
+
 func TestTaskSummary(t *testing.T) {
 	data := prompts.TaskSummaryData{
 		TaskContent: "# TASK1: Redesign Task Banner\n\nReplace the bordered header with a borderless banner.",
@@ -310,7 +447,8 @@ func TestTaskSummary(t *testing.T) {
 }
 
 func TestMemoryUpdate(t *testing.T) {
-	result := prompts.MemoryUpdate()
+	result, err := prompts.MemoryUpdate(prompts.MemoryUpdateData{})
+	require.NoError(t, err)
 
 	// Scope section
 	assert.Contains(t, result, "## Scope")
@@ -339,3 +477,13 @@ func TestMemoryUpdate(t *testing.T) {
 	assert.NotContains(t, result, "Update the project context.")
 	assert.Equal(t, strings.TrimSpace(result), result)
 }
+
+func TestMemoryUpdate_SessionVault(t *testing.T) {
+	result, err := prompts.MemoryUpdate(prompts.MemoryUpdateData{Dir: ".snap/sessions/auth/memory"})
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "Update `.snap/sessions/auth/memory/` in the **project root**")
+	assert.Contains(t, result, "All reads and writes target `.snap/sessions/auth/memory/`")
+	assert.Contains(t, result, "## Create `.snap/sessions/auth/memory/` If Missing")
+	assert.NotContains(t, result, "docs/context")
+}
@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/yarlson/snap/internal/state"
@@ -11,6 +12,8 @@ type startupAction int
 const (
 	actionResume startupAction = iota
 	actionSelect
+	actionMonitorCI
+	actionPublish
 )
 
 type startupTarget struct {
@@ -24,11 +27,28 @@ type startupTarget struct {
 // resolveStartup determines whether to resume an active task or select a new one.
 // When the state has an active task, it validates that the task file exists in the
 // tasks directory and that the step is within bounds. When idle, it returns a select
-// target without scanning the filesystem.
+// target without scanning the filesystem, a publish target when an earlier
+// run was interrupted before a completed task's branch was published, or a
+// CI monitoring target when an earlier run was interrupted while monitoring
+// CI and no task has been added since.
 // Returns an error with recovery guidance for inconsistent state.
 // The returned target includes scanned tasks when resuming, which can be reused to
 // avoid redundant directory scans by the caller.
-func resolveStartup(workflowState *state.State, tasksDir, taskFilePath string, totalSteps int) (*startupTarget, error) {
+// A non-zero step replaces the saved step of an active task and must be within
+// 1..totalSteps; it is how a resume picks a step when the saved one no longer fits.
+func resolveStartup(workflowState *state.State, tasksDir, taskFilePath, tasksGlob string, totalSteps, step int) (*startupTarget, error) {
+	if workflowState != nil && workflowState.CurrentTaskID == "" && workflowState.PublishBranch != "" {
+		return &startupTarget{action: actionPublish}, nil
+	}
+	if workflowState != nil && workflowState.CurrentTaskID == "" && workflowState.MonitoringCI {
+		// Tasks added since the run finished come first; post-run runs
+		// again once they are done.
+		tasks, err := discoverTasks(tasksDir, taskFilePath, tasksGlob)
+		if err != nil || SelectNextTask(tasks, workflowState.CompletedTaskIDs) == nil {
+			return &startupTarget{action: actionMonitorCI}, nil
+		}
+		return &startupTarget{action: actionSelect}, nil
+	}
 	if workflowState == nil || workflowState.CurrentTaskID == "" {
 		return &startupTarget{action: actionSelect}, nil
 	}
@@ -36,7 +56,7 @@ func resolveStartup(workflowState *state.State, tasksDir, taskFilePath string, t
 	// Active task exists — validate for resume.
 
 	// Scan tasks directory to verify active task file still exists.
-	tasks, err := discoverTasks(tasksDir, taskFilePath)
+	tasks, err := discoverTasks(tasksDir, taskFilePath, tasksGlob)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan tasks for resume validation: %w", err)
 	}
@@ -73,12 +93,31 @@ func resolveStartup(workflowState *state.State, tasksDir, taskFilePath string, t
 		}
 	}
 
-	// Validate step against the current workflow step count (not state's TotalSteps,
-	// which may be from an older version of the workflow).
-	if workflowState.CurrentStep < 1 || workflowState.CurrentStep > totalSteps+1 {
+	resumeAt := workflowState.CurrentStep
+	switch {
+	case step != 0:
+		if err := validateResumeStep(step, totalSteps); err != nil {
+			return nil, err
+		}
+		resumeAt = step
+	case workflowState.TotalSteps > 0 && workflowState.TotalSteps != totalSteps:
+		// The step list changed since the task started. A task whose steps
+		// all ran is still complete; otherwise the saved step must exist in
+		// the new list.
+		if workflowState.IsTaskComplete() {
+			resumeAt = totalSteps + 1
+		} else if resumeAt < 1 || resumeAt > totalSteps {
+			return nil, fmt.Errorf(
+				"step %d of %s is past the end of the workflow (%d steps; the task started with %d); pick a step with snap resume --step <n>, or use --fresh to reset",
+				resumeAt, workflowState.CurrentTaskID, totalSteps, workflowState.TotalSteps,
+			)
+		}
+	case resumeAt < 1 || resumeAt > totalSteps+1:
+		// Validate step against the current workflow step count (not state's
+		// TotalSteps, which may be from an older version of the workflow).
 		return nil, fmt.Errorf(
 			"invalid step %d for %s (expected 1-%d); use --fresh to reset or --show-state to inspect",
-			workflowState.CurrentStep, workflowState.CurrentTaskID, totalSteps,
+			resumeAt, workflowState.CurrentTaskID, totalSteps,
 		)
 	}
 
@@ -86,14 +125,62 @@ func resolveStartup(workflowState *state.State, tasksDir, taskFilePath string, t
 		action:   actionResume,
 		taskID:   workflowState.CurrentTaskID,
 		taskFile: workflowState.CurrentTaskFile,
-		step:     workflowState.CurrentStep,
+		step:     resumeAt,
 		tasks:    tasks,
 	}, nil
 }
 
-func discoverTasks(tasksDir, taskFilePath string) ([]TaskInfo, error) {
+// ErrNothingToResume is returned by ResolveResume when the state has no active task.
+var ErrNothingToResume = errors.New("nothing to resume")
+
+// errStepWhileMonitoringCI is returned when a resume step is requested after
+// all tasks are done and only CI monitoring remains.
+var errStepWhileMonitoringCI = errors.New("all tasks are complete and CI monitoring is in progress; resume without a step")
+
+// ResumeTarget is the task and step an interrupted run continues from.
+// MonitoringCI is set instead when the run was interrupted while monitoring
+// CI after its last task.
+type ResumeTarget struct {
+	TaskID       string
+	TaskFile     string
+	Step         int
+	MonitoringCI bool
+}
+
+// ResolveResume validates that workflowState has an active task that can be
+// resumed from tasksDir (or taskFilePath) and returns where it continues.
+// tasksGlob is the custom task filename pattern, empty for TASK<n>.md, and
+// steps the configured step list (nil for DefaultSteps()).
+// A non-zero step overrides the saved step and must be within 1..StepCount(steps).
+// Returns ErrNothingToResume when there is no state or no active task.
+func ResolveResume(workflowState *state.State, tasksDir, taskFilePath, tasksGlob string, steps []StepDef, step int) (*ResumeTarget, error) {
+	target, err := resolveStartup(workflowState, tasksDir, taskFilePath, tasksGlob, StepCount(steps), step)
+	if err != nil {
+		return nil, err
+	}
+	if target.action == actionMonitorCI {
+		if step != 0 {
+			return nil, errStepWhileMonitoringCI
+		}
+		return &ResumeTarget{MonitoringCI: true}, nil
+	}
+	if target.action != actionResume {
+		return nil, ErrNothingToResume
+	}
+	return &ResumeTarget{TaskID: target.taskID, TaskFile: target.taskFile, Step: target.step}, nil
+}
+
+// validateResumeStep checks a requested resume step against the workflow.
+func validateResumeStep(step, totalSteps int) error {
+	if step < 1 || step > totalSteps {
+		return fmt.Errorf("invalid step %d (expected 1-%d)", step, totalSteps)
+	}
+	return nil
+}
+
+func discoverTasks(tasksDir, taskFilePath, tasksGlob string) ([]TaskInfo, error) {
 	if taskFilePath != "" {
 		return ScanSingleTask(taskFilePath)
 	}
-	return ScanTasks(tasksDir)
+	return ScanTasksGlob(tasksDir, tasksGlob)
 }
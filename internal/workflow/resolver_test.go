@@ -12,18 +12,65 @@ import (
 
 func TestResolveStartup(t *testing.T) {
 	t.Run("returns select action for nil state", func(t *testing.T) {
-		target, err := resolveStartup(nil, t.TempDir(), "", 9)
+		target, err := resolveStartup(nil, t.TempDir(), "", "", 9, 0)
 		require.NoError(t, err)
 		assert.Equal(t, actionSelect, target.action)
 	})
 
 	t.Run("returns select action for idle state", func(t *testing.T) {
 		s := state.NewState("docs/tasks", "PRD.md", 9)
-		target, err := resolveStartup(s, t.TempDir(), "", 9)
+		target, err := resolveStartup(s, t.TempDir(), "", "", 9, 0)
 		require.NoError(t, err)
 		assert.Equal(t, actionSelect, target.action)
 	})
 
+	t.Run("returns CI monitoring action when monitoring was interrupted", func(t *testing.T) {
+		s := state.NewState("docs/tasks", "PRD.md", 9)
+		s.MonitoringCI = true
+		target, err := resolveStartup(s, t.TempDir(), "", "", 9, 0)
+		require.NoError(t, err)
+		assert.Equal(t, actionMonitorCI, target.action)
+	})
+
+	t.Run("selects a task added after CI monitoring was interrupted", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "TASK1.md", "# Task 1")
+		createFile(t, dir, "TASK2.md", "# Task 2")
+
+		s := state.NewState(dir, "PRD.md", 9)
+		s.CompletedTaskIDs = []string{"TASK1"}
+		s.MonitoringCI = true
+
+		target, err := resolveStartup(s, dir, "", "", 9, 0)
+		require.NoError(t, err)
+		assert.Equal(t, actionSelect, target.action)
+
+		_, err = ResolveResume(s, dir, "", "", nil, 0)
+		assert.ErrorIs(t, err, ErrNothingToResume)
+	})
+
+	t.Run("keeps monitoring CI when every task is done", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "TASK1.md", "# Task 1")
+
+		s := state.NewState(dir, "PRD.md", 9)
+		s.CompletedTaskIDs = []string{"TASK1"}
+		s.MonitoringCI = true
+
+		target, err := resolveStartup(s, dir, "", "", 9, 0)
+		require.NoError(t, err)
+		assert.Equal(t, actionMonitorCI, target.action)
+	})
+
+	t.Run("publishes a completed task's branch left unpublished", func(t *testing.T) {
+		s := state.NewState("docs/tasks", "PRD.md", 9)
+		s.CompletedTaskIDs = []string{"TASK1"}
+		s.PublishBranch = "snap/task1"
+		target, err := resolveStartup(s, t.TempDir(), "", "", 9, 0)
+		require.NoError(t, err)
+		assert.Equal(t, actionPublish, target.action)
+	})
+
 	t.Run("returns resume target for valid active state", func(t *testing.T) {
 		dir := t.TempDir()
 		createFile(t, dir, "TASK1.md", "# Task 1")
@@ -34,7 +81,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "TASK1.md"
 		s.CurrentStep = 3
 
-		target, err := resolveStartup(s, dir, "", 9)
+		target, err := resolveStartup(s, dir, "", "", 9, 0)
 		require.NoError(t, err)
 		assert.Equal(t, actionResume, target.action)
 		assert.Equal(t, "TASK1", target.taskID)
@@ -51,7 +98,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "TASK1.md"
 		s.CurrentStep = 1
 
-		_, err := resolveStartup(s, dir, "", 9)
+		_, err := resolveStartup(s, dir, "", "", 9, 0)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "TASK1")
 		assert.Contains(t, err.Error(), "not found")
@@ -73,7 +120,7 @@ func TestResolveStartup(t *testing.T) {
 			PRDPath:          "PRD.md",
 		}
 
-		_, err := resolveStartup(s, dir, "", 9)
+		_, err := resolveStartup(s, dir, "", "", 9, 0)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "already")
 		assert.Contains(t, err.Error(), "--fresh")
@@ -88,7 +135,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "TASK1.md"
 		s.CurrentStep = 0
 
-		_, err := resolveStartup(s, dir, "", 9)
+		_, err := resolveStartup(s, dir, "", "", 9, 0)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid step")
 		assert.Contains(t, err.Error(), "--fresh")
@@ -103,7 +150,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "TASK1.md"
 		s.CurrentStep = 7 // > totalSteps(5) + 1
 
-		_, err := resolveStartup(s, dir, "", 5)
+		_, err := resolveStartup(s, dir, "", "", 5, 0)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid step")
 	})
@@ -117,7 +164,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "TASK1.md"
 		s.CurrentStep = 9
 
-		target, err := resolveStartup(s, dir, "", 9)
+		target, err := resolveStartup(s, dir, "", "", 9, 0)
 		require.NoError(t, err)
 		assert.Equal(t, actionResume, target.action)
 		assert.Equal(t, 9, target.step)
@@ -132,7 +179,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "TASK1.md"
 		s.CurrentStep = 10 // totalSteps + 1: all steps done, cleanup pending
 
-		target, err := resolveStartup(s, dir, "", 9)
+		target, err := resolveStartup(s, dir, "", "", 9, 0)
 		require.NoError(t, err)
 		assert.Equal(t, actionResume, target.action)
 		assert.Equal(t, 10, target.step)
@@ -144,7 +191,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "TASK1.md"
 		s.CurrentStep = 1
 
-		_, err := resolveStartup(s, "/nonexistent", "", 9)
+		_, err := resolveStartup(s, "/nonexistent", "", "", 9, 0)
 		assert.Error(t, err)
 	})
 
@@ -158,7 +205,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "" // empty, as after v1 migration
 		s.CurrentStep = 3
 
-		target, err := resolveStartup(s, dir, "", 9)
+		target, err := resolveStartup(s, dir, "", "", 9, 0)
 		require.NoError(t, err)
 		assert.Equal(t, actionResume, target.action)
 		assert.Equal(t, "TASK2", target.taskID)
@@ -171,7 +218,7 @@ func TestResolveStartup(t *testing.T) {
 		// Pass a nonexistent directory. If the scanner were called,
 		// it would fail. Idle state should not trigger scanning.
 		s := state.NewState("/nonexistent", "PRD.md", 9)
-		target, err := resolveStartup(s, "/nonexistent", "", 9)
+		target, err := resolveStartup(s, "/nonexistent", "", "", 9, 0)
 		require.NoError(t, err)
 		assert.Equal(t, actionSelect, target.action)
 	})
@@ -186,7 +233,7 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "ad-hoc-task.md"
 		s.CurrentStep = 3
 
-		target, err := resolveStartup(s, dir, taskPath, 9)
+		target, err := resolveStartup(s, dir, taskPath, "", 9, 0)
 		require.NoError(t, err)
 		assert.Equal(t, actionResume, target.action)
 		assert.Equal(t, "ad-hoc-task", target.taskID)
@@ -203,8 +250,102 @@ func TestResolveStartup(t *testing.T) {
 		s.CurrentTaskFile = "ad-hoc-task.md"
 		s.CurrentStep = 1
 
-		_, err := resolveStartup(s, dir, taskPath, 9)
+		_, err := resolveStartup(s, dir, taskPath, "", 9, 0)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found")
 	})
 }
+
+func TestResolveResume(t *testing.T) {
+	t.Run("returns ErrNothingToResume for nil state", func(t *testing.T) {
+		_, err := ResolveResume(nil, t.TempDir(), "", "", nil, 0)
+		assert.ErrorIs(t, err, ErrNothingToResume)
+	})
+
+	t.Run("returns ErrNothingToResume for idle state", func(t *testing.T) {
+		s := state.NewState("docs/tasks", "PRD.md", StepCount(nil))
+		_, err := ResolveResume(s, t.TempDir(), "", "", nil, 0)
+		assert.ErrorIs(t, err, ErrNothingToResume)
+	})
+
+	t.Run("returns CI monitoring target", func(t *testing.T) {
+		s := state.NewState("docs/tasks", "PRD.md", StepCount(nil))
+		s.MonitoringCI = true
+
+		target, err := ResolveResume(s, t.TempDir(), "", "", nil, 0)
+		require.NoError(t, err)
+		assert.Equal(t, &ResumeTarget{MonitoringCI: true}, target)
+
+		_, err = ResolveResume(s, t.TempDir(), "", "", nil, 3)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "CI monitoring is in progress")
+	})
+
+	t.Run("returns saved step for active task", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "TASK1.md", "# Task 1")
+
+		s := state.NewState(dir, "PRD.md", StepCount(nil))
+		s.CurrentTaskID = "TASK1"
+		s.CurrentTaskFile = "TASK1.md"
+		s.CurrentStep = 4
+
+		target, err := ResolveResume(s, dir, "", "", nil, 0)
+		require.NoError(t, err)
+		assert.Equal(t, &ResumeTarget{TaskID: "TASK1", TaskFile: "TASK1.md", Step: 4}, target)
+	})
+
+	t.Run("overrides step when requested", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "TASK1.md", "# Task 1")
+
+		s := state.NewState(dir, "PRD.md", StepCount(nil))
+		s.CurrentTaskID = "TASK1"
+		s.CurrentStep = 4
+
+		target, err := ResolveResume(s, dir, "", "", nil, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 2, target.Step)
+	})
+
+	t.Run("rejects out-of-range step", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "TASK1.md", "# Task 1")
+
+		s := state.NewState(dir, "PRD.md", StepCount(nil))
+		s.CurrentTaskID = "TASK1"
+		s.CurrentStep = 4
+
+		_, err := ResolveResume(s, dir, "", "", nil, StepCount(nil)+1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid step")
+	})
+
+	t.Run("handles a changed step list", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "TASK1.md", "# Task 1")
+		steps := DefaultSteps()[:4]
+
+		s := state.NewState(dir, "PRD.md", StepCount(nil))
+		s.CurrentTaskID = "TASK1"
+		s.CurrentStep = 3
+
+		target, err := ResolveResume(s, dir, "", "", steps, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, target.Step, "saved step still exists")
+
+		s.CurrentStep = 8
+		_, err = ResolveResume(s, dir, "", "", steps, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "step 8 of TASK1 is past the end of the workflow (4 steps; the task started with 10)")
+
+		target, err = ResolveResume(s, dir, "", "", steps, 4)
+		require.NoError(t, err)
+		assert.Equal(t, 4, target.Step, "--step picks a step in the new list")
+
+		s.CurrentStep = StepCount(nil) + 1
+		target, err = ResolveResume(s, dir, "", "", steps, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 5, target.Step, "a task whose steps all ran stays complete")
+	})
+}
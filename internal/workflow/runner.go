@@ -1,16 +1,23 @@
 package workflow
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/yarlson/snap/internal/cleanup"
 	"github.com/yarlson/snap/internal/model"
 	"github.com/yarlson/snap/internal/postrun"
 	"github.com/yarlson/snap/internal/queue"
@@ -20,13 +27,25 @@ import (
 	"github.com/yarlson/snap/internal/workflow/prompts"
 )
 
-// workflowStepCount is the number of steps in the iteration workflow.
-// Keep in sync with the steps slice in runIteration.
-const workflowStepCount = 10
+// SnapshotMode controls when the runner captures step snapshots.
+type SnapshotMode string
 
-// StepCount returns the number of steps in the iteration workflow.
-func StepCount() int {
-	return workflowStepCount
+const (
+	SnapshotEveryStep SnapshotMode = "every-step" // After every step except commits (default)
+	SnapshotOnFailure SnapshotMode = "on-failure" // Only when a step fails, before the error is returned
+	SnapshotOff       SnapshotMode = "off"        // Never, even with a snapshotter configured
+)
+
+// ParseSnapshotMode parses a --snapshots value. Empty means SnapshotEveryStep.
+func ParseSnapshotMode(s string) (SnapshotMode, error) {
+	switch m := SnapshotMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return SnapshotEveryStep, nil
+	case SnapshotEveryStep, SnapshotOnFailure, SnapshotOff:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%q is not a snapshot mode (supported: every-step, on-failure, off)", s)
+	}
 }
 
 // Config holds workflow configuration.
@@ -34,14 +53,160 @@ type Config struct {
 	TasksDir     string
 	PRDPath      string
 	TaskFilePath string // Optional path to a single ad hoc task file
+	TasksGlob    string // Custom task filename pattern (e.g. "story-*.md"); empty means TASK<n>.md
 	FreshStart   bool   // Force fresh start, ignore existing state
+	ResumeStep   int    // Resume the active task at this step instead of the saved one (0 = saved step); requires an active task
+
+	// Steps is the iteration workflow, e.g. from .snap/workflow.yaml; nil
+	// runs DefaultSteps(). StepCount(Steps) is the step count saved in state.
+	Steps []StepDef
+
+	// SkipSteps names steps of Steps left out of every iteration (matched
+	// case-insensitively). The saved step count excludes them.
+	SkipSteps []string
+
+	// StepModelOverrides replaces the model of the named steps of Steps
+	// (matched case-insensitively), e.g. {"Code review": model.Fast}. Names
+	// that match no step are reported as a warning at startup.
+	StepModelOverrides map[string]model.Type
+
+	// GlobalPreamble is prepended to every step prompt, e.g. house rules
+	// from .snap/preamble.md. Empty adds nothing.
+	GlobalPreamble string
+
 	ProviderName string // Provider display name (e.g. "claude", "codex")
+	PinnedModels bool   // The user pinned model names; the startup summary shows them
 	IsTTY        bool   // Whether stdout is a terminal
+	Headless     bool   // Force headless on a TTY, e.g. when stdin is reserved for commit prompts; see IsHeadless
 	DisplayName  string // For startup summary (session name or tasks dir path); falls back to TasksDir if empty
-	RemoteURL    string // Pre-detected git remote URL (empty = no remote)
+	RemoteURL    string // Pre-detected URL of the push remote (empty = no remote)
 	IsGitHub     bool   // Whether the remote is a GitHub remote
+
+	// Fork workflow. PushRemote is the remote post-run pushes to (default:
+	// origin); PRRepo, when set, opens the PR in the upstream repository
+	// with the pushed branch as head.
+	PushRemote string
+	PRRepo     postrun.Repo
+
+	// Task-description pre-step. It costs one provider call per iteration,
+	// so it only runs when Describe is set.
+	Describe            bool       // Generate a one-line task description shown under the header
+	DescriptionModel    model.Type // Model used for the description (default: model.Fast)
+	DescriptionMaxBytes int        // Max task file bytes sent for the description (default: 2000)
+
+	QueueDrainInterval time.Duration // Minimum spacing between queued prompts drained between steps (0 = back-to-back)
+	CIPollInterval     time.Duration // CI status poll interval after push (0 = postrun default)
+	IsolateCIFix       bool          // Apply CI fixes in a temporary worktree instead of the working tree
+
+	// CIFixFallbacks are the executors CI fixes switch to, in order, when
+	// the provider call keeps failing.
+	CIFixFallbacks []postrun.Fallback
+
+	ConfirmCommits bool // Ask before the commit steps (TTY only); declining skips both commits
+	SignCommits    bool // Commit steps must create signed commits; an unsigned commit fails the step
+	StrictCommits  bool // A commit step that leaves uncommitted changes fails instead of only listing them
+	ShowDiff       bool // Print a colorized `git diff --stat` after the implement step (TTY only)
+	Explain        bool // Print each step's purpose before it runs
+
+	// PRPerTask implements each task on its own branch from the base branch
+	// and runs post-run (push, PR, CI) after every task instead of once at
+	// the end, for one reviewable PR per task.
+	PRPerTask bool
+
+	// Parallel runs up to this many tasks at a time, each in its own git
+	// worktree and branch with its own state, merging completed tasks back
+	// in order (0 or 1 = one at a time). Tasks run together only when their
+	// "affects:" lines declare disjoint paths. Needs a provider that can run
+	// in another directory; not combined with PRPerTask.
+	Parallel int
+
+	// PauseBetweenTasks asks before starting the next task (TTY only), so
+	// each task's commits can be reviewed first. Declining stops the run
+	// with its state saved; the next run starts the next task.
+	PauseBetweenTasks bool
+
+	// OnlyTask runs just this task (e.g. "TASK3") and stops once it
+	// completes, without selecting another task or running post-run. A
+	// completed task runs again. Empty means every task, in order.
+	OnlyTask string
+
+	// MaxIterations stops the run after this many completed tasks when
+	// another task remains (0 = unlimited). The state is kept, so the next
+	// run continues with the next task.
+	MaxIterations int
+
+	Scope string // Optional repo-relative path prefix; lint/test, review and docs steps focus on it
+
+	// BaseSHA is the commit the code-review and update-docs steps diff
+	// against. Empty means the task's start commit, so a resumed task is
+	// reviewed as a whole; without one (outside git) they diff against HEAD.
+	BaseSHA string
+
+	// MemoryDir is the memory vault the update-memory step maintains,
+	// relative to the project root. Empty means docs/context/; a session
+	// vault is also read by the implement step.
+	MemoryDir string
+
+	// UpdateChangelog has the update-docs step also add a Keep a Changelog
+	// entry for the task to ChangelogPath (default: CHANGELOG.md), which the
+	// commit step then picks up.
+	UpdateChangelog bool
+	ChangelogPath   string
+
+	// ExtraGuardrails are project rules (e.g. "no new dependencies without
+	// an ADR") added to the implement step's quality guardrails and checked
+	// by the code-review step.
+	ExtraGuardrails []string
+
+	ProviderStderr StderrMode   // How provider stderr is shown during steps (default: StderrHide)
+	SnapshotMode   SnapshotMode // When steps are snapshotted, given a snapshotter (default: SnapshotEveryStep)
+
+	// PostIterationHook is a shell command run after each completed task,
+	// with SNAP_TASK_ID and SNAP_TASKS_DIR set (empty = none). A failing
+	// hook is a warning unless HookFatal is set, which stops the run.
+	PostIterationHook string
+	HookFatal         bool
+
+	// Step limits and snapshot pruning. SnapshotRetention needs a
+	// snapshotter; other stash entries are never touched.
+	FailFastOnLint    bool          // Stop the iteration when a lint/test step reports SNAP-CHECKS: FAIL
+	IdleTimeout       time.Duration // Cancel a step when the provider writes nothing for this long (0 = off)
+	StepTimeout       time.Duration // Cancel a step that runs longer than this (0 = no limit)
+	SnapshotRetention int           // Keep this many of the newest snap snapshots, pruning the rest after each completed iteration (0 = all)
+
+	// Step retries. A step whose provider call fails is retried up to
+	// MaxStepRetries times, waiting RetryBackoff before the first retry and
+	// twice as long before each one after it.
+	MaxStepRetries int
+	RetryBackoff   time.Duration
+
+	// StepErrorTailLines is how many of the last provider output lines are
+	// repeated under a failed step (0 = default 10, negative = none).
+	StepErrorTailLines int
+
+	// Lint-and-test commands. Empty values are detected from the project
+	// (Makefile targets, go.mod, golangci-lint config, package.json scripts).
+	LintCommand string
+	TestCommand string
+}
+
+// DefaultChangelogPath is the changelog the update-docs step maintains when
+// Config.UpdateChangelog is set without a path.
+const DefaultChangelogPath = "CHANGELOG.md"
+
+// IsHeadless reports whether the run has no interactive directive queue: no
+// stdin reader and no prompt hint. A run without a terminal is always
+// headless.
+func (c Config) IsHeadless() bool {
+	return c.Headless || !c.IsTTY
 }
 
+// defaultDescriptionMaxBytes caps the task content sent to the description pre-step.
+const defaultDescriptionMaxBytes = 2000
+
+// defaultStepErrorTailLines is how many output lines a failed step repeats.
+const defaultStepErrorTailLines = 10
+
 // StateManager defines the interface for state management, used in tests for dependency injection.
 type StateManager interface {
 	Load() (*state.State, error)
@@ -50,6 +215,13 @@ type StateManager interface {
 	Exists() bool
 }
 
+// HistoryRecorder is implemented by state managers that keep completion
+// records outside the state file, so they survive Reset (state.Manager
+// writes history.jsonl).
+type HistoryRecorder interface {
+	AppendHistory(taskID string, rec state.TaskRecord) error
+}
+
 // Runner orchestrates the task implementation workflow.
 type Runner struct {
 	executor     Executor
@@ -57,9 +229,24 @@ type Runner struct {
 	config       Config
 	stateManager StateManager
 	snapshotter  *snapshot.Snapshotter
+	worktree     *snapshot.Snapshotter
+	cleanups     *cleanup.Registry // Temporary resources (CI fix worktrees) removed when Run returns
+	steps        []StepDef         // Steps each iteration runs: Config.Steps minus Config.SkipSteps, set by Run
+	skipped      []string          // Names of the steps Config.SkipSteps removed
 	promptQueue  *queue.Queue
 	stepContext  *StepContext
 	output       io.Writer
+	confirm      CommitPromptFunc
+	pause        ConfirmFunc
+	onInterrupt  func()
+	noSignals    bool // WithoutSignalHandler: the embedding program handles SIGINT/SIGTERM
+	checks       Checks
+	checksSet    bool         // checks is resolved; RunIteration resolves it when Run hasn't
+	events       *eventSink   // JSON progress events (WithEventSink); nil drops them
+	metrics      *metricsFile // Step timings (WithMetricsFile); nil drops them
+	metricsTask  string       // Task the step timings are recorded for
+	taskSkipped  bool         // The last iteration ended with the /skip directive
+	stopAt       int          // Step an iteration stops before (parallel worktrees); 0 runs them all
 }
 
 // NewRunner creates a new workflow runner. Output defaults to os.Stdout.
@@ -74,14 +261,68 @@ func NewRunner(executor Executor, config Config, opts ...RunnerOption) *Runner {
 		promptQueue:  queue.New(),
 		stepContext:  NewStepContext(),
 		output:       os.Stdout,
+		cleanups:     &cleanup.Registry{},
 	}
 	for _, opt := range opts {
 		opt(r)
 	}
-	r.stepRunner = NewStepRunner(executor, r.output)
+	r.stepRunner = r.newStepRunner(r.output, r.output)
 	return r
 }
 
+// snapshotEveryStep reports whether a snapshot is captured after each step:
+// a snapshotter is configured and the mode is every-step (or unset).
+func (r *Runner) snapshotEveryStep() bool {
+	return r.snapshotter != nil && (r.config.SnapshotMode == "" || r.config.SnapshotMode == SnapshotEveryStep)
+}
+
+// captureSnapshot saves the working tree as a snapshot labelled label and
+// reports whether one was created. Snapshot errors never stop the run; they
+// are only reported.
+func (r *Runner) captureSnapshot(ctx context.Context, label snapshot.Label) bool {
+	if r.snapshotter == nil {
+		return false
+	}
+	created, err := r.snapshotter.Capture(ctx, label.String())
+	if err != nil {
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  snapshot skipped: %v", err)))
+		return false
+	}
+	if created {
+		fmt.Fprint(r.output, ui.Info("  snapshot saved"))
+	}
+	return created
+}
+
+// newStepRunner creates a step runner writing to w with the configured
+// stderr mode. On a TTY, the thinking spinner draws on terminal: w without
+// any output capture.
+func (r *Runner) newStepRunner(w, terminal io.Writer) *StepRunner {
+	opts := []StepRunnerOption{
+		WithStderrMode(r.config.ProviderStderr),
+		WithIdleTimeout(r.config.IdleTimeout),
+		WithStepTimeout(r.config.StepTimeout),
+		WithErrorTail(r.stepErrorTailLines()),
+	}
+	if r.config.IsTTY {
+		opts = append(opts, WithSpinner(terminal))
+	}
+	if r.metrics != nil {
+		opts = append(opts, WithStepTiming(func(t StepTiming) {
+			r.metrics.record(r.stepMetric(t))
+		}))
+	}
+	return NewStepRunner(r.executor, w, opts...)
+}
+
+// stepErrorTailLines resolves Config.StepErrorTailLines.
+func (r *Runner) stepErrorTailLines() int {
+	if r.config.StepErrorTailLines == 0 {
+		return defaultStepErrorTailLines
+	}
+	return r.config.StepErrorTailLines
+}
+
 // RunnerOption configures optional Runner behavior.
 type RunnerOption func(*Runner)
 
@@ -109,11 +350,96 @@ func WithSnapshotter(s *snapshot.Snapshotter) RunnerOption {
 	}
 }
 
+// WithWorkTree sets the git working tree inspected before commit steps.
+// A commit step is skipped when the tree is clean, which keeps resuming
+// across an already-made commit idempotent. Defaults to the snapshotter.
+func WithWorkTree(s *snapshot.Snapshotter) RunnerOption {
+	return func(r *Runner) {
+		r.worktree = s
+	}
+}
+
+// ConfirmFunc asks the user a yes/no question and reports the answer.
+type ConfirmFunc func(ctx context.Context, message string) bool
+
+// CommitDecision is the answer to the commit confirmation prompt.
+type CommitDecision string
+
+const (
+	CommitApprove CommitDecision = "approve" // Run the commit steps
+	CommitSkip    CommitDecision = "skip"    // Skip both commit steps and continue
+	CommitAbort   CommitDecision = "abort"   // Stop the run before committing
+)
+
+// ErrCommitAborted is returned when the user aborts at the commit
+// confirmation prompt. It wraps context.Canceled, so callers treat it as an
+// interruption; the state stays at the commit step for snap resume.
+var ErrCommitAborted = fmt.Errorf("aborted before committing: %w", context.Canceled)
+
+// CommitPromptFunc asks whether to run a task's commit steps.
+type CommitPromptFunc func(ctx context.Context, message string) CommitDecision
+
+// WithCommitConfirm sets the prompt used to gate commit steps when
+// Config.ConfirmCommits is enabled on a TTY.
+func WithCommitConfirm(fn CommitPromptFunc) RunnerOption {
+	return func(r *Runner) {
+		r.confirm = fn
+	}
+}
+
+// WithTaskPause sets the prompt asked between tasks when
+// Config.PauseBetweenTasks is enabled on a TTY.
+func WithTaskPause(fn ConfirmFunc) RunnerOption {
+	return func(r *Runner) {
+		r.pause = fn
+	}
+}
+
+// WithQueue sets the queue of typed directives the runner drains between
+// steps, so an input reader created before the runner can feed it.
+func WithQueue(q *queue.Queue) RunnerOption {
+	return func(r *Runner) {
+		r.promptQueue = q
+	}
+}
+
+// WithStepContext sets the step context the runner updates with the running
+// step, for an input reader created before the runner to display.
+func WithStepContext(c *StepContext) RunnerOption {
+	return func(r *Runner) {
+		r.stepContext = c
+	}
+}
+
+// WithInterruptHook sets a function the signal handler runs before it writes
+// the interrupted message, so the caller can stop reading input and hand the
+// terminal back (leave raw mode, resume paused output) first.
+func WithInterruptHook(fn func()) RunnerOption {
+	return func(r *Runner) {
+		r.onInterrupt = fn
+	}
+}
+
+// WithoutSignalHandler stops Run from handling SIGINT and SIGTERM, for
+// programs that embed the runner and own signal handling. Cancel the context
+// passed to Run to stop it; the interrupt hook is not called.
+func WithoutSignalHandler() RunnerOption {
+	return func(r *Runner) {
+		r.noSignals = true
+	}
+}
+
 // Queue returns the runner's prompt queue for wiring to an input reader.
 func (r *Runner) Queue() *queue.Queue {
 	return r.promptQueue
 }
 
+// Headless reports whether the run takes no queued directives from stdin,
+// so the caller should not start an input reader. See Config.IsHeadless.
+func (r *Runner) Headless() bool {
+	return r.config.IsHeadless()
+}
+
 // StepContext returns the runner's step context for queue UI display.
 func (r *Runner) StepContext() *StepContext {
 	return r.stepContext
@@ -127,35 +453,18 @@ func (r *Runner) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	defer signal.Stop(sigChan)
+	if !r.noSignals {
+		defer r.handleSignals(cancel)()
+	}
 
-	go func() {
-		<-sigChan
-		// Write the interrupted message, bypassing a potentially-paused buffer
-		// (e.g., SwitchWriter in paused mode when user is composing input).
-		// Use Direct() if available to ensure the message is always visible.
-		// After cancel(), the main goroutine will return through its defer chain,
-		// ensuring terminal cleanup runs.
-		currentState, err := r.stateManager.Load()
-		var msg string
-		if err == nil && currentState != nil {
-			msg = ui.InterruptedWithContext("Stopped by user", currentState.CurrentStep, currentState.TotalSteps)
-		} else {
-			msg = ui.Interrupted("Stopped by user")
-		}
+	// Whatever an interrupt or error left registered (e.g. a CI fix
+	// worktree) is removed on the way out.
+	defer r.runCleanups()
 
-		if sw, ok := r.output.(*ui.SwitchWriter); ok {
-			//nolint:errcheck // Best-effort flush of interrupted message; signal handler context.
-			_, _ = sw.Direct([]byte(msg))
-		} else {
-			fmt.Fprint(r.output, msg)
-		}
-		cancel()
-		// After signal.Stop (deferred above), a second SIGINT gets Go's
-		// default behavior: immediate process termination.
-	}()
+	configured, unknownModels, err := r.prepareSteps()
+	if err != nil {
+		return err
+	}
 
 	// Handle fresh start flag
 	if r.config.FreshStart && r.stateManager.Exists() {
@@ -178,22 +487,57 @@ func (r *Runner) Run(ctx context.Context) error {
 
 	// Initialize state if needed
 	if workflowState == nil {
-		workflowState = state.NewState(r.config.TasksDir, r.config.PRDPath, workflowStepCount)
+		workflowState = state.NewState(r.config.TasksDir, r.config.PRDPath, len(r.steps))
+		workflowState.SkippedSteps = r.skipped
+	}
+
+	// A run that died mid-step (crash, reboot) leaves its PID behind. Claim
+	// the state for this process: the PID is saved with the next state update
+	// and cleared on exit.
+	abandoned := workflowState.Abandoned()
+	previousPID, previousStep := workflowState.PID, workflowState.CurrentStep
+	workflowState.PID = os.Getpid()
+	defer r.releaseState()
+
+	if r.config.OnlyTask != "" {
+		if err := r.resolveOnlyTask(workflowState); err != nil {
+			return err
+		}
+	}
+
+	// A task started with other steps skipped continues at the same step.
+	if r.config.ResumeStep == 0 {
+		if err := RemapSkippedStep(workflowState, configured, r.config.SkipSteps); err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
 	}
 
 	// Resolve startup target: resume active task or select next.
-	target, err := resolveStartup(workflowState, r.config.TasksDir, r.config.TaskFilePath, workflowStepCount)
+	target, err := resolveStartup(workflowState, r.config.TasksDir, r.config.TaskFilePath, r.config.TasksGlob, len(r.steps), r.config.ResumeStep)
 	if err != nil {
 		return fmt.Errorf("cannot resume: %w", err)
 	}
 
 	isResume := target.action == actionResume
 
+	if r.config.ResumeStep != 0 {
+		if target.action == actionMonitorCI {
+			return fmt.Errorf("cannot resume: %w", errStepWhileMonitoringCI)
+		}
+		if !isResume {
+			return fmt.Errorf("cannot resume: %w", ErrNothingToResume)
+		}
+		workflowState.CurrentStep = target.step
+	}
+
 	switch target.action {
 	case actionResume:
 		if workflowState.LastError != "" {
 			fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Last error: %s", workflowState.LastError)))
 		}
+		if abandoned {
+			r.noteUncleanShutdown(previousPID, previousStep)
+		}
 	case actionSelect:
 		done, err := r.selectIdleTask(ctx, workflowState)
 		if err != nil {
@@ -202,6 +546,26 @@ func (r *Runner) Run(ctx context.Context) error {
 		if done {
 			return nil
 		}
+	case actionMonitorCI:
+		if r.config.OnlyTask != "" {
+			// Starting the task ends the interrupted CI monitoring; the
+			// next full run goes through post-run again.
+			done, err := r.selectIdleTask(ctx, workflowState)
+			if err != nil || done {
+				return err
+			}
+			break
+		}
+		fmt.Fprint(r.output, ui.Info("All tasks implemented, reattaching to CI monitoring"))
+		return r.runPostrun(ctx, workflowState)
+	case actionPublish:
+		if err := r.resumePublish(ctx, workflowState); err != nil {
+			return err
+		}
+		done, err := r.selectIdleTask(ctx, workflowState)
+		if err != nil || done {
+			return err
+		}
 	}
 
 	// Print startup summary.
@@ -217,60 +581,259 @@ func (r *Runner) Run(ctx context.Context) error {
 		}
 		taskCount = len(tasks)
 	}
-	doneCount := len(workflowState.CompletedTaskIDs)
-	var action string
+	summary := ui.StartupSummary{
+		DisplayName: r.config.TasksDir,
+		Provider:    r.config.ProviderName,
+		TaskCount:   taskCount,
+		DoneCount:   len(workflowState.CompletedTaskIDs),
+	}
+	if r.config.DisplayName != "" {
+		summary.DisplayName = r.config.DisplayName
+	}
+	if r.config.PinnedModels {
+		summary.Models = fmt.Sprintf("fast: %s, thinking: %s", r.modelName(model.Fast), r.modelName(model.Thinking))
+	}
 	if isResume {
-		action = fmt.Sprintf("resuming %s from step %d", target.taskID, target.step)
+		summary.Action = fmt.Sprintf("resuming %s from step %d", target.taskID, target.step)
 	} else {
-		action = fmt.Sprintf("starting %s", workflowState.CurrentTaskID)
+		summary.Action = fmt.Sprintf("starting %s", workflowState.CurrentTaskID)
 	}
-	displayName := r.config.TasksDir
-	if r.config.DisplayName != "" {
-		displayName = r.config.DisplayName
+	fmt.Fprintln(r.output, summary)
+	r.events.emit(Event{Type: EventRunStarted, Summary: &summary})
+
+	r.checks, r.checksSet = resolveChecks(r.config, "."), true
+	if line := formatChecks(r.checks); line != "" {
+		fmt.Fprint(r.output, ui.Info(line))
+	}
+	if len(r.skipped) > 0 {
+		fmt.Fprint(r.output, ui.Info("  skipping: "+strings.Join(r.skipped, ", ")))
+	}
+	for _, name := range unknownModels {
+		fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Model override for unknown step %q ignored (steps: %s)",
+			name, strings.Join(stepDefNames(configured), ", "))))
 	}
-	fmt.Fprintln(r.output, ui.FormatStartupSummary(displayName, r.config.ProviderName, taskCount, doneCount, action))
 
-	// Print prompt hint on fresh start with TTY (suppress on resume).
-	if !isResume && r.config.IsTTY {
+	// Print prompt hint on fresh start with TTY (suppress on resume and headless).
+	if !isResume && !r.config.IsHeadless() {
 		fmt.Fprint(r.output, ui.Info("Type a directive and press Enter to queue it between steps"))
 	}
 
 	// Run the workflow loop
+	iterations := 0
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			iterationComplete, err := r.runIteration(ctx, workflowState)
+			completed, err := r.runParallel(ctx, workflowState, iterations)
 			if err != nil {
-				// If context was cancelled (e.g., by signal handler), return
-				// the context error so the caller can map it to exit code 130.
-				if ctx.Err() != nil {
-					return ctx.Err()
-				}
-				// Save error state
-				workflowState.MarkStepFailed(err)
-				if saveErr := r.stateManager.Save(workflowState); saveErr != nil {
-					fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Failed to save error state: %v", saveErr)))
+				return err
+			}
+			if completed > 0 {
+				iterations += completed
+				if stop, err := r.advance(ctx, workflowState, iterations); stop || err != nil {
+					return err
 				}
-				return fmt.Errorf("iteration failed: %w", err)
+				continue
+			}
+
+			if err := r.resumeKeptBranch(ctx, workflowState); err != nil {
+				return err
+			}
+			iterationComplete, err := r.RunIteration(ctx, workflowState)
+			if err != nil {
+				return r.iterationFailed(ctx, workflowState, err)
 			}
 
 			if iterationComplete {
-				// Select next task for the next iteration.
-				// selectIdleTask handles the "all complete" case.
-				done, err := r.selectIdleTask(ctx, workflowState)
-				if err != nil {
-					return err
+				switch {
+				case r.config.PRPerTask && r.taskSkipped:
+					// Nothing of a skipped task is published.
+					if err := r.switchToBase(ctx, workflowState); err != nil {
+						return err
+					}
+				case r.config.PRPerTask:
+					if err := r.publishTask(ctx, workflowState); err != nil {
+						return err
+					}
 				}
-				if done {
+
+				if r.config.OnlyTask != "" {
+					fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Finished %s; not continuing to other tasks (--only-task)", r.config.OnlyTask)))
 					return nil
 				}
+
+				iterations++
+				if stop, err := r.advance(ctx, workflowState, iterations); stop || err != nil {
+					return err
+				}
 			}
 		}
 	}
 }
 
+// iterationFailed records err, returned by an iteration, in the state and
+// returns the error the run ends with.
+func (r *Runner) iterationFailed(ctx context.Context, workflowState *state.State, err error) error {
+	// If context was cancelled (e.g., by signal handler), return the
+	// context error so the caller can map it to exit code 130.
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	// An abort at the commit prompt leaves the state at the commit step,
+	// so it isn't recorded as a failure.
+	if errors.Is(err, ErrCommitAborted) {
+		return err
+	}
+	// Save error state
+	workflowState.MarkStepFailed(err)
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		workflowState.LastFailure = &stepErr.Failure
+	}
+	if saveErr := r.stateManager.Save(workflowState); saveErr != nil {
+		fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Failed to save error state: %v", saveErr)))
+	}
+	return fmt.Errorf("iteration failed: %w", err)
+}
+
+// prepareSteps sets the steps each iteration runs from Config.Steps,
+// Config.StepModelOverrides and Config.SkipSteps. It returns the configured
+// steps before skipping and the overridden step names that match no step.
+func (r *Runner) prepareSteps() (configured []StepDef, unknownModels []string, err error) {
+	if err := ValidateStepModels(r.config.StepModelOverrides); err != nil {
+		return nil, nil, fmt.Errorf("invalid step model overrides: %w", err)
+	}
+	configured, unknownModels = ApplyStepModels(r.config.Steps, r.config.StepModelOverrides)
+	steps, skipped, err := SkipSteps(configured, r.config.SkipSteps)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid skipped steps: %w", err)
+	}
+	r.steps, r.skipped = steps, skipped
+	return configured, unknownModels, nil
+}
+
+// handleSignals cancels the run on SIGINT/SIGTERM and returns the function
+// that stops handling them.
+func (r *Runner) handleSignals(cancel context.CancelFunc) (stop func()) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		// Restore the terminal before anything is printed: the user may be
+		// composing a directive with stdin in raw mode and output paused.
+		if r.onInterrupt != nil {
+			r.onInterrupt()
+		}
+
+		// Write the interrupted message, bypassing a potentially-paused buffer
+		// (e.g., SwitchWriter in paused mode when user is composing input).
+		// Use Direct() if available to ensure the message is always visible.
+		// After cancel(), the main goroutine will return through its defer chain,
+		// ensuring terminal cleanup runs.
+		currentState, err := r.stateManager.Load()
+		var msg string
+		if err == nil && currentState != nil {
+			msg = ui.InterruptedWithContext("Stopped by user", currentState.CurrentStep, currentState.TotalSteps)
+		} else {
+			msg = ui.Interrupted("Stopped by user")
+		}
+
+		if sw, ok := r.output.(*ui.SwitchWriter); ok {
+			//nolint:errcheck // Best-effort flush of interrupted message; signal handler context.
+			_, _ = sw.Direct([]byte(msg))
+		} else {
+			fmt.Fprint(r.output, msg)
+		}
+		cancel()
+		// After the returned stop function runs, a second SIGINT gets Go's
+		// default behavior: immediate process termination.
+	}()
+
+	return func() { signal.Stop(sigChan) }
+}
+
+// advance moves on after completed tasks, iterations in total so far: it
+// stops at Config.MaxIterations, asks before the next task when pausing
+// between tasks, and selects the next task. It reports whether the run
+// should stop.
+func (r *Runner) advance(ctx context.Context, workflowState *state.State, iterations int) (bool, error) {
+	if r.config.MaxIterations > 0 && iterations >= r.config.MaxIterations && r.tasksRemain(workflowState) {
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("reached max iterations (%d), stopping", r.config.MaxIterations)))
+		return true, nil
+	}
+
+	if !r.continueToNextTask(ctx, workflowState) {
+		if ctx.Err() != nil {
+			return true, ctx.Err()
+		}
+		fmt.Fprint(r.output, ui.Info("Paused before the next task; run snap again to continue"))
+		return true, nil
+	}
+
+	// Select next task for the next iteration.
+	// selectIdleTask handles the "all complete" case.
+	return r.selectIdleTask(ctx, workflowState)
+}
+
+// noteUncleanShutdown tells the user that the previous run stopped mid-step
+// without saving an error, and which step runs now.
+func (r *Runner) noteUncleanShutdown(pid, step int) {
+	fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf(
+		"Previous run did not finish: process %d stopped during step %d/%d (%s), e.g. after a reboot or crash",
+		pid, step, len(r.steps), StepName(r.steps, step))))
+	if r.config.ResumeStep != 0 {
+		return
+	}
+	fmt.Fprint(r.output, ui.Info(fmt.Sprintf(
+		"Re-running step %d from the start; to start elsewhere, stop and run: snap resume --step <n>", step)))
+}
+
+// releaseState clears this process's PID from the saved state, marking a
+// clean shutdown. State that was reset when the run finished stays absent,
+// and a PID this run never saved is left for the next run to report.
+func (r *Runner) releaseState() {
+	saved, err := r.stateManager.Load()
+	if err != nil || saved == nil || saved.PID != os.Getpid() {
+		return
+	}
+	saved.PID = 0
+	if err := r.stateManager.Save(saved); err != nil {
+		fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to save state: %v", err)))
+	}
+}
+
+// runStep runs a step, retrying provider failures up to
+// Config.MaxStepRetries times with exponential backoff from
+// Config.RetryBackoff. reset runs before each retry to drop the failed
+// attempt's captured output. Cancellation ends the retries at once.
+func (r *Runner) runStep(ctx context.Context, sr *StepRunner, stepNum, totalSteps int, step workflowStep, args []string, reset func()) error {
+	delay := r.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := sr.RunStepNumbered(ctx, stepNum, totalSteps, step.name, step.model, args...)
+		if err == nil || ctx.Err() != nil || attempt > r.config.MaxStepRetries || !errors.Is(err, ErrProvider) {
+			return err
+		}
+		fmt.Fprint(sr.output, ui.Info(fmt.Sprintf("  retrying step %d (attempt %d/%d)", stepNum, attempt+1, r.config.MaxStepRetries+1)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		reset()
+	}
+}
+
+// runCleanups removes the temporary resources still registered, warning
+// about any that can't be removed.
+func (r *Runner) runCleanups() {
+	if err := r.cleanups.Run(); err != nil {
+		fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: cleanup failed: %v", err)))
+	}
+}
+
 // selectIdleTask scans for TASK<n>.md files and selects the next incomplete task.
 // It updates the state with the selected task and saves it. Returns (true, nil) when
 // all tasks are complete (caller should exit cleanly).
@@ -279,36 +842,51 @@ func (r *Runner) selectIdleTask(ctx context.Context, workflowState *state.State)
 	if err != nil {
 		return false, fmt.Errorf("failed to scan tasks: %w", err)
 	}
+	if len(tasks) == 0 && r.config.TasksGlob != "" {
+		return false, fmt.Errorf("no task files matching %s found in %s", r.config.TasksGlob, r.config.TasksDir)
+	}
 	if len(tasks) == 0 {
 		hints := DiagnoseEmptyTaskDir(r.config.TasksDir)
 		return false, fmt.Errorf("%s", FormatTaskDirError(r.config.TasksDir, hints))
 	}
 
 	next := SelectNextTask(tasks, workflowState.CompletedTaskIDs)
+	if r.config.OnlyTask != "" {
+		next = findTask(tasks, r.config.OnlyTask)
+		// Running a completed task again makes it incomplete until it
+		// completes again.
+		workflowState.CompletedTaskIDs = slices.DeleteFunc(workflowState.CompletedTaskIDs, func(id string) bool {
+			return id == r.config.OnlyTask
+		})
+	}
 	if next == nil {
 		// All discovered tasks are completed.
 		fmt.Fprint(r.output, ui.Complete("All tasks implemented!"))
 
-		// Run post-completion step (push, PR, CI).
-		if err := postrun.Run(ctx, postrun.Config{
-			Output:    r.output,
-			Executor:  r.executor,
-			RemoteURL: r.config.RemoteURL,
-			IsGitHub:  r.config.IsGitHub,
-			PRDPath:   r.config.PRDPath,
-			TasksDir:  r.config.TasksDir,
-		}); err != nil {
-			return false, err
+		// Each task already went through post-run on its own branch.
+		if r.config.PRPerTask {
+			if err := r.switchToBase(ctx, workflowState); err != nil {
+				return false, err
+			}
+			if err := r.stateManager.Reset(); err != nil {
+				fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to clean up state: %v", err)))
+			}
+			return true, nil
 		}
 
-		if err := r.stateManager.Reset(); err != nil {
-			fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to clean up state: %v", err)))
+		if err := r.runPostrun(ctx, workflowState); err != nil {
+			return false, err
 		}
 		return true, nil
 	}
 
-	workflowState.CurrentTaskID = next.ID
-	workflowState.CurrentTaskFile = next.Filename
+	if r.config.PRPerTask {
+		if err := r.startTaskBranch(ctx, workflowState, next.ID); err != nil {
+			return false, err
+		}
+	}
+
+	workflowState.StartTask(next.ID, next.Filename)
 	if err := r.stateManager.Save(workflowState); err != nil {
 		return false, fmt.Errorf("failed to save state after task selection: %w", err)
 	}
@@ -316,36 +894,330 @@ func (r *Runner) selectIdleTask(ctx context.Context, workflowState *state.State)
 	return false, nil
 }
 
-func (r *Runner) runIteration(ctx context.Context, workflowState *state.State) (bool, error) {
-	taskStart := time.Now()
-	taskLabel := workflowState.CurrentTaskID
-	if taskLabel == "" {
-		taskLabel = "next task"
+// resolveOnlyTask checks that Config.OnlyTask names a task file and that no
+// other task is in progress, normalizing it to the task's ID.
+func (r *Runner) resolveOnlyTask(workflowState *state.State) error {
+	tasks, err := r.discoverTasks()
+	if err != nil {
+		return fmt.Errorf("failed to scan tasks: %w", err)
 	}
-	// Generate a one-line task description via fast model (best-effort).
-	var description string
-	if workflowState.CurrentTaskFile != "" {
-		taskFilePath := r.activeTaskPath(workflowState.CurrentTaskFile)
-		if content, err := os.ReadFile(taskFilePath); err == nil {
-			// Truncate to first 2000 bytes to avoid sending large files to LLM.
-			taskContent := string(content)
-			if len(taskContent) > 2000 {
-				taskContent = taskContent[:2000]
-			}
-			if prompt, err := prompts.TaskSummary(prompts.TaskSummaryData{TaskContent: taskContent}); err == nil {
-				var buf strings.Builder
-				if err := r.stepRunner.executor.Run(ctx, &buf, model.Fast, prompt); err == nil {
-					description = ui.StripColors(strings.TrimSpace(buf.String()))
-				}
-			}
+	task := findTask(tasks, r.config.OnlyTask)
+	if task == nil {
+		return fmt.Errorf("task %s not found in %s; check the task ID, or use --fresh to reset or --show-state to inspect",
+			r.config.OnlyTask, r.config.TasksDir)
+	}
+	r.config.OnlyTask = task.ID
+	if active := workflowState.CurrentTaskID; active != "" && active != task.ID {
+		return fmt.Errorf("cannot run %s: %s is in progress; finish it with snap resume first, or use --fresh to reset", task.ID, active)
+	}
+	return nil
+}
+
+// findTask returns the task with the given ID (matched case-insensitively),
+// or nil.
+func findTask(tasks []TaskInfo, id string) *TaskInfo {
+	for i := range tasks {
+		if strings.EqualFold(tasks[i].ID, id) {
+			return &tasks[i]
 		}
 	}
+	return nil
+}
 
-	fmt.Fprint(r.output, ui.Header(fmt.Sprintf("Implementing %s", taskLabel), description))
+// runPostrun runs the post-completion step (push, PR, CI) and clears the
+// state once it finishes. Entering CI monitoring is recorded in the state, so
+// a run interrupted while monitoring reattaches to CI on the next start
+// instead of going through post-run from scratch.
+func (r *Runner) runPostrun(ctx context.Context, workflowState *state.State) error {
+	cfg := r.postrunConfig()
+	cfg.Reattach = workflowState.MonitoringCI
+	cfg.OnMonitorCI = func() error {
+		workflowState.MonitoringCI = true
+		return r.stateManager.Save(workflowState)
+	}
+	if err := postrun.Run(ctx, cfg); err != nil {
+		// A failed post-run starts over on the next run rather than
+		// reattaching to CI that already ended.
+		if workflowState.MonitoringCI && ctx.Err() == nil {
+			workflowState.MonitoringCI = false
+			if saveErr := r.stateManager.Save(workflowState); saveErr != nil {
+				fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to save state: %v", saveErr)))
+			}
+		}
+		return err
+	}
+
+	// CI monitoring exits cleanly on cancellation; keep the state so the
+	// next run reattaches.
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if err := r.stateManager.Reset(); err != nil {
+		fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to clean up state: %v", err)))
+	}
+	return nil
+}
+
+// postrunConfig returns the post-run configuration shared by the end-of-run
+// and per-task post-runs.
+func (r *Runner) postrunConfig() postrun.Config {
+	return postrun.Config{
+		Output:     r.output,
+		Executor:   r.executor,
+		RemoteURL:  r.config.RemoteURL,
+		IsGitHub:   r.config.IsGitHub,
+		PushRemote: r.config.PushRemote,
+		Repo:       r.config.PRRepo,
+		PRDPath:    r.config.PRDPath,
+		TasksDir:   r.config.TasksDir,
+
+		PollInterval: r.config.CIPollInterval,
+		IsolateCIFix: r.config.IsolateCIFix,
+		Cleanup:      r.cleanups,
+		FixFallbacks: r.config.CIFixFallbacks,
+		SignCommits:  r.config.SignCommits,
+	}
+}
+
+// startTaskBranch checks out a new branch for the task from the base branch
+// (Config.PRPerTask). The branch checked out when the first task starts
+// becomes the base branch and is kept in the state, so an interrupted run
+// still knows where to branch from.
+func (r *Runner) startTaskBranch(ctx context.Context, workflowState *state.State, taskID string) error {
+	if r.worktree == nil {
+		return errors.New("PR per task: no git work tree")
+	}
+	current, err := r.worktree.Branch(ctx)
+	if err != nil {
+		return fmt.Errorf("PR per task: %w", err)
+	}
+	if workflowState.BaseBranch == "" {
+		if current == "" {
+			return errors.New("PR per task: HEAD is detached; check out the base branch first")
+		}
+		workflowState.BaseBranch = current
+	}
+	if current != workflowState.BaseBranch {
+		if err := r.worktree.Switch(ctx, workflowState.BaseBranch, false); err != nil {
+			return fmt.Errorf("PR per task: %w", err)
+		}
+	}
+	// A branch left by an earlier run of the task is reset to the base.
+	branch := taskBranch(taskID)
+	if err := r.worktree.Switch(ctx, branch, true); err != nil {
+		return fmt.Errorf("PR per task: %w", err)
+	}
+	fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Created branch %s from %s", branch, workflowState.BaseBranch)))
+	return nil
+}
+
+// publishTask runs post-run (push, PR, CI) for the task just completed on
+// its own branch, then returns to the base branch for the next task. The
+// state's PublishBranch, set when the task completed, is cleared only once
+// post-run finishes, so a failed or interrupted post-run runs again first
+// on the next start.
+func (r *Runner) publishTask(ctx context.Context, workflowState *state.State) error {
+	if err := postrun.Run(ctx, r.postrunConfig()); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	workflowState.PublishBranch = ""
+	if err := r.stateManager.Save(workflowState); err != nil {
+		return fmt.Errorf("failed to save state after publishing: %w", err)
+	}
+	return r.switchToBase(ctx, workflowState)
+}
+
+// resumePublish checks out the branch of a task an earlier run completed but
+// did not finish publishing, and publishes it.
+func (r *Runner) resumePublish(ctx context.Context, workflowState *state.State) error {
+	if r.worktree == nil {
+		return errors.New("PR per task: no git work tree")
+	}
+	branch := workflowState.PublishBranch
+	current, err := r.worktree.Branch(ctx)
+	if err != nil {
+		return fmt.Errorf("PR per task: %w", err)
+	}
+	if current != branch {
+		if err := r.worktree.Switch(ctx, branch, false); err != nil {
+			return fmt.Errorf("PR per task: %w", err)
+		}
+	}
+	fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Publishing %s, left unpublished by the last run", branch)))
+	return r.publishTask(ctx, workflowState)
+}
+
+// switchToBase checks out the base branch recorded by startTaskBranch, if
+// any and not already checked out.
+func (r *Runner) switchToBase(ctx context.Context, workflowState *state.State) error {
+	if workflowState.BaseBranch == "" || r.worktree == nil {
+		return nil
+	}
+	current, err := r.worktree.Branch(ctx)
+	if err != nil {
+		return fmt.Errorf("PR per task: %w", err)
+	}
+	if current == workflowState.BaseBranch {
+		return nil
+	}
+	if err := r.worktree.Switch(ctx, workflowState.BaseBranch, false); err != nil {
+		return fmt.Errorf("PR per task: %w", err)
+	}
+	fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Switched back to %s", workflowState.BaseBranch)))
+	return nil
+}
+
+// taskBranchUnsafe matches characters kept out of task branch names.
+var taskBranchUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// taskBranch returns the branch a task is implemented on with PR per task,
+// e.g. "snap/task1".
+func taskBranch(taskID string) string {
+	return "snap/" + strings.Trim(taskBranchUnsafe.ReplaceAllString(strings.ToLower(taskID), "-"), "-.")
+}
+
+// taskDescription is a generated task description and the SHA-256 of the
+// task file content it was generated from.
+type taskDescription struct {
+	text string
+	hash string
+}
+
+// cachedDescription reports whether cached still describes the task file,
+// i.e. the file content has not changed since it was generated.
+func (r *Runner) cachedDescription(taskFile string, cached taskDescription) bool {
+	if !r.config.Describe || taskFile == "" || cached.text == "" {
+		return false
+	}
+	content, err := os.ReadFile(r.activeTaskPath(taskFile))
+	if err != nil {
+		return false
+	}
+	return contentHash(content) == cached.hash
+}
+
+// contentHash returns the hex SHA-256 of data.
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// describeTask generates a one-line task description (best-effort). Returns an
+// empty description when the pre-step is disabled, no task is selected, or any
+// part of the generation fails.
+func (r *Runner) describeTask(ctx context.Context, taskFile string) taskDescription {
+	if !r.config.Describe || taskFile == "" {
+		return taskDescription{}
+	}
+
+	content, err := os.ReadFile(r.activeTaskPath(taskFile))
+	if err != nil {
+		return taskDescription{}
+	}
+	hash := contentHash(content)
+
+	// Truncate to avoid sending large files to the LLM.
+	maxBytes := r.config.DescriptionMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultDescriptionMaxBytes
+	}
+	taskContent := string(content)
+	if len(taskContent) > maxBytes {
+		taskContent = taskContent[:maxBytes]
+	}
+
+	prompt, err := prompts.TaskSummary(prompts.TaskSummaryData{TaskContent: taskContent})
+	if err != nil {
+		return taskDescription{}
+	}
+
+	mt := r.config.DescriptionModel
+	if mt == "" {
+		mt = model.Fast
+	}
+
+	var buf strings.Builder
+	if err := r.stepRunner.executor.Run(ctx, &buf, mt, prompt); err != nil {
+		return taskDescription{}
+	}
+	return taskDescription{text: ui.StripColors(strings.TrimSpace(buf.String())), hash: hash}
+}
+
+// RunIteration runs the workflow steps once for the task in workflowState,
+// from its current step, and reports whether they all completed. Unlike Run
+// it handles no signals and does no startup or post-run work: it doesn't
+// load or reset the state, select the task, or record a failure. Set
+// CurrentTaskID and CurrentTaskFile first, or leave them empty to let the
+// implement step pick the next task. The lint and test commands are
+// detected and overridden as in Run. Progress is saved through the state
+// manager after each step.
+func (r *Runner) RunIteration(ctx context.Context, workflowState *state.State) (bool, error) {
+	if r.steps == nil {
+		if _, _, err := r.prepareSteps(); err != nil {
+			return false, err
+		}
+	}
+	if !r.checksSet {
+		r.checks, r.checksSet = resolveChecks(r.config, "."), true
+	}
+	return r.runIteration(ctx, workflowState)
+}
+
+func (r *Runner) runIteration(ctx context.Context, workflowState *state.State) (bool, error) {
+	r.taskSkipped = false
+	taskStart := time.Now()
+	taskLabel := workflowState.CurrentTaskID
+	if taskLabel == "" {
+		taskLabel = "next task"
+	}
+	// Generate the task description in the background so the first step is
+	// not delayed. Output is held until the header is rendered, which happens
+	// when the description arrives or the first step finishes, whichever is
+	// first. A description cached in the state is reused while the task file
+	// is unchanged; a new one is stored once the goroutine has finished.
+	header := newHeaderGate(r.output, fmt.Sprintf("Implementing %s", taskLabel))
+	describeCtx, cancelDescribe := context.WithCancel(ctx)
+	describeDone := make(chan struct{})
+	taskFile := workflowState.CurrentTaskFile
+	var described taskDescription
+	cached := taskDescription{text: workflowState.TaskDescription, hash: workflowState.TaskDescriptionHash}
+	if r.cachedDescription(taskFile, cached) {
+		described = cached
+		header.Open(cached.text)
+		close(describeDone)
+	} else {
+		go func() {
+			defer close(describeDone)
+			described = r.describeTask(describeCtx, taskFile)
+			header.Open(described.text)
+		}()
+	}
+	describeStored := false
+	finishDescribe := func() {
+		cancelDescribe()
+		header.Open("")
+		<-describeDone
+		if !describeStored && described.text != "" {
+			workflowState.TaskDescription = described.text
+			workflowState.TaskDescriptionHash = described.hash
+		}
+		describeStored = true
+	}
+	defer finishDescribe()
+
+	// Step timings are written when the iteration ends, however it ends.
+	r.metricsTask = taskLabel
+	defer r.flushMetrics()
 
 	// Build the Step 1 prompt based on whether a specific task is targeted.
 	implementData := prompts.ImplementData{
-		PRDPath: r.config.PRDPath,
+		PRDPath:    r.config.PRDPath,
+		MemoryDir:  r.config.MemoryDir,
+		Guardrails: r.config.ExtraGuardrails,
 	}
 	if workflowState.CurrentTaskFile != "" {
 		implementData.TaskPath = r.activeTaskPath(workflowState.CurrentTaskFile)
@@ -357,154 +1229,295 @@ func (r *Runner) runIteration(ctx context.Context, workflowState *state.State) (
 	}
 
 	ensureCompletenessPrompt, err := prompts.EnsureCompleteness(prompts.EnsureCompletenessData{
-		TaskPath: implementData.TaskPath,
-		TaskID:   implementData.TaskID,
+		TaskPath:  implementData.TaskPath,
+		TaskID:    implementData.TaskID,
+		TestsPath: testsOutlinePath(implementData.TaskPath),
 	})
 	if err != nil {
 		return false, fmt.Errorf("failed to render ensure-completeness prompt: %w", err)
 	}
 
+	lintAndTestPrompt, err := prompts.LintAndTest(prompts.LintAndTestData{
+		Scope:        r.config.Scope,
+		LintCommand:  r.checks.Lint,
+		TestCommand:  r.checks.Test,
+		ReportResult: r.config.FailFastOnLint,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to render lint-and-test prompt: %w", err)
+	}
+
+	// The review and docs steps diff against the task's start commit, so it
+	// is recorded before their prompts are rendered.
+	if workflowState.CurrentStep <= 1 && workflowState.TaskStartCommit == "" {
+		r.recordTaskStart(ctx, workflowState)
+	}
+	baseRef := r.diffBase(workflowState)
+
 	codeReviewPrompt, err := prompts.CodeReview(prompts.CodeReviewData{
-		TaskPath: implementData.TaskPath,
-		TaskID:   implementData.TaskID,
+		TaskPath:   implementData.TaskPath,
+		TaskID:     implementData.TaskID,
+		Scope:      r.config.Scope,
+		BaseRef:    baseRef,
+		Guardrails: r.config.ExtraGuardrails,
 	})
 	if err != nil {
 		return false, fmt.Errorf("failed to render code-review prompt: %w", err)
 	}
 
 	updateDocsPrompt, err := prompts.UpdateDocs(prompts.UpdateDocsData{
-		TaskPath: implementData.TaskPath,
-		TaskID:   implementData.TaskID,
+		TaskPath:  implementData.TaskPath,
+		TaskID:    implementData.TaskID,
+		Scope:     r.config.Scope,
+		BaseRef:   baseRef,
+		Changelog: r.changelogPath(),
 	})
 	if err != nil {
 		return false, fmt.Errorf("failed to render update-docs prompt: %w", err)
 	}
 
-	steps := []struct {
-		name   string
-		prompt string
-		args   []string
-		model  model.Type
-	}{
-		{
-			name:   fmt.Sprintf("Implement %s", taskLabel),
-			prompt: implementPrompt,
-			model:  model.Thinking,
-		},
-		{
-			name:   "Ensure completeness",
-			prompt: ensureCompletenessPrompt,
-			model:  model.Thinking,
-		},
-		{
-			name:   "Lint & test",
-			prompt: prompts.LintAndTest(),
-			args:   []string{"-c"},
-			model:  model.Fast,
-		},
-		{
-			name:   "Code review",
-			prompt: codeReviewPrompt,
-			model:  model.Thinking,
-		},
-		{
-			name:   "Apply fixes",
-			prompt: prompts.ApplyFixes(),
-			args:   []string{"-c"},
-			model:  model.Fast,
-		},
-		{
-			name:   "Verify fixes",
-			prompt: prompts.LintAndTest(),
-			args:   []string{"-c"},
-			model:  model.Fast,
-		},
-		{
-			name:   "Update docs",
-			prompt: updateDocsPrompt,
-			model:  model.Fast,
-		},
-		{
-			name:   "Commit code",
-			prompt: prompts.Commit(),
-			model:  model.Fast,
-		},
-		{
-			name:   "Update memory",
-			prompt: prompts.MemoryUpdate(),
-			args:   []string{"-c"},
-			model:  model.Fast,
-		},
-		{
-			name:   "Commit memory",
-			prompt: prompts.Commit(),
-			args:   []string{"-c"},
-			model:  model.Fast,
-		},
+	memoryUpdatePrompt, err := prompts.MemoryUpdate(prompts.MemoryUpdateData{Dir: r.config.MemoryDir})
+	if err != nil {
+		return false, fmt.Errorf("failed to render memory-update prompt: %w", err)
+	}
+
+	stepPrompts := map[string]string{
+		PromptImplement:          implementPrompt,
+		PromptEnsureCompleteness: ensureCompletenessPrompt,
+		PromptLintAndTest:        lintAndTestPrompt,
+		PromptCodeReview:         codeReviewPrompt,
+		PromptApplyFixes:         prompts.ApplyFixes(),
+		PromptUpdateDocs:         updateDocsPrompt,
+		PromptCommit:             prompts.Commit(),
+		PromptMemoryUpdate:       memoryUpdatePrompt,
+	}
+	steps := buildSteps(r.steps, stepPrompts, taskLabel)
+
+	if err := validateSteps(steps); err != nil {
+		return false, err
 	}
 
 	// Resume from current step
 	startStep := workflowState.CurrentStep
-	if startStep > 1 {
-		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Resuming from step %d: %s", startStep, steps[startStep-1].name)))
+	if startStep > 1 && startStep <= len(steps) {
+		fmt.Fprint(header, ui.Info(fmt.Sprintf("Resuming from step %d: %s", startStep, steps[startStep-1].name)))
 	}
 
 	totalSteps := len(steps)
-	if totalSteps != workflowStepCount {
-		return false, fmt.Errorf("internal error: workflowStepCount(%d) != len(steps)(%d); update the constant", workflowStepCount, totalSteps)
-	}
 
-	// Ensure state has correct total steps (handles state from older versions or fresh start)
-	if workflowState.TotalSteps != totalSteps {
+	// Ensure state has correct total steps (handles state from older versions,
+	// a changed step list, or fresh start)
+	if workflowState.TotalSteps != totalSteps || !slices.Equal(workflowState.SkippedSteps, r.skipped) {
+		if startStep > 1 && workflowState.TotalSteps > 0 && workflowState.TotalSteps != totalSteps {
+			fmt.Fprint(header, ui.Interrupted(fmt.Sprintf("Workflow changed since %s started (%d steps, now %d)",
+				taskLabel, workflowState.TotalSteps, totalSteps)))
+		}
 		workflowState.TotalSteps = totalSteps
+		workflowState.SkippedSteps = r.skipped
 		if err := r.stateManager.Save(workflowState); err != nil {
 			return false, fmt.Errorf("failed to update total steps in state: %w", err)
 		}
 	}
+	taskID := workflowState.CurrentTaskID
+	r.events.emit(Event{Type: EventTaskSelected, TaskID: taskID, Step: startStep, TotalSteps: totalSteps})
 
+	var commitDecision CommitDecision
+	checksUnverified := false
 	for stepNum := startStep; stepNum <= totalSteps; stepNum++ {
 		// Check for context cancellation before starting each step.
 		if ctx.Err() != nil {
 			return false, ctx.Err()
 		}
+		if r.stopAt > 0 && stepNum >= r.stopAt {
+			return false, nil
+		}
 
 		step := steps[stepNum-1]
 
 		// Update step context for queue UI display.
 		r.stepContext.Set(stepNum, totalSteps, step.name)
 
-		// Determine if this step should have no-commit suffix
-		var prompt string
-		if strings.Contains(step.name, "Commit") {
-			prompt = BuildPrompt(step.prompt)
-		} else {
-			prompt = BuildPrompt(step.prompt, WithNoCommit())
+		// Skip commit steps when there is nothing to commit, e.g. when a prior
+		// interrupted run already committed this work.
+		if step.commit && r.treeClean(ctx) {
+			if stepNum == startStep {
+				finishDescribe()
+			}
+			fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Skipped step %d/%d: %s (nothing to commit)", stepNum, totalSteps, step.name)))
+			r.events.emit(Event{Type: EventStepSkipped, TaskID: taskID, Step: stepNum, TotalSteps: totalSteps, StepName: step.name,
+				Reason: "nothing to commit"})
+			workflowState.MarkStepComplete()
+			if err := r.stateManager.Save(workflowState); err != nil {
+				return false, fmt.Errorf("failed to save state after step %d: %w", stepNum, err)
+			}
+			continue
+		}
+
+		// Gate commit steps on user confirmation. One answer covers both the
+		// code commit and its paired memory commit.
+		if step.commit && r.confirmsCommits() {
+			if stepNum == startStep {
+				finishDescribe()
+			}
+			if commitDecision == "" {
+				commitDecision = r.confirm(ctx, "Commit now?")
+				if ctx.Err() != nil {
+					return false, ctx.Err()
+				}
+			}
+			if commitDecision == CommitAbort {
+				fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf(
+					"Stopped before step %d/%d: %s; review the changes, then run snap resume to continue", stepNum, totalSteps, step.name)))
+				return false, ErrCommitAborted
+			}
+			if commitDecision == CommitSkip {
+				fmt.Fprint(r.output, ui.Info(fmt.Sprintf("Skipped step %d/%d: %s", stepNum, totalSteps, step.name)))
+				r.events.emit(Event{Type: EventStepSkipped, TaskID: taskID, Step: stepNum, TotalSteps: totalSteps, StepName: step.name,
+					Reason: "commit declined"})
+				workflowState.MarkStepComplete()
+				if err := r.stateManager.Save(workflowState); err != nil {
+					return false, fmt.Errorf("failed to save state after step %d: %w", stepNum, err)
+				}
+				continue
+			}
 		}
 
 		// Build full args with prompt
-		fullArgs := make([]string, 0, len(step.args)+1)
-		fullArgs = append(fullArgs, step.args...)
-		fullArgs = append(fullArgs, prompt)
+		var fullArgs []string
+		if step.continues {
+			fullArgs = append(fullArgs, continueFlag)
+		}
+		fullArgs = append(fullArgs, step.fullPrompt(r.config.SignCommits, r.config.GlobalPreamble))
 
-		// Execute step with numbering
-		if err := r.stepRunner.RunStepNumbered(ctx, stepNum, totalSteps, step.name, step.model, fullArgs...); err != nil {
-			return false, err
+		// Execute step with numbering. The first step of the iteration writes
+		// through the header gate so its output follows the header. Lint/test
+		// output is also captured when it gates the iteration, and the tail of
+		// every step's output is kept for failure details.
+		var terminal io.Writer = r.output
+		if stepNum == startStep {
+			terminal = header
+		}
+		tail := &tailBuffer{max: failureOutputMax}
+		stepOut := terminal
+		stepOut = io.MultiWriter(stepOut, tail)
+		var captured bytes.Buffer
+		gated := step.checks && r.config.FailFastOnLint
+		if gated {
+			stepOut = io.MultiWriter(stepOut, &captured)
+		}
+		if r.config.Explain {
+			fmt.Fprint(terminal, ui.Info(fmt.Sprintf("Step %d/%d %s: %s", stepNum, totalSteps, step.name, step.purpose)))
+		}
+		headBefore := r.commitHead(ctx, step)
+		stepStart := time.Now()
+		stepEvent := func(eventType string, duration time.Duration) Event {
+			return Event{Type: eventType, TaskID: taskID, Step: stepNum, TotalSteps: totalSteps, StepName: step.name,
+				DurationMS: duration.Milliseconds()}
+		}
+		emitStep := func(eventType string, duration time.Duration) {
+			r.events.emit(stepEvent(eventType, duration))
+		}
+		snapLabel := snapshot.Label{TaskID: taskLabel, Step: stepNum, Total: totalSteps, Name: step.name}
+		stepFailed := func(err error) error {
+			// An interrupted run stops without snapshotting; its git
+			// commands would fail on the cancelled context anyway.
+			if r.config.SnapshotMode == SnapshotOnFailure && ctx.Err() == nil && r.captureSnapshot(ctx, snapLabel) {
+				emitStep(EventSnapshotSaved, 0)
+			}
+			stepErr := r.stepError(stepNum, step, tail, err)
+			e := stepEvent(EventStepFailed, time.Since(stepStart))
+			e.Error, e.Failure = err.Error(), &stepErr.Failure
+			r.events.emit(e)
+			return stepErr
+		}
+		emitStep(EventStepStarted, 0)
+		err := r.runStep(ctx, r.newStepRunner(stepOut, terminal), stepNum, totalSteps, step, fullArgs, func() {
+			tail.buf = tail.buf[:0]
+			captured.Reset()
+		})
+		if stepNum == startStep {
+			finishDescribe()
+		}
+		if err != nil {
+			return false, stepFailed(err)
+		}
+
+		// The provider was asked to sign; check it did before moving on. The
+		// step stays current, so resuming after a fix skips it on a clean tree.
+		if step.mayCommit() && r.config.SignCommits {
+			if err := r.checkSigned(ctx, workflowState, headBefore); err != nil {
+				fmt.Fprintln(r.output, ui.Error(fmt.Sprintf("Step %d/%d created an unsigned commit", stepNum, totalSteps)))
+				return false, stepFailed(fmt.Errorf("step %d/%d %q: %w", stepNum, totalSteps, step.name, err))
+			}
+		}
+
+		// A commit step should leave nothing behind; stray changes would
+		// otherwise ride along into the next task.
+		if step.commit {
+			if stray := r.strayFiles(ctx); len(stray) > 0 {
+				fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  step %d/%d left %d uncommitted file(s): %s", stepNum, totalSteps, len(stray), formatStrayFiles(stray))))
+				if r.config.StrictCommits {
+					fmt.Fprintln(r.output, ui.Error(fmt.Sprintf("Step %d/%d left uncommitted changes", stepNum, totalSteps)))
+					return false, stepFailed(fmt.Errorf("step %d/%d %q: %w", stepNum, totalSteps, step.name, ErrDirtyTree))
+				}
+			}
+		}
+
+		// Hard gate: don't carry failing checks into review and commit. The
+		// step stays current, so resuming re-runs it.
+		if gated {
+			passed, reported := parseChecksResult(captured.String())
+			switch {
+			case !reported:
+				checksUnverified = true
+				fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  step %d/%d reported no SNAP-CHECKS result; continuing", stepNum, totalSteps)))
+			case !passed:
+				fmt.Fprintln(r.output, ui.Error(fmt.Sprintf("Step %d/%d reported failing checks; stopping before commit", stepNum, totalSteps)))
+				return false, stepFailed(fmt.Errorf("step %d/%d %q: %w", stepNum, totalSteps, step.name, ErrChecksFailed))
+			}
+		}
+
+		// Preview what the implement step touched before review starts.
+		if step.implements && r.config.ShowDiff && r.config.IsTTY {
+			r.printDiffStat(ctx)
 		}
 
 		// Capture a snapshot of the working tree after this step (if snapshotter is enabled).
 		// Skip snapshots for commit steps (tree is clean after commit, no-op operation).
-		if r.snapshotter != nil && !strings.Contains(step.name, "Commit") {
-			snapMsg := fmt.Sprintf("snap: %s step %d/%d — %s", taskLabel, stepNum, totalSteps, step.name)
-			if created, snapErr := r.snapshotter.Capture(ctx, snapMsg); snapErr != nil {
-				fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  snapshot skipped: %v", snapErr)))
-			} else if created {
-				fmt.Fprint(r.output, ui.Info("  snapshot saved"))
-			}
+		if r.snapshotEveryStep() && !step.commit && r.captureSnapshot(ctx, snapLabel) {
+			emitStep(EventSnapshotSaved, 0)
+		}
+
+		// Drain queued user prompts between steps, recording the ones that
+		// ran so the task keeps a record of them.
+		drained := DrainQueue(ctx, r.output, r.stepRunner, r.promptQueue,
+			WithDrainInterval(r.config.QueueDrainInterval))
+		LogDrainSummary(r.output, drained)
+		if len(drained) > 0 {
+			e := stepEvent(EventDirectivesDrained, 0)
+			e.Directives = drainedDirectives(drained)
+			r.events.emit(e)
 		}
+		workflowState.TaskDirectives = append(workflowState.TaskDirectives, appliedDirectives(drained)...)
 
-		// Drain queued user prompts between steps.
-		if errs := DrainQueue(ctx, r.output, r.stepRunner, r.promptQueue); len(errs) > 0 {
-			fmt.Fprint(os.Stderr, ui.DimError(fmt.Sprintf("%d queued prompt(s) failed", len(errs)))+"\n")
+		// /skip ends the task here: the remaining steps, commits included,
+		// don't run, the tree goes back to where the task started (its
+		// changes kept as a snapshot), and the task is recorded as completed
+		// in one save.
+		if skipRequested(drained) {
+			emitStep(EventStepCompleted, time.Since(stepStart))
+			fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  skipping %s by user request", taskLabel)))
+			if stray := r.strayFiles(ctx); len(stray) > 0 {
+				fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  %s left %d uncommitted file(s): %s", taskLabel, len(stray), formatStrayFiles(stray))))
+			}
+			if err := r.discardSkippedTask(ctx, workflowState, snapLabel); err != nil {
+				return false, fmt.Errorf("skip %s: %w", taskID, err)
+			}
+			r.taskSkipped = true
+			if err := r.completeTask(workflowState, true); err != nil {
+				return false, err
+			}
+			return true, nil
 		}
 
 		// Mark step complete and save state
@@ -512,11 +1525,34 @@ func (r *Runner) runIteration(ctx context.Context, workflowState *state.State) (
 		if err := r.stateManager.Save(workflowState); err != nil {
 			return false, fmt.Errorf("failed to save state after step %d: %w", stepNum, err)
 		}
+		emitStep(EventStepCompleted, time.Since(stepStart))
 	}
 
 	// Task complete - mark as completed and reset to idle.
 	fmt.Fprint(r.output, ui.CompleteWithDuration("Iteration complete", time.Since(taskStart)))
+	r.events.emit(Event{Type: EventIterationComplete, TaskID: taskID, TotalSteps: totalSteps,
+		DurationMS: time.Since(taskStart).Milliseconds(), Applied: workflowState.TaskDirectives})
+	if summary := DirectivesSummary(workflowState.TaskDirectives); summary != "" {
+		fmt.Fprint(r.output, ui.Info(summary))
+	}
+	r.printChangeSummary(ctx, workflowState, !checksUnverified)
 
+	if err := r.completeTask(workflowState, false); err != nil {
+		return false, err
+	}
+	r.pruneSnapshots(ctx)
+
+	// The task is recorded as done first: a fatal hook failure stops the
+	// run, and the next run starts the next task.
+	if err := r.runPostIterationHook(ctx, taskID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// completeTask records the current task as completed (skipped by the user,
+// or with all its steps run), resets the state to idle and saves it.
+func (r *Runner) completeTask(workflowState *state.State, skipped bool) error {
 	if id := workflowState.CurrentTaskID; id != "" {
 		alreadyCompleted := false
 		for _, cid := range workflowState.CompletedTaskIDs {
@@ -528,26 +1564,356 @@ func (r *Runner) runIteration(ctx context.Context, workflowState *state.State) (
 		if !alreadyCompleted {
 			workflowState.CompletedTaskIDs = append(workflowState.CompletedTaskIDs, id)
 		}
+		// The implement step's model is recorded as having produced the
+		// task's code.
+		rec := state.TaskRecord{
+			CompletedAt: time.Now(),
+			Provider:    r.config.ProviderName,
+			Model:       r.modelName(r.implementModel()),
+			Directives:  workflowState.TaskDirectives,
+			Skipped:     skipped,
+		}
+		workflowState.RecordCompletion(id, rec)
+		if r.config.PRPerTask && !skipped {
+			workflowState.PublishBranch = taskBranch(id)
+		}
+		if hr, ok := r.stateManager.(HistoryRecorder); ok {
+			if err := hr.AppendHistory(id, rec); err != nil {
+				fmt.Fprint(r.output, ui.Interrupted(fmt.Sprintf("Warning: failed to record task history: %v", err)))
+			}
+		}
 	}
 	workflowState.CurrentTaskID = ""
 	workflowState.CurrentTaskFile = ""
 	workflowState.CurrentStep = 1
+	workflowState.TaskStartCommit = ""
+	workflowState.TaskDescription = ""
+	workflowState.TaskDescriptionHash = ""
+	workflowState.TaskDirectives = nil
 	workflowState.LastError = ""
 	workflowState.SessionID = ""
 	workflowState.LastUpdated = time.Now()
 
 	if err := r.stateManager.Save(workflowState); err != nil {
-		return false, fmt.Errorf("failed to save state after completion: %w", err)
+		return fmt.Errorf("failed to save state after completion: %w", err)
 	}
+	return nil
+}
 
-	return true, nil
+// pruneSnapshots drops snap snapshots beyond Config.SnapshotRetention.
+// Failures are shown and otherwise ignored, like snapshot capture.
+func (r *Runner) pruneSnapshots(ctx context.Context) {
+	if r.snapshotter == nil || r.config.SnapshotRetention <= 0 {
+		return
+	}
+	pruned, err := r.snapshotter.Prune(ctx, r.config.SnapshotRetention)
+	if err != nil {
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("snapshot pruning skipped: %v", err)))
+		return
+	}
+	if pruned > 0 {
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("pruned %d old snapshot(s), keeping the newest %d", pruned, r.config.SnapshotRetention)))
+	}
+}
+
+// printDiffStat prints the working tree diff summary. Failures are shown and
+// otherwise ignored; the preview is informational only.
+func (r *Runner) printDiffStat(ctx context.Context) {
+	git := r.snapshotter
+	if git == nil {
+		git = snapshot.New(".")
+	}
+	stat, err := git.DiffStat(ctx)
+	switch {
+	case err != nil:
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  diff preview skipped: %v", err)))
+	case stat == "":
+		fmt.Fprint(r.output, ui.Info("  no changes to tracked files"))
+	default:
+		fmt.Fprint(r.output, ui.DiffStat(stat))
+	}
+}
+
+// gitTree returns the git working tree to inspect, or nil when none is
+// configured.
+func (r *Runner) gitTree() *snapshot.Snapshotter {
+	if r.worktree != nil {
+		return r.worktree
+	}
+	return r.snapshotter
+}
+
+// discardSkippedTask saves the uncommitted changes of a task skipped with
+// /skip as a snapshot and resets the tree to the commit the task started
+// from, so none of its work rides along into the next task's commits.
+// Commits the task already made are kept on a snap/skipped/ branch first,
+// since the reset moves the checked-out branch back past them. Task files
+// are left in place. Outside a git repository there is nothing to reset.
+func (r *Runner) discardSkippedTask(ctx context.Context, workflowState *state.State, label snapshot.Label) error {
+	tree := r.gitTree()
+	if tree == nil {
+		return nil
+	}
+	start := workflowState.TaskStartCommit
+	if start == "" {
+		return errors.New("no start commit recorded to reset to; discard the changes yourself, then run snap resume")
+	}
+	created, err := tree.Capture(ctx, label.String())
+	if err != nil {
+		return fmt.Errorf("save changes: %w", err)
+	}
+	if created {
+		fmt.Fprint(r.output, ui.Info("  uncommitted changes saved as a snapshot; see: snap snapshot list"))
+	}
+	head, err := tree.Head(ctx)
+	if err != nil {
+		return err
+	}
+	if head != start {
+		branch := fmt.Sprintf("snap/skipped/%s-%d", strings.TrimPrefix(taskBranch(workflowState.CurrentTaskID), "snap/"), time.Now().Unix())
+		if err := tree.CreateBranch(ctx, branch, head); err != nil {
+			return fmt.Errorf("save commits: %w", err)
+		}
+		fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  commits made since %s saved on branch %s", shortSHA(start), branch)))
+	}
+	keep := []string{r.config.TasksDir, r.config.PRDPath, r.config.TaskFilePath}
+	if err := tree.ResetTo(ctx, start, slices.DeleteFunc(keep, func(p string) bool { return p == "" })...); err != nil {
+		return err
+	}
+	fmt.Fprint(r.output, ui.Info(fmt.Sprintf("  reset to %s, where %s started", shortSHA(start), workflowState.CurrentTaskID)))
+	return nil
+}
+
+// recordTaskStart stores the commit the task starts from, so its change
+// summary can be measured at the end. Outside a git repository nothing is
+// recorded and the summary is skipped.
+func (r *Runner) recordTaskStart(ctx context.Context, workflowState *state.State) {
+	tree := r.gitTree()
+	if tree == nil {
+		return
+	}
+	if head, err := tree.Head(ctx); err == nil {
+		workflowState.TaskStartCommit = head
+	}
+}
+
+// diffBase returns the commit the review and docs steps diff against:
+// Config.BaseSHA, else the task's start commit, else empty for HEAD.
+func (r *Runner) diffBase(workflowState *state.State) string {
+	if r.config.BaseSHA != "" {
+		return r.config.BaseSHA
+	}
+	return workflowState.TaskStartCommit
+}
+
+// printChangeSummary prints the boxed files/lines summary for the task that
+// just completed, measured from its start commit. It prints nothing when
+// the start commit is unknown or git fails.
+func (r *Runner) printChangeSummary(ctx context.Context, workflowState *state.State, testsPassing bool) {
+	tree := r.gitTree()
+	if tree == nil || workflowState.TaskStartCommit == "" {
+		return
+	}
+	stat, err := tree.ChangesSince(ctx, workflowState.TaskStartCommit)
+	if err != nil {
+		return
+	}
+	taskName := workflowState.CurrentTaskID
+	if taskName == "" {
+		taskName = "Task"
+	}
+	fmt.Fprint(r.output, ui.CompleteBoxed(taskName, stat.Files, stat.Insertions, testsPassing))
+}
+
+// treeClean reports whether the working tree is known to be clean. Without a
+// git tree to inspect, or when inspection fails, it reports false so the
+// commit step runs as usual.
+func (r *Runner) treeClean(ctx context.Context) bool {
+	tree := r.gitTree()
+	if tree == nil {
+		return false
+	}
+	clean, err := tree.Clean(ctx)
+	return err == nil && clean
+}
+
+// ErrDirtyTree is returned when a commit step runs with
+// Config.StrictCommits and leaves uncommitted changes.
+var ErrDirtyTree = errors.New("commit step left uncommitted changes")
+
+// maxStrayFiles is how many uncommitted paths are listed after a commit step.
+const maxStrayFiles = 5
+
+// strayFiles returns the paths a commit step left uncommitted. It returns
+// nil without a git tree to inspect or when inspection fails.
+func (r *Runner) strayFiles(ctx context.Context) []string {
+	tree := r.gitTree()
+	if tree == nil {
+		return nil
+	}
+	paths, err := tree.Dirty(ctx)
+	if err != nil {
+		return nil
+	}
+	return paths
+}
+
+// formatStrayFiles joins the first maxStrayFiles paths, noting how many
+// more there are.
+func formatStrayFiles(paths []string) string {
+	if len(paths) <= maxStrayFiles {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:maxStrayFiles], ", "), len(paths)-maxStrayFiles)
+}
+
+// commitHead returns HEAD before a step that may commit runs with
+// Config.SignCommits, so checkSigned can tell whether the step committed. It
+// is "" otherwise.
+func (r *Runner) commitHead(ctx context.Context, step workflowStep) string {
+	tree := r.gitTree()
+	if !step.mayCommit() || !r.config.SignCommits || tree == nil {
+		return ""
+	}
+	head, err := tree.Head(ctx)
+	if err != nil {
+		return ""
+	}
+	return head
+}
+
+// ErrUnsignedCommit is returned when a step that may commit runs with
+// Config.SignCommits and the task's commits include an unsigned one.
+var ErrUnsignedCommit = errors.New("commit is not signed")
+
+// checkSigned returns ErrUnsignedCommit when a step moved HEAD from before and
+// a commit since the task started (TaskStartCommit, else before) is unsigned,
+// so a commit made earlier in the task is caught too. Nothing is checked when
+// HEAD can't be read.
+func (r *Runner) checkSigned(ctx context.Context, workflowState *state.State, before string) error {
+	tree := r.gitTree()
+	if tree == nil {
+		return nil
+	}
+	head, headErr := tree.Head(ctx)
+	if headErr != nil || head == before {
+		return nil
+	}
+	base := workflowState.TaskStartCommit
+	if base == "" {
+		base = before
+	}
+	unsigned, err := postrun.UnsignedCommits(ctx, tree.Git(), base, head)
+	if err != nil {
+		return fmt.Errorf("check commit signatures: %w", err)
+	}
+	switch {
+	case len(unsigned) == 0:
+		return nil
+	case len(unsigned) == 1 && unsigned[0] == head:
+		return fmt.Errorf("%w: %s (sign it with: git commit --amend -S --no-edit)", ErrUnsignedCommit, shortSHA(head))
+	}
+	shas := make([]string, len(unsigned))
+	for i, sha := range unsigned {
+		shas[i] = shortSHA(sha)
+	}
+	return fmt.Errorf("%w: %s (sign them with: git rebase --exec 'git commit --amend -S --no-edit' %s)",
+		ErrUnsignedCommit, strings.Join(shas, ", "), shortSHA(base))
+}
+
+// shortSHA abbreviates a commit hash for messages.
+func shortSHA(sha string) string {
+	return sha[:min(len(sha), 7)]
+}
+
+// modelName returns the concrete model name for mt when the executor can
+// report it, and the abstract type otherwise.
+func (r *Runner) modelName(mt model.Type) string {
+	if namer, ok := r.executor.(ModelNamer); ok {
+		if name := namer.ModelName(mt); name != "" {
+			return name
+		}
+	}
+	return string(mt)
+}
+
+// implementModel returns the model of the step that implements the task,
+// after overrides; the thinking model when the step list has none.
+func (r *Runner) implementModel() model.Type {
+	for _, d := range r.steps {
+		if d.Prompt == PromptImplement {
+			return d.Model
+		}
+	}
+	return model.Thinking
+}
+
+// stepError wraps a step failure with the details --show-state reports.
+func (r *Runner) stepError(stepNum int, step workflowStep, tail *tailBuffer, err error) *StepError {
+	return &StepError{
+		Failure: state.Failure{
+			Step:     stepNum,
+			StepName: step.name,
+			Model:    r.modelName(step.model),
+			Output:   tail.String(),
+		},
+		Err: err,
+	}
+}
+
+// confirmsCommits reports whether commit steps wait for user confirmation.
+// Without a TTY there is nobody to ask, so commits proceed.
+func (r *Runner) confirmsCommits() bool {
+	return r.config.ConfirmCommits && r.config.IsTTY && r.confirm != nil
+}
+
+// continueToNextTask asks whether to start the next task when pausing
+// between tasks is enabled on a TTY. It does not ask when no task remains,
+// so post-run starts right away.
+func (r *Runner) continueToNextTask(ctx context.Context, workflowState *state.State) bool {
+	if !r.config.PauseBetweenTasks || !r.config.IsTTY || r.pause == nil {
+		return true
+	}
+	if !r.tasksRemain(workflowState) {
+		return true
+	}
+	return r.pause(ctx, "Continue to next task?")
+}
+
+// tasksRemain reports whether a task file is left to implement. A failed
+// scan reports false; selectIdleTask surfaces the error.
+func (r *Runner) tasksRemain(workflowState *state.State) bool {
+	tasks, err := r.discoverTasks()
+	return err == nil && SelectNextTask(tasks, workflowState.CompletedTaskIDs) != nil
+}
+
+// changelogPath returns the changelog the update-docs step maintains, or ""
+// when changelog updates are off.
+func (r *Runner) changelogPath() string {
+	if !r.config.UpdateChangelog {
+		return ""
+	}
+	if r.config.ChangelogPath == "" {
+		return DefaultChangelogPath
+	}
+	return r.config.ChangelogPath
 }
 
 func (r *Runner) discoverTasks() ([]TaskInfo, error) {
-	if r.config.TaskFilePath != "" {
-		return ScanSingleTask(r.config.TaskFilePath)
+	return discoverTasks(r.config.TasksDir, r.config.TaskFilePath, r.config.TasksGlob)
+}
+
+// testsOutlinePath returns the acceptance test outline planned next to a
+// task file (TASK3.md → TASK3.tests.md), or "" when there is none.
+func testsOutlinePath(taskPath string) string {
+	if taskPath == "" {
+		return ""
+	}
+	path := strings.TrimSuffix(taskPath, ".md") + ".tests.md"
+	if _, err := os.Stat(path); err != nil {
+		return ""
 	}
-	return ScanTasks(r.config.TasksDir)
+	return path
 }
 
 func (r *Runner) activeTaskPath(currentTaskFile string) string {
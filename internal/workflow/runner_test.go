@@ -3,18 +3,26 @@ package workflow_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/yarlson/snap/internal/input"
 	"github.com/yarlson/snap/internal/model"
 	"github.com/yarlson/snap/internal/snapshot"
 	"github.com/yarlson/snap/internal/state"
@@ -45,9 +53,8 @@ func TestRunner_StateManagement(t *testing.T) {
 		mockExec := &MockExecutor{
 			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
 				stepCount++
-				// Fail after step 3 to simulate interruption.
-				// stepCount 1 = description generation (pre-step), 2-4 = steps 1-3.
-				if stepCount == 4 {
+				// Fail step 3 to simulate interruption.
+				if stepCount == 3 {
 					return errors.New("simulated failure")
 				}
 				return nil
@@ -80,7 +87,7 @@ func TestRunner_StateManagement(t *testing.T) {
 	t.Run("state reset with fresh flag", func(t *testing.T) {
 		// Ensure state exists
 		stateManager := state.NewManagerWithDir(tmpDir)
-		workflowState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		workflowState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		workflowState.CurrentStep = 5
 		err := stateManager.Save(workflowState)
 		assert.NoError(t, err)
@@ -178,8 +185,8 @@ func TestRunner_ImplementStepPromptIncludesOptionalContextDocs(t *testing.T) {
 	mockExec := &MockExecutor{
 		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
 			callCount++
-			// Call 1 = description generation (pre-step); call 2 = step 1 (implement).
-			if callCount == 2 && len(args) > 0 {
+			// Description pre-step is disabled, so call 1 = step 1 (implement).
+			if callCount == 1 && len(args) > 0 {
 				implementPrompt = args[len(args)-1]
 			}
 			return errors.New("stop after first step")
@@ -216,8 +223,8 @@ func TestRunner_IdleTaskSelection(t *testing.T) {
 		mockExec := &MockExecutor{
 			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
 				callCount++
-				// Call 1 = description generation; call 2 = step 1 (implement).
-				if callCount == 2 && len(args) > 0 {
+				// Description pre-step is disabled, so call 1 = step 1 (implement).
+				if callCount == 1 && len(args) > 0 {
 					implementPrompt = args[len(args)-1]
 				}
 				return errors.New("stop")
@@ -257,7 +264,7 @@ func TestRunner_IdleTaskSelection(t *testing.T) {
 		stateManager := state.NewManagerWithDir(tmpDir)
 		//nolint:errcheck // cleanup
 		_ = stateManager.Reset()
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CompletedTaskIDs = []string{"TASK1"}
 		assert.NoError(t, stateManager.Save(seedState))
 
@@ -266,8 +273,8 @@ func TestRunner_IdleTaskSelection(t *testing.T) {
 		mockExec := &MockExecutor{
 			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
 				callCount++
-				// Call 1 = description generation; call 2 = step 1 (implement).
-				if callCount == 2 && len(args) > 0 {
+				// Description pre-step is disabled, so call 1 = step 1 (implement).
+				if callCount == 1 && len(args) > 0 {
 					implementPrompt = args[len(args)-1]
 				}
 				return errors.New("stop")
@@ -325,7 +332,7 @@ func TestRunner_IdleTaskSelection(t *testing.T) {
 		stateManager := state.NewManagerWithDir(tmpDir)
 		//nolint:errcheck // cleanup
 		_ = stateManager.Reset()
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CompletedTaskIDs = []string{"TASK1"}
 		assert.NoError(t, stateManager.Save(seedState))
 
@@ -363,8 +370,8 @@ func TestRunner_IdleTaskSelection(t *testing.T) {
 			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
 				stepCount++
 				// Let first iteration's steps succeed, then fail on second iteration.
-				// Each iteration has 1 description call + 10 workflow steps = 11 calls.
-				if stepCount > 11 {
+				// Each iteration has 10 workflow steps.
+				if stepCount > 10 {
 					return errors.New("stop after first iteration")
 				}
 				return nil
@@ -400,7 +407,7 @@ func TestRunner_ResumeExactTaskAndStep(t *testing.T) {
 
 		// Pre-seed state: TASK2 active at step 5.
 		stateManager := state.NewManagerWithDir(tmpDir)
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CurrentTaskID = "TASK2"
 		seedState.CurrentTaskFile = "TASK2.md"
 		seedState.CurrentStep = 5
@@ -447,7 +454,7 @@ func TestRunner_ResumeExactTaskAndStep(t *testing.T) {
 
 		// Pre-seed state: TASK1 active at step 3, no tasks completed.
 		stateManager := state.NewManagerWithDir(tmpDir)
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CurrentTaskID = "TASK1"
 		seedState.CurrentTaskFile = "TASK1.md"
 		seedState.CurrentStep = 3
@@ -485,7 +492,7 @@ func TestRunner_ResumeExactTaskAndStep(t *testing.T) {
 
 		// Pre-seed state: TASK1 active at step 4.
 		stateManager := state.NewManagerWithDir(tmpDir)
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CurrentTaskID = "TASK1"
 		seedState.CurrentTaskFile = "TASK1.md"
 		seedState.CurrentStep = 4
@@ -512,51 +519,8 @@ func TestRunner_ResumeExactTaskAndStep(t *testing.T) {
 	})
 }
 
-func TestRunner_ResumeFailsOnInvalidState(t *testing.T) {
-	t.Run("fails when active task file is missing", func(t *testing.T) {
-		tmpDir := t.TempDir()
-
-		prdPath := filepath.Join(tmpDir, "PRD.md")
-		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
-		// Only TASK2 exists; TASK1 is missing.
-		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
-
-		// Pre-seed state: TASK1 active (but file doesn't exist).
-		stateManager := state.NewManagerWithDir(tmpDir)
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
-		seedState.CurrentTaskID = "TASK1"
-		seedState.CurrentTaskFile = "TASK1.md"
-		seedState.CurrentStep = 3
-		require.NoError(t, stateManager.Save(seedState))
-
-		executorCalled := false
-		mockExec := &MockExecutor{
-			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
-				executorCalled = true
-				return nil
-			},
-		}
-
-		runner := workflow.NewRunner(mockExec, workflow.Config{
-			TasksDir: tmpDir,
-			PRDPath:  prdPath,
-		}, workflow.WithStateManager(stateManager))
-
-		err := runner.Run(context.Background())
-
-		// Should fail with a clear error, not silently pick the next task.
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "TASK1")
-		assert.Contains(t, err.Error(), "not found")
-		assert.Contains(t, err.Error(), "--fresh")
-		// Executor should never be called for invalid resume state.
-		assert.False(t, executorCalled, "executor should not run when resume state is invalid")
-	})
-}
-
-func TestRunner_IterationCompleteIncludesDuration(t *testing.T) {
+func TestRunner_InterruptWhileComposing(t *testing.T) {
 	tmpDir := t.TempDir()
-
 	prdPath := filepath.Join(tmpDir, "PRD.md")
 	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
 	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
@@ -565,182 +529,206 @@ func TestRunner_IterationCompleteIncludesDuration(t *testing.T) {
 	//nolint:errcheck // cleanup
 	_ = stateManager.Reset()
 
-	var buf bytes.Buffer
+	pr, pw, err := os.Pipe()
+	require.NoError(t, err)
+	defer pr.Close()
+
+	var terminal bytes.Buffer
+	sw := ui.NewSwitchWriter(&terminal)
+
+	var stdinReader *input.Reader
 	mockExec := &MockExecutor{
-		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
-			return nil
+		runFunc: func(ctx context.Context, w io.Writer, _ model.Type, _ ...string) error {
+			// The user starts typing a directive, which pauses output; the
+			// provider keeps writing, then the process is interrupted.
+			_, err := pw.WriteString("half a direc")
+			require.NoError(t, err)
+			require.Eventually(t, sw.IsPaused, time.Second, 5*time.Millisecond)
+			_, err = io.WriteString(w, "provider output while composing\n")
+			require.NoError(t, err)
+			require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+			<-ctx.Done()
+			return ctx.Err()
 		},
 	}
 
 	runner := workflow.NewRunner(mockExec, workflow.Config{
 		TasksDir: tmpDir,
 		PRDPath:  prdPath,
-	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
-
-	err := runner.Run(context.Background())
-	assert.NoError(t, err)
-
-	output := buf.String()
-	// The iteration complete line should contain timing
-	assert.Contains(t, output, "Iteration complete")
-	// Should contain a duration marker (at minimum "0s")
-	stripped := ui.StripColors(output)
-	assert.Regexp(t, `Iteration complete\s+\d+`, stripped)
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(sw),
+		workflow.WithInterruptHook(func() { stdinReader.Stop() }))
+
+	stdinReader = input.NewReader(pr, runner.Queue(),
+		input.WithTerminal(pr),
+		input.WithOutput(sw),
+		input.WithStepInfo(runner.StepContext()),
+		input.WithMode(input.NewMode(sw)),
+	)
+	stdinReader.Start()
+
+	err = runner.Run(context.Background())
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The reader goroutine exits on its next read, and nothing was queued.
+	require.NoError(t, pw.Close())
+	assert.Eventually(t, stdinReader.Done, time.Second, 10*time.Millisecond)
+	assert.Zero(t, runner.Queue().Len())
+
+	assert.False(t, sw.IsPaused(), "output resumes when the reader stops")
+	output := ui.StripColors(terminal.String())
+	held := strings.Index(output, "provider output while composing")
+	stopped := strings.Index(output, "Stopped by user")
+	require.NotEqual(t, -1, held, "output held while composing is flushed")
+	require.NotEqual(t, -1, stopped, "the interrupted message is shown")
+	assert.Less(t, held, stopped, "held output comes before the interrupted message")
+	assert.Contains(t, output[stopped:], "State saved at step 1/10")
 }
 
-func TestRunner_StepCount(t *testing.T) {
-	assert.Equal(t, 10, workflow.StepCount())
-}
+func TestRunner_UncleanShutdown(t *testing.T) {
+	exited := exec.Command("true")
+	require.NoError(t, exited.Run())
+	deadPID := exited.Process.Pid
 
-func TestRunner_EmbeddedPrompts(t *testing.T) {
 	tmpDir := t.TempDir()
-
 	prdPath := filepath.Join(tmpDir, "PRD.md")
 	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
 	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
 
+	// A run that died during step 4 without recording an error.
 	stateManager := state.NewManagerWithDir(tmpDir)
-	//nolint:errcheck // cleanup
-	_ = stateManager.Reset()
-
-	var capturedPrompts []string
+	seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
+	seedState.CurrentTaskID = "TASK1"
+	seedState.CurrentTaskFile = "TASK1.md"
+	seedState.CurrentStep = 4
+	seedState.PID = deadPID
+	require.NoError(t, stateManager.Save(seedState))
+
+	var runningPID int
+	calls := 0
 	mockExec := &MockExecutor{
-		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
-			if len(args) > 0 {
-				capturedPrompts = append(capturedPrompts, args[len(args)-1])
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			calls++
+			if calls == 1 {
+				return nil
 			}
-			return nil
+			saved, err := stateManager.Load()
+			require.NoError(t, err)
+			runningPID = saved.PID
+			return errors.New("stop")
 		},
 	}
 
+	var buf bytes.Buffer
 	runner := workflow.NewRunner(mockExec, workflow.Config{
 		TasksDir: tmpDir,
 		PRDPath:  prdPath,
-	}, workflow.WithStateManager(stateManager))
-
-	err := runner.Run(context.Background())
-	assert.NoError(t, err)
-	require.Len(t, capturedPrompts, 11, "workflow should execute 1 description call + 10 steps")
-
-	// Prompt 0: Task description generation (pre-step)
-	assert.Contains(t, capturedPrompts[0], "Summarize")
-
-	// Step 1: Implement — contains PRD path, task reference, and quality guardrails
-	assert.Contains(t, capturedPrompts[1], prdPath)
-	assert.Contains(t, capturedPrompts[1], "TASK1")
-	assert.Contains(t, capturedPrompts[1], "context-map.md")
-	assert.Contains(t, capturedPrompts[1], "Quality Guardrails")
-	assert.Contains(t, capturedPrompts[1], "parameterized queries")
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+	require.Error(t, runner.Run(context.Background()))
 
-	// Step 2: Ensure completeness
-	assert.Contains(t, capturedPrompts[2], "fully implemented")
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, fmt.Sprintf("Previous run did not finish: process %d stopped during step 4/10 (Code review)", deadPID))
+	assert.Contains(t, output, "Re-running step 4 from the start")
+	assert.Equal(t, os.Getpid(), runningPID, "the running process is recorded in state")
 
-	// Step 3: Lint & test
-	assert.Contains(t, capturedPrompts[3], "AGENTS.md")
-	assert.Contains(t, capturedPrompts[3], "linters")
+	// The failed run recorded its error and released the state, so the next
+	// start reports the error instead of an unclean shutdown.
+	loaded, err := stateManager.Load()
+	require.NoError(t, err)
+	assert.Zero(t, loaded.PID)
+	assert.False(t, loaded.Abandoned())
+
+	buf.Reset()
+	calls = 0
+	require.Error(t, runner.Run(context.Background()))
+	assert.Contains(t, buf.String(), "Last error: ")
+	assert.NotContains(t, buf.String(), "Previous run did not finish")
+}
 
-	// Step 4: Code review — full embedded skill with context loading and task scope
-	assert.Contains(t, capturedPrompts[4], "CLAUDE.md")
-	assert.Contains(t, capturedPrompts[4], "git diff HEAD")
-	assert.Contains(t, capturedPrompts[4], "CRITICAL")
-	assert.Contains(t, capturedPrompts[4], "TASK1")
-	assert.NotContains(t, capturedPrompts[4], "Use the code-review skill")
+func TestRunner_ResumeStep(t *testing.T) {
+	t.Run("jumps to the requested step of the active task", func(t *testing.T) {
+		tmpDir := t.TempDir()
 
-	// Step 5: Apply fixes
-	assert.Contains(t, capturedPrompts[5], "Fix")
-	assert.Contains(t, capturedPrompts[5], "issues")
+		prdPath := filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
 
-	// Step 6: Verify fixes (same as step 3)
-	assert.Contains(t, capturedPrompts[6], "AGENTS.md")
+		stateManager := state.NewManagerWithDir(tmpDir)
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
+		seedState.CurrentTaskID = "TASK1"
+		seedState.CurrentTaskFile = "TASK1.md"
+		seedState.CurrentStep = 6
+		require.NoError(t, stateManager.Save(seedState))
 
-	// Step 7: Update docs — diff-based documentation update
-	assert.Contains(t, capturedPrompts[7], "git diff HEAD")
-	assert.Contains(t, capturedPrompts[7], "README.md")
-	assert.Contains(t, capturedPrompts[7], "user-facing")
+		var buf bytes.Buffer
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				return errors.New("stop")
+			},
+		}
 
-	// Step 8: Commit code
-	assert.Contains(t, capturedPrompts[8], "conventional commit")
-	assert.Contains(t, capturedPrompts[8], "co-author")
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir:   tmpDir,
+			PRDPath:    prdPath,
+			ResumeStep: 2,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
 
-	// Step 9: Context update — full embedded prompt, not delegation
-	assert.Contains(t, capturedPrompts[9], "docs/context/")
-	assert.Contains(t, capturedPrompts[9], "summary.md")
-	assert.NotContains(t, capturedPrompts[9], "Update the project context.")
+		err := runner.Run(context.Background())
+		assert.Error(t, err)
 
-	// Step 10: Commit memory (same as step 8)
-	assert.Contains(t, capturedPrompts[10], "conventional commit")
-}
+		loaded, err := stateManager.Load()
+		require.NoError(t, err)
+		assert.Equal(t, 2, loaded.CurrentStep)
+		assert.Contains(t, buf.String(), "resuming TASK1 from step 2")
+	})
 
-func TestRunner_CompletionDeduplication(t *testing.T) {
-	t.Run("completion appends task ID exactly once", func(t *testing.T) {
+	t.Run("refuses when there is no active task", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		prdPath := filepath.Join(tmpDir, "PRD.md")
 		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
 		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
-		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
-
-		// Pre-seed state: TASK1 already completed, TASK2 active at last step.
-		// After TASK2 completes, CompletedTaskIDs should have exactly [TASK1, TASK2].
-		stateManager := state.NewManagerWithDir(tmpDir)
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
-		seedState.CurrentTaskID = "TASK2"
-		seedState.CurrentTaskFile = "TASK2.md"
-		seedState.CurrentStep = 9 // Last step
-		seedState.CompletedTaskIDs = []string{"TASK1"}
-		require.NoError(t, stateManager.Save(seedState))
 
+		executorCalled := false
 		mockExec := &MockExecutor{
 			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				executorCalled = true
 				return nil
 			},
 		}
 
 		runner := workflow.NewRunner(mockExec, workflow.Config{
-			TasksDir: tmpDir,
-			PRDPath:  prdPath,
-		}, workflow.WithStateManager(stateManager))
+			TasksDir:   tmpDir,
+			PRDPath:    prdPath,
+			ResumeStep: 3,
+		}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)))
 
 		err := runner.Run(context.Background())
-		assert.NoError(t, err)
-
-		// TASK2 should appear exactly once in CompletedTaskIDs.
-		// (State is reset after all complete, so check the load returns nil or clean state.)
-		// Since all tasks are done, state file is cleaned up. Verify through the
-		// fact that the run completed without error — if a duplicate were appended,
-		// state validation would reject it.
+		require.ErrorIs(t, err, workflow.ErrNothingToResume)
+		assert.False(t, executorCalled)
 	})
+}
 
-	t.Run("completion does not duplicate already-completed task", func(t *testing.T) {
+func TestRunner_ResumeFailsOnInvalidState(t *testing.T) {
+	t.Run("fails when active task file is missing", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		prdPath := filepath.Join(tmpDir, "PRD.md")
 		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
-		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+		// Only TASK2 exists; TASK1 is missing.
 		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
-		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK3.md"), []byte("# Task 3"), 0o600))
 
-		// Pre-seed state: simulate edge case where TASK1 is both active and somehow
-		// already in the completed list (bypassing normal validation for test purposes).
-		// The completion logic should NOT duplicate it.
+		// Pre-seed state: TASK1 active (but file doesn't exist).
 		stateManager := state.NewManagerWithDir(tmpDir)
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CurrentTaskID = "TASK1"
 		seedState.CurrentTaskFile = "TASK1.md"
-		seedState.CurrentStep = 9
-		seedState.CompletedTaskIDs = []string{"TASK1"} // Already there (edge case)
-		// Note: this state is technically invalid per IsValid(), but we're testing
-		// defense-in-depth in the completion path itself.
+		seedState.CurrentStep = 3
+		require.NoError(t, stateManager.Save(seedState))
 
-		stepCount := 0
+		executorCalled := false
 		mockExec := &MockExecutor{
 			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
-				stepCount++
-				// 1 desc + 2 workflow steps (9,10) = 3 calls for first iteration.
-				// Fail early in second iteration to stop.
-				if stepCount > 10 {
-					return errors.New("stop")
-				}
+				executorCalled = true
 				return nil
 			},
 		}
@@ -750,26 +738,19 @@ func TestRunner_CompletionDeduplication(t *testing.T) {
 			PRDPath:  prdPath,
 		}, workflow.WithStateManager(stateManager))
 
-		// The run may fail at startup validation (active task in completed list).
-		// That's acceptable — the important thing is no duplicate gets saved.
-		//nolint:errcheck // testing dedup behavior, not error handling
-		_ = runner.Run(context.Background())
+		err := runner.Run(context.Background())
 
-		// If state was saved, verify no duplicate entries.
-		loaded, err := stateManager.Load()
-		if err == nil && loaded != nil {
-			seen := make(map[string]int)
-			for _, id := range loaded.CompletedTaskIDs {
-				seen[id]++
-			}
-			for id, count := range seen {
-				assert.Equal(t, 1, count, "task %s appears %d times in CompletedTaskIDs", id, count)
-			}
-		}
+		// Should fail with a clear error, not silently pick the next task.
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "TASK1")
+		assert.Contains(t, err.Error(), "not found")
+		assert.Contains(t, err.Error(), "--fresh")
+		// Executor should never be called for invalid resume state.
+		assert.False(t, executorCalled, "executor should not run when resume state is invalid")
 	})
 }
 
-func TestRunner_UpdateDocsStepConfig(t *testing.T) {
+func TestRunner_IterationCompleteIncludesDuration(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	prdPath := filepath.Join(tmpDir, "PRD.md")
@@ -780,18 +761,9 @@ func TestRunner_UpdateDocsStepConfig(t *testing.T) {
 	//nolint:errcheck // cleanup
 	_ = stateManager.Reset()
 
-	type stepCapture struct {
-		model  model.Type
-		prompt string
-	}
-	var captured []stepCapture
+	var buf bytes.Buffer
 	mockExec := &MockExecutor{
-		runFunc: func(_ context.Context, _ io.Writer, mt model.Type, args ...string) error {
-			prompt := ""
-			if len(args) > 0 {
-				prompt = args[len(args)-1]
-			}
-			captured = append(captured, stepCapture{model: mt, prompt: prompt})
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
 			return nil
 		},
 	}
@@ -799,175 +771,3404 @@ func TestRunner_UpdateDocsStepConfig(t *testing.T) {
 	runner := workflow.NewRunner(mockExec, workflow.Config{
 		TasksDir: tmpDir,
 		PRDPath:  prdPath,
-	}, workflow.WithStateManager(stateManager))
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
 
 	err := runner.Run(context.Background())
 	assert.NoError(t, err)
-	require.Len(t, captured, 11, "1 description call + 10 workflow steps")
 
-	// Step 7 (index 7, offset by 1 for description call): "Update docs" must use Fast model and include no-commit suffix.
-	assert.Equal(t, model.Fast, captured[7].model, "Update docs step should use Fast model")
-	assert.Contains(t, captured[7].prompt, "Do not stage, commit, amend, rebase, or push", "Update docs step should include no-commit suffix")
+	output := buf.String()
+	// The iteration complete line should contain timing
+	assert.Contains(t, output, "Iteration complete")
+	// Should contain a duration marker (at minimum "0s")
+	stripped := ui.StripColors(output)
+	assert.Regexp(t, `Iteration complete\s+\d+`, stripped)
+}
+
+// namedExecutor is a MockExecutor that reports concrete model names.
+type namedExecutor struct {
+	*MockExecutor
 }
 
-func TestRunner_DescriptionFailureIsGraceful(t *testing.T) {
+func (e *namedExecutor) ModelName(mt model.Type) string {
+	if mt == model.Thinking {
+		return "opus"
+	}
+	return "haiku"
+}
+
+func TestRunner_StartupSummaryShowsPinnedModels(t *testing.T) {
+	for _, pinned := range []bool{true, false} {
+		t.Run(fmt.Sprintf("pinned=%v", pinned), func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			executor := &namedExecutor{MockExecutor: &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+					return errors.New("stop")
+				},
+			}}
+
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(executor, workflow.Config{
+				TasksDir:     tmpDir,
+				PRDPath:      prdPath,
+				ProviderName: "claude",
+				PinnedModels: pinned,
+			}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(&buf))
+
+			//nolint:errcheck // stopped by the executor
+			_ = runner.Run(context.Background())
+
+			if pinned {
+				assert.Contains(t, buf.String(), "| claude | fast: haiku, thinking: opus | 1 task")
+			} else {
+				assert.NotContains(t, buf.String(), "fast: haiku")
+			}
+		})
+	}
+}
+
+func TestRunner_RecordsProviderAndModelOnCompletion(t *testing.T) {
+	tests := []struct {
+		name      string
+		named     bool
+		overrides map[string]model.Type
+		wantModel string
+	}{
+		{name: "executor reports model name", named: true, wantModel: "opus"},
+		{name: "falls back to model type", named: false, wantModel: "thinking"},
+		{name: "uses the implement step's model", named: true, overrides: map[string]model.Type{"Implement": model.Fast}, wantModel: "haiku"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+			stateManager := state.NewManagerWithDir(tmpDir)
+			//nolint:errcheck // cleanup
+			_ = stateManager.Reset()
+
+			// Stop on TASK2 so the state (reset once all tasks are done) survives.
+			mock := &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+					if strings.Contains(args[len(args)-1], "TASK2") {
+						return errors.New("stop")
+					}
+					return nil
+				},
+			}
+			var executor workflow.Executor = mock
+			if tt.named {
+				executor = &namedExecutor{MockExecutor: mock}
+			}
+
+			before := time.Now()
+			runner := workflow.NewRunner(executor, workflow.Config{
+				TasksDir:           tmpDir,
+				PRDPath:            prdPath,
+				ProviderName:       "claude",
+				StepModelOverrides: tt.overrides,
+			}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+
+			//nolint:errcheck // stopped on TASK2 by design
+			_ = runner.Run(context.Background())
+
+			loaded, err := stateManager.Load()
+			require.NoError(t, err)
+			require.NotNil(t, loaded)
+			rec, ok := loaded.CompletedTasks["TASK1"]
+			require.True(t, ok, "completion record should be saved for TASK1")
+			assert.Equal(t, "claude", rec.Provider)
+			assert.Equal(t, tt.wantModel, rec.Model)
+			assert.False(t, rec.CompletedAt.Before(before), "completion time should be recorded")
+			assert.NotContains(t, loaded.CompletedTasks, "TASK2")
+		})
+	}
+}
+
+func TestRunner_CompletionHistorySurvivesReset(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	prdPath := filepath.Join(tmpDir, "PRD.md")
 	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
 	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
 
 	stateManager := state.NewManagerWithDir(tmpDir)
-	//nolint:errcheck // cleanup
-	_ = stateManager.Reset()
+	runner := workflow.NewRunner(&namedExecutor{MockExecutor: &MockExecutor{}}, workflow.Config{
+		TasksDir:     tmpDir,
+		PRDPath:      prdPath,
+		ProviderName: "claude",
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
 
-	var buf bytes.Buffer
-	callCount := 0
+	require.NoError(t, runner.Run(context.Background()))
+
+	// The run finished, so the state file is gone; the history is not.
+	assert.False(t, stateManager.Exists())
+	history, err := stateManager.History()
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	for i, id := range []string{"TASK1", "TASK2"} {
+		assert.Equal(t, id, history[i].TaskID)
+		assert.Equal(t, "claude", history[i].Provider)
+		assert.Equal(t, "opus", history[i].Model)
+		assert.False(t, history[i].CompletedAt.IsZero())
+	}
+}
+
+func TestRunner_RecordsAppliedDirectives(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+
+	// Stop on TASK2 so the state (reset once all tasks are done) survives.
 	mockExec := &MockExecutor{
-		runFunc: func(_ context.Context, _ io.Writer, mt model.Type, _ ...string) error {
-			callCount++
-			// Call 1 = description generation (Fast model) — fail it.
-			if callCount == 1 {
-				assert.Equal(t, model.Fast, mt, "description call should use Fast model")
-				return errors.New("LLM unavailable")
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			prompt := args[len(args)-1]
+			switch {
+			case strings.Contains(prompt, "rename the flag"):
+				return errors.New("provider rate limited")
+			case strings.Contains(prompt, "TASK2"):
+				return errors.New("stop")
+			}
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+	runner.Queue().Enqueue("use the existing logger")
+	runner.Queue().Enqueue("rename the flag")
+	runner.Queue().Enqueue("keep the old name as an alias")
+
+	//nolint:errcheck // stopped on TASK2 by design
+	_ = runner.Run(context.Background())
+
+	stripped := ui.StripColors(buf.String())
+	assert.Contains(t, stripped, "2 directives applied: use the existing logger; keep the old name as an alias",
+		"the iteration summary lists the directives that ran, not the failed one")
+
+	loaded, err := stateManager.Load()
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, []string{"use the existing logger", "keep the old name as an alias"}, loaded.CompletedTasks["TASK1"].Directives)
+	assert.Empty(t, loaded.TaskDirectives, "the next task starts without directives")
+}
+
+func TestRunner_StepCount(t *testing.T) {
+	assert.Equal(t, 10, workflow.StepCount(nil))
+}
+
+func TestRunner_ConfiguredSteps(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+
+	type call struct {
+		model     model.Type
+		continues bool
+	}
+	var calls []call
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, mt model.Type, args ...string) error {
+			if strings.Contains(args[len(args)-1], "TASK2") {
+				return errors.New("stop")
+			}
+			calls = append(calls, call{model: mt, continues: args[0] == "-c"})
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+		Steps: []workflow.StepDef{
+			{Name: "Implement", Prompt: workflow.PromptImplement, Model: model.Thinking},
+			{Name: "Lint & test", Prompt: workflow.PromptLintAndTest, Model: model.Fast, Continue: true},
+			{Name: "Commit", Prompt: workflow.PromptCommit, Model: model.Fast},
+		},
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+	require.Error(t, runner.Run(context.Background()))
+
+	assert.Equal(t, []call{
+		{model: model.Thinking},
+		{model: model.Fast, continues: true},
+		{model: model.Fast},
+	}, calls)
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "Step 1/3: Implement TASK1")
+	assert.Contains(t, output, "Step 3/3: Commit")
+
+	loaded, err := stateManager.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TASK1"}, loaded.CompletedTaskIDs)
+	assert.Equal(t, 3, loaded.TotalSteps)
+}
+
+func TestRunner_SkipSteps(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			if strings.Contains(args[len(args)-1], "TASK2") {
+				return errors.New("stop")
+			}
+			calls++
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:  tmpDir,
+		PRDPath:   prdPath,
+		SkipSteps: []string{"update docs", "Code review"},
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+	require.Error(t, runner.Run(context.Background()))
+
+	assert.Equal(t, 8, calls)
+	output := ui.StripColors(buf.String())
+	assert.Equal(t, 1, strings.Count(output, "skipping: Code review, Update docs"))
+	assert.Contains(t, output, "Step 4/8: Apply fixes")
+	assert.NotContains(t, output, "/8: Code review")
+
+	loaded, err := stateManager.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TASK1"}, loaded.CompletedTaskIDs)
+	assert.Equal(t, 8, loaded.TotalSteps)
+	assert.Equal(t, []string{"Code review", "Update docs"}, loaded.SkippedSteps)
+}
+
+func TestRunner_SkipSteps_ResumesAtTheSameStep(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	// The task stopped at step 4 (Apply fixes) of a run that skipped the
+	// code review.
+	stateManager := state.NewManagerWithDir(tmpDir)
+	seed := state.NewState(tmpDir, prdPath, workflow.StepCount(nil)-1)
+	seed.CurrentTaskID = "TASK1"
+	seed.CurrentTaskFile = "TASK1.md"
+	seed.CurrentStep = 4
+	seed.SkippedSteps = []string{"Code review"}
+	require.NoError(t, stateManager.Save(seed))
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+	require.NoError(t, runner.Run(context.Background()))
+
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "Resuming from step 5: Apply fixes")
+	assert.NotContains(t, output, "Code review")
+}
+
+func TestRunner_StepModelOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	var models []model.Type
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, mt model.Type, _ ...string) error {
+			models = append(models, mt)
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+		StepModelOverrides: map[string]model.Type{
+			"code review": model.Fast,
+			"Lint & test": model.Thinking,
+			"Code reveiw": model.Fast,
+		},
+	}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(&buf))
+	require.NoError(t, runner.Run(context.Background()))
+
+	require.Len(t, models, 10)
+	assert.Equal(t, model.Thinking, models[2], "Lint & test")
+	assert.Equal(t, model.Fast, models[3], "Code review")
+	assert.Equal(t, model.Fast, models[5], "Verify fixes keeps its model")
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, `Model override for unknown step "Code reveiw" ignored (steps: Implement, Ensure completeness,`)
+	assert.NotContains(t, output, `unknown step "code review"`)
+}
+
+func TestRunner_ResumeAfterStepListChanged(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
+	seedState.CurrentTaskID = "TASK1"
+	seedState.CurrentTaskFile = "TASK1.md"
+	seedState.CurrentStep = 2
+	require.NoError(t, stateManager.Save(seedState))
+
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			return errors.New("stop")
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+		Steps: []workflow.StepDef{
+			{Name: "Implement", Prompt: workflow.PromptImplement, Model: model.Thinking},
+			{Name: "Lint & test", Prompt: workflow.PromptLintAndTest, Model: model.Fast, Continue: true},
+		},
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+	require.Error(t, runner.Run(context.Background()))
+
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "Workflow changed since TASK1 started (10 steps, now 2)")
+	assert.Contains(t, output, "Resuming from step 2: Lint & test")
+
+	loaded, err := stateManager.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.TotalSteps)
+}
+
+func TestRunner_EmbeddedPrompts(t *testing.T) {
+	for _, describe := range []bool{true, false} {
+		t.Run(fmt.Sprintf("describe %v", describe), func(t *testing.T) {
+			testEmbeddedPrompts(t, describe)
+		})
+	}
+}
+
+func testEmbeddedPrompts(t *testing.T, describe bool) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var stepPrompts, summaryPrompts []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			if len(args) > 0 {
+				stepPrompts = append(stepPrompts, args[len(args)-1])
+			}
+			return nil
+		},
+		describeFunc: func(_ context.Context, _ io.Writer, _ model.Type, prompt string) error {
+			summaryPrompts = append(summaryPrompts, prompt)
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+		Describe: describe,
+	}, workflow.WithStateManager(stateManager))
+
+	err := runner.Run(context.Background())
+	assert.NoError(t, err)
+
+	if describe {
+		require.Len(t, summaryPrompts, 1, "one task description call")
+		assert.Contains(t, summaryPrompts[0], "# Task 1")
+	} else {
+		require.Empty(t, summaryPrompts, "no task description call")
+	}
+	require.Len(t, stepPrompts, 10, "10 workflow steps")
+
+	// Step 1: Implement — contains PRD path, task reference, and quality guardrails
+	assert.Contains(t, stepPrompts[0], prdPath)
+	assert.Contains(t, stepPrompts[0], "TASK1")
+	assert.Contains(t, stepPrompts[0], "context-map.md")
+	assert.Contains(t, stepPrompts[0], "Quality Guardrails")
+	assert.Contains(t, stepPrompts[0], "parameterized queries")
+
+	// Step 2: Ensure completeness
+	assert.Contains(t, stepPrompts[1], "fully implemented")
+
+	// Step 3: Lint & test
+	assert.Contains(t, stepPrompts[2], "AGENTS.md")
+	assert.Contains(t, stepPrompts[2], "linters")
+
+	// Step 4: Code review — full embedded skill with context loading and task scope
+	assert.Contains(t, stepPrompts[3], "CLAUDE.md")
+	assert.Contains(t, stepPrompts[3], "git diff HEAD")
+	assert.Contains(t, stepPrompts[3], "CRITICAL")
+	assert.Contains(t, stepPrompts[3], "TASK1")
+	assert.NotContains(t, stepPrompts[3], "Use the code-review skill")
+
+	// Step 5: Apply fixes
+	assert.Contains(t, stepPrompts[4], "Fix")
+	assert.Contains(t, stepPrompts[4], "issues")
+
+	// Step 6: Verify fixes (same as step 3)
+	assert.Contains(t, stepPrompts[5], "AGENTS.md")
+
+	// Step 7: Update docs — diff-based documentation update
+	assert.Contains(t, stepPrompts[6], "git diff HEAD")
+	assert.Contains(t, stepPrompts[6], "README.md")
+	assert.Contains(t, stepPrompts[6], "user-facing")
+
+	// Step 8: Commit code
+	assert.Contains(t, stepPrompts[7], "conventional commit")
+	assert.Contains(t, stepPrompts[7], "co-author")
+
+	// Step 9: Context update — full embedded prompt, not delegation
+	assert.Contains(t, stepPrompts[8], "docs/context/")
+	assert.Contains(t, stepPrompts[8], "summary.md")
+	assert.NotContains(t, stepPrompts[8], "Update the project context.")
+
+	// Step 10: Commit memory (same as step 8)
+	assert.Contains(t, stepPrompts[9], "conventional commit")
+}
+
+func TestRunner_GlobalPreamble(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	var prompts []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			prompts = append(prompts, args[len(args)-1])
+			return nil
+		},
+	}
+	preamble := "House rules: always use tabs. No new dependencies."
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:       tmpDir,
+		PRDPath:        prdPath,
+		GlobalPreamble: preamble,
+	}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(io.Discard))
+
+	require.NoError(t, runner.Run(context.Background()))
+	require.Len(t, prompts, 10)
+	for i, p := range prompts {
+		assert.True(t, strings.HasPrefix(p, preamble+"\n\n"), "step %d starts with the preamble", i+1)
+		assert.True(t, strings.HasSuffix(p, "Do not pause for confirmation."), "step %d ends with the autonomous suffix", i+1)
+	}
+	// Commit steps keep their commit instructions; the others stay no-commit.
+	assert.Contains(t, prompts[7], "conventional commit")
+	assert.NotContains(t, prompts[7], "Do not stage, commit")
+	assert.Contains(t, prompts[9], "conventional commit")
+	assert.Contains(t, prompts[0], "Do not stage, commit, amend, rebase, or push any changes in this step.")
+}
+
+func TestRunner_CompletionDeduplication(t *testing.T) {
+	t.Run("completion appends task ID exactly once", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		prdPath := filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+		// Pre-seed state: TASK1 already completed, TASK2 active at last step.
+		// After TASK2 completes, CompletedTaskIDs should have exactly [TASK1, TASK2].
+		stateManager := state.NewManagerWithDir(tmpDir)
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
+		seedState.CurrentTaskID = "TASK2"
+		seedState.CurrentTaskFile = "TASK2.md"
+		seedState.CurrentStep = 9 // Last step
+		seedState.CompletedTaskIDs = []string{"TASK1"}
+		require.NoError(t, stateManager.Save(seedState))
+
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				return nil
+			},
+		}
+
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(stateManager))
+
+		err := runner.Run(context.Background())
+		assert.NoError(t, err)
+
+		// TASK2 should appear exactly once in CompletedTaskIDs.
+		// (State is reset after all complete, so check the load returns nil or clean state.)
+		// Since all tasks are done, state file is cleaned up. Verify through the
+		// fact that the run completed without error — if a duplicate were appended,
+		// state validation would reject it.
+	})
+
+	t.Run("completion does not duplicate already-completed task", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		prdPath := filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK3.md"), []byte("# Task 3"), 0o600))
+
+		// Pre-seed state: simulate edge case where TASK1 is both active and somehow
+		// already in the completed list (bypassing normal validation for test purposes).
+		// The completion logic should NOT duplicate it.
+		stateManager := state.NewManagerWithDir(tmpDir)
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
+		seedState.CurrentTaskID = "TASK1"
+		seedState.CurrentTaskFile = "TASK1.md"
+		seedState.CurrentStep = 9
+		seedState.CompletedTaskIDs = []string{"TASK1"} // Already there (edge case)
+		// Note: this state is technically invalid per IsValid(), but we're testing
+		// defense-in-depth in the completion path itself.
+
+		stepCount := 0
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				stepCount++
+				// 1 desc + 2 workflow steps (9,10) = 3 calls for first iteration.
+				// Fail early in second iteration to stop.
+				if stepCount > 10 {
+					return errors.New("stop")
+				}
+				return nil
+			},
+		}
+
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(stateManager))
+
+		// The run may fail at startup validation (active task in completed list).
+		// That's acceptable — the important thing is no duplicate gets saved.
+		//nolint:errcheck // testing dedup behavior, not error handling
+		_ = runner.Run(context.Background())
+
+		// If state was saved, verify no duplicate entries.
+		loaded, err := stateManager.Load()
+		if err == nil && loaded != nil {
+			seen := make(map[string]int)
+			for _, id := range loaded.CompletedTaskIDs {
+				seen[id]++
+			}
+			for id, count := range seen {
+				assert.Equal(t, 1, count, "task %s appears %d times in CompletedTaskIDs", id, count)
+			}
+		}
+	})
+}
+
+func TestRunner_UpdateDocsStepConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	type stepCapture struct {
+		model  model.Type
+		prompt string
+	}
+	var captured []stepCapture
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, mt model.Type, args ...string) error {
+			prompt := ""
+			if len(args) > 0 {
+				prompt = args[len(args)-1]
+			}
+			captured = append(captured, stepCapture{model: mt, prompt: prompt})
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager))
+
+	err := runner.Run(context.Background())
+	assert.NoError(t, err)
+	require.Len(t, captured, 10, "10 workflow steps")
+
+	// Step 7 (index 6): "Update docs" must use Fast model and include no-commit suffix.
+	assert.Equal(t, model.Fast, captured[6].model, "Update docs step should use Fast model")
+	assert.Contains(t, captured[6].prompt, "Do not stage, commit, amend, rebase, or push", "Update docs step should include no-commit suffix")
+}
+
+func TestRunner_ConfirmCommits(t *testing.T) {
+	tests := []struct {
+		name        string
+		isTTY       bool
+		answer      workflow.CommitDecision
+		wantAsks    int
+		wantCalls   int
+		wantSkipped bool
+		wantAborted bool
+	}{
+		{name: "skip skips both commit steps", isTTY: true, answer: workflow.CommitSkip, wantAsks: 1, wantCalls: 8, wantSkipped: true},
+		{name: "approve runs both commit steps", isTTY: true, answer: workflow.CommitApprove, wantAsks: 1, wantCalls: 10},
+		{name: "abort stops before committing", isTTY: true, answer: workflow.CommitAbort, wantAsks: 1, wantCalls: 7, wantAborted: true},
+		{name: "non-TTY auto-confirms", isTTY: false, answer: workflow.CommitSkip, wantAsks: 0, wantCalls: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			stateManager := state.NewManagerWithDir(tmpDir)
+			//nolint:errcheck // cleanup
+			_ = stateManager.Reset()
+
+			calls := 0
+			mockExec := &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+					calls++
+					return nil
+				},
+			}
+
+			var asked []string
+			confirm := func(_ context.Context, message string) workflow.CommitDecision {
+				asked = append(asked, message)
+				return tt.answer
+			}
+
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(mockExec, workflow.Config{
+				TasksDir:       tmpDir,
+				PRDPath:        prdPath,
+				IsTTY:          tt.isTTY,
+				Headless:       true,
+				ConfirmCommits: true,
+			}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf),
+				workflow.WithCommitConfirm(confirm))
+
+			err := runner.Run(context.Background())
+
+			assert.Len(t, asked, tt.wantAsks, "one confirmation covers both commit steps")
+			assert.Equal(t, tt.wantCalls, calls)
+
+			stripped := ui.StripColors(buf.String())
+			if tt.wantAborted {
+				require.ErrorIs(t, err, workflow.ErrCommitAborted)
+				require.ErrorIs(t, err, context.Canceled)
+				assert.Contains(t, stripped, "Stopped before step 8/10: Commit code")
+
+				saved, loadErr := stateManager.Load()
+				require.NoError(t, loadErr)
+				assert.Equal(t, 8, saved.CurrentStep, "resume asks again at the commit step")
+				assert.Nil(t, saved.LastFailure)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, stripped, "Iteration complete", "workflow continues after the commit decision")
+			if tt.wantSkipped {
+				assert.Contains(t, stripped, "Skipped step 8/10: Commit code")
+				assert.Contains(t, stripped, "Skipped step 10/10: Commit memory")
+			} else {
+				assert.NotContains(t, stripped, "Skipped step")
+			}
+		})
+	}
+}
+
+func TestRunner_PauseBetweenTasks(t *testing.T) {
+	tests := []struct {
+		name      string
+		isTTY     bool
+		answer    bool
+		wantAsks  int
+		wantCalls int
+		wantPause bool
+	}{
+		{name: "declined stops after the task", isTTY: true, answer: false, wantAsks: 1, wantCalls: 10, wantPause: true},
+		{name: "approved asks once per remaining task", isTTY: true, answer: true, wantAsks: 1, wantCalls: 20},
+		{name: "non-TTY auto-continues", isTTY: false, answer: false, wantAsks: 0, wantCalls: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+			stateManager := state.NewManagerWithDir(tmpDir)
+			//nolint:errcheck // cleanup
+			_ = stateManager.Reset()
+
+			calls := 0
+			mockExec := &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+					calls++
+					return nil
+				},
+			}
+
+			var asked []string
+			pause := func(_ context.Context, message string) bool {
+				asked = append(asked, message)
+				return tt.answer
+			}
+
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(mockExec, workflow.Config{
+				TasksDir:          tmpDir,
+				PRDPath:           prdPath,
+				IsTTY:             tt.isTTY,
+				Headless:          true,
+				PauseBetweenTasks: true,
+			}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf),
+				workflow.WithTaskPause(pause))
+
+			require.NoError(t, runner.Run(context.Background()))
+
+			assert.Len(t, asked, tt.wantAsks, "no prompt after the last task")
+			assert.Equal(t, tt.wantCalls, calls)
+
+			stripped := ui.StripColors(buf.String())
+			if !tt.wantPause {
+				assert.Contains(t, stripped, "All tasks implemented!")
+				return
+			}
+			assert.Equal(t, []string{"Continue to next task?"}, asked)
+			assert.Contains(t, stripped, "Paused before the next task; run snap again to continue")
+			assert.NotContains(t, stripped, "All tasks implemented!")
+
+			saved, err := stateManager.Load()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"TASK1"}, saved.CompletedTaskIDs)
+		})
+	}
+}
+
+func TestRunner_SkipDirective(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	var runner *workflow.Runner
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			if strings.Contains(args[len(args)-1], "TASK2") {
+				return errors.New("stop")
+			}
+			calls++
+			if calls == 2 {
+				runner.Queue().Enqueue("/skip")
+			}
+			return nil
+		},
+	}
+
+	var buf, events bytes.Buffer
+	runner = workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf), workflow.WithEventSink(&events))
+	require.Error(t, runner.Run(context.Background()))
+
+	assert.Equal(t, 2, calls, "TASK1 stops after step 2")
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "skipping TASK1 by user request")
+	assert.NotContains(t, output, "Step 3/10")
+	assert.NotContains(t, output, "Iteration complete")
+	assert.Contains(t, output, "Implementing TASK2")
+
+	loaded, err := stateManager.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TASK1"}, loaded.CompletedTaskIDs)
+	assert.True(t, loaded.CompletedTasks["TASK1"].Skipped)
+	assert.Empty(t, loaded.CompletedTasks["TASK1"].Directives, "/skip is not an applied directive")
+	assert.Equal(t, "TASK2", loaded.CurrentTaskID)
+	assert.Equal(t, 1, loaded.CurrentStep)
+	assert.NotContains(t, events.String(), `"type":"iteration_complete"`)
+}
+
+func TestRunner_SkipDirective_ResetsTheTree(t *testing.T) {
+	repoDir := t.TempDir()
+	gitOut := func(args ...string) string {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+		return strings.TrimSpace(string(out))
+	}
+	gitOut("init")
+	gitOut("config", "user.email", "test@test.com")
+	gitOut("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+	gitOut("add", ".")
+	gitOut("commit", "-m", "initial")
+	base := gitOut("rev-parse", "HEAD")
+
+	// The task files live in the repository, untracked.
+	tasksDir := filepath.Join(repoDir, "docs", "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	prdPath := filepath.Join(tasksDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+	stateManager := state.NewManagerWithDir(t.TempDir())
+	var runner *workflow.Runner
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			calls++
+			prompt := args[len(args)-1]
+			switch {
+			case calls == 1:
+				require.NoError(t, os.WriteFile(filepath.Join(repoDir, "skipped.go"), []byte("package main\n"), 0o600))
+				return os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main // skipped\n"), 0o600)
+			case calls == 2:
+				runner.Queue().Enqueue("/skip")
+			case strings.Contains(prompt, "Stage and commit all changes"):
+				gitOut("add", "task2.go")
+				gitOut("commit", "-m", "task 2")
+			default:
+				return os.WriteFile(filepath.Join(repoDir, "task2.go"), []byte(fmt.Sprintf("package main // %d\n", calls)), 0o600)
+			}
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner = workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tasksDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf), workflow.WithWorkTree(snapshot.New(repoDir)))
+	require.NoError(t, runner.Run(context.Background()))
+
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "TASK1 left 3 uncommitted file(s): main.go, docs/, skipped.go")
+	assert.Contains(t, output, "uncommitted changes saved as a snapshot")
+	assert.Contains(t, output, "reset to "+base[:7]+", where TASK1 started")
+
+	committed := gitOut("log", "--format=", "--name-only", base+"..HEAD")
+	assert.Contains(t, committed, "task2.go")
+	assert.NotContains(t, committed, "main.go", "TASK2's commits hold none of TASK1's changes")
+	assert.NotContains(t, committed, "skipped.go")
+	assert.Equal(t, "package main\n", gitOut("show", "HEAD:main.go")+"\n")
+	assert.NoFileExists(t, filepath.Join(repoDir, "skipped.go"))
+	assert.FileExists(t, filepath.Join(tasksDir, "TASK2.md"), "task files are kept")
+	assert.Contains(t, gitOut("stash", "list"), "TASK1", "the skipped changes are kept as a snapshot")
+}
+
+func TestRunner_SkipDirective_AfterCommitKeepsTheCommits(t *testing.T) {
+	repoDir := t.TempDir()
+	gitOut := func(args ...string) string {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+		return strings.TrimSpace(string(out))
+	}
+	gitOut("init")
+	gitOut("config", "user.email", "test@test.com")
+	gitOut("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+	gitOut("add", ".")
+	gitOut("commit", "-m", "initial")
+	base := gitOut("rev-parse", "HEAD")
+
+	tasksDir := t.TempDir()
+	prdPath := filepath.Join(tasksDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+	stateManager := state.NewManagerWithDir(t.TempDir())
+	var runner *workflow.Runner
+	committed := false
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			prompt := args[len(args)-1]
+			switch {
+			case strings.Contains(prompt, "TASK2"):
+				return errors.New("stop")
+			case committed:
+				// The step after the code commit: skip the rest of TASK1.
+				runner.Queue().Enqueue("/skip")
+			case strings.Contains(prompt, "Stage and commit all changes"):
+				gitOut("add", "feature.go")
+				gitOut("commit", "-m", "task 1")
+				committed = true
+			default:
+				return os.WriteFile(filepath.Join(repoDir, "feature.go"), []byte("package main\n"), 0o600)
+			}
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner = workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tasksDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf), workflow.WithWorkTree(snapshot.New(repoDir)))
+	require.Error(t, runner.Run(context.Background()))
+
+	branch := gitOut("branch", "--list", "--format=%(refname:short)", "snap/skipped/*")
+	require.True(t, strings.HasPrefix(branch, "snap/skipped/task1-"), "branch %q", branch)
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "commits made since "+base[:7]+" saved on branch "+branch)
+	assert.Contains(t, output, "reset to "+base[:7]+", where TASK1 started")
+
+	assert.Equal(t, base, gitOut("rev-parse", "HEAD"), "the skipped task's commit is off the branch")
+	assert.Equal(t, "task 1", gitOut("log", "-1", "--format=%s", branch), "and kept on the saved branch")
+	assert.Equal(t, "feature.go", gitOut("show", "--format=", "--name-only", branch))
+}
+
+func TestRunner_MaxIterations(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("TASK%d.md", i)), []byte("# Task"), 0o600))
+	}
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			calls++
+			return nil
+		},
+	}
+	run := func() string {
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir:      tmpDir,
+			PRDPath:       prdPath,
+			MaxIterations: 2,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+		require.NoError(t, runner.Run(context.Background()))
+		return ui.StripColors(buf.String())
+	}
+
+	output := run()
+	assert.Equal(t, 20, calls)
+	assert.Contains(t, output, "reached max iterations (2), stopping")
+	assert.NotContains(t, output, "All tasks implemented!")
+
+	saved, err := stateManager.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TASK1", "TASK2"}, saved.CompletedTaskIDs)
+	assert.Empty(t, saved.CurrentTaskID)
+
+	// The next run continues with TASK3; with no task left after it, the
+	// cap doesn't stop the run from finishing.
+	output = run()
+	assert.Equal(t, 30, calls)
+	assert.NotContains(t, output, "reached max iterations")
+	assert.Contains(t, output, "All tasks implemented!")
+}
+
+func TestRunner_PostIterationHook(t *testing.T) {
+	setup := func(t *testing.T, tasks int) (tmpDir string, stateManager *state.Manager) {
+		t.Helper()
+		tmpDir = t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "PRD.md"), []byte("# PRD"), 0o600))
+		for i := 1; i <= tasks; i++ {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("TASK%d.md", i)), []byte("# Task"), 0o600))
+		}
+		return tmpDir, state.NewManagerWithDir(tmpDir)
+	}
+	run := func(ctx context.Context, tmpDir string, stateManager *state.Manager, hook string, fatal bool) (string, error) {
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{
+			TasksDir:          tmpDir,
+			PRDPath:           filepath.Join(tmpDir, "PRD.md"),
+			PostIterationHook: hook,
+			HookFatal:         fatal,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+		err := runner.Run(ctx)
+		return ui.StripColors(buf.String()), err
+	}
+
+	t.Run("runs after each task with the task in the environment", func(t *testing.T) {
+		tmpDir, stateManager := setup(t, 2)
+		logPath := filepath.Join(tmpDir, "hook.log")
+
+		output, err := run(context.Background(), tmpDir, stateManager,
+			`echo "$SNAP_TASK_ID $SNAP_TASKS_DIR" >> `+logPath, false)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		assert.Equal(t, "TASK1 "+tmpDir+"\nTASK2 "+tmpDir+"\n", string(data))
+		assert.Less(t, strings.Index(output, "Iteration complete"), strings.Index(output, "Running post-iteration hook"))
+	})
+
+	t.Run("failure is a warning by default", func(t *testing.T) {
+		tmpDir, stateManager := setup(t, 2)
+
+		output, err := run(context.Background(), tmpDir, stateManager, "echo verify failed; exit 3", false)
+		require.NoError(t, err)
+		assert.Contains(t, output, "verify failed")
+		assert.Equal(t, 2, strings.Count(output, "Post-iteration hook failed: exit status 3; continuing"))
+		assert.Contains(t, output, "All tasks implemented!")
+	})
+
+	t.Run("HookFatal stops the run after the task is recorded", func(t *testing.T) {
+		tmpDir, stateManager := setup(t, 2)
+
+		_, err := run(context.Background(), tmpDir, stateManager, "exit 3", true)
+		require.ErrorIs(t, err, workflow.ErrHookFailed)
+		assert.Contains(t, err.Error(), "post-iteration hook failed for TASK1: exit status 3")
+
+		saved, err := stateManager.Load()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"TASK1"}, saved.CompletedTaskIDs)
+	})
+
+	t.Run("cancelling the run kills the hook", func(t *testing.T) {
+		tmpDir, stateManager := setup(t, 1)
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := run(ctx, tmpDir, stateManager, "exec sleep 30", true)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+}
+
+func TestRunner_SignCommits_RejectsUnsignedCommit(t *testing.T) {
+	repoDir := t.TempDir()
+	tasksDir := t.TempDir()
+
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	gitRun("init")
+	gitRun("config", "user.email", "test@test.com")
+	gitRun("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+	gitRun("add", ".")
+	gitRun("commit", "-m", "initial")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "new.go"), []byte("package main\n"), 0o600))
+
+	prdPath := filepath.Join(tasksDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tasksDir)
+	seedState := state.NewState(tasksDir, prdPath, workflow.StepCount(nil))
+	seedState.CurrentTaskID = "TASK1"
+	seedState.CurrentTaskFile = "TASK1.md"
+	seedState.CurrentStep = 8
+	require.NoError(t, stateManager.Save(seedState))
+
+	var prompts []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			prompts = append(prompts, args[len(args)-1])
+			gitRun("add", ".")
+			gitRun("commit", "-m", "unsigned work")
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:    tasksDir,
+		PRDPath:     prdPath,
+		SignCommits: true,
+	},
+		workflow.WithStateManager(stateManager),
+		workflow.WithRunnerOutput(&buf),
+		workflow.WithWorkTree(snapshot.New(repoDir)),
+	)
+
+	err := runner.Run(context.Background())
+	require.ErrorIs(t, err, workflow.ErrUnsignedCommit)
+	assert.Contains(t, err.Error(), "git commit --amend -S --no-edit")
+	require.Len(t, prompts, 1)
+	assert.Contains(t, prompts[0], "run git commit with -S")
+	assert.Contains(t, ui.StripColors(buf.String()), "Step 8/10 created an unsigned commit")
+
+	saved, err := stateManager.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 8, saved.CurrentStep, "the commit step stays current")
+}
+
+func TestRunner_SignCommits_ChecksTheTasksCommits(t *testing.T) {
+	repoDir := t.TempDir()
+	tasksDir := t.TempDir()
+
+	gitOut := func(args ...string) string {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+		return strings.TrimSpace(string(out))
+	}
+	gitOut("init")
+	gitOut("config", "user.email", "test@test.com")
+	gitOut("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+	gitOut("add", ".")
+	gitOut("commit", "-m", "initial")
+	start := gitOut("rev-parse", "HEAD")
+
+	// An earlier step committed without signing.
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "early.go"), []byte("package main\n"), 0o600))
+	gitOut("add", ".")
+	gitOut("commit", "-m", "early unsigned work")
+	early := gitOut("rev-parse", "HEAD")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "new.go"), []byte("package main\n"), 0o600))
+
+	prdPath := filepath.Join(tasksDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tasksDir)
+	seedState := state.NewState(tasksDir, prdPath, workflow.StepCount(nil))
+	seedState.CurrentTaskID = "TASK1"
+	seedState.CurrentTaskFile = "TASK1.md"
+	seedState.CurrentStep = 8
+	seedState.TaskStartCommit = start
+	require.NoError(t, stateManager.Save(seedState))
+
+	mockExec := &MockExecutor{
+		runFunc: func(context.Context, io.Writer, model.Type, ...string) error {
+			gitOut("add", ".")
+			gitOut("commit", "-m", "unsigned work")
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:    tasksDir,
+		PRDPath:     prdPath,
+		SignCommits: true,
+	},
+		workflow.WithStateManager(stateManager),
+		workflow.WithRunnerOutput(io.Discard),
+		workflow.WithWorkTree(snapshot.New(repoDir)),
+	)
+
+	err := runner.Run(context.Background())
+	require.ErrorIs(t, err, workflow.ErrUnsignedCommit)
+	head := gitOut("rev-parse", "HEAD")
+	assert.Contains(t, err.Error(), early[:7]+", "+head[:7])
+	assert.Contains(t, err.Error(), "git rebase --exec 'git commit --amend -S --no-edit' "+start[:7])
+}
+
+func TestRunner_PRPerTask(t *testing.T) {
+	repoDir := t.TempDir()
+	tasksDir := t.TempDir()
+
+	gitOut := func(args ...string) string {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+		return strings.TrimSpace(string(out))
+	}
+	gitOut("init")
+	gitOut("config", "user.email", "test@test.com")
+	gitOut("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+	gitOut("add", ".")
+	gitOut("commit", "-m", "initial")
+	base := gitOut("symbolic-ref", "--short", "HEAD")
+
+	prdPath := filepath.Join(tasksDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tasksDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	// Every step changes a file and the commit steps commit, so each task
+	// leaves commits on its branch.
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			calls++
+			if strings.Contains(args[len(args)-1], "Stage and commit all changes") {
+				gitOut("add", ".")
+				gitOut("commit", "-m", fmt.Sprintf("work %d", calls))
+				return nil
+			}
+			return os.WriteFile(filepath.Join(repoDir, fmt.Sprintf("file%d.txt", calls)), []byte("x"), 0o600)
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:  tasksDir,
+		PRDPath:   prdPath,
+		PRPerTask: true,
+	},
+		workflow.WithStateManager(stateManager),
+		workflow.WithRunnerOutput(&buf),
+		workflow.WithWorkTree(snapshot.New(repoDir)),
+	)
+
+	require.NoError(t, runner.Run(context.Background()))
+
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "Created branch snap/task1 from "+base)
+	assert.Contains(t, output, "Created branch snap/task2 from "+base)
+	assert.Equal(t, 2, strings.Count(output, "No remote configured, skipping push"), "post-run once per task")
+	assert.Contains(t, output, "Switched back to "+base)
+	assert.Contains(t, output, "All tasks implemented!")
+
+	assert.Equal(t, base, gitOut("symbolic-ref", "--short", "HEAD"), "the run ends on the base branch")
+	assert.Equal(t, "initial", gitOut("log", "--format=%s", base), "the base branch is untouched")
+	assert.NotEqual(t, gitOut("rev-parse", base), gitOut("rev-parse", "snap/task1"))
+	assert.Equal(t, gitOut("rev-parse", base), gitOut("merge-base", "snap/task1", "snap/task2"),
+		"task branches both start from the base branch")
+	assert.False(t, stateManager.Exists(), "state is cleared once every task is done")
+}
+
+func TestRunner_PRPerTask_PublishesLeftoverBranch(t *testing.T) {
+	repoDir := t.TempDir()
+	tasksDir := t.TempDir()
+
+	gitOut := func(args ...string) string {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+		return strings.TrimSpace(string(out))
+	}
+	gitOut("init")
+	gitOut("config", "user.email", "test@test.com")
+	gitOut("config", "user.name", "test")
+	gitOut("commit", "--allow-empty", "-m", "initial")
+	base := gitOut("symbolic-ref", "--short", "HEAD")
+
+	// TASK1 completed on its branch, but the run stopped before publishing
+	// it; snap/task2 is left over from an earlier attempt at TASK2.
+	gitOut("switch", "-c", "snap/task1")
+	gitOut("commit", "--allow-empty", "-m", "task 1")
+	gitOut("switch", "-c", "snap/task2")
+	gitOut("commit", "--allow-empty", "-m", "stale task 2")
+	gitOut("switch", "snap/task1")
+
+	prdPath := filepath.Join(tasksDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK2.md"), []byte("# Task 2"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tasksDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+	seed := state.NewState(tasksDir, prdPath, workflow.StepCount(nil))
+	seed.CompletedTaskIDs = []string{"TASK1"}
+	seed.BaseBranch = base
+	seed.PublishBranch = "snap/task1"
+	require.NoError(t, stateManager.Save(seed))
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{
+		TasksDir:  tasksDir,
+		PRDPath:   prdPath,
+		PRPerTask: true,
+	},
+		workflow.WithStateManager(stateManager),
+		workflow.WithRunnerOutput(&buf),
+		workflow.WithWorkTree(snapshot.New(repoDir)),
+	)
+
+	require.NoError(t, runner.Run(context.Background()))
+
+	output := ui.StripColors(buf.String())
+	assert.Contains(t, output, "Publishing snap/task1, left unpublished by the last run")
+	assert.Equal(t, 2, strings.Count(output, "No remote configured, skipping push"), "post-run for both tasks")
+	assert.Less(t, strings.Index(output, "Publishing snap/task1"), strings.Index(output, "Created branch snap/task2"))
+	assert.Equal(t, gitOut("rev-parse", base), gitOut("rev-parse", "snap/task2"), "the stale task branch is reset to the base")
+	assert.Equal(t, base, gitOut("symbolic-ref", "--short", "HEAD"))
+}
+
+func TestRunner_PRPerTask_DetachedHead(t *testing.T) {
+	repoDir := t.TempDir()
+	tasksDir := t.TempDir()
+
+	for _, args := range [][]string{
+		{"init"},
+		{"-c", "user.email=test@test.com", "-c", "user.name=test", "commit", "--allow-empty", "-m", "initial"},
+		{"switch", "--detach"},
+	} {
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+
+	prdPath := filepath.Join(tasksDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tasksDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			calls++
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:  tasksDir,
+		PRDPath:   prdPath,
+		PRPerTask: true,
+	},
+		workflow.WithStateManager(stateManager),
+		workflow.WithRunnerOutput(io.Discard),
+		workflow.WithWorkTree(snapshot.New(repoDir)),
+	)
+
+	err := runner.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HEAD is detached")
+	assert.Zero(t, calls, "no step runs without a task branch")
+}
+
+func TestRunner_SkipsCommitOnCleanTree(t *testing.T) {
+	tests := []struct {
+		name        string
+		dirty       bool
+		wantCalls   int
+		wantSkipped bool
+	}{
+		{name: "clean tree skips commit steps", dirty: false, wantCalls: 1, wantSkipped: true},
+		{name: "dirty tree runs commit steps", dirty: true, wantCalls: 3, wantSkipped: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := t.TempDir()
+			tasksDir := t.TempDir()
+
+			gitRun := func(args ...string) {
+				t.Helper()
+				cmd := exec.CommandContext(context.Background(), "git", args...)
+				cmd.Dir = repoDir
+				out, err := cmd.CombinedOutput()
+				require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+			}
+			gitRun("init")
+			gitRun("config", "user.email", "test@test.com")
+			gitRun("config", "user.name", "test")
+			require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+			gitRun("add", ".")
+			gitRun("commit", "-m", "work already committed")
+			if tt.dirty {
+				require.NoError(t, os.WriteFile(filepath.Join(repoDir, "new.go"), []byte("package main\n"), 0o600))
+			}
+
+			prdPath := filepath.Join(tasksDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			// Pre-seed state: interrupted at the code commit step.
+			stateManager := state.NewManagerWithDir(tasksDir)
+			seedState := state.NewState(tasksDir, prdPath, workflow.StepCount(nil))
+			seedState.CurrentTaskID = "TASK1"
+			seedState.CurrentTaskFile = "TASK1.md"
+			seedState.CurrentStep = 8
+			require.NoError(t, stateManager.Save(seedState))
+
+			calls := 0
+			mockExec := &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+					calls++
+					return nil
+				},
+			}
+
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(mockExec, workflow.Config{
+				TasksDir: tasksDir,
+				PRDPath:  prdPath,
+			},
+				workflow.WithStateManager(stateManager),
+				workflow.WithRunnerOutput(&buf),
+				workflow.WithWorkTree(snapshot.New(repoDir)),
+			)
+
+			require.NoError(t, runner.Run(context.Background()))
+			assert.Equal(t, tt.wantCalls, calls)
+
+			stripped := ui.StripColors(buf.String())
+			assert.Contains(t, stripped, "Iteration complete")
+			if tt.wantSkipped {
+				assert.Contains(t, stripped, "Skipped step 8/10: Commit code (nothing to commit)")
+				assert.Contains(t, stripped, "Skipped step 10/10: Commit memory (nothing to commit)")
+			} else {
+				assert.NotContains(t, stripped, "nothing to commit")
+			}
+		})
+	}
+}
+
+func TestRunner_PrintsTaskChangeSummary(t *testing.T) {
+	repoDir := t.TempDir()
+	tasksDir := t.TempDir()
+
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	gitRun("init")
+	gitRun("config", "user.email", "test@test.com")
+	gitRun("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+	gitRun("add", ".")
+	gitRun("commit", "-m", "initial")
+
+	prdPath := filepath.Join(tasksDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	// The implement step edits a tracked file; the commit step commits it.
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			calls++
+			switch calls {
+			case 1:
+				return os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o600)
+			case 8:
+				gitRun("commit", "-am", "implement TASK1")
+			}
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tasksDir,
+		PRDPath:  prdPath,
+	},
+		workflow.WithStateManager(state.NewManagerWithDir(tasksDir)),
+		workflow.WithRunnerOutput(&buf),
+		workflow.WithWorkTree(snapshot.New(repoDir)),
+	)
+
+	require.NoError(t, runner.Run(context.Background()))
+
+	stripped := ui.StripColors(buf.String())
+	assert.Contains(t, stripped, "Iteration complete")
+	assert.Contains(t, stripped, "TASK1 implementation complete")
+	assert.Contains(t, stripped, "1 files changed")
+	assert.Contains(t, stripped, "2 lines added")
+}
+
+func TestRunner_ReviewAndDocsDiffAgainstTaskStart(t *testing.T) {
+	repoDir := t.TempDir()
+	gitRun := func(args ...string) string {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+		return strings.TrimSpace(string(out))
+	}
+	gitRun("init")
+	gitRun("config", "user.email", "test@test.com")
+	gitRun("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+	gitRun("add", ".")
+	gitRun("commit", "-m", "initial")
+	head := gitRun("rev-parse", "HEAD")
+
+	tests := []struct {
+		name     string
+		worktree bool
+		baseSHA  string
+		want     string
+	}{
+		{name: "task start commit", worktree: true, want: "git diff " + head},
+		{name: "base sha overrides", worktree: true, baseSHA: "abc1234", want: "git diff abc1234"},
+		{name: "no work tree", want: "git diff HEAD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasksDir := t.TempDir()
+			prdPath := filepath.Join(tasksDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			var captured []string
+			mockExec := &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+					captured = append(captured, args[len(args)-1])
+					return nil
+				},
+			}
+			opts := []workflow.RunnerOption{
+				workflow.WithStateManager(state.NewManagerWithDir(tasksDir)),
+				workflow.WithRunnerOutput(io.Discard),
+			}
+			if tt.worktree {
+				opts = append(opts, workflow.WithWorkTree(snapshot.New(repoDir)))
+			}
+			runner := workflow.NewRunner(mockExec, workflow.Config{
+				TasksDir: tasksDir,
+				PRDPath:  prdPath,
+				BaseSHA:  tt.baseSHA,
+			}, opts...)
+			require.NoError(t, runner.Run(context.Background()))
+
+			// Commit steps are skipped on the clean tree; steps 1-7 always run.
+			require.GreaterOrEqual(t, len(captured), 7)
+			assert.Contains(t, captured[3], tt.want, "code review diffs against the base")
+			assert.Contains(t, captured[6], tt.want, "update docs diffs against the base")
+		})
+	}
+}
+
+func TestRunner_StrayFilesAfterCommitStep(t *testing.T) {
+	tests := []struct {
+		name    string
+		stray   bool
+		strict  bool
+		wantErr bool
+	}{
+		{name: "clean after commit"},
+		{name: "stray files reported", stray: true},
+		{name: "stray files fail with strict commits", stray: true, strict: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := t.TempDir()
+			gitRun := func(args ...string) {
+				t.Helper()
+				cmd := exec.CommandContext(context.Background(), "git", args...)
+				cmd.Dir = repoDir
+				out, err := cmd.CombinedOutput()
+				require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+			}
+			gitRun("init")
+			gitRun("config", "user.email", "test@test.com")
+			gitRun("config", "user.name", "test")
+			require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o600))
+			gitRun("add", ".")
+			gitRun("commit", "-m", "initial")
+
+			tasksDir := t.TempDir()
+			prdPath := filepath.Join(tasksDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			calls := 0
+			mockExec := &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+					calls++
+					switch calls {
+					case 1: // implement
+						require.NoError(t, os.WriteFile(filepath.Join(repoDir, "feature.go"), []byte("package main\n"), 0o600))
+						if tt.stray {
+							require.NoError(t, os.WriteFile(filepath.Join(repoDir, "scratch.txt"), []byte("notes"), 0o600))
+						}
+					case 8: // commit the code, missing the scratch file
+						gitRun("add", "feature.go")
+						gitRun("commit", "-m", "feature")
+					}
+					return nil
+				},
+			}
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(mockExec, workflow.Config{
+				TasksDir:      tasksDir,
+				PRDPath:       prdPath,
+				StrictCommits: tt.strict,
+			},
+				workflow.WithStateManager(state.NewManagerWithDir(tasksDir)),
+				workflow.WithRunnerOutput(&buf),
+				workflow.WithWorkTree(snapshot.New(repoDir)),
+			)
+			err := runner.Run(context.Background())
+			output := ui.StripColors(buf.String())
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, workflow.ErrDirtyTree)
+				assert.Contains(t, output, "Step 8/10 left uncommitted changes")
+				assert.Equal(t, 8, calls, "the run stops at the commit step")
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.stray {
+				assert.Contains(t, output, "step 8/10 left 1 uncommitted file(s): scratch.txt")
+			} else {
+				assert.NotContains(t, output, "uncommitted file(s)")
+			}
+		})
+	}
+}
+
+func TestRunner_ScopeInjectedIntoPrompts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var captured []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			captured = append(captured, args[len(args)-1])
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+		Scope:    "services/billing",
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+
+	require.NoError(t, runner.Run(context.Background()))
+	require.Len(t, captured, 10)
+
+	// Steps 3 and 6 lint/test, 4 reviews, 7 updates docs.
+	for _, i := range []int{2, 3, 5, 6} {
+		assert.Contains(t, captured[i], "services/billing", "step %d should be scoped", i+1)
+	}
+	assert.NotContains(t, captured[0], "services/billing", "implement step is not scoped")
+}
+
+func TestRunner_SessionMemoryDir(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var captured []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			captured = append(captured, args[len(args)-1])
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:  tmpDir,
+		PRDPath:   prdPath,
+		MemoryDir: ".snap/sessions/auth/memory",
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+
+	require.NoError(t, runner.Run(context.Background()))
+	require.Len(t, captured, 10)
+
+	// Step 9 maintains the session vault; step 1 reads it.
+	assert.Contains(t, captured[8], "Update `.snap/sessions/auth/memory/` in the **project root**")
+	assert.NotContains(t, captured[8], "docs/context")
+	assert.Contains(t, captured[0], ".snap/sessions/auth/memory/context-map.md")
+}
+
+func TestRunner_UpdateChangelog(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "default path", want: "`CHANGELOG.md`"},
+		{name: "custom path", path: "docs/CHANGES.md", want: "`docs/CHANGES.md`"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			stateManager := state.NewManagerWithDir(tmpDir)
+			//nolint:errcheck // cleanup
+			_ = stateManager.Reset()
+
+			var captured []string
+			mockExec := &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+					captured = append(captured, args[len(args)-1])
+					return nil
+				},
+			}
+
+			runner := workflow.NewRunner(mockExec, workflow.Config{
+				TasksDir:        tmpDir,
+				PRDPath:         prdPath,
+				UpdateChangelog: true,
+				ChangelogPath:   tc.path,
+			}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+
+			require.NoError(t, runner.Run(context.Background()))
+			require.Len(t, captured, 10, "the changelog adds no step")
+
+			// Step 7 updates docs and the changelog without committing;
+			// step 8 commits both.
+			assert.Contains(t, captured[6], tc.want)
+			assert.Contains(t, captured[6], "Keep a Changelog")
+			assert.Contains(t, captured[6], "Do not stage, commit")
+			for i, prompt := range captured {
+				if i != 6 {
+					assert.NotContains(t, prompt, "Keep a Changelog", "step %d", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Explain(t *testing.T) {
+	for _, explain := range []bool{true, false} {
+		t.Run(fmt.Sprintf("explain=%v", explain), func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			stateManager := state.NewManagerWithDir(tmpDir)
+			//nolint:errcheck // cleanup
+			_ = stateManager.Reset()
+
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{
+				TasksDir: tmpDir,
+				PRDPath:  prdPath,
+				Explain:  explain,
+			}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+			require.NoError(t, runner.Run(context.Background()))
+
+			output := ui.StripColors(buf.String())
+			lines := []string{
+				"Step 1/10 Implement TASK1: implements the task",
+				"Step 4/10 Code review: reviews the diff",
+				"Step 10/10 Commit memory: commits the memory vault updates",
+			}
+			for _, line := range lines {
+				if explain {
+					assert.Contains(t, output, line)
+				} else {
+					assert.NotContains(t, output, line)
+				}
+			}
+			if explain {
+				assert.Less(t, strings.Index(output, "Step 4/10 Code review: reviews"), strings.Index(output, "Step 4/10: Code review"),
+					"the purpose comes before the step runs")
+			}
+		})
+	}
+}
+
+func TestRunner_ExtraGuardrails(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var captured []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			captured = append(captured, args[len(args)-1])
+			return nil
+		},
+	}
+
+	rule := "No new dependencies without an ADR"
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:        tmpDir,
+		PRDPath:         prdPath,
+		ExtraGuardrails: []string{rule},
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+
+	require.NoError(t, runner.Run(context.Background()))
+	require.Len(t, captured, 10)
+
+	// Step 1 implements and step 4 reviews; no other step sees the rules.
+	for i, prompt := range captured {
+		if i == 0 || i == 3 {
+			assert.Contains(t, prompt, "- "+rule, "step %d", i+1)
+		} else {
+			assert.NotContains(t, prompt, rule, "step %d", i+1)
+		}
+	}
+}
+
+func TestRunner_ContinuationSteps(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var continues []bool
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			continues = append(continues, args[0] == "-c")
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+	require.NoError(t, runner.Run(context.Background()))
+
+	// Lint & test, Apply fixes, Verify fixes, Update memory and Commit memory
+	// continue the previous step's conversation.
+	assert.Equal(t, []bool{false, false, true, false, true, true, false, false, true, true}, continues)
+}
+
+func TestRunner_FailFastOnLint(t *testing.T) {
+	tests := []struct {
+		name      string
+		failFast  bool
+		result    string
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "failing checks stop the iteration", failFast: true, result: "SNAP-CHECKS: FAIL", wantErr: true, wantCalls: 3},
+		{name: "passing checks continue", failFast: true, result: "SNAP-CHECKS: PASS", wantCalls: 10},
+		{name: "missing result continues", failFast: true, result: "all done", wantCalls: 10},
+		{name: "disabled ignores the result", result: "SNAP-CHECKS: FAIL", wantCalls: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			stateManager := state.NewManagerWithDir(tmpDir)
+			//nolint:errcheck // cleanup
+			_ = stateManager.Reset()
+
+			var prompts []string
+			mockExec := &MockExecutor{
+				runFunc: func(_ context.Context, w io.Writer, _ model.Type, args ...string) error {
+					prompt := args[len(args)-1]
+					prompts = append(prompts, prompt)
+					if strings.Contains(prompt, "discover the project's required linters") {
+						fmt.Fprintf(w, "ran go test ./...\n%s\n", tt.result)
+					}
+					return nil
+				},
+			}
+
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(mockExec, workflow.Config{
+				TasksDir:       tmpDir,
+				PRDPath:        prdPath,
+				FailFastOnLint: tt.failFast,
+			}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+
+			err := runner.Run(context.Background())
+			assert.Len(t, prompts, tt.wantCalls)
+			assert.Equal(t, tt.failFast, strings.Contains(prompts[2], "SNAP-CHECKS: PASS"), "result line is only requested with fail-fast")
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, workflow.ErrChecksFailed)
+			assert.Contains(t, ui.StripColors(buf.String()), "Step 3/10 reported failing checks")
+
+			saved, loadErr := stateManager.Load()
+			require.NoError(t, loadErr)
+			assert.Equal(t, 3, saved.CurrentStep, "resume re-runs the failing lint step")
+			assert.Contains(t, saved.LastError, "lint/test checks failed")
+			require.NotNil(t, saved.LastFailure)
+			assert.Equal(t, 3, saved.LastFailure.Step)
+			assert.Contains(t, saved.LastFailure.Output, "SNAP-CHECKS: FAIL")
+		})
+	}
+}
+
+func TestRunner_TasksGlob(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "story-10-export.md"), []byte("# Export"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "story-2-login.md"), []byte("# Login"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Ignored"), 0o600))
+
+	// Task files in the order prompts first mention them.
+	var referenced []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			prompt := args[len(args)-1]
+			for _, name := range []string{"story-2-login.md", "story-10-export.md", "TASK1.md"} {
+				if strings.Contains(prompt, name) && !slices.Contains(referenced, name) {
+					referenced = append(referenced, name)
+				}
+			}
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:  tmpDir,
+		PRDPath:   prdPath,
+		TasksGlob: "story-*.md",
+	}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(&buf))
+
+	require.NoError(t, runner.Run(context.Background()))
+	assert.Equal(t, []string{"story-2-login.md", "story-10-export.md"}, referenced, "matching tasks run in numeric order")
+	assert.Contains(t, buf.String(), "story-10-export")
+
+	empty := t.TempDir()
+	runner = workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:  empty,
+		TasksGlob: "story-*.md",
+	}, workflow.WithStateManager(state.NewManagerWithDir(empty)), workflow.WithRunnerOutput(io.Discard))
+	err := runner.Run(context.Background())
+	assert.ErrorContains(t, err, "no task files matching story-*.md found in "+empty)
+}
+
+func TestRunner_RecordsStepFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, w io.Writer, _ model.Type, _ ...string) error {
+			calls++
+			if calls == 4 {
+				fmt.Fprintf(w, "%s\nreviewer crashed\n", strings.Repeat("x", 3000))
+				return errors.New("exit status 1")
+			}
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+
+	err := runner.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step 4/10 "Code review" failed: exit status 1`)
+
+	var stepErr *workflow.StepError
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, 4, stepErr.Failure.Step)
+	assert.Equal(t, "Code review", stepErr.Failure.StepName)
+	assert.Equal(t, string(model.Thinking), stepErr.Failure.Model)
+	assert.Contains(t, stepErr.Failure.Output, "reviewer crashed")
+	assert.LessOrEqual(t, len(stepErr.Failure.Output), 2000, "output is truncated to its tail")
+
+	saved, loadErr := stateManager.Load()
+	require.NoError(t, loadErr)
+	require.NotNil(t, saved.LastFailure)
+	assert.Equal(t, stepErr.Failure, *saved.LastFailure)
+	assert.Contains(t, saved.Summary(func(n int) string { return workflow.StepName(nil, n) }), "TASK1 failed at step 4/10 (Code review)")
+}
+
+func TestRunner_StepRetries(t *testing.T) {
+	setup := func(t *testing.T) (string, string, *state.Manager) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		prdPath := filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+		return tmpDir, prdPath, state.NewManagerWithDir(tmpDir)
+	}
+
+	t.Run("retries a transient failure", func(t *testing.T) {
+		tmpDir, prdPath, stateManager := setup(t)
+		calls := 0
+		mockExec := &MockExecutor{
+			runFunc: func(context.Context, io.Writer, model.Type, ...string) error {
+				calls++
+				if calls == 2 || calls == 3 {
+					return errors.New("429 rate limited")
+				}
+				return nil
+			},
+		}
+
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir:       tmpDir,
+			PRDPath:        prdPath,
+			MaxStepRetries: 2,
+			RetryBackoff:   time.Millisecond,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+		require.NoError(t, runner.Run(context.Background()))
+
+		assert.Equal(t, 12, calls, "step 2 ran three times")
+		output := ui.StripColors(buf.String())
+		assert.Contains(t, output, "retrying step 2 (attempt 2/3)")
+		assert.Contains(t, output, "retrying step 2 (attempt 3/3)")
+		assert.Contains(t, output, "All tasks implemented!")
+	})
+
+	t.Run("saves the error once retries are exhausted", func(t *testing.T) {
+		tmpDir, prdPath, stateManager := setup(t)
+		calls := 0
+		mockExec := &MockExecutor{
+			runFunc: func(context.Context, io.Writer, model.Type, ...string) error {
+				calls++
+				if calls >= 2 {
+					return errors.New("503 overloaded")
+				}
+				return nil
+			},
+		}
+
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir:       tmpDir,
+			PRDPath:        prdPath,
+			MaxStepRetries: 1,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+		err := runner.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503 overloaded")
+
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, 1, strings.Count(ui.StripColors(buf.String()), "retrying step 2"))
+		saved, loadErr := stateManager.Load()
+		require.NoError(t, loadErr)
+		assert.Equal(t, 2, saved.CurrentStep)
+		assert.Contains(t, saved.LastError, "503 overloaded")
+	})
+
+	t.Run("cancellation stops the retries", func(t *testing.T) {
+		tmpDir, prdPath, stateManager := setup(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		calls := 0
+		mockExec := &MockExecutor{
+			runFunc: func(context.Context, io.Writer, model.Type, ...string) error {
+				calls++
+				cancel()
+				return errors.New("429 rate limited")
+			},
+		}
+
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir:       tmpDir,
+			PRDPath:        prdPath,
+			MaxStepRetries: 5,
+			RetryBackoff:   time.Hour,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+		err := runner.Run(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestRunner_StepTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	calls := 0
+	mockExec := &MockExecutor{
+		runFunc: func(ctx context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			calls++
+			if calls == 2 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:    tmpDir,
+		PRDPath:     prdPath,
+		StepTimeout: 20 * time.Millisecond,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+
+	err := runner.Run(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, workflow.ErrStepTimeout)
+	assert.ErrorIs(t, err, workflow.ErrProvider)
+	assert.NotErrorIs(t, err, context.Canceled)
+	assert.Contains(t, err.Error(), `step 2/10 "Ensure completeness" failed: step timed out after 20ms`)
+	assert.Contains(t, ui.StripColors(buf.String()), "Step failed")
+
+	// The step stays current, so resuming re-runs it.
+	saved, loadErr := stateManager.Load()
+	require.NoError(t, loadErr)
+	assert.Equal(t, 2, saved.CurrentStep)
+	assert.Contains(t, saved.LastError, "step timed out after 20ms")
+}
+
+func TestRunner_ReattachesToCIMonitoring(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	interrupted := state.NewState(tmpDir, "", workflow.StepCount(nil))
+	interrupted.CompletedTaskIDs = []string{"TASK1"}
+	interrupted.MonitoringCI = true
+	require.NoError(t, stateManager.Save(interrupted))
+
+	mockExec := &MockExecutor{
+		runFunc: func(context.Context, io.Writer, model.Type, ...string) error {
+			t.Fatal("no workflow step should run")
+			return nil
+		},
+	}
+
+	t.Run("rejects a resume step", func(t *testing.T) {
+		runner := workflow.NewRunner(mockExec, workflow.Config{TasksDir: tmpDir, ResumeStep: 3},
+			workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+		err := runner.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "CI monitoring is in progress")
+	})
+
+	t.Run("goes straight to post-run", func(t *testing.T) {
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(mockExec, workflow.Config{TasksDir: tmpDir},
+			workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+		require.NoError(t, runner.Run(context.Background()))
+
+		output := ui.StripColors(buf.String())
+		assert.Contains(t, output, "reattaching to CI monitoring")
+		assert.Contains(t, output, "No remote configured")
+		assert.NotContains(t, output, "All tasks implemented!")
+		assert.False(t, stateManager.Exists(), "state is cleared once post-run finishes")
+	})
+}
+
+func TestRunner_FailedPostrunStopsReattaching(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir) // Not a git repository, so the push fails
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	interrupted := state.NewState(tmpDir, "", workflow.StepCount(nil))
+	interrupted.CompletedTaskIDs = []string{"TASK1"}
+	interrupted.MonitoringCI = true
+	require.NoError(t, stateManager.Save(interrupted))
+
+	runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{TasksDir: tmpDir, RemoteURL: "https://example.com/repo.git"},
+		workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+	err := runner.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "push failed")
+
+	saved, loadErr := stateManager.Load()
+	require.NoError(t, loadErr)
+	assert.False(t, saved.MonitoringCI, "the next run goes through post-run from the start")
+	assert.Equal(t, []string{"TASK1"}, saved.CompletedTaskIDs)
+}
+
+func TestRunner_CheckCommandsInjectedIntoLintStep(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var captured []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			captured = append(captured, args[len(args)-1])
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:    tmpDir,
+		PRDPath:     prdPath,
+		LintCommand: "make lint",
+		TestCommand: "make test-unit",
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+
+	require.NoError(t, runner.Run(context.Background()))
+	require.Len(t, captured, 10)
+
+	// Steps 3 and 6 lint/test.
+	for _, i := range []int{2, 5} {
+		assert.Contains(t, captured[i], "- Lint: `make lint`", "step %d", i+1)
+		assert.Contains(t, captured[i], "- Test: `make test-unit`", "step %d", i+1)
+	}
+	assert.Contains(t, ui.StripColors(buf.String()), "Checks: lint `make lint`, test `make test-unit`")
+}
+
+func TestRunner_DescriptionFailureIsGraceful(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var buf bytes.Buffer
+	callCount := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			callCount++
+			return nil
+		},
+		// Fail the description generation call (Fast model).
+		describeFunc: func(_ context.Context, _ io.Writer, mt model.Type, _ string) error {
+			assert.Equal(t, model.Fast, mt, "description call should use Fast model")
+			return errors.New("LLM unavailable")
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+		Describe: true,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+
+	err := runner.Run(context.Background())
+	assert.NoError(t, err, "workflow should complete despite description generation failure")
+
+	output := buf.String()
+	stripped := ui.StripColors(output)
+
+	// Header should still appear with task label but no description line.
+	assert.Contains(t, stripped, "▶ Implementing TASK1", "header should be printed")
+	// All 10 steps should execute.
+	assert.Equal(t, 10, callCount, "all 10 workflow steps should execute after description failure")
+	assert.Contains(t, output, "Iteration complete", "iteration should finish")
+}
+
+func TestRunner_DescribeDisabled_SkipsPreStep(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var buf bytes.Buffer
+	var captured []string
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			captured = append(captured, args[len(args)-1])
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+
+	err := runner.Run(context.Background())
+	assert.NoError(t, err)
+
+	// Only the 10 workflow steps run; the first call is the implement step.
+	require.Len(t, captured, 10, "no description call unless Describe is set")
+	assert.Contains(t, captured[0], "TASK1")
+
+	stripped := ui.StripColors(buf.String())
+	assert.Contains(t, stripped, "▶ Implementing TASK1", "header should be printed")
+	assert.Contains(t, buf.String(), "Iteration complete", "iteration should finish")
+}
+
+func TestRunner_DescriptionModelAndMaxBytes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1\nKEEP"+strings.Repeat("x", 100)+"DROPPED"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	var descModel model.Type
+	var descPrompt string
+	mockExec := &MockExecutor{
+		describeFunc: func(_ context.Context, _ io.Writer, mt model.Type, prompt string) error {
+			descModel = mt
+			descPrompt = prompt
+			return nil
+		},
+	}
+
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir:            tmpDir,
+		PRDPath:             prdPath,
+		Describe:            true,
+		DescriptionModel:    model.Thinking,
+		DescriptionMaxBytes: 20,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard))
+
+	err := runner.Run(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, model.Thinking, descModel)
+	assert.Contains(t, descPrompt, "KEEP")
+	assert.NotContains(t, descPrompt, "DROPPED")
+}
+
+func TestRunner_DescriptionCache(t *testing.T) {
+	hashOf := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	tests := []struct {
+		name       string
+		cachedFor  string
+		wantCalls  int
+		wantHeader string
+	}{
+		{name: "hit reuses the cached description", cachedFor: "# Task 1", wantCalls: 0, wantHeader: "Cached description"},
+		{name: "task file change regenerates it", cachedFor: "# Task 1 (old)", wantCalls: 1, wantHeader: "Fresh description"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+			stateManager := state.NewManagerWithDir(tmpDir)
+			s := state.NewState(tmpDir, prdPath, 10)
+			s.CurrentTaskID = "TASK1"
+			s.CurrentTaskFile = "TASK1.md"
+			s.CurrentStep = 3
+			s.TaskDescription = "Cached description"
+			s.TaskDescriptionHash = hashOf(tt.cachedFor)
+			require.NoError(t, stateManager.Save(s))
+
+			// Fail the resumed step once the description is settled, so the
+			// saved state shows what was cached.
+			var calls int
+			descriptionDone := make(chan struct{})
+			executor := funcExecutor(func(_ context.Context, w io.Writer, _ model.Type, args ...string) error {
+				if strings.HasPrefix(args[len(args)-1], "Summarize") {
+					defer close(descriptionDone)
+					calls++
+					_, err := io.WriteString(w, "Fresh description")
+					return err
+				}
+				if tt.wantCalls > 0 {
+					<-descriptionDone
+				}
+				return errors.New("step failed")
+			})
+
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(executor, workflow.Config{
+				TasksDir: tmpDir,
+				PRDPath:  prdPath,
+				Describe: true,
+			}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+			require.Error(t, runner.Run(context.Background()))
+
+			assert.Equal(t, tt.wantCalls, calls, "description provider calls")
+			assert.Contains(t, ui.StripColors(buf.String()), tt.wantHeader)
+
+			saved, err := stateManager.Load()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHeader, saved.TaskDescription)
+			assert.Equal(t, hashOf("# Task 1"), saved.TaskDescriptionHash)
+		})
+	}
+}
+
+// funcExecutor is an unsynchronized executor for tests that need the
+// description pre-step and step 1 to run at the same time.
+type funcExecutor func(ctx context.Context, w io.Writer, mt model.Type, args ...string) error
+
+func (f funcExecutor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	return f(ctx, w, mt, args...)
+}
+
+func TestRunner_DescriptionRunsConcurrentlyWithImplement(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	implementStarted := make(chan struct{})
+	descriptionDone := make(chan struct{})
+	var once sync.Once
+	executor := funcExecutor(func(_ context.Context, w io.Writer, _ model.Type, args ...string) error {
+		prompt := args[len(args)-1]
+		switch {
+		case strings.HasPrefix(prompt, "Summarize"):
+			defer close(descriptionDone)
+			// Only returns once the implement step is already running.
+			select {
+			case <-implementStarted:
+			case <-time.After(5 * time.Second):
+				return errors.New("implement step did not start while description was pending")
+			}
+			_, err := io.WriteString(w, "Adds OAuth login")
+			return err
+		case strings.Contains(prompt, "Implement TASK1"):
+			once.Do(func() { close(implementStarted) })
+			<-descriptionDone
+			_, err := io.WriteString(w, "IMPLEMENT-OUTPUT\n")
+			return err
+		}
+		return nil
+	})
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(executor, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+		Describe: true,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+
+	require.NoError(t, runner.Run(context.Background()))
+
+	stripped := ui.StripColors(buf.String())
+	headerIdx := strings.Index(stripped, "Implementing TASK1")
+	descIdx := strings.Index(stripped, "Adds OAuth login")
+	implIdx := strings.Index(stripped, "IMPLEMENT-OUTPUT")
+	require.NotEqual(t, -1, headerIdx)
+	require.NotEqual(t, -1, descIdx, "description should be rendered in the header")
+	require.NotEqual(t, -1, implIdx)
+	assert.Less(t, headerIdx, implIdx, "step 1 output should follow the header")
+	assert.Less(t, descIdx, implIdx, "step 1 output should follow the description")
+	assert.Contains(t, stripped, "Iteration complete")
+}
+
+func TestRunner_DescriptionCancelledWhenFirstStepFinishes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	descriptionCancelled := make(chan struct{})
+	executor := funcExecutor(func(ctx context.Context, w io.Writer, _ model.Type, args ...string) error {
+		prompt := args[len(args)-1]
+		if strings.HasPrefix(prompt, "Summarize") {
+			// Hang until the runner gives up on the description.
+			<-ctx.Done()
+			close(descriptionCancelled)
+			return ctx.Err()
+		}
+		if strings.Contains(prompt, "Implement TASK1") {
+			_, err := io.WriteString(w, "IMPLEMENT-OUTPUT\n")
+			return err
+		}
+		return nil
+	})
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(executor, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+		Describe: true,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+
+	require.NoError(t, runner.Run(context.Background()))
+
+	select {
+	case <-descriptionCancelled:
+	default:
+		t.Fatal("description call should be cancelled once step 1 finishes")
+	}
+
+	stripped := ui.StripColors(buf.String())
+	headerIdx := strings.Index(stripped, "Implementing TASK1")
+	implIdx := strings.Index(stripped, "IMPLEMENT-OUTPUT")
+	require.NotEqual(t, -1, headerIdx)
+	require.NotEqual(t, -1, implIdx)
+	assert.Less(t, headerIdx, implIdx, "header renders before buffered step 1 output")
+	assert.Contains(t, stripped, "Iteration complete")
+}
+
+func TestRunner_QueuedPromptFailureShownInOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			if strings.Contains(args[len(args)-1], "rename the flag") {
+				return errors.New("provider rate limited")
 			}
 			return nil
 		},
 	}
 
-	runner := workflow.NewRunner(mockExec, workflow.Config{
-		TasksDir: tmpDir,
-		PRDPath:  prdPath,
-	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
-
-	err := runner.Run(context.Background())
-	assert.NoError(t, err, "workflow should complete despite description generation failure")
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+	runner.Queue().Enqueue("rename the flag")
+
+	require.NoError(t, runner.Run(context.Background()))
+
+	stripped := ui.StripColors(buf.String())
+	assert.Contains(t, stripped, "✗ Queued: rename the flag", "failed directive should be reported in the main output")
+	assert.Contains(t, stripped, "Iteration complete")
+}
+
+func TestRunner_SnapshotErrorsAreNonFatal(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// No git init — snapshotter will fail on every Capture call.
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	},
+		workflow.WithStateManager(stateManager),
+		workflow.WithRunnerOutput(&buf),
+		workflow.WithSnapshotter(snapshot.New(tmpDir)), // not a git repo
+	)
+
+	err := runner.Run(context.Background())
+	assert.NoError(t, err, "workflow should complete despite snapshot failures")
+
+	output := buf.String()
+	assert.Contains(t, output, "snapshot skipped", "snapshot errors should be logged")
+	assert.Contains(t, output, "Iteration complete", "iteration should finish despite snapshot errors")
+}
+
+func TestRunner_SnapshotsCreatedDuringIteration(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Initialize a real git repo so snapshots can work.
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test",
+			"GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=test",
+			"GIT_COMMITTER_EMAIL=test@test.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	gitRun("init")
+	gitRun("config", "user.email", "test@test.com")
+	gitRun("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# init"), 0o600))
+	gitRun("add", ".")
+	gitRun("commit", "-m", "initial commit")
+
+	// Create PRD and task files.
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	stateManager := state.NewManagerWithDir(tmpDir)
+	//nolint:errcheck // cleanup
+	_ = stateManager.Reset()
+
+	// Mock executor that modifies a file on each step so snapshots have changes.
+	stepCount := 0
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+			stepCount++
+			return os.WriteFile(
+				filepath.Join(tmpDir, "README.md"),
+				[]byte(fmt.Sprintf("# step %d", stepCount)),
+				0o600,
+			)
+		},
+	}
+
+	var buf, events bytes.Buffer
+	runner := workflow.NewRunner(mockExec, workflow.Config{
+		TasksDir: tmpDir,
+		PRDPath:  prdPath,
+	},
+		workflow.WithStateManager(stateManager),
+		workflow.WithRunnerOutput(&buf),
+		workflow.WithSnapshotter(snapshot.New(tmpDir)),
+		workflow.WithEventSink(&events),
+	)
+
+	err := runner.Run(context.Background())
+	assert.NoError(t, err)
+
+	// Check stash list for snap entries.
+	cmd := exec.CommandContext(context.Background(), "git", "stash", "list")
+	cmd.Dir = tmpDir
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	stashEntries := strings.TrimSpace(string(out))
+	assert.NotEmpty(t, stashEntries, "stash list should contain snapshot entries")
+
+	lines := strings.Split(stashEntries, "\n")
+	// Should have 8 snapshots (steps 1-7 and 9; skip steps 8 and 10 which are commit steps with clean trees).
+	assert.Equal(t, 8, len(lines), "expected snapshots for all non-commit steps")
+
+	// All entries should have snap: prefix.
+	for _, line := range lines {
+		assert.Contains(t, line, "snap:")
+	}
+
+	// Output should mention snapshots.
+	output := buf.String()
+	assert.Contains(t, output, "snapshot saved")
+	assert.Equal(t, 8, strings.Count(events.String(), `"type":"snapshot_saved"`))
+}
+
+func TestParseSnapshotMode(t *testing.T) {
+	for in, want := range map[string]workflow.SnapshotMode{
+		"":           workflow.SnapshotEveryStep,
+		"every-step": workflow.SnapshotEveryStep,
+		"On-Failure": workflow.SnapshotOnFailure,
+		" off ":      workflow.SnapshotOff,
+	} {
+		got, err := workflow.ParseSnapshotMode(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := workflow.ParseSnapshotMode("always")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "supported: every-step, on-failure, off")
+}
+
+// initSnapshotRepo creates a git repo in a temp dir with one commit of
+// README.md, for runner tests that capture snapshots.
+func initSnapshotRepo(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = tmpDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	gitRun("init")
+	gitRun("config", "user.email", "test@test.com")
+	gitRun("config", "user.name", "test")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# init"), 0o600))
+	gitRun("add", ".")
+	gitRun("commit", "-m", "initial commit")
+	return tmpDir
+}
+
+func TestRunner_SnapshotMode(t *testing.T) {
+	setup := func(t *testing.T, mode workflow.SnapshotMode, failOn int) (dir string, err error, out, events string) {
+		t.Helper()
+		tmpDir := initSnapshotRepo(t)
+
+		prdPath := filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+		stateManager := state.NewManagerWithDir(tmpDir)
+		//nolint:errcheck // cleanup
+		_ = stateManager.Reset()
+
+		calls := 0
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				calls++
+				if err := os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte(fmt.Sprintf("# step %d", calls)), 0o600); err != nil {
+					return err
+				}
+				if calls == failOn {
+					return errors.New("provider crashed")
+				}
+				return nil
+			},
+		}
+
+		var buf, eventBuf bytes.Buffer
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir:     tmpDir,
+			PRDPath:      prdPath,
+			SnapshotMode: mode,
+		},
+			workflow.WithStateManager(stateManager),
+			workflow.WithRunnerOutput(&buf),
+			workflow.WithSnapshotter(snapshot.New(tmpDir)),
+			workflow.WithEventSink(&eventBuf),
+		)
+		err = runner.Run(context.Background())
+		return tmpDir, err, ui.StripColors(buf.String()), eventBuf.String()
+	}
+	stashes := func(t *testing.T, dir string) []snapshot.Entry {
+		t.Helper()
+		entries, err := snapshot.New(dir).List(context.Background(), snapshot.Filter{})
+		require.NoError(t, err)
+		return entries
+	}
+
+	t.Run("on-failure captures the failed step only", func(t *testing.T) {
+		dir, err, out, events := setup(t, workflow.SnapshotOnFailure, 3)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "provider crashed")
+
+		entries := stashes(t, dir)
+		require.Len(t, entries, 1)
+		assert.Equal(t, 3, entries[0].Label.Step)
+		assert.Equal(t, "Lint & test", entries[0].Label.Name)
+		assert.Equal(t, 1, strings.Count(out, "snapshot saved"))
+
+		saved := strings.Index(events, `"type":"snapshot_saved"`)
+		failed := strings.Index(events, `"type":"step_failed"`)
+		require.NotEqual(t, -1, saved)
+		assert.Less(t, saved, failed, "snapshot is taken before the failure propagates")
+	})
 
-	output := buf.String()
-	stripped := ui.StripColors(output)
+	t.Run("on-failure captures nothing when every step succeeds", func(t *testing.T) {
+		dir, err, out, _ := setup(t, workflow.SnapshotOnFailure, 0)
+		require.NoError(t, err)
+		assert.Empty(t, stashes(t, dir))
+		assert.NotContains(t, out, "snapshot saved")
+	})
 
-	// Header should still appear with task label but no description line.
-	assert.Contains(t, stripped, "▶ Implementing TASK1", "header should be printed")
-	// All 10 steps should execute (callCount = 1 desc + 10 steps = 11).
-	assert.Equal(t, 11, callCount, "all 10 workflow steps should execute after description failure")
-	assert.Contains(t, output, "Iteration complete", "iteration should finish")
+	t.Run("off ignores the snapshotter", func(t *testing.T) {
+		dir, err, out, _ := setup(t, workflow.SnapshotOff, 3)
+		require.Error(t, err)
+		assert.Empty(t, stashes(t, dir))
+		assert.NotContains(t, out, "snapshot saved")
+	})
 }
 
-func TestRunner_SnapshotErrorsAreNonFatal(t *testing.T) {
-	tmpDir := t.TempDir()
+func TestRunner_SnapshotRetention(t *testing.T) {
+	tmpDir := initSnapshotRepo(t)
 
-	// No git init — snapshotter will fail on every Capture call.
 	prdPath := filepath.Join(tmpDir, "PRD.md")
 	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
 	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK2.md"), []byte("# Task 2"), 0o600))
 
 	stateManager := state.NewManagerWithDir(tmpDir)
 	//nolint:errcheck // cleanup
 	_ = stateManager.Reset()
 
+	calls := 0
 	mockExec := &MockExecutor{
 		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
-			return nil
+			calls++
+			return os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte(fmt.Sprintf("# step %d", calls)), 0o600)
 		},
 	}
 
 	var buf bytes.Buffer
 	runner := workflow.NewRunner(mockExec, workflow.Config{
-		TasksDir: tmpDir,
-		PRDPath:  prdPath,
+		TasksDir:          tmpDir,
+		PRDPath:           prdPath,
+		SnapshotRetention: 3,
 	},
 		workflow.WithStateManager(stateManager),
 		workflow.WithRunnerOutput(&buf),
-		workflow.WithSnapshotter(snapshot.New(tmpDir)), // not a git repo
+		workflow.WithSnapshotter(snapshot.New(tmpDir)),
 	)
+	require.NoError(t, runner.Run(context.Background()))
 
-	err := runner.Run(context.Background())
-	assert.NoError(t, err, "workflow should complete despite snapshot failures")
+	entries, err := snapshot.New(tmpDir).List(context.Background(), snapshot.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	for _, e := range entries {
+		assert.Equal(t, "TASK2", e.Label.TaskID, "the newest snapshots are kept")
+	}
 
-	output := buf.String()
-	assert.Contains(t, output, "snapshot skipped", "snapshot errors should be logged")
-	assert.Contains(t, output, "Iteration complete", "iteration should finish despite snapshot errors")
+	// Each task captures 8 snapshots: the first prune drops 5, the second 8.
+	stripped := ui.StripColors(buf.String())
+	assert.Contains(t, stripped, "pruned 5 old snapshot(s), keeping the newest 3")
+	assert.Contains(t, stripped, "pruned 8 old snapshot(s), keeping the newest 3")
 }
 
-func TestRunner_SnapshotsCreatedDuringIteration(t *testing.T) {
+func TestRunner_RunIteration(t *testing.T) {
 	tmpDir := t.TempDir()
-
-	// Initialize a real git repo so snapshots can work.
-	gitRun := func(args ...string) {
-		t.Helper()
-		cmd := exec.CommandContext(context.Background(), "git", args...)
-		cmd.Dir = tmpDir
-		cmd.Env = append(os.Environ(),
-			"GIT_AUTHOR_NAME=test",
-			"GIT_AUTHOR_EMAIL=test@test.com",
-			"GIT_COMMITTER_NAME=test",
-			"GIT_COMMITTER_EMAIL=test@test.com",
-		)
-		out, err := cmd.CombinedOutput()
-		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
-	}
-	gitRun("init")
-	gitRun("config", "user.email", "test@test.com")
-	gitRun("config", "user.name", "test")
-	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# init"), 0o600))
-	gitRun("add", ".")
-	gitRun("commit", "-m", "initial commit")
-
-	// Create PRD and task files.
 	prdPath := filepath.Join(tmpDir, "PRD.md")
 	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
-	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
-
-	stateManager := state.NewManagerWithDir(tmpDir)
-	//nolint:errcheck // cleanup
-	_ = stateManager.Reset()
+	for _, name := range []string{"TASK1.md", "TASK2.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("# "+name), 0o600))
+	}
 
-	// Mock executor that modifies a file on each step so snapshots have changes.
-	stepCount := 0
+	var prompts []string
 	mockExec := &MockExecutor{
-		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
-			stepCount++
-			return os.WriteFile(
-				filepath.Join(tmpDir, "README.md"),
-				[]byte(fmt.Sprintf("# step %d", stepCount)),
-				0o600,
-			)
+		runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+			prompts = append(prompts, args[len(args)-1])
+			return nil
 		},
 	}
+	// The test command is detected from go.mod in the working directory;
+	// the lint command comes from the Config override.
+	t.Chdir(tmpDir)
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/app\n"), 0o600))
 
+	stateManager := state.NewManagerWithDir(tmpDir)
 	var buf bytes.Buffer
 	runner := workflow.NewRunner(mockExec, workflow.Config{
-		TasksDir: tmpDir,
-		PRDPath:  prdPath,
-	},
-		workflow.WithStateManager(stateManager),
-		workflow.WithRunnerOutput(&buf),
-		workflow.WithSnapshotter(snapshot.New(tmpDir)),
-	)
+		TasksDir:    tmpDir,
+		PRDPath:     prdPath,
+		LintCommand: "make check-style",
+	}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf), workflow.WithoutSignalHandler())
 
-	err := runner.Run(context.Background())
-	assert.NoError(t, err)
+	workflowState := state.NewState(tmpDir, prdPath, 0)
+	workflowState.CurrentTaskID = "TASK2"
+	workflowState.CurrentTaskFile = "TASK2.md"
 
-	// Check stash list for snap entries.
-	cmd := exec.CommandContext(context.Background(), "git", "stash", "list")
-	cmd.Dir = tmpDir
-	out, err := cmd.Output()
+	complete, err := runner.RunIteration(context.Background(), workflowState)
 	require.NoError(t, err)
-	stashEntries := strings.TrimSpace(string(out))
-	assert.NotEmpty(t, stashEntries, "stash list should contain snapshot entries")
+	assert.True(t, complete)
+	require.Len(t, prompts, 10, "one pass over the default steps")
+	assert.Contains(t, prompts[0], "TASK2")
+	assert.Contains(t, prompts[2], "make check-style", "lint-and-test step gets the lint override")
+	assert.Contains(t, prompts[2], "go test ./...", "lint-and-test step gets the detected test command")
+	assert.Equal(t, []string{"TASK2"}, workflowState.CompletedTaskIDs)
+
+	// RunIteration doesn't select the next task; Run does.
+	assert.NotContains(t, ui.StripColors(buf.String()), "Implementing TASK1")
+}
 
-	lines := strings.Split(stashEntries, "\n")
-	// Should have 8 snapshots (steps 1-7 and 9; skip steps 8 and 10 which are commit steps with clean trees).
-	assert.Equal(t, 8, len(lines), "expected snapshots for all non-commit steps")
+func TestRunner_OnlyTask(t *testing.T) {
+	setup := func(t *testing.T) (string, *state.Manager) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "PRD.md"), []byte("# PRD"), 0o600))
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("TASK%d.md", i)), []byte("# Task"), 0o600))
+		}
+		return tmpDir, state.NewManagerWithDir(tmpDir)
+	}
+	run := func(tmpDir string, stateManager *state.Manager, only string, calls *int) (string, error) {
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(&MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				*calls++
+				return nil
+			},
+		}, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  filepath.Join(tmpDir, "PRD.md"),
+			OnlyTask: only,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+		err := runner.Run(context.Background())
+		return ui.StripColors(buf.String()), err
+	}
 
-	// All entries should have snap: prefix.
-	for _, line := range lines {
-		assert.Contains(t, line, "snap:")
+	t.Run("runs the named task and stops", func(t *testing.T) {
+		tmpDir, stateManager := setup(t)
+		calls := 0
+		output, err := run(tmpDir, stateManager, "TASK2", &calls)
+		require.NoError(t, err)
+		assert.Equal(t, 10, calls)
+		assert.Contains(t, output, "Implementing TASK2")
+		assert.Contains(t, output, "Finished TASK2; not continuing to other tasks")
+		assert.NotContains(t, output, "All tasks implemented")
+
+		saved, err := stateManager.Load()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"TASK2"}, saved.CompletedTaskIDs)
+		assert.Empty(t, saved.CurrentTaskID)
+	})
+
+	t.Run("re-runs a completed task", func(t *testing.T) {
+		tmpDir, stateManager := setup(t)
+		s := state.NewState(tmpDir, filepath.Join(tmpDir, "PRD.md"), 10)
+		s.CompletedTaskIDs = []string{"TASK1", "TASK2"}
+		require.NoError(t, stateManager.Save(s))
+
+		calls := 0
+		output, err := run(tmpDir, stateManager, "task1", &calls)
+		require.NoError(t, err)
+		assert.Equal(t, 10, calls)
+		assert.Contains(t, output, "Implementing TASK1")
+
+		saved, err := stateManager.Load()
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"TASK1", "TASK2"}, saved.CompletedTaskIDs)
+	})
+
+	t.Run("missing task", func(t *testing.T) {
+		tmpDir, stateManager := setup(t)
+		calls := 0
+		_, err := run(tmpDir, stateManager, "TASK9", &calls)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "task TASK9 not found in "+tmpDir)
+		assert.Contains(t, err.Error(), "use --fresh to reset")
+		assert.Zero(t, calls)
+	})
+
+	t.Run("another task in progress", func(t *testing.T) {
+		tmpDir, stateManager := setup(t)
+		s := state.NewState(tmpDir, filepath.Join(tmpDir, "PRD.md"), 10)
+		s.CurrentTaskID = "TASK1"
+		s.CurrentTaskFile = "TASK1.md"
+		s.CurrentStep = 4
+		require.NoError(t, stateManager.Save(s))
+
+		calls := 0
+		_, err := run(tmpDir, stateManager, "TASK3", &calls)
+		require.Error(t, err)
+		assert.Equal(t, "cannot run TASK3: TASK1 is in progress; finish it with snap resume first, or use --fresh to reset", err.Error())
+		assert.Zero(t, calls)
+	})
+}
+
+func TestRunner_MetricsFile(t *testing.T) {
+	setup := func(t *testing.T) (tmpDir, prdPath, metricsPath string) {
+		t.Helper()
+		tmpDir = t.TempDir()
+		prdPath = filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+		return tmpDir, prdPath, filepath.Join(tmpDir, "metrics.json")
+	}
+	readMetrics := func(t *testing.T, path string) []workflow.StepMetric {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var metrics []workflow.StepMetric
+		require.NoError(t, json.Unmarshal(data, &metrics))
+		return metrics
 	}
 
-	// Output should mention snapshots.
-	output := buf.String()
-	assert.Contains(t, output, "snapshot saved")
+	t.Run("one iteration records every step", func(t *testing.T) {
+		tmpDir, prdPath, metricsPath := setup(t)
+		runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(io.Discard),
+			workflow.WithMetricsFile(metricsPath))
+		require.NoError(t, runner.Run(context.Background()))
+
+		metrics := readMetrics(t, metricsPath)
+		require.Len(t, metrics, 10)
+		for i, m := range metrics {
+			assert.Equal(t, "TASK1", m.TaskID)
+			assert.Equal(t, i+1, m.StepNumber)
+			assert.GreaterOrEqual(t, m.DurationMS, int64(0))
+			assert.NotEmpty(t, m.Model)
+			assert.False(t, m.Failed)
+		}
+		assert.Equal(t, "Implement TASK1", metrics[0].StepName)
+		assert.Equal(t, string(model.Thinking), metrics[0].Model)
+		assert.Equal(t, "Lint & test", metrics[2].StepName)
+	})
+
+	t.Run("merges with existing entries and records a failed step", func(t *testing.T) {
+		tmpDir, prdPath, metricsPath := setup(t)
+		require.NoError(t, os.WriteFile(metricsPath,
+			[]byte(`[{"task_id":"TASK0","step_name":"Implement","step_number":1,"duration_ms":42,"model":"opus"}]`), 0o600))
+		calls := 0
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				calls++
+				if calls == 3 {
+					return errors.New("lint exploded")
+				}
+				return nil
+			},
+		}
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(io.Discard),
+			workflow.WithMetricsFile(metricsPath))
+		require.Error(t, runner.Run(context.Background()))
+
+		metrics := readMetrics(t, metricsPath)
+		require.Len(t, metrics, 4)
+		assert.Equal(t, workflow.StepMetric{TaskID: "TASK0", StepName: "Implement", StepNumber: 1, DurationMS: 42, Model: "opus"}, metrics[0])
+		assert.Equal(t, 3, metrics[3].StepNumber)
+		assert.True(t, metrics[3].Failed)
+	})
+
+	t.Run("unreadable file is a warning", func(t *testing.T) {
+		tmpDir, prdPath, metricsPath := setup(t)
+		require.NoError(t, os.WriteFile(metricsPath, []byte("not json"), 0o600))
+		var buf bytes.Buffer
+		runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(&buf),
+			workflow.WithMetricsFile(metricsPath))
+		require.NoError(t, runner.Run(context.Background()))
+		assert.Contains(t, ui.StripColors(buf.String()), "Warning: failed to save step metrics: metrics file "+metricsPath+" is not a JSON array of steps")
+	})
+}
+
+// decodeEvents parses a newline-delimited JSON event stream.
+func decodeEvents(t *testing.T, data string) []workflow.Event {
+	t.Helper()
+	var events []workflow.Event
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var e workflow.Event
+		require.NoError(t, json.Unmarshal([]byte(line), &e), "line: %s", line)
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestRunner_EventSink(t *testing.T) {
+	setup := func(t *testing.T) (string, string) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		prdPath := filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+		return tmpDir, prdPath
+	}
+
+	t.Run("completed task", func(t *testing.T) {
+		tmpDir, prdPath := setup(t)
+		var buf, sink bytes.Buffer
+		runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(&buf), workflow.WithEventSink(&sink))
+		require.NoError(t, runner.Run(context.Background()))
+
+		events := decodeEvents(t, sink.String())
+		require.Len(t, events, 23, "run_started, task_selected, started and completed per step, iteration_complete")
+		assert.Equal(t, workflow.EventRunStarted, events[0].Type)
+		assert.Equal(t, &ui.StartupSummary{DisplayName: tmpDir, TaskCount: 1, Action: "starting TASK1"}, events[0].Summary)
+		assert.Equal(t, workflow.Event{Type: workflow.EventTaskSelected, Time: events[1].Time, TaskID: "TASK1", Step: 1, TotalSteps: 10}, events[1])
+		assert.Equal(t, workflow.EventStepStarted, events[2].Type)
+		assert.Equal(t, "Implement TASK1", events[2].StepName)
+		assert.Equal(t, workflow.EventStepCompleted, events[9].Type)
+		assert.Equal(t, 4, events[9].Step)
+		assert.Equal(t, "Code review", events[9].StepName)
+		last := events[len(events)-1]
+		assert.Equal(t, workflow.EventIterationComplete, last.Type)
+		assert.Equal(t, "TASK1", last.TaskID)
+		assert.Empty(t, last.Applied)
+		for _, e := range events {
+			assert.False(t, e.Time.IsZero(), "%s has a timestamp", e.Type)
+		}
+		assert.NotContains(t, buf.String(), `"type"`, "events stay out of the display output")
+	})
+
+	t.Run("failed step", func(t *testing.T) {
+		tmpDir, prdPath := setup(t)
+		calls := 0
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				calls++
+				if calls == 3 {
+					return errors.New("lint exploded")
+				}
+				return nil
+			},
+		}
+		var sink bytes.Buffer
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(io.Discard), workflow.WithEventSink(&sink))
+		require.Error(t, runner.Run(context.Background()))
+
+		events := decodeEvents(t, sink.String())
+		last := events[len(events)-1]
+		assert.Equal(t, workflow.EventStepFailed, last.Type)
+		assert.Equal(t, 3, last.Step)
+		assert.Equal(t, 10, last.TotalSteps)
+		assert.Equal(t, "Lint & test", last.StepName)
+		assert.Contains(t, last.Error, "lint exploded")
+		require.NotNil(t, last.Failure)
+		assert.Equal(t, 3, last.Failure.Step)
+		assert.Equal(t, "Lint & test", last.Failure.StepName)
+		assert.Equal(t, "fast", last.Failure.Model)
+	})
+
+	t.Run("queued directives", func(t *testing.T) {
+		tmpDir, prdPath := setup(t)
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, args ...string) error {
+				if strings.Contains(args[len(args)-1], "rename the flag") {
+					return errors.New("provider rate limited")
+				}
+				return nil
+			},
+		}
+		var sink bytes.Buffer
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(state.NewManagerWithDir(tmpDir)), workflow.WithRunnerOutput(io.Discard), workflow.WithEventSink(&sink))
+		runner.Queue().Enqueue("use the existing logger")
+		runner.Queue().Enqueue("rename the flag")
+		require.NoError(t, runner.Run(context.Background()))
+
+		var drained []workflow.Event
+		events := decodeEvents(t, sink.String())
+		for _, e := range events {
+			if e.Type == workflow.EventDirectivesDrained {
+				drained = append(drained, e)
+			}
+		}
+		require.Len(t, drained, 1, "only the drain that ran prompts is reported")
+		assert.Equal(t, "TASK1", drained[0].TaskID)
+		assert.Equal(t, 1, drained[0].Step)
+		require.Len(t, drained[0].Directives, 2)
+		assert.Equal(t, "use the existing logger", drained[0].Directives[0].Prompt)
+		assert.Empty(t, drained[0].Directives[0].Error)
+		assert.Equal(t, "rename the flag", drained[0].Directives[1].Prompt)
+		assert.Contains(t, drained[0].Directives[1].Error, "provider rate limited")
+
+		last := events[len(events)-1]
+		assert.Equal(t, workflow.EventIterationComplete, last.Type)
+		assert.Equal(t, []string{"use the existing logger"}, last.Applied)
+	})
+
+	t.Run("commit steps skipped on a clean tree", func(t *testing.T) {
+		tmpDir, prdPath := setup(t)
+		repoDir := t.TempDir()
+		for _, args := range [][]string{
+			{"init"},
+			{"-c", "user.email=test@test.com", "-c", "user.name=test", "commit", "--allow-empty", "-m", "initial"},
+		} {
+			cmd := exec.CommandContext(context.Background(), "git", args...)
+			cmd.Dir = repoDir
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+		}
+		stateManager := state.NewManagerWithDir(tmpDir)
+		seed := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
+		seed.CurrentTaskID = "TASK1"
+		seed.CurrentTaskFile = "TASK1.md"
+		seed.CurrentStep = 8
+		require.NoError(t, stateManager.Save(seed))
+
+		var sink bytes.Buffer
+		runner := workflow.NewRunner(&MockExecutor{}, workflow.Config{
+			TasksDir: tmpDir,
+			PRDPath:  prdPath,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(io.Discard),
+			workflow.WithWorkTree(snapshot.New(repoDir)), workflow.WithEventSink(&sink))
+		require.NoError(t, runner.Run(context.Background()))
+
+		var skipped []workflow.Event
+		for _, e := range decodeEvents(t, sink.String()) {
+			if e.Type == workflow.EventStepSkipped {
+				skipped = append(skipped, e)
+			}
+		}
+		require.Len(t, skipped, 2)
+		assert.Equal(t, workflow.Event{Type: workflow.EventStepSkipped, Time: skipped[0].Time, TaskID: "TASK1", Step: 8, TotalSteps: 10,
+			StepName: "Commit code", Reason: "nothing to commit"}, skipped[0])
+		assert.Equal(t, 10, skipped[1].Step)
+	})
+}
+
+func TestRunner_ShowDiffAfterImplement(t *testing.T) {
+	tests := []struct {
+		name        string
+		isTTY       bool
+		wantPreview bool
+	}{
+		{name: "TTY prints diff stat", isTTY: true, wantPreview: true},
+		{name: "non-TTY skips preview", isTTY: false, wantPreview: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			gitRun := func(args ...string) {
+				t.Helper()
+				cmd := exec.CommandContext(context.Background(), "git", args...)
+				cmd.Dir = tmpDir
+				out, err := cmd.CombinedOutput()
+				require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+			}
+			gitRun("init")
+			gitRun("config", "user.email", "test@test.com")
+			gitRun("config", "user.name", "test")
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte(".snap/\n"), 0o600))
+			prdPath := filepath.Join(tmpDir, "PRD.md")
+			require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+			gitRun("add", ".")
+			gitRun("commit", "-m", "initial commit")
+
+			stateManager := state.NewManagerWithDir(tmpDir)
+			//nolint:errcheck // cleanup
+			_ = stateManager.Reset()
+
+			// Step 1 edits a tracked file and adds a new one; step 2 stops the run.
+			calls := 0
+			mockExec := &MockExecutor{
+				runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+					calls++
+					if calls > 1 {
+						return errors.New("stop")
+					}
+					require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "util.go"), []byte("package main\n"), 0o600))
+					return os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o600)
+				},
+			}
+
+			var buf bytes.Buffer
+			runner := workflow.NewRunner(mockExec, workflow.Config{
+				TasksDir: tmpDir,
+				PRDPath:  prdPath,
+				IsTTY:    tt.isTTY,
+				Headless: true,
+				ShowDiff: true,
+			},
+				workflow.WithStateManager(stateManager),
+				workflow.WithRunnerOutput(&buf),
+				workflow.WithSnapshotter(snapshot.New(tmpDir)),
+			)
+
+			//nolint:errcheck // stopped on step 2 by design
+			_ = runner.Run(context.Background())
+
+			stripped := ui.StripColors(buf.String())
+			if tt.wantPreview {
+				assert.Contains(t, stripped, "main.go | 2 ++")
+				assert.Contains(t, stripped, "util.go | 1 +", "untracked files count")
+				assert.Contains(t, stripped, "2 files changed")
+			} else {
+				assert.NotContains(t, stripped, "file changed")
+			}
+		})
+	}
 }
 
 func TestRunner_StartupSummary(t *testing.T) {
@@ -984,7 +4185,7 @@ func TestRunner_StartupSummary(t *testing.T) {
 		stateManager := state.NewManagerWithDir(tmpDir)
 		//nolint:errcheck // cleanup
 		_ = stateManager.Reset()
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CompletedTaskIDs = []string{"TASK1"}
 		require.NoError(t, stateManager.Save(seedState))
 
@@ -1099,7 +4300,7 @@ func TestRunner_StartupSummary(t *testing.T) {
 
 		// Pre-seed state: TASK1 active at step 3 (resume scenario).
 		stateManager := state.NewManagerWithDir(tmpDir)
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CurrentTaskID = "TASK1"
 		seedState.CurrentTaskFile = "TASK1.md"
 		seedState.CurrentStep = 3
@@ -1160,6 +4361,41 @@ func TestRunner_StartupSummary(t *testing.T) {
 			"prompt hint should NOT appear when not TTY")
 	})
 
+	t.Run("prompt hint suppressed when headless", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		prdPath := filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+		stateManager := state.NewManagerWithDir(tmpDir)
+		//nolint:errcheck // cleanup
+		_ = stateManager.Reset()
+
+		var buf bytes.Buffer
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				return errors.New("stop")
+			},
+		}
+
+		runner := workflow.NewRunner(mockExec, workflow.Config{
+			TasksDir:     tmpDir,
+			PRDPath:      prdPath,
+			ProviderName: "claude",
+			IsTTY:        true,
+			Headless:     true,
+		}, workflow.WithStateManager(stateManager), workflow.WithRunnerOutput(&buf))
+
+		//nolint:errcheck // testing output, not error
+		_ = runner.Run(context.Background())
+
+		stripped := ui.StripColors(buf.String())
+		assert.NotContains(t, stripped, "Type a directive",
+			"prompt hint should NOT appear in headless mode")
+		assert.Contains(t, stripped, "snap:", "startup summary should still print")
+	})
+
 	t.Run("resume shows summary with resuming action", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -1171,7 +4407,7 @@ func TestRunner_StartupSummary(t *testing.T) {
 
 		// Pre-seed state: TASK2 active at step 5.
 		stateManager := state.NewManagerWithDir(tmpDir)
-		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount())
+		seedState := state.NewState(tmpDir, prdPath, workflow.StepCount(nil))
 		seedState.CurrentTaskID = "TASK2"
 		seedState.CurrentTaskFile = "TASK2.md"
 		seedState.CurrentStep = 5
@@ -1217,7 +4453,7 @@ func TestRunner_CancelledContextReturnsContextCanceled(t *testing.T) {
 		var buf bytes.Buffer
 		mockExec := &MockExecutor{
 			runFunc: func(_ context.Context, _ io.Writer, _ model.Type, _ ...string) error {
-				// Cancel the context during the first executor call (description generation).
+				// Cancel the context during the first executor call (implement step).
 				cancel()
 				return nil
 			},
@@ -1298,3 +4534,21 @@ func TestRunner_CancelledContextReturnsContextCanceled(t *testing.T) {
 		assert.ErrorIs(t, err, context.Canceled)
 	})
 }
+
+func TestConfig_IsHeadless(t *testing.T) {
+	tests := []struct {
+		name   string
+		config workflow.Config
+		want   bool
+	}{
+		{name: "terminal", config: workflow.Config{IsTTY: true}, want: false},
+		{name: "no terminal is always headless", config: workflow.Config{}, want: true},
+		{name: "forced on a terminal", config: workflow.Config{IsTTY: true, Headless: true}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.IsHeadless())
+			assert.Equal(t, tt.want, workflow.NewRunner(&MockExecutor{}, tt.config).Headless())
+		})
+	}
+}
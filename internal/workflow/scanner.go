@@ -17,7 +17,7 @@ var taskFileRegex = regexp.MustCompile(`^TASK(\d+)\.md$`)
 // TaskInfo describes a discovered task file.
 type TaskInfo struct {
 	ID       string // e.g. "TASK1"
-	Number   int    // numeric index extracted from filename
+	Number   int    // numeric index extracted from filename (-1 when a custom glob match has none)
 	Filename string // e.g. "TASK1.md"
 }
 
@@ -61,6 +61,77 @@ func ScanTasks(dir string) ([]TaskInfo, error) {
 	return tasks, nil
 }
 
+// taskOrderRegex extracts the ordering number from a task filename matched by
+// a custom glob: the first run of digits.
+var taskOrderRegex = regexp.MustCompile(`\d+`)
+
+// ValidateTasksGlob checks that glob is a valid filename pattern. Patterns
+// match file names within the tasks directory, so they can't contain a path
+// separator.
+func ValidateTasksGlob(glob string) error {
+	if strings.ContainsRune(glob, '/') || strings.ContainsRune(glob, filepath.Separator) {
+		return fmt.Errorf("invalid tasks glob %q: must match file names, not paths", glob)
+	}
+	if _, err := filepath.Match(glob, ""); err != nil {
+		return fmt.Errorf("invalid tasks glob %q: %w", glob, err)
+	}
+	return nil
+}
+
+// ScanTasksGlob returns the regular files in dir whose names match glob, for
+// teams whose task files aren't named TASK<n>.md. A task's ID is its file
+// name without the extension, so IDs stay stable as files are added. Tasks
+// are ordered by the first number in the name, then by name; names without a
+// number sort last. An empty glob behaves like ScanTasks.
+func ScanTasksGlob(dir, glob string) ([]TaskInfo, error) {
+	if glob == "" {
+		return ScanTasks(dir)
+	}
+	if err := ValidateTasksGlob(glob); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read tasks directory: %w", err)
+	}
+
+	var tasks []TaskInfo
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		name := entry.Name()
+		if ok, _ := filepath.Match(glob, name); !ok { //nolint:errcheck // pattern validated above
+			continue
+		}
+		num := -1
+		if m := taskOrderRegex.FindString(name); m != "" {
+			if n, err := strconv.Atoi(m); err == nil {
+				num = n
+			}
+		}
+		tasks = append(tasks, TaskInfo{
+			ID:       strings.TrimSuffix(name, filepath.Ext(name)),
+			Number:   num,
+			Filename: name,
+		})
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if (a.Number < 0) != (b.Number < 0) {
+			return b.Number < 0
+		}
+		if a.Number != b.Number {
+			return a.Number < b.Number
+		}
+		return a.Filename < b.Filename
+	})
+
+	return tasks, nil
+}
+
 // ScanSingleTask returns a synthetic task list for a single ad hoc task file.
 func ScanSingleTask(path string) ([]TaskInfo, error) {
 	info, err := os.Stat(path)
@@ -90,34 +161,33 @@ func ScanSingleTask(path string) ([]TaskInfo, error) {
 // caseMismatchRegex matches task filenames case-insensitively (e.g., task1.md, Task2.md).
 var caseMismatchRegex = regexp.MustCompile(`(?i)^task(\d+)\.md$`)
 
+// tasksListRefRegex matches task file references in TASKS.md (e.g., "TASK3.md").
+var tasksListRefRegex = regexp.MustCompile(`\bTASK(\d+)\.md\b`)
+
 // prdTaskHeaderRegex matches PRD lines with embedded task headers (e.g., "## TASK1: Feature").
 var prdTaskHeaderRegex = regexp.MustCompile(`^## TASK\d+:`)
 
 // DiagnoseEmptyTaskDir checks for common reasons why ScanTasks returned no results.
-// It returns hint strings for case-mismatched filenames and PRD-embedded task headers.
+// It returns hint strings for case-mismatched filenames, PRD-embedded task headers,
+// and planning that wrote PRD.md and TASKS.md but stopped before the task files.
 // This function only reads files, never modifies them.
 func DiagnoseEmptyTaskDir(dir string) []string {
-	var hints []string
-
-	entries, err := os.ReadDir(dir)
-	if err != nil {
+	if _, err := os.ReadDir(dir); err != nil {
 		return nil
 	}
 
 	// Check 1: Case-insensitive scan for task files that don't match strict pattern.
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		if caseMismatchRegex.MatchString(name) && !taskFileRegex.MatchString(name) {
-			matches := caseMismatchRegex.FindStringSubmatch(name)
-			correctName := "TASK" + matches[1] + ".md"
-			hints = append(hints, fmt.Sprintf("Found: %s (rename to %s)", name, correctName))
+	hints := MisnamedTaskFiles(dir)
+
+	// Check 2: Planning interrupted after TASKS.md but before the TASK files.
+	// Skipped when misnamed task files exist; the rename hint covers that.
+	if len(hints) == 0 {
+		if hint := diagnoseInterruptedPlanning(dir); hint != "" {
+			hints = append(hints, hint)
 		}
 	}
 
-	// Check 2: Scan PRD.md for embedded task headers.
+	// Check 3: Scan PRD.md for embedded task headers.
 	prdPath := filepath.Join(dir, "PRD.md")
 	if f, err := os.Open(prdPath); err == nil {
 		defer f.Close()
@@ -133,6 +203,55 @@ func DiagnoseEmptyTaskDir(dir string) []string {
 	return hints
 }
 
+// MisnamedTaskFiles returns a rename hint for each file in dir that looks like
+// a task file but doesn't match the TASK<n>.md pattern (e.g., task1.md), which
+// ScanTasks would skip.
+func MisnamedTaskFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var hints []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if caseMismatchRegex.MatchString(name) && !taskFileRegex.MatchString(name) {
+			matches := caseMismatchRegex.FindStringSubmatch(name)
+			correctName := "TASK" + matches[1] + ".md"
+			hints = append(hints, fmt.Sprintf("Found: %s (rename to %s)", name, correctName))
+		}
+	}
+	return hints
+}
+
+// diagnoseInterruptedPlanning returns a hint when PRD.md exists and TASKS.md
+// lists task files, none of which are on disk. Returns "" otherwise.
+func diagnoseInterruptedPlanning(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, "PRD.md")); err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "TASKS.md"))
+	if err != nil {
+		return ""
+	}
+	listed := make(map[string]bool)
+	for _, m := range tasksListRefRegex.FindAllStringSubmatch(string(data), -1) {
+		listed[m[1]] = true
+	}
+	if len(listed) == 0 {
+		return ""
+	}
+	noun := "tasks"
+	if len(listed) == 1 {
+		noun = "task"
+	}
+	return fmt.Sprintf("TASKS.md lists %d %s but no TASK files were generated — planning was likely interrupted.\n"+
+		"Re-run snap plan for this session and choose to clean up and re-plan to regenerate them.", len(listed), noun)
+}
+
 // FormatTaskDirError builds a user-facing error message for empty task directory.
 // It follows the DESIGN.md error pattern: "Error: <what>", context, hints, fix.
 func FormatTaskDirError(dir string, hints []string) string {
@@ -114,6 +114,51 @@ func TestScanTasks(t *testing.T) {
 	})
 }
 
+func TestScanTasksGlob(t *testing.T) {
+	t.Run("orders matches by number, then name", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "story-10-export.md", "")
+		createFile(t, dir, "story-2-login.md", "")
+		createFile(t, dir, "story-backlog.md", "")
+		createFile(t, dir, "story-2-audit.md", "")
+		createFile(t, dir, "TASK1.md", "")
+		createFile(t, dir, "PRD.md", "")
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "story-1.md"), 0o755))
+
+		tasks, err := ScanTasksGlob(dir, "story-*.md")
+		require.NoError(t, err)
+		assert.Equal(t, []TaskInfo{
+			{ID: "story-2-audit", Number: 2, Filename: "story-2-audit.md"},
+			{ID: "story-2-login", Number: 2, Filename: "story-2-login.md"},
+			{ID: "story-10-export", Number: 10, Filename: "story-10-export.md"},
+			{ID: "story-backlog", Number: -1, Filename: "story-backlog.md"},
+		}, tasks)
+
+		next := SelectNextTask(tasks, []string{"story-2-audit"})
+		require.NotNil(t, next)
+		assert.Equal(t, "story-2-login", next.ID, "IDs derived from names select stably")
+	})
+
+	t.Run("empty glob scans TASK<n>.md", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "TASK2.md", "")
+		createFile(t, dir, "story-1.md", "")
+
+		tasks, err := ScanTasksGlob(dir, "")
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "TASK2", tasks[0].ID)
+	})
+
+	t.Run("rejects invalid patterns", func(t *testing.T) {
+		_, err := ScanTasksGlob(t.TempDir(), "story-[.md")
+		assert.ErrorContains(t, err, `invalid tasks glob "story-[.md"`)
+
+		assert.ErrorContains(t, ValidateTasksGlob("stories/*.md"), "must match file names")
+		assert.NoError(t, ValidateTasksGlob("*.task.md"))
+	})
+}
+
 func TestSelectNextTask(t *testing.T) {
 	t.Run("selects first task when none completed", func(t *testing.T) {
 		tasks := []TaskInfo{
@@ -200,6 +245,39 @@ func TestDiagnoseEmptyTaskDir(t *testing.T) {
 		assert.Contains(t, hints[0], "TASK2.md")
 	})
 
+	t.Run("detects planning interrupted before task files", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "PRD.md", "# My PRD\n")
+		createFile(t, dir, "TASKS.md", "## G. Task List\n\n1. `TASK1.md` — Login\n2. `TASK2.md` — Logout\n\nTASK1.md blocks TASK2.md\n")
+
+		hints := DiagnoseEmptyTaskDir(dir)
+		require.Len(t, hints, 1)
+		assert.Contains(t, hints[0], "TASKS.md lists 2 tasks but no TASK files were generated")
+		assert.Contains(t, hints[0], "snap plan")
+	})
+
+	t.Run("no interrupted-planning hint without PRD or task references", func(t *testing.T) {
+		noPRD := t.TempDir()
+		createFile(t, noPRD, "TASKS.md", "1. `TASK1.md` — Login\n")
+		assert.Empty(t, DiagnoseEmptyTaskDir(noPRD))
+
+		noRefs := t.TempDir()
+		createFile(t, noRefs, "PRD.md", "# My PRD\n")
+		createFile(t, noRefs, "TASKS.md", "## G. Task List\n\nTBD\n")
+		assert.Empty(t, DiagnoseEmptyTaskDir(noRefs))
+	})
+
+	t.Run("rename hint takes precedence over interrupted planning", func(t *testing.T) {
+		dir := t.TempDir()
+		createFile(t, dir, "PRD.md", "# My PRD\n")
+		createFile(t, dir, "TASKS.md", "1. `TASK1.md` — Login\n")
+		createFile(t, dir, "task1.md", "task 1")
+
+		hints := DiagnoseEmptyTaskDir(dir)
+		require.Len(t, hints, 1)
+		assert.Contains(t, hints[0], "rename to TASK1.md")
+	})
+
 	t.Run("detects PRD with embedded task headers", func(t *testing.T) {
 		dir := t.TempDir()
 		createFile(t, dir, "PRD.md", "# My PRD\n\n## TASK1: Feature\n\nSome content\n## TASK2: Other\n")
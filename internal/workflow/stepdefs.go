@@ -0,0 +1,331 @@
+package workflow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/state"
+)
+
+// WorkflowFileName is the step list file in the .snap directory.
+const WorkflowFileName = "workflow.yaml"
+
+// StepDef describes one step of the iteration workflow, as listed in
+// .snap/workflow.yaml.
+type StepDef struct {
+	Name        string     `yaml:"name"`
+	Prompt      string     `yaml:"prompt"`                // Prompt key (implement, lint-and-test, commit, ...)
+	Model       model.Type `yaml:"model"`                 // fast or thinking
+	Continue    bool       `yaml:"continue"`              // Continue the previous step's conversation (-c)
+	Purpose     string     `yaml:"purpose,omitempty"`     // Printed with --explain (default: the prompt's purpose)
+	AllowCommit bool       `yaml:"allowCommit,omitempty"` // May commit mid-pipeline; omits the no-commit suffix
+}
+
+// Prompt keys a StepDef can name.
+const (
+	PromptImplement          = "implement"
+	PromptEnsureCompleteness = "ensure-completeness"
+	PromptLintAndTest        = "lint-and-test"
+	PromptCodeReview         = "code-review"
+	PromptApplyFixes         = "apply-fixes"
+	PromptUpdateDocs         = "update-docs"
+	PromptCommit             = "commit"
+	PromptMemoryUpdate       = "memory-update"
+)
+
+// promptPurposes holds each prompt key's default --explain line.
+var promptPurposes = map[string]string{
+	PromptImplement:          "implements the task, following the PRD and the conventions in docs/context/",
+	PromptEnsureCompleteness: "checks every requirement and acceptance criterion of the task is met",
+	PromptLintAndTest:        "runs the linters and tests and fixes what fails",
+	PromptCodeReview:         "reviews the diff for security, reliability, architecture and test gaps against CLAUDE.md guidelines",
+	PromptApplyFixes:         "fixes the issues the review found",
+	PromptUpdateDocs:         "updates user-facing documentation for the change",
+	PromptCommit:             "commits the task's changes",
+	PromptMemoryUpdate:       "records what this task taught about the project in the memory vault",
+}
+
+// DefaultSteps returns the built-in iteration workflow, used when no step
+// list is configured.
+func DefaultSteps() []StepDef {
+	return []StepDef{
+		{Name: "Implement", Prompt: PromptImplement, Model: model.Thinking},
+		{Name: "Ensure completeness", Prompt: PromptEnsureCompleteness, Model: model.Thinking},
+		{Name: "Lint & test", Prompt: PromptLintAndTest, Model: model.Fast, Continue: true},
+		{Name: "Code review", Prompt: PromptCodeReview, Model: model.Thinking},
+		{Name: "Apply fixes", Prompt: PromptApplyFixes, Model: model.Fast, Continue: true},
+		{Name: "Verify fixes", Prompt: PromptLintAndTest, Model: model.Fast, Continue: true,
+			Purpose: "re-runs the linters and tests after the review fixes"},
+		{Name: "Update docs", Prompt: PromptUpdateDocs, Model: model.Fast},
+		{Name: "Commit code", Prompt: PromptCommit, Model: model.Fast,
+			Purpose: "commits the task's code, tests and docs"},
+		{Name: "Update memory", Prompt: PromptMemoryUpdate, Model: model.Fast, Continue: true},
+		{Name: "Commit memory", Prompt: PromptCommit, Model: model.Fast, Continue: true,
+			Purpose: "commits the memory vault updates"},
+	}
+}
+
+// StepCount returns the number of steps in the iteration workflow. steps is
+// the configured list (Config.Steps); nil means DefaultSteps().
+func StepCount(steps []StepDef) int {
+	if steps == nil {
+		return len(DefaultSteps())
+	}
+	return len(steps)
+}
+
+// StepName returns the display name for a 1-indexed step number of steps
+// (nil means DefaultSteps()). Returns "unknown" for out-of-range inputs.
+func StepName(steps []StepDef, stepNum int) string {
+	if steps == nil {
+		steps = DefaultSteps()
+	}
+	if stepNum < 1 || stepNum > len(steps) {
+		return "unknown"
+	}
+	return steps[stepNum-1].Name
+}
+
+// SkipSteps returns steps (nil means DefaultSteps()) without the ones named
+// in skip, matched case-insensitively, and the skipped names in step order.
+// Every name must match a step, and the remaining list must be valid.
+func SkipSteps(steps []StepDef, skip []string) (kept []StepDef, skipped []string, err error) {
+	if steps == nil {
+		steps = DefaultSteps()
+	}
+	if len(skip) == 0 {
+		return steps, nil, nil
+	}
+
+	for _, name := range skip {
+		if !slices.ContainsFunc(steps, func(s StepDef) bool { return strings.EqualFold(s.Name, strings.TrimSpace(name)) }) {
+			return nil, nil, fmt.Errorf("no step named %q (steps: %s)", name, strings.Join(stepDefNames(steps), ", "))
+		}
+	}
+	for _, s := range steps {
+		if slices.ContainsFunc(skip, func(name string) bool { return strings.EqualFold(s.Name, strings.TrimSpace(name)) }) {
+			skipped = append(skipped, s.Name)
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if len(kept) == 0 {
+		return nil, nil, errors.New("every step is skipped")
+	}
+	if err := ValidateStepDefs(kept); err != nil {
+		return nil, nil, err
+	}
+	return kept, skipped, nil
+}
+
+// RemapSkippedStep moves the saved step of an active task that started with
+// other steps skipped (State.SkippedSteps) onto the list skip leaves of
+// steps (nil means DefaultSteps()), by step name, so the task continues at
+// the step it stopped at. A step the new list leaves out is an error that
+// points to --step. State whose saved list no longer matches steps (the
+// step list itself changed) is left for the resume checks.
+func RemapSkippedStep(workflowState *state.State, steps []StepDef, skip []string) error {
+	if workflowState == nil || workflowState.CurrentTaskID == "" {
+		return nil
+	}
+	running, skipped, err := SkipSteps(steps, skip)
+	if err != nil {
+		return err
+	}
+	if slices.Equal(workflowState.SkippedSteps, skipped) {
+		return nil
+	}
+	saved, _, err := SkipSteps(steps, workflowState.SkippedSteps)
+	if err != nil || len(saved) != workflowState.TotalSteps {
+		return nil
+	}
+
+	step := workflowState.CurrentStep
+	switch {
+	case step < 1:
+		return nil
+	case step > len(saved):
+		step = len(running) + 1
+	default:
+		name := saved[step-1].Name
+		i := slices.IndexFunc(running, func(s StepDef) bool { return s.Name == name })
+		if i < 0 {
+			return fmt.Errorf(
+				"%s stopped at step %q, which --skip-step now leaves out; resume without skipping it, or pick a step with snap resume --step <n>",
+				workflowState.CurrentTaskID, name,
+			)
+		}
+		step = i + 1
+	}
+	workflowState.CurrentStep = step
+	workflowState.TotalSteps = len(running)
+	workflowState.SkippedSteps = skipped
+	return nil
+}
+
+// ApplyStepModels returns a copy of steps (nil means DefaultSteps()) with
+// the model of each step named in overrides replaced, matched
+// case-insensitively, and the override names that match no step, sorted.
+func ApplyStepModels(steps []StepDef, overrides map[string]model.Type) (out []StepDef, unknown []string) {
+	if steps == nil {
+		steps = DefaultSteps()
+	}
+	out = slices.Clone(steps)
+	for name, m := range overrides {
+		matched := false
+		for i := range out {
+			if strings.EqualFold(out[i].Name, strings.TrimSpace(name)) {
+				out[i].Model = m
+				matched = true
+			}
+		}
+		if !matched {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return out, unknown
+}
+
+// ValidateStepModels checks that every step model override is fast or
+// thinking.
+func ValidateStepModels(overrides map[string]model.Type) error {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if m := overrides[name]; m != model.Fast && m != model.Thinking {
+			return fmt.Errorf("step %q: model must be fast or thinking, got %q", name, m)
+		}
+	}
+	return nil
+}
+
+// stepDefNames returns the names of steps, in order.
+func stepDefNames(steps []StepDef) []string {
+	names := make([]string, len(steps))
+	for i, s := range steps {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// PreambleFileName is the prompt preamble file in the .snap directory.
+const PreambleFileName = "preamble.md"
+
+// LoadPreamble reads the text prepended to every step prompt from
+// .snap/preamble.md under projectRoot. A missing file returns "".
+func LoadPreamble(projectRoot string) (string, error) {
+	path := filepath.Join(projectRoot, state.StateDir, PreambleFileName)
+	data, err := os.ReadFile(path) //nolint:gosec // Fixed path under the project's .snap directory
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// workflowFile is the layout of .snap/workflow.yaml.
+type workflowFile struct {
+	Steps  []StepDef             `yaml:"steps"`
+	Models map[string]model.Type `yaml:"models"` // Step name to model, applied over Steps
+}
+
+// LoadSteps reads the step list from .snap/workflow.yaml under projectRoot.
+// A missing file, or one that only sets models, returns nil, which runs
+// DefaultSteps().
+func LoadSteps(projectRoot string) ([]StepDef, error) {
+	f, path, err := readWorkflowFile(projectRoot)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	if len(f.Steps) == 0 && len(f.Models) > 0 {
+		return nil, nil
+	}
+	if err := ValidateStepDefs(f.Steps); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return f.Steps, nil
+}
+
+// LoadStepModels reads the per-step model overrides (the models: map) from
+// .snap/workflow.yaml under projectRoot. A missing file returns nil.
+func LoadStepModels(projectRoot string) (map[string]model.Type, error) {
+	f, path, err := readWorkflowFile(projectRoot)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	if err := ValidateStepModels(f.Models); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return f.Models, nil
+}
+
+// readWorkflowFile decodes .snap/workflow.yaml under projectRoot and returns
+// it with its path. A missing file returns nil.
+func readWorkflowFile(projectRoot string) (*workflowFile, string, error) {
+	path := filepath.Join(projectRoot, state.StateDir, WorkflowFileName)
+	data, err := os.ReadFile(path) //nolint:gosec // Fixed path under the project's .snap directory
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, path, nil
+	}
+	if err != nil {
+		return nil, path, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var f workflowFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return nil, path, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return &f, path, nil
+}
+
+// ValidateStepDefs checks a configured step list: at least one step, each
+// with a name, a known prompt key and a fast or thinking model, and no
+// conversation to continue at step 1.
+func ValidateStepDefs(steps []StepDef) error {
+	if len(steps) == 0 {
+		return errors.New("no steps defined")
+	}
+	for i, s := range steps {
+		n := i + 1
+		if strings.TrimSpace(s.Name) == "" {
+			return fmt.Errorf("step %d has no name", n)
+		}
+		if _, ok := promptPurposes[s.Prompt]; !ok {
+			return fmt.Errorf("step %d %q: unknown prompt %q (known: %s)", n, s.Name, s.Prompt, strings.Join(promptKeys(), ", "))
+		}
+		if s.Model != model.Fast && s.Model != model.Thinking {
+			return fmt.Errorf("step %d %q: model must be fast or thinking, got %q", n, s.Name, s.Model)
+		}
+	}
+	if steps[0].Continue {
+		return fmt.Errorf("step 1 %q continues the conversation (-c), but no earlier step has started one", steps[0].Name)
+	}
+	return nil
+}
+
+// promptKeys returns the known prompt keys, sorted.
+func promptKeys() []string {
+	keys := make([]string, 0, len(promptPurposes))
+	for k := range promptPurposes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
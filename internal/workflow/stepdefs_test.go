@@ -0,0 +1,227 @@
+package workflow_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/state"
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+func writeWorkflowFile(t *testing.T, root, content string) {
+	t.Helper()
+	dir := filepath.Join(root, ".snap")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, workflow.WorkflowFileName), []byte(content), 0o600))
+}
+
+func TestLoadSteps(t *testing.T) {
+	t.Run("missing file runs the built-in steps", func(t *testing.T) {
+		steps, err := workflow.LoadSteps(t.TempDir())
+		require.NoError(t, err)
+		assert.Nil(t, steps)
+		assert.Equal(t, 10, workflow.StepCount(steps))
+		assert.Equal(t, "Code review", workflow.StepName(steps, 4))
+	})
+
+	t.Run("reads allowCommit", func(t *testing.T) {
+		root := t.TempDir()
+		writeWorkflowFile(t, root, `steps:
+  - name: Implement
+    prompt: implement
+    model: thinking
+  - name: Snapshot fixtures
+    prompt: apply-fixes
+    model: fast
+    allowCommit: true
+`)
+		steps, err := workflow.LoadSteps(root)
+		require.NoError(t, err)
+		require.Len(t, steps, 2)
+		assert.False(t, steps[0].AllowCommit)
+		assert.True(t, steps[1].AllowCommit)
+	})
+
+	t.Run("reads the configured steps", func(t *testing.T) {
+		root := t.TempDir()
+		writeWorkflowFile(t, root, `steps:
+  - name: Implement
+    prompt: implement
+    model: thinking
+  - name: Lint & test
+    prompt: lint-and-test
+    model: fast
+    continue: true
+  - name: Commit
+    prompt: commit
+    model: fast
+`)
+		steps, err := workflow.LoadSteps(root)
+		require.NoError(t, err)
+		assert.Equal(t, []workflow.StepDef{
+			{Name: "Implement", Prompt: workflow.PromptImplement, Model: model.Thinking},
+			{Name: "Lint & test", Prompt: workflow.PromptLintAndTest, Model: model.Fast, Continue: true},
+			{Name: "Commit", Prompt: workflow.PromptCommit, Model: model.Fast},
+		}, steps)
+		assert.Equal(t, 3, workflow.StepCount(steps))
+		assert.Equal(t, "Commit", workflow.StepName(steps, 3))
+		assert.Equal(t, "unknown", workflow.StepName(steps, 4))
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no steps", "steps: []\n", "no steps defined"},
+		{"unknown field", "steps:\n  - name: Lint\n    prompt: lint-and-test\n    model: fast\n    retries: 2\n", "field retries not found"},
+		{"unknown prompt", "steps:\n  - name: Deploy\n    prompt: deploy\n    model: fast\n", `step 1 "Deploy": unknown prompt "deploy" (known: apply-fixes, code-review, commit,`},
+		{"bad model", "steps:\n  - name: Implement\n    prompt: implement\n    model: slow\n", `step 1 "Implement": model must be fast or thinking, got "slow"`},
+		{"missing name", "steps:\n  - prompt: implement\n    model: fast\n", "step 1 has no name"},
+		{"first step continues", "steps:\n  - name: Lint\n    prompt: lint-and-test\n    model: fast\n    continue: true\n", "no earlier step has started one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeWorkflowFile(t, root, tt.content)
+
+			_, err := workflow.LoadSteps(root)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "workflow.yaml")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDefaultSteps_AreValid(t *testing.T) {
+	require.NoError(t, workflow.ValidateStepDefs(workflow.DefaultSteps()))
+}
+
+func TestSkipSteps(t *testing.T) {
+	kept, skipped, err := workflow.SkipSteps(nil, []string{"update docs", "Code Review"})
+	require.NoError(t, err)
+	assert.Len(t, kept, 8)
+	assert.Equal(t, []string{"Code review", "Update docs"}, skipped, "canonical names in step order")
+	assert.Equal(t, "Apply fixes", workflow.StepName(kept, 4))
+
+	kept, skipped, err = workflow.SkipSteps(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, workflow.DefaultSteps(), kept)
+	assert.Empty(t, skipped)
+
+	_, _, err = workflow.SkipSteps(nil, []string{"Deploy"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no step named "Deploy" (steps: Implement, Ensure completeness,`)
+
+	only := []workflow.StepDef{{Name: "Implement", Prompt: workflow.PromptImplement, Model: model.Thinking}}
+	_, _, err = workflow.SkipSteps(only, []string{"Implement"})
+	require.EqualError(t, err, "every step is skipped")
+
+	_, _, err = workflow.SkipSteps(nil, []string{"Implement", "Ensure completeness"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step 1 "Lint & test" continues the conversation`)
+}
+
+func TestRemapSkippedStep(t *testing.T) {
+	started := func(skipped []string, step int) *state.State {
+		kept, names, err := workflow.SkipSteps(nil, skipped)
+		require.NoError(t, err)
+		s := state.NewState("docs/tasks", "PRD.md", len(kept))
+		s.CurrentTaskID = "TASK1"
+		s.CurrentStep = step
+		s.SkippedSteps = names
+		return s
+	}
+
+	t.Run("follows the step by name", func(t *testing.T) {
+		s := started([]string{"Code review"}, 4)
+		require.NoError(t, workflow.RemapSkippedStep(s, nil, nil))
+		assert.Equal(t, 5, s.CurrentStep, "Apply fixes is step 5 of the full list")
+		assert.Equal(t, workflow.StepCount(nil), s.TotalSteps)
+		assert.Empty(t, s.SkippedSteps)
+	})
+
+	t.Run("keeps a finished task finished", func(t *testing.T) {
+		s := started(nil, workflow.StepCount(nil)+1)
+		require.NoError(t, workflow.RemapSkippedStep(s, nil, []string{"Update docs"}))
+		assert.Equal(t, workflow.StepCount(nil), s.CurrentStep)
+		assert.Equal(t, []string{"Update docs"}, s.SkippedSteps)
+	})
+
+	t.Run("refuses a step the new list leaves out", func(t *testing.T) {
+		s := started(nil, 4)
+		err := workflow.RemapSkippedStep(s, nil, []string{"code review"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `TASK1 stopped at step "Code review", which --skip-step now leaves out`)
+		assert.Contains(t, err.Error(), "snap resume --step <n>")
+		assert.Equal(t, 4, s.CurrentStep)
+	})
+
+	t.Run("leaves the same skip list alone", func(t *testing.T) {
+		s := started([]string{"Code review"}, 4)
+		require.NoError(t, workflow.RemapSkippedStep(s, nil, []string{"Code review"}))
+		assert.Equal(t, 4, s.CurrentStep)
+	})
+}
+
+func TestLoadStepModels(t *testing.T) {
+	models, err := workflow.LoadStepModels(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, models)
+
+	root := t.TempDir()
+	writeWorkflowFile(t, root, `models:
+  Code review: fast
+  Lint & test: thinking
+`)
+	models, err = workflow.LoadStepModels(root)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.Type{"Code review": model.Fast, "Lint & test": model.Thinking}, models)
+
+	steps, err := workflow.LoadSteps(root)
+	require.NoError(t, err)
+	assert.Nil(t, steps, "a file with only models runs the built-in steps")
+
+	writeWorkflowFile(t, root, "models:\n  Code review: smart\n")
+	_, err = workflow.LoadStepModels(root)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step "Code review": model must be fast or thinking, got "smart"`)
+}
+
+func TestApplyStepModels(t *testing.T) {
+	steps, unknown := workflow.ApplyStepModels(nil, map[string]model.Type{
+		"code review": model.Fast,
+		"Lint & test": model.Thinking,
+		"Code reveiw": model.Fast,
+		"Deploy":      model.Thinking,
+	})
+	assert.Equal(t, []string{"Code reveiw", "Deploy"}, unknown)
+	assert.Equal(t, model.Fast, steps[3].Model)
+	assert.Equal(t, model.Thinking, steps[2].Model)
+	assert.Equal(t, model.Fast, steps[5].Model, "only the named step changes")
+	assert.Equal(t, model.Thinking, workflow.DefaultSteps()[3].Model)
+
+	configured := workflow.DefaultSteps()
+	steps, unknown = workflow.ApplyStepModels(configured, map[string]model.Type{"Implement": model.Fast})
+	assert.Empty(t, unknown)
+	assert.Equal(t, model.Fast, steps[0].Model)
+	assert.Equal(t, model.Thinking, configured[0].Model, "the configured list is not modified")
+}
+
+func TestLoadPreamble(t *testing.T) {
+	root := t.TempDir()
+	preamble, err := workflow.LoadPreamble(root)
+	require.NoError(t, err)
+	assert.Empty(t, preamble, "missing file adds no preamble")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".snap"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".snap", workflow.PreambleFileName), []byte("\nAlways use tabs.\n\n"), 0o600))
+	preamble, err = workflow.LoadPreamble(root)
+	require.NoError(t, err)
+	assert.Equal(t, "Always use tabs.", preamble)
+}
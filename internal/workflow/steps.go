@@ -1,19 +1,24 @@
 package workflow
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/state"
 	"github.com/yarlson/snap/internal/ui"
 )
 
 const (
 	autonomousSuffix = "Work autonomously end-to-end. Do not ask the user any questions. Do not request approval. Do not pause for confirmation."
 	noCommitSuffix   = "Do not stage, commit, amend, rebase, or push any changes in this step."
+	signCommitSuffix = "Sign every commit: run git commit with -S and never pass --no-gpg-sign."
 )
 
 // Executor runs an external coding agent command (e.g., claude or codex).
@@ -21,25 +26,310 @@ type Executor interface {
 	Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error
 }
 
+// ModelNamer is implemented by executors that can report the concrete model
+// name behind an abstract model type.
+type ModelNamer interface {
+	ModelName(mt model.Type) string
+}
+
+// StderrExecutor is implemented by executors that can stream the provider's
+// stderr separately from its parsed output.
+type StderrExecutor interface {
+	RunSplit(ctx context.Context, w, stderr io.Writer, mt model.Type, args ...string) error
+}
+
+// StderrMode controls how provider stderr is shown while a step runs.
+type StderrMode string
+
+const (
+	StderrHide StderrMode = "hide" // Only reported when the provider fails (default)
+	StderrDim  StderrMode = "dim"  // Streamed into the step output, one dimmed line at a time
+	StderrShow StderrMode = "show" // Streamed into the step output unchanged
+)
+
+// ParseStderrMode parses a --provider-stderr value. Empty means StderrHide.
+func ParseStderrMode(s string) (StderrMode, error) {
+	switch m := StderrMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return StderrHide, nil
+	case StderrHide, StderrDim, StderrShow:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%q is not a stderr mode (supported: hide, dim, show)", s)
+	}
+}
+
 // StepRunner executes workflow steps using the configured agent CLI.
 type StepRunner struct {
-	executor Executor
-	output   io.Writer
+	executor    Executor
+	output      io.Writer
+	stderrMode  StderrMode
+	idleTimeout time.Duration
+	stepTimeout time.Duration
+	spinner     io.Writer
+	onTiming    func(StepTiming)
+	errorTail   int
+}
+
+// StepRunnerOption configures optional StepRunner behavior.
+type StepRunnerOption func(*StepRunner)
+
+// WithStderrMode sets how provider stderr is shown. It only takes effect for
+// executors that implement StderrExecutor; others keep their own handling.
+func WithStderrMode(m StderrMode) StepRunnerOption {
+	return func(r *StepRunner) {
+		r.stderrMode = m
+	}
+}
+
+// WithIdleTimeout cancels a step when the provider writes no output for d,
+// catching providers that hang without exiting. Zero or negative disables it.
+func WithIdleTimeout(d time.Duration) StepRunnerOption {
+	return func(r *StepRunner) {
+		r.idleTimeout = d
+	}
+}
+
+// WithStepTimeout cancels a step that runs longer than d, however much output
+// the provider writes. Zero or negative disables it.
+func WithStepTimeout(d time.Duration) StepRunnerOption {
+	return func(r *StepRunner) {
+		r.stepTimeout = d
+	}
+}
+
+// StepTiming is how long a numbered step's provider call took.
+type StepTiming struct {
+	Number  int
+	Name    string
+	Model   model.Type
+	Elapsed time.Duration
+	Err     error // Non-nil when the step failed
+}
+
+// WithStepTiming calls fn after each numbered step with the duration shown
+// in its "Step complete" or "Step failed" line.
+func WithStepTiming(fn func(StepTiming)) StepRunnerOption {
+	return func(r *StepRunner) {
+		r.onTiming = fn
+	}
+}
+
+// WithErrorTail keeps the last n lines of each numbered step's output and
+// repeats them, without colors, under "Step failed" when the step fails.
+// Zero or negative disables it.
+func WithErrorTail(n int) StepRunnerOption {
+	return func(r *StepRunner) {
+		r.errorTail = n
+	}
+}
+
+// spinnerInterval is how often the thinking spinner redraws.
+const spinnerInterval = 100 * time.Millisecond
+
+// WithSpinner draws a dim "thinking" spinner with the elapsed time on w while
+// the provider runs, until its first output byte or the end of the step. w
+// should be the terminal the step output reaches, minus any capture; frames
+// are skipped while it is paused. Only set it for TTY output.
+func WithSpinner(w io.Writer) StepRunnerOption {
+	return func(r *StepRunner) {
+		r.spinner = w
+	}
 }
 
 // NewStepRunner creates a new step runner that writes output to w.
-func NewStepRunner(executor Executor, w io.Writer) *StepRunner {
-	return &StepRunner{
-		executor: executor,
-		output:   w,
+func NewStepRunner(executor Executor, w io.Writer, opts ...StepRunnerOption) *StepRunner {
+	r := &StepRunner{
+		executor:   executor,
+		output:     w,
+		stderrMode: StderrHide,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ErrProvider marks failures of the provider CLI. Errors carrying it keep
+// their own message; match them with errors.Is.
+var ErrProvider = errors.New("provider failed")
+
+// providerError attaches ErrProvider to an error without changing its message.
+type providerError struct{ error }
+
+func (e providerError) Unwrap() []error { return []error{e.error, ErrProvider} }
+
+// ProviderError marks err as a provider failure. A nil err stays nil.
+func ProviderError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return providerError{err}
+}
+
+// StepError is a failed workflow step. Its message is the underlying
+// error's; Failure carries where it happened for state and diagnostics.
+type StepError struct {
+	Failure state.Failure
+	Err     error
+}
+
+func (e *StepError) Error() string { return e.Err.Error() }
+
+func (e *StepError) Unwrap() error { return e.Err }
+
+// failureOutputMax caps how much of a failed step's output is kept.
+const failureOutputMax = 2000
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the kept output without colors or surrounding whitespace.
+func (t *tailBuffer) String() string {
+	return strings.TrimSpace(ui.StripColors(strings.ToValidUTF8(string(t.buf), "")))
+}
+
+// lineTail keeps the last len(lines) non-blank lines written to it in a ring
+// buffer.
+type lineTail struct {
+	lines   []string
+	next    int // Slot the next complete line goes to
+	count   int // Complete lines kept, at most len(lines)
+	partial []byte
+}
+
+func (t *lineTail) Write(p []byte) (int, error) {
+	t.partial = append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i < 0 {
+			break
+		}
+		t.push(string(t.partial[:i]))
+		t.partial = t.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+func (t *lineTail) push(line string) {
+	if strings.TrimSpace(ui.StripColors(line)) == "" {
+		return
+	}
+	t.lines[t.next] = line
+	t.next = (t.next + 1) % len(t.lines)
+	t.count = min(t.count+1, len(t.lines))
+}
+
+// Lines returns the kept lines, oldest first, without colors or trailing
+// whitespace. An unterminated last line counts as a line. A nil tail has no
+// lines.
+func (t *lineTail) Lines() []string {
+	if t == nil {
+		return nil
+	}
+	var out []string
+	for i := range t.count {
+		out = append(out, t.lines[(t.next-t.count+i+len(t.lines))%len(t.lines)])
+	}
+	if strings.TrimSpace(ui.StripColors(string(t.partial))) != "" {
+		out = append(out, string(t.partial))
+		if len(out) > len(t.lines) {
+			out = out[1:]
+		}
+	}
+	for i, line := range out {
+		out[i] = strings.TrimRight(ui.StripColors(strings.ToValidUTF8(line, "")), "\r \t")
+	}
+	return out
+}
+
+// ErrIdleTimeout reports that a step was cancelled because the provider
+// wrote no output for the configured idle timeout.
+var ErrIdleTimeout = errors.New("provider idle timeout")
+
+// ErrStepTimeout reports that a step was cancelled because it ran longer
+// than the configured step timeout.
+var ErrStepTimeout = errors.New("step timeout")
+
+// execute runs the executor writing to w under the step timeout and idle
+// watchdog, if configured. Failures are marked with ErrProvider.
+func (r *StepRunner) execute(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	if r.spinner != nil {
+		spinner := ui.StartSpinner(r.spinner, spinnerInterval)
+		defer spinner.Stop()
+		w = &firstWriteHook{w: w, hook: spinner.Stop}
 	}
+
+	if r.stepTimeout <= 0 {
+		return r.watch(ctx, w, mt, args...)
+	}
+
+	// Cancelling the parent (Ctrl+C) leaves the cause context.Canceled, so
+	// interrupts are never reported as timeouts.
+	ctx, cancel := context.WithTimeoutCause(ctx, r.stepTimeout, ErrStepTimeout)
+	defer cancel()
+
+	err := r.watch(ctx, w, mt, args...)
+	if err != nil && errors.Is(context.Cause(ctx), ErrStepTimeout) {
+		return ProviderError(fmt.Errorf("step timed out after %s: %w", r.stepTimeout, ErrStepTimeout))
+	}
+	return err
+}
+
+// watch runs the executor writing to w under the idle watchdog, if one is
+// configured.
+func (r *StepRunner) watch(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	if r.idleTimeout <= 0 {
+		return r.run(ctx, w, mt, args...)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	watchdog := newIdleWatchdog(w, r.idleTimeout, func() { cancel(ErrIdleTimeout) })
+	defer watchdog.Stop()
+
+	err := r.run(ctx, watchdog, mt, args...)
+	if err != nil && errors.Is(context.Cause(ctx), ErrIdleTimeout) {
+		return ProviderError(fmt.Errorf("no output for %s: %w", r.idleTimeout, ErrIdleTimeout))
+	}
+	return err
+}
+
+// run runs the executor writing to w, routing provider stderr per the
+// stderr mode.
+func (r *StepRunner) run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	split, ok := r.executor.(StderrExecutor)
+	if !ok || r.stderrMode == StderrHide || r.stderrMode == "" {
+		return ProviderError(r.executor.Run(ctx, w, mt, args...))
+	}
+
+	// stdout and stderr are copied on separate goroutines.
+	out := &syncWriter{w: w}
+	if r.stderrMode == StderrShow {
+		return ProviderError(split.RunSplit(ctx, out, out, mt, args...))
+	}
+	dim := &dimLineWriter{w: out}
+	err := split.RunSplit(ctx, out, dim, mt, args...)
+	dim.Flush()
+	return ProviderError(err)
 }
 
 // RunStep executes a single workflow step with the given name and arguments.
 func (r *StepRunner) RunStep(ctx context.Context, stepName string, mt model.Type, args ...string) error {
 	fmt.Fprint(r.output, ui.Step(stepName))
 
-	if err := r.executor.Run(ctx, r.output, mt, args...); err != nil {
+	if err := r.execute(ctx, r.output, mt, args...); err != nil {
 		return fmt.Errorf("step %q failed: %w", stepName, err)
 	}
 
@@ -50,47 +340,215 @@ func (r *StepRunner) RunStep(ctx context.Context, stepName string, mt model.Type
 func (r *StepRunner) RunStepNumbered(ctx context.Context, current, total int, stepName string, mt model.Type, args ...string) error {
 	fmt.Fprint(r.output, ui.StepNumbered(current, total, stepName))
 
+	w := r.output
+	var tail *lineTail
+	if r.errorTail > 0 {
+		tail = &lineTail{lines: make([]string, r.errorTail)}
+		w = io.MultiWriter(w, tail)
+	}
+
 	start := time.Now()
-	if err := r.executor.Run(ctx, r.output, mt, args...); err != nil {
-		elapsed := time.Since(start)
+	err := r.execute(ctx, w, mt, args...)
+	elapsed := time.Since(start)
+	if r.onTiming != nil {
+		r.onTiming(StepTiming{Number: current, Name: stepName, Model: mt, Elapsed: elapsed, Err: err})
+	}
+	if err != nil {
 		fmt.Fprintln(r.output, ui.StepFailed("Step failed", elapsed))
+		// An interrupted step's output is still on screen; only failures repeat it.
+		if lines := tail.Lines(); len(lines) > 0 && ctx.Err() == nil {
+			fmt.Fprintln(r.output, ui.ErrorWithDetails(fmt.Sprintf("Last %d lines of output", len(lines)), lines))
+		}
 		return fmt.Errorf("step %d/%d %q failed: %w", current, total, stepName, err)
 	}
 
-	elapsed := time.Since(start)
 	fmt.Fprintln(r.output, ui.StepComplete("Step complete", elapsed))
 	return nil
 }
 
-// stepNames maps 1-indexed step numbers to their display names.
-// Must be kept in sync with the steps slice in Runner.runIteration.
-var stepNames = [workflowStepCount]string{
-	"Implement",
-	"Ensure completeness",
-	"Lint & test",
-	"Code review",
-	"Apply fixes",
-	"Verify fixes",
-	"Update docs",
-	"Commit code",
-	"Update memory",
-	"Commit memory",
+// syncWriter serializes writes from the stdout and stderr copiers.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// firstWriteHook calls hook once, before the first write passes through to w.
+type firstWriteHook struct {
+	w    io.Writer
+	hook func()
+	once sync.Once
+}
+
+func (f *firstWriteHook) Write(p []byte) (int, error) {
+	f.once.Do(f.hook)
+	return f.w.Write(p)
 }
 
-// StepName returns the display name for a 1-indexed step number.
-// Returns "unknown" for out-of-range inputs.
-func StepName(stepNum int) string {
-	if stepNum < 1 || stepNum > workflowStepCount {
-		return "unknown"
+// idleWatchdog passes writes through to w and calls expire when nothing has
+// been written for timeout. Every non-empty write restarts the countdown.
+type idleWatchdog struct {
+	w       io.Writer
+	timeout time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func newIdleWatchdog(w io.Writer, timeout time.Duration, expire func()) *idleWatchdog {
+	return &idleWatchdog{w: w, timeout: timeout, timer: time.AfterFunc(timeout, expire)}
+}
+
+func (d *idleWatchdog) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		d.mu.Lock()
+		d.timer.Reset(d.timeout)
+		d.mu.Unlock()
 	}
-	return stepNames[stepNum-1]
+	return d.w.Write(p)
+}
+
+// Stop disarms the watchdog.
+func (d *idleWatchdog) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.timer.Stop()
+}
+
+// dimLineWriter writes provider stderr as dimmed lines. Carriage-return
+// redraws (progress spinners) collapse to the last frame of each line, and
+// blank lines are dropped.
+type dimLineWriter struct {
+	w       io.Writer
+	partial []byte
+}
+
+func (d *dimLineWriter) Write(p []byte) (int, error) {
+	d.partial = append(d.partial, p...)
+	for {
+		i := bytes.IndexByte(d.partial, '\n')
+		if i < 0 {
+			break
+		}
+		d.writeLine(d.partial[:i])
+		d.partial = d.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any unterminated final line.
+func (d *dimLineWriter) Flush() {
+	if len(d.partial) > 0 {
+		d.writeLine(d.partial)
+		d.partial = nil
+	}
+}
+
+func (d *dimLineWriter) writeLine(line []byte) {
+	if i := bytes.LastIndexByte(bytes.TrimRight(line, "\r"), '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+	text := strings.TrimSpace(string(line))
+	if text == "" {
+		return
+	}
+	//nolint:errcheck // Best-effort display of provider diagnostics.
+	_, _ = io.WriteString(d.w, ui.Info(text))
+}
+
+// continueFlag is the provider argument that continues the previous
+// conversation instead of starting a new one.
+const continueFlag = "-c"
+
+// workflowStep is one step of the iteration workflow.
+type workflowStep struct {
+	name        string
+	purpose     string // One-line rationale printed with Config.Explain
+	prompt      string
+	model       model.Type
+	continues   bool // Continue the previous step's conversation (-c)
+	checks      bool // Lint/test step; gated by Config.FailFastOnLint
+	commit      bool // Commit step; skipped on a clean tree and gated by commit confirmation
+	allowCommit bool // May commit mid-pipeline; omits the no-commit suffix
+	implements  bool // Implement step; followed by the --show-diff preview
+}
+
+// buildSteps turns step definitions into runnable steps. stepPrompts maps
+// prompt keys to the rendered prompts; implement steps are named after the
+// task, as in "Implement TASK1".
+func buildSteps(defs []StepDef, stepPrompts map[string]string, taskLabel string) []workflowStep {
+	steps := make([]workflowStep, len(defs))
+	for i, d := range defs {
+		step := workflowStep{
+			name:        d.Name,
+			purpose:     d.Purpose,
+			prompt:      stepPrompts[d.Prompt],
+			model:       d.Model,
+			continues:   d.Continue,
+			checks:      d.Prompt == PromptLintAndTest,
+			commit:      d.Prompt == PromptCommit,
+			allowCommit: d.AllowCommit,
+			implements:  d.Prompt == PromptImplement,
+		}
+		if step.purpose == "" {
+			step.purpose = promptPurposes[d.Prompt]
+		}
+		if step.implements {
+			step.name = fmt.Sprintf("%s %s", d.Name, taskLabel)
+		}
+		steps[i] = step
+	}
+	return steps
+}
+
+// fullPrompt returns the step prompt with the preamble and suffixes it runs
+// with. Steps that may commit don't get the no-commit suffix, and are asked
+// to sign their commits when signCommits is set.
+func (s workflowStep) fullPrompt(signCommits bool, preamble string) string {
+	opts := []PromptOption{WithPreamble(preamble)}
+	switch {
+	case s.mayCommit() && signCommits:
+		opts = append(opts, WithSignedCommit())
+	case !s.mayCommit():
+		opts = append(opts, WithNoCommit())
+	}
+	return BuildPrompt(s.prompt, opts...)
+}
+
+// mayCommit reports whether the step is allowed to create commits.
+func (s workflowStep) mayCommit() bool {
+	return s.commit || s.allowCommit
+}
+
+// validateSteps checks a step list before it runs. The first step has no
+// earlier conversation to continue, so it can't use -c.
+func validateSteps(steps []workflowStep) error {
+	if len(steps) > 0 && steps[0].continues {
+		return fmt.Errorf("step 1 %q continues the conversation (-c), but no earlier step has started one", steps[0].name)
+	}
+	return nil
 }
 
 // PromptOption is a function that modifies prompt building behavior.
 type PromptOption func(*promptConfig)
 
 type promptConfig struct {
-	noCommit bool
+	preamble   string
+	noCommit   bool
+	signCommit bool
+}
+
+// WithPreamble puts text, separated by a blank line, before the prompt.
+// Empty text adds nothing.
+func WithPreamble(text string) PromptOption {
+	return func(c *promptConfig) {
+		c.preamble = strings.TrimSpace(text)
+	}
 }
 
 // WithNoCommit adds the no-commit suffix to the prompt.
@@ -100,12 +558,23 @@ func WithNoCommit() PromptOption {
 	}
 }
 
-// BuildPrompt constructs a prompt with the autonomous suffix and optional no-commit suffix.
+// WithSignedCommit adds the suffix asking for signed commits.
+func WithSignedCommit() PromptOption {
+	return func(c *promptConfig) {
+		c.signCommit = true
+	}
+}
+
+// BuildPrompt constructs a prompt with the autonomous suffix, an optional
+// no-commit or signed-commit suffix, and an optional preamble.
 func BuildPrompt(base string, options ...PromptOption) string {
 	cfg := &promptConfig{}
 	for _, opt := range options {
 		opt(cfg)
 	}
+	if cfg.preamble != "" {
+		base = strings.TrimRight(cfg.preamble+"\n\n"+base, "\n")
+	}
 
 	var parts []string
 	if base != "" {
@@ -114,6 +583,9 @@ func BuildPrompt(base string, options ...PromptOption) string {
 	if cfg.noCommit {
 		parts = append(parts, noCommitSuffix)
 	}
+	if cfg.signCommit {
+		parts = append(parts, signCommitSuffix)
+	}
 	parts = append(parts, autonomousSuffix)
 
 	return strings.Join(parts, " ")
@@ -0,0 +1,59 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/model"
+)
+
+func TestValidateSteps(t *testing.T) {
+	require.NoError(t, validateSteps(nil))
+	require.NoError(t, validateSteps([]workflowStep{
+		{name: "Implement"},
+		{name: "Lint & test", continues: true},
+	}))
+
+	err := validateSteps([]workflowStep{
+		{name: "Lint & test", continues: true},
+		{name: "Implement"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step 1 "Lint & test" continues the conversation (-c)`)
+}
+
+func TestBuildSteps_AllowCommit(t *testing.T) {
+	steps := buildSteps([]StepDef{
+		{Name: "Implement", Prompt: PromptImplement, Model: model.Thinking},
+		{Name: "Generate fixtures", Prompt: PromptApplyFixes, Model: model.Fast, AllowCommit: true},
+	}, map[string]string{PromptImplement: "Implement it", PromptApplyFixes: "Generate and commit fixtures"}, "TASK1")
+
+	assert.False(t, steps[0].allowCommit)
+	assert.Contains(t, steps[0].fullPrompt(false, ""), noCommitSuffix)
+	assert.True(t, steps[1].allowCommit)
+	assert.NotContains(t, steps[1].fullPrompt(false, ""), noCommitSuffix)
+	assert.False(t, steps[1].commit, "allowCommit doesn't make a commit step")
+}
+
+func TestWorkflowStep_FullPrompt(t *testing.T) {
+	plain := workflowStep{name: "Commit-free refactor", prompt: "Refactor"}
+	assert.Contains(t, plain.fullPrompt(false, ""), noCommitSuffix, "suffix doesn't depend on the step name")
+	assert.NotContains(t, plain.fullPrompt(true, ""), signCommitSuffix)
+
+	commit := workflowStep{name: "Save work", prompt: "Commit", commit: true}
+	assert.NotContains(t, commit.fullPrompt(false, ""), noCommitSuffix)
+	assert.NotContains(t, commit.fullPrompt(false, ""), signCommitSuffix)
+	assert.Contains(t, commit.fullPrompt(true, ""), signCommitSuffix)
+
+	custom := workflowStep{name: "Generate fixtures", prompt: "Generate and commit fixtures", allowCommit: true}
+	assert.NotContains(t, custom.fullPrompt(false, ""), noCommitSuffix)
+	assert.Contains(t, custom.fullPrompt(false, ""), "Generate and commit fixtures")
+	assert.Contains(t, custom.fullPrompt(true, ""), signCommitSuffix)
+
+	withRules := commit.fullPrompt(true, "No new dependencies.")
+	assert.True(t, strings.HasPrefix(withRules, "No new dependencies.\n\nCommit"))
+	assert.Contains(t, withRules, signCommitSuffix)
+}
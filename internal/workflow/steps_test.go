@@ -4,22 +4,36 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/yarlson/snap/internal/model"
 	"github.com/yarlson/snap/internal/ui"
 	"github.com/yarlson/snap/internal/workflow"
 )
 
-// MockExecutor is a mock implementation of the claude executor.
+// MockExecutor is a mock implementation of the claude executor. The task
+// description pre-step goes to describeFunc (and succeeds with no output when
+// it is nil), so runFunc only sees workflow steps, in order, even though the
+// pre-step runs concurrently with step 1.
 type MockExecutor struct {
-	runFunc func(ctx context.Context, w io.Writer, mt model.Type, args ...string) error
+	runFunc      func(ctx context.Context, w io.Writer, mt model.Type, args ...string) error
+	describeFunc func(ctx context.Context, w io.Writer, mt model.Type, prompt string) error
 }
 
 func (m *MockExecutor) Run(ctx context.Context, w io.Writer, mt model.Type, args ...string) error {
+	if len(args) > 0 && strings.HasPrefix(args[len(args)-1], "Summarize the following task") {
+		if m.describeFunc != nil {
+			return m.describeFunc(ctx, w, mt, args[len(args)-1])
+		}
+		return nil
+	}
 	if m.runFunc != nil {
 		return m.runFunc(ctx, w, mt, args...)
 	}
@@ -101,7 +115,9 @@ func TestStepRunner_RunStepNumbered_PrintsTimingOnFailure(t *testing.T) {
 	runner := workflow.NewStepRunner(mockExec, &buf)
 	err := runner.RunStepNumbered(context.Background(), 1, 9, "Test Step", model.Fast, "arg")
 
-	assert.Error(t, err)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, workflow.ErrProvider, "executor failures are provider failures")
+	assert.Equal(t, `step 1/9 "Test Step" failed: command failed`, err.Error())
 
 	output := ui.StripColors(buf.String())
 	assert.Contains(t, output, "✗")
@@ -110,6 +126,245 @@ func TestStepRunner_RunStepNumbered_PrintsTimingOnFailure(t *testing.T) {
 	assert.Contains(t, output, "s")
 }
 
+func TestStepRunner_ErrorTail(t *testing.T) {
+	run := func(t *testing.T, fail bool, opts ...workflow.StepRunnerOption) string {
+		t.Helper()
+		var buf bytes.Buffer
+		mockExec := &MockExecutor{
+			runFunc: func(_ context.Context, w io.Writer, _ model.Type, _ ...string) error {
+				for i := 1; i <= 12; i++ {
+					fmt.Fprintf(w, "\x1b[31mline %d\x1b[0m\n", i)
+				}
+				fmt.Fprint(w, "\n  \nunterminated")
+				if fail {
+					return errors.New("claude command failed: exit status 1")
+				}
+				return nil
+			},
+		}
+		runner := workflow.NewStepRunner(mockExec, &buf, opts...)
+		err := runner.RunStepNumbered(context.Background(), 1, 10, "Implement", model.Thinking)
+		assert.Equal(t, fail, err != nil)
+		return buf.String()
+	}
+
+	t.Run("failure repeats the last lines without colors", func(t *testing.T) {
+		output := run(t, true, workflow.WithErrorTail(3))
+		_, after, found := strings.Cut(output, "Step failed")
+		require.True(t, found)
+		assert.Contains(t, after, "Last 3 lines of output")
+		assert.Contains(t, after, "└─ line 11")
+		assert.Contains(t, after, "└─ line 12")
+		assert.Contains(t, after, "└─ unterminated")
+		assert.NotContains(t, after, "line 10")
+		assert.NotContains(t, after, "\x1b[31m")
+	})
+
+	t.Run("success prints no tail", func(t *testing.T) {
+		assert.NotContains(t, run(t, false, workflow.WithErrorTail(3)), "Last 3 lines")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		assert.NotContains(t, run(t, true), "lines of output")
+	})
+}
+
+// splitExecutor writes fixed stdout and stderr through RunSplit.
+type splitExecutor struct {
+	stdout, stderr string
+	runCalls       int
+}
+
+func (e *splitExecutor) Run(_ context.Context, w io.Writer, _ model.Type, _ ...string) error {
+	e.runCalls++
+	_, err := io.WriteString(w, e.stdout)
+	return err
+}
+
+func (e *splitExecutor) RunSplit(_ context.Context, w, stderr io.Writer, _ model.Type, _ ...string) error {
+	if _, err := io.WriteString(stderr, e.stderr); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, e.stdout)
+	return err
+}
+
+func TestStepRunner_StderrModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     workflow.StderrMode
+		want     string
+		wantRuns int
+	}{
+		{name: "default hides stderr", want: "answer\n", wantRuns: 1},
+		{name: "hide", mode: workflow.StderrHide, want: "answer\n", wantRuns: 1},
+		{name: "dim collapses spinner frames", mode: workflow.StderrDim, want: "loading 100%\nanswer\nwarn: slow\n"},
+		{name: "show passes stderr through", mode: workflow.StderrShow, want: "loading 10%\rloading 100%\n\nwarn: slow" + "answer\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := &splitExecutor{stdout: "answer\n", stderr: "loading 10%\rloading 100%\n\nwarn: slow"}
+			var buf bytes.Buffer
+
+			var opts []workflow.StepRunnerOption
+			if tt.mode != "" {
+				opts = append(opts, workflow.WithStderrMode(tt.mode))
+			}
+			runner := workflow.NewStepRunner(exec, &buf, opts...)
+			require.NoError(t, runner.RunStep(context.Background(), "Step", model.Fast))
+
+			assert.Equal(t, tt.wantRuns, exec.runCalls)
+			output := ui.StripColors(buf.String())
+			assert.True(t, strings.HasSuffix(output, tt.want), "got %q", output)
+		})
+	}
+}
+
+func TestStepRunner_StderrModeIgnoredWithoutSplitExecutor(t *testing.T) {
+	var buf bytes.Buffer
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, w io.Writer, _ model.Type, _ ...string) error {
+			_, err := io.WriteString(w, "answer\n")
+			return err
+		},
+	}
+
+	runner := workflow.NewStepRunner(mockExec, &buf, workflow.WithStderrMode(workflow.StderrDim))
+	require.NoError(t, runner.RunStep(context.Background(), "Step", model.Fast))
+	assert.True(t, strings.HasSuffix(buf.String(), "answer\n"))
+}
+
+func TestStepRunner_Spinner(t *testing.T) {
+	mockExec := &MockExecutor{
+		runFunc: func(_ context.Context, w io.Writer, _ model.Type, _ ...string) error {
+			time.Sleep(250 * time.Millisecond)
+			fmt.Fprintln(w, "done")
+			time.Sleep(150 * time.Millisecond)
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	sw := ui.NewSwitchWriter(&buf)
+	runner := workflow.NewStepRunner(mockExec, sw, workflow.WithSpinner(sw))
+	require.NoError(t, runner.RunStepNumbered(context.Background(), 1, 10, "Implement", model.Thinking))
+
+	output := buf.String()
+	spin := strings.Index(output, " thinking ")
+	clear := strings.LastIndex(output, "\r\x1b[K")
+	done := strings.Index(output, "done")
+	require.NotEqual(t, -1, spin, "spinner is drawn while the provider is silent")
+	assert.Less(t, strings.Index(output, "Step 1/10: Implement"), spin)
+	assert.Less(t, clear, done, "spinner is cleared before the first output")
+	assert.Equal(t, strings.LastIndex(output, " thinking "), strings.LastIndex(output[:done], " thinking "),
+		"spinner stops at the first output")
+}
+
+func TestStepRunner_IdleTimeout(t *testing.T) {
+	t.Run("cancels a silent provider", func(t *testing.T) {
+		mockExec := &MockExecutor{
+			runFunc: func(ctx context.Context, w io.Writer, _ model.Type, _ ...string) error {
+				fmt.Fprintln(w, "thinking")
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+
+		runner := workflow.NewStepRunner(mockExec, io.Discard, workflow.WithIdleTimeout(20*time.Millisecond))
+		err := runner.RunStepNumbered(context.Background(), 2, 10, "Ensure completeness", model.Thinking)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, workflow.ErrIdleTimeout)
+		assert.ErrorIs(t, err, workflow.ErrProvider)
+		assert.Contains(t, err.Error(), "no output for 20ms")
+	})
+
+	t.Run("output keeps a long step alive", func(t *testing.T) {
+		mockExec := &MockExecutor{
+			runFunc: func(ctx context.Context, w io.Writer, _ model.Type, _ ...string) error {
+				for range 8 {
+					time.Sleep(10 * time.Millisecond)
+					fmt.Fprintln(w, "working")
+				}
+				return ctx.Err()
+			},
+		}
+
+		runner := workflow.NewStepRunner(mockExec, io.Discard, workflow.WithIdleTimeout(50*time.Millisecond))
+		require.NoError(t, runner.RunStep(context.Background(), "Implement", model.Thinking))
+	})
+
+	t.Run("other failures pass through", func(t *testing.T) {
+		mockExec := &MockExecutor{
+			runFunc: func(context.Context, io.Writer, model.Type, ...string) error {
+				return errors.New("exit status 2")
+			},
+		}
+
+		runner := workflow.NewStepRunner(mockExec, io.Discard, workflow.WithIdleTimeout(time.Second))
+		err := runner.RunStep(context.Background(), "Implement", model.Thinking)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, workflow.ErrIdleTimeout)
+		assert.Contains(t, err.Error(), "exit status 2")
+	})
+}
+
+func TestStepRunner_StepTimeout(t *testing.T) {
+	t.Run("cancels a step that runs too long despite output", func(t *testing.T) {
+		mockExec := &MockExecutor{
+			runFunc: func(ctx context.Context, w io.Writer, _ model.Type, _ ...string) error {
+				for ctx.Err() == nil {
+					fmt.Fprintln(w, "working")
+					time.Sleep(5 * time.Millisecond)
+				}
+				return ctx.Err()
+			},
+		}
+
+		runner := workflow.NewStepRunner(mockExec, io.Discard,
+			workflow.WithStepTimeout(30*time.Millisecond), workflow.WithIdleTimeout(time.Second))
+		err := runner.RunStepNumbered(context.Background(), 3, 10, "Lint & test", model.Fast)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, workflow.ErrStepTimeout)
+		assert.ErrorIs(t, err, workflow.ErrProvider)
+		assert.NotErrorIs(t, err, workflow.ErrIdleTimeout)
+		assert.Contains(t, err.Error(), "step timed out after 30ms")
+	})
+
+	t.Run("interrupts are not timeouts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockExec := &MockExecutor{
+			runFunc: func(ctx context.Context, _ io.Writer, _ model.Type, _ ...string) error {
+				cancel()
+				return ctx.Err()
+			},
+		}
+
+		runner := workflow.NewStepRunner(mockExec, io.Discard, workflow.WithStepTimeout(time.Minute))
+		err := runner.RunStep(ctx, "Implement", model.Thinking)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, workflow.ErrStepTimeout)
+	})
+}
+
+func TestParseStderrMode(t *testing.T) {
+	for in, want := range map[string]workflow.StderrMode{
+		"":       workflow.StderrHide,
+		"hide":   workflow.StderrHide,
+		"DIM":    workflow.StderrDim,
+		" show ": workflow.StderrShow,
+	} {
+		got, err := workflow.ParseStderrMode(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := workflow.ParseStderrMode("verbose")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "supported: hide, dim, show")
+}
+
 func TestStepName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -133,7 +388,7 @@ func TestStepName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, workflow.StepName(tt.stepNum))
+			assert.Equal(t, tt.expected, workflow.StepName(nil, tt.stepNum))
 		})
 	}
 }
@@ -163,6 +418,18 @@ func TestWorkflow_BuildPrompt(t *testing.T) {
 			options:  []workflow.PromptOption{workflow.WithNoCommit()},
 			expected: "Do not stage, commit, amend, rebase, or push any changes in this step. Work autonomously end-to-end. Do not ask the user any questions. Do not request approval. Do not pause for confirmation.",
 		},
+		{
+			name:     "preamble before the prompt, suffixes last",
+			base:     "Test prompt",
+			options:  []workflow.PromptOption{workflow.WithPreamble("Use tabs.\n"), workflow.WithNoCommit()},
+			expected: "Use tabs.\n\nTest prompt Do not stage, commit, amend, rebase, or push any changes in this step. Work autonomously end-to-end. Do not ask the user any questions. Do not request approval. Do not pause for confirmation.",
+		},
+		{
+			name:     "empty preamble adds nothing",
+			base:     "Test prompt",
+			options:  []workflow.PromptOption{workflow.WithPreamble("  ")},
+			expected: "Test prompt Work autonomously end-to-end. Do not ask the user any questions. Do not request approval. Do not pause for confirmation.",
+		},
 	}
 
 	for _, tt := range tests {
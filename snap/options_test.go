@@ -0,0 +1,114 @@
+package snap_test
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/snap"
+)
+
+// nopExecutor answers every prompt without doing anything.
+type nopExecutor struct{}
+
+func (nopExecutor) Run(context.Context, io.Writer, snap.ModelType, ...string) error {
+	return nil
+}
+
+// TestOptions_SettableOutsideModule sets every Options field from outside
+// the module's packages: a field whose type lives in internal/ could not be
+// set here and would fail to compile.
+func TestOptions_SettableOutsideModule(t *testing.T) {
+	directives := snap.NewDirectives()
+	directives.Enqueue("keep the API stable")
+	stepInfo := snap.NewStepInfo()
+	stepInfo.Set(2, 10, "Ensure completeness")
+
+	opts := snap.Options{
+		Session:        "auth",
+		TasksDir:       "docs/tasks",
+		PRDPath:        "docs/tasks/PRD.md",
+		TasksGlob:      "story-*.md",
+		Provider:       "codex",
+		FastModel:      "fast",
+		ThinkingModel:  "thinking",
+		ProviderEnv:    map[string]string{"HTTPS_PROXY": "http://proxy:3128"},
+		Executor:       nopExecutor{},
+		Output:         io.Discard,
+		Events:         io.Discard,
+		MetricsFile:    "metrics.json",
+		ErrorTailLines: 20,
+
+		Fresh:          true,
+		Scope:          "services/auth",
+		BaseSHA:        "main",
+		SessionMemory:  true,
+		Changelog:      "CHANGELOG.md",
+		FailFast:       true,
+		NoDescribe:     true,
+		Explain:        true,
+		SignCommits:    true,
+		StrictCommits:  true,
+		PRPerTask:      true,
+		Parallel:       2,
+		MaxIterations:  3,
+		OnlyTask:       "TASK3",
+		PushRemote:     "origin",
+		PRRemote:       "upstream",
+		ProviderStderr: "dim",
+		Snapshots:      "on-failure",
+		KeepSnapshots:  5,
+		IdleTimeout:    time.Minute,
+		StepTimeout:    time.Hour,
+		StepRetries:    2,
+		RetryBackoff:   time.Second,
+		CIPollInterval: 30 * time.Second,
+		IsolateCIFix:   true,
+		CIFixFallbacks: []string{"claude"},
+		Guardrails:     []string{"no new dependencies"},
+		LintCommand:    "golangci-lint run",
+		TestCommand:    "go test ./...",
+		NoSignals:      true,
+
+		Steps: []snap.StepDef{
+			{Name: "Implement", Prompt: snap.PromptImplement, Model: snap.ModelThinking},
+			{Name: "Commit code", Prompt: snap.PromptCommit, Model: snap.ModelFast},
+		},
+		SkipSteps:         []string{"Commit code"},
+		StepModels:        map[string]snap.ModelType{"Implement": snap.ModelFast},
+		Preamble:          "House rules",
+		PostIterationHook: "make notify",
+		HookFatal:         true,
+		TaskFile:          "fix-login.md",
+		ResumeStep:        2,
+
+		Terminal:      true,
+		NoInput:       true,
+		ShowDiff:      true,
+		QueueInterval: time.Second,
+		Directives:    directives,
+		StepInfo:      stepInfo,
+		ConfirmCommit: func(context.Context, string) snap.CommitDecision {
+			return snap.CommitSkip
+		},
+		ConfirmNextTask: func(context.Context, string) bool { return true },
+		OnInterrupt:     func() {},
+	}
+
+	v := reflect.ValueOf(opts)
+	for i := range v.NumField() {
+		assert.False(t, v.Field(i).IsZero(), "Options.%s is not set by this test", v.Type().Field(i).Name)
+	}
+
+	prompt, ok := directives.Dequeue()
+	require.True(t, ok)
+	assert.Equal(t, "keep the API stable", prompt)
+	current, total, name := stepInfo.Get()
+	assert.Equal(t, []any{2, 10, "Ensure completeness"}, []any{current, total, name})
+	assert.Equal(t, snap.CommitSkip, opts.ConfirmCommit(context.Background(), "Commit now?"))
+}
@@ -0,0 +1,532 @@
+// Package snap runs the snap task workflow from Go code. It is the library
+// form of `snap run`: New resolves the provider, the task layout and the
+// pre-flight checks from Options, and Pipeline.Run implements tasks until
+// none remain, then runs the post-run steps (push, PR, CI).
+package snap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yarlson/snap/internal/model"
+	"github.com/yarlson/snap/internal/pathutil"
+	"github.com/yarlson/snap/internal/postrun"
+	"github.com/yarlson/snap/internal/provider"
+	"github.com/yarlson/snap/internal/queue"
+	"github.com/yarlson/snap/internal/session"
+	"github.com/yarlson/snap/internal/snapshot"
+	"github.com/yarlson/snap/internal/state"
+	"github.com/yarlson/snap/internal/vcs"
+	"github.com/yarlson/snap/internal/workflow"
+)
+
+// Executor runs one prompt on the given model and streams the reply to w.
+// Set Options.Executor to drive the workflow with something other than a
+// provider CLI.
+type Executor = workflow.Executor
+
+// ModelType selects the fast or the thinking model for a prompt.
+type ModelType = model.Type
+
+// Model types passed to Executor.Run.
+const (
+	ModelFast     = model.Fast
+	ModelThinking = model.Thinking
+)
+
+// Directives is a queue of typed directives the runner takes between steps.
+// Set Options.Directives to one to feed it from your own input reader.
+type Directives = queue.Queue
+
+// NewDirectives returns an empty directive queue.
+func NewDirectives() *Directives {
+	return queue.New()
+}
+
+// StepInfo holds the running step's number, total and name, for showing
+// next to an input prompt. It is safe for concurrent use.
+type StepInfo = workflow.StepContext
+
+// NewStepInfo returns a StepInfo with no step set.
+func NewStepInfo() *StepInfo {
+	return workflow.NewStepContext()
+}
+
+// CommitDecision is the answer to the commit confirmation prompt.
+type CommitDecision = workflow.CommitDecision
+
+// Answers a CommitPromptFunc returns.
+const (
+	CommitApprove = workflow.CommitApprove // Run the commit steps
+	CommitSkip    = workflow.CommitSkip    // Skip both commit steps and continue
+	CommitAbort   = workflow.CommitAbort   // Stop the run before committing
+)
+
+// CommitPromptFunc asks whether to run a task's commit steps.
+type CommitPromptFunc = workflow.CommitPromptFunc
+
+// ConfirmFunc asks a yes/no question, e.g. whether to start the next task.
+type ConfirmFunc = workflow.ConfirmFunc
+
+// DefaultTasksDir is the legacy tasks directory used when Options.TasksDir
+// is empty.
+const DefaultTasksDir = "docs/tasks"
+
+// Options configures a pipeline. The zero value runs like `snap run` with no
+// flags in the current directory.
+type Options struct {
+	// Task layout. Session names an existing session under .snap/sessions.
+	// Without one, the only session is used; with no sessions, the legacy
+	// layout in TasksDir is used when it exists, else a "default" session
+	// is created.
+	Session   string
+	TasksDir  string // Legacy tasks directory (default: DefaultTasksDir)
+	PRDPath   string // Legacy PRD path (default: <TasksDir>/PRD.md)
+	TasksGlob string // Task filename pattern (e.g. "story-*.md"); empty means TASK<n>.md
+
+	// Provider. Executor, when set, replaces the provider CLI; Provider is
+	// then only the name shown in the startup summary.
+	Provider      string            // claude or codex (default: claude)
+	FastModel     string            // Model name for fast steps (default: provider default)
+	ThinkingModel string            // Model name for thinking steps (default: provider default)
+	ProviderEnv   map[string]string // Environment variables set on every provider CLI process
+	Executor      Executor
+
+	Output         io.Writer // Workflow output (default: os.Stdout)
+	Events         io.Writer // Newline-delimited JSON progress events (workflow.Event); nil sends none
+	MetricsFile    string    // JSON array of per-step durations, appended after each task (empty = none)
+	ErrorTailLines int       // Output lines printed when a step fails (0 = 10, negative = none)
+
+	Fresh          bool          // Ignore saved state and start over
+	Scope          string        // Repo-relative path the lint/test, review and docs steps focus on
+	BaseSHA        string        // Commit the review and docs steps diff against (default: each task's start commit)
+	SessionMemory  bool          // Keep the memory vault in the session; requires a session
+	Changelog      string        // Changelog the update-docs step adds a Keep a Changelog entry to per task (empty = off)
+	FailFast       bool          // Stop the iteration when lint/test reports SNAP-CHECKS: FAIL
+	NoDescribe     bool          // Skip the task-description pre-step
+	Explain        bool          // Print each step's purpose before it runs
+	SignCommits    bool          // Require signed commits; needs a signing setup git can use
+	StrictCommits  bool          // Fail a commit step that leaves uncommitted changes
+	PRPerTask      bool          // One branch and post-run (push, PR, CI) per task, each from the base branch
+	Parallel       int           // Run up to this many tasks with disjoint affects: paths at a time, each in its own worktree (0 or 1 = one at a time)
+	MaxIterations  int           // Stop after this many completed tasks, keeping state for the next run (0 = unlimited)
+	OnlyTask       string        // Run just this task (e.g. "TASK3"), even if completed, then stop without post-run
+	PushRemote     string        // Remote post-run pushes to (default: origin)
+	PRRemote       string        // Remote whose GitHub repository PRs are opened in, e.g. "upstream" for a fork (default: the push remote's)
+	ProviderStderr string        // hide, dim or show (default: hide)
+	Snapshots      string        // Step snapshots in the git stash: every-step, on-failure or off (default: off)
+	KeepSnapshots  int           // Prune all but this many of the newest snapshots after each task (0 = keep all)
+	IdleTimeout    time.Duration // Cancel a step after this long without provider output (0 = off)
+	StepTimeout    time.Duration // Cancel a step that runs longer than this (0 = no limit)
+	StepRetries    int           // Retry a step whose provider call fails up to this many times
+	RetryBackoff   time.Duration // Wait before the first step retry; doubles for each retry after it
+	CIPollInterval time.Duration // CI status poll interval after push (0 = default)
+	IsolateCIFix   bool          // Apply CI fixes in a temporary worktree
+	CIFixFallbacks []string      // Providers CI fixes switch to, in order, when calls keep failing
+	Guardrails     []string      // Project rules the implement and code-review steps enforce
+	LintCommand    string        // Lint command (default: detected from the project)
+	TestCommand    string        // Test command (default: detected from the project)
+	NoSignals      bool          // Leave SIGINT/SIGTERM to the caller, which cancels ctx to stop the run
+
+	// Steps replaces the built-in iteration workflow (DefaultSteps). The
+	// CLI loads it from .snap/workflow.yaml with LoadSteps.
+	Steps []StepDef
+
+	// SkipSteps names steps left out of every task (--skip-step), matched
+	// case-insensitively against Steps.
+	SkipSteps []string
+
+	// StepModels replaces the model of the named steps, e.g.
+	// {"Code review": ModelFast}. The CLI loads it from the models: map in
+	// .snap/workflow.yaml with LoadStepModels.
+	StepModels map[string]ModelType
+
+	// Preamble is prepended to every step prompt, e.g. house rules such as
+	// "no new dependencies". The CLI loads it from .snap/preamble.md with
+	// LoadPreamble.
+	Preamble string
+
+	// PostIterationHook is a shell command run after each completed task,
+	// with SNAP_TASK_ID and SNAP_TASKS_DIR set. A failing hook is a warning
+	// unless HookFatal is set. The CLI reads both from .snaprc.
+	PostIterationHook string
+	HookFatal         bool
+
+	// TaskFile runs this one task file instead of a session or tasks
+	// directory, keeping its state in .snap/adhoc/. Session, TasksDir and
+	// PRDPath are then ignored.
+	TaskFile string
+
+	// ResumeStep restarts the interrupted task from this 1-indexed step
+	// instead of the saved one (0 = the saved step).
+	ResumeStep int
+
+	// Terminal runs. The CLI sets these when output goes to a terminal;
+	// without Terminal the run is headless and the rest has no effect.
+	Terminal        bool             // Output is a terminal: spinner, directive queue and prompts
+	NoInput         bool             // Take no typed directives, e.g. while the prompts below read stdin
+	ShowDiff        bool             // Print a diff stat after the implement step
+	QueueInterval   time.Duration    // Minimum time between queued directives run between steps
+	Directives      *Directives      // Typed directives run between steps (default: the runner's own queue)
+	StepInfo        *StepInfo        // Updated with the running step, for the input UI (default: the runner's own)
+	ConfirmCommit   CommitPromptFunc // Asked before each task's commit steps; nil commits without asking
+	ConfirmNextTask ConfirmFunc      // Asked before starting each next task; nil continues
+	OnInterrupt     func()           // Run on SIGINT/SIGTERM before the interrupted message, e.g. to stop reading input
+}
+
+// Pipeline is a configured workflow run.
+type Pipeline struct {
+	runner *workflow.Runner
+}
+
+// Run builds a pipeline from opts and runs it.
+func Run(ctx context.Context, opts Options) error {
+	p, err := New(opts)
+	if err != nil {
+		return err
+	}
+	return p.Run(ctx)
+}
+
+// New runs the pre-flight checks (provider CLI, gh for GitHub remotes,
+// commit signing) and builds the executor, state manager, snapshotter and
+// runner. It writes nothing to the repository except a new "default"
+// session when the layout calls for one.
+func New(opts Options) (*Pipeline, error) {
+	stderrMode, err := workflow.ParseStderrMode(opts.ProviderStderr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider stderr mode: %w", err)
+	}
+	snapshotMode := workflow.SnapshotOff
+	if opts.Snapshots != "" {
+		if snapshotMode, err = workflow.ParseSnapshotMode(opts.Snapshots); err != nil {
+			return nil, fmt.Errorf("invalid snapshot mode: %w", err)
+		}
+	}
+	if opts.TasksGlob != "" {
+		if err := workflow.ValidateTasksGlob(opts.TasksGlob); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Steps != nil {
+		if err := workflow.ValidateStepDefs(opts.Steps); err != nil {
+			return nil, fmt.Errorf("invalid workflow steps: %w", err)
+		}
+	}
+	if _, _, err := workflow.SkipSteps(opts.Steps, opts.SkipSteps); err != nil {
+		return nil, fmt.Errorf("invalid --skip-step: %w", err)
+	}
+	if err := workflow.ValidateStepModels(opts.StepModels); err != nil {
+		return nil, fmt.Errorf("invalid step models: %w", err)
+	}
+
+	providerName := provider.NormalizeName(opts.Provider)
+	executor := opts.Executor
+	if executor == nil {
+		executor, err = newExecutor(providerName, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fixFallbacks, err := resolveFixFallbacks(providerName, opts.CIFixFallbacks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pre-flight: detect git remotes and validate gh CLI if GitHub.
+	remoteURL, prRepo, err := resolveRemotes(opts.PushRemote, opts.PRRemote)
+	if err != nil {
+		return nil, err
+	}
+	isGitHub := postrun.IsGitHubRemote(remoteURL)
+	if isGitHub {
+		if err := provider.ValidateGH(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pre-flight: signed commits need a signing setup git can use.
+	if opts.SignCommits {
+		if err := snapshot.New(".").SigningConfigured(context.Background()); err != nil {
+			return nil, fmt.Errorf("--sign-commits: %w", err)
+		}
+	}
+
+	// Pre-flight: the review base must name a commit; it is pinned to its
+	// hash so a moving ref does not shift between tasks.
+	var baseSHA string
+	if opts.BaseSHA != "" {
+		baseSHA, err = snapshot.New(".").ResolveCommit(context.Background(), opts.BaseSHA)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --base-sha: %w", err)
+		}
+	}
+
+	l, err := resolveLayout(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var memoryDir string
+	if opts.SessionMemory {
+		if l.session == "" {
+			return nil, errors.New("--session-memory requires a named session")
+		}
+		memoryDir = session.MemoryDir("", l.session)
+	}
+
+	scope, err := pathutil.ResolveScope(opts.Scope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scope: %w", err)
+	}
+	if opts.Changelog != "" {
+		if err := pathutil.ValidatePath(opts.Changelog); err != nil {
+			return nil, fmt.Errorf("invalid changelog path: %w", err)
+		}
+	}
+
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	config := workflow.Config{
+		TasksDir:           l.tasksDir,
+		PRDPath:            l.prdPath,
+		TaskFilePath:       l.taskFile,
+		TasksGlob:          opts.TasksGlob,
+		FreshStart:         opts.Fresh,
+		ResumeStep:         opts.ResumeStep,
+		ProviderName:       providerName,
+		PinnedModels:       opts.FastModel != "" || opts.ThinkingModel != "",
+		SignCommits:        opts.SignCommits,
+		StrictCommits:      opts.StrictCommits,
+		PRPerTask:          opts.PRPerTask,
+		Scope:              scope,
+		BaseSHA:            baseSHA,
+		MemoryDir:          memoryDir,
+		UpdateChangelog:    opts.Changelog != "",
+		ChangelogPath:      opts.Changelog,
+		DisplayName:        l.displayName,
+		RemoteURL:          remoteURL,
+		IsGitHub:           isGitHub,
+		PushRemote:         opts.PushRemote,
+		PRRepo:             prRepo,
+		Describe:           !opts.NoDescribe,
+		Explain:            opts.Explain,
+		IdleTimeout:        opts.IdleTimeout,
+		StepTimeout:        opts.StepTimeout,
+		MaxStepRetries:     opts.StepRetries,
+		MaxIterations:      opts.MaxIterations,
+		OnlyTask:           opts.OnlyTask,
+		RetryBackoff:       opts.RetryBackoff,
+		CIPollInterval:     opts.CIPollInterval,
+		IsolateCIFix:       opts.IsolateCIFix,
+		CIFixFallbacks:     fixFallbacks,
+		ExtraGuardrails:    opts.Guardrails,
+		ProviderStderr:     stderrMode,
+		SnapshotMode:       snapshotMode,
+		SnapshotRetention:  opts.KeepSnapshots,
+		Parallel:           opts.Parallel,
+		PostIterationHook:  opts.PostIterationHook,
+		HookFatal:          opts.HookFatal,
+		FailFastOnLint:     opts.FailFast,
+		LintCommand:        opts.LintCommand,
+		TestCommand:        opts.TestCommand,
+		Steps:              opts.Steps,
+		SkipSteps:          opts.SkipSteps,
+		StepModelOverrides: opts.StepModels,
+		GlobalPreamble:     opts.Preamble,
+		StepErrorTailLines: opts.ErrorTailLines,
+		IsTTY:              opts.Terminal,
+		Headless:           opts.NoInput,
+		ShowDiff:           opts.ShowDiff,
+		QueueDrainInterval: opts.QueueInterval,
+		ConfirmCommits:     opts.ConfirmCommit != nil,
+		PauseBetweenTasks:  opts.ConfirmNextTask != nil,
+	}
+
+	runnerOpts := []workflow.RunnerOption{
+		workflow.WithRunnerOutput(output),
+		workflow.WithStateManager(l.stateManager),
+		workflow.WithWorkTree(snapshot.New(".")),
+	}
+	if snapshotMode != workflow.SnapshotOff {
+		runnerOpts = append(runnerOpts, workflow.WithSnapshotter(snapshot.New(".")))
+	}
+	if opts.Events != nil {
+		runnerOpts = append(runnerOpts, workflow.WithEventSink(opts.Events))
+	}
+	if opts.MetricsFile != "" {
+		runnerOpts = append(runnerOpts, workflow.WithMetricsFile(opts.MetricsFile))
+	}
+	if opts.NoSignals {
+		runnerOpts = append(runnerOpts, workflow.WithoutSignalHandler())
+	}
+	if opts.Directives != nil {
+		runnerOpts = append(runnerOpts, workflow.WithQueue(opts.Directives))
+	}
+	if opts.StepInfo != nil {
+		runnerOpts = append(runnerOpts, workflow.WithStepContext(opts.StepInfo))
+	}
+	if opts.ConfirmCommit != nil {
+		runnerOpts = append(runnerOpts, workflow.WithCommitConfirm(opts.ConfirmCommit))
+	}
+	if opts.ConfirmNextTask != nil {
+		runnerOpts = append(runnerOpts, workflow.WithTaskPause(opts.ConfirmNextTask))
+	}
+	if opts.OnInterrupt != nil {
+		runnerOpts = append(runnerOpts, workflow.WithInterruptHook(opts.OnInterrupt))
+	}
+
+	return &Pipeline{runner: workflow.NewRunner(executor, config, runnerOpts...)}, nil
+}
+
+// Run implements tasks until none remain, then runs the post-run steps.
+// Cancelling ctx stops the workflow after saving its state.
+func (p *Pipeline) Run(ctx context.Context) error {
+	return p.runner.Run(ctx)
+}
+
+// newExecutor resolves the provider CLI in PATH and builds its executor with
+// the pinned model names and provider environment.
+func newExecutor(providerName string, opts Options) (workflow.Executor, error) {
+	path, err := provider.ResolveCLI(providerName)
+	if err != nil {
+		return nil, err
+	}
+	var modelOpts []provider.Option
+	if opts.FastModel != "" {
+		modelOpts = append(modelOpts, provider.WithModel(model.Fast, opts.FastModel))
+	}
+	if opts.ThinkingModel != "" {
+		modelOpts = append(modelOpts, provider.WithModel(model.Thinking, opts.ThinkingModel))
+	}
+	if len(opts.ProviderEnv) > 0 {
+		modelOpts = append(modelOpts, provider.WithEnv(opts.ProviderEnv))
+	}
+	return provider.NewExecutor(providerName, path, modelOpts...)
+}
+
+// resolveFixFallbacks builds the CI fix fallback executors in order. Each
+// must be a different provider whose CLI is in PATH.
+func resolveFixFallbacks(providerName string, names []string) ([]postrun.Fallback, error) {
+	var fallbacks []postrun.Fallback
+	for _, value := range names {
+		name := provider.NormalizeName(value)
+		if name == providerName {
+			return nil, fmt.Errorf("invalid --ci-fix-fallback: %s is already the provider", name)
+		}
+		path, err := provider.ResolveCLI(name)
+		if err != nil {
+			return nil, err
+		}
+		executor, err := provider.NewExecutor(name, path)
+		if err != nil {
+			return nil, err
+		}
+		fallbacks = append(fallbacks, postrun.Fallback{Name: name, Executor: executor})
+	}
+	return fallbacks, nil
+}
+
+// resolveRemotes returns the push remote's URL and, for a PR remote naming
+// another GitHub repository, the fork/upstream pair PRs are opened with.
+// A push remote that was asked for must exist; without one, a missing
+// origin means no post-run push.
+func resolveRemotes(pushRemote, prRemote string) (string, postrun.Repo, error) {
+	git := vcs.Git("")
+	name := pushRemote
+	if name == "" {
+		name = postrun.DefaultRemote
+	}
+	remoteURL, err := postrun.DetectRemote(git, name)
+	if err != nil {
+		return "", postrun.Repo{}, fmt.Errorf("failed to detect git remote: %w", err)
+	}
+	if remoteURL == "" && pushRemote != "" {
+		return "", postrun.Repo{}, fmt.Errorf("invalid --push-remote: no remote named %q", pushRemote)
+	}
+	if prRemote == "" {
+		return remoteURL, postrun.Repo{}, nil
+	}
+
+	prURL, err := postrun.DetectRemote(git, prRemote)
+	if err != nil {
+		return "", postrun.Repo{}, fmt.Errorf("failed to detect git remote: %w", err)
+	}
+	if prURL == "" {
+		return "", postrun.Repo{}, fmt.Errorf("invalid --pr-remote: no remote named %q", prRemote)
+	}
+	upstream, ok := postrun.GitHubRepo(prURL)
+	if !ok {
+		return "", postrun.Repo{}, fmt.Errorf("invalid --pr-remote: %s is not a GitHub repository (%s)", prRemote, prURL)
+	}
+	fork, ok := postrun.GitHubRepo(remoteURL)
+	if !ok {
+		return "", postrun.Repo{}, fmt.Errorf("invalid --pr-remote: push remote %s is not a GitHub repository", name)
+	}
+	if fork == upstream {
+		return remoteURL, postrun.Repo{}, nil
+	}
+	return remoteURL, postrun.Repo{Upstream: upstream, Fork: fork}, nil
+}
+
+// layout is where a run reads its tasks and keeps its state.
+type layout struct {
+	tasksDir     string
+	prdPath      string
+	taskFile     string
+	displayName  string
+	session      string
+	stateManager workflow.StateManager
+}
+
+// resolveLayout picks the task file, session or legacy layout for opts.
+func resolveLayout(opts Options) (layout, error) {
+	if opts.TaskFile != "" {
+		path, err := filepath.Abs(opts.TaskFile)
+		if err != nil {
+			return layout{}, fmt.Errorf("resolve task file: %w", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return layout{}, fmt.Errorf("task file: %w", err)
+		}
+		return layout{
+			tasksDir:     filepath.Dir(path),
+			taskFile:     path,
+			displayName:  path,
+			stateManager: state.NewManagerForTaskFile(path),
+		}, nil
+	}
+
+	tasksDir := opts.TasksDir
+	if tasksDir == "" {
+		tasksDir = DefaultTasksDir
+	}
+	target, err := session.ResolveForRun(".", opts.Session, tasksDir)
+	if err != nil {
+		return layout{}, err
+	}
+	if target.Legacy() {
+		return layout{
+			tasksDir:     target.TasksDir,
+			prdPath:      pathutil.ResolvePRDPath(target.TasksDir, opts.PRDPath),
+			displayName:  target.DisplayName,
+			stateManager: state.NewManager(),
+		}, nil
+	}
+	return layout{
+		tasksDir:     target.TasksDir,
+		prdPath:      filepath.Join(target.TasksDir, "PRD.md"),
+		displayName:  target.DisplayName,
+		session:      target.Session,
+		stateManager: state.NewManagerInDir(session.Dir(".", target.Session)),
+	}, nil
+}
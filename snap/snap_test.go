@@ -0,0 +1,178 @@
+package snap
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/snap/internal/postrun"
+	"github.com/yarlson/snap/internal/state"
+	"github.com/yarlson/snap/internal/ui"
+)
+
+// recordingExecutor answers every prompt successfully and records it.
+type recordingExecutor struct {
+	prompts []string
+}
+
+func (e *recordingExecutor) Run(_ context.Context, w io.Writer, _ ModelType, args ...string) error {
+	e.prompts = append(e.prompts, args[len(args)-1])
+	_, err := io.WriteString(w, "done\n")
+	return err
+}
+
+func TestRun_CompletesTasks(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	tasksDir := filepath.Join("docs", "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "PRD.md"), []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+
+	executor := &recordingExecutor{}
+	var out bytes.Buffer
+	err := Run(context.Background(), Options{
+		Executor:   executor,
+		Output:     &out,
+		NoDescribe: true,
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, executor.prompts, 10, "one provider call per workflow step")
+	output := ui.StripColors(out.String())
+	assert.Contains(t, output, "TASK1")
+	assert.Contains(t, output, "All tasks implemented!")
+	assert.False(t, state.NewManager().Exists(), "state is cleared once every task is done")
+}
+
+func TestRun_TaskFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("fix-login.md", []byte("# Fix login"), 0o600))
+
+	executor := &recordingExecutor{}
+	var out bytes.Buffer
+	err := Run(context.Background(), Options{
+		TaskFile:   "fix-login.md",
+		Executor:   executor,
+		Output:     &out,
+		NoDescribe: true,
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, executor.prompts)
+	assert.Contains(t, executor.prompts[0], "fix-login.md")
+	assert.NoDirExists(t, filepath.Join(".snap", "sessions"), "a task file run creates no session")
+	assert.NoFileExists(t, filepath.Join(".snap", "state.json"))
+}
+
+func TestRun_StepsFromWorkflowFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.MkdirAll(DefaultTasksDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(DefaultTasksDir, "PRD.md"), []byte("# PRD"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(DefaultTasksDir, "TASK1.md"), []byte("# Task 1"), 0o600))
+	require.NoError(t, os.MkdirAll(".snap", 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(".snap", "workflow.yaml"), []byte(`steps:
+  - name: Implement
+    prompt: implement
+    model: thinking
+  - name: Commit code
+    prompt: commit
+    model: fast
+`), 0o600))
+
+	steps, err := LoadSteps(".")
+	require.NoError(t, err)
+	assert.Equal(t, []StepDef{
+		{Name: "Implement", Prompt: PromptImplement, Model: ModelThinking},
+		{Name: "Commit code", Prompt: PromptCommit, Model: ModelFast},
+	}, steps)
+
+	executor := &recordingExecutor{}
+	require.NoError(t, Run(context.Background(), Options{
+		Executor:   executor,
+		Output:     io.Discard,
+		NoDescribe: true,
+		Steps:      steps,
+	}))
+	assert.Len(t, executor.prompts, 2, "one provider call per configured step")
+}
+
+func TestNew_SessionMemoryRequiresSession(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.MkdirAll(DefaultTasksDir, 0o755))
+
+	_, err := New(Options{Executor: &recordingExecutor{}, SessionMemory: true})
+	require.Error(t, err)
+	assert.Equal(t, "--session-memory requires a named session", err.Error())
+}
+
+func TestNew_BaseSHAMustBeACommit(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.MkdirAll(DefaultTasksDir, 0o755))
+
+	_, err := New(Options{Executor: &recordingExecutor{}, BaseSHA: "no-such-ref"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --base-sha: no-such-ref is not a commit")
+}
+
+func TestResolveRemotes(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for _, args := range [][]string{
+		{"init"},
+		{"remote", "add", "origin", "git@github.com:me/repo.git"},
+		{"remote", "add", "upstream", "https://github.com/up/repo.git"},
+		{"remote", "add", "mirror", "https://gitlab.com/me/repo.git"},
+	} {
+		out, err := exec.CommandContext(context.Background(), "git", args...).CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, out)
+	}
+
+	remoteURL, repo, err := resolveRemotes("", "")
+	require.NoError(t, err)
+	assert.Equal(t, "git@github.com:me/repo.git", remoteURL)
+	assert.Equal(t, postrun.Repo{}, repo)
+
+	remoteURL, repo, err = resolveRemotes("", "upstream")
+	require.NoError(t, err)
+	assert.Equal(t, "git@github.com:me/repo.git", remoteURL)
+	assert.Equal(t, postrun.Repo{Upstream: "up/repo", Fork: "me/repo"}, repo)
+
+	_, repo, err = resolveRemotes("upstream", "upstream")
+	require.NoError(t, err)
+	assert.Equal(t, postrun.Repo{}, repo, "same repository needs no fork handling")
+
+	_, _, err = resolveRemotes("fork", "")
+	require.Error(t, err)
+	assert.Equal(t, `invalid --push-remote: no remote named "fork"`, err.Error())
+
+	_, _, err = resolveRemotes("", "mirror")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --pr-remote: mirror is not a GitHub repository")
+}
+
+func TestResolveFixFallbacks(t *testing.T) {
+	fallbacks, err := resolveFixFallbacks("claude", nil)
+	require.NoError(t, err)
+	assert.Empty(t, fallbacks)
+
+	_, err = resolveFixFallbacks("claude", []string{"Claude-Code"})
+	require.Error(t, err)
+	assert.Equal(t, "invalid --ci-fix-fallback: claude is already the provider", err.Error())
+
+	t.Setenv("PATH", t.TempDir())
+	_, err = resolveFixFallbacks("claude", []string{"codex"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "codex not found in PATH")
+}
@@ -0,0 +1,46 @@
+package snap
+
+import "github.com/yarlson/snap/internal/workflow"
+
+// StepDef describes one step of the iteration workflow, as listed in
+// .snap/workflow.yaml. Set Options.Steps to a list of them to replace the
+// built-in workflow.
+type StepDef = workflow.StepDef
+
+// Prompt keys a StepDef can name.
+const (
+	PromptImplement          = workflow.PromptImplement
+	PromptEnsureCompleteness = workflow.PromptEnsureCompleteness
+	PromptLintAndTest        = workflow.PromptLintAndTest
+	PromptCodeReview         = workflow.PromptCodeReview
+	PromptApplyFixes         = workflow.PromptApplyFixes
+	PromptUpdateDocs         = workflow.PromptUpdateDocs
+	PromptCommit             = workflow.PromptCommit
+	PromptMemoryUpdate       = workflow.PromptMemoryUpdate
+)
+
+// DefaultSteps returns the built-in iteration workflow, which runs when
+// Options.Steps is nil.
+func DefaultSteps() []StepDef {
+	return workflow.DefaultSteps()
+}
+
+// LoadSteps reads the step list from .snap/workflow.yaml under projectRoot,
+// for Options.Steps. A missing file, or one that only sets models, returns
+// nil.
+func LoadSteps(projectRoot string) ([]StepDef, error) {
+	return workflow.LoadSteps(projectRoot)
+}
+
+// LoadStepModels reads the per-step model overrides (the models: map) from
+// .snap/workflow.yaml under projectRoot, for Options.StepModels. A missing
+// file returns nil.
+func LoadStepModels(projectRoot string) (map[string]ModelType, error) {
+	return workflow.LoadStepModels(projectRoot)
+}
+
+// LoadPreamble reads .snap/preamble.md under projectRoot, for
+// Options.Preamble. A missing file returns "".
+func LoadPreamble(projectRoot string) (string, error) {
+	return workflow.LoadPreamble(projectRoot)
+}